@@ -0,0 +1,88 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.DeleteJobService = (*DeleteJobService)(nil)
+
+// DeleteJobService wraps a influxdb.DeleteJobService and authorizes actions
+// against it appropriately. A delete job may be started, seen and canceled by
+// authorizers with write access to its bucket.
+type DeleteJobService struct {
+	s influxdb.DeleteJobService
+}
+
+// NewDeleteJobService constructs an instance of an authorizing delete job service.
+func NewDeleteJobService(s influxdb.DeleteJobService) *DeleteJobService {
+	return &DeleteJobService{
+		s: s,
+	}
+}
+
+// StartDeleteJob checks to see if the authorizer on context has write access
+// to the bucket to delete from.
+func (s *DeleteJobService) StartDeleteJob(ctx context.Context, req influxdb.DeleteJobRequest) (*influxdb.DeleteJob, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, req.BucketID, req.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.StartDeleteJob(ctx, req)
+}
+
+// FindDeleteJobs retrieves all delete jobs matching filter and then filters the
+// list down to the jobs of buckets the authorizer may write to.
+func (s *DeleteJobService) FindDeleteJobs(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	js, err := s.s.FindDeleteJobs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := js[:0]
+	for _, j := range js {
+		if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, j.BucketID, j.OrgID); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+				continue
+			}
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+// FindDeleteJobByID checks to see if the authorizer on context has write access
+// to the bucket of the delete job.
+func (s *DeleteJobService) FindDeleteJobByID(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	j, err := s.s.FindDeleteJobByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, j.BucketID, j.OrgID); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// CancelDeleteJob checks to see if the authorizer on context has write access
+// to the bucket of the delete job.
+func (s *DeleteJobService) CancelDeleteJob(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, err := s.FindDeleteJobByID(ctx, id); err != nil {
+		return err
+	}
+	return s.s.CancelDeleteJob(ctx, id)
+}
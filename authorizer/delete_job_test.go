@@ -0,0 +1,188 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func newDeleteJobTestService() *mock.DeleteJobService {
+	s := mock.NewDeleteJobService()
+	jobs := []*influxdb.DeleteJob{
+		{ID: 1, OrgID: 10, BucketID: 1},
+		{ID: 2, OrgID: 10, BucketID: 2},
+		{ID: 3, OrgID: 10, BucketID: 1},
+	}
+	s.FindDeleteJobsFn = func(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+		return append([]*influxdb.DeleteJob{}, jobs...), nil
+	}
+	s.FindDeleteJobByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+		for _, j := range jobs {
+			if j.ID == id {
+				return j, nil
+			}
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "delete job not found"}
+	}
+	return s
+}
+
+func TestDeleteJobService_FindDeleteJobs(t *testing.T) {
+	type args struct {
+		permissions []influxdb.Permission
+	}
+	type wants struct {
+		ids []influxdb.ID
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to write all buckets",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "write",
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+						},
+					},
+				},
+			},
+			wants: wants{
+				ids: []influxdb.ID{1, 2, 3},
+			},
+		},
+		{
+			name: "authorized to write one bucket",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "write",
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+							ID:   influxdbtesting.IDPtr(1),
+						},
+					},
+				},
+			},
+			wants: wants{
+				ids: []influxdb.ID{1, 3},
+			},
+		},
+		{
+			name: "only authorized to read buckets",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "read",
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewDeleteJobService(newDeleteJobTestService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.args.permissions))
+
+			jobs, err := s.FindDeleteJobs(ctx, influxdb.DeleteJobFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []influxdb.ID
+			for _, j := range jobs {
+				ids = append(ids, j.ID)
+			}
+			if diff := cmp.Diff(ids, tt.wants.ids); diff != "" {
+				t.Errorf("delete jobs are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestDeleteJobService_CancelDeleteJob(t *testing.T) {
+	type args struct {
+		permission influxdb.Permission
+		id         influxdb.ID
+	}
+	type wants struct {
+		err error
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to write bucket",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+						ID:   influxdbtesting.IDPtr(2),
+					},
+				},
+				id: 2,
+			},
+		},
+		{
+			name: "unauthorized to write bucket",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+						ID:   influxdbtesting.IDPtr(1),
+					},
+				},
+				id: 2,
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "write:orgs/000000000000000a/buckets/0000000000000002 is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := newDeleteJobTestService()
+			var canceled []influxdb.ID
+			jobs.CancelDeleteJobFn = func(ctx context.Context, id influxdb.ID) error {
+				canceled = append(canceled, id)
+				return nil
+			}
+			s := authorizer.NewDeleteJobService(jobs)
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, []influxdb.Permission{tt.args.permission}))
+
+			err := s.CancelDeleteJob(ctx, tt.args.id)
+			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
+			if err == nil && len(canceled) != 1 {
+				t.Errorf("expected the delete job to be canceled, got %v", canceled)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.RunningQueryService = (*RunningQueryService)(nil)
+
+// RunningQueryService wraps a influxdb.RunningQueryService and authorizes actions
+// against it appropriately. A running query may be seen and killed by the user
+// who started it and by any authorizer with write access to its organization.
+type RunningQueryService struct {
+	s influxdb.RunningQueryService
+}
+
+// NewRunningQueryService constructs an instance of an authorizing running query service.
+func NewRunningQueryService(s influxdb.RunningQueryService) *RunningQueryService {
+	return &RunningQueryService{
+		s: s,
+	}
+}
+
+// FindRunningQueries retrieves all running queries matching filter and then
+// filters the list down to the queries the authorizer may access.
+func (s *RunningQueryService) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	qs, err := s.s.FindRunningQueries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := qs[:0]
+	for _, q := range qs {
+		if err := authorizeRunningQuery(ctx, q); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+				continue
+			}
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+// KillQuery checks to see if the authorizer on context may access the query
+// before killing it.
+func (s *RunningQueryService) KillQuery(ctx context.Context, id uint64) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	qs, err := s.s.FindRunningQueries(ctx, influxdb.RunningQueryFilter{ID: &id})
+	if err != nil {
+		return err
+	} else if len(qs) == 0 {
+		return influxdb.ErrRunningQueryNotFound
+	}
+
+	if err := authorizeRunningQuery(ctx, qs[0]); err != nil {
+		return err
+	}
+	return s.s.KillQuery(ctx, id)
+}
+
+func authorizeRunningQuery(ctx context.Context, q *influxdb.RunningQuery) error {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+	if q.UserID.Valid() && a.GetUserID() == q.UserID {
+		return nil
+	}
+	_, _, err = AuthorizeWriteOrg(ctx, q.OrgID)
+	return err
+}
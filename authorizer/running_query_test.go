@@ -0,0 +1,219 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func newRunningQueryTestService() *mock.RunningQueryService {
+	s := mock.NewRunningQueryService()
+	s.FindRunningQueriesFn = func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+		queries := []*influxdb.RunningQuery{
+			{ID: 1, OrgID: 10, UserID: 2},
+			{ID: 2, OrgID: 10, UserID: 3},
+			{ID: 3, OrgID: 11, UserID: 3},
+		}
+		if filter.ID != nil {
+			for _, q := range queries {
+				if q.ID == *filter.ID {
+					return []*influxdb.RunningQuery{q}, nil
+				}
+			}
+			return nil, nil
+		}
+		return queries, nil
+	}
+	return s
+}
+
+func TestRunningQueryService_FindRunningQueries(t *testing.T) {
+	type args struct {
+		permissions []influxdb.Permission
+	}
+	type wants struct {
+		ids []uint64
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to write all orgs",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "write",
+						Resource: influxdb.Resource{
+							Type: influxdb.OrgsResourceType,
+						},
+					},
+				},
+			},
+			wants: wants{
+				ids: []uint64{1, 2, 3},
+			},
+		},
+		{
+			name: "authorized to write one org",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "write",
+						Resource: influxdb.Resource{
+							Type: influxdb.OrgsResourceType,
+							ID:   influxdbtesting.IDPtr(11),
+						},
+					},
+				},
+			},
+			wants: wants{
+				ids: []uint64{1, 3},
+			},
+		},
+		{
+			name: "only own queries",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "read",
+						Resource: influxdb.Resource{
+							Type: influxdb.OrgsResourceType,
+						},
+					},
+				},
+			},
+			wants: wants{
+				ids: []uint64{1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewRunningQueryService(newRunningQueryTestService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.args.permissions))
+
+			qs, err := s.FindRunningQueries(ctx, influxdb.RunningQueryFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []uint64
+			for _, q := range qs {
+				ids = append(ids, q.ID)
+			}
+			if diff := cmp.Diff(ids, tt.wants.ids); diff != "" {
+				t.Errorf("running queries are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestRunningQueryService_KillQuery(t *testing.T) {
+	type args struct {
+		permission influxdb.Permission
+		id         uint64
+	}
+	type wants struct {
+		err error
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to write org",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.OrgsResourceType,
+						ID:   influxdbtesting.IDPtr(10),
+					},
+				},
+				id: 2,
+			},
+		},
+		{
+			name: "own query",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "read",
+					Resource: influxdb.Resource{
+						Type: influxdb.OrgsResourceType,
+						ID:   influxdbtesting.IDPtr(10),
+					},
+				},
+				id: 1,
+			},
+		},
+		{
+			name: "unauthorized to write org",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "read",
+					Resource: influxdb.Resource{
+						Type: influxdb.OrgsResourceType,
+						ID:   influxdbtesting.IDPtr(10),
+					},
+				},
+				id: 2,
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "write:orgs/000000000000000a is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+		{
+			name: "query not found",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.OrgsResourceType,
+					},
+				},
+				id: 4,
+			},
+			wants: wants{
+				err: influxdb.ErrRunningQueryNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qs := newRunningQueryTestService()
+			var killed []uint64
+			qs.KillQueryFn = func(ctx context.Context, id uint64) error {
+				killed = append(killed, id)
+				return nil
+			}
+			s := authorizer.NewRunningQueryService(qs)
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, []influxdb.Permission{tt.args.permission}))
+
+			err := s.KillQuery(ctx, tt.args.id)
+			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
+			if err == nil && !cmp.Equal(killed, []uint64{tt.args.id}) {
+				t.Errorf("unexpected killed queries: %v", killed)
+			}
+		})
+	}
+}
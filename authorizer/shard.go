@@ -0,0 +1,117 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.ShardService = (*ShardService)(nil)
+
+// ShardService wraps a influxdb.ShardService and authorizes actions
+// against it appropriately. Access to a shard is tied to the bucket
+// which owns it.
+type ShardService struct {
+	s  influxdb.ShardService
+	bs influxdb.BucketService
+}
+
+// NewShardService constructs an instance of an authorizing shard service.
+// The bucket service is used to find the organization of a bucket.
+func NewShardService(s influxdb.ShardService, bs influxdb.BucketService) *ShardService {
+	return &ShardService{
+		s:  s,
+		bs: bs,
+	}
+}
+
+// FindShardGroups retrieves all shard groups matching filter and then filters
+// the list down to the shard groups of buckets the authorizer may read.
+func (s *ShardService) FindShardGroups(ctx context.Context, filter influxdb.ShardGroupFilter) ([]*influxdb.ShardGroup, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	sgs, err := s.s.FindShardGroups(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[influxdb.ID]bool)
+	groups := sgs[:0]
+	for _, sg := range sgs {
+		ok, seen := allowed[sg.BucketID]
+		if !seen {
+			ok, err = s.canReadBucket(ctx, sg.BucketID)
+			if err != nil {
+				return nil, err
+			}
+			allowed[sg.BucketID] = ok
+		}
+		if ok {
+			groups = append(groups, sg)
+		}
+	}
+	return groups, nil
+}
+
+func (s *ShardService) canReadBucket(ctx context.Context, id influxdb.ID) (bool, error) {
+	b, err := s.bs.FindBucketByID(ctx, id)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if _, _, err := AuthorizeReadBucket(ctx, b.Type, b.ID, b.OrgID); err != nil {
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteShard checks to see if the authorizer on context has write access to
+// the bucket owning the shard.
+func (s *ShardService) DeleteShard(ctx context.Context, shardID uint64) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.authorizeWriteShard(ctx, shardID); err != nil {
+		return err
+	}
+	return s.s.DeleteShard(ctx, shardID)
+}
+
+// RebuildShardIndex checks to see if the authorizer on context has write access
+// to the bucket owning the shard.
+func (s *ShardService) RebuildShardIndex(ctx context.Context, shardID uint64) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.authorizeWriteShard(ctx, shardID); err != nil {
+		return err
+	}
+	return s.s.RebuildShardIndex(ctx, shardID)
+}
+
+func (s *ShardService) authorizeWriteShard(ctx context.Context, shardID uint64) error {
+	sgs, err := s.s.FindShardGroups(ctx, influxdb.ShardGroupFilter{ShardID: &shardID})
+	if err != nil {
+		return err
+	} else if len(sgs) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "shard not found",
+		}
+	}
+
+	b, err := s.bs.FindBucketByID(ctx, sgs[0].BucketID)
+	if err != nil {
+		return err
+	}
+	_, _, err = AuthorizeWrite(ctx, influxdb.BucketsResourceType, b.ID, b.OrgID)
+	return err
+}
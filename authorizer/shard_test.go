@@ -0,0 +1,265 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func newShardTestServices() (*mock.ShardService, *mock.BucketService) {
+	shards := mock.NewShardService()
+	shards.FindShardGroupsFn = func(ctx context.Context, filter influxdb.ShardGroupFilter) ([]*influxdb.ShardGroup, error) {
+		groups := []*influxdb.ShardGroup{
+			{ID: 1, BucketID: 1, Shards: []influxdb.Shard{{ID: 1}}},
+			{ID: 2, BucketID: 2, Shards: []influxdb.Shard{{ID: 2}}},
+			{ID: 3, BucketID: 1, Shards: []influxdb.Shard{{ID: 3}}},
+		}
+		if filter.ShardID != nil {
+			for _, sg := range groups {
+				if sg.Shards[0].ID == *filter.ShardID {
+					return []*influxdb.ShardGroup{sg}, nil
+				}
+			}
+			return nil, nil
+		}
+		return groups, nil
+	}
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, OrgID: 10}, nil
+	}
+	return shards, buckets
+}
+
+func TestShardService_FindShardGroups(t *testing.T) {
+	type args struct {
+		permissions []influxdb.Permission
+	}
+	type wants struct {
+		ids []uint64
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to read all buckets",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "read",
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+						},
+					},
+				},
+			},
+			wants: wants{
+				ids: []uint64{1, 2, 3},
+			},
+		},
+		{
+			name: "authorized to read one bucket",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "read",
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+							ID:   influxdbtesting.IDPtr(1),
+						},
+					},
+				},
+			},
+			wants: wants{
+				ids: []uint64{1, 3},
+			},
+		},
+		{
+			name: "unauthorized to read buckets",
+			args: args{
+				permissions: []influxdb.Permission{
+					{
+						Action: "write",
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewShardService(newShardTestServices())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.args.permissions))
+
+			sgs, err := s.FindShardGroups(ctx, influxdb.ShardGroupFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []uint64
+			for _, sg := range sgs {
+				ids = append(ids, sg.ID)
+			}
+			if diff := cmp.Diff(ids, tt.wants.ids); diff != "" {
+				t.Errorf("shard groups are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestShardService_DeleteShard(t *testing.T) {
+	type args struct {
+		permission influxdb.Permission
+		id         uint64
+	}
+	type wants struct {
+		err error
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "authorized to write bucket",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+						ID:   influxdbtesting.IDPtr(2),
+					},
+				},
+				id: 2,
+			},
+		},
+		{
+			name: "unauthorized to write bucket",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+						ID:   influxdbtesting.IDPtr(1),
+					},
+				},
+				id: 2,
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "write:orgs/000000000000000a/buckets/0000000000000002 is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+		{
+			name: "shard not found",
+			args: args{
+				permission: influxdb.Permission{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+					},
+				},
+				id: 4,
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "shard not found",
+					Code: influxdb.ENotFound,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shards, buckets := newShardTestServices()
+			var deleted []uint64
+			shards.DeleteShardFn = func(ctx context.Context, id uint64) error {
+				deleted = append(deleted, id)
+				return nil
+			}
+			s := authorizer.NewShardService(shards, buckets)
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, []influxdb.Permission{tt.args.permission}))
+
+			err := s.DeleteShard(ctx, tt.args.id)
+			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
+			if err == nil && !cmp.Equal(deleted, []uint64{tt.args.id}) {
+				t.Errorf("unexpected deleted shards: %v", deleted)
+			}
+		})
+	}
+}
+
+func TestShardService_RebuildShardIndex(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		err        error
+	}{
+		{
+			name: "authorized to write bucket",
+			permission: influxdb.Permission{
+				Action: "write",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+					ID:   influxdbtesting.IDPtr(2),
+				},
+			},
+		},
+		{
+			name: "unauthorized to write bucket",
+			permission: influxdb.Permission{
+				Action: "read",
+				Resource: influxdb.Resource{
+					Type: influxdb.BucketsResourceType,
+					ID:   influxdbtesting.IDPtr(2),
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/buckets/0000000000000002 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shards, buckets := newShardTestServices()
+			var rebuilt []uint64
+			shards.RebuildShardIndexFn = func(ctx context.Context, id uint64) error {
+				rebuilt = append(rebuilt, id)
+				return nil
+			}
+			s := authorizer.NewShardService(shards, buckets)
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, []influxdb.Permission{tt.permission}))
+
+			err := s.RebuildShardIndex(ctx, 2)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+			if err == nil && !cmp.Equal(rebuilt, []uint64{2}) {
+				t.Errorf("unexpected rebuilt shards: %v", rebuilt)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.StorageVerifyService = (*StorageVerifyService)(nil)
+
+// StorageVerifyService wraps a influxdb.StorageVerifyService and authorizes
+// actions against it appropriately. A verification may be seen by authorizers
+// with read access to its bucket, and started or canceled by authorizers with
+// write access to it.
+type StorageVerifyService struct {
+	s  influxdb.StorageVerifyService
+	bs influxdb.BucketService
+}
+
+// NewStorageVerifyService constructs an instance of an authorizing storage
+// verify service. The bucket service is used to find the organization of a bucket.
+func NewStorageVerifyService(s influxdb.StorageVerifyService, bs influxdb.BucketService) *StorageVerifyService {
+	return &StorageVerifyService{
+		s:  s,
+		bs: bs,
+	}
+}
+
+// VerifyStorage checks to see if the authorizer on context has write access to
+// the bucket to verify.
+func (s *StorageVerifyService) VerifyStorage(ctx context.Context, req influxdb.StorageVerificationRequest) (*influxdb.StorageVerification, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.authorizeWriteBucket(ctx, req.BucketID); err != nil {
+		return nil, err
+	}
+	return s.s.VerifyStorage(ctx, req)
+}
+
+// FindStorageVerifications retrieves all verifications matching filter and then
+// filters the list down to the verifications of buckets the authorizer may read.
+func (s *StorageVerifyService) FindStorageVerifications(ctx context.Context, filter influxdb.StorageVerificationFilter) ([]*influxdb.StorageVerification, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	vs, err := s.s.FindStorageVerifications(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[influxdb.ID]bool)
+	verifications := vs[:0]
+	for _, v := range vs {
+		ok, seen := allowed[v.BucketID]
+		if !seen {
+			ok, err = s.canReadBucket(ctx, v.BucketID)
+			if err != nil {
+				return nil, err
+			}
+			allowed[v.BucketID] = ok
+		}
+		if ok {
+			verifications = append(verifications, v)
+		}
+	}
+	return verifications, nil
+}
+
+// FindStorageVerificationByID checks to see if the authorizer on context has
+// read access to the bucket of the verification.
+func (s *StorageVerifyService) FindStorageVerificationByID(ctx context.Context, id influxdb.ID) (*influxdb.StorageVerification, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	v, err := s.s.FindStorageVerificationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := s.bs.FindBucketByID(ctx, v.BucketID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeReadBucket(ctx, b.Type, b.ID, b.OrgID); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// CancelStorageVerification checks to see if the authorizer on context has
+// write access to the bucket of the verification.
+func (s *StorageVerifyService) CancelStorageVerification(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	v, err := s.s.FindStorageVerificationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeWriteBucket(ctx, v.BucketID); err != nil {
+		return err
+	}
+	return s.s.CancelStorageVerification(ctx, id)
+}
+
+func (s *StorageVerifyService) authorizeWriteBucket(ctx context.Context, id influxdb.ID) error {
+	b, err := s.bs.FindBucketByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, _, err = AuthorizeWrite(ctx, influxdb.BucketsResourceType, b.ID, b.OrgID)
+	return err
+}
+
+func (s *StorageVerifyService) canReadBucket(ctx context.Context, id influxdb.ID) (bool, error) {
+	b, err := s.bs.FindBucketByID(ctx, id)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if _, _, err := AuthorizeReadBucket(ctx, b.Type, b.ID, b.OrgID); err != nil {
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
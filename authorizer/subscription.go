@@ -0,0 +1,97 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.SubscriptionService = (*SubscriptionService)(nil)
+
+// SubscriptionService wraps a influxdb.SubscriptionService and authorizes actions
+// against it appropriately. As a subscription forwards all the points written to
+// its bucket, seeing one requires read access to the bucket; creating or deleting
+// one requires both read and write access to the bucket.
+type SubscriptionService struct {
+	s influxdb.SubscriptionService
+}
+
+// NewSubscriptionService constructs an instance of an authorizing subscription service.
+func NewSubscriptionService(s influxdb.SubscriptionService) *SubscriptionService {
+	return &SubscriptionService{
+		s: s,
+	}
+}
+
+// FindSubscriptionByID checks to see if the authorizer on context has read access to the bucket of the subscription.
+func (s *SubscriptionService) FindSubscriptionByID(ctx context.Context, id influxdb.ID) (*influxdb.Subscription, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	sub, err := s.s.FindSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, sub.BucketID, sub.OrgID); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// FindSubscriptions retrieves all subscriptions that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *SubscriptionService) FindSubscriptions(ctx context.Context, filter influxdb.SubscriptionFilter) ([]*influxdb.Subscription, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ss, _, err := s.s.FindSubscriptions(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	subs := ss[:0]
+	for _, sub := range ss {
+		if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, sub.BucketID, sub.OrgID); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+				continue
+			}
+			return nil, 0, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, len(subs), nil
+}
+
+// CreateSubscription checks to see if the authorizer on context has read and write access to the bucket of the subscription.
+func (s *SubscriptionService) CreateSubscription(ctx context.Context, sub *influxdb.Subscription) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeSubscriptionBucket(ctx, sub); err != nil {
+		return err
+	}
+	return s.s.CreateSubscription(ctx, sub)
+}
+
+// DeleteSubscription checks to see if the authorizer on context has read and write access to the bucket of the subscription.
+func (s *SubscriptionService) DeleteSubscription(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	sub, err := s.s.FindSubscriptionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorizeSubscriptionBucket(ctx, sub); err != nil {
+		return err
+	}
+	return s.s.DeleteSubscription(ctx, id)
+}
+
+func authorizeSubscriptionBucket(ctx context.Context, sub *influxdb.Subscription) error {
+	if _, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, sub.BucketID, sub.OrgID); err != nil {
+		return err
+	}
+	_, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, sub.BucketID, sub.OrgID)
+	return err
+}
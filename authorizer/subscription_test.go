@@ -0,0 +1,236 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func newSubscriptionTestService() *mock.SubscriptionService {
+	subs := []*influxdb.Subscription{
+		{ID: 1, OrgID: 10, BucketID: 100, Name: "s0"},
+		{ID: 2, OrgID: 10, BucketID: 101, Name: "s1"},
+		{ID: 3, OrgID: 11, BucketID: 102, Name: "s2"},
+	}
+
+	s := mock.NewSubscriptionService()
+	s.FindSubscriptionByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Subscription, error) {
+		for _, sub := range subs {
+			if sub.ID == id {
+				return sub, nil
+			}
+		}
+		return nil, influxdb.ErrSubscriptionNotFound
+	}
+	s.FindSubscriptionsFn = func(ctx context.Context, filter influxdb.SubscriptionFilter) ([]*influxdb.Subscription, int, error) {
+		return append([]*influxdb.Subscription(nil), subs...), len(subs), nil
+	}
+	return s
+}
+
+func TestSubscriptionService_FindSubscriptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		ids         []influxdb.ID
+	}{
+		{
+			name: "authorized to read all buckets",
+			permissions: []influxdb.Permission{
+				{
+					Action: "read",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+					},
+				},
+			},
+			ids: []influxdb.ID{1, 2, 3},
+		},
+		{
+			name: "authorized to read buckets of an org",
+			permissions: []influxdb.Permission{
+				{
+					Action: "read",
+					Resource: influxdb.Resource{
+						Type:  influxdb.BucketsResourceType,
+						OrgID: influxdbtesting.IDPtr(10),
+					},
+				},
+			},
+			ids: []influxdb.ID{1, 2},
+		},
+		{
+			name: "authorized to read one bucket",
+			permissions: []influxdb.Permission{
+				{
+					Action: "read",
+					Resource: influxdb.Resource{
+						Type: influxdb.BucketsResourceType,
+						ID:   influxdbtesting.IDPtr(102),
+					},
+				},
+			},
+			ids: []influxdb.ID{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewSubscriptionService(newSubscriptionTestService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.permissions))
+
+			subs, n, err := s.FindSubscriptions(ctx, influxdb.SubscriptionFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []influxdb.ID
+			for _, sub := range subs {
+				ids = append(ids, sub.ID)
+			}
+			if diff := cmp.Diff(ids, tt.ids); diff != "" {
+				t.Errorf("subscriptions are different -got/+want\ndiff %s", diff)
+			}
+			if n != len(tt.ids) {
+				t.Errorf("unexpected count: got %d, want %d", n, len(tt.ids))
+			}
+		})
+	}
+}
+
+func TestSubscriptionService_FindSubscriptionByID(t *testing.T) {
+	s := authorizer.NewSubscriptionService(newSubscriptionTestService())
+
+	ctx := context.Background()
+	ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, []influxdb.Permission{{
+		Action: "read",
+		Resource: influxdb.Resource{
+			Type: influxdb.BucketsResourceType,
+			ID:   influxdbtesting.IDPtr(100),
+		},
+	}}))
+
+	if _, err := s.FindSubscriptionByID(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	_, err := s.FindSubscriptionByID(ctx, 2)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "read:orgs/000000000000000a/buckets/0000000000000065 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
+
+func TestSubscriptionService_CreateSubscription(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name: "authorized to read and write bucket",
+			permissions: []influxdb.Permission{
+				{
+					Action:   "read",
+					Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: influxdbtesting.IDPtr(100)},
+				},
+				{
+					Action:   "write",
+					Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: influxdbtesting.IDPtr(100)},
+				},
+			},
+		},
+		{
+			name: "unauthorized to write bucket",
+			permissions: []influxdb.Permission{
+				{
+					Action:   "read",
+					Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: influxdbtesting.IDPtr(100)},
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/buckets/0000000000000064 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name: "unauthorized to read bucket",
+			permissions: []influxdb.Permission{
+				{
+					Action:   "write",
+					Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: influxdbtesting.IDPtr(100)},
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "read:orgs/000000000000000a/buckets/0000000000000064 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []*influxdb.Subscription
+			ss := newSubscriptionTestService()
+			ss.CreateSubscriptionFn = func(ctx context.Context, sub *influxdb.Subscription) error {
+				created = append(created, sub)
+				return nil
+			}
+			s := authorizer.NewSubscriptionService(ss)
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.permissions))
+
+			err := s.CreateSubscription(ctx, &influxdb.Subscription{OrgID: 10, BucketID: 100, Name: "s3"})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+			want := 1
+			if tt.err != nil {
+				want = 0
+			}
+			if len(created) != want {
+				t.Errorf("unexpected number of created subscriptions: got %d, want %d", len(created), want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionService_DeleteSubscription(t *testing.T) {
+	var deleted []influxdb.ID
+	ss := newSubscriptionTestService()
+	ss.DeleteSubscriptionFn = func(ctx context.Context, id influxdb.ID) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+	s := authorizer.NewSubscriptionService(ss)
+
+	ctx := context.Background()
+	ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, []influxdb.Permission{
+		{
+			Action:   "read",
+			Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: influxdbtesting.IDPtr(10)},
+		},
+		{
+			Action:   "write",
+			Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: influxdbtesting.IDPtr(10)},
+		},
+	}))
+
+	if err := s.DeleteSubscription(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	err := s.DeleteSubscription(ctx, 3)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "read:orgs/000000000000000b/buckets/0000000000000066 is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+	if !cmp.Equal(deleted, []influxdb.ID{1}) {
+		t.Errorf("unexpected deleted subscriptions: %v", deleted)
+	}
+}
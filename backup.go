@@ -31,16 +31,96 @@ type RestoreService interface {
 	RestoreShard(ctx context.Context, shardID uint64, r io.Reader) error
 }
 
+// ResourceRestoreService restores resources of an organization from a backup
+// of the metadata database into the live one.
+type ResourceRestoreService interface {
+	// RestoreResources reads a backed-up metadata database from r and restores the
+	// requested resources of one of its organizations, on behalf of userID.
+	RestoreResources(ctx context.Context, userID ID, r io.Reader, req RestoreResourcesRequest) (*RestoreResourcesReport, error)
+}
+
+// RestorableResourceTypes are the kinds of resources which can be restored from a backup
+// of the metadata database, in the order they are restored.
+var RestorableResourceTypes = []ResourceType{
+	VariablesResourceType,
+	DashboardsResourceType,
+	TasksResourceType,
+	ChecksResourceType,
+	NotificationRuleResourceType,
+	TelegrafsResourceType,
+}
+
+// RestoreResourcesRequest selects the resources to restore from a backup of the metadata database.
+type RestoreResourcesRequest struct {
+	// OrgID or Org identify the organization of the backup to restore the resources of.
+	OrgID ID     `json:"orgID,omitempty"`
+	Org   string `json:"org,omitempty"`
+
+	// NewOrgID or NewOrg identify the organization to restore the resources to.
+	// The resources are restored to the organization of the same name if neither is set.
+	NewOrgID ID     `json:"newOrgID,omitempty"`
+	NewOrg   string `json:"newOrg,omitempty"`
+
+	// Kinds are the kinds of resources to restore, all restorable kinds if empty.
+	Kinds []ResourceType `json:"kinds,omitempty"`
+}
+
+// RestoreResourcesReport lists the resources restored from a backup and the ones
+// that could not be.
+type RestoreResourcesReport struct {
+	Restored  []RestoredResource `json:"restored"`
+	Conflicts []RestoreConflict  `json:"conflicts"`
+}
+
+// RestoredResource is a resource restored from a backup. Restored resources get
+// a new ID.
+type RestoredResource struct {
+	Kind  ResourceType `json:"kind"`
+	Name  string       `json:"name"`
+	OldID ID           `json:"oldID"`
+	NewID ID           `json:"newID"`
+}
+
+// RestoreConflict is a resource of the backup that was not restored.
+type RestoreConflict struct {
+	Kind   ResourceType `json:"kind"`
+	Name   string       `json:"name"`
+	ID     ID           `json:"id"`
+	Reason string       `json:"reason"`
+}
+
 // Manifest lists the KV and shard file information contained in the backup.
 type Manifest struct {
 	KV    ManifestKVEntry `json:"kv"`
 	Files []ManifestEntry `json:"files"`
 
+	// Time is when the backup was started.
+	Time time.Time `json:"time"`
+
+	// Parent is the file name of the manifest of the previous backup this
+	// backup is based on. It is only set for incremental backups, whose files
+	// contain the shard data changed since the Time of the parent backup.
+	Parent string `json:"parent,omitempty"`
+
+	// Encryption is set if the files of the backup are encrypted.
+	Encryption *ManifestEncryption `json:"encryption,omitempty"`
+
 	// These fields are only set if filtering options are set on the CLI.
 	OrganizationID string `json:"organizationID,omitempty"`
 	BucketID       string `json:"bucketID,omitempty"`
 }
 
+// ManifestEncryption describes how the files of a backup are encrypted.
+type ManifestEncryption struct {
+	// Cipher is the cipher the files are encrypted with.
+	Cipher string `json:"cipher"`
+
+	// KDF is the function deriving the key from the passphrase or key file,
+	// with Salt.
+	KDF  string `json:"kdf"`
+	Salt []byte `json:"salt"`
+}
+
 // ManifestEntry contains the data information for a backed up shard.
 type ManifestEntry struct {
 	OrganizationID   string    `json:"organizationID"`
@@ -51,12 +131,18 @@ type ManifestEntry struct {
 	FileName         string    `json:"fileName"`
 	Size             int64     `json:"size"`
 	LastModified     time.Time `json:"lastModified"`
+
+	// SHA256 is the hex-encoded SHA-256 checksum of the backup file.
+	SHA256 string `json:"sha256,omitempty"`
 }
 
 // ManifestKVEntry contains the KV store information for a backup.
 type ManifestKVEntry struct {
 	FileName string `json:"fileName"`
 	Size     int64  `json:"size"`
+
+	// SHA256 is the hex-encoded SHA-256 checksum of the backup file.
+	SHA256 string `json:"sha256,omitempty"`
 }
 
 // Size returns the size of the manifest.
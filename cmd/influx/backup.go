@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -29,14 +30,22 @@ type cmdBackupBuilder struct {
 	genericCLIOpts
 	*globalFlags
 
-	bucketID   string
-	bucketName string
-	org        organization
-	path       string
+	bucketID    string
+	bucketName  string
+	org         organization
+	path        string
+	incremental bool
+	keyFlags    backupKeyFlags
 
 	manifest influxdb.Manifest
 	baseName string
 
+	// key is the key the backup files are encrypted with, if set.
+	key []byte
+
+	// since is the time of the parent of an incremental backup.
+	since time.Time
+
 	backupService *http.BackupService
 	kvStore       *bolt.KVStore
 	kvService     *kv.Service
@@ -58,6 +67,8 @@ func (b *cmdBackupBuilder) cmdBackup() *cobra.Command {
 	b.org.register(b.viper, cmd, true)
 	cmd.Flags().StringVar(&b.bucketID, "bucket-id", "", "The ID of the bucket to backup")
 	cmd.Flags().StringVarP(&b.bucketName, "bucket", "b", "", "The name of the bucket to backup")
+	cmd.Flags().BoolVar(&b.incremental, "incremental", false, "Only backup the shard data changed since the latest backup in the directory")
+	b.keyFlags.register(cmd, "to encrypt the backup files with")
 	cmd.Use = "backup [flags] path"
 	cmd.Args = func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
@@ -75,12 +86,22 @@ Backs up InfluxDB to a directory.
 Examples:
 	# backup all data
 	influx backup /path/to/backup
+
+	# backup the data changed since the latest backup in the directory
+	influx backup --incremental /path/to/backup
+
+	# backup all data, encrypted with the content of a key file
+	influx backup --keyfile /path/to/keyfile /path/to/backup
+
+	# verify the files of the backups in a directory
+	influx backup verify /path/to/backup
 `
+	cmd.AddCommand(b.cmdVerify())
 	return cmd
 }
 
 func (b *cmdBackupBuilder) manifestPath() string {
-	return fmt.Sprintf("%s.manifest", b.baseName)
+	return b.baseName + manifestExt
 }
 
 func (b *cmdBackupBuilder) kvPath() string {
@@ -101,13 +122,29 @@ func (b *cmdBackupBuilder) backupRunE(cmd *cobra.Command, args []string) (err er
 	}
 
 	// Determine a base
-	b.baseName = time.Now().UTC().Format(influxdb.BackupFilenamePattern)
+	b.manifest.Time = time.Now().UTC()
+	b.baseName = b.manifest.Time.Format(influxdb.BackupFilenamePattern)
 
 	// Ensure directory exsits.
 	if err := os.MkdirAll(b.path, 0777); err != nil {
 		return err
 	}
 
+	if b.incremental {
+		if err := b.loadParentManifest(); err != nil {
+			return err
+		}
+	}
+
+	secret, err := b.keyFlags.secret()
+	if err != nil {
+		return err
+	} else if secret != nil {
+		if b.manifest.Encryption, b.key, err = newBackupEncryption(secret); err != nil {
+			return err
+		}
+	}
+
 	ac := flags.config()
 	b.backupService = &http.BackupService{
 		Addr:               ac.Host,
@@ -116,20 +153,23 @@ func (b *cmdBackupBuilder) backupRunE(cmd *cobra.Command, args []string) (err er
 	}
 
 	// Back up Bolt database to file.
-	if err := b.backupKVStore(ctx); err != nil {
+	kvPath, err := b.backupKVStore(ctx)
+	if err != nil {
 		return err
+	} else if b.key != nil {
+		defer os.Remove(kvPath)
 	}
 
 	// Open bolt DB.
 	boltClient := bolt.NewClient(b.logger)
-	boltClient.Path = filepath.Join(b.path, b.kvPath())
+	boltClient.Path = kvPath
 	if err := boltClient.Open(ctx); err != nil {
 		return err
 	}
 	defer boltClient.Close()
 
 	// Open meta store so we can iterate over meta data.
-	b.kvStore = bolt.NewKVStore(b.logger, filepath.Join(b.path, b.kvPath()))
+	b.kvStore = bolt.NewKVStore(b.logger, kvPath)
 	b.kvStore.WithDB(boltClient.DB())
 
 	tenantStore := tenant.NewStore(b.kvStore)
@@ -156,38 +196,76 @@ func (b *cmdBackupBuilder) backupRunE(cmd *cobra.Command, args []string) (err er
 	return nil
 }
 
-// backupKVStore streams the bolt KV file to a file at path.
-func (b *cmdBackupBuilder) backupKVStore(ctx context.Context) error {
+// loadParentManifest bases the backup on the most recent backup in the
+// directory, so that only the shard data changed since then is backed up.
+// The metadata is always backed up in full.
+func (b *cmdBackupBuilder) loadParentManifest() error {
+	manifests, err := readManifests(b.path)
+	if err != nil {
+		return err
+	}
+
+	parent := latestManifest(manifests, time.Time{})
+	if parent == "" {
+		b.logger.Info("No previous backup found, performing a full backup")
+		return nil
+	}
+
+	b.manifest.Parent = parent
+	b.since = manifests[parent].Time
+	b.logger.Info("Performing incremental backup", zap.String("parent", parent), zap.Time("since", b.since))
+	return nil
+}
+
+// backupKVStore streams the bolt KV file to a file at path. It returns the
+// path of the bolt file to read the metadata from: the backup file itself, or
+// a temporary plaintext copy of it if the backup is encrypted, which the caller
+// removes once done.
+func (b *cmdBackupBuilder) backupKVStore(ctx context.Context) (string, error) {
 	path := filepath.Join(b.path, b.kvPath())
 	b.logger.Info("Backing up KV store", zap.String("path", b.kvPath()))
 
 	// Open writer to output file.
-	f, err := os.Create(path)
+	f, err := createBackupFile(path, b.key)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer f.Close()
 
+	localPath, w := path, io.Writer(f)
+	if b.key != nil {
+		tmp, err := ioutil.TempFile("", "influx-backup")
+		if err != nil {
+			return "", err
+		}
+		defer tmp.Close()
+		localPath, w = tmp.Name(), io.MultiWriter(f, tmp)
+	}
+
 	// Stream bolt file from server, sync, and ensure file closes correctly.
-	if err := b.backupService.BackupKVStore(ctx, f); err != nil {
-		return err
-	} else if err := f.Sync(); err != nil {
-		return err
-	} else if err := f.Close(); err != nil {
-		return err
+	err = b.backupService.BackupKVStore(ctx, w)
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		if localPath != path {
+			os.Remove(localPath)
+		}
+		return "", err
 	}
 
 	// Lookup file size.
 	fi, err := os.Stat(path)
 	if err != nil {
-		return err
+		return "", err
 	}
 	b.manifest.KV = influxdb.ManifestKVEntry{
 		FileName: b.kvPath(),
 		Size:     fi.Size(),
+		SHA256:   f.SHA256(),
 	}
 
-	return nil
+	return localPath, nil
 }
 
 func (b *cmdBackupBuilder) backupOrganizations(ctx context.Context) (err error) {
@@ -279,7 +357,7 @@ func (b *cmdBackupBuilder) backupShard(ctx context.Context, org *influxdb.Organi
 	b.logger.Info("Backing up shard", zap.Uint64("id", shardID), zap.String("path", b.shardPath(shardID)))
 
 	// Open writer to output file.
-	f, err := os.Create(path)
+	f, err := createBackupFile(path, b.key)
 	if err != nil {
 		return err
 	}
@@ -290,12 +368,10 @@ func (b *cmdBackupBuilder) backupShard(ctx context.Context, org *influxdb.Organi
 	defer gw.Close()
 
 	// Stream file from server, sync, and ensure file closes correctly.
-	if err := b.backupService.BackupShard(ctx, gw, shardID, time.Time{}); err != nil {
+	if err := b.backupService.BackupShard(ctx, gw, shardID, b.since); err != nil {
 		return err
 	} else if err := gw.Close(); err != nil {
 		return err
-	} else if err := f.Sync(); err != nil {
-		return err
 	} else if err := f.Close(); err != nil {
 		return err
 	}
@@ -316,6 +392,7 @@ func (b *cmdBackupBuilder) backupShard(ctx context.Context, org *influxdb.Organi
 		FileName:         b.shardPath(shardID),
 		Size:             fi.Size(),
 		LastModified:     fi.ModTime().UTC(),
+		SHA256:           f.SHA256(),
 	})
 
 	return nil
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/encrypt"
+	"github.com/spf13/cobra"
+)
+
+const (
+	backupCipher = "aes-256-gcm"
+	backupKDF    = "scrypt"
+)
+
+// backupKeyFlags are the flags providing the secret the files of a backup are
+// encrypted with.
+type backupKeyFlags struct {
+	passphrase string
+	keyFile    string
+}
+
+func (f *backupKeyFlags) register(cmd *cobra.Command, usage string) {
+	cmd.Flags().StringVar(&f.passphrase, "passphrase", "", "Passphrase "+usage)
+	cmd.Flags().StringVar(&f.keyFile, "keyfile", "", "Path to a key file "+usage)
+}
+
+// secret returns the passphrase or the content of the key file, or nil if
+// neither is specified.
+func (f *backupKeyFlags) secret() ([]byte, error) {
+	switch {
+	case f.passphrase != "" && f.keyFile != "":
+		return nil, errors.New("must specify only one of passphrase or key file")
+	case f.passphrase != "":
+		return []byte(f.passphrase), nil
+	case f.keyFile != "":
+		buf, err := ioutil.ReadFile(f.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read key file: %w", err)
+		} else if len(buf) == 0 {
+			return nil, fmt.Errorf("key file %s is empty", f.keyFile)
+		}
+		return buf, nil
+	}
+	return nil, nil
+}
+
+// newBackupEncryption returns the encryption of a new backup whose files are
+// encrypted with secret, and its key.
+func newBackupEncryption(secret []byte) (*influxdb.ManifestEncryption, []byte, error) {
+	salt, err := encrypt.NewSalt()
+	if err != nil {
+		return nil, nil, err
+	}
+	enc := &influxdb.ManifestEncryption{
+		Cipher: backupCipher,
+		KDF:    backupKDF,
+		Salt:   salt,
+	}
+	key, err := backupKey(enc, secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	return enc, key, nil
+}
+
+// backupKey returns the key the files of a backup encrypted with enc are
+// encrypted with, or nil if the backup is not encrypted.
+func backupKey(enc *influxdb.ManifestEncryption, secret []byte) ([]byte, error) {
+	if enc == nil {
+		return nil, nil
+	} else if enc.Cipher != backupCipher || enc.KDF != backupKDF {
+		return nil, fmt.Errorf("unsupported backup encryption %s with %s", enc.Cipher, enc.KDF)
+	} else if secret == nil {
+		return nil, errors.New("backup is encrypted, must specify its passphrase or key file")
+	}
+	return encrypt.DeriveKey(secret, enc.Salt)
+}
+
+// backupFile is a file of a backup being written. The data written to it is
+// encrypted if it has a key, and its checksum is computed along the way.
+type backupFile struct {
+	f      *os.File
+	hash   hash.Hash
+	enc    *encrypt.Writer
+	w      io.Writer
+	closed bool
+}
+
+// createBackupFile creates the backup file at path, encrypted with key if not nil.
+func createBackupFile(path string, key []byte) (*backupFile, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	bf := &backupFile{f: f, hash: sha256.New()}
+	bf.w = io.MultiWriter(f, bf.hash)
+	if key != nil {
+		if bf.enc, err = encrypt.NewWriter(bf.w, key); err != nil {
+			f.Close()
+			return nil, err
+		}
+		bf.w = bf.enc
+	}
+	return bf, nil
+}
+
+func (f *backupFile) Write(p []byte) (int, error) {
+	return f.w.Write(p)
+}
+
+// Close writes any remaining data, syncs and closes the file. It is a no-op
+// on a closed file, so that it can be deferred.
+func (f *backupFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+
+	if f.enc != nil {
+		if err := f.enc.Close(); err != nil {
+			f.f.Close()
+			return err
+		}
+	}
+	if err := f.f.Sync(); err != nil {
+		f.f.Close()
+		return err
+	}
+	return f.f.Close()
+}
+
+// SHA256 returns the hex-encoded checksum of the file, once closed.
+func (f *backupFile) SHA256() string {
+	return hex.EncodeToString(f.hash.Sum(nil))
+}
+
+// fileSHA256 returns the hex-encoded SHA-256 checksum of the file at path.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// openBackupFile opens a file of a backup for reading, decrypting it with key
+// if not nil.
+func openBackupFile(path string, key []byte) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	} else if key == nil {
+		return f, nil
+	}
+
+	r, err := encrypt.NewReader(f, key)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("decrypt %s: %w", path, err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
+}
+
+// decryptBackupFile decrypts the file of a backup at path into a temporary
+// file, and returns its path. The caller removes it once done with it.
+func decryptBackupFile(path string, key []byte) (string, error) {
+	r, err := openBackupFile(path, key)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	f, err := ioutil.TempFile("", "influx-restore")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("decrypt %s: %w", path, err)
+	} else if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
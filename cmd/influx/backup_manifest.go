@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+const manifestExt = ".manifest"
+
+// readManifests reads the manifest files of the backup directory at path,
+// keyed by file name. Manifests which do not record the time of their backup
+// get it from their file name.
+func readManifests(path string) (map[string]*influxdb.Manifest, error) {
+	filenames, err := filepath.Glob(filepath.Join(path, "*"+manifestExt))
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make(map[string]*influxdb.Manifest, len(filenames))
+	for _, filename := range filenames {
+		// Skip file if it is a directory.
+		if fi, err := os.Stat(filename); err != nil {
+			return nil, err
+		} else if fi.IsDir() {
+			continue
+		}
+
+		// Read manifest file for backup.
+		var manifest influxdb.Manifest
+		if buf, err := ioutil.ReadFile(filename); err != nil {
+			return nil, err
+		} else if err := json.Unmarshal(buf, &manifest); err != nil {
+			return nil, fmt.Errorf("read manifest %s: %v", filepath.Base(filename), err)
+		}
+
+		name := filepath.Base(filename)
+		if manifest.Time.IsZero() {
+			t, err := time.Parse(influxdb.BackupFilenamePattern, strings.TrimSuffix(name, manifestExt))
+			if err != nil {
+				return nil, fmt.Errorf("read manifest %s: unknown backup time", name)
+			}
+			manifest.Time = t
+		}
+		manifests[name] = &manifest
+	}
+	return manifests, nil
+}
+
+// latestManifest returns the file name of the most recent backup started at
+// or before asOf, or of the most recent backup if asOf is zero. It returns an
+// empty string if there is no such backup.
+func latestManifest(manifests map[string]*influxdb.Manifest, asOf time.Time) string {
+	var latest string
+	for name, m := range manifests {
+		if !asOf.IsZero() && m.Time.After(asOf) {
+			continue
+		}
+		if latest == "" || m.Time.After(manifests[latest].Time) ||
+			(m.Time.Equal(manifests[latest].Time) && name > latest) {
+			latest = name
+		}
+	}
+	return latest
+}
+
+// manifestChain returns the manifests needed to restore the backup named name,
+// from its full backup to the backup itself.
+func manifestChain(manifests map[string]*influxdb.Manifest, name string) ([]*influxdb.Manifest, error) {
+	var chain []*influxdb.Manifest
+	seen := make(map[string]bool)
+	for name != "" {
+		m, ok := manifests[name]
+		if !ok {
+			return nil, fmt.Errorf("manifest %s of incremental backup not found", name)
+		} else if seen[name] {
+			return nil, fmt.Errorf("manifest %s is its own ancestor", name)
+		}
+		seen[name] = true
+
+		chain = append(chain, m)
+		name = m.Parent
+	}
+
+	// Order from the full backup to the most recent increment.
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestManifest(t *testing.T, dir, name string, m influxdb.Manifest) {
+	t.Helper()
+	buf, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), buf, 0600))
+}
+
+func TestReadManifests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influx-backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	t0 := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	writeTestManifest(t, dir, "20201001T000000Z.manifest", influxdb.Manifest{KV: influxdb.ManifestKVEntry{FileName: "20201001T000000Z.bolt"}})
+	writeTestManifest(t, dir, "20201002T000000Z.manifest", influxdb.Manifest{Time: t0.Add(24*time.Hour + time.Millisecond), Parent: "20201001T000000Z.manifest"})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "20201001T000000Z.bolt"), nil, 0600))
+
+	manifests, err := readManifests(dir)
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+
+	// The time of a manifest which does not record it is read from its file name.
+	require.Equal(t, t0, manifests["20201001T000000Z.manifest"].Time)
+	require.Equal(t, t0.Add(24*time.Hour+time.Millisecond), manifests["20201002T000000Z.manifest"].Time)
+	require.Equal(t, "20201001T000000Z.manifest", manifests["20201002T000000Z.manifest"].Parent)
+}
+
+func TestLatestManifest(t *testing.T) {
+	t0 := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	manifests := map[string]*influxdb.Manifest{
+		"a.manifest": {Time: t0},
+		"b.manifest": {Time: t0.Add(time.Hour), Parent: "a.manifest"},
+		"c.manifest": {Time: t0.Add(2 * time.Hour), Parent: "b.manifest"},
+	}
+
+	for _, tt := range []struct {
+		name string
+		asOf time.Time
+		want string
+	}{
+		{name: "latest", want: "c.manifest"},
+		{name: "at backup time", asOf: t0.Add(time.Hour), want: "b.manifest"},
+		{name: "between backups", asOf: t0.Add(90 * time.Minute), want: "b.manifest"},
+		{name: "before first backup", asOf: t0.Add(-time.Minute), want: ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, latestManifest(manifests, tt.asOf))
+		})
+	}
+}
+
+func TestManifestChain(t *testing.T) {
+	full := &influxdb.Manifest{KV: influxdb.ManifestKVEntry{FileName: "a.bolt"}}
+	inc1 := &influxdb.Manifest{KV: influxdb.ManifestKVEntry{FileName: "b.bolt"}, Parent: "a.manifest"}
+	inc2 := &influxdb.Manifest{KV: influxdb.ManifestKVEntry{FileName: "c.bolt"}, Parent: "b.manifest"}
+	manifests := map[string]*influxdb.Manifest{
+		"a.manifest": full,
+		"b.manifest": inc1,
+		"c.manifest": inc2,
+		"d.manifest": {Parent: "x.manifest"},
+		"e.manifest": {Parent: "f.manifest"},
+		"f.manifest": {Parent: "e.manifest"},
+	}
+
+	chain, err := manifestChain(manifests, "c.manifest")
+	require.NoError(t, err)
+	require.Equal(t, []*influxdb.Manifest{full, inc1, inc2}, chain)
+
+	chain, err = manifestChain(manifests, "a.manifest")
+	require.NoError(t, err)
+	require.Equal(t, []*influxdb.Manifest{full}, chain)
+
+	_, err = manifestChain(manifests, "d.manifest")
+	require.EqualError(t, err, "manifest x.manifest of incremental backup not found")
+
+	_, err = manifestChain(manifests, "e.manifest")
+	require.EqualError(t, err, "manifest e.manifest is its own ancestor")
+}
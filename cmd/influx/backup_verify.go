@@ -0,0 +1,292 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Statuses of the files of a verified backup.
+const (
+	backupFileOK         = "ok"
+	backupFileUnverified = "unverified"
+	backupFileFailed     = "failed"
+)
+
+// backupFileCheck is the result of the verification of a file of a backup.
+type backupFileCheck struct {
+	Manifest string `json:"manifest"`
+	File     string `json:"file"`
+	Status   string `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+func (b *cmdBackupBuilder) cmdVerify() *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd("verify [flags] path", b.verifyRunE, false)
+	b.genericCLIOpts.registerPrintOptions(cmd)
+	b.keyFlags.register(cmd, "the backup files are encrypted with")
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("must specify path to backup directory")
+		} else if len(args) > 1 {
+			return fmt.Errorf("too many args specified")
+		}
+		b.path = args[0]
+		return nil
+	}
+	cmd.Short = "Verify the files of the backups in a directory"
+	cmd.Long = `
+Verifies the files of the backups in a directory, without a running server.
+
+The size and checksum of every file are checked against its manifest, the KV
+store is opened and checked, and every block of the TSM files of the shards is
+read and checked. The content of encrypted backups is only checked if their
+passphrase or key file is specified.
+
+Examples:
+	# verify the backups in a directory
+	influx backup verify /path/to/backup
+
+	# verify the backups in a directory, encrypted with a passphrase
+	influx backup verify --passphrase mysecret /path/to/backup
+`
+	return cmd
+}
+
+func (b *cmdBackupBuilder) verifyRunE(cmd *cobra.Command, args []string) error {
+	secret, err := b.keyFlags.secret()
+	if err != nil {
+		return err
+	}
+
+	manifests, err := readManifests(b.path)
+	if err != nil {
+		return err
+	} else if len(manifests) == 0 {
+		return fmt.Errorf("no backup manifests found in %s", b.path)
+	}
+
+	names := make([]string, 0, len(manifests))
+	for name := range manifests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var checks []backupFileCheck
+	for _, name := range names {
+		manifest := manifests[name]
+
+		// The content of an encrypted backup is only verified with its key.
+		var key []byte
+		if manifest.Encryption == nil || secret != nil {
+			if key, err = backupKey(manifest.Encryption, secret); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		encrypted := manifest.Encryption != nil
+
+		check := verifyBackupFile(b.path, manifest.KV.FileName, manifest.KV.Size, manifest.KV.SHA256, encrypted, key, verifyBoltFile)
+		check.Manifest = name
+		checks = append(checks, check)
+
+		for _, f := range manifest.Files {
+			check := verifyBackupFile(b.path, f.FileName, f.Size, f.SHA256, encrypted, key, verifyShardFile)
+			check.Manifest = name
+			checks = append(checks, check)
+		}
+	}
+
+	if err := b.printBackupFileChecks(checks); err != nil {
+		return err
+	}
+
+	var failed int
+	for _, check := range checks {
+		if check.Status == backupFileFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d backup files failed verification", failed, len(checks))
+	}
+	return nil
+}
+
+func (b *cmdBackupBuilder) printBackupFileChecks(checks []backupFileCheck) error {
+	if b.json {
+		return b.writeJSON(checks)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.WriteHeaders("Manifest", "File", "Status", "Detail")
+	for _, check := range checks {
+		w.Write(map[string]interface{}{
+			"Manifest": check.Manifest,
+			"File":     check.File,
+			"Status":   check.Status,
+			"Detail":   check.Detail,
+		})
+	}
+	return nil
+}
+
+// verifyBackupFile verifies the size and checksum of the file of a backup, and
+// its content with verifyContent. The content of an encrypted file is left
+// unverified without its key.
+func verifyBackupFile(dir, name string, size int64, sum string, encrypted bool, key []byte, verifyContent func(path string, key []byte) error) backupFileCheck {
+	check := backupFileCheck{File: name}
+	fail := func(err error) backupFileCheck {
+		check.Status, check.Detail = backupFileFailed, err.Error()
+		return check
+	}
+
+	path := filepath.Join(dir, name)
+	if err := checkBackupFile(path, size, sum); err != nil {
+		return fail(err)
+	}
+
+	if encrypted && key == nil {
+		check.Status, check.Detail = backupFileUnverified, "encrypted, content not verified"
+		return check
+	}
+	if err := verifyContent(path, key); err != nil {
+		return fail(err)
+	}
+
+	check.Status = backupFileOK
+	if sum == "" {
+		check.Detail = "no checksum recorded"
+	}
+	return check
+}
+
+// checkBackupFile returns an error if the file of a backup at path does not
+// have the size and checksum recorded in its manifest. Files backed up without
+// a checksum are only checked for their size.
+func checkBackupFile(path string, size int64, sum string) error {
+	if fi, err := os.Stat(path); os.IsNotExist(err) {
+		return errors.New("file not found")
+	} else if err != nil {
+		return err
+	} else if fi.Size() != size {
+		return fmt.Errorf("size is %d bytes, expected %d", fi.Size(), size)
+	}
+
+	if sum == "" {
+		return nil
+	}
+	if got, err := fileSHA256(path); err != nil {
+		return err
+	} else if got != sum {
+		return fmt.Errorf("checksum mismatch: got %s, expected %s", got, sum)
+	}
+	return nil
+}
+
+// verifyBoltFile verifies that the KV store backed up to path opens and is consistent.
+func verifyBoltFile(path string, key []byte) error {
+	if key != nil {
+		tmpPath, err := decryptBackupFile(path, key)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmpPath)
+		path = tmpPath
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("open bolt file: %w", err)
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		for err := range tx.Check() {
+			return fmt.Errorf("check bolt file: %w", err)
+		}
+		return nil
+	})
+}
+
+// verifyShardFile verifies that the shard archive backed up to path reads, and
+// that the blocks of its TSM files are intact.
+func verifyShardFile(path string, key []byte) error {
+	f, err := openBackupFile(path, key)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if filepath.Ext(hdr.Name) != "."+tsm1.TSMFileExtension {
+			if _, err := io.Copy(ioutil.Discard, tr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := verifyTSMFile(tr); err != nil {
+			return fmt.Errorf("%s: %w", hdr.Name, err)
+		}
+	}
+}
+
+// verifyTSMFile reads the TSM file read from r and verifies the checksums of its blocks.
+func verifyTSMFile(r io.Reader) error {
+	f, err := ioutil.TempFile("", "influx-verify")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	} else if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	itr := reader.BlockIterator()
+	for i := 0; itr.Next(); i++ {
+		key, _, _, _, checksum, buf, err := itr.Read()
+		if err != nil {
+			return fmt.Errorf("read block %d of key %q: %w", i, key, err)
+		} else if expected := crc32.ChecksumIEEE(buf); checksum != expected {
+			return fmt.Errorf("block %d of key %q: got checksum %d, expected %d", i, key, checksum, expected)
+		}
+	}
+	return itr.Err()
+}
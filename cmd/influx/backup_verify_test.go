@@ -0,0 +1,129 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// writeTestShardFile writes a backup file of a shard holding a TSM file,
+// encrypted with key if not nil, and returns its size and checksum.
+func writeTestShardFile(t *testing.T, path string, key []byte) (int64, string) {
+	t.Helper()
+
+	var tsm bytes.Buffer
+	w, err := tsm1.NewTSMWriter(&tsm)
+	require.NoError(t, err)
+	require.NoError(t, w.Write([]byte("cpu,host=a#!~#value"), tsm1.Values{tsm1.NewValue(0, 1.0), tsm1.NewValue(1, 2.0)}))
+	require.NoError(t, w.WriteIndex())
+	require.NoError(t, w.Close())
+
+	f, err := createBackupFile(path, key)
+	require.NoError(t, err)
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "autogen/1/000000001-000000001.tsm", Mode: 0600, Size: int64(tsm.Len())}))
+	_, err = tw.Write(tsm.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	require.NoError(t, f.Close())
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	return fi.Size(), f.SHA256()
+}
+
+func TestVerifyBackupFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influx-backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, key, err := newBackupEncryption([]byte("passphrase"))
+	require.NoError(t, err)
+
+	t.Run("plain", func(t *testing.T) {
+		size, sum := writeTestShardFile(t, filepath.Join(dir, "plain.tar.gz"), nil)
+		sum2, err := fileSHA256(filepath.Join(dir, "plain.tar.gz"))
+		require.NoError(t, err)
+		require.Equal(t, sum, sum2)
+
+		check := verifyBackupFile(dir, "plain.tar.gz", size, sum, false, nil, verifyShardFile)
+		require.Equal(t, backupFileOK, check.Status, check.Detail)
+
+		check = verifyBackupFile(dir, "plain.tar.gz", size+1, sum, false, nil, verifyShardFile)
+		require.Equal(t, backupFileFailed, check.Status)
+
+		check = verifyBackupFile(dir, "missing.tar.gz", size, sum, false, nil, verifyShardFile)
+		require.Equal(t, backupFileFailed, check.Status)
+		require.Equal(t, "file not found", check.Detail)
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		size, sum := writeTestShardFile(t, filepath.Join(dir, "enc.tar.gz"), key)
+
+		check := verifyBackupFile(dir, "enc.tar.gz", size, sum, true, key, verifyShardFile)
+		require.Equal(t, backupFileOK, check.Status, check.Detail)
+
+		// Without the key, only the checksum is verified.
+		check = verifyBackupFile(dir, "enc.tar.gz", size, sum, true, nil, verifyShardFile)
+		require.Equal(t, backupFileUnverified, check.Status)
+
+		// Corrupted content fails the checksum, or decryption if none is recorded.
+		buf, err := ioutil.ReadFile(filepath.Join(dir, "enc.tar.gz"))
+		require.NoError(t, err)
+		buf[len(buf)/2] ^= 1
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "enc.tar.gz"), buf, 0600))
+
+		check = verifyBackupFile(dir, "enc.tar.gz", size, sum, true, key, verifyShardFile)
+		require.Equal(t, backupFileFailed, check.Status)
+		require.Contains(t, check.Detail, "checksum mismatch")
+
+		check = verifyBackupFile(dir, "enc.tar.gz", size, "", true, key, verifyShardFile)
+		require.Equal(t, backupFileFailed, check.Status)
+	})
+}
+
+func TestRestoreLoadIncremental_Checksums(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influx-backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	kv := []byte("bolt")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "20201001T000000Z.bolt"), kv, 0600))
+	kvSum, err := fileSHA256(filepath.Join(dir, "20201001T000000Z.bolt"))
+	require.NoError(t, err)
+	size, sum := writeTestShardFile(t, filepath.Join(dir, "20201001T000000Z.s1.tar.gz"), nil)
+
+	writeTestManifest(t, dir, "20201001T000000Z.manifest", influxdb.Manifest{
+		KV:    influxdb.ManifestKVEntry{FileName: "20201001T000000Z.bolt", Size: int64(len(kv)), SHA256: kvSum},
+		Files: []influxdb.ManifestEntry{{ShardID: 1, FileName: "20201001T000000Z.s1.tar.gz", Size: size, SHA256: sum}},
+	})
+
+	load := func() error {
+		b := newCmdRestoreBuilder(&globalFlags{}, genericCLIOpts{})
+		b.path, b.logger = dir, zap.NewNop()
+		return b.loadIncremental(time.Time{}, nil)
+	}
+	require.NoError(t, load())
+
+	// Nothing is restored from a backup with a damaged file.
+	buf, err := ioutil.ReadFile(filepath.Join(dir, "20201001T000000Z.s1.tar.gz"))
+	require.NoError(t, err)
+	buf[len(buf)/2] ^= 1
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "20201001T000000Z.s1.tar.gz"), buf, 0600))
+
+	err = load()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "checksum mismatch")
+}
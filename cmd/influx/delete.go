@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/http"
 	"github.com/influxdata/influxdb/v2/kit/signals"
 	"github.com/spf13/cobra"
@@ -21,14 +22,24 @@ type cmdDeleteBuilder struct {
 	genericCLIOpts
 	*globalFlags
 
-	flags http.DeleteRequest
+	flags       http.DeleteRequest
+	async       bool
+	id          string
+	hideHeaders bool
+	json        bool
 }
 
 func (b *cmdDeleteBuilder) cmd() *cobra.Command {
 	cmd := b.newCmd("delete", b.fluxDeleteF)
 	cmd.Short = "Delete points from influxDB"
 	cmd.Long = `Delete points from influxDB, by specify start, end time
-	and a sql like predicate string.`
+	and a sql like predicate string. With --async, the points are deleted in
+	the background by a delete job, which can be followed with the status
+	command and stopped with the cancel command.`
+	cmd.AddCommand(
+		b.cmdStatus(),
+		b.cmdCancel(),
+	)
 
 	opts := flagOpts{
 		{
@@ -63,6 +74,8 @@ func (b *cmdDeleteBuilder) cmd() *cobra.Command {
 	cmd.PersistentFlags().StringVar(&b.flags.Start, "start", "", "the start time in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
 	cmd.PersistentFlags().StringVar(&b.flags.Stop, "stop", "", "the stop time in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
 	cmd.PersistentFlags().StringVarP(&b.flags.Predicate, "predicate", "p", "", "sql like predicate string, exp 'tag1=\"v1\" and (tag2=123)'")
+	cmd.Flags().BoolVar(&b.async, "async", false, "Delete the points in the background and print the started delete job")
+	registerPrintOptions(b.viper, cmd, &b.hideHeaders, &b.json)
 
 	return cmd
 }
@@ -93,6 +106,14 @@ func (b *cmdDeleteBuilder) fluxDeleteF(cmd *cobra.Command, args []string) error
 	}
 
 	ctx := signals.WithStandardSignals(context.Background())
+	if b.async {
+		job, err := s.StartDeleteJob(ctx, b.flags)
+		if err != nil {
+			return fmt.Errorf("failed to start delete job: %v", err)
+		}
+		return b.printDeleteJobs(deleteJobPrintOpt{job: job})
+	}
+
 	if err := s.DeleteBucketRangePredicate(ctx, b.flags); err != nil && err != context.Canceled {
 		return fmt.Errorf("failed to delete data: %v", err)
 	}
@@ -100,6 +121,136 @@ func (b *cmdDeleteBuilder) fluxDeleteF(cmd *cobra.Command, args []string) error
 	return nil
 }
 
+func (b *cmdDeleteBuilder) cmdStatus() *cobra.Command {
+	cmd := b.newCmd("status", b.statusRunEFn)
+	cmd.Short = "List the recent delete jobs, or show the progress of one"
+	cmd.Long = `List the recent delete jobs of the org-id and bucket-id, if
+	given, or show the progress of the delete job with the given ID.`
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The ID of the delete job")
+	registerPrintOptions(b.viper, cmd, &b.hideHeaders, &b.json)
+
+	return cmd
+}
+
+func (b *cmdDeleteBuilder) statusRunEFn(cmd *cobra.Command, args []string) error {
+	s, err := newDeleteJobService()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if b.id != "" {
+		id, err := influxdb.IDFromString(b.id)
+		if err != nil {
+			return fmt.Errorf("failed to decode delete job id %q: %v", b.id, err)
+		}
+		job, err := s.FindDeleteJobByID(ctx, *id)
+		if err != nil {
+			return fmt.Errorf("failed to find delete job: %v", err)
+		}
+		return b.printDeleteJobs(deleteJobPrintOpt{job: job})
+	}
+
+	if b.flags.Org != "" || b.flags.Bucket != "" {
+		return fmt.Errorf("delete jobs can only be filtered by org-id and bucket-id")
+	}
+
+	var filter influxdb.DeleteJobFilter
+	if b.flags.OrgID != "" {
+		if filter.OrgID, err = influxdb.IDFromString(b.flags.OrgID); err != nil {
+			return fmt.Errorf("failed to decode org id %q: %v", b.flags.OrgID, err)
+		}
+	}
+	if b.flags.BucketID != "" {
+		if filter.BucketID, err = influxdb.IDFromString(b.flags.BucketID); err != nil {
+			return fmt.Errorf("failed to decode bucket id %q: %v", b.flags.BucketID, err)
+		}
+	}
+
+	jobs, err := s.FindDeleteJobs(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to find delete jobs: %v", err)
+	}
+	if jobs == nil {
+		jobs = []*influxdb.DeleteJob{}
+	}
+	return b.printDeleteJobs(deleteJobPrintOpt{jobs: jobs})
+}
+
+func (b *cmdDeleteBuilder) cmdCancel() *cobra.Command {
+	cmd := b.newCmd("cancel", b.cancelRunEFn)
+	cmd.Short = "Cancel a running delete job"
+	cmd.Long = `Cancel a running delete job. The points deleted before the job
+	is canceled stay deleted.`
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The ID of the delete job (required)")
+	cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func (b *cmdDeleteBuilder) cancelRunEFn(cmd *cobra.Command, args []string) error {
+	s, err := newDeleteJobService()
+	if err != nil {
+		return err
+	}
+
+	id, err := influxdb.IDFromString(b.id)
+	if err != nil {
+		return fmt.Errorf("failed to decode delete job id %q: %v", b.id, err)
+	}
+	if err := s.CancelDeleteJob(context.Background(), *id); err != nil {
+		return fmt.Errorf("failed to cancel delete job: %v", err)
+	}
+	return nil
+}
+
+type deleteJobPrintOpt struct {
+	job  *influxdb.DeleteJob
+	jobs []*influxdb.DeleteJob
+}
+
+func (b *cmdDeleteBuilder) printDeleteJobs(printOpt deleteJobPrintOpt) error {
+	if b.json {
+		var v interface{} = printOpt.jobs
+		if printOpt.jobs == nil {
+			v = printOpt.job
+		}
+		return b.writeJSON(v)
+	}
+
+	if printOpt.job != nil {
+		printOpt.jobs = append(printOpt.jobs, printOpt.job)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+	w.WriteHeaders("ID", "Bucket ID", "Status", "Started At", "Shards", "Series Deleted", "Errors")
+	for _, job := range printOpt.jobs {
+		w.Write(map[string]interface{}{
+			"ID":             job.ID.String(),
+			"Bucket ID":      job.BucketID.String(),
+			"Status":         job.Status,
+			"Started At":     job.StartedAt,
+			"Shards":         fmt.Sprintf("%d/%d", job.Progress.ShardsDeleted, job.Progress.ShardsTotal),
+			"Series Deleted": job.Progress.SeriesDeleted,
+			"Errors":         len(job.Errors),
+		})
+	}
+	return nil
+}
+
+func newDeleteJobService() (*http.DeleteJobService, error) {
+	client, err := newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return &http.DeleteJobService{Client: client}, nil
+}
+
 func (b *cmdDeleteBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
 	cmd := b.genericCLIOpts.newCmd(use, runE, true)
 	b.globalFlags.registerFlags(b.viper, cmd)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,11 +13,14 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/csv"
 	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/v2"
 	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/tenant"
 	"github.com/spf13/cobra"
 )
 
@@ -26,20 +30,168 @@ var queryFlags struct {
 	raw  bool
 }
 
+type runningQuerySVCsFn func() (influxdb.RunningQueryService, influxdb.OrganizationService, error)
+
 func cmdQuery(f *globalFlags, opts genericCLIOpts) *cobra.Command {
-	cmd := opts.newCmd("query [query literal or -f /path/to/query.flux]", fluxQueryF, true)
+	builder := newCmdQueryBuilder(newRunningQuerySVCs, f, opts)
+	return builder.cmd()
+}
+
+type cmdQueryBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn runningQuerySVCsFn
+
+	id          uint64
+	json        bool
+	hideHeaders bool
+	org         organization
+}
+
+func newCmdQueryBuilder(svcsFn runningQuerySVCsFn, f *globalFlags, opts genericCLIOpts) *cmdQueryBuilder {
+	return &cmdQueryBuilder{
+		genericCLIOpts: opts,
+		globalFlags:    f,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdQueryBuilder) cmd() *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd("query [query literal or -f /path/to/query.flux]", fluxQueryF, true)
 	cmd.Short = "Execute a Flux query"
 	cmd.Long = `Execute a Flux query provided via the first argument or a file or stdin`
 	cmd.Args = cobra.MaximumNArgs(1)
 
-	f.registerFlags(opts.viper, cmd)
-	queryFlags.org.register(opts.viper, cmd, true)
+	b.globalFlags.registerFlags(b.viper, cmd)
+	queryFlags.org.register(b.viper, cmd, true)
 	cmd.Flags().StringVarP(&queryFlags.file, "file", "f", "", "Path to Flux query file")
 	cmd.Flags().BoolVarP(&queryFlags.raw, "raw", "r", false, "Display raw query results")
 
+	cmd.AddCommand(
+		b.cmdList(),
+		b.cmdKill(),
+	)
+
+	return cmd
+}
+
+func (b *cmdQueryBuilder) cmdList() *cobra.Command {
+	cmd := b.newCmd("list", b.cmdListRunEFn)
+	cmd.Short = "List the Flux and InfluxQL queries running in an organization"
+	cmd.Aliases = []string{"find", "ls"}
+
+	b.org.register(b.viper, cmd, false)
+	registerPrintOptions(b.viper, cmd, &b.hideHeaders, &b.json)
+
 	return cmd
 }
 
+func (b *cmdQueryBuilder) cmdListRunEFn(cmd *cobra.Command, _ []string) error {
+	if err := b.org.validOrgFlags(b.globalFlags); err != nil {
+		return err
+	}
+
+	querySVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return err
+	}
+
+	queries, err := querySVC.FindRunningQueries(context.Background(), influxdb.RunningQueryFilter{OrgID: &orgID})
+	if err != nil {
+		return err
+	}
+
+	if b.json {
+		if queries == nil {
+			// guarantee we never return a null value from CLI
+			queries = make([]*influxdb.RunningQuery, 0)
+		}
+		return b.writeJSON(queries)
+	}
+
+	tabW := b.newTabWriter()
+	defer tabW.Flush()
+
+	tabW.HideHeaders(b.hideHeaders)
+
+	tabW.WriteHeaders(
+		"ID",
+		"Language",
+		"Query",
+		"Database",
+		"Duration",
+		"State",
+		"User ID",
+		"Token ID",
+	)
+
+	for _, q := range queries {
+		m := map[string]interface{}{
+			"ID":       q.ID,
+			"Language": q.Language,
+			"Query":    q.Query,
+			"Database": q.Database,
+			"Duration": q.Duration.Round(time.Millisecond).String(),
+			"State":    q.State,
+			"User ID":  "",
+			"Token ID": "",
+		}
+		if q.UserID.Valid() {
+			m["User ID"] = q.UserID.String()
+		}
+		if q.AuthorizationID.Valid() {
+			m["Token ID"] = q.AuthorizationID.String()
+		}
+		tabW.Write(m)
+	}
+
+	return nil
+}
+
+func (b *cmdQueryBuilder) cmdKill() *cobra.Command {
+	cmd := b.newCmd("kill", b.cmdKillRunEFn)
+	cmd.Short = "Kill a running Flux or InfluxQL query"
+
+	cmd.Flags().Uint64Var(&b.id, "id", 0, "The ID of the query to kill (required)")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func (b *cmdQueryBuilder) cmdKillRunEFn(cmd *cobra.Command, _ []string) error {
+	querySVC, _, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	if err := querySVC.KillQuery(context.Background(), b.id); err != nil {
+		return err
+	}
+	fmt.Fprintf(b.w, "Query %d killed\n", b.id)
+	return nil
+}
+
+func (b *cmdQueryBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd(use, runE, true)
+	b.globalFlags.registerFlags(b.viper, cmd)
+	return cmd
+}
+
+func newRunningQuerySVCs() (influxdb.RunningQueryService, influxdb.OrganizationService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orgSvc := &tenant.OrgClientService{Client: httpClient}
+
+	return &ihttp.RunningQueryService{Client: httpClient}, orgSvc, nil
+}
+
 // readFluxQuery returns first argument, file contents or stdin
 func readFluxQuery(args []string, file string) (string, error) {
 	// backward compatibility
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdQuery(t *testing.T) {
+	orgID := influxdb.ID(9000)
+
+	fakeSVCFn := func(svc influxdb.RunningQueryService) runningQuerySVCsFn {
+		return func() (influxdb.RunningQueryService, influxdb.OrganizationService, error) {
+			return svc, &mock.OrganizationService{
+				FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+					return &influxdb.Organization{ID: orgID, Name: "influxdata"}, nil
+				},
+			}, nil
+		}
+	}
+
+	t.Run("list", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			command string
+			flags   []string
+			envVars map[string]string
+		}{
+			{
+				name:    "org id",
+				flags:   []string{"--org-id=" + orgID.String()},
+				envVars: envVarsZeroMap,
+			},
+			{
+				name:    "org",
+				flags:   []string{"--org=influxdata"},
+				envVars: envVarsZeroMap,
+			},
+			{
+				name: "env vars",
+				envVars: map[string]string{
+					"INFLUX_ORG": "influxdata",
+				},
+			},
+			{
+				name:    "ls alias",
+				command: "ls",
+				flags:   []string{"-o=influxdata"},
+				envVars: envVarsZeroMap,
+			},
+			{
+				name:    "find alias",
+				command: "find",
+				flags:   []string{"-o=influxdata"},
+				envVars: envVarsZeroMap,
+			},
+		}
+
+		cmdFn := func() (func(*globalFlags, genericCLIOpts) *cobra.Command, *influxdb.RunningQueryFilter) {
+			var filter influxdb.RunningQueryFilter
+			svc := mock.NewRunningQueryService()
+			svc.FindRunningQueriesFn = func(ctx context.Context, f influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+				filter = f
+				return []*influxdb.RunningQuery{
+					{ID: 1, OrgID: orgID, UserID: 2, AuthorizationID: 3, Language: "influxql", Query: "SELECT * FROM cpu", Database: "db0", Duration: 1500 * time.Millisecond, State: "running"},
+					{ID: 4, OrgID: orgID, Language: "flux", Query: `from(bucket: "b")`, Duration: 1500 * time.Microsecond, State: "executing"},
+				}, nil
+			}
+
+			return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+				builder := newCmdQueryBuilder(fakeSVCFn(svc), g, opt)
+				return builder.cmd()
+			}, &filter
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				defer addEnvVars(t, tt.envVars)()
+
+				buf := new(bytes.Buffer)
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(buf),
+				)
+				nestedCmdFn, filter := cmdFn()
+				cmd := builder.cmd(nestedCmdFn)
+
+				if tt.command == "" {
+					tt.command = "list"
+				}
+
+				cmd.SetArgs(append([]string{"query", tt.command}, tt.flags...))
+
+				require.NoError(t, cmd.Execute())
+				require.NotNil(t, filter.OrgID)
+				assert.Equal(t, orgID, *filter.OrgID)
+
+				lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+				require.Len(t, lines, 3)
+				assert.Equal(t, []string{"ID", "Language", "Query", "Database", "Duration", "State", "User", "ID", "Token", "ID"}, fieldsOf(lines[0]))
+				assert.Equal(t, []string{"1", "influxql", "SELECT", "*", "FROM", "cpu", "db0", "1.5s", "running", influxdb.ID(2).String(), influxdb.ID(3).String()}, fieldsOf(lines[1]))
+				assert.Equal(t, []string{"4", "flux", `from(bucket:`, `"b")`, "2ms", "executing"}, fieldsOf(lines[2]))
+			}
+
+			t.Run(tt.name, fn)
+		}
+
+		t.Run("json", func(t *testing.T) {
+			defer addEnvVars(t, envVarsZeroMap)()
+
+			buf := new(bytes.Buffer)
+			builder := newInfluxCmdBuilder(
+				in(new(bytes.Buffer)),
+				out(buf),
+			)
+			nestedCmdFn, _ := cmdFn()
+			cmd := builder.cmd(nestedCmdFn)
+			cmd.SetArgs([]string{"query", "list", "--org-id=" + orgID.String(), "--json"})
+
+			require.NoError(t, cmd.Execute())
+
+			var queries []map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &queries))
+			require.Len(t, queries, 2)
+			assert.Equal(t, float64(1), queries[0]["id"])
+			assert.Equal(t, "SELECT * FROM cpu", queries[0]["query"])
+			assert.Equal(t, float64(4), queries[1]["id"])
+			assert.Equal(t, "flux", queries[1]["language"])
+		})
+	})
+
+	t.Run("kill", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			flags      []string
+			killErr    error
+			expectedID uint64
+			expected   string
+			expectErr  bool
+		}{
+			{
+				name:       "with id",
+				flags:      []string{"--id=7"},
+				expectedID: 7,
+				expected:   "Query 7 killed\n",
+			},
+			{
+				name:      "missing id",
+				expectErr: true,
+			},
+			{
+				name:       "kill error",
+				flags:      []string{"--id=8"},
+				killErr:    errors.New("no such query id: 8"),
+				expectedID: 8,
+				expectErr:  true,
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				var killed []uint64
+				svc := mock.NewRunningQueryService()
+				svc.KillQueryFn = func(ctx context.Context, id uint64) error {
+					killed = append(killed, id)
+					return tt.killErr
+				}
+
+				buf := new(bytes.Buffer)
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(buf),
+				)
+				cmd := builder.cmd(func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+					return newCmdQueryBuilder(fakeSVCFn(svc), g, opt).cmd()
+				})
+				cmd.SetArgs(append([]string{"query", "kill"}, tt.flags...))
+
+				err := cmd.Execute()
+				if tt.expectErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+					assert.Equal(t, tt.expected, buf.String())
+				}
+
+				if tt.expectedID != 0 {
+					assert.Equal(t, []uint64{tt.expectedID}, killed)
+				} else {
+					assert.Empty(t, killed)
+				}
+			}
+
+			t.Run(tt.name, fn)
+		}
+	})
+}
+
+func fieldsOf(line []byte) []string {
+	var fields []string
+	for _, f := range bytes.Fields(line) {
+		fields = append(fields, string(f))
+	}
+	return fields
+}
@@ -3,13 +3,11 @@ package main
 import (
 	"compress/gzip"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/bolt"
@@ -36,9 +34,18 @@ type cmdRestoreBuilder struct {
 	newOrgName    string
 	org           organization
 	path          string
+	asOf          string
+	resources     []string
+	keyFlags      backupKeyFlags
 
-	kvEntry      *influxdb.ManifestKVEntry
-	shardEntries map[uint64]*influxdb.ManifestEntry
+	kvEntry *influxdb.ManifestKVEntry
+
+	// fileKeys holds the keys of the encrypted backup files, by file name.
+	fileKeys map[string][]byte
+
+	// shardEntries holds the backup files of each shard, from the full
+	// backup to the most recent increment.
+	shardEntries map[uint64][]*influxdb.ManifestEntry
 
 	orgService     *tenant.OrgClientService
 	bucketService  *tenant.BucketClientService
@@ -54,7 +61,8 @@ func newCmdRestoreBuilder(f *globalFlags, opts genericCLIOpts) *cmdRestoreBuilde
 		genericCLIOpts: opts,
 		globalFlags:    f,
 
-		shardEntries: make(map[uint64]*influxdb.ManifestEntry),
+		shardEntries: make(map[uint64][]*influxdb.ManifestEntry),
+		fileKeys:     make(map[string][]byte),
 	}
 }
 
@@ -67,6 +75,9 @@ func (b *cmdRestoreBuilder) cmdRestore() *cobra.Command {
 	cmd.Flags().StringVar(&b.newBucketName, "new-bucket", "", "The name of the bucket to restore to")
 	cmd.Flags().StringVar(&b.newOrgName, "new-org", "", "The name of the organization to restore to")
 	cmd.Flags().StringVar(&b.path, "input", "", "Local backup data path (required)")
+	cmd.Flags().StringVar(&b.asOf, "as-of", "", "Restore the latest backup started at or before this RFC3339 time")
+	b.keyFlags.register(cmd, "the backup files are encrypted with")
+	cmd.Flags().StringSliceVar(&b.resources, "resources", nil, "Only restore these kinds of resources of the organization from the metadata backup: "+joinResourceTypes(influxdb.RestorableResourceTypes))
 	cmd.Use = "restore [flags] path"
 	cmd.Args = func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
@@ -84,6 +95,15 @@ Restore influxdb.
 Examples:
 	# restore all data
 	influx restore /path/to/restore
+
+	# restore the data as of the latest backup started before a point in time
+	influx restore --as-of 2020-10-01T00:00:00Z /path/to/restore
+
+	# restore the dashboards and tasks of an organization into another one
+	influx restore --org my-org --new-org other-org --resources dashboards,tasks /path/to/restore
+
+	# restore all data from a backup encrypted with the content of a key file
+	influx restore --keyfile /path/to/keyfile /path/to/restore
 `
 	return cmd
 }
@@ -102,11 +122,29 @@ func (b *cmdRestoreBuilder) restoreRunE(cmd *cobra.Command, args []string) (err
 		return fmt.Errorf("must specify source org id or name when renaming restored org")
 	} else if b.newBucketName != "" && b.bucketID == "" && b.bucketName == "" {
 		return fmt.Errorf("must specify source bucket id or name when renaming restored bucket")
+	} else if len(b.resources) > 0 && b.org.id == "" && b.org.name == "" {
+		return fmt.Errorf("must specify source org id or name when restoring resources")
+	} else if len(b.resources) > 0 && (b.full || b.bucketID != "" || b.bucketName != "") {
+		return fmt.Errorf("cannot restore resources along with buckets")
+	}
+
+	var asOf time.Time
+	if b.asOf != "" {
+		if asOf, err = time.Parse(time.RFC3339, b.asOf); err != nil {
+			return fmt.Errorf("invalid --as-of time: %w", err)
+		}
+	}
+
+	secret, err := b.keyFlags.secret()
+	if err != nil {
+		return err
 	}
 
 	// Read in set of KV data & shard data to restore.
-	if err := b.loadIncremental(); err != nil {
+	if err := b.loadIncremental(asOf, secret); err != nil {
 		return fmt.Errorf("restore failed while processing manifest files: %s", err.Error())
+	} else if b.kvEntry == nil && !asOf.IsZero() {
+		return fmt.Errorf("no backup started at or before %s found in: %s", b.asOf, b.path)
 	} else if b.kvEntry == nil {
 		return fmt.Errorf("no manifest files found in: %s", b.path)
 	}
@@ -126,7 +164,9 @@ func (b *cmdRestoreBuilder) restoreRunE(cmd *cobra.Command, args []string) (err
 	b.orgService = &tenant.OrgClientService{Client: client}
 	b.bucketService = &tenant.BucketClientService{Client: client}
 
-	if !b.full {
+	if len(b.resources) > 0 {
+		return b.restoreResources(ctx)
+	} else if !b.full {
 		return b.restorePartial(ctx)
 	}
 	return b.restoreFull(ctx)
@@ -139,8 +179,8 @@ func (b *cmdRestoreBuilder) restoreFull(ctx context.Context) (err error) {
 	}
 
 	// Restore each shard for the bucket.
-	for _, file := range b.shardEntries {
-		if err := b.restoreShard(ctx, file.ShardID, file); err != nil {
+	for shardID, files := range b.shardEntries {
+		if err := b.restoreShardFiles(ctx, shardID, files); err != nil {
 			return err
 		}
 	}
@@ -149,7 +189,7 @@ func (b *cmdRestoreBuilder) restoreFull(ctx context.Context) (err error) {
 }
 
 func (b *cmdRestoreBuilder) restoreKVStore(ctx context.Context) (err error) {
-	f, err := os.Open(filepath.Join(b.path, b.kvEntry.FileName))
+	f, err := openBackupFile(filepath.Join(b.path, b.kvEntry.FileName), b.fileKeys[b.kvEntry.FileName])
 	if err != nil {
 		return err
 	}
@@ -163,12 +203,83 @@ func (b *cmdRestoreBuilder) restoreKVStore(ctx context.Context) (err error) {
 	return nil
 }
 
+// restoreResources restores the requested kinds of resources of an organization
+// from the backup of the metadata into the live one. Resources which already
+// exist are reported as conflicts and left untouched.
+func (b *cmdRestoreBuilder) restoreResources(ctx context.Context) error {
+	req := influxdb.RestoreResourcesRequest{
+		Org:    b.org.name,
+		NewOrg: b.newOrgName,
+	}
+	if b.org.id != "" {
+		if err := req.OrgID.DecodeFromString(b.org.id); err != nil {
+			return err
+		}
+	}
+	for _, kind := range b.resources {
+		req.Kinds = append(req.Kinds, influxdb.ResourceType(kind))
+	}
+
+	f, err := openBackupFile(filepath.Join(b.path, b.kvEntry.FileName), b.fileKeys[b.kvEntry.FileName])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	report, err := b.restoreService.RestoreResources(ctx, 0, f, req)
+	if err != nil {
+		return fmt.Errorf("cannot restore resources: %w", err)
+	}
+
+	if b.json {
+		return b.writeJSON(report)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.WriteHeaders("Kind", "Name", "Backup ID", "ID", "Conflict")
+	for _, r := range report.Restored {
+		w.Write(map[string]interface{}{
+			"Kind":      r.Kind,
+			"Name":      r.Name,
+			"Backup ID": r.OldID,
+			"ID":        r.NewID,
+			"Conflict":  "",
+		})
+	}
+	for _, c := range report.Conflicts {
+		w.Write(map[string]interface{}{
+			"Kind":      c.Kind,
+			"Name":      c.Name,
+			"Backup ID": c.ID,
+			"ID":        "",
+			"Conflict":  c.Reason,
+		})
+	}
+	return nil
+}
+
+func joinResourceTypes(kinds []influxdb.ResourceType) string {
+	s := make([]string, len(kinds))
+	for i, kind := range kinds {
+		s[i] = string(kind)
+	}
+	return strings.Join(s, ", ")
+}
+
 // restorePartial restores shard data to a server without deleting existing data.
 // Organizations & buckets are created as needed. Cannot overwrite an existing bucket.
 func (b *cmdRestoreBuilder) restorePartial(ctx context.Context) (err error) {
-	// Open bolt DB.
+	// Open bolt DB, from a decrypted copy if the backup is encrypted.
 	boltClient := bolt.NewClient(b.logger)
 	boltClient.Path = filepath.Join(b.path, b.kvEntry.FileName)
+	if key := b.fileKeys[b.kvEntry.FileName]; key != nil {
+		if boltClient.Path, err = decryptBackupFile(boltClient.Path, key); err != nil {
+			return err
+		}
+		defer os.Remove(boltClient.Path)
+	}
 	if err := boltClient.Open(ctx); err != nil {
 		return err
 	}
@@ -306,19 +417,19 @@ func (b *cmdRestoreBuilder) restoreBucket(ctx context.Context, bkt *influxdb.Buc
 	}
 
 	// Restore each shard for the bucket.
-	for _, file := range b.shardEntries {
-		if bkt.ID.String() != file.BucketID {
+	for shardID, files := range b.shardEntries {
+		if bkt.ID.String() != files[0].BucketID {
 			continue
 		}
 
 		// Skip if shard metadata was not imported.
-		newID, ok := shardIDMap[file.ShardID]
+		newID, ok := shardIDMap[shardID]
 		if !ok {
-			b.logger.Warn("Meta info not found, skipping file", zap.Uint64("shard", file.ShardID), zap.String("bucket_id", file.BucketID), zap.String("filename", file.FileName))
-			return nil
+			b.logger.Warn("Meta info not found, skipping shard", zap.Uint64("shard", shardID), zap.String("bucket_id", files[0].BucketID))
+			continue
 		}
 
-		if err := b.restoreShard(ctx, newID, file); err != nil {
+		if err := b.restoreShardFiles(ctx, newID, files); err != nil {
 			return err
 		}
 	}
@@ -326,10 +437,21 @@ func (b *cmdRestoreBuilder) restoreBucket(ctx context.Context, bkt *influxdb.Buc
 	return nil
 }
 
+// restoreShardFiles restores the full backup of a shard and then replays its
+// increments, in order.
+func (b *cmdRestoreBuilder) restoreShardFiles(ctx context.Context, newShardID uint64, files []*influxdb.ManifestEntry) error {
+	for _, file := range files {
+		if err := b.restoreShard(ctx, newShardID, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (b *cmdRestoreBuilder) restoreShard(ctx context.Context, newShardID uint64, file *influxdb.ManifestEntry) error {
 	b.logger.Info("Restoring shard live from backup", zap.Uint64("shard", newShardID), zap.String("filename", file.FileName))
 
-	f, err := os.Open(filepath.Join(b.path, file.FileName))
+	f, err := openBackupFile(filepath.Join(b.path, file.FileName), b.fileKeys[file.FileName])
 	if err != nil {
 		return err
 	}
@@ -344,50 +466,53 @@ func (b *cmdRestoreBuilder) restoreShard(ctx context.Context, newShardID uint64,
 	return b.restoreService.RestoreShard(ctx, newShardID, gr)
 }
 
-// loadIncremental loads multiple manifest files from a given directory.
-func (b *cmdRestoreBuilder) loadIncremental() error {
-	// Read all manifest files from path, sort in descending time.
-	manifests, err := filepath.Glob(filepath.Join(b.path, "*.manifest"))
+// loadIncremental loads the manifest files of the latest backup started at or
+// before asOf, or of the latest backup if asOf is zero. An incremental backup
+// is restored along with the backups it is based on, from its full backup on.
+// The files of encrypted backups are decrypted with keys derived from secret.
+// The size and checksum of every file to restore are checked up front, so that
+// nothing is restored from a damaged backup.
+func (b *cmdRestoreBuilder) loadIncremental(asOf time.Time, secret []byte) error {
+	manifests, err := readManifests(b.path)
 	if err != nil {
 		return err
-	} else if len(manifests) == 0 {
+	}
+
+	name := latestManifest(manifests, asOf)
+	if name == "" {
 		return nil
 	}
-	sort.Sort(sort.Reverse(sort.StringSlice(manifests)))
+	chain, err := manifestChain(manifests, name)
+	if err != nil {
+		return err
+	}
+	b.logger.Info("Restoring backup", zap.String("manifest", name), zap.Int("increments", len(chain)-1))
 
-	b.shardEntries = make(map[uint64]*influxdb.ManifestEntry)
-	for _, filename := range manifests {
-		// Skip file if it is a directory.
-		if fi, err := os.Stat(filename); err != nil {
-			return err
-		} else if fi.IsDir() {
-			continue
-		}
+	// Restore the metadata of the latest backup.
+	kv := &chain[len(chain)-1].KV
+	if err := checkBackupFile(filepath.Join(b.path, kv.FileName), kv.Size, kv.SHA256); err != nil {
+		return fmt.Errorf("%s: %w", kv.FileName, err)
+	}
+	b.kvEntry = kv
 
-		// Read manifest file for backup.
-		var manifest influxdb.Manifest
-		if buf, err := ioutil.ReadFile(filename); err != nil {
+	b.shardEntries = make(map[uint64][]*influxdb.ManifestEntry)
+	for _, manifest := range chain {
+		key, err := backupKey(manifest.Encryption, secret)
+		if err != nil {
 			return err
-		} else if err := json.Unmarshal(buf, &manifest); err != nil {
-			return fmt.Errorf("read manifest: %v", err)
 		}
+		b.fileKeys[manifest.KV.FileName] = key
 
-		// Save latest KV entry.
-		if b.kvEntry == nil {
-			b.kvEntry = &manifest.KV
-		}
-
-		// Load most recent backup per shard.
 		for i := range manifest.Files {
-			sh := manifest.Files[i]
-			if _, err := os.Stat(filepath.Join(b.path, sh.FileName)); err != nil {
+			sh := &manifest.Files[i]
+			path := filepath.Join(b.path, sh.FileName)
+			if _, err := os.Stat(path); err != nil {
 				continue
+			} else if err := checkBackupFile(path, sh.Size, sh.SHA256); err != nil {
+				return fmt.Errorf("%s: %w", sh.FileName, err)
 			}
-
-			entry := b.shardEntries[sh.ShardID]
-			if entry == nil || sh.LastModified.After(entry.LastModified) {
-				b.shardEntries[sh.ShardID] = &sh
-			}
+			b.shardEntries[sh.ShardID] = append(b.shardEntries[sh.ShardID], sh)
+			b.fileKeys[sh.FileName] = key
 		}
 	}
 
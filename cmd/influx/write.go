@@ -1,6 +1,7 @@
 package main
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/csv"
 	"fmt"
@@ -84,7 +85,7 @@ func cmdWrite(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 	opts.mustRegister(opt.viper, cmd)
 	cmd.PersistentFlags().StringVar(&writeFlags.Format, "format", "", "Input format, either lp (Line Protocol) or csv (Comma Separated Values). Defaults to lp unless '.csv' extension")
 	cmd.PersistentFlags().StringArrayVar(&writeFlags.Headers, "header", []string{}, "Header prepends lines to input data; Example --header HEADER1 --header HEADER2")
-	cmd.PersistentFlags().StringArrayVarP(&writeFlags.Files, "file", "f", []string{}, "The path to the file to import")
+	cmd.PersistentFlags().StringArrayVarP(&writeFlags.Files, "file", "f", []string{}, "The path to the file to import, decompressed with gzip if it has a .gz extension")
 	cmd.PersistentFlags().StringArrayVarP(&writeFlags.URLs, "url", "u", []string{}, "The URL to import data from")
 	cmd.PersistentFlags().BoolVar(&writeFlags.Debug, "debug", false, "Log CSV columns to stderr before reading data rows")
 	cmd.PersistentFlags().BoolVar(&writeFlags.SkipRowOnError, "skipRowOnError", false, "Log CSV data errors to stderr and continue with CSV processing")
@@ -153,7 +154,16 @@ func (writeFlags *writeFlagsType) createLineReader(ctx context.Context, cmd *cob
 				return nil, csv2lp.MultiCloser(closers...), fmt.Errorf("failed to open %q: %v", file, err)
 			}
 			closers = append(closers, f)
-			readers = append(readers, decode(f), strings.NewReader("\n"))
+			var r io.Reader = f
+			if strings.HasSuffix(file, ".gz") {
+				gr, err := gzip.NewReader(f)
+				if err != nil {
+					return nil, csv2lp.MultiCloser(closers...), fmt.Errorf("failed to decompress %q: %v", file, err)
+				}
+				r = gr
+				file = strings.TrimSuffix(file, ".gz")
+			}
+			readers = append(readers, decode(r), strings.NewReader("\n"))
 			if len(writeFlags.Format) == 0 && strings.HasSuffix(file, ".csv") {
 				writeFlags.Format = inputFormatCsv
 			}
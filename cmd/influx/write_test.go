@@ -55,6 +55,15 @@ func readLines(reader io.Reader) []string {
 	return retVal
 }
 
+// gzipBytes returns data compressed with gzip
+func gzipBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
 func createTempFile(suffix string, contents []byte) string {
 	file, err := ioutil.TempFile("", "influx_writeTest*."+suffix)
 	file.Close() // Close immediately, since we need only a file name
@@ -93,6 +102,8 @@ func Test_writeFlags_createLineReader(t *testing.T) {
 	defer removeTempFiles()
 	fileContents := "_measurement,b,c,d\nf1,f2,f3,f4"
 	csvFile1 := createTempFile("csv", []byte(fileContents))
+	csvGzipFile := createTempFile("csv.gz", gzipBytes([]byte(fileContents)))
+	lpGzipFile := createTempFile("gz", gzipBytes([]byte("m1 f=1\nm2 f=2")))
 	stdInContents := "i,j,_measurement,k\nstdin1,stdin2,stdin3,stdin4"
 
 	// use a test HTTP server to provide CSV data
@@ -235,6 +246,23 @@ func Test_writeFlags_createLineReader(t *testing.T) {
 			lines:  strings.Split(fileContents, "\n"),
 			lpData: true,
 		},
+		{
+			name: "read data from gzipped CSV file + transform to line protocol",
+			flags: writeFlagsType{
+				Files: []string{csvGzipFile},
+			},
+			lines: []string{
+				"f1 b=f2,c=f3,d=f4",
+			},
+		},
+		{
+			name: "read line protocol data from gzipped file",
+			flags: writeFlagsType{
+				Files: []string{lpGzipFile},
+			},
+			lines:  []string{"m1 f=1", "m2 f=2"},
+			lpData: true,
+		},
 		{
 			name: "read data from CSV file + transform to line protocol + throttle read to 1MB/min",
 			flags: writeFlagsType{
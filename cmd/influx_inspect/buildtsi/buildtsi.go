@@ -1,9 +1,8 @@
-// Package buildtsi reads an in-memory index and exports it as a TSI index.
+// Package buildtsi rebuilds the TSI index of shards from their TSM and WAL files.
 package buildtsi
 
 import (
 	"errors"
-	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -16,89 +15,65 @@ import (
 	"sync/atomic"
 
 	"github.com/influxdata/influxdb/v2/logger"
-	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/pkg/file"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
-	"github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
 
-const defaultBatchSize = 10000
-
-// Command represents the program execution for "influx_inspect buildtsi".
+// Command represents the program execution for "influxd inspect build-tsi".
 type Command struct {
+	Stdin   io.Reader
 	Stderr  io.Writer
 	Stdout  io.Writer
 	Verbose bool
 	Logger  *zap.Logger
 
-	concurrency       int // Number of goroutines to dedicate to shard index building.
-	databaseFilter    string
-	retentionFilter   string
-	shardFilter       string
-	compactSeriesFile bool
-	maxLogFileSize    int64
-	maxCacheSize      uint64
-	batchSize         int
+	Concurrency       int    // Number of goroutines to dedicate to shard index building.
+	BucketFilter      string // Only rebuild the index of the bucket with this ID, if set.
+	RetentionFilter   string // Only rebuild the index of the retention policy with this name, if set.
+	ShardFilter       string // Only rebuild the index of the shard with this ID, if set.
+	CompactSeriesFile bool   // Compact the series files instead of rebuilding the index.
+	MaxLogFileSize    int64
+	MaxCacheSize      uint64
+	BatchSize         int
 }
 
 // NewCommand returns a new instance of Command.
 func NewCommand() *Command {
 	return &Command{
-		Stderr:      os.Stderr,
-		Stdout:      os.Stdout,
-		Logger:      zap.NewNop(),
-		batchSize:   defaultBatchSize,
-		concurrency: runtime.GOMAXPROCS(0),
+		Stdin:          os.Stdin,
+		Stderr:         os.Stderr,
+		Stdout:         os.Stdout,
+		Logger:         zap.NewNop(),
+		Concurrency:    runtime.GOMAXPROCS(0),
+		MaxLogFileSize: tsdb.DefaultMaxIndexLogFileSize,
+		MaxCacheSize:   tsdb.DefaultCacheMaxMemorySize,
+		BatchSize:      tsm1.DefaultIndexBatchSize,
 	}
 }
 
-// Run executes the command.
-func (cmd *Command) Run(args ...string) error {
-	fs := flag.NewFlagSet("buildtsi", flag.ExitOnError)
-	dataDir := fs.String("datadir", "", "data directory")
-	walDir := fs.String("waldir", "", "WAL directory")
-	fs.IntVar(&cmd.concurrency, "concurrency", runtime.GOMAXPROCS(0), "Number of workers to dedicate to shard index building. Defaults to GOMAXPROCS")
-	fs.StringVar(&cmd.databaseFilter, "database", "", "optional: database name")
-	fs.StringVar(&cmd.retentionFilter, "retention", "", "optional: retention policy")
-	fs.StringVar(&cmd.shardFilter, "shard", "", "optional: shard id")
-	fs.BoolVar(&cmd.compactSeriesFile, "compact-series-file", false, "optional: compact existing series file. Do not rebuilt index.")
-	fs.Int64Var(&cmd.maxLogFileSize, "max-log-file-size", tsdb.DefaultMaxIndexLogFileSize, "optional: maximum log file size")
-	fs.Uint64Var(&cmd.maxCacheSize, "max-cache-size", tsdb.DefaultCacheMaxMemorySize, "optional: maximum cache size")
-	fs.IntVar(&cmd.batchSize, "batch-size", defaultBatchSize, "optional: set the size of the batches we write to the index. Setting this can have adverse affects on performance and heap requirements")
-	fs.BoolVar(&cmd.Verbose, "v", false, "verbose")
-	fs.SetOutput(cmd.Stdout)
-	if err := fs.Parse(args); err != nil {
-		return err
-	} else if fs.NArg() > 0 || *dataDir == "" || *walDir == "" {
-		fs.Usage()
-		return nil
-	}
-	cmd.Logger = logger.New(cmd.Stderr)
-
-	return cmd.run(*dataDir, *walDir)
-}
-
-func (cmd *Command) run(dataDir, walDir string) error {
+// Run rebuilds the index of the shards, or compacts the series files, of the
+// buckets in the data directory of the engine. The WAL of the shards is in walDir.
+func (cmd *Command) Run(dataDir, walDir string) error {
 	// Verify the user actually wants to run as root.
 	if isRoot() {
 		fmt.Fprintln(cmd.Stdout, "You are currently running as root. This will build your")
 		fmt.Fprintln(cmd.Stdout, "index files with root ownership and will be inaccessible")
 		fmt.Fprintln(cmd.Stdout, "if you run influxd as a non-root user. You should run")
-		fmt.Fprintln(cmd.Stdout, "buildtsi as the same user you are running influxd.")
+		fmt.Fprintln(cmd.Stdout, "this command as the same user you are running influxd.")
 		fmt.Fprint(cmd.Stdout, "Are you sure you want to continue? (y/N): ")
 		var answer string
-		if fmt.Scanln(&answer); !strings.HasPrefix(strings.TrimSpace(strings.ToLower(answer)), "y") {
+		if fmt.Fscanln(cmd.Stdin, &answer); !strings.HasPrefix(strings.TrimSpace(strings.ToLower(answer)), "y") {
 			return fmt.Errorf("operation aborted")
 		}
 	}
 
-	if cmd.compactSeriesFile {
-		if cmd.retentionFilter != "" {
+	if cmd.CompactSeriesFile {
+		if cmd.RetentionFilter != "" {
 			return errors.New("cannot specify retention policy when compacting series file")
-		} else if cmd.shardFilter != "" {
+		} else if cmd.ShardFilter != "" {
 			return errors.New("cannot specify shard ID when compacting series file")
 		}
 	}
@@ -112,11 +87,11 @@ func (cmd *Command) run(dataDir, walDir string) error {
 		name := fi.Name()
 		if !fi.IsDir() {
 			continue
-		} else if cmd.databaseFilter != "" && name != cmd.databaseFilter {
+		} else if cmd.BucketFilter != "" && name != cmd.BucketFilter {
 			continue
 		}
 
-		if cmd.compactSeriesFile {
+		if cmd.CompactSeriesFile {
 			if err := cmd.compactDatabaseSeriesFile(name, filepath.Join(dataDir, name)); err != nil {
 				return err
 			}
@@ -149,7 +124,7 @@ func (cmd *Command) compactDatabaseSeriesFile(dbName, path string) error {
 
 	// Concurrently process each partition in the series file
 	var g errgroup.Group
-	for i := 0; i < cmd.concurrency; i++ {
+	for i := 0; i < cmd.Concurrency; i++ {
 		g.Go(func() error {
 			for path := range pathCh {
 				if err := cmd.compactSeriesFilePartition(path); err != nil {
@@ -270,7 +245,7 @@ func (cmd *Command) processDatabase(dbName, dataDir, walDir string) error {
 			continue
 		} else if rpName == tsdb.SeriesFileDirectory {
 			continue
-		} else if cmd.retentionFilter != "" && rpName != cmd.retentionFilter {
+		} else if cmd.RetentionFilter != "" && rpName != cmd.RetentionFilter {
 			continue
 		}
 
@@ -300,7 +275,7 @@ func (cmd *Command) processRetentionPolicy(sfile *tsdb.SeriesFile, dbName, rpNam
 	for _, fi := range fis {
 		if !fi.IsDir() {
 			continue
-		} else if cmd.shardFilter != "" && fi.Name() != cmd.shardFilter {
+		} else if cmd.ShardFilter != "" && fi.Name() != cmd.ShardFilter {
 			continue
 		}
 
@@ -314,7 +289,7 @@ func (cmd *Command) processRetentionPolicy(sfile *tsdb.SeriesFile, dbName, rpNam
 
 	errC := make(chan error, len(shards))
 	var maxi uint32 // index of maximum shard being worked on.
-	for k := 0; k < cmd.concurrency; k++ {
+	for k := 0; k < cmd.Concurrency; k++ {
 		go func() {
 			for {
 				i := int(atomic.AddUint32(&maxi, 1) - 1) // Get next partition to work on.
@@ -324,7 +299,7 @@ func (cmd *Command) processRetentionPolicy(sfile *tsdb.SeriesFile, dbName, rpNam
 
 				id, name := shards[i].ID, shards[i].Path
 				log := cmd.Logger.With(logger.Database(dbName), logger.RetentionPolicy(rpName), logger.Shard(id))
-				errC <- IndexShard(sfile, filepath.Join(dataDir, name), filepath.Join(walDir, name), cmd.maxLogFileSize, cmd.maxCacheSize, cmd.batchSize, log, cmd.Verbose)
+				errC <- tsm1.IndexShard(sfile, filepath.Join(dataDir, name), filepath.Join(walDir, name), cmd.MaxLogFileSize, cmd.MaxCacheSize, cmd.BatchSize, log, cmd.Verbose)
 			}
 		}()
 	}
@@ -338,220 +313,6 @@ func (cmd *Command) processRetentionPolicy(sfile *tsdb.SeriesFile, dbName, rpNam
 	return nil
 }
 
-func IndexShard(sfile *tsdb.SeriesFile, dataDir, walDir string, maxLogFileSize int64, maxCacheSize uint64, batchSize int, log *zap.Logger, verboseLogging bool) error {
-	log.Info("Rebuilding shard")
-
-	// Check if shard already has a TSI index.
-	indexPath := filepath.Join(dataDir, "index")
-	log.Info("Checking index path", zap.String("path", indexPath))
-	if _, err := os.Stat(indexPath); !os.IsNotExist(err) {
-		log.Info("tsi1 index already exists, skipping", zap.String("path", indexPath))
-		return nil
-	}
-
-	log.Info("Opening shard")
-
-	// Remove temporary index files if this is being re-run.
-	tmpPath := filepath.Join(dataDir, ".index")
-	log.Info("Cleaning up partial index from previous run, if any")
-	if err := os.RemoveAll(tmpPath); err != nil {
-		return err
-	}
-
-	// Open TSI index in temporary path.
-	tsiIndex := tsi1.NewIndex(sfile, "",
-		tsi1.WithPath(tmpPath),
-		tsi1.WithMaximumLogFileSize(maxLogFileSize),
-		tsi1.DisableFsync(),
-		// Each new series entry in a log file is ~12 bytes so this should
-		// roughly equate to one flush to the file for every batch.
-		tsi1.WithLogFileBufferSize(12*batchSize),
-	)
-
-	tsiIndex.WithLogger(log)
-
-	log.Info("Opening tsi index in temporary location", zap.String("path", tmpPath))
-	if err := tsiIndex.Open(); err != nil {
-		return err
-	}
-	defer tsiIndex.Close()
-
-	// Write out tsm1 files.
-	// Find shard files.
-	tsmPaths, err := collectTSMFiles(dataDir)
-	if err != nil {
-		return err
-	}
-
-	log.Info("Iterating over tsm files")
-	for _, path := range tsmPaths {
-		log.Info("Processing tsm file", zap.String("path", path))
-		if err := IndexTSMFile(tsiIndex, path, batchSize, log, verboseLogging); err != nil {
-			return err
-		}
-	}
-
-	// Write out wal files.
-	walPaths, err := collectWALFiles(walDir)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			return err
-		}
-
-	} else {
-		log.Info("Building cache from wal files")
-		cache := tsm1.NewCache(maxCacheSize)
-		loader := tsm1.NewCacheLoader(walPaths)
-		loader.WithLogger(log)
-		if err := loader.Load(cache); err != nil {
-			return err
-		}
-
-		log.Info("Iterating over cache")
-		keysBatch := make([][]byte, 0, batchSize)
-		namesBatch := make([][]byte, 0, batchSize)
-		tagsBatch := make([]models.Tags, 0, batchSize)
-
-		for _, key := range cache.Keys() {
-			seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
-			name, tags := models.ParseKeyBytes(seriesKey)
-
-			if verboseLogging {
-				log.Info("Series", zap.String("name", string(name)), zap.String("tags", tags.String()))
-			}
-
-			keysBatch = append(keysBatch, seriesKey)
-			namesBatch = append(namesBatch, name)
-			tagsBatch = append(tagsBatch, tags)
-
-			// Flush batch?
-			if len(keysBatch) == batchSize {
-				if err := tsiIndex.CreateSeriesListIfNotExists(keysBatch, namesBatch, tagsBatch); err != nil {
-					return fmt.Errorf("problem creating series: (%s)", err)
-				}
-				keysBatch = keysBatch[:0]
-				namesBatch = namesBatch[:0]
-				tagsBatch = tagsBatch[:0]
-			}
-		}
-
-		// Flush any remaining series in the batches
-		if len(keysBatch) > 0 {
-			if err := tsiIndex.CreateSeriesListIfNotExists(keysBatch, namesBatch, tagsBatch); err != nil {
-				return fmt.Errorf("problem creating series: (%s)", err)
-			}
-			keysBatch = nil
-			namesBatch = nil
-			tagsBatch = nil
-		}
-	}
-
-	// Attempt to compact the index & wait for all compactions to complete.
-	log.Info("compacting index")
-	tsiIndex.Compact()
-	tsiIndex.Wait()
-
-	// Close TSI index.
-	log.Info("Closing tsi index")
-	if err := tsiIndex.Close(); err != nil {
-		return err
-	}
-
-	// Rename TSI to standard path.
-	log.Info("Moving tsi to permanent location")
-	return os.Rename(tmpPath, indexPath)
-}
-
-func IndexTSMFile(index *tsi1.Index, path string, batchSize int, log *zap.Logger, verboseLogging bool) error {
-	f, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	r, err := tsm1.NewTSMReader(f)
-	if err != nil {
-		log.Warn("Unable to read, skipping", zap.String("path", path), zap.Error(err))
-		return nil
-	}
-	defer r.Close()
-
-	keysBatch := make([][]byte, 0, batchSize)
-	namesBatch := make([][]byte, 0, batchSize)
-	tagsBatch := make([]models.Tags, batchSize)
-	var ti int
-	for i := 0; i < r.KeyCount(); i++ {
-		key, _ := r.KeyAt(i)
-		seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
-		var name []byte
-		name, tagsBatch[ti] = models.ParseKeyBytesWithTags(seriesKey, tagsBatch[ti])
-
-		if verboseLogging {
-			log.Info("Series", zap.String("name", string(name)), zap.String("tags", tagsBatch[ti].String()))
-		}
-
-		keysBatch = append(keysBatch, seriesKey)
-		namesBatch = append(namesBatch, name)
-		ti++
-
-		// Flush batch?
-		if len(keysBatch) == batchSize {
-			if err := index.CreateSeriesListIfNotExists(keysBatch, namesBatch, tagsBatch[:ti]); err != nil {
-				return fmt.Errorf("problem creating series: (%s)", err)
-			}
-			keysBatch = keysBatch[:0]
-			namesBatch = namesBatch[:0]
-			ti = 0 // Reset tags.
-		}
-	}
-
-	// Flush any remaining series in the batches
-	if len(keysBatch) > 0 {
-		if err := index.CreateSeriesListIfNotExists(keysBatch, namesBatch, tagsBatch[:ti]); err != nil {
-			return fmt.Errorf("problem creating series: (%s)", err)
-		}
-	}
-	return nil
-}
-
-func collectTSMFiles(path string) ([]string, error) {
-	fis, err := ioutil.ReadDir(path)
-	if err != nil {
-		return nil, err
-	}
-
-	var paths []string
-	for _, fi := range fis {
-		if filepath.Ext(fi.Name()) != "."+tsm1.TSMFileExtension {
-			continue
-		}
-		paths = append(paths, filepath.Join(path, fi.Name()))
-	}
-	return paths, nil
-}
-
-func collectWALFiles(path string) ([]string, error) {
-	if path == "" {
-		return nil, os.ErrNotExist
-	}
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil, err
-	}
-	fis, err := ioutil.ReadDir(path)
-	if err != nil {
-		return nil, err
-	}
-
-	var paths []string
-	for _, fi := range fis {
-		if filepath.Ext(fi.Name()) != "."+tsm1.WALFileExtension {
-			continue
-		}
-		paths = append(paths, filepath.Join(path, fi.Name()))
-	}
-	return paths, nil
-}
-
 func isRoot() bool {
 	user, _ := user.Current()
 	return user != nil && user.Username == "root"
@@ -3,7 +3,6 @@ package tombstone
 
 import (
 	"errors"
-	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -13,10 +12,20 @@ import (
 	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
 )
 
-// Command represents the program execution for "influx_inspect verify-tombstone".
+// Verbosity levels of the output of the verification.
+const (
+	Quiet = iota
+	Verbose
+	VeryVerbose
+	VeryVeryVerbose
+)
+
+// Command represents the program execution for "influxd inspect verify-tombstone".
 type Command struct {
 	Stderr io.Writer
 	Stdout io.Writer
+
+	Verbosity int
 }
 
 // NewCommand returns a new instance of Command.
@@ -27,41 +36,12 @@ func NewCommand() *Command {
 	}
 }
 
-// Run executes the command.
-func (cmd *Command) Run(args ...string) error {
-	runner := verifier{w: cmd.Stdout}
-	fs := flag.NewFlagSet("verify-tombstone", flag.ExitOnError)
-	fs.StringVar(&runner.path, "path", os.Getenv("HOME")+"/.influxdb", "path to find tombstone files")
-	v := fs.Bool("v", false, "verbose: emit periodic progress")
-	vv := fs.Bool("vv", false, "very verbose: emit every tombstone entry key and time range")
-	vvv := fs.Bool("vvv", false, "very very verbose: emit every tombstone entry key and RFC3339Nano time range")
-
-	fs.SetOutput(cmd.Stdout)
-
-	if err := fs.Parse(args); err != nil {
-		return err
-	}
-
-	if *v {
-		runner.verbosity = verbose
-	}
-	if *vv {
-		runner.verbosity = veryVerbose
-	}
-	if *vvv {
-		runner.verbosity = veryVeryVerbose
-	}
-
+// Run verifies the tombstone files found under path.
+func (cmd *Command) Run(path string) error {
+	runner := verifier{w: cmd.Stdout, path: path, verbosity: cmd.Verbosity}
 	return runner.Run()
 }
 
-const (
-	quiet = iota
-	verbose
-	veryVerbose
-	veryVeryVerbose
-)
-
 type verifier struct {
 	path      string
 	verbosity int
@@ -100,29 +80,29 @@ func (v *verifier) Run() error {
 	var failed bool
 	start := time.Now()
 	for v.Next() {
-		if v.verbosity > quiet {
+		if v.verbosity > Quiet {
 			fmt.Fprintf(v.w, "Verifying: %q\n", v.f)
 		}
 
 		tombstoner := tsm1.NewTombstoner(v.f, nil)
 		if !tombstoner.HasTombstones() {
-			fmt.Fprintf(v.w, "%s has no tombstone entries", v.f)
+			fmt.Fprintf(v.w, "%s has no tombstone entries\n", v.f)
 			continue
 		}
 
 		var totalEntries int64
 		err := tombstoner.Walk(func(t tsm1.Tombstone) error {
 			totalEntries++
-			if v.verbosity > quiet && totalEntries%(10*1e6) == 0 {
+			if v.verbosity > Quiet && totalEntries%(10*1e6) == 0 {
 				fmt.Fprintf(v.w, "Verified %d tombstone entries\n", totalEntries)
-			} else if v.verbosity > verbose {
+			} else if v.verbosity > Verbose {
 				var min interface{} = t.Min
 				var max interface{} = t.Max
-				if v.verbosity > veryVerbose {
+				if v.verbosity > VeryVerbose {
 					min = time.Unix(0, t.Min)
 					max = time.Unix(0, t.Max)
 				}
-				fmt.Printf("key: %q, min: %v, max: %v\n", t.Key, min, max)
+				fmt.Fprintf(v.w, "key: %q, min: %v, max: %v\n", t.Key, min, max)
 			}
 			return nil
 		})
@@ -2,7 +2,6 @@
 package tsm
 
 import (
-	"flag"
 	"fmt"
 	"hash/crc32"
 	"io"
@@ -16,10 +15,14 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Command represents the program execution for "influx_inspect verify".
+// Command represents the program execution for "influxd inspect verify-tsm".
 type Command struct {
 	Stderr io.Writer
 	Stdout io.Writer
+
+	// CheckUTF8 verifies that the series keys are valid UTF-8, instead of
+	// verifying the checksums of the blocks.
+	CheckUTF8 bool
 }
 
 // NewCommand returns a new instance of Command.
@@ -30,27 +33,12 @@ func NewCommand() *Command {
 	}
 }
 
-// Run executes the command.
-func (cmd *Command) Run(args ...string) error {
-	var path string
-	fs := flag.NewFlagSet("verify", flag.ExitOnError)
-	fs.StringVar(&path, "dir", os.Getenv("HOME")+"/.influxdb", "Root storage path. [$HOME/.influxdb]")
-
-	var checkUTF8 bool
-	fs.BoolVar(&checkUTF8, "check-utf8", false, "Verify series keys are valid UTF-8")
-
-	fs.SetOutput(cmd.Stdout)
-	fs.Usage = cmd.printUsage
-
-	if err := fs.Parse(args); err != nil {
-		return err
-	}
-
-	dataPath := filepath.Join(path, "data")
+// Run verifies the TSM files found under dataPath.
+func (cmd *Command) Run(dataPath string) error {
 	tw := tabwriter.NewWriter(cmd.Stdout, 16, 8, 0, '\t', 0)
 
 	var runner verifier
-	if checkUTF8 {
+	if cmd.CheckUTF8 {
 		runner = &verifyUTF8{}
 	} else {
 		runner = &verifyChecksums{}
@@ -60,24 +48,6 @@ func (cmd *Command) Run(args ...string) error {
 	return err
 }
 
-// printUsage prints the usage message to STDERR.
-func (cmd *Command) printUsage() {
-	usage := fmt.Sprintf(`Verifies the integrity of TSM files.
-
-Usage: influx_inspect verify [flags]
-
-    -dir <path>
-            The root storage path.
-            Must be changed if you are using a non-default storage directory.
-            Defaults to "%[1]s/.influxdb".
-    -check-utf8 
-            Verify series keys are valid UTF-8.
-            This check skips verification of block checksums.
- `, os.Getenv("HOME"))
-
-	fmt.Fprint(cmd.Stdout, usage)
-}
-
 type verifyTSM struct {
 	files []string
 	f     string
@@ -97,7 +67,7 @@ func (v *verifyTSM) loadFiles(dataPath string) error {
 	})
 
 	if err != nil {
-		return errors.Wrap(err, "could not load storage files (use -dir for custom storage root)")
+		return errors.Wrap(err, "could not load storage files (use --engine-path for custom storage root)")
 	}
 
 	return nil
@@ -0,0 +1,96 @@
+package inspect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/influxdata/influxdb/v2/cmd/influx_inspect/buildtsi"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/spf13/cobra"
+)
+
+// NewBuildTSICommand returns a new instance of the build-tsi command.
+func NewBuildTSICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "build-tsi",
+		Short: "Rebuilds the TSI index of shards from their TSM and WAL files",
+		Long: `
+This command will rebuild the TSI index of shards from their TSM and
+WAL files. Shards which already have an index are skipped, so the index
+directory of the shards to rebuild must be removed first.
+
+The server must not be running while the index is rebuilt, unless the
+--online flag is set. The server at --host then takes the shard selected
+with --shard offline, rebuilds its index and brings it back online.
+Writes and queries to the shard fail while its index is rebuilt.`,
+		Args: cobra.NoArgs,
+	}
+
+	var flags engineFlags
+	var online onlineFlags
+	b := buildtsi.NewCommand()
+	flags.register(cmd)
+	flags.registerBucketID(cmd, "Only rebuild the index of the bucket with this ID")
+	cmd.Flags().StringVar(&b.RetentionFilter, "retention", "", "Only rebuild the index of the retention policy with this name")
+	cmd.Flags().StringVar(&b.ShardFilter, "shard", "", "Only rebuild the index of the shard with this ID")
+	cmd.Flags().IntVar(&b.Concurrency, "concurrency", b.Concurrency, "Number of workers to dedicate to shard index building")
+	cmd.Flags().Int64Var(&b.MaxLogFileSize, "max-log-file-size", b.MaxLogFileSize, "Maximum size of the log files of the index")
+	cmd.Flags().Uint64Var(&b.MaxCacheSize, "max-cache-size", b.MaxCacheSize, "Maximum size of the cache the WAL files are loaded into")
+	cmd.Flags().IntVar(&b.BatchSize, "batch-size", b.BatchSize, "Number of series written to the index at once. Setting this can have adverse effects on performance and heap requirements")
+	cmd.Flags().BoolVarP(&b.Verbose, "verbose", "v", false, "Log every series indexed")
+	online.register(cmd, "Rebuild the index of the shard on the running server")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if online.enabled {
+			return rebuildShardIndexOnline(cmd, online, b.ShardFilter)
+		}
+
+		b.Stdin, b.Stdout, b.Stderr = cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr()
+		b.Logger = logger.New(b.Stderr)
+		b.BucketFilter = flags.bucketID
+		return b.Run(flags.dataDir(), flags.walDir())
+	}
+
+	return cmd
+}
+
+// onlineFlags locate a running server to run a command against.
+type onlineFlags struct {
+	enabled    bool
+	host       string
+	token      string
+	skipVerify bool
+}
+
+func (f *onlineFlags) register(cmd *cobra.Command, usage string) {
+	cmd.Flags().BoolVar(&f.enabled, "online", false, usage)
+	cmd.Flags().StringVar(&f.host, "host", "http://localhost:8086", "HTTP address of the running server")
+	cmd.Flags().StringVar(&f.token, "token", os.Getenv("INFLUX_TOKEN"), "Authentication token of the running server")
+	cmd.Flags().BoolVar(&f.skipVerify, "skip-verify", false, "Skip TLS certificate verification")
+}
+
+// rebuildShardIndexOnline asks the running server to rebuild the index of a shard.
+func rebuildShardIndexOnline(cmd *cobra.Command, f onlineFlags, shard string) error {
+	if shard == "" {
+		return errors.New("--shard is required to rebuild an index online")
+	}
+	shardID, err := strconv.ParseUint(shard, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid shard ID %q: %w", shard, err)
+	}
+
+	client, err := http.NewHTTPClient(f.host, f.token, f.skipVerify)
+	if err != nil {
+		return err
+	}
+	s := &http.ShardService{Client: client}
+	if err := s.RebuildShardIndex(context.Background(), shardID); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Rebuilt the index of shard %d\n", shardID)
+	return nil
+}
@@ -0,0 +1,37 @@
+package inspect
+
+import (
+	"github.com/influxdata/influxdb/v2/cmd/influx_inspect/buildtsi"
+	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/spf13/cobra"
+)
+
+// NewCompactSeriesFileCommand returns a new instance of the compact-series-file command.
+func NewCompactSeriesFileCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compact-series-file",
+		Short: "Compacts the series file to remove deleted series",
+		Long: `
+This command will compact the segments of the series files of buckets,
+removing the deleted series, and rebuild their indexes.
+
+The server must not be running while the series files are compacted.`,
+		Args: cobra.NoArgs,
+	}
+
+	var flags engineFlags
+	b := buildtsi.NewCommand()
+	b.CompactSeriesFile = true
+	flags.register(cmd)
+	flags.registerBucketID(cmd, "Only compact the series file of the bucket with this ID")
+	cmd.Flags().IntVar(&b.Concurrency, "concurrency", b.Concurrency, "Number of workers to dedicate to compacting the partitions of the series files")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		b.Stdin, b.Stdout, b.Stderr = cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr()
+		b.Logger = logger.New(b.Stderr)
+		b.BucketFilter = flags.bucketID
+		return b.Run(flags.dataDir(), flags.walDir())
+	}
+
+	return cmd
+}
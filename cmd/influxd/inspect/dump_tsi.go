@@ -0,0 +1,489 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
+	"github.com/spf13/cobra"
+)
+
+// NewDumpTSICommand returns a new instance of the dump-tsi command.
+func NewDumpTSICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dump-tsi path...",
+		Short: "Dumps low-level details about TSI files",
+		Long: `
+This command will dump a summary of the TSI log and index files given
+as arguments, or of all the files of the TSI index directory given as
+the only argument. With the --series, --measurements, --tag-keys,
+--tag-values and --tag-value-series flags, the content of the files is
+dumped instead.`,
+		Args: cobra.MinimumNArgs(1),
+	}
+
+	d := &tsiDump{}
+	var measurementFilter, tagKeyFilter, tagValueFilter string
+	cmd.Flags().StringVar(&d.seriesFilePath, "series-file", "", "Path to the series file of the bucket of the index")
+	_ = cmd.MarkFlagRequired("series-file")
+	cmd.Flags().BoolVar(&d.showSeries, "series", false, "Show raw series data")
+	cmd.Flags().BoolVar(&d.showMeasurements, "measurements", false, "Show raw measurement data")
+	cmd.Flags().BoolVar(&d.showTagKeys, "tag-keys", false, "Show raw tag key data")
+	cmd.Flags().BoolVar(&d.showTagValues, "tag-values", false, "Show raw tag value data")
+	cmd.Flags().BoolVar(&d.showTagValueSeries, "tag-value-series", false, "Show raw series data for each value")
+	cmd.Flags().StringVar(&measurementFilter, "measurement-filter", "", "Regex measurement filter")
+	cmd.Flags().StringVar(&tagKeyFilter, "tag-key-filter", "", "Regex tag key filter")
+	cmd.Flags().StringVar(&tagValueFilter, "tag-value-filter", "", "Regex tag value filter")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		d.stdout, d.stderr = cmd.OutOrStdout(), cmd.ErrOrStderr()
+		d.paths = args
+
+		var err error
+		if d.measurementFilter, err = compileFilter(measurementFilter); err != nil {
+			return err
+		} else if d.tagKeyFilter, err = compileFilter(tagKeyFilter); err != nil {
+			return err
+		} else if d.tagValueFilter, err = compileFilter(tagValueFilter); err != nil {
+			return err
+		}
+
+		// Some flags imply other flags.
+		if d.showTagValueSeries {
+			d.showTagValues = true
+		}
+		if d.showTagValues {
+			d.showTagKeys = true
+		}
+		if d.showTagKeys {
+			d.showMeasurements = true
+		}
+
+		return d.run()
+	}
+
+	return cmd
+}
+
+// compileFilter compiles the regular expression of a filter, if not empty.
+func compileFilter(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+type tsiDump struct {
+	stdout, stderr io.Writer
+
+	seriesFilePath string
+	paths          []string
+
+	showSeries         bool
+	showMeasurements   bool
+	showTagKeys        bool
+	showTagValues      bool
+	showTagValueSeries bool
+
+	measurementFilter *regexp.Regexp
+	tagKeyFilter      *regexp.Regexp
+	tagValueFilter    *regexp.Regexp
+}
+
+func (d *tsiDump) run() error {
+	sfile := tsdb.NewSeriesFile(d.seriesFilePath)
+	sfile.Logger = logger.New(d.stderr)
+	if err := sfile.Open(); err != nil {
+		return err
+	}
+	defer sfile.Close()
+
+	// Build a file set from the paths on the command line.
+	idx, fs, err := d.readFileSet(sfile)
+	if err != nil {
+		return err
+	}
+
+	if fs != nil {
+		defer fs.Release()
+		defer fs.Close()
+	} else {
+		defer idx.Close()
+	}
+
+	if d.showSeries {
+		if err := d.printSeries(sfile); err != nil {
+			return err
+		}
+	}
+
+	// If this is an ad-hoc fileset then process it.
+	if fs != nil {
+		if d.showSeries || d.showMeasurements {
+			return d.printMeasurements(sfile, fs)
+		}
+		return d.printFileSummaries(fs)
+	}
+
+	// Otherwise iterate over each partition in the index.
+	for i := 0; i < int(idx.PartitionN); i++ {
+		if err := func() error {
+			fs, err := idx.PartitionAt(i).RetainFileSet()
+			if err != nil {
+				return err
+			}
+			defer fs.Release()
+
+			if d.showSeries || d.showMeasurements {
+				return d.printMeasurements(sfile, fs)
+			}
+			return d.printFileSummaries(fs)
+		}(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *tsiDump) readFileSet(sfile *tsdb.SeriesFile) (*tsi1.Index, *tsi1.FileSet, error) {
+	// If only one path exists and it's a directory then open as an index.
+	if len(d.paths) == 1 {
+		fi, err := os.Stat(d.paths[0])
+		if err != nil {
+			return nil, nil, err
+		} else if fi.IsDir() {
+			// Verify directory is an index before opening it.
+			if ok, err := tsi1.IsIndexDir(d.paths[0]); err != nil {
+				return nil, nil, err
+			} else if !ok {
+				return nil, nil, fmt.Errorf("not a TSI index directory: %s", d.paths[0])
+			}
+
+			idx := tsi1.NewIndex(sfile, "",
+				tsi1.WithPath(d.paths[0]),
+				tsi1.DisableCompactions(),
+			)
+			if err := idx.Open(); err != nil {
+				return nil, nil, err
+			}
+			return idx, nil, nil
+		}
+	}
+
+	// Open each file and group into a fileset.
+	var files []tsi1.File
+	for _, path := range d.paths {
+		switch ext := filepath.Ext(path); ext {
+		case tsi1.LogFileExt:
+			f := tsi1.NewLogFile(sfile, path)
+			if err := f.Open(); err != nil {
+				return nil, nil, err
+			}
+			files = append(files, f)
+
+		case tsi1.IndexFileExt:
+			f := tsi1.NewIndexFile(sfile)
+			f.SetPath(path)
+			if err := f.Open(); err != nil {
+				return nil, nil, err
+			}
+			files = append(files, f)
+
+		default:
+			return nil, nil, fmt.Errorf("unexpected file extension: %s", ext)
+		}
+	}
+
+	fs, err := tsi1.NewFileSet(nil, sfile, files)
+	if err != nil {
+		return nil, nil, err
+	}
+	fs.Retain()
+
+	return nil, fs, nil
+}
+
+func (d *tsiDump) printSeries(sfile *tsdb.SeriesFile) error {
+	// Print header.
+	tw := tabwriter.NewWriter(d.stdout, 8, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "Series\t")
+
+	// Iterate over each series.
+	itr := sfile.SeriesIDIterator()
+	for {
+		e, err := itr.Next()
+		if err != nil {
+			return err
+		} else if e.SeriesID == 0 {
+			break
+		}
+		name, tags := tsdb.ParseSeriesKey(sfile.SeriesKey(e.SeriesID))
+
+		if !d.matchSeries(name, tags) {
+			continue
+		}
+
+		deleted := sfile.IsDeleted(e.SeriesID)
+
+		fmt.Fprintf(tw, "%s%s\t%v\n", name, tags.HashKey(), deletedString(deleted))
+	}
+
+	// Flush & write footer spacing.
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprint(d.stdout, "\n\n")
+
+	return nil
+}
+
+func (d *tsiDump) printMeasurements(sfile *tsdb.SeriesFile, fs *tsi1.FileSet) error {
+	if !d.showMeasurements {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(d.stdout, 8, 8, 1, '\t', 0)
+	fmt.Fprintln(tw, "Measurement\t")
+
+	// Iterate over each series.
+	if itr := fs.MeasurementIterator(); itr != nil {
+		for e := itr.Next(); e != nil; e = itr.Next() {
+			if d.measurementFilter != nil && !d.measurementFilter.Match(e.Name()) {
+				continue
+			}
+
+			fmt.Fprintf(tw, "%s\t%v\n", e.Name(), deletedString(e.Deleted()))
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+
+			if err := d.printTagKeys(sfile, fs, e.Name()); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprint(d.stdout, "\n\n")
+
+	return nil
+}
+
+func (d *tsiDump) printTagKeys(sfile *tsdb.SeriesFile, fs *tsi1.FileSet, name []byte) error {
+	if !d.showTagKeys {
+		return nil
+	}
+
+	// Iterate over each key.
+	tw := tabwriter.NewWriter(d.stdout, 8, 8, 1, '\t', 0)
+	itr := fs.TagKeyIterator(name)
+	for e := itr.Next(); e != nil; e = itr.Next() {
+		if d.tagKeyFilter != nil && !d.tagKeyFilter.Match(e.Key()) {
+			continue
+		}
+
+		fmt.Fprintf(tw, "    %s\t%v\n", e.Key(), deletedString(e.Deleted()))
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		if err := d.printTagValues(sfile, fs, name, e.Key()); err != nil {
+			return err
+		}
+	}
+	fmt.Fprint(d.stdout, "\n")
+
+	return nil
+}
+
+func (d *tsiDump) printTagValues(sfile *tsdb.SeriesFile, fs *tsi1.FileSet, name, key []byte) error {
+	if !d.showTagValues {
+		return nil
+	}
+
+	// Iterate over each value.
+	tw := tabwriter.NewWriter(d.stdout, 8, 8, 1, '\t', 0)
+	itr := fs.TagValueIterator(name, key)
+	for e := itr.Next(); e != nil; e = itr.Next() {
+		if d.tagValueFilter != nil && !d.tagValueFilter.Match(e.Value()) {
+			continue
+		}
+
+		fmt.Fprintf(tw, "        %s\t%v\n", e.Value(), deletedString(e.Deleted()))
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		if err := d.printTagValueSeries(sfile, fs, name, key, e.Value()); err != nil {
+			return err
+		}
+	}
+	fmt.Fprint(d.stdout, "\n")
+
+	return nil
+}
+
+func (d *tsiDump) printTagValueSeries(sfile *tsdb.SeriesFile, fs *tsi1.FileSet, name, key, value []byte) error {
+	if !d.showTagValueSeries {
+		return nil
+	}
+
+	// Iterate over each series.
+	tw := tabwriter.NewWriter(d.stdout, 8, 8, 1, '\t', 0)
+	itr, err := fs.TagValueSeriesIDIterator(name, key, value)
+	if err != nil {
+		return err
+	} else if itr == nil {
+		return nil
+	}
+	defer itr.Close()
+
+	for {
+		e, err := itr.Next()
+		if err != nil {
+			return err
+		} else if e.SeriesID == 0 {
+			break
+		}
+
+		name, tags := tsdb.ParseSeriesKey(sfile.SeriesKey(e.SeriesID))
+
+		if !d.matchSeries(name, tags) {
+			continue
+		}
+
+		fmt.Fprintf(tw, "            %s%s\n", name, tags.HashKey())
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprint(d.stdout, "\n")
+
+	return nil
+}
+
+func (d *tsiDump) printFileSummaries(fs *tsi1.FileSet) error {
+	for _, f := range fs.Files() {
+		switch f := f.(type) {
+		case *tsi1.LogFile:
+			if err := d.printLogFileSummary(f); err != nil {
+				return err
+			}
+		case *tsi1.IndexFile:
+			if err := d.printIndexFileSummary(f); err != nil {
+				return err
+			}
+		default:
+			return errors.New("unexpected file type")
+		}
+		fmt.Fprintln(d.stdout, "")
+	}
+	return nil
+}
+
+func (d *tsiDump) printLogFileSummary(f *tsi1.LogFile) error {
+	fmt.Fprintf(d.stdout, "[LOG FILE] %s\n", filepath.Base(f.Path()))
+	tw := tabwriter.NewWriter(d.stdout, 8, 8, 1, '\t', 0)
+	fmt.Fprintf(tw, "Series:\t%d\n", f.SeriesN())
+	fmt.Fprintf(tw, "Measurements:\t%d\n", f.MeasurementN())
+	fmt.Fprintf(tw, "Tag Keys:\t%d\n", f.TagKeyN())
+	fmt.Fprintf(tw, "Tag Values:\t%d\n", f.TagValueN())
+	return tw.Flush()
+}
+
+func (d *tsiDump) printIndexFileSummary(f *tsi1.IndexFile) error {
+	fmt.Fprintf(d.stdout, "[INDEX FILE] %s\n", filepath.Base(f.Path()))
+
+	// Calculate summary stats.
+	var measurementN, measurementSeriesN, measurementSeriesSize uint64
+	var keyN uint64
+	var valueN, valueSeriesN, valueSeriesSize uint64
+
+	if mitr := f.MeasurementIterator(); mitr != nil {
+		for me, _ := mitr.Next().(*tsi1.MeasurementBlockElem); me != nil; me, _ = mitr.Next().(*tsi1.MeasurementBlockElem) {
+			kitr := f.TagKeyIterator(me.Name())
+			for ke, _ := kitr.Next().(*tsi1.TagBlockKeyElem); ke != nil; ke, _ = kitr.Next().(*tsi1.TagBlockKeyElem) {
+				vitr := ke.TagValueIterator()
+				for ve, _ := vitr.Next().(*tsi1.TagBlockValueElem); ve != nil; ve, _ = vitr.Next().(*tsi1.TagBlockValueElem) {
+					valueN++
+					valueSeriesN += ve.SeriesN()
+					valueSeriesSize += uint64(len(ve.SeriesData()))
+				}
+				keyN++
+			}
+			measurementN++
+			measurementSeriesN += me.SeriesN()
+			measurementSeriesSize += uint64(len(me.SeriesData()))
+		}
+	}
+
+	// Write stats.
+	tw := tabwriter.NewWriter(d.stdout, 8, 8, 1, '\t', 0)
+	fmt.Fprintf(tw, "Measurements:\t%d\n", measurementN)
+	fmt.Fprintf(tw, "  Series data size:\t%d (%s)\n", measurementSeriesSize, formatSize(measurementSeriesSize))
+	fmt.Fprintf(tw, "  Bytes per series:\t%.01fb\n", bytesPerSeries(measurementSeriesSize, measurementSeriesN))
+	fmt.Fprintf(tw, "Tag Keys:\t%d\n", keyN)
+	fmt.Fprintf(tw, "Tag Values:\t%d\n", valueN)
+	fmt.Fprintf(tw, "  Series:\t%d\n", valueSeriesN)
+	fmt.Fprintf(tw, "  Series data size:\t%d (%s)\n", valueSeriesSize, formatSize(valueSeriesSize))
+	fmt.Fprintf(tw, "  Bytes per series:\t%.01fb\n", bytesPerSeries(valueSeriesSize, valueSeriesN))
+	return tw.Flush()
+}
+
+// matchSeries returns true if the command filters matches the series.
+func (d *tsiDump) matchSeries(name []byte, tags models.Tags) bool {
+	// Filter by measurement.
+	if d.measurementFilter != nil && !d.measurementFilter.Match(name) {
+		return false
+	}
+
+	// Filter by tag key/value.
+	if d.tagKeyFilter != nil || d.tagValueFilter != nil {
+		var matched bool
+		for _, tag := range tags {
+			if (d.tagKeyFilter == nil || d.tagKeyFilter.Match(tag.Key)) && (d.tagValueFilter == nil || d.tagValueFilter.Match(tag.Value)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func bytesPerSeries(size, n uint64) float64 {
+	if n == 0 {
+		return 0
+	}
+	return float64(size) / float64(n)
+}
+
+// deletedString returns "(deleted)" if v is true.
+func deletedString(v bool) string {
+	if v {
+		return "(deleted)"
+	}
+	return ""
+}
+
+func formatSize(v uint64) string {
+	denom := uint64(1)
+	var uom string
+	for _, uom = range []string{"b", "kb", "mb", "gb", "tb"} {
+		if denom*1024 > v {
+			break
+		}
+		denom *= 1024
+	}
+	return fmt.Sprintf("%0.01f%s", float64(v)/float64(denom), uom)
+}
@@ -0,0 +1,333 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewDumpTSMCommand returns a new instance of the dump-tsm command.
+func NewDumpTSMCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dump-tsm path...",
+		Short: "Dumps the block statistics of TSM files",
+		Long: `
+This command will dump the keys of the TSM files given as arguments,
+with the number of blocks and values of each key, their time range,
+block type and the encodings of their timestamps and values. It ends
+with statistics on the encodings used and a histogram of the
+compression ratio of the blocks, which is the size the values would
+have uncompressed divided by the size of the block.`,
+		Args: cobra.MinimumNArgs(1),
+	}
+
+	d := &tsmDumper{}
+	cmd.Flags().BoolVar(&d.blocks, "blocks", false, "Dump every block of the keys")
+	cmd.Flags().StringVar(&d.filterKey, "filter-key", "", "Only dump the keys containing this substring")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		d.w = cmd.OutOrStdout()
+		for _, path := range args {
+			if err := d.dump(path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+type tsmDumper struct {
+	w io.Writer
+
+	blocks    bool
+	filterKey string
+}
+
+// blockTypeNames are the names of the TSM block types.
+var blockTypeNames = map[byte]string{
+	tsm1.BlockFloat64:  "float",
+	tsm1.BlockInteger:  "integer",
+	tsm1.BlockBoolean:  "boolean",
+	tsm1.BlockString:   "string",
+	tsm1.BlockUnsigned: "unsigned",
+}
+
+// compressionRatioBuckets are the exclusive upper bounds of the buckets of the
+// compression ratio histogram. The last bucket is unbounded.
+var compressionRatioBuckets = []float64{1, 2, 4, 8, 16, 32, 64}
+
+// keyStats are the statistics of the blocks of a key.
+type keyStats struct {
+	key              string
+	typ              byte
+	blocks, points   int
+	minTime, maxTime int64
+	size, rawSize    int64
+	encodings        []string
+}
+
+// tsmStats are the statistics of the blocks of a file.
+type tsmStats struct {
+	blocks, points   int
+	size, rawSize    int64
+	minSize, maxSize int64
+
+	// encodings counts the blocks using each encoding, by timestamps or
+	// block type.
+	encodings map[string]map[string]int
+	ratios    []int
+}
+
+func (s *tsmStats) add(typ byte, size, rawSize int64, points int, tsEncoding, valueEncoding string) {
+	if s.blocks == 0 || size < s.minSize {
+		s.minSize = size
+	}
+	if size > s.maxSize {
+		s.maxSize = size
+	}
+	s.blocks++
+	s.points += points
+	s.size += size
+	s.rawSize += rawSize
+
+	if s.encodings == nil {
+		s.encodings = make(map[string]map[string]int)
+		s.ratios = make([]int, len(compressionRatioBuckets)+1)
+	}
+	for _, e := range [][2]string{{"timestamp", tsEncoding}, {blockTypeNames[typ], valueEncoding}} {
+		if s.encodings[e[0]] == nil {
+			s.encodings[e[0]] = make(map[string]int)
+		}
+		s.encodings[e[0]][e[1]]++
+	}
+
+	ratio := float64(rawSize) / float64(size)
+	s.ratios[sort.Search(len(compressionRatioBuckets), func(i int) bool { return compressionRatioBuckets[i] > ratio })]++
+}
+
+func (d *tsmDumper) dump(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	defer r.Close()
+
+	minTime, maxTime := r.TimeRange()
+	fmt.Fprintln(d.w, "Summary:")
+	fmt.Fprintf(d.w, "  File: %s\n", path)
+	fmt.Fprintf(d.w, "  Time Range: %s - %s\n", formatTime(minTime), formatTime(maxTime))
+	fmt.Fprintf(d.w, "  Duration: %s\n", time.Duration(maxTime-minTime))
+	fmt.Fprintf(d.w, "  Keys: %d\n", r.KeyCount())
+	fmt.Fprintf(d.w, "  File Size: %d\n", r.Size())
+	fmt.Fprintf(d.w, "  Index Size: %d\n", r.IndexSize())
+	fmt.Fprintln(d.w)
+
+	var (
+		stats tsmStats
+		keys  []*keyStats
+		ks    *keyStats
+	)
+
+	bw := tabwriter.NewWriter(d.w, 8, 2, 1, ' ', 0)
+	if d.blocks {
+		fmt.Fprintln(d.w, "Blocks:")
+		fmt.Fprintln(bw, strings.Join([]string{"  Key", "Block", "Type", "Size", "Points", "Min Time", "Max Time", "Timestamps", "Values", "Ratio"}, "\t"))
+	}
+
+	iter := r.BlockIterator()
+	for iter.Next() {
+		key, minTime, maxTime, typ, _, buf, err := iter.Read()
+		if err != nil {
+			return fmt.Errorf("unable to read block of %s: %w", path, err)
+		}
+		if d.filterKey != "" && !strings.Contains(string(key), d.filterKey) {
+			continue
+		}
+
+		points, err := tsm1.BlockCount(buf)
+		if err != nil {
+			return fmt.Errorf("block of %q in %s: %w", key, path, err)
+		}
+		tsEncoding, valueEncoding, err := tsm1.BlockEncodings(buf)
+		if err != nil {
+			return fmt.Errorf("block of %q in %s: %w", key, path, err)
+		}
+		rawSize, err := rawBlockSize(typ, buf, points)
+		if err != nil {
+			return fmt.Errorf("block of %q in %s: %w", key, path, err)
+		}
+		// The size of a block includes its checksum.
+		size := int64(len(buf)) + 4
+
+		if ks == nil || ks.key != string(key) {
+			ks = &keyStats{key: string(key), typ: typ, minTime: minTime}
+			keys = append(keys, ks)
+		}
+		ks.blocks++
+		ks.points += points
+		ks.maxTime = maxTime
+		ks.size += size
+		ks.rawSize += rawSize
+		ks.encodings = appendEncoding(ks.encodings, tsEncoding+"/"+valueEncoding)
+		stats.add(typ, size, rawSize, points, tsEncoding, valueEncoding)
+
+		if d.blocks {
+			fmt.Fprintln(bw, strings.Join([]string{
+				"  " + ks.key,
+				strconv.Itoa(ks.blocks),
+				blockTypeNames[typ],
+				strconv.FormatInt(size, 10),
+				strconv.Itoa(points),
+				formatTime(minTime),
+				formatTime(maxTime),
+				tsEncoding,
+				valueEncoding,
+				fmt.Sprintf("%.2fx", float64(rawSize)/float64(size)),
+			}, "\t"))
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("unable to read blocks of %s: %w", path, err)
+	}
+	if d.blocks {
+		bw.Flush()
+		fmt.Fprintln(d.w)
+	}
+
+	fmt.Fprintln(d.w, "Keys:")
+	kw := tabwriter.NewWriter(d.w, 8, 2, 1, ' ', 0)
+	fmt.Fprintln(kw, strings.Join([]string{"  Key", "Type", "Blocks", "Points", "Min Time", "Max Time", "Size", "Bytes/Point", "Encodings"}, "\t"))
+	for _, ks := range keys {
+		fmt.Fprintln(kw, strings.Join([]string{
+			"  " + ks.key,
+			blockTypeNames[ks.typ],
+			strconv.Itoa(ks.blocks),
+			strconv.Itoa(ks.points),
+			formatTime(ks.minTime),
+			formatTime(ks.maxTime),
+			strconv.FormatInt(ks.size, 10),
+			fmt.Sprintf("%.2f", float64(ks.size)/float64(ks.points)),
+			strings.Join(ks.encodings, ","),
+		}, "\t"))
+	}
+	kw.Flush()
+	fmt.Fprintln(d.w)
+
+	d.printStatistics(&stats)
+	return nil
+}
+
+func (d *tsmDumper) printStatistics(s *tsmStats) {
+	fmt.Fprintln(d.w, "Statistics:")
+	if s.blocks == 0 {
+		fmt.Fprintln(d.w, "  Blocks: 0")
+		fmt.Fprintln(d.w)
+		return
+	}
+
+	fmt.Fprintln(d.w, "  Blocks:")
+	fmt.Fprintf(d.w, "    Total: %d Size: %d Min: %d Max: %d Avg: %d\n", s.blocks, s.size, s.minSize, s.maxSize, s.size/int64(s.blocks))
+	fmt.Fprintln(d.w, "  Points:")
+	fmt.Fprintf(d.w, "    Total: %d\n", s.points)
+
+	fmt.Fprintln(d.w, "  Encoding:")
+	for _, name := range []string{"timestamp", "float", "integer", "unsigned", "boolean", "string"} {
+		counts := s.encodings[name]
+		if counts == nil {
+			continue
+		}
+		encodings := make([]string, 0, len(counts))
+		for encoding := range counts {
+			encodings = append(encodings, encoding)
+		}
+		sort.Strings(encodings)
+
+		var line []string
+		for _, encoding := range encodings {
+			n := counts[encoding]
+			line = append(line, fmt.Sprintf("%s: %d (%d%%)", encoding, n, n*100/s.blocks))
+		}
+		fmt.Fprintf(d.w, "    %s: %s\n", strings.Title(name), strings.Join(line, " "))
+	}
+
+	fmt.Fprintln(d.w, "  Compression:")
+	fmt.Fprintf(d.w, "    Bytes/Point: %.2f\n", float64(s.size)/float64(s.points))
+	fmt.Fprintf(d.w, "    Ratio: %.2fx\n", float64(s.rawSize)/float64(s.size))
+
+	fmt.Fprintln(d.w, "  Compression Ratio Histogram:")
+	var max int
+	for _, n := range s.ratios {
+		if n > max {
+			max = n
+		}
+	}
+	tw := tabwriter.NewWriter(d.w, 8, 2, 1, ' ', 0)
+	for i, n := range s.ratios {
+		var bucket string
+		switch {
+		case i == 0:
+			bucket = fmt.Sprintf("< %gx", compressionRatioBuckets[0])
+		case i == len(compressionRatioBuckets):
+			bucket = fmt.Sprintf(">= %gx", compressionRatioBuckets[i-1])
+		default:
+			bucket = fmt.Sprintf("%gx - %gx", compressionRatioBuckets[i-1], compressionRatioBuckets[i])
+		}
+		bar := strings.Repeat("#", int(math.Ceil(float64(n)*40/float64(max))))
+		fmt.Fprintf(tw, "    %s\t%d\t%s\n", bucket, n, bar)
+	}
+	tw.Flush()
+	fmt.Fprintln(d.w)
+}
+
+// rawBlockSize returns the size the timestamps and values of a block would
+// have uncompressed.
+func rawBlockSize(typ byte, block []byte, points int) (int64, error) {
+	size := int64(points) * 8
+	switch typ {
+	case tsm1.BlockFloat64, tsm1.BlockInteger, tsm1.BlockUnsigned:
+		size += int64(points) * 8
+	case tsm1.BlockBoolean:
+		size += int64(points)
+	case tsm1.BlockString:
+		var values []tsm1.StringValue
+		values, err := tsm1.DecodeStringBlock(block, &values)
+		if err != nil {
+			return 0, err
+		}
+		for _, v := range values {
+			size += int64(len(v.RawValue()))
+		}
+	}
+	return size, nil
+}
+
+// appendEncoding appends encoding to encodings, unless it is already there.
+func appendEncoding(encodings []string, encoding string) []string {
+	for _, e := range encodings {
+		if e == encoding {
+			return encodings
+		}
+	}
+	return append(encodings, encoding)
+}
+
+func formatTime(t int64) string {
+	return time.Unix(0, t).UTC().Format(time.RFC3339Nano)
+}
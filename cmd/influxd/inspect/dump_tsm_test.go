@@ -0,0 +1,122 @@
+package inspect
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+)
+
+func TestDumpTSM(t *testing.T) {
+	e := newTestEngine(t)
+	e.writeShard(t, testBucketID, 1,
+		[]string{"cpu,host=a value=1 10", "cpu,host=a value=2 20", "mem,host=b free=2i 20"},
+		nil,
+	)
+	path := filepath.Join(e.shardDir("1"), "000000001-000000001.tsm")
+
+	tests := []struct {
+		name    string
+		args    []string
+		exp     []string
+		notExp  []string
+		wantErr string
+	}{
+		{
+			name: "summary",
+			args: []string{path},
+			exp: []string{
+				"File: " + path,
+				"Time Range: 1970-01-01T00:00:00.00000001Z - 1970-01-01T00:00:00.00000002Z",
+				"Keys: 2",
+				"cpu,host=a#!~#value float   1       2",
+				"mem,host=b#!~#free  integer 1       1",
+				"Total: 2 Size: 63 Min: 24 Max: 39 Avg: 31",
+				"Timestamp: rle: 1 (50%) simple8b: 1 (50%)",
+				"Float: gorilla: 1 (50%)",
+				"Integer: simple8b: 1 (50%)",
+				"< 1x      2",
+			},
+			notExp: []string{"Blocks:\n  Key"},
+		},
+		{
+			name: "blocks",
+			args: []string{"--blocks", path},
+			exp: []string{
+				"Blocks:\n  Key",
+				"cpu,host=a#!~#value 1       float   39      2",
+				"rle        gorilla  0.82x",
+			},
+		},
+		{
+			name:   "filter key",
+			args:   []string{"--filter-key", "mem", path},
+			exp:    []string{"mem,host=b#!~#free", "Total: 1 Size: 24"},
+			notExp: []string{"cpu,host=a"},
+		},
+		{
+			name:   "no matching key",
+			args:   []string{"--filter-key", "disk", path},
+			exp:    []string{"Statistics:\n  Blocks: 0"},
+			notExp: []string{"Histogram"},
+		},
+		{
+			name:    "missing file",
+			args:    []string{filepath.Join(e.shardDir("1"), "missing.tsm")},
+			wantErr: "no such file or directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runCommand(t, NewDumpTSMCommand(), tt.args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, s := range tt.exp {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected output to contain %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tt.notExp {
+				if strings.Contains(out, s) {
+					t.Errorf("expected output not to contain %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+// TestTSMStats_Ratios checks that the compression ratio histogram counts
+// the ratios at the bounds of its buckets in the buckets they start.
+func TestTSMStats_Ratios(t *testing.T) {
+	var s tsmStats
+	for _, rawSize := range []int64{5, 10, 15, 20, 39, 40, 639, 640, 1000} {
+		s.add(tsm1.BlockFloat64, 10, rawSize, 1, "rle", "gorilla")
+	}
+
+	exp := []int{
+		1, // < 1x: 0.5
+		2, // 1x - 2x: 1, 1.5
+		2, // 2x - 4x: 2, 3.9
+		1, // 4x - 8x: 4
+		0, // 8x - 16x
+		0, // 16x - 32x
+		1, // 32x - 64x: 63.9
+		2, // >= 64x: 64, 100
+	}
+	if len(s.ratios) != len(exp) {
+		t.Fatalf("got %d buckets, expected %d", len(s.ratios), len(exp))
+	}
+	for i := range exp {
+		if s.ratios[i] != exp[i] {
+			t.Errorf("bucket %d: got %d ratios, expected %d", i, s.ratios[i], exp[i])
+		}
+	}
+}
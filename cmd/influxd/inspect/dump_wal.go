@@ -0,0 +1,213 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewDumpWALCommand returns a new instance of the dump-wal command.
+func NewDumpWALCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dump-wal path...",
+		Short: "Dumps the entries of WAL files",
+		Long: `
+This command will dump the entries of the WAL segment files given as
+arguments: the keys of write entries with their number of points and
+time range, and the keys of delete and delete range entries. With
+--values, every value of the write entries is dumped. With
+--find-duplicates, only the keys with duplicate or out of order
+timestamps within an entry are dumped.
+
+Segments which are truncated, such as by a crash in the middle of a
+write, or corrupt are reported along with the offset up to which they
+are valid. They can be repaired with verify-wal --repair.`,
+		Args: cobra.MinimumNArgs(1),
+	}
+
+	var d walDumper
+	cmd.Flags().BoolVar(&d.values, "values", false, "Dump every value of the write entries")
+	cmd.Flags().BoolVar(&d.findDuplicates, "find-duplicates", false, "Only dump the keys with duplicate or out of order timestamps")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		d.w = cmd.OutOrStdout()
+		var invalid int
+		for _, path := range args {
+			if err := d.dump(path); err != nil {
+				var segErr *walSegmentError
+				if !errors.As(err, &segErr) {
+					return err
+				}
+				fmt.Fprintf(d.w, "%v\n", err)
+				invalid++
+			}
+		}
+		if invalid > 0 {
+			return fmt.Errorf("%d of %d WAL files are truncated or corrupt", invalid, len(args))
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+type walDumper struct {
+	w io.Writer
+
+	values         bool
+	findDuplicates bool
+}
+
+func (d *walDumper) dump(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	r := tsm1.NewWALSegmentReader(f)
+	defer r.Close()
+
+	w := d.w
+	fmt.Fprintf(w, "File: %s\n", path)
+	for r.Next() {
+		entry, err := r.Read()
+		if err != nil {
+			return newWALSegmentError(path, r.Count(), err)
+		}
+
+		switch entry := entry.(type) {
+		case *tsm1.WriteWALEntry:
+			keys := make([]string, 0, len(entry.Values))
+			for k := range entry.Values {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			if d.findDuplicates {
+				for _, k := range keys {
+					if hasDuplicates(entry.Values[k]) {
+						fmt.Fprintln(w, k)
+					}
+				}
+				continue
+			}
+
+			var points int
+			minTime, maxTime := int64(math.MaxInt64), int64(math.MinInt64)
+			for _, values := range entry.Values {
+				points += len(values)
+				min, max := valuesTimeRange(values)
+				if min < minTime {
+					minTime = min
+				}
+				if max > maxTime {
+					maxTime = max
+				}
+			}
+			fmt.Fprintf(w, "[write] sz=%d keys=%d points=%d", entry.MarshalSize(), len(keys), points)
+			if points > 0 {
+				fmt.Fprintf(w, " min=%s max=%s", formatTime(minTime), formatTime(maxTime))
+			}
+			fmt.Fprintln(w)
+
+			for _, k := range keys {
+				values := entry.Values[k]
+				if d.values {
+					for _, v := range values {
+						fmt.Fprintf(w, "%s %d %v\n", k, v.UnixNano(), v.Value())
+					}
+					continue
+				}
+				min, max := valuesTimeRange(values)
+				fmt.Fprintf(w, "%s points=%d min=%s max=%s\n", k, len(values), formatTime(min), formatTime(max))
+			}
+
+		case *tsm1.DeleteWALEntry:
+			if d.findDuplicates {
+				continue
+			}
+			fmt.Fprintf(w, "[delete] sz=%d keys=%d\n", entry.MarshalSize(), len(entry.Keys))
+			for _, k := range entry.Keys {
+				fmt.Fprintf(w, "%s\n", k)
+			}
+
+		case *tsm1.DeleteRangeWALEntry:
+			if d.findDuplicates {
+				continue
+			}
+			fmt.Fprintf(w, "[delete-range] min=%s max=%s sz=%d keys=%d\n", formatTime(entry.Min), formatTime(entry.Max), entry.MarshalSize(), len(entry.Keys))
+			for _, k := range entry.Keys {
+				fmt.Fprintf(w, "%s\n", k)
+			}
+
+		default:
+			return fmt.Errorf("invalid wal entry: %#v", entry)
+		}
+	}
+	return nil
+}
+
+// valuesTimeRange returns the minimum and maximum timestamps of values, which
+// are not necessarily sorted.
+func valuesTimeRange(values []tsm1.Value) (min, max int64) {
+	min, max = int64(math.MaxInt64), int64(math.MinInt64)
+	for _, v := range values {
+		if t := v.UnixNano(); t < min {
+			min = t
+		}
+		if t := v.UnixNano(); t > max {
+			max = t
+		}
+	}
+	return min, max
+}
+
+// hasDuplicates returns true if the timestamps of values are not strictly increasing.
+func hasDuplicates(values []tsm1.Value) bool {
+	for i := 1; i < len(values); i++ {
+		if values[i].UnixNano() <= values[i-1].UnixNano() {
+			return true
+		}
+	}
+	return false
+}
+
+// walSegmentError is returned when a WAL segment is truncated or corrupt.
+type walSegmentError struct {
+	path string
+
+	// valid is the number of bytes of the segment up to the last valid entry.
+	valid int64
+
+	// truncated is true if the last entry of the segment is incomplete, rather
+	// than corrupt.
+	truncated bool
+	err       error
+}
+
+func newWALSegmentError(path string, valid int64, err error) *walSegmentError {
+	return &walSegmentError{
+		path:      path,
+		valid:     valid,
+		truncated: errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF),
+		err:       err,
+	}
+}
+
+func (e *walSegmentError) Error() string {
+	state := "corrupt"
+	if e.truncated {
+		state = "truncated"
+	}
+	return fmt.Sprintf("%s is %s after %d valid bytes: %v", e.path, state, e.valid, e.err)
+}
+
+func (e *walSegmentError) Unwrap() error {
+	return e.err
+}
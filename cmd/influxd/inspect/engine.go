@@ -0,0 +1,50 @@
+package inspect
+
+import (
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/spf13/cobra"
+)
+
+// engineFlags locate the files of the storage engine. The data of a shard is
+// stored under data/<bucket ID>/<retention policy>/<shard ID>, and its WAL
+// under wal/<bucket ID>/<retention policy>/<shard ID>, in the engine path.
+type engineFlags struct {
+	path     string
+	bucketID string
+}
+
+func (f *engineFlags) register(cmd *cobra.Command) {
+	var defaultPath string
+	if dir, err := fs.InfluxDir(); err == nil {
+		defaultPath = filepath.Join(dir, "engine")
+	}
+	cmd.Flags().StringVar(&f.path, "engine-path", defaultPath, "Path to persistent engine files")
+}
+
+func (f *engineFlags) registerBucketID(cmd *cobra.Command, usage string) {
+	cmd.Flags().StringVar(&f.bucketID, "bucket-id", "", usage)
+}
+
+// dataDir returns the directory of the data of the buckets.
+func (f *engineFlags) dataDir() string {
+	return filepath.Join(f.path, "data")
+}
+
+// walDir returns the directory of the WAL of the buckets.
+func (f *engineFlags) walDir() string {
+	return filepath.Join(f.path, "wal")
+}
+
+// bucketDataDir returns the directory of the data of the selected bucket, or
+// of all buckets if none is selected.
+func (f *engineFlags) bucketDataDir() string {
+	return filepath.Join(f.dataDir(), f.bucketID)
+}
+
+// bucketWALDir returns the directory of the WAL of the selected bucket, or of
+// all buckets if none is selected.
+func (f *engineFlags) bucketWALDir() string {
+	return filepath.Join(f.walDir(), f.bucketID)
+}
@@ -0,0 +1,33 @@
+package inspect
+
+import (
+	"bufio"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewExportBlocksCommand returns a new instance of the export-blocks command.
+func NewExportBlocksCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   `export-blocks path...`,
+		Short: "Exports block data",
+		Long: `
+This command will export all blocks in one or more TSM files to
+another format for easier inspection and debugging.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := bufio.NewWriter(cmd.OutOrStdout())
+			e := tsm1.NewSQLBlockExporter(w)
+			for _, arg := range args {
+				if err := e.ExportFile(arg); err != nil {
+					return err
+				}
+			}
+			if err := e.Close(); err != nil {
+				return err
+			}
+			return w.Flush()
+		},
+	}
+}
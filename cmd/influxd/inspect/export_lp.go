@@ -0,0 +1,362 @@
+package inspect
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/escape"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewExportLineProtocolCommand returns a new instance of the export-lp command.
+func NewExportLineProtocolCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-lp",
+		Short: "Export TSM and WAL data of a bucket as line protocol",
+		Long: `
+This command will export the data of a bucket, read from its TSM files
+and WAL segments, as line protocol which can be written back with
+'influx write'. The server does not need to be running.
+
+The data of the TSM files of a shard is exported before the data of its
+WAL segments, so that the most recent values win when written back.
+Deletes which are only recorded in WAL segments are not applied. Blocks
+and WAL entries which cannot be read are reported and skipped.`,
+		Args: cobra.NoArgs,
+	}
+
+	var flags engineFlags
+	var start, end string
+	e := &lpExporter{}
+	flags.register(cmd)
+	flags.registerBucketID(cmd, "ID of the bucket to export")
+	cmd.Flags().StringVar(&e.outputPath, "output-path", "", "Path of the file to export to, or - for stdout")
+	cmd.Flags().StringSliceVar(&e.measurements, "measurement", nil, "Only export the data of these measurements")
+	cmd.Flags().StringVar(&start, "start", "", "Only export data at or after this time (RFC3339 format)")
+	cmd.Flags().StringVar(&end, "end", "", "Only export data at or before this time (RFC3339 format)")
+	cmd.Flags().BoolVar(&e.compress, "compress", true, "Compress the exported line protocol with gzip")
+	_ = cmd.MarkFlagRequired("bucket-id")
+	_ = cmd.MarkFlagRequired("output-path")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := influxdb.IDFromString(flags.bucketID); err != nil {
+			return fmt.Errorf("invalid bucket ID %q: %w", flags.bucketID, err)
+		}
+
+		var err error
+		if e.start, e.end, err = parseTimeRange(start, end); err != nil {
+			return err
+		}
+
+		e.stdout, e.stderr = cmd.OutOrStdout(), cmd.ErrOrStderr()
+		return e.run(flags.bucketDataDir(), flags.bucketWALDir())
+	}
+
+	return cmd
+}
+
+// parseTimeRange parses the bounds of a time range in RFC3339 format, which
+// default to the minimum and maximum times if empty.
+func parseTimeRange(start, end string) (int64, int64, error) {
+	min, max := int64(math.MinInt64), int64(math.MaxInt64)
+	if start != "" {
+		t, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid start time: %w", err)
+		}
+		min = t.UnixNano()
+	}
+	if end != "" {
+		t, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid end time: %w", err)
+		}
+		max = t.UnixNano()
+	}
+	if min > max {
+		return 0, 0, errors.New("start time must not be after end time")
+	}
+	return min, max, nil
+}
+
+type lpExporter struct {
+	stdout, stderr io.Writer
+
+	outputPath   string
+	measurements []string
+	start, end   int64
+	compress     bool
+
+	filter map[string]struct{}
+	lines  int
+}
+
+func (e *lpExporter) run(dataDir, walDir string) error {
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		return fmt.Errorf("no data found for bucket in %s", dataDir)
+	} else if err != nil {
+		return err
+	}
+
+	if len(e.measurements) > 0 {
+		e.filter = make(map[string]struct{}, len(e.measurements))
+		for _, m := range e.measurements {
+			e.filter[m] = struct{}{}
+		}
+	}
+
+	var w io.Writer = e.stdout
+	var f *os.File
+	if e.outputPath != "-" {
+		var err error
+		if f, err = os.Create(e.outputPath); err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	var gw *gzip.Writer
+	if e.compress {
+		gw = gzip.NewWriter(w)
+		w = gw
+	}
+	bw := bufio.NewWriter(w)
+
+	if err := e.export(bw, dataDir, walDir); err != nil {
+		return err
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if gw != nil {
+		if err := gw.Close(); err != nil {
+			return err
+		}
+	}
+	if f != nil {
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(e.stderr, "Exported %d lines\n", e.lines)
+	return nil
+}
+
+// export writes the data of the TSM files and the WAL segments of each shard,
+// in order of shard ID.
+func (e *lpExporter) export(w io.Writer, dataDir, walDir string) error {
+	tsmFiles := map[string][]string{}
+	var shards []uint64
+	if err := walkTSMFiles(dataDir, func(_, _, id, path string) error {
+		if _, ok := tsmFiles[id]; !ok {
+			shardID, _ := strconv.ParseUint(id, 10, 64)
+			shards = append(shards, shardID)
+		}
+		tsmFiles[id] = append(tsmFiles[id], path)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var segments []string
+	if _, err := os.Stat(walDir); err == nil {
+		if segments, err = walFiles(walDir); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	walSegments := map[string][]string{}
+	for _, path := range segments {
+		id := shardIDOfPath(path)
+		if id == "" {
+			continue
+		}
+		if _, ok := tsmFiles[id]; !ok {
+			if _, ok := walSegments[id]; !ok {
+				shardID, _ := strconv.ParseUint(id, 10, 64)
+				shards = append(shards, shardID)
+			}
+		}
+		walSegments[id] = append(walSegments[id], path)
+	}
+	sort.Slice(shards, func(i, j int) bool { return shards[i] < shards[j] })
+
+	for _, shardID := range shards {
+		id := strconv.FormatUint(shardID, 10)
+		for _, path := range tsmFiles[id] {
+			if err := e.exportTSMFile(w, path); err != nil {
+				return err
+			}
+		}
+		for _, path := range walSegments[id] {
+			if err := e.exportWALFile(w, path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// shardIDOfPath returns the ID of the shard the file at path belongs to, or
+// an empty string if its directory is not a shard directory.
+func shardIDOfPath(path string) string {
+	id := filepath.Base(filepath.Dir(path))
+	if n, err := strconv.ParseUint(id, 10, 64); err != nil || n < 1 {
+		return ""
+	}
+	return id
+}
+
+func (e *lpExporter) exportTSMFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "error opening %q, skipping: %v\n", path, err)
+		return nil
+	}
+
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "error reading %q, skipping: %v\n", path, err)
+		f.Close()
+		return nil
+	}
+	defer r.Close()
+
+	if !r.OverlapsTimeRange(e.start, e.end) {
+		return nil
+	}
+
+	for i := 0; i < r.KeyCount(); i++ {
+		key, _ := r.KeyAt(i)
+		seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+		if !e.matches(seriesKey) {
+			continue
+		}
+
+		values, err := r.ReadAll(key)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "error reading key %q in %q, skipping: %v\n", key, path, err)
+			continue
+		}
+		if err := e.writeValues(w, seriesKey, field, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *lpExporter) exportWALFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "error opening %q, skipping: %v\n", path, err)
+		return nil
+	}
+
+	r := tsm1.NewWALSegmentReader(f)
+	defer r.Close()
+
+	for r.Next() {
+		entry, err := r.Read()
+		if err != nil {
+			fmt.Fprintf(e.stderr, "%q is corrupt after %d valid bytes, skipping the rest: %v\n", path, r.Count(), err)
+			return nil
+		}
+
+		// Deletes are not applied: the data they remove may have been
+		// exported already.
+		t, ok := entry.(*tsm1.WriteWALEntry)
+		if !ok {
+			continue
+		}
+
+		keys := make([]string, 0, len(t.Values))
+		for key := range t.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey([]byte(key))
+			if !e.matches(seriesKey) {
+				continue
+			}
+			if err := e.writeValues(w, seriesKey, field, t.Values[key]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matches returns true if the series with the given key is exported.
+func (e *lpExporter) matches(seriesKey []byte) bool {
+	if e.filter == nil {
+		return true
+	}
+	_, ok := e.filter[string(models.ParseName(seriesKey))]
+	return ok
+}
+
+// writeValues writes the values of field of a series within the time range as
+// lines of line protocol.
+func (e *lpExporter) writeValues(w io.Writer, seriesKey []byte, field []byte, values []tsm1.Value) error {
+	buf := make([]byte, 0, len(seriesKey)+len(field)+32)
+	buf = append(buf, seriesKey...)
+	buf = append(buf, ' ')
+	buf = append(buf, escape.String(string(field))...)
+	buf = append(buf, '=')
+	prefixSize := len(buf)
+
+	for _, value := range values {
+		ts := value.UnixNano()
+		if ts < e.start || ts > e.end {
+			continue
+		}
+
+		buf = buf[:prefixSize]
+		switch v := value.(type) {
+		case tsm1.FloatValue:
+			buf = strconv.AppendFloat(buf, v.RawValue(), 'g', -1, 64)
+		case tsm1.IntegerValue:
+			buf = strconv.AppendInt(buf, v.RawValue(), 10)
+			buf = append(buf, 'i')
+		case tsm1.UnsignedValue:
+			buf = strconv.AppendUint(buf, v.RawValue(), 10)
+			buf = append(buf, 'u')
+		case tsm1.BooleanValue:
+			buf = strconv.AppendBool(buf, v.RawValue())
+		case tsm1.StringValue:
+			buf = append(buf, '"')
+			buf = append(buf, models.EscapeStringField(v.RawValue())...)
+			buf = append(buf, '"')
+		default:
+			return fmt.Errorf("unsupported value type %T for key %q", value, seriesKey)
+		}
+		buf = append(buf, ' ')
+		buf = strconv.AppendInt(buf, ts, 10)
+		buf = append(buf, '\n')
+
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+		e.lines++
+	}
+	return nil
+}
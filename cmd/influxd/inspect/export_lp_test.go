@@ -0,0 +1,186 @@
+package inspect
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+func TestExportLineProtocol(t *testing.T) {
+	e := newTestEngine(t)
+	e.writeShard(t, testBucketID, 1,
+		[]string{
+			`cpu,host=a value=1.5 1000000000`,
+			`cpu,host=a value=2 2000000000`,
+			`m\ 1,tag\,k=v\=1 f\ 1="a \"q\" \\ b",i=-2i,u=3u,b=true 3000000000`,
+		},
+		[]string{
+			`cpu,host=a value=5 2000000000`,
+			`mem free=4i 3000000000`,
+		},
+	)
+	// A shard holding only WAL segments is exported as well.
+	e.writeShard(t, testBucketID, 2, nil, []string{`cpu,host=b value=6 4000000000`})
+
+	tests := []struct {
+		name    string
+		args    []string
+		exp     []string
+		wantErr string
+	}{
+		{
+			name: "all",
+			exp: []string{
+				`cpu,host=a value=1.5 1000000000`,
+				`cpu,host=a value=2 2000000000`,
+				`m\ 1,tag\,k=v\=1 b=true 3000000000`,
+				`m\ 1,tag\,k=v\=1 f\ 1="a \"q\" \\ b" 3000000000`,
+				`m\ 1,tag\,k=v\=1 i=-2i 3000000000`,
+				`m\ 1,tag\,k=v\=1 u=3u 3000000000`,
+				`cpu,host=a value=5 2000000000`,
+				`mem free=4i 3000000000`,
+				`cpu,host=b value=6 4000000000`,
+			},
+		},
+		{
+			name: "measurements",
+			args: []string{"--measurement", "cpu,mem"},
+			exp: []string{
+				`cpu,host=a value=1.5 1000000000`,
+				`cpu,host=a value=2 2000000000`,
+				`cpu,host=a value=5 2000000000`,
+				`mem free=4i 3000000000`,
+				`cpu,host=b value=6 4000000000`,
+			},
+		},
+		{
+			name: "escaped measurement",
+			args: []string{"--measurement", "m 1"},
+			exp: []string{
+				`m\ 1,tag\,k=v\=1 b=true 3000000000`,
+				`m\ 1,tag\,k=v\=1 f\ 1="a \"q\" \\ b" 3000000000`,
+				`m\ 1,tag\,k=v\=1 i=-2i 3000000000`,
+				`m\ 1,tag\,k=v\=1 u=3u 3000000000`,
+			},
+		},
+		{
+			name: "time range",
+			args: []string{"--start", "1970-01-01T00:00:02Z", "--end", "1970-01-01T00:00:02Z"},
+			exp: []string{
+				`cpu,host=a value=2 2000000000`,
+				`cpu,host=a value=5 2000000000`,
+			},
+		},
+		{
+			name: "start",
+			args: []string{"--start", "1970-01-01T00:00:03.5Z"},
+			exp: []string{
+				`cpu,host=b value=6 4000000000`,
+			},
+		},
+		{
+			name: "end",
+			args: []string{"--end", "1970-01-01T00:00:01Z"},
+			exp: []string{
+				`cpu,host=a value=1.5 1000000000`,
+			},
+		},
+		{
+			name:    "start after end",
+			args:    []string{"--start", "1970-01-01T00:00:03Z", "--end", "1970-01-01T00:00:02Z"},
+			wantErr: "start time must not be after end time",
+		},
+		{
+			name:    "invalid start",
+			args:    []string{"--start", "yesterday"},
+			wantErr: "invalid start time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--engine-path", e.path, "--bucket-id", testBucketID, "--output-path", "-", "--compress=false"}, tt.args...)
+			out, err := runCommand(t, NewExportLineProtocolCommand(), args...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, exp := out, strings.Join(tt.exp, "\n")+"\n"; got != exp {
+				t.Fatalf("unexpected line protocol:\ngot:\n%s\nexp:\n%s", got, exp)
+			}
+		})
+	}
+}
+
+// TestExportLineProtocol_WriteBack ensures that writing the exported line
+// protocol back yields the data of the bucket, with the points of the WAL
+// overriding the points of the TSM files.
+func TestExportLineProtocol_WriteBack(t *testing.T) {
+	e := newTestEngine(t)
+	e.writeShard(t, testBucketID, 1,
+		[]string{`cpu,host=a value=1,s="x" 1000000000`, `cpu,host=a value=2,s="y" 2000000000`},
+		[]string{`cpu,host=a value=5 2000000000`, `cpu,host=a s="z" 1000000000`},
+	)
+
+	path := filepath.Join(t.TempDir(), "export.lp.gz")
+	if _, err := runCommand(t, NewExportLineProtocolCommand(), "--engine-path", e.path, "--bucket-id", testBucketID, "--output-path", path); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := ioutil.ReadAll(gr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := models.ParsePoints(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]interface{}{}
+	for _, p := range points {
+		iter := p.FieldIterator()
+		for iter.Next() {
+			key := string(p.Key()) + " " + string(iter.FieldKey()) + " " + p.Time().UTC().Format("15:04:05")
+			switch iter.Type() {
+			case models.Float:
+				got[key], _ = iter.FloatValue()
+			case models.String:
+				got[key] = iter.StringValue()
+			}
+		}
+	}
+
+	exp := map[string]interface{}{
+		"cpu,host=a value 00:00:01": 1.0,
+		"cpu,host=a value 00:00:02": 5.0,
+		"cpu,host=a s 00:00:01":     "z",
+		"cpu,host=a s 00:00:02":     "y",
+	}
+	if len(got) != len(exp) {
+		t.Fatalf("unexpected values: got %v, exp %v", got, exp)
+	}
+	for k, v := range exp {
+		if got[k] != v {
+			t.Errorf("%s: got %v, exp %v", k, got[k], v)
+		}
+	}
+}
@@ -18,17 +18,21 @@ func NewCommand() *cobra.Command {
 	// List of available sub-commands
 	// If a new sub-command is created, it must be added here
 	subCommands := []*cobra.Command{
-		//NewBuildTSICommand(),
-		//NewCompactSeriesFileCommand(),
-		//NewExportBlocksCommand(),
+		NewBuildTSICommand(),
+		NewCompactSeriesFileCommand(),
+		NewExportBlocksCommand(),
 		NewExportIndexCommand(),
-		//NewReportTSMCommand(),
-		//NewVerifyTSMCommand(),
-		//NewVerifyWALCommand(),
-		//NewReportTSICommand(),
-		//NewVerifySeriesFileCommand(),
-		//NewDumpWALCommand(),
-		//NewDumpTSICommand(),
+		NewExportLineProtocolCommand(),
+		NewReportTSMCommand(),
+		NewReportDiskCommand(),
+		NewVerifyTSMCommand(),
+		NewVerifyWALCommand(),
+		NewReportTSICommand(),
+		NewVerifySeriesFileCommand(),
+		NewVerifyTombstoneCommand(),
+		NewDumpTSMCommand(),
+		NewDumpWALCommand(),
+		NewDumpTSICommand(),
 	}
 
 	base.AddCommand(subCommands...)
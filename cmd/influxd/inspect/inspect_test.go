@@ -0,0 +1,279 @@
+package inspect
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
+	"github.com/spf13/cobra"
+)
+
+const (
+	testBucketID = "0000000000000001"
+	testRP       = "autogen"
+)
+
+// testEngine is the path of the files of a storage engine, written with the
+// tsdb store the storage engine uses.
+type testEngine struct {
+	path string
+}
+
+func newTestEngine(t *testing.T) *testEngine {
+	t.Helper()
+	return &testEngine{path: t.TempDir()}
+}
+
+func (e *testEngine) dataDir() string { return filepath.Join(e.path, "data") }
+func (e *testEngine) walDir() string  { return filepath.Join(e.path, "wal") }
+
+// shardDir returns the data directory of a shard of the test bucket.
+func (e *testEngine) shardDir(shardID string) string {
+	return filepath.Join(e.dataDir(), testBucketID, testRP, shardID)
+}
+
+// shardWALDir returns the WAL directory of a shard of the test bucket.
+func (e *testEngine) shardWALDir(shardID string) string {
+	return filepath.Join(e.walDir(), testBucketID, testRP, shardID)
+}
+
+// seriesFileDir returns the directory of the series file of the test bucket.
+func (e *testEngine) seriesFileDir() string {
+	return filepath.Join(e.dataDir(), testBucketID, tsdb.SeriesFileDirectory)
+}
+
+// writeShard writes the points of tsm, in line protocol, to the TSM files of
+// a shard of bucketID, and then the points of wal to its WAL segments.
+func (e *testEngine) writeShard(t *testing.T, bucketID string, shardID uint64, tsm, wal []string) {
+	t.Helper()
+
+	s := tsdb.NewStore(e.dataDir())
+	s.EngineOptions.IndexVersion = tsi1.IndexName
+	s.EngineOptions.Config.WALDir = e.walDir()
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.CreateShard(bucketID, testRP, shardID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		points, err := models.ParsePointsString(strings.Join(lines, "\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.WriteToShard(shardID, points); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(tsm)
+	if len(tsm) > 0 {
+		engine, err := s.Shard(shardID).Engine()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.(*tsm1.Engine).WriteSnapshot(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(wal)
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runCommand runs cmd with args and returns its standard output.
+func runCommand(t *testing.T, cmd *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	cmd.SetArgs(args)
+	cmd.SetIn(strings.NewReader("y\n"))
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SilenceUsage = true
+	err := cmd.Execute()
+	if testing.Verbose() {
+		t.Logf("%s %s\nstdout:\n%s\nstderr:\n%s", cmd.Name(), strings.Join(args, " "), stdout.String(), stderr.String())
+	}
+	return stdout.String(), err
+}
+
+// TestCommands runs each command against a generated shard.
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  func() *cobra.Command
+
+		// args returns the arguments of the command for e.
+		args func(e *testEngine) []string
+
+		// prepare modifies the files of the engine before running the command.
+		prepare func(t *testing.T, e *testEngine)
+		expect  []string
+
+		// check verifies the files of the engine after running the command.
+		check func(t *testing.T, e *testEngine)
+	}{
+		{
+			name:   "report-tsm",
+			cmd:    NewReportTSMCommand,
+			args:   func(e *testEngine) []string { return []string{"--engine-path", e.path, "--detailed", "--exact"} },
+			expect: []string{"Files: 1", "- " + testBucketID + ": 2 (100%)", "- cpu: 1 (50%)", "- host: 2"},
+		},
+		{
+			name:   "verify-tsm",
+			cmd:    NewVerifyTSMCommand,
+			args:   func(e *testEngine) []string { return []string{"--engine-path", e.path} },
+			expect: []string{"000000001-000000001.tsm: healthy", "Broken Blocks: 0 / 2"},
+		},
+		{
+			name: "verify-tsm corrupt",
+			cmd:  NewVerifyTSMCommand,
+			args: func(e *testEngine) []string { return []string{"--engine-path", e.path} },
+			prepare: func(t *testing.T, e *testEngine) {
+				// Flip a byte of the data of the first block, after the
+				// header of the file and the checksum of the block.
+				f, err := os.OpenFile(filepath.Join(e.shardDir("1"), "000000001-000000001.tsm"), os.O_RDWR, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+				b := make([]byte, 1)
+				if _, err := f.ReadAt(b, 10); err != nil {
+					t.Fatal(err)
+				}
+				b[0] ^= 0xff
+				if _, err := f.WriteAt(b, 10); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expect: []string{"Broken Blocks: 1 / 2"},
+		},
+		{
+			name:   "verify-tsm utf8",
+			cmd:    NewVerifyTSMCommand,
+			args:   func(e *testEngine) []string { return []string{"--engine-path", e.path, "--check-utf8"} },
+			expect: []string{"Invalid Keys: 0 / 2"},
+		},
+		{
+			name: "export-blocks",
+			cmd:  NewExportBlocksCommand,
+			args: func(e *testEngine) []string {
+				return []string{filepath.Join(e.shardDir("1"), "000000001-000000001.tsm")}
+			},
+			expect: []string{"CREATE TABLE IF NOT EXISTS blocks", "'cpu', 'host=a', 'value', 'cpu,host=a', 10, 10, 'float'", "COMMIT;"},
+		},
+		{
+			name:   "verify-wal",
+			cmd:    NewVerifyWALCommand,
+			args:   func(e *testEngine) []string { return []string{"--engine-path", e.path, "--bucket-id", testBucketID} },
+			expect: []string{"Files checked: 1", "Total entries: 1", "No invalid files found"},
+		},
+		{
+			name: "dump-wal",
+			cmd:  NewDumpWALCommand,
+			args: func(e *testEngine) []string {
+				return []string{"--values", filepath.Join(e.shardWALDir("1"), "_00002.wal")}
+			},
+			expect: []string{"[write] sz=42 keys=1 points=1", "cpu,host=a#!~#value 30 3"},
+		},
+		{
+			name:   "report-tsi",
+			cmd:    NewReportTSICommand,
+			args:   func(e *testEngine) []string { return []string{"--engine-path", e.path, "--bucket-id", testBucketID} },
+			expect: []string{"Cardinality (exact): 2", "Shard ID: 1", `"cpu"`, `"mem"`},
+		},
+		{
+			name: "dump-tsi",
+			cmd:  NewDumpTSICommand,
+			args: func(e *testEngine) []string {
+				return []string{"--series-file", e.seriesFileDir(), "--series", filepath.Join(e.shardDir("1"), "index")}
+			},
+			expect: []string{"cpu,host=a", "mem,host=b"},
+		},
+		{
+			name:   "verify-seriesfile",
+			cmd:    NewVerifySeriesFileCommand,
+			args:   func(e *testEngine) []string { return []string{"--engine-path", e.path} },
+			expect: []string{filepath.Join(testBucketID, tsdb.SeriesFileDirectory) + ": valid"},
+		},
+		{
+			name: "verify-tombstone",
+			cmd:  NewVerifyTombstoneCommand,
+			args: func(e *testEngine) []string { return []string{"--engine-path", e.path, "-vv"} },
+			prepare: func(t *testing.T, e *testEngine) {
+				ts := tsm1.NewTombstoner(filepath.Join(e.shardDir("1"), "000000001-000000001.tsm"), nil)
+				if err := ts.AddRange([][]byte{[]byte("cpu,host=a#!~#value")}, 0, 15); err != nil {
+					t.Fatal(err)
+				}
+				if err := ts.Flush(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expect: []string{`key: "cpu,host=a#!~#value", min: 0, max: 15`, "Verified 1 entries"},
+		},
+		{
+			name:   "compact-series-file",
+			cmd:    NewCompactSeriesFileCommand,
+			args:   func(e *testEngine) []string { return []string{"--engine-path", e.path} },
+			expect: []string{"compacted  ", filepath.Join(tsdb.SeriesFileDirectory, "07")},
+		},
+		{
+			name: "build-tsi",
+			cmd:  NewBuildTSICommand,
+			args: func(e *testEngine) []string { return []string{"--engine-path", e.path, "--shard", "1"} },
+			prepare: func(t *testing.T, e *testEngine) {
+				if err := os.RemoveAll(filepath.Join(e.shardDir("1"), "index")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			check: func(t *testing.T, e *testEngine) {
+				out, err := runCommand(t, NewReportTSICommand(), "--engine-path", e.path, "--bucket-id", testBucketID)
+				if err != nil {
+					t.Fatal(err)
+				} else if !strings.Contains(out, "Cardinality (exact): 2") {
+					t.Fatalf("unexpected cardinality of the rebuilt index:\n%s", out)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			e.writeShard(t, testBucketID, 1,
+				[]string{"cpu,host=a value=1 10", "mem,host=b free=2i 20"},
+				[]string{"cpu,host=a value=3 30"},
+			)
+			if tt.prepare != nil {
+				tt.prepare(t, e)
+			}
+
+			out, err := runCommand(t, tt.cmd(), tt.args(e)...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, s := range tt.expect {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected output to contain %q, got:\n%s", s, out)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, e)
+			}
+		})
+	}
+}
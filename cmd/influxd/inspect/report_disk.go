@@ -0,0 +1,383 @@
+package inspect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+	bbolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// NewReportDiskCommand returns a new instance of the report-disk command.
+func NewReportDiskCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report-disk",
+		Short: "Report the disk usage of buckets and shards",
+		Long: `
+This command will report the bytes used on disk by the TSM files, WAL
+segments and tsi1 index of each shard, and by the series file of each
+bucket. The names of the buckets and of their organizations are read
+from the bolt file, if it can be opened.
+
+With --detailed, the bytes used by each measurement are estimated from
+the sizes of the blocks referenced by the index of the TSM files.`,
+		Args: cobra.NoArgs,
+	}
+
+	var flags engineFlags
+	r := &diskReporter{}
+	flags.register(cmd)
+	flags.registerBucketID(cmd, "Only report on the bucket with this ID")
+	var defaultBoltPath string
+	if dir, err := fs.InfluxDir(); err == nil {
+		defaultBoltPath = filepath.Join(dir, bolt.DefaultFilename)
+	}
+	cmd.Flags().StringVar(&r.boltPath, "bolt-path", defaultBoltPath, "Path to the bolt file, to resolve the names of buckets")
+	cmd.Flags().BoolVar(&r.detailed, "detailed", false, "Estimate the bytes used by each measurement")
+	cmd.Flags().BoolVar(&r.json, "json", false, "Output the report as JSON")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		r.stdout, r.stderr = cmd.OutOrStdout(), cmd.ErrOrStderr()
+		return r.run(flags.dataDir(), flags.walDir(), flags.bucketID)
+	}
+
+	return cmd
+}
+
+// diskUsage is the number of bytes used on disk by each kind of file.
+type diskUsage struct {
+	TSM        int64 `json:"tsm"`
+	WAL        int64 `json:"wal"`
+	Index      int64 `json:"index"`
+	SeriesFile int64 `json:"seriesFile"`
+	Total      int64 `json:"total"`
+}
+
+func (u *diskUsage) add(other diskUsage) {
+	u.TSM += other.TSM
+	u.WAL += other.WAL
+	u.Index += other.Index
+	u.SeriesFile += other.SeriesFile
+	u.Total += other.Total
+}
+
+type shardDiskUsage struct {
+	ID              uint64 `json:"id"`
+	RetentionPolicy string `json:"retentionPolicy"`
+	diskUsage
+}
+
+type measurementDiskUsage struct {
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+}
+
+type bucketDiskUsage struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Org  string `json:"org,omitempty"`
+	diskUsage
+	Shards       []*shardDiskUsage       `json:"shards"`
+	Measurements []*measurementDiskUsage `json:"measurements,omitempty"`
+}
+
+type diskReport struct {
+	Buckets []*bucketDiskUsage `json:"buckets"`
+	Total   diskUsage          `json:"total"`
+}
+
+type diskReporter struct {
+	stdout, stderr io.Writer
+
+	boltPath       string
+	detailed, json bool
+}
+
+func (r *diskReporter) run(dataDir, walDir, bucketID string) error {
+	dirs, err := ioutil.ReadDir(dataDir)
+	if err != nil {
+		return err
+	}
+
+	report := &diskReport{}
+	for _, fi := range dirs {
+		if !fi.IsDir() || (bucketID != "" && fi.Name() != bucketID) {
+			continue
+		}
+		b, err := r.bucketUsage(filepath.Join(dataDir, fi.Name()), filepath.Join(walDir, fi.Name()))
+		if err != nil {
+			return err
+		}
+		b.ID = fi.Name()
+		report.Buckets = append(report.Buckets, b)
+		report.Total.add(b.diskUsage)
+	}
+	if bucketID != "" && len(report.Buckets) == 0 {
+		return fmt.Errorf("no data found for bucket %s", bucketID)
+	}
+
+	if err := r.resolveNames(report.Buckets); err != nil {
+		fmt.Fprintf(r.stderr, "unable to resolve bucket names, reporting IDs only: %v\n", err)
+	}
+
+	sort.Slice(report.Buckets, func(i, j int) bool {
+		return report.Buckets[i].Total > report.Buckets[j].Total
+	})
+
+	if r.json {
+		enc := json.NewEncoder(r.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	r.printReport(report)
+	return nil
+}
+
+// bucketUsage returns the disk usage of the bucket with the given data and WAL
+// directories.
+func (r *diskReporter) bucketUsage(dataDir, walDir string) (*bucketDiskUsage, error) {
+	b := &bucketDiskUsage{Shards: []*shardDiskUsage{}}
+	var measurements map[string]*measurementDiskUsage
+	if r.detailed {
+		measurements = map[string]*measurementDiskUsage{}
+	}
+
+	size, err := dirSize(filepath.Join(dataDir, tsdb.SeriesFileDirectory), nil)
+	if err != nil {
+		return nil, err
+	}
+	b.SeriesFile, b.Total = size, size
+
+	rps, err := ioutil.ReadDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, rp := range rps {
+		if !rp.IsDir() || rp.Name() == tsdb.SeriesFileDirectory {
+			continue
+		}
+		shards, err := ioutil.ReadDir(filepath.Join(dataDir, rp.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, shard := range shards {
+			id, err := strconv.ParseUint(shard.Name(), 10, 64)
+			if !shard.IsDir() || err != nil {
+				continue
+			}
+
+			s := &shardDiskUsage{ID: id, RetentionPolicy: rp.Name()}
+			if err := r.shardUsage(s, filepath.Join(dataDir, rp.Name(), shard.Name()), filepath.Join(walDir, rp.Name(), shard.Name()), measurements); err != nil {
+				return nil, err
+			}
+			b.Shards = append(b.Shards, s)
+			b.add(s.diskUsage)
+		}
+	}
+	sort.Slice(b.Shards, func(i, j int) bool { return b.Shards[i].ID < b.Shards[j].ID })
+
+	for _, m := range measurements {
+		b.Measurements = append(b.Measurements, m)
+	}
+	sort.Slice(b.Measurements, func(i, j int) bool {
+		if b.Measurements[i].Bytes != b.Measurements[j].Bytes {
+			return b.Measurements[i].Bytes > b.Measurements[j].Bytes
+		}
+		return b.Measurements[i].Name < b.Measurements[j].Name
+	})
+	return b, nil
+}
+
+// shardUsage sets the disk usage of the shard with the given data and WAL
+// directories, and adds the estimated size of its measurements, if not nil.
+func (r *diskReporter) shardUsage(s *shardDiskUsage, dataDir, walDir string, measurements map[string]*measurementDiskUsage) error {
+	var err error
+	if s.Index, err = dirSize(filepath.Join(dataDir, "index"), nil); err != nil {
+		return err
+	}
+	if s.TSM, err = dirSize(dataDir, func(path string) bool {
+		ext := filepath.Ext(path)
+		return ext == "."+tsm1.TSMFileExtension || ext == "."+tsm1.TombstoneFileExtension
+	}); err != nil {
+		return err
+	}
+	if s.WAL, err = dirSize(walDir, func(path string) bool {
+		return filepath.Ext(path) == "."+tsm1.WALFileExtension
+	}); err != nil {
+		return err
+	}
+	s.Total = s.TSM + s.WAL + s.Index
+
+	if measurements == nil {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(dataDir, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		if err := r.measurementUsage(path, measurements); err != nil {
+			fmt.Fprintf(r.stderr, "error reading %q, skipping: %v\n", path, err)
+		}
+	}
+	return nil
+}
+
+// measurementUsage adds the sizes of the blocks of the TSM file at path to the
+// measurements they belong to.
+func (r *diskReporter) measurementUsage(path string, measurements map[string]*measurementDiskUsage) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	reader, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	defer reader.Close()
+
+	var entries []tsm1.IndexEntry
+	for i := 0; i < reader.KeyCount(); i++ {
+		key, _ := reader.KeyAt(i)
+		seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+		name := string(models.ParseName(seriesKey))
+
+		m := measurements[name]
+		if m == nil {
+			m = &measurementDiskUsage{Name: name}
+			measurements[name] = m
+		}
+		for _, e := range reader.ReadEntries(key, &entries) {
+			m.Bytes += int64(e.Size)
+		}
+	}
+	return nil
+}
+
+// dirSize returns the number of bytes used by the files under dir accepted by
+// match, or by all files if match is nil. A missing directory uses no bytes.
+func dirSize(dir string, match func(path string) bool) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			// Only the top level of a shard holds its TSM files.
+			if match != nil && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if match == nil || match(path) {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// resolveNames sets the names of the buckets, and of their organizations, from
+// the bolt file.
+func (r *diskReporter) resolveNames(buckets []*bucketDiskUsage) error {
+	if r.boltPath == "" || len(buckets) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(r.boltPath); err != nil {
+		return err
+	}
+
+	// The bolt file is locked while the server is running.
+	db, err := bbolt.Open(r.boltPath, 0600, &bbolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("open %s: %w", r.boltPath, err)
+	}
+	defer db.Close()
+
+	kvStore := bolt.NewKVStore(zap.NewNop(), r.boltPath)
+	kvStore.WithDB(db)
+	svc := tenant.NewService(tenant.NewStore(kvStore))
+
+	ctx := context.Background()
+	orgs, _, err := svc.FindOrganizations(ctx, influxdb.OrganizationFilter{})
+	if err != nil {
+		return err
+	}
+	orgNames := make(map[influxdb.ID]string, len(orgs))
+	for _, o := range orgs {
+		orgNames[o.ID] = o.Name
+	}
+
+	for _, b := range buckets {
+		id, err := influxdb.IDFromString(b.ID)
+		if err != nil {
+			continue
+		}
+		bucket, err := svc.FindBucketByID(ctx, *id)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		b.Name, b.Org = bucket.Name, orgNames[bucket.OrgID]
+	}
+	return nil
+}
+
+func (r *diskReporter) printReport(report *diskReport) {
+	tw := tabwriter.NewWriter(r.stdout, 8, 2, 1, ' ', 0)
+	fmt.Fprintln(tw, "Bucket\tName\tOrg\tShards\tTSM\tWAL\tIndex\tSeries File\tTotal")
+	for _, b := range report.Buckets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			b.ID, b.Name, b.Org, len(b.Shards),
+			formatSize(uint64(b.TSM)), formatSize(uint64(b.WAL)), formatSize(uint64(b.Index)),
+			formatSize(uint64(b.SeriesFile)), formatSize(uint64(b.Total)))
+	}
+	fmt.Fprintf(tw, "Total\t\t\t\t%s\t%s\t%s\t%s\t%s\n",
+		formatSize(uint64(report.Total.TSM)), formatSize(uint64(report.Total.WAL)), formatSize(uint64(report.Total.Index)),
+		formatSize(uint64(report.Total.SeriesFile)), formatSize(uint64(report.Total.Total)))
+	tw.Flush()
+
+	fmt.Fprintln(r.stdout)
+	fmt.Fprintln(tw, "Bucket\tRP\tShard\tTSM\tWAL\tIndex\tTotal")
+	for _, b := range report.Buckets {
+		for _, s := range b.Shards {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+				b.ID, s.RetentionPolicy, s.ID,
+				formatSize(uint64(s.TSM)), formatSize(uint64(s.WAL)), formatSize(uint64(s.Index)), formatSize(uint64(s.Total)))
+		}
+	}
+	tw.Flush()
+
+	if !r.detailed {
+		return
+	}
+	fmt.Fprintln(r.stdout)
+	fmt.Fprintln(tw, "Bucket\tMeasurement\tTSM (est)")
+	for _, b := range report.Buckets {
+		for _, m := range b.Measurements {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", b.ID, m.Name, formatSize(uint64(m.Bytes)))
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,250 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"go.uber.org/zap"
+)
+
+// fileSizes returns the number of bytes used by the files matching the glob
+// patterns, and by all the files under the directories matching them.
+func fileSizes(t *testing.T, patterns ...string) int64 {
+	t.Helper()
+	var size int64
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range matches {
+			if err := filepath.Walk(m, func(path string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() {
+					size += info.Size()
+				}
+				return err
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return size
+}
+
+// blockSizes returns the number of bytes used by the blocks of each
+// measurement in the TSM files of the shard directory.
+func blockSizes(t *testing.T, dir string) map[string]int64 {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := map[string]int64{}
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := tsm1.NewTSMReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		iter := r.BlockIterator()
+		for iter.Next() {
+			key, _, _, _, _, buf, err := iter.Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+			// Each block is prefixed with its checksum.
+			sizes[string(models.ParseName(seriesKey))] += int64(len(buf)) + 4
+		}
+		r.Close()
+	}
+	return sizes
+}
+
+// createBucket creates a bucket named name, in an organization named org, in
+// the bolt file at path.
+func createBucket(t *testing.T, path, org, name string) influxdb.ID {
+	t.Helper()
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	store := bolt.NewKVStore(logger, path, bolt.WithNoSync)
+	if err := store.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := all.Up(ctx, logger, store); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := tenant.NewService(tenant.NewStore(store))
+	o := &influxdb.Organization{Name: org}
+	if err := svc.CreateOrganization(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+	b := &influxdb.Bucket{OrgID: o.ID, Name: name}
+	if err := svc.CreateBucket(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	return b.ID
+}
+
+func runReportDisk(t *testing.T, e *testEngine, r *diskReporter, bucketID string) (*diskReport, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	r.stdout, r.stderr, r.json = &stdout, &stderr, true
+	if err := r.run(e.dataDir(), e.walDir(), bucketID); err != nil {
+		t.Fatal(err)
+	}
+	var report diskReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("unable to decode report: %v\n%s", err, stdout.String())
+	}
+	return &report, stderr.String()
+}
+
+func TestReportDisk(t *testing.T) {
+	e := newTestEngine(t)
+	boltPath := filepath.Join(t.TempDir(), bolt.DefaultFilename)
+	bucketID := createBucket(t, boltPath, "my-org", "my-bucket").String()
+
+	e.writeShard(t, bucketID, 1,
+		[]string{"cpu,host=a value=1 10", "cpu,host=a value=2 20", "cpu,host=b value=3 10", "mem,host=a free=1i 10"},
+		[]string{"cpu,host=a value=3 30"},
+	)
+	e.writeShard(t, bucketID, 2, nil, []string{"mem,host=b free=2i 40"})
+	// The bucket of this shard is unknown to the bolt file.
+	const otherID = "00000000000000ff"
+	e.writeShard(t, otherID, 3, nil, []string{"disk used=1i 10"})
+
+	report, stderr := runReportDisk(t, e, &diskReporter{boltPath: boltPath, detailed: true}, "")
+	if stderr != "" {
+		t.Fatalf("unexpected errors: %s", stderr)
+	}
+
+	if len(report.Buckets) != 2 {
+		t.Fatalf("unexpected number of buckets: %d", len(report.Buckets))
+	}
+	// Buckets are sorted by decreasing size.
+	b, other := report.Buckets[0], report.Buckets[1]
+	if b.ID != bucketID || b.Name != "my-bucket" || b.Org != "my-org" {
+		t.Fatalf("unexpected bucket: id=%s name=%q org=%q", b.ID, b.Name, b.Org)
+	}
+	if other.ID != otherID || other.Name != "" || other.Org != "" {
+		t.Fatalf("unexpected bucket: id=%s name=%q org=%q", other.ID, other.Name, other.Org)
+	}
+
+	var total diskUsage
+	for _, bucket := range report.Buckets {
+		shardPath := func(dir string, id uint64, elem ...string) string {
+			return filepath.Join(append([]string{dir, bucket.ID, testRP, strconv.FormatUint(id, 10)}, elem...)...)
+		}
+
+		var expBucket diskUsage
+		expBucket.SeriesFile = fileSizes(t, filepath.Join(e.dataDir(), bucket.ID, tsdb.SeriesFileDirectory))
+		expBucket.Total = expBucket.SeriesFile
+		for _, s := range bucket.Shards {
+			exp := diskUsage{
+				TSM:   fileSizes(t, shardPath(e.dataDir(), s.ID, "*.tsm")),
+				WAL:   fileSizes(t, shardPath(e.walDir(), s.ID, "*.wal")),
+				Index: fileSizes(t, shardPath(e.dataDir(), s.ID, "index")),
+			}
+			exp.Total = exp.TSM + exp.WAL + exp.Index
+			if s.diskUsage != exp {
+				t.Errorf("bucket %s, shard %d: got %+v, exp %+v", bucket.ID, s.ID, s.diskUsage, exp)
+			}
+			if s.RetentionPolicy != testRP {
+				t.Errorf("bucket %s, shard %d: unexpected retention policy %q", bucket.ID, s.ID, s.RetentionPolicy)
+			}
+			expBucket.add(exp)
+		}
+		if bucket.diskUsage != expBucket {
+			t.Errorf("bucket %s: got %+v, exp %+v", bucket.ID, bucket.diskUsage, expBucket)
+		}
+		total.add(expBucket)
+	}
+	if report.Total != total {
+		t.Errorf("total: got %+v, exp %+v", report.Total, total)
+	}
+
+	if got := len(b.Shards); got != 2 || b.Shards[0].ID != 1 || b.Shards[1].ID != 2 {
+		t.Fatalf("unexpected shards of bucket %s: %+v", bucketID, b.Shards)
+	}
+	if b.Shards[0].TSM == 0 || b.Shards[0].WAL == 0 || b.Shards[0].Index == 0 {
+		t.Errorf("expected TSM files, WAL segments and index for shard 1: %+v", b.Shards[0].diskUsage)
+	}
+	if b.Shards[1].TSM != 0 || b.Shards[1].WAL == 0 {
+		t.Errorf("expected only WAL segments for shard 2: %+v", b.Shards[1].diskUsage)
+	}
+
+	// Only the TSM files are used to estimate the size of the measurements.
+	exp := blockSizes(t, filepath.Join(e.dataDir(), bucketID, testRP, "1"))
+	if len(b.Measurements) != len(exp) {
+		t.Fatalf("unexpected measurements: %+v", b.Measurements)
+	}
+	// The cpu measurement holds more blocks than the mem measurement.
+	if b.Measurements[0].Name != "cpu" || b.Measurements[1].Name != "mem" {
+		t.Errorf("unexpected order of measurements: %s, %s", b.Measurements[0].Name, b.Measurements[1].Name)
+	}
+	for _, m := range b.Measurements {
+		if m.Bytes != exp[m.Name] {
+			t.Errorf("measurement %s: got %d bytes, exp %d", m.Name, m.Bytes, exp[m.Name])
+		}
+	}
+	if len(other.Measurements) != 0 {
+		t.Errorf("unexpected measurements for bucket %s: %+v", otherID, other.Measurements)
+	}
+
+	t.Run("bucket-id", func(t *testing.T) {
+		report, _ := runReportDisk(t, e, &diskReporter{boltPath: boltPath}, otherID)
+		if len(report.Buckets) != 1 || report.Buckets[0].ID != otherID {
+			t.Fatalf("unexpected buckets: %+v", report.Buckets)
+		}
+		if report.Total != report.Buckets[0].diskUsage {
+			t.Errorf("total: got %+v, exp %+v", report.Total, report.Buckets[0].diskUsage)
+		}
+	})
+
+	t.Run("missing bolt file", func(t *testing.T) {
+		report, stderr := runReportDisk(t, e, &diskReporter{boltPath: filepath.Join(t.TempDir(), "missing.bolt")}, "")
+		if !strings.Contains(stderr, "unable to resolve bucket names, reporting IDs only") {
+			t.Errorf("expected a note about the bucket names, got %q", stderr)
+		}
+		for _, b := range report.Buckets {
+			if b.Name != "" || b.Org != "" {
+				t.Errorf("unexpected names for bucket %s: %q, %q", b.ID, b.Name, b.Org)
+			}
+		}
+		if len(report.Buckets) != 2 || report.Total != total {
+			t.Errorf("unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		out, err := runCommand(t, NewReportDiskCommand(), "--engine-path", e.path, "--bolt-path", boltPath, "--detailed")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range []string{bucketID, "my-bucket", "my-org", otherID, "Total", "Measurement", "cpu", "mem"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("expected output to contain %q, got:\n%s", s, out)
+			}
+		}
+	})
+}
@@ -0,0 +1,314 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// NewReportTSICommand returns a new instance of the report-tsi command.
+func NewReportTSICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report-tsi",
+		Short: "Reports the cardinality of TSI indexes",
+		Long: `
+This command will report the exact series cardinality of the
+measurements of a bucket, in total and per shard, from the TSI index
+of its shards.`,
+		Args: cobra.NoArgs,
+	}
+
+	var flags engineFlags
+	r := &tsiReport{}
+	flags.register(cmd)
+	flags.registerBucketID(cmd, "ID of the bucket to report on")
+	_ = cmd.MarkFlagRequired("bucket-id")
+	cmd.Flags().StringVar(&r.seriesFilePath, "series-file", "", "Path to the series file of the bucket. Defaults to the _series directory of the bucket")
+	cmd.Flags().IntVarP(&r.topN, "top", "t", 0, "Limit results to the top n measurements")
+	cmd.Flags().IntVar(&r.concurrency, "concurrency", runtime.GOMAXPROCS(0), "Number of workers to dedicate to computing the cardinality of the shards")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		r.stdout, r.stderr = cmd.OutOrStdout(), cmd.ErrOrStderr()
+		r.bucketPath = flags.bucketDataDir()
+		if r.seriesFilePath == "" {
+			r.seriesFilePath = filepath.Join(r.bucketPath, tsdb.SeriesFileDirectory)
+		}
+		return r.run()
+	}
+
+	return cmd
+}
+
+type tsiReport struct {
+	stdout, stderr io.Writer
+
+	bucketPath     string
+	seriesFilePath string
+	topN           int
+	concurrency    int
+
+	shardPaths map[uint64]string
+	shardIdxs  map[uint64]*tsi1.Index
+
+	// The series of the measurements of each shard.
+	mu            sync.Mutex
+	cardinalities map[uint64]map[string]*tsdb.SeriesIDSet
+}
+
+func (r *tsiReport) run() error {
+	r.shardPaths = make(map[uint64]string)
+	r.shardIdxs = make(map[uint64]*tsi1.Index)
+	r.cardinalities = make(map[uint64]map[string]*tsdb.SeriesIDSet)
+
+	// Walk bucket directory to get shards.
+	if err := filepath.Walk(r.bucketPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if !info.IsDir() {
+			return nil
+		}
+
+		if info.Name() == tsdb.SeriesFileDirectory || info.Name() == "index" {
+			return filepath.SkipDir
+		}
+
+		id, err := strconv.ParseUint(info.Name(), 10, 64)
+		if err != nil {
+			return nil
+		}
+		r.shardPaths[id] = path
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if len(r.shardPaths) == 0 {
+		fmt.Fprintf(r.stderr, "No shards under %s\n", r.bucketPath)
+		return nil
+	}
+
+	sfile := tsdb.NewSeriesFile(r.seriesFilePath)
+	sfile.Logger = logger.New(r.stderr)
+	if err := sfile.Open(); err != nil {
+		return err
+	}
+	defer sfile.Close()
+
+	// Open all the indexes.
+	for id, path := range r.shardPaths {
+		path = filepath.Join(path, "index")
+
+		// Verify directory is an index before opening it.
+		if ok, err := tsi1.IsIndexDir(path); err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("not a TSI index directory: %q", path)
+		}
+
+		idx := tsi1.NewIndex(sfile, "", tsi1.WithPath(path), tsi1.DisableCompactions())
+		if err := idx.Open(); err != nil {
+			return err
+		}
+		defer idx.Close()
+		r.shardIdxs[id] = idx
+	}
+
+	// Blocks until all work done.
+	if err := r.calculateCardinalities(); err != nil {
+		return err
+	}
+
+	if err := r.printSummaryByMeasurement(sfile); err != nil {
+		return err
+	}
+
+	ids := make([]uint64, 0, len(r.shardIdxs))
+	for id := range r.shardIdxs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		if err := r.printShardByMeasurement(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// calculateCardinalities calculates the cardinality of the measurements of
+// the shards concurrently.
+func (r *tsiReport) calculateCardinalities() error {
+	ids := make(chan uint64, len(r.shardIdxs))
+	for id := range r.shardIdxs {
+		ids <- id
+	}
+	close(ids)
+
+	var g errgroup.Group
+	for i := 0; i < r.concurrency; i++ {
+		g.Go(func() error {
+			for id := range ids {
+				if err := r.cardinalityByMeasurement(id); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+func (r *tsiReport) cardinalityByMeasurement(shardID uint64) error {
+	idx := r.shardIdxs[shardID]
+	itr, err := idx.MeasurementIterator()
+	if err != nil {
+		return err
+	} else if itr == nil {
+		return nil
+	}
+	defer itr.Close()
+
+	sets := make(map[string]*tsdb.SeriesIDSet)
+	for {
+		name, err := itr.Next()
+		if err != nil {
+			return err
+		} else if name == nil {
+			break
+		}
+
+		sitr, err := idx.MeasurementSeriesIDIterator(name)
+		if err != nil {
+			return err
+		} else if sitr == nil {
+			continue
+		}
+
+		set := tsdb.NewSeriesIDSet()
+		var e tsdb.SeriesIDElem
+		for e, err = sitr.Next(); err == nil && e.SeriesID != 0; e, err = sitr.Next() {
+			set.AddNoLock(e.SeriesID)
+		}
+		sitr.Close()
+		if err != nil {
+			return err
+		}
+		sets[string(name)] = set
+	}
+
+	r.mu.Lock()
+	r.cardinalities[shardID] = sets
+	r.mu.Unlock()
+	return nil
+}
+
+type measurementCardinality struct {
+	name  string
+	count uint64
+}
+
+// sortCardinalities sorts the cardinalities from the highest, and returns
+// the top n of them if n is positive.
+func sortCardinalities(a []measurementCardinality, n int) []measurementCardinality {
+	sort.Slice(a, func(i, j int) bool {
+		if a[i].count != a[j].count {
+			return a[i].count > a[j].count
+		}
+		return a[i].name < a[j].name
+	})
+	if n > 0 && n < len(a) {
+		a = a[:n]
+	}
+	return a
+}
+
+func (r *tsiReport) printSummaryByMeasurement(sfile *tsdb.SeriesFile) error {
+	// Get global set of measurement names across shards.
+	idxs := &tsdb.IndexSet{SeriesFile: sfile}
+	for _, idx := range r.shardIdxs {
+		idxs.Indexes = append(idxs.Indexes, idx)
+	}
+
+	mitr, err := idxs.MeasurementIterator()
+	if err != nil {
+		return err
+	} else if mitr == nil {
+		return errors.New("got nil measurement iterator for index set")
+	}
+	defer mitr.Close()
+
+	var total uint64
+	var measurements []measurementCardinality
+	for {
+		name, err := mitr.Next()
+		if err != nil {
+			return err
+		} else if name == nil {
+			break
+		}
+
+		set := tsdb.NewSeriesIDSet()
+		for _, sets := range r.cardinalities {
+			if other, ok := sets[string(name)]; ok {
+				set.Merge(other)
+			}
+		}
+		count := set.Cardinality()
+		total += count
+		measurements = append(measurements, measurementCardinality{name: string(name), count: count})
+	}
+	measurements = sortCardinalities(measurements, r.topN)
+
+	tw := tabwriter.NewWriter(r.stdout, 4, 4, 1, '\t', 0)
+	fmt.Fprintf(tw, "Summary\nBucket Path: %s\nCardinality (exact): %d\n\n", r.bucketPath, total)
+	fmt.Fprint(tw, "Measurement\tCardinality (exact)\n\n")
+	for _, m := range measurements {
+		fmt.Fprintf(tw, "%q\t\t%d\n", m.name, m.count)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprint(r.stdout, "\n\n")
+	return nil
+}
+
+func (r *tsiReport) printShardByMeasurement(id uint64) error {
+	var total uint64
+	var measurements []measurementCardinality
+	for name, set := range r.cardinalities[id] {
+		count := set.Cardinality()
+		if count == 0 {
+			continue
+		}
+		total += count
+		measurements = append(measurements, measurementCardinality{name: name, count: count})
+	}
+	measurements = sortCardinalities(measurements, r.topN)
+
+	tw := tabwriter.NewWriter(r.stdout, 4, 4, 1, '\t', 0)
+	fmt.Fprintf(tw, "===============\nShard ID: %d\nPath: %s\nCardinality (exact): %d\n\n", id, r.shardPaths[id], total)
+	fmt.Fprint(tw, "Measurement\tCardinality (exact)\n\n")
+	for _, m := range measurements {
+		fmt.Fprintf(tw, "%q\t\t%d\n", m.name, m.count)
+	}
+	fmt.Fprint(tw, "===============\n\n")
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprint(r.stdout, "\n\n")
+	return nil
+}
@@ -0,0 +1,300 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator/hll"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewReportTSMCommand returns a new instance of the report-tsm command.
+func NewReportTSMCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report-tsm",
+		Short: "Run TSM report",
+		Long: `
+This command will analyze TSM files within the engine path and report
+the series they hold, per bucket and shard. With --detailed, the
+cardinality of measurements, fields and tags is reported as well.`,
+		Args: cobra.NoArgs,
+	}
+
+	var flags engineFlags
+	r := &tsmReport{}
+	flags.register(cmd)
+	flags.registerBucketID(cmd, "Only report on the TSM files of the bucket with this ID")
+	cmd.Flags().StringVar(&r.pattern, "pattern", "", "Only report on the TSM files whose path contains this pattern")
+	cmd.Flags().BoolVar(&r.detailed, "detailed", false, "Report the cardinality of measurements, fields and tags")
+	cmd.Flags().BoolVar(&r.exact, "exact", false, "Report exact cardinality counts instead of estimates. Note: this can use a lot of memory")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		r.stdout, r.stderr = cmd.OutOrStdout(), cmd.ErrOrStderr()
+		return r.run(flags.bucketDataDir())
+	}
+
+	return cmd
+}
+
+type tsmReport struct {
+	stdout, stderr io.Writer
+
+	pattern         string
+	detailed, exact bool
+}
+
+func (r *tsmReport) run(dir string) error {
+	newCounterFn := newHLLCounter
+	estTitle := " (est)"
+	if r.exact {
+		estTitle = ""
+		newCounterFn = newExactCounter
+	}
+
+	totalSeries := newCounterFn()
+	tagCardinalities := map[string]counter{}
+	measCardinalities := map[string]counter{}
+	fieldCardinalities := map[string]counter{}
+	bucketCardinalities := map[string]counter{}
+
+	start := time.Now()
+
+	tw := tabwriter.NewWriter(r.stdout, 8, 2, 1, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"Bucket", "RP", "Shard", "File", "Series", "New" + estTitle, "Min Time", "Max Time", "Load Time"}, "\t"))
+
+	minTime, maxTime := int64(math.MaxInt64), int64(math.MinInt64)
+	var fileCount int
+	if err := walkTSMFiles(dir, func(bucket, rp, id, path string) error {
+		if r.pattern != "" && !strings.Contains(path, r.pattern) {
+			return nil
+		}
+
+		file, err := os.OpenFile(path, os.O_RDONLY, 0600)
+		if err != nil {
+			fmt.Fprintf(r.stderr, "error opening %q, skipping: %v\n", path, err)
+			return nil
+		}
+
+		loadStart := time.Now()
+		reader, err := tsm1.NewTSMReader(file)
+		if err != nil {
+			fmt.Fprintf(r.stderr, "error reading %q, skipping: %v\n", file.Name(), err)
+			file.Close()
+			return nil
+		}
+		loadTime := time.Since(loadStart)
+		fileCount++
+
+		bucketCount := bucketCardinalities[bucket]
+		if bucketCount == nil {
+			bucketCount = newCounterFn()
+			bucketCardinalities[bucket] = bucketCount
+		}
+		oldCount := bucketCount.Count()
+
+		seriesCount := reader.KeyCount()
+		for i := 0; i < seriesCount; i++ {
+			key, _ := reader.KeyAt(i)
+			totalSeries.Add(key)
+			bucketCount.Add(key)
+
+			if r.detailed {
+				seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+				measurement, tags := models.ParseKeyBytes(seriesKey)
+
+				measCount := measCardinalities[string(measurement)]
+				if measCount == nil {
+					measCount = newCounterFn()
+					measCardinalities[string(measurement)] = measCount
+				}
+				measCount.Add(key)
+
+				fieldCount := fieldCardinalities[string(measurement)]
+				if fieldCount == nil {
+					fieldCount = newCounterFn()
+					fieldCardinalities[string(measurement)] = fieldCount
+				}
+				fieldCount.Add(field)
+
+				for _, t := range tags {
+					tagCount := tagCardinalities[string(t.Key)]
+					if tagCount == nil {
+						tagCount = newCounterFn()
+						tagCardinalities[string(t.Key)] = tagCount
+					}
+					tagCount.Add(t.Value)
+				}
+			}
+		}
+		minT, maxT := reader.TimeRange()
+		if minT < minTime {
+			minTime = minT
+		}
+		if maxT > maxTime {
+			maxTime = maxT
+		}
+		reader.Close()
+
+		fmt.Fprintln(tw, strings.Join([]string{
+			bucket, rp, id,
+			filepath.Base(file.Name()),
+			strconv.FormatInt(int64(seriesCount), 10),
+			strconv.FormatInt(int64(bucketCount.Count()-oldCount), 10),
+			time.Unix(0, minT).UTC().Format(time.RFC3339Nano),
+			time.Unix(0, maxT).UTC().Format(time.RFC3339Nano),
+			loadTime.String(),
+		}, "\t"))
+		if r.detailed {
+			tw.Flush()
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	tw.Flush()
+	fmt.Fprintln(r.stdout)
+
+	fmt.Fprintln(r.stdout, "Summary:")
+	fmt.Fprintf(r.stdout, "  Files: %d\n", fileCount)
+	if fileCount > 0 {
+		fmt.Fprintf(r.stdout, "  Time Range: %s - %s\n",
+			time.Unix(0, minTime).UTC().Format(time.RFC3339Nano),
+			time.Unix(0, maxTime).UTC().Format(time.RFC3339Nano),
+		)
+		fmt.Fprintf(r.stdout, "  Duration: %s \n", time.Unix(0, maxTime).Sub(time.Unix(0, minTime)))
+	}
+	fmt.Fprintln(r.stdout)
+
+	fmt.Fprintln(r.stdout, "Statistics")
+	fmt.Fprintln(r.stdout, "  Series:")
+	for _, bucket := range sortKeys(bucketCardinalities) {
+		count := bucketCardinalities[bucket].Count()
+		fmt.Fprintf(r.stdout, "     - %s%s: %d (%d%%)\n", bucket, estTitle, count, int(float64(count)/float64(totalSeries.Count())*100))
+	}
+	fmt.Fprintf(r.stdout, "  Total%s: %d\n", estTitle, totalSeries.Count())
+
+	if r.detailed {
+		fmt.Fprintf(r.stdout, "\n  Measurements%s:\n", estTitle)
+		for _, t := range sortKeys(measCardinalities) {
+			count := measCardinalities[t].Count()
+			fmt.Fprintf(r.stdout, "    - %v: %d (%d%%)\n", t, count, int(float64(count)/float64(totalSeries.Count())*100))
+		}
+
+		fmt.Fprintf(r.stdout, "\n  Fields%s:\n", estTitle)
+		for _, t := range sortKeys(fieldCardinalities) {
+			fmt.Fprintf(r.stdout, "    - %v: %d\n", t, fieldCardinalities[t].Count())
+		}
+
+		fmt.Fprintf(r.stdout, "\n  Tags%s:\n", estTitle)
+		for _, t := range sortKeys(tagCardinalities) {
+			fmt.Fprintf(r.stdout, "    - %v: %d\n", t, tagCardinalities[t].Count())
+		}
+	}
+
+	fmt.Fprintf(r.stdout, "Completed in %s\n", time.Since(start))
+	return nil
+}
+
+// sortKeys returns the sorted keys of a map.
+func sortKeys(vals map[string]counter) (keys []string) {
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// walkTSMFiles calls fn for every TSM file under root, which is the data
+// directory of the engine or of a bucket, in the order of their shard IDs.
+func walkTSMFiles(root string, fn func(bucket, rp, id, path string) error) error {
+	type location struct {
+		bucket, rp, id, path string
+		shardID              uint64
+	}
+
+	var files []location
+	if err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if info.IsDir() || filepath.Ext(info.Name()) != "."+tsm1.TSMFileExtension {
+			return nil
+		}
+
+		shardDir := filepath.Dir(path)
+		id, err := strconv.ParseUint(filepath.Base(shardDir), 10, 64)
+		if err != nil || id < 1 {
+			return fmt.Errorf("not a valid shard dir: %v", shardDir)
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(absPath, string(filepath.Separator))
+		if len(parts) < 4 {
+			return fmt.Errorf("not a valid shard dir: %v", shardDir)
+		}
+		files = append(files, location{
+			bucket:  parts[len(parts)-4],
+			rp:      parts[len(parts)-3],
+			id:      parts[len(parts)-2],
+			path:    path,
+			shardID: id,
+		})
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].shardID < files[j].shardID
+	})
+
+	for _, f := range files {
+		if err := fn(f.bucket, f.rp, f.id, f.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// counter abstracts a method of counting keys.
+type counter interface {
+	Add(key []byte)
+	Count() uint64
+}
+
+// newHLLCounter returns an approximate counter using HyperLogLogs for cardinality estimation.
+func newHLLCounter() counter {
+	return hll.NewDefaultPlus()
+}
+
+// exactCounter counts keys exactly, by keeping the set of distinct keys.
+type exactCounter struct {
+	m map[string]struct{}
+}
+
+func (c *exactCounter) Add(key []byte) {
+	c.m[string(key)] = struct{}{}
+}
+
+func (c *exactCounter) Count() uint64 {
+	return uint64(len(c.m))
+}
+
+func newExactCounter() counter {
+	return &exactCounter{
+		m: make(map[string]struct{}),
+	}
+}
@@ -0,0 +1,74 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2/cmd/influx_inspect/verify/seriesfile"
+	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/spf13/cobra"
+)
+
+// NewVerifySeriesFileCommand returns a new instance of the verify-seriesfile command.
+func NewVerifySeriesFileCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-seriesfile",
+		Short: "Verifies the integrity of series files",
+		Long: `
+This command will verify the segments and indexes of the partitions of
+the series files of buckets. Run with --verbose to log the problems
+found in an invalid series file.`,
+		Args: cobra.NoArgs,
+	}
+
+	var flags engineFlags
+	var seriesFilePath string
+	var verbose bool
+	v := seriesfile.NewVerify()
+	flags.register(cmd)
+	flags.registerBucketID(cmd, "Only verify the series file of the bucket with this ID")
+	cmd.Flags().StringVar(&seriesFilePath, "series-file", "", "Path to a series file to verify, instead of the series files of the buckets")
+	cmd.Flags().IntVar(&v.Concurrent, "concurrency", v.Concurrent, "Number of workers to dedicate to verifying the partitions of a series file")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Log the progress of the verification and the problems found")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if verbose {
+			v.Logger = logger.New(cmd.ErrOrStderr())
+		}
+
+		var paths []string
+		switch {
+		case seriesFilePath != "":
+			paths = []string{seriesFilePath}
+		case flags.bucketID != "":
+			paths = []string{filepath.Join(flags.bucketDataDir(), tsdb.SeriesFileDirectory)}
+		default:
+			var err error
+			if paths, err = filepath.Glob(filepath.Join(flags.dataDir(), "*", tsdb.SeriesFileDirectory)); err != nil {
+				return err
+			}
+		}
+
+		var invalid int
+		for _, path := range paths {
+			valid, err := v.VerifySeriesFile(path)
+			if err != nil {
+				return fmt.Errorf("verify %s: %w", path, err)
+			}
+			status := "valid"
+			if !valid {
+				status = "invalid"
+				invalid++
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", path, status)
+		}
+		if invalid > 0 {
+			return errors.New("failed series file verification")
+		}
+		return nil
+	}
+
+	return cmd
+}
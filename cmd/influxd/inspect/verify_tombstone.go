@@ -0,0 +1,31 @@
+package inspect
+
+import (
+	"github.com/influxdata/influxdb/v2/cmd/influx_inspect/verify/tombstone"
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyTombstoneCommand returns a new instance of the verify-tombstone command.
+func NewVerifyTombstoneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-tombstone",
+		Short: "Verifies the integrity of tombstone files",
+		Long: `
+This command will verify that the entries of the tombstone files of the
+shards can be read.`,
+		Args: cobra.NoArgs,
+	}
+
+	var flags engineFlags
+	v := tombstone.NewCommand()
+	flags.register(cmd)
+	flags.registerBucketID(cmd, "Only verify the tombstone files of the bucket with this ID")
+	cmd.Flags().CountVarP(&v.Verbosity, "verbose", "v", "Emit periodic progress (-v), every entry (-vv) or every entry with RFC3339Nano times (-vvv)")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		v.Stdout, v.Stderr = cmd.OutOrStdout(), cmd.ErrOrStderr()
+		return v.Run(flags.bucketDataDir())
+	}
+
+	return cmd
+}
@@ -0,0 +1,31 @@
+package inspect
+
+import (
+	"github.com/influxdata/influxdb/v2/cmd/influx_inspect/verify/tsm"
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyTSMCommand returns a new instance of the verify-tsm command.
+func NewVerifyTSMCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-tsm",
+		Short: "Verifies the integrity of TSM files",
+		Long: `
+This command will verify the checksums of the blocks of the TSM files
+of the shards, or that their series keys are valid UTF-8.`,
+		Args: cobra.NoArgs,
+	}
+
+	var flags engineFlags
+	v := tsm.NewCommand()
+	flags.register(cmd)
+	flags.registerBucketID(cmd, "Only verify the TSM files of the bucket with this ID")
+	cmd.Flags().BoolVar(&v.CheckUTF8, "check-utf8", false, "Verify that series keys are valid UTF-8. This check skips verification of block checksums")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		v.Stdout, v.Stderr = cmd.OutOrStdout(), cmd.ErrOrStderr()
+		return v.Run(flags.bucketDataDir())
+	}
+
+	return cmd
+}
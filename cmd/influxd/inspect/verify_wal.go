@@ -0,0 +1,128 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyWALCommand returns a new instance of the verify-wal command.
+func NewVerifyWALCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-wal",
+		Short: "Checks for corrupt WAL files",
+		Long: `
+This command will analyze the WAL (Write-Ahead Log) segments of the
+shards to check if there are any truncated or corrupt files. If any are
+found, their names are reported, along with the offset up to which they
+are valid. The total number of entries in the scanned WAL files is
+reported as well.
+
+With --repair, the truncated and corrupt segments are truncated after
+their last valid entry, so the engine can start after a crash in the
+middle of a write. The entries after it are lost. The server must not be
+running while the WAL is repaired.`,
+		Args: cobra.NoArgs,
+	}
+
+	var flags engineFlags
+	var repair bool
+	flags.register(cmd)
+	flags.registerBucketID(cmd, "Only verify the WAL files of the bucket with this ID")
+	cmd.Flags().BoolVar(&repair, "repair", false, "Truncate the invalid segments after their last valid entry")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return verifyWAL(cmd.OutOrStdout(), flags.bucketWALDir(), repair)
+	}
+
+	return cmd
+}
+
+func verifyWAL(w io.Writer, dir string, repair bool) error {
+	paths, err := walFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 8, 2, 1, ' ', 0)
+	var totalEntries, invalid int
+	for _, path := range paths {
+		n, err := verifyWALSegment(path)
+		totalEntries += n
+
+		var segErr *walSegmentError
+		if errors.As(err, &segErr) {
+			invalid++
+			state := "corrupt"
+			if segErr.truncated {
+				state = "truncated"
+			}
+			if repair {
+				if err := os.Truncate(path, segErr.valid); err != nil {
+					return err
+				}
+				state = "repaired, " + state
+			}
+			fmt.Fprintf(tw, "%s\t%s after %d valid bytes\t%v\n", path, state, segErr.valid, segErr.err)
+		} else if err != nil {
+			return err
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "Files checked: %d\n", len(paths))
+	fmt.Fprintf(w, "Total entries: %d\n", totalEntries)
+	if invalid > 0 {
+		if repair {
+			fmt.Fprintf(w, "Invalid files repaired: %d\n", invalid)
+			return nil
+		}
+		fmt.Fprintf(w, "Invalid files found: %d\n", invalid)
+		return errors.New("failed WAL verification")
+	}
+	fmt.Fprintln(w, "No invalid files found")
+	return nil
+}
+
+// verifyWALSegment reads all the entries of the WAL segment at path. It returns
+// their number and, if the segment is truncated or corrupt, a *walSegmentError
+// with the number of bytes of the segment which are valid.
+func verifyWALSegment(path string) (entries int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+
+	r := tsm1.NewWALSegmentReader(f)
+	defer r.Close()
+
+	for r.Next() {
+		if _, err := r.Read(); err != nil {
+			return entries, newWALSegmentError(path, r.Count(), err)
+		}
+		entries++
+	}
+	return entries, nil
+}
+
+// walFiles returns the paths of the WAL segments under dir.
+func walFiles(dir string) ([]string, error) {
+	var paths []string
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if !info.IsDir() && filepath.Ext(path) == "."+tsm1.WALFileExtension {
+			paths = append(paths, path)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
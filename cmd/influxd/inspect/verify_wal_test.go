@@ -0,0 +1,209 @@
+package inspect
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+)
+
+// writeWALSegment writes entries to a new WAL segment at path, and returns its
+// size.
+func writeWALSegment(t *testing.T, path string, entries ...tsm1.WALEntry) int64 {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := tsm1.NewWALSegmentWriter(f)
+	for _, entry := range entries {
+		b, err := entry.Encode(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Write(entry.Type(), snappy.Encode(nil, b)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return fi.Size()
+}
+
+// appendFile appends b to the file at path.
+func appendFile(t *testing.T, path string, b []byte) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi.Size()
+}
+
+func TestVerifyWAL(t *testing.T) {
+	entries := []tsm1.WALEntry{
+		&tsm1.WriteWALEntry{Values: map[string][]tsm1.Value{
+			"cpu,host=a#!~#value": {tsm1.NewValue(1, 1.5), tsm1.NewValue(2, 2.5)},
+			"mem,host=a#!~#free":  {tsm1.NewValue(1, int64(3))},
+		}},
+		&tsm1.DeleteWALEntry{Keys: [][]byte{[]byte("mem,host=a#!~#free")}},
+		&tsm1.DeleteRangeWALEntry{Keys: [][]byte{[]byte("cpu,host=a#!~#value")}, Min: 2, Max: 3},
+	}
+	last := &tsm1.WriteWALEntry{Values: map[string][]tsm1.Value{
+		"cpu,host=a#!~#value": {tsm1.NewValue(4, 4.5)},
+	}}
+
+	tests := []struct {
+		name  string
+		state string
+
+		// invalidate appends an invalid entry to the segment at path.
+		invalidate func(t *testing.T, path string)
+	}{
+		{
+			name:  "truncated",
+			state: "truncated",
+			invalidate: func(t *testing.T, path string) {
+				// Append the first half of an entry, as a crash in the
+				// middle of a write would.
+				tmp := filepath.Join(t.TempDir(), "_00001.wal")
+				writeWALSegment(t, tmp, last)
+				b, err := ioutil.ReadFile(tmp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				appendFile(t, path, b[:len(b)/2])
+			},
+		},
+		{
+			name:  "truncated header",
+			state: "truncated",
+			invalidate: func(t *testing.T, path string) {
+				appendFile(t, path, []byte{byte(tsm1.WriteWALEntryType), 0, 0})
+			},
+		},
+		{
+			name:  "corrupt",
+			state: "corrupt",
+			invalidate: func(t *testing.T, path string) {
+				// A write entry of 4 bytes which are not snappy encoded.
+				appendFile(t, path, []byte{byte(tsm1.WriteWALEntryType), 0, 0, 0, 4, 0xff, 0xff, 0xff, 0xff})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			path := filepath.Join(e.shardWALDir("1"), "_00001.wal")
+			valid := writeWALSegment(t, path, entries...)
+			tt.invalidate(t, path)
+			if fileSize(t, path) <= valid {
+				t.Fatal("expected invalid bytes after the valid entries")
+			}
+
+			// The offset of the last valid entry is reported.
+			report := fmt.Sprintf("%s after %d valid bytes", tt.state, valid)
+			out, err := runCommand(t, NewVerifyWALCommand(), "--engine-path", e.path, "--bucket-id", testBucketID)
+			if err == nil || err.Error() != "failed WAL verification" {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, s := range []string{path, report, "Files checked: 1", "Total entries: 3", "Invalid files found: 1"} {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected verify-wal output to contain %q, got:\n%s", s, out)
+				}
+			}
+
+			// The valid entries are dumped, followed by the error.
+			out, err = runCommand(t, NewDumpWALCommand(), path)
+			if err == nil || err.Error() != "1 of 1 WAL files are truncated or corrupt" {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, s := range []string{
+				"[write] sz=", " keys=2 points=3",
+				"cpu,host=a#!~#value points=2", "mem,host=a#!~#free points=1",
+				"[delete] sz=", "[delete-range] min=", " keys=1\ncpu,host=a#!~#value\n",
+				fmt.Sprintf("%s is %s", path, report),
+			} {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected dump-wal output to contain %q, got:\n%s", s, out)
+				}
+			}
+
+			// The segment is truncated after its last valid entry.
+			out, err = runCommand(t, NewVerifyWALCommand(), "--engine-path", e.path, "--bucket-id", testBucketID, "--repair")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, s := range []string{"repaired, " + report, "Invalid files repaired: 1"} {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected verify-wal --repair output to contain %q, got:\n%s", s, out)
+				}
+			}
+			if got := fileSize(t, path); got != valid {
+				t.Fatalf("unexpected size of the repaired segment: got %d, exp %d", got, valid)
+			}
+
+			// The repaired segment reads back cleanly.
+			out, err = runCommand(t, NewVerifyWALCommand(), "--engine-path", e.path, "--bucket-id", testBucketID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, s := range []string{"Total entries: 3", "No invalid files found"} {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected verify-wal output to contain %q, got:\n%s", s, out)
+				}
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := tsm1.NewWALSegmentReader(f)
+			defer r.Close()
+			var n int
+			for ; r.Next(); n++ {
+				entry, err := r.Read()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got, exp := entry.Type(), entries[n].Type(); got != exp {
+					t.Fatalf("entry %d: got type %d, exp %d", n, got, exp)
+				}
+			}
+			if n != len(entries) {
+				t.Fatalf("unexpected number of entries: got %d, exp %d", n, len(entries))
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/signals"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/influxdata/influxdb/v2/v1/coordinator"
+	"github.com/influxdata/influxdb/v2/v1/services/subscriber"
 	"github.com/influxdata/influxdb/v2/vault"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -127,6 +128,9 @@ type InfluxdOpts struct {
 	// Storage options.
 	StorageConfig storage.Config
 
+	// Subscriber options.
+	SubscriberConfig subscriber.Config
+
 	Viper *viper.Viper
 }
 
@@ -141,6 +145,7 @@ func newOpts(viper *viper.Viper) *InfluxdOpts {
 		Viper:             viper,
 		StorageConfig:     storage.NewConfig(),
 		CoordinatorConfig: coordinator.NewConfig(),
+		SubscriberConfig:  subscriber.NewConfig(),
 
 		LogLevel:          zapcore.InfoLevel,
 		ReportingDisabled: false,
@@ -457,6 +462,37 @@ func (o *InfluxdOpts) bindCliOpts(cmd *cobra.Command) {
 			Flag:  "influxql-max-select-buckets",
 			Desc:  "The maximum number of group by time bucket a SELECT can create. A value of zero will max the maximum number of buckets unlimited.",
 		},
+
+		// subscriber configuration
+		{
+			DestP:   &o.SubscriberConfig.Enabled,
+			Flag:    "subscriber-enabled",
+			Default: o.SubscriberConfig.Enabled,
+			Desc:    "Forwards writes to the destinations of subscriptions. Set to false to disable the subscriber service.",
+		},
+		{
+			DestP: &o.SubscriberConfig.HTTPTimeout,
+			Flag:  "subscriber-http-timeout",
+			Desc:  "The timeout of writes to HTTP and HTTPS subscription destinations.",
+		},
+		{
+			DestP:   &o.SubscriberConfig.InsecureSkipVerify,
+			Flag:    "subscriber-insecure-skip-verify",
+			Default: o.SubscriberConfig.InsecureSkipVerify,
+			Desc:    "Skips the verification of the certificates of HTTPS subscription destinations.",
+		},
+		{
+			DestP:   &o.SubscriberConfig.WriteBufferSize,
+			Flag:    "subscriber-write-buffer-size",
+			Default: o.SubscriberConfig.WriteBufferSize,
+			Desc:    "The number of writes buffered for each subscription. Writes to a subscription with a full buffer are dropped.",
+		},
+		{
+			DestP:   &o.SubscriberConfig.WriteConcurrency,
+			Flag:    "subscriber-write-concurrency",
+			Default: o.SubscriberConfig.WriteConcurrency,
+			Desc:    "The number of concurrent writes to the destinations of each subscription.",
+		},
 	}
 
 	cli.BindOptions(o.Viper, cmd, opts)
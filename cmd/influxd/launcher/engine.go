@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/prom"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/v1/coordinator"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -23,14 +24,22 @@ var _ Engine = (*storage.Engine)(nil)
 // to facilitate testing.
 type Engine interface {
 	influxdb.DeleteService
+	influxdb.DeleteJobService
 	storage.PointsWriter
 	storage.EngineSchema
 	prom.PrometheusCollector
 	influxdb.BackupService
 	influxdb.RestoreService
+	influxdb.ShardService
+	influxdb.StorageVerifyService
 
 	SeriesCardinality(orgID, bucketID influxdb.ID) int64
 
+	WritePointsInto(*coordinator.IntoWriteRequest) error
+
+	// Statistics returns statistics for the SHOW STATS statement.
+	Statistics(tags map[string]string) []models.Statistic
+
 	TSDBStore() storage.TSDBStore
 	MetaClient() storage.MetaClient
 
@@ -113,6 +122,16 @@ func (t *TemporaryEngine) WritePoints(ctx context.Context, orgID influxdb.ID, bu
 	return t.engine.WritePoints(ctx, orgID, bucketID, points)
 }
 
+// WritePointsInto stores the points of a SELECT INTO query into the storage engine.
+func (t *TemporaryEngine) WritePointsInto(req *coordinator.IntoWriteRequest) error {
+	return t.engine.WritePointsInto(req)
+}
+
+// Statistics returns the statistics of the storage engine.
+func (t *TemporaryEngine) Statistics(tags map[string]string) []models.Statistic {
+	return t.engine.Statistics(tags)
+}
+
 // SeriesCardinality returns the number of series in the engine.
 func (t *TemporaryEngine) SeriesCardinality(orgID, bucketID influxdb.ID) int64 {
 	return t.engine.SeriesCardinality(orgID, bucketID)
@@ -178,6 +197,61 @@ func (t *TemporaryEngine) RestoreShard(ctx context.Context, shardID uint64, r io
 	return t.engine.RestoreShard(ctx, shardID, r)
 }
 
+// FindShardGroups returns the shard groups of all buckets matching filter.
+func (t *TemporaryEngine) FindShardGroups(ctx context.Context, filter influxdb.ShardGroupFilter) ([]*influxdb.ShardGroup, error) {
+	return t.engine.FindShardGroups(ctx, filter)
+}
+
+// DeleteShard removes a shard and its data.
+func (t *TemporaryEngine) DeleteShard(ctx context.Context, shardID uint64) error {
+	return t.engine.DeleteShard(ctx, shardID)
+}
+
+// RebuildShardIndex rebuilds the index of a shard.
+func (t *TemporaryEngine) RebuildShardIndex(ctx context.Context, shardID uint64) error {
+	return t.engine.RebuildShardIndex(ctx, shardID)
+}
+
+// VerifyStorage starts the verification of the stored data of a bucket.
+func (t *TemporaryEngine) VerifyStorage(ctx context.Context, req influxdb.StorageVerificationRequest) (*influxdb.StorageVerification, error) {
+	return t.engine.VerifyStorage(ctx, req)
+}
+
+// FindStorageVerifications returns the recent storage verifications matching filter.
+func (t *TemporaryEngine) FindStorageVerifications(ctx context.Context, filter influxdb.StorageVerificationFilter) ([]*influxdb.StorageVerification, error) {
+	return t.engine.FindStorageVerifications(ctx, filter)
+}
+
+// FindStorageVerificationByID returns a single storage verification by ID.
+func (t *TemporaryEngine) FindStorageVerificationByID(ctx context.Context, id influxdb.ID) (*influxdb.StorageVerification, error) {
+	return t.engine.FindStorageVerificationByID(ctx, id)
+}
+
+// CancelStorageVerification cancels a running storage verification.
+func (t *TemporaryEngine) CancelStorageVerification(ctx context.Context, id influxdb.ID) error {
+	return t.engine.CancelStorageVerification(ctx, id)
+}
+
+// StartDeleteJob starts deleting the points of a bucket in the background.
+func (t *TemporaryEngine) StartDeleteJob(ctx context.Context, req influxdb.DeleteJobRequest) (*influxdb.DeleteJob, error) {
+	return t.engine.StartDeleteJob(ctx, req)
+}
+
+// FindDeleteJobs returns the recent delete jobs matching filter.
+func (t *TemporaryEngine) FindDeleteJobs(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+	return t.engine.FindDeleteJobs(ctx, filter)
+}
+
+// FindDeleteJobByID returns a single delete job by ID.
+func (t *TemporaryEngine) FindDeleteJobByID(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+	return t.engine.FindDeleteJobByID(ctx, id)
+}
+
+// CancelDeleteJob cancels a running delete job.
+func (t *TemporaryEngine) CancelDeleteJob(ctx context.Context, id influxdb.ID) error {
+	return t.engine.CancelDeleteJob(ctx, id)
+}
+
 func (t *TemporaryEngine) TSDBStore() storage.TSDBStore {
 	return &t.tsdbStore
 }
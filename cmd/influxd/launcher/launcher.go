@@ -49,7 +49,9 @@ import (
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/query/control"
 	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"github.com/influxdata/influxdb/v2/query/registry"
 	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/v2/restore"
 	"github.com/influxdata/influxdb/v2/secret"
 	"github.com/influxdata/influxdb/v2/session"
 	"github.com/influxdata/influxdb/v2/snowflake"
@@ -57,6 +59,7 @@ import (
 	"github.com/influxdata/influxdb/v2/storage"
 	storageflux "github.com/influxdata/influxdb/v2/storage/flux"
 	"github.com/influxdata/influxdb/v2/storage/readservice"
+	"github.com/influxdata/influxdb/v2/subscription"
 	taskbackend "github.com/influxdata/influxdb/v2/task/backend"
 	"github.com/influxdata/influxdb/v2/task/backend/coordinator"
 	"github.com/influxdata/influxdb/v2/task/backend/executor"
@@ -69,8 +72,11 @@ import (
 	_ "github.com/influxdata/influxdb/v2/tsdb/index/tsi1"  // needed for tsi1
 	authv1 "github.com/influxdata/influxdb/v2/v1/authorization"
 	iqlcoordinator "github.com/influxdata/influxdb/v2/v1/coordinator"
+	"github.com/influxdata/influxdb/v2/v1/monitor"
+	"github.com/influxdata/influxdb/v2/v1/services/continuous_querier"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	storage2 "github.com/influxdata/influxdb/v2/v1/services/storage"
+	"github.com/influxdata/influxdb/v2/v1/services/subscriber"
 	"github.com/influxdata/influxdb/v2/vault"
 	pzap "github.com/influxdata/influxdb/v2/zap"
 	"github.com/opentracing/opentracing-go"
@@ -105,6 +111,12 @@ type Launcher struct {
 	// storage engine
 	engine Engine
 
+	// monitor of the InfluxQL SHOW STATS and SHOW DIAGNOSTICS statements
+	monitor *monitor.Monitor
+
+	// subscriber forwards writes to the destinations of subscriptions
+	subscriber *subscriber.Service
+
 	// InfluxQL query engine
 	queryController *control.Controller
 
@@ -186,6 +198,20 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 		m.log.Info("Failed closing query service", zap.Error(err))
 	}
 
+	if m.monitor != nil {
+		m.log.Info("Stopping", zap.String("service", "monitor"))
+		if err := m.monitor.Close(); err != nil {
+			m.log.Info("Failed closing monitor", zap.Error(err))
+		}
+	}
+
+	if m.subscriber != nil {
+		m.log.Info("Stopping", zap.String("service", "subscriber"))
+		if err := m.subscriber.Close(); err != nil {
+			m.log.Info("Failed closing subscriber", zap.Error(err))
+		}
+	}
+
 	m.log.Info("Stopping", zap.String("service", "storage-engine"))
 	if err := m.engine.Close(); err != nil {
 		m.log.Error("Failed to close engine", zap.Error(err))
@@ -375,12 +401,23 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		return err
 	}
 
+	// The subscriber receives every write to the storage engine and forwards
+	// it to the destinations of the subscriptions of its bucket.
+	if err := opts.SubscriberConfig.Validate(); err != nil {
+		m.log.Error("Invalid subscriber configuration", zap.Error(err))
+		return err
+	}
+	m.subscriber = subscriber.NewService(subscription.NewService(m.kvStore), opts.SubscriberConfig)
+	m.subscriber.WithLogger(m.log)
+
+	engineOpts := []storage.Option{storage.WithMetaClient(metaClient)}
+	if opts.SubscriberConfig.Enabled {
+		engineOpts = append(engineOpts, storage.WithWriteSubscriber(m.subscriber.Points()))
+	}
+
 	if opts.Testing {
 		// the testing engine will write/read into a temporary directory
-		engine := NewTemporaryEngine(
-			opts.StorageConfig,
-			storage.WithMetaClient(metaClient),
-		)
+		engine := NewTemporaryEngine(opts.StorageConfig, engineOpts...)
 		flushers = append(flushers, engine)
 		m.engine = engine
 	} else {
@@ -389,11 +426,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			os.Exit(1)
 		}
 
-		m.engine = storage.NewEngine(
-			opts.EnginePath,
-			opts.StorageConfig,
-			storage.WithMetaClient(metaClient),
-		)
+		m.engine = storage.NewEngine(opts.EnginePath, opts.StorageConfig, engineOpts...)
 	}
 	m.engine.WithLogger(m.log)
 	if err := m.engine.Open(ctx); err != nil {
@@ -403,13 +436,38 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 	// The Engine's metrics must be registered after it opens.
 	m.reg.MustRegister(m.engine.PrometheusCollectors()...)
 
+	// The monitor only serves statistics and diagnostics on demand;
+	// it does not store them in a bucket.
+	m.monitor = monitor.New(m.engine, monitor.Config{})
+	m.monitor.Version = info.Version
+	m.monitor.Commit = info.Commit
+	m.monitor.BuildTime = info.Date
+	m.monitor.WithLogger(m.log)
+	if err := m.monitor.Open(); err != nil {
+		m.log.Error("Failed to open monitor", zap.Error(err))
+		return err
+	}
+
+	if err := m.subscriber.Open(ctx); err != nil {
+		m.log.Error("Failed to open subscriber", zap.Error(err))
+		return err
+	}
+	m.reg.MustRegister(m.subscriber.PrometheusCollectors()...)
+
 	var (
-		deleteService  platform.DeleteService  = m.engine
-		pointsWriter   storage.PointsWriter    = m.engine
-		backupService  platform.BackupService  = m.engine
-		restoreService platform.RestoreService = m.engine
+		deleteService    platform.DeleteService        = m.engine
+		deleteJobService platform.DeleteJobService     = m.engine
+		pointsWriter     storage.PointsWriter          = m.engine
+		backupService    platform.BackupService        = m.engine
+		restoreService   platform.RestoreService       = m.engine
+		shardService     platform.ShardService         = m.engine
+		verifyService    platform.StorageVerifyService = m.engine
 	)
 
+	// runningQueries tracks the Flux and InfluxQL queries running on this
+	// server so they can be listed and killed.
+	runningQueries := registry.New()
+
 	deps, err := influxdb.NewDependencies(
 		storageflux.NewReader(storage2.NewStore(m.engine.TSDBStore(), m.engine.MetaClient())),
 		m.engine,
@@ -431,6 +489,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		QueueSize:                       opts.QueueSize,
 		Logger:                          m.log.With(zap.String("service", "storage-reads")),
 		ExecutorDependencies:            []flux.Dependency{deps},
+		Registry:                        runningQueries,
 	})
 	if err != nil {
 		m.log.Error("Failed to create query controller", zap.Error(err))
@@ -439,6 +498,72 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 
 	m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
 
+	dbrpSvc := dbrp.NewAuthorizedService(dbrp.NewService(ctx, authorizer.NewBucketService(ts.BucketService), m.kvStore))
+
+	ts.BucketService = storage.NewBucketService(m.log, ts.BucketService, m.engine)
+	ts.BucketService = dbrp.NewBucketService(m.log, ts.BucketService, dbrpSvc)
+	ts.BucketService = subscription.NewBucketService(m.log, ts.BucketService, m.subscriber)
+
+	var (
+		authorizerV1 platform.AuthorizerV1
+		passwordV1   platform.PasswordsService
+		authSvcV1    *authv1.Service
+	)
+	{
+		authStore, err := authv1.NewStore(m.kvStore)
+		if err != nil {
+			m.log.Error("Failed creating new authorization store", zap.Error(err))
+			return err
+		}
+
+		authSvcV1 = authv1.NewService(authStore, ts)
+		passwordV1 = authv1.NewCachingPasswordsService(authSvcV1)
+
+		authorizerV1 = &authv1.Authorizer{
+			AuthV1:   authSvcV1,
+			AuthV2:   authSvc,
+			Comparer: passwordV1,
+			User:     ts,
+		}
+	}
+
+	cm := iqlcontrol.NewControllerMetrics([]string{})
+	m.reg.MustRegister(cm.PrometheusCollectors()...)
+
+	mapper := &iqlcoordinator.LocalShardMapper{
+		MetaClient: metaClient,
+		TSDBStore:  m.engine.TSDBStore(),
+		DBRP:       dbrpSvc,
+	}
+
+	m.log.Info("Configuring InfluxQL statement executor (zeros indicate unlimited).",
+		zap.Int("max_select_point", opts.CoordinatorConfig.MaxSelectPointN),
+		zap.Int("max_select_series", opts.CoordinatorConfig.MaxSelectSeriesN),
+		zap.Int("max_select_buckets", opts.CoordinatorConfig.MaxSelectBucketsN))
+
+	qe := iqlquery.NewExecutor(m.log, cm)
+	se := &iqlcoordinator.StatementExecutor{
+		MetaClient:           metaClient,
+		TSDBStore:            m.engine.TSDBStore(),
+		ShardMapper:          mapper,
+		DBRP:                 dbrpSvc,
+		BucketService:        authorizer.NewBucketService(ts.BucketService),
+		PointsWriter:         m.engine,
+		ShardService:         shardService,
+		RunningQueryService:  authorizer.NewRunningQueryService(runningQueries),
+		Monitor:              m.monitor,
+		SubscriptionService:  authorizer.NewSubscriptionService(m.subscriber),
+		AuthorizationService: authorization.NewAuthedAuthorizationService(authSvcV1, ts),
+		PasswordService:      authv1.NewAuthedPasswordService(authv1.AuthFinder(authSvcV1), passwordV1),
+		PermissionService:    authv1.NewAuthedPermissionService(authv1.AuthFinder(authSvcV1), authSvcV1),
+		MaxSelectPointN:      opts.CoordinatorConfig.MaxSelectPointN,
+		MaxSelectSeriesN:     opts.CoordinatorConfig.MaxSelectSeriesN,
+		MaxSelectBucketsN:    opts.CoordinatorConfig.MaxSelectBucketsN,
+	}
+	qe.StatementExecutor = se
+	qe.StatementNormalizer = se
+	qe.Registry = runningQueries
+
 	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
 	var taskSvc platform.TaskService
 	{
@@ -459,6 +584,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			combinedTaskService,
 			combinedTaskService,
 			executor.WithFlagger(m.flagger),
+			executor.WithTaskRunner(continuous_querier.TaskType, continuous_querier.NewRunner(qe)),
 		)
 		m.executor = executor
 		m.reg.MustRegister(executorMetrics.PrometheusCollectors()...)
@@ -510,35 +636,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			m.log.Error("Failed to resume existing tasks", zap.Error(err))
 		}
 	}
-
-	dbrpSvc := dbrp.NewAuthorizedService(dbrp.NewService(ctx, authorizer.NewBucketService(ts.BucketService), m.kvStore))
-
-	cm := iqlcontrol.NewControllerMetrics([]string{})
-	m.reg.MustRegister(cm.PrometheusCollectors()...)
-
-	mapper := &iqlcoordinator.LocalShardMapper{
-		MetaClient: metaClient,
-		TSDBStore:  m.engine.TSDBStore(),
-		DBRP:       dbrpSvc,
-	}
-
-	m.log.Info("Configuring InfluxQL statement executor (zeros indicate unlimited).",
-		zap.Int("max_select_point", opts.CoordinatorConfig.MaxSelectPointN),
-		zap.Int("max_select_series", opts.CoordinatorConfig.MaxSelectSeriesN),
-		zap.Int("max_select_buckets", opts.CoordinatorConfig.MaxSelectBucketsN))
-
-	qe := iqlquery.NewExecutor(m.log, cm)
-	se := &iqlcoordinator.StatementExecutor{
-		MetaClient:        metaClient,
-		TSDBStore:         m.engine.TSDBStore(),
-		ShardMapper:       mapper,
-		DBRP:              dbrpSvc,
-		MaxSelectPointN:   opts.CoordinatorConfig.MaxSelectPointN,
-		MaxSelectSeriesN:  opts.CoordinatorConfig.MaxSelectSeriesN,
-		MaxSelectBucketsN: opts.CoordinatorConfig.MaxSelectBucketsN,
-	}
-	qe.StatementExecutor = se
-	qe.StatementNormalizer = se
+	se.TaskService = authorizer.NewTaskService(m.log, taskSvc)
 
 	var checkSvc platform.CheckService
 	{
@@ -650,9 +748,6 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		labelSvc = label.NewService(labelsStore)
 	}
 
-	ts.BucketService = storage.NewBucketService(m.log, ts.BucketService, m.engine)
-	ts.BucketService = dbrp.NewBucketService(m.log, ts.BucketService, dbrpSvc)
-
 	var onboardOpts []tenant.OnboardServiceOptionFn
 	if opts.TestingAlwaysAllowSetup {
 		onboardOpts = append(onboardOpts, tenant.WithAlwaysAllowInitialUser())
@@ -663,29 +758,6 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 	onboardSvc = tenant.NewOnboardingMetrics(m.reg, onboardSvc, metric.WithSuffix("new"))             // with metrics
 	onboardSvc = tenant.NewOnboardingLogger(m.log.With(zap.String("handler", "onboard")), onboardSvc) // with logging
 
-	var (
-		authorizerV1 platform.AuthorizerV1
-		passwordV1   platform.PasswordsService
-		authSvcV1    *authv1.Service
-	)
-	{
-		authStore, err := authv1.NewStore(m.kvStore)
-		if err != nil {
-			m.log.Error("Failed creating new authorization store", zap.Error(err))
-			return err
-		}
-
-		authSvcV1 = authv1.NewService(authStore, ts)
-		passwordV1 = authv1.NewCachingPasswordsService(authSvcV1)
-
-		authorizerV1 = &authv1.Authorizer{
-			AuthV1:   authSvcV1,
-			AuthV2:   authSvc,
-			Comparer: passwordV1,
-			User:     ts,
-		}
-	}
-
 	var (
 		dashboardSvc    platform.DashboardService
 		dashboardLogSvc platform.DashboardOperationLogService
@@ -715,6 +787,23 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		NotificationRuleFinder:     notificationRuleSvc,
 	}
 
+	// Resources restored from backups of the metadata database are created with
+	// the permissions of the user restoring them.
+	resourceRestoreSvc := restore.NewService(
+		m.log.With(zap.String("service", "restore")),
+		restore.Services{
+			Organizations:         authorizer.NewOrgService(ts.OrganizationService),
+			Dashboards:            authorizer.NewDashboardService(dashboardSvc),
+			Tasks:                 authorizer.NewTaskService(m.log, taskSvc),
+			Checks:                authorizer.NewCheckService(checkSvc, ts.UserResourceMappingService, ts.OrganizationService),
+			NotificationRules:     authorizer.NewNotificationRuleStore(notificationRuleSvc, ts.UserResourceMappingService, ts.OrganizationService),
+			NotificationEndpoints: authorizer.NewNotificationEndpointService(notificationEndpointSvc, ts.UserResourceMappingService, ts.OrganizationService),
+			Variables:             authorizer.NewVariableService(variableSvc),
+			Telegrafs:             authorizer.NewTelegrafConfigService(telegrafSvc, ts.UserResourceMappingService),
+		},
+		backupServices(m.log.With(zap.String("service", "restore"))),
+	)
+
 	m.apibackend = &http.APIBackend{
 		AssetsPath:           opts.AssetsPath,
 		HTTPErrorHandler:     kithttp.ErrorHandler(0),
@@ -727,12 +816,18 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			BucketFinder:  ts.BucketService,
 			LogBucketName: platform.MonitoringSystemBucketName,
 		},
-		DeleteService:        deleteService,
-		BackupService:        backupService,
-		RestoreService:       restoreService,
-		AuthorizationService: authSvc,
-		AuthorizerV1:         authorizerV1,
-		AlgoWProxy:           &http.NoopProxyHandler{},
+		DeleteService:          deleteService,
+		DeleteJobService:       deleteJobService,
+		BackupService:          backupService,
+		RestoreService:         restoreService,
+		ResourceRestoreService: resourceRestoreSvc,
+		ShardService:           shardService,
+		RunningQueryService:    runningQueries,
+		StorageVerifyService:   verifyService,
+		SubscriptionService:    m.subscriber,
+		AuthorizationService:   authSvc,
+		AuthorizerV1:           authorizerV1,
+		AlgoWProxy:             &http.NoopProxyHandler{},
 		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
 		BucketService:                   ts.BucketService,
 		SessionService:                  sessionSvc,
@@ -1131,3 +1226,28 @@ func (m *Launcher) DBRPMappingServiceV2() platform.DBRPMappingServiceV2 {
 func (m *Launcher) SessionService() platform.SessionService {
 	return m.apibackend.SessionService
 }
+
+// backupServices returns the services reading the resources of a backup of the
+// metadata database.
+func backupServices(log *zap.Logger) restore.SourceFunc {
+	return func(store kv.Store) (restore.Services, error) {
+		ts := tenant.NewService(tenant.NewStore(store))
+		kvSvc := kv.NewService(log, store, ts)
+		endpoints := endpointservice.NewStore(store)
+		rules, err := ruleservice.New(log, store, kvSvc, ts, endpoints)
+		if err != nil {
+			return restore.Services{}, err
+		}
+
+		return restore.Services{
+			Organizations:         ts,
+			Dashboards:            dashboards.NewService(store, kvSvc),
+			Tasks:                 kvSvc,
+			Checks:                checks.NewService(log, store, ts, kvSvc),
+			NotificationRules:     rules,
+			NotificationEndpoints: endpoints,
+			Variables:             kvSvc,
+			Telegrafs:             telegrafservice.New(store),
+		}, nil
+	}
+}
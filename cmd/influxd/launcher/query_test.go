@@ -15,6 +15,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/csv"
 	"github.com/influxdata/flux/execute"
@@ -2561,6 +2562,147 @@ from(bucket: v.bucket)
 	}
 }
 
+// TestQueryPushDowns_SeriesCardinality checks that counting the series with
+// the storage engine gives the same results as counting them with Flux.
+func TestQueryPushDowns_SeriesCardinality(t *testing.T) {
+	testcases := []struct {
+		name    string
+		data    []string
+		query   string
+		wantErr string
+	}{
+		{
+			name: "first",
+			data: []string{
+				"m0,k=k0 f=0i 0",
+				"m0,k=k0 f=1i 1000000000",
+				"m0,k=k1 f=2i 2000000000",
+				"m0,k=k1 g=3i 3000000000",
+				"m1,k=k0 f=4i 4000000000",
+			},
+			query: `
+from(bucket: v.bucket)
+	|> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T01:00:00Z)
+	|> first()
+	|> group()
+	|> count()
+`,
+		},
+		{
+			name: "last with predicate",
+			data: []string{
+				"m0,k=k0 f=0i 0",
+				"m0,k=k1 f=1i 1000000000",
+				"m0,k=k1 g=2i 2000000000",
+				"m1,k=k0 f=3i 3000000000",
+			},
+			query: `
+from(bucket: v.bucket)
+	|> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T01:00:00Z)
+	|> filter(fn: (r) => r._measurement == "m0")
+	|> last()
+	|> group()
+	|> count()
+`,
+		},
+		{
+			name: "range without data",
+			data: []string{
+				"m0,k=k0 f=0i 0",
+				"m0,k=k1 f=1i 1000000000",
+			},
+			query: `
+from(bucket: v.bucket)
+	|> range(start: 1970-01-01T00:00:10Z, stop: 1970-01-01T01:00:00Z)
+	|> first()
+	|> group()
+	|> count()
+`,
+		},
+		{
+			name: "mixed value types",
+			data: []string{
+				"m0,k=k0 f=0 0",
+				"m1,k=k0 f=1i 1000000000",
+			},
+			query: `
+from(bucket: v.bucket)
+	|> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T01:00:00Z)
+	|> first()
+	|> group()
+	|> count()
+`,
+			wantErr: "schema collision detected",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			l := launcher.RunTestLauncherOrFail(t, ctx, mock.NewFlagger(map[feature.Flag]interface{}{}))
+
+			l.SetupOrFail(t)
+			defer l.ShutdownOrFail(t, ctx)
+
+			l.WritePointsOrFail(t, strings.Join(tc.data, "\n"))
+
+			queryStr := "v = {bucket: " + "\"" + l.Bucket.Name + "\"" + "}\n" + tc.query
+
+			// The query counts the series with Flux when the rule is disabled.
+			want, wantErr := executeQueryTables(l, "import \"planner\"\noption planner.disablePhysicalRules = [\"PushDownReadSeriesCardinalityRule\"]\n"+queryStr)
+			got, gotErr := executeQueryTables(l, queryStr)
+			if tc.wantErr != "" {
+				if wantErr == nil || !strings.Contains(wantErr.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q without the rule, got %v", tc.wantErr, wantErr)
+				}
+				if gotErr == nil || !strings.Contains(gotErr.Error(), tc.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.wantErr, gotErr)
+				}
+			} else {
+				if wantErr != nil {
+					t.Fatalf("unexpected error without the rule: %v", wantErr)
+				}
+				if gotErr != nil {
+					t.Fatalf("unexpected error: %v", gotErr)
+				}
+				if !cmp.Equal(want, got) {
+					t.Fatalf("unexpected tables -want/+got:\n%s", cmp.Diff(want, got))
+				}
+			}
+			if want, got := uint64(1), l.NumReads(t, "readSeriesCardinality"); want != got {
+				t.Fatalf("unexpected sample count -want/+got:\n\t- %d\n\t+ %d", want, got)
+			}
+		})
+	}
+}
+
+// executeQueryTables executes a query and returns the tables of its results.
+func executeQueryTables(l *launcher.TestLauncher, q string) ([]*executetest.Table, error) {
+	res, err := l.ExecuteQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Done()
+
+	var tables []*executetest.Table
+	for _, r := range res.Results {
+		if err := r.Tables().Do(func(tbl flux.Table) error {
+			t, err := executetest.ConvertTable(tbl)
+			if err != nil {
+				return err
+			}
+			tables = append(tables, t)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := res.Query.Err(); err != nil {
+		return nil, err
+	}
+	executetest.NormalizeTables(tables)
+	return tables, nil
+}
+
 func TestLauncher_Query_Buckets_MultiplePages(t *testing.T) {
 	l := launcher.RunTestLauncherOrFail(t, ctx, nil)
 	l.SetupOrFail(t)
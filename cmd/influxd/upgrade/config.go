@@ -39,6 +39,11 @@ var configMapRules = map[string]string{
 	"coordinator.max-select-point":                         "influxql-max-select-point",
 	"coordinator.max-select-series":                        "influxql-max-select-series",
 	"coordinator.max-select-buckets":                       "influxql-max-select-buckets",
+	"subscriber.enabled":                                   "subscriber-enabled",
+	"subscriber.http-timeout":                              "subscriber-http-timeout",
+	"subscriber.insecure-skip-verify":                      "subscriber-insecure-skip-verify",
+	"subscriber.write-buffer-size":                         "subscriber-write-buffer-size",
+	"subscriber.write-concurrency":                         "subscriber-write-concurrency",
 	"logging.level":                                        "log-level",
 	"http.bind-address":                                    "http-bind-address",
 	"http.https-certificate":                               "tls-cert",
@@ -438,6 +438,11 @@ storage-shard-precreator-check-interval = "10m0s"
 storage-tsm-use-madv-willneed = false
 storage-validate-keys = false
 storage-wal-fsync-delay = "0s"
+subscriber-enabled = true
+subscriber-http-timeout = "30s"
+subscriber-insecure-skip-verify = false
+subscriber-write-buffer-size = 1000
+subscriber-write-concurrency = 40
 tls-cert = "/etc/ssl/influxdb.pem"
 tls-key = ""
 `
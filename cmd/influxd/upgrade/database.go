@@ -10,7 +10,9 @@ import (
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/pkg/fs"
+	"github.com/influxdata/influxdb/v2/v1/services/continuous_querier"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
+	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
 )
 
@@ -213,6 +215,11 @@ func upgradeDatabases(ctx context.Context, v1 *influxDBv1, v2 *influxDBv2, v1opt
 			if err != nil {
 				return nil, fmt.Errorf("error exporting continuous query %s from DB %s: %w", cq.Name, db.Name, err)
 			}
+
+			if err := upgradeContinuousQuery(ctx, v2, v2opts, orgID, cq); err != nil {
+				log.Warn("Failed to create task for continuous query, it must be recreated manually",
+					zap.String("db", db.Name), zap.String("cq_name", cq.Name), zap.Error(err))
+			}
 		}
 		_, err = cqFile.WriteString("\n")
 		if err != nil {
@@ -222,3 +229,22 @@ func upgradeDatabases(ctx context.Context, v1 *influxDBv1, v2 *influxDBv2, v1opt
 
 	return db2BucketIds, nil
 }
+
+// upgradeContinuousQuery creates a task owned by the upgrade user which runs the continuous query.
+func upgradeContinuousQuery(ctx context.Context, v2 *influxDBv2, v2opts *optionsV2, orgID influxdb.ID, cq meta.ContinuousQueryInfo) error {
+	stmt, err := influxql.ParseStatement(cq.Query)
+	if err != nil {
+		return err
+	}
+	cqStmt, ok := stmt.(*influxql.CreateContinuousQueryStatement)
+	if !ok {
+		return fmt.Errorf("unexpected statement %q", cq.Query)
+	}
+
+	tc, err := continuous_querier.NewTaskCreate(orgID, v2opts.userID, cqStmt)
+	if err != nil {
+		return err
+	}
+	_, err = v2.taskSvc.CreateTask(ctx, tc)
+	return err
+}
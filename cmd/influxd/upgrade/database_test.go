@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/influxdb/v2/bolt"
 	"github.com/influxdata/influxdb/v2/cmd/influxd/launcher"
 	"github.com/influxdata/influxdb/v2/internal/testutil"
+	"github.com/influxdata/influxdb/v2/v1/services/continuous_querier"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -82,9 +83,22 @@ func TestUpgradeRealDB(t *testing.T) {
 	log, err := zap.NewDevelopment()
 	require.Nil(t, err)
 
+	v2opts.userID = resp.User.ID
 	db2bids, err := upgradeDatabases(ctx, v1, v2, v1opts, v2opts, resp.Org.ID, log)
 	require.Nil(t, err)
 
+	cqType := continuous_querier.TaskType
+	tasks, _, err := v2.taskSvc.FindTasks(ctx, influxdb.TaskFilter{Type: &cqType, OrganizationID: &resp.Org.ID})
+	require.Nil(t, err)
+	cqTasks := make(map[string]string)
+	for _, task := range tasks {
+		assert.Equal(t, resp.User.ID, task.OwnerID)
+		cqTasks[task.Name] = continuous_querier.Database(task)
+	}
+	assert.Equal(t, "test", cqTasks["other_cq"])
+	assert.Equal(t, "test", cqTasks["cq_3"])
+	assert.Equal(t, "empty", cqTasks["cq"])
+
 	err = v2.close()
 	require.Nil(t, err)
 
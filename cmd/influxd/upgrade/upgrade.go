@@ -23,6 +23,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/kv/migration"
 	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/influxdata/influxdb/v2/tenant"
 	authv1 "github.com/influxdata/influxdb/v2/v1/authorization"
@@ -135,7 +136,7 @@ func NewCommand(v *viper.Viper) *cobra.Command {
       1. Reads the 1.x config file and creates a 2.x config file with matching options. Unsupported 1.x options are reported.
       2. Copies 1.x database files.
       3. Creates influx CLI configurations.
-      4. Exports any 1.x continuous queries to disk.
+      4. Exports any 1.x continuous queries to disk, and creates a task running each of them.
 
     If --config-file is not passed, 1.x db folder (--v1-dir options) is taken as an input. If neither option is given,
     the CLI will search for config under ${HOME}/.influxdb/ and /etc/influxdb/. If config can't be found, the CLI assumes
@@ -288,6 +289,7 @@ type influxDBv2 struct {
 	onboardSvc  influxdb.OnboardingService
 	authSvc     *authv1.Service
 	authSvcV2   influxdb.AuthorizationService
+	taskSvc     influxdb.TaskService
 	meta        *meta.Client
 }
 
@@ -600,6 +602,11 @@ func newInfluxDBv2(ctx context.Context, opts *optionsV2, log *zap.Logger) (svc *
 
 	svc.authSvc = authv1.NewService(authStoreV1, svc.ts)
 
+	// task service, used to migrate continuous queries
+	svc.taskSvc = kv.NewService(log.With(zap.String("store", "kv")), svc.kvStore, svc.ts, kv.ServiceConfig{
+		FluxLanguageService: fluxlang.DefaultService,
+	})
+
 	return svc, nil
 }
 
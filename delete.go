@@ -1,6 +1,9 @@
 package influxdb
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Predicate is something that can match on a series key.
 type Predicate interface {
@@ -13,3 +16,85 @@ type Predicate interface {
 type DeleteService interface {
 	DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID ID, min, max int64, pred Predicate) error
 }
+
+// Statuses of a delete job.
+const (
+	// DeleteJobRunning is the status of a delete job in progress.
+	DeleteJobRunning = "running"
+	// DeleteJobSuccess is the status of a delete job which deleted the data of
+	// all the shards of its bucket.
+	DeleteJobSuccess = "success"
+	// DeleteJobFailed is the status of a delete job which failed to delete the
+	// data of some shards, reported in its errors.
+	DeleteJobFailed = "failed"
+	// DeleteJobCanceled is the status of a canceled delete job. The data
+	// deleted before it was canceled stays deleted.
+	DeleteJobCanceled = "canceled"
+)
+
+// DeleteJob is a delete of the points of a bucket running in the background.
+type DeleteJob struct {
+	ID         ID                `json:"id"`
+	OrgID      ID                `json:"orgID"`
+	BucketID   ID                `json:"bucketID"`
+	Start      time.Time         `json:"start"`
+	Stop       time.Time         `json:"stop"`
+	Predicate  string            `json:"predicate,omitempty"`
+	Status     string            `json:"status"`
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt *time.Time        `json:"finishedAt,omitempty"`
+	Progress   DeleteJobProgress `json:"progress"`
+	Errors     []DeleteJobError  `json:"errors"`
+}
+
+// DeleteJobProgress is the progress of a delete job.
+type DeleteJobProgress struct {
+	ShardsTotal   int   `json:"shardsTotal"`
+	ShardsDeleted int   `json:"shardsDeleted"`
+	SeriesDeleted int64 `json:"seriesDeleted"`
+}
+
+// DeleteJobError is the error of a delete job on one of the shards of its bucket.
+type DeleteJobError struct {
+	ShardID uint64 `json:"shardID"`
+	Message string `json:"message"`
+}
+
+// DeleteJobRequest is a request to delete the points of a bucket between
+// Start and Stop (inclusive, in nanoseconds) which match Predicate.
+type DeleteJobRequest struct {
+	OrgID     ID
+	BucketID  ID
+	Start     int64
+	Stop      int64
+	Predicate Predicate
+
+	// Statement is the predicate statement, reported by the job.
+	Statement string
+
+	// SeriesPerSecond limits the rate at which series are deleted, to limit
+	// the impact of the delete on the server. The default rate is used if 0.
+	SeriesPerSecond int
+}
+
+// DeleteJobFilter represents a set of filters that restrict the returned delete jobs.
+type DeleteJobFilter struct {
+	OrgID    *ID
+	BucketID *ID
+}
+
+// DeleteJobService runs deletes in the background.
+type DeleteJobService interface {
+	// StartDeleteJob starts deleting the points of a bucket in the background.
+	StartDeleteJob(ctx context.Context, req DeleteJobRequest) (*DeleteJob, error)
+
+	// FindDeleteJobs returns the recent delete jobs matching filter, in the
+	// order they were started.
+	FindDeleteJobs(ctx context.Context, filter DeleteJobFilter) ([]*DeleteJob, error)
+
+	// FindDeleteJobByID returns a single delete job by ID.
+	FindDeleteJobByID(ctx context.Context, id ID) (*DeleteJob, error)
+
+	// CancelDeleteJob cancels a running delete job.
+	CancelDeleteJob(ctx context.Context, id ID) error
+}
@@ -59,8 +59,14 @@ type APIBackend struct {
 
 	PointsWriter                    storage.PointsWriter
 	DeleteService                   influxdb.DeleteService
+	DeleteJobService                influxdb.DeleteJobService
 	BackupService                   influxdb.BackupService
 	RestoreService                  influxdb.RestoreService
+	ResourceRestoreService          influxdb.ResourceRestoreService
+	ShardService                    influxdb.ShardService
+	RunningQueryService             influxdb.RunningQueryService
+	StorageVerifyService            influxdb.StorageVerifyService
+	SubscriptionService             influxdb.SubscriptionService
 	AuthorizationService            influxdb.AuthorizationService
 	AuthorizerV1                    influxdb.AuthorizerV1
 	OnboardingService               influxdb.OnboardingService
@@ -142,6 +148,7 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	h.Mount(prefixChronograf, NewChronografHandler(b.ChronografService, b.HTTPErrorHandler))
 
 	deleteBackend := NewDeleteBackend(b.Logger.With(zap.String("handler", "delete")), b)
+	deleteBackend.DeleteJobService = authorizer.NewDeleteJobService(deleteBackend.DeleteJobService)
 	h.Mount(prefixDelete, NewDeleteHandler(b.Logger, deleteBackend))
 
 	documentBackend := NewDocumentBackend(b.Logger.With(zap.String("handler", "document")), b)
@@ -199,6 +206,22 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	restoreBackend.RestoreService = authorizer.NewRestoreService(restoreBackend.RestoreService)
 	h.Mount(prefixRestore, NewRestoreHandler(restoreBackend))
 
+	shardBackend := NewShardBackend(b)
+	shardBackend.ShardService = authorizer.NewShardService(shardBackend.ShardService, b.BucketService)
+	h.Mount(prefixShards, NewShardHandler(shardBackend))
+
+	runningQueryBackend := NewRunningQueryBackend(b)
+	runningQueryBackend.RunningQueryService = authorizer.NewRunningQueryService(runningQueryBackend.RunningQueryService)
+	h.Mount(prefixRunningQueries, NewRunningQueryHandler(runningQueryBackend))
+
+	storageVerifyBackend := NewStorageVerifyBackend(b)
+	storageVerifyBackend.StorageVerifyService = authorizer.NewStorageVerifyService(storageVerifyBackend.StorageVerifyService, b.BucketService)
+	h.Mount(prefixStorageVerify, NewStorageVerifyHandler(storageVerifyBackend))
+
+	subscriptionBackend := NewSubscriptionBackend(b)
+	subscriptionBackend.SubscriptionService = authorizer.NewSubscriptionService(subscriptionBackend.SubscriptionService)
+	h.Mount(prefixSubscriptions, NewSubscriptionHandler(subscriptionBackend))
+
 	h.Mount(dbrp.PrefixDBRP, dbrp.NewHTTPHandler(b.Logger, b.DBRPService, b.OrganizationService))
 
 	writeBackend := NewWriteBackend(b.Logger.With(zap.String("handler", "write")), b)
@@ -12,6 +12,7 @@ import (
 	"github.com/influxdata/influxdb/v2"
 	pcontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
 	"github.com/influxdata/influxdb/v2/predicate"
 	"go.uber.org/zap"
 )
@@ -23,6 +24,7 @@ type DeleteBackend struct {
 	influxdb.HTTPErrorHandler
 
 	DeleteService       influxdb.DeleteService
+	DeleteJobService    influxdb.DeleteJobService
 	BucketService       influxdb.BucketService
 	OrganizationService influxdb.OrganizationService
 }
@@ -34,6 +36,7 @@ func NewDeleteBackend(log *zap.Logger, b *APIBackend) *DeleteBackend {
 
 		HTTPErrorHandler:    b.HTTPErrorHandler,
 		DeleteService:       b.DeleteService,
+		DeleteJobService:    b.DeleteJobService,
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
 	}
@@ -47,15 +50,20 @@ type DeleteHandler struct {
 	log *zap.Logger
 
 	DeleteService       influxdb.DeleteService
+	DeleteJobService    influxdb.DeleteJobService
 	BucketService       influxdb.BucketService
 	OrganizationService influxdb.OrganizationService
 }
 
 const (
-	prefixDelete = "/api/v2/delete"
+	prefixDelete     = "/api/v2/delete"
+	prefixDeleteJobs = prefixDelete + "/jobs"
+	deleteJobIDPath  = prefixDeleteJobs + "/:id"
 )
 
 // NewDeleteHandler creates a new handler at /api/v2/delete to receive delete requests.
+// With the async query parameter, the delete runs in the background as a job,
+// which can be followed and canceled at /api/v2/delete/jobs.
 func NewDeleteHandler(log *zap.Logger, b *DeleteBackend) *DeleteHandler {
 	h := &DeleteHandler{
 		HTTPErrorHandler: b.HTTPErrorHandler,
@@ -64,10 +72,14 @@ func NewDeleteHandler(log *zap.Logger, b *DeleteBackend) *DeleteHandler {
 
 		BucketService:       b.BucketService,
 		DeleteService:       b.DeleteService,
+		DeleteJobService:    b.DeleteJobService,
 		OrganizationService: b.OrganizationService,
 	}
 
 	h.HandlerFunc("POST", prefixDelete, h.handleDelete)
+	h.HandlerFunc("GET", prefixDeleteJobs, h.handleGetDeleteJobs)
+	h.HandlerFunc("GET", deleteJobIDPath, h.handleGetDeleteJob)
+	h.HandlerFunc("DELETE", deleteJobIDPath, h.handleCancelDeleteJob)
 	return h
 }
 
@@ -114,6 +126,11 @@ func (h *DeleteHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Get("async") == "true" {
+		h.startDeleteJob(w, r, dr)
+		return
+	}
+
 	if err := h.DeleteService.DeleteBucketRangePredicate(r.Context(), dr.Org.ID, dr.Bucket.ID, dr.Start, dr.Stop, dr.Predicate); err != nil {
 		h.HandleHTTPError(ctx, &influxdb.Error{
 			Code: influxdb.EInternal,
@@ -132,6 +149,127 @@ func (h *DeleteHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *DeleteHandler) startDeleteJob(w http.ResponseWriter, r *http.Request, dr *deleteRequest) {
+	ctx := r.Context()
+
+	job, err := h.DeleteJobService.StartDeleteJob(ctx, influxdb.DeleteJobRequest{
+		OrgID:     dr.Org.ID,
+		BucketID:  dr.Bucket.ID,
+		Start:     dr.Start,
+		Stop:      dr.Stop,
+		Predicate: dr.Predicate,
+		Statement: dr.Statement,
+	})
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	h.log.Debug("Delete job started",
+		zap.String("orgID", dr.Org.ID.String()),
+		zap.String("bucketID", dr.Bucket.ID.String()),
+		zap.String("jobID", job.ID.String()),
+	)
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, job); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type deleteJobsResponse struct {
+	Jobs []*influxdb.DeleteJob `json:"jobs"`
+}
+
+func (h *DeleteHandler) handleGetDeleteJobs(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DeleteHandler.handleGetDeleteJobs")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var filter influxdb.DeleteJobFilter
+	for _, f := range []struct {
+		param string
+		id    **influxdb.ID
+	}{
+		{param: "orgID", id: &filter.OrgID},
+		{param: "bucketID", id: &filter.BucketID},
+	} {
+		s := r.URL.Query().Get(f.param)
+		if s == "" {
+			continue
+		}
+		id, err := influxdb.IDFromString(s)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid %s", f.param),
+				Err:  err,
+			}, w)
+			return
+		}
+		*f.id = id
+	}
+
+	jobs, err := h.DeleteJobService.FindDeleteJobs(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if jobs == nil {
+		jobs = []*influxdb.DeleteJob{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, deleteJobsResponse{Jobs: jobs}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *DeleteHandler) handleGetDeleteJob(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DeleteHandler.handleGetDeleteJob")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	job, err := h.DeleteJobService.FindDeleteJobByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, job); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *DeleteHandler) handleCancelDeleteJob(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DeleteHandler.handleCancelDeleteJob")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.DeleteJobService.CancelDeleteJob(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func decodeDeleteRequest(ctx context.Context, r *http.Request, orgSvc influxdb.OrganizationService, bucketSvc influxdb.BucketService) (*deleteRequest, error) {
 	dr := new(deleteRequest)
 	err := json.NewDecoder(r.Body).Decode(dr)
@@ -158,6 +296,7 @@ type deleteRequest struct {
 	Start     int64
 	Stop      int64
 	Predicate influxdb.Predicate
+	Statement string
 }
 
 type deleteRequestDecode struct {
@@ -206,6 +345,7 @@ func (dr *deleteRequest) UnmarshalJSON(b []byte) error {
 		}
 	}
 	dr.Stop = stop.UnixNano()
+	dr.Statement = drd.Predicate
 	node, err := predicate.Parse(drd.Predicate)
 	if err != nil {
 		return err
@@ -223,6 +363,20 @@ type DeleteService struct {
 
 // DeleteBucketRangePredicate send delete request over http to delete points.
 func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, dr DeleteRequest) error {
+	return s.do(ctx, dr, false, nil)
+}
+
+// StartDeleteJob sends a delete request over http to delete points in the
+// background, and returns the started delete job.
+func (s *DeleteService) StartDeleteJob(ctx context.Context, dr DeleteRequest) (*influxdb.DeleteJob, error) {
+	var job influxdb.DeleteJob
+	if err := s.do(ctx, dr, true, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *DeleteService) do(ctx context.Context, dr DeleteRequest, async bool, v interface{}) error {
 	u, err := NewURL(s.Addr, prefixDelete)
 	if err != nil {
 		return err
@@ -235,6 +389,7 @@ func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, dr Delet
 	if err != nil {
 		return err
 	}
+	req = req.WithContext(ctx)
 
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	SetToken(s.Token, req)
@@ -251,6 +406,9 @@ func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, dr Delet
 	} else if dr.Bucket != "" {
 		params.Set("bucket", dr.Bucket)
 	}
+	if async {
+		params.Set("async", "true")
+	}
 	req.URL.RawQuery = params.Encode()
 
 	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
@@ -261,5 +419,98 @@ func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, dr Delet
 	}
 	defer resp.Body.Close()
 
-	return CheckError(resp)
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+	if v != nil {
+		return json.NewDecoder(resp.Body).Decode(v)
+	}
+	return nil
+}
+
+// DeleteJobService connects to Influx via HTTP to run delete jobs.
+type DeleteJobService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.DeleteJobService = (*DeleteJobService)(nil)
+
+// StartDeleteJob starts deleting the points of a bucket in the background. The
+// points to delete are selected by the statement of req, its parsed predicate
+// is not sent.
+func (s *DeleteJobService) StartDeleteJob(ctx context.Context, req influxdb.DeleteJobRequest) (*influxdb.DeleteJob, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	body := deleteRequestDecode{
+		Start:     time.Unix(0, req.Start).UTC().Format(time.RFC3339Nano),
+		Stop:      time.Unix(0, req.Stop).UTC().Format(time.RFC3339Nano),
+		Predicate: req.Statement,
+	}
+
+	var job influxdb.DeleteJob
+	err := s.Client.
+		PostJSON(body, prefixDelete).
+		QueryParams(
+			[2]string{"orgID", req.OrgID.String()},
+			[2]string{"bucketID", req.BucketID.String()},
+			[2]string{"async", "true"},
+		).
+		DecodeJSON(&job).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindDeleteJobs returns the recent delete jobs matching filter.
+func (s *DeleteJobService) FindDeleteJobs(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.BucketID != nil {
+		params = append(params, [2]string{"bucketID", filter.BucketID.String()})
+	}
+
+	var resp deleteJobsResponse
+	err := s.Client.
+		Get(prefixDeleteJobs).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Jobs, nil
+}
+
+// FindDeleteJobByID returns a single delete job by ID.
+func (s *DeleteJobService) FindDeleteJobByID(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var job influxdb.DeleteJob
+	err := s.Client.
+		Get(prefixDeleteJobs, id.String()).
+		DecodeJSON(&job).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CancelDeleteJob cancels a running delete job.
+func (s *DeleteJobService) CancelDeleteJob(ctx context.Context, id influxdb.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.Client.
+		Delete(prefixDeleteJobs, id.String()).
+		Do(ctx)
 }
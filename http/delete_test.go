@@ -3,10 +3,13 @@ package http
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	pcontext "github.com/influxdata/influxdb/v2/context"
@@ -24,6 +27,7 @@ func NewMockDeleteBackend(t *testing.T) *DeleteBackend {
 		log: zaptest.NewLogger(t),
 
 		DeleteService:       mock.NewDeleteService(),
+		DeleteJobService:    mock.NewDeleteJobService(),
 		BucketService:       mock.NewBucketService(),
 		OrganizationService: mock.NewOrganizationService(),
 	}
@@ -32,6 +36,7 @@ func NewMockDeleteBackend(t *testing.T) *DeleteBackend {
 func TestDelete(t *testing.T) {
 	type fields struct {
 		DeleteService       influxdb.DeleteService
+		DeleteJobService    influxdb.DeleteJobService
 		OrganizationService influxdb.OrganizationService
 		BucketService       influxdb.BucketService
 	}
@@ -242,7 +247,7 @@ func TestDelete(t *testing.T) {
 				body: []byte(`{
 					"start":"2009-01-01T23:00:00Z",
 					"stop":"2019-11-10T01:00:00Z",
-					"predicate": "tag1=\"v1\" and (tag2=\"v2\" or tag3=\"v3\")"
+					"predicate": "tag1=\"v1\" and (tag2>1 or tag3=\"v3\")"
 				}`),
 				authorizer: &influxdb.Authorization{
 					UserID: user1ID,
@@ -282,7 +287,7 @@ func TestDelete(t *testing.T) {
 				statusCode: http.StatusBadRequest,
 				body: `{
 					"code": "invalid",
-					"message": "invalid request; error parsing request json: the logical operator OR is not supported yet at position 25"
+					"message": "invalid request; error parsing request json: invalid operator \">\" at position: 19"
 				  }`,
 			},
 		},
@@ -296,7 +301,7 @@ func TestDelete(t *testing.T) {
 				body: []byte(`{
 					"start":"2009-01-01T23:00:00Z",
 					"stop":"2019-11-10T01:00:00Z",
-					"predicate": "tag1=\"v1\" and (tag2=\"v2\" and tag3=\"v3\")"
+					"predicate": "tag1=\"v1\" and (tag2=\"v2\" or tag3=~/v3/) and _field!=\"f1\""
 				}`),
 				authorizer: &influxdb.Authorization{
 					UserID: user1ID,
@@ -337,12 +342,96 @@ func TestDelete(t *testing.T) {
 				body:       ``,
 			},
 		},
+		{
+			name: "async delete",
+			args: args{
+				queryParams: map[string][]string{
+					"org":    []string{"org1"},
+					"bucket": []string{"buck1"},
+					"async":  []string{"true"},
+				},
+				body: []byte(`{"start":"2009-01-01T23:00:00Z","stop":"2019-11-10T01:00:00Z","predicate":"tag1=\"v1\""}`),
+				authorizer: &influxdb.Authorization{
+					UserID: user1ID,
+					Status: influxdb.Active,
+					Permissions: []influxdb.Permission{
+						{
+							Action: influxdb.WriteAction,
+							Resource: influxdb.Resource{
+								Type:  influxdb.BucketsResourceType,
+								ID:    influxtesting.IDPtr(influxdb.ID(2)),
+								OrgID: influxtesting.IDPtr(influxdb.ID(1)),
+							},
+						},
+					},
+				},
+			},
+			fields: fields{
+				DeleteService: &mock.DeleteService{
+					DeleteBucketRangePredicateF: func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+						return errors.New("unexpected synchronous delete")
+					},
+				},
+				DeleteJobService: &mock.DeleteJobService{
+					StartDeleteJobFn: func(ctx context.Context, req influxdb.DeleteJobRequest) (*influxdb.DeleteJob, error) {
+						if req.OrgID != 1 || req.BucketID != 2 || req.Predicate == nil || req.Statement != `tag1="v1"` {
+							return nil, fmt.Errorf("unexpected request: %+v", req)
+						}
+						return &influxdb.DeleteJob{
+							ID:        influxdb.ID(3),
+							OrgID:     req.OrgID,
+							BucketID:  req.BucketID,
+							Start:     time.Unix(0, req.Start).UTC(),
+							Stop:      time.Unix(0, req.Stop).UTC(),
+							Predicate: req.Statement,
+							Status:    influxdb.DeleteJobRunning,
+							StartedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+							Progress:  influxdb.DeleteJobProgress{ShardsTotal: 2},
+							Errors:    []influxdb.DeleteJobError{},
+						}, nil
+					},
+				},
+				BucketService: &mock.BucketService{
+					FindBucketFn: func(ctx context.Context, f influxdb.BucketFilter) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{
+							ID:   influxdb.ID(2),
+							Name: "bucket1",
+						}, nil
+					},
+				},
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, f influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+						return &influxdb.Organization{
+							ID:   influxdb.ID(1),
+							Name: "org1",
+						}, nil
+					},
+				},
+			},
+			wants: wants{
+				statusCode:  http.StatusAccepted,
+				contentType: "application/json; charset=utf-8",
+				body: `{
+					"id": "0000000000000003",
+					"orgID": "0000000000000001",
+					"bucketID": "0000000000000002",
+					"start": "2009-01-01T23:00:00Z",
+					"stop": "2019-11-10T01:00:00Z",
+					"predicate": "tag1=\"v1\"",
+					"status": "running",
+					"startedAt": "2020-01-01T00:00:00Z",
+					"progress": {"shardsTotal": 2, "shardsDeleted": 0, "seriesDeleted": 0},
+					"errors": []
+				}`,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			deleteBackend := NewMockDeleteBackend(t)
 			deleteBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
 			deleteBackend.DeleteService = tt.fields.DeleteService
+			deleteBackend.DeleteJobService = tt.fields.DeleteJobService
 			deleteBackend.OrganizationService = tt.fields.OrganizationService
 			deleteBackend.BucketService = tt.fields.BucketService
 			h := NewDeleteHandler(zaptest.NewLogger(t), deleteBackend)
@@ -382,3 +471,52 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteHandler_handleGetDeleteJobs(t *testing.T) {
+	b := NewMockDeleteBackend(t)
+	b.DeleteJobService = &mock.DeleteJobService{
+		FindDeleteJobsFn: func(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+			if filter.OrgID != nil || filter.BucketID == nil || *filter.BucketID != 10 {
+				t.Errorf("unexpected filter: %v %v", filter.OrgID, filter.BucketID)
+			}
+			return nil, nil
+		},
+	}
+	h := NewDeleteHandler(zaptest.NewLogger(t), b)
+
+	r := httptest.NewRequest("GET", "http://localhost:8086/api/v2/delete/jobs?bucketID=000000000000000a", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: got %d, want %d: %s", res.StatusCode, http.StatusOK, body)
+	}
+	if exp := "{\"jobs\":[]}\n"; string(body) != exp {
+		t.Errorf("unexpected body:\ngot  %s\nwant %s", body, exp)
+	}
+}
+
+func TestDeleteHandler_handleCancelDeleteJob(t *testing.T) {
+	var canceled []influxdb.ID
+	b := NewMockDeleteBackend(t)
+	b.DeleteJobService = &mock.DeleteJobService{
+		CancelDeleteJobFn: func(ctx context.Context, id influxdb.ID) error {
+			canceled = append(canceled, id)
+			return nil
+		},
+	}
+	h := NewDeleteHandler(zaptest.NewLogger(t), b)
+
+	r := httptest.NewRequest("DELETE", "http://localhost:8086/api/v2/delete/jobs/0000000000000001", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if res := w.Result(); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status code: got %d, want %d", res.StatusCode, http.StatusNoContent)
+	}
+	if len(canceled) != 1 || canceled[0] != 1 {
+		t.Fatalf("unexpected canceled delete jobs: %v", canceled)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
+	pctx "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"go.uber.org/zap"
 )
@@ -21,7 +22,8 @@ type RestoreBackend struct {
 	Logger *zap.Logger
 	influxdb.HTTPErrorHandler
 
-	RestoreService influxdb.RestoreService
+	RestoreService         influxdb.RestoreService
+	ResourceRestoreService influxdb.ResourceRestoreService
 }
 
 // NewRestoreBackend returns a new instance of RestoreBackend.
@@ -29,8 +31,9 @@ func NewRestoreBackend(b *APIBackend) *RestoreBackend {
 	return &RestoreBackend{
 		Logger: b.Logger.With(zap.String("handler", "restore")),
 
-		HTTPErrorHandler: b.HTTPErrorHandler,
-		RestoreService:   b.RestoreService,
+		HTTPErrorHandler:       b.HTTPErrorHandler,
+		RestoreService:         b.RestoreService,
+		ResourceRestoreService: b.ResourceRestoreService,
 	}
 }
 
@@ -40,28 +43,32 @@ type RestoreHandler struct {
 	influxdb.HTTPErrorHandler
 	Logger *zap.Logger
 
-	RestoreService influxdb.RestoreService
+	RestoreService         influxdb.RestoreService
+	ResourceRestoreService influxdb.ResourceRestoreService
 }
 
 const (
-	prefixRestore     = "/api/v2/restore"
-	restoreKVPath     = prefixRestore + "/kv"
-	restoreBucketPath = prefixRestore + "/buckets/:bucketID"
-	restoreShardPath  = prefixRestore + "/shards/:shardID"
+	prefixRestore        = "/api/v2/restore"
+	restoreKVPath        = prefixRestore + "/kv"
+	restoreBucketPath    = prefixRestore + "/buckets/:bucketID"
+	restoreShardPath     = prefixRestore + "/shards/:shardID"
+	restoreResourcesPath = prefixRestore + "/resources"
 )
 
 // NewRestoreHandler creates a new handler at /api/v2/restore to receive restore requests.
 func NewRestoreHandler(b *RestoreBackend) *RestoreHandler {
 	h := &RestoreHandler{
-		HTTPErrorHandler: b.HTTPErrorHandler,
-		Router:           NewRouter(b.HTTPErrorHandler),
-		Logger:           b.Logger,
-		RestoreService:   b.RestoreService,
+		HTTPErrorHandler:       b.HTTPErrorHandler,
+		Router:                 NewRouter(b.HTTPErrorHandler),
+		Logger:                 b.Logger,
+		RestoreService:         b.RestoreService,
+		ResourceRestoreService: b.ResourceRestoreService,
 	}
 
 	h.HandlerFunc(http.MethodPost, restoreKVPath, h.handleRestoreKVStore)
 	h.HandlerFunc(http.MethodPost, restoreBucketPath, h.handleRestoreBucket)
 	h.HandlerFunc(http.MethodPost, restoreShardPath, h.handleRestoreShard)
+	h.HandlerFunc(http.MethodPost, restoreResourcesPath, h.handleRestoreResources)
 
 	return h
 }
@@ -129,6 +136,64 @@ func (h *RestoreHandler) handleRestoreShard(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+func (h *RestoreHandler) handleRestoreResources(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "RestoreHandler.handleRestoreResources")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	req, err := decodeRestoreResourcesRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	report, err := h.ResourceRestoreService.RestoreResources(ctx, auth.GetUserID(), r.Body, req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, report); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeRestoreResourcesRequest(r *http.Request) (influxdb.RestoreResourcesRequest, error) {
+	var req influxdb.RestoreResourcesRequest
+	q := r.URL.Query()
+	for _, p := range []struct {
+		name string
+		id   *influxdb.ID
+	}{
+		{name: "orgID", id: &req.OrgID},
+		{name: "newOrgID", id: &req.NewOrgID},
+	} {
+		if v := q.Get(p.name); v != "" {
+			if err := p.id.DecodeFromString(v); err != nil {
+				return req, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("invalid %s", p.name),
+					Err:  err,
+				}
+			}
+		}
+	}
+	req.Org = q.Get("org")
+	req.NewOrg = q.Get("newOrg")
+	for _, kind := range q["kind"] {
+		req.Kinds = append(req.Kinds, influxdb.ResourceType(kind))
+	}
+	return req, nil
+}
+
 // RestoreService is the client implementation of influxdb.RestoreService.
 type RestoreService struct {
 	Addr               string
@@ -232,3 +297,59 @@ func (s *RestoreService) RestoreShard(ctx context.Context, shardID uint64, r io.
 
 	return nil
 }
+
+// RestoreResources uploads a backup of the metadata database and restores the
+// requested resources of one of its organizations. They are restored on behalf
+// of the user of the token.
+func (s *RestoreService) RestoreResources(ctx context.Context, _ influxdb.ID, r io.Reader, req influxdb.RestoreResourcesRequest) (*influxdb.RestoreResourcesReport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, restoreResourcesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+	if req.OrgID.Valid() {
+		q.Set("orgID", req.OrgID.String())
+	}
+	if req.Org != "" {
+		q.Set("org", req.Org)
+	}
+	if req.NewOrgID.Valid() {
+		q.Set("newOrgID", req.NewOrgID.String())
+	}
+	if req.NewOrg != "" {
+		q.Set("newOrg", req.NewOrg)
+	}
+	for _, kind := range req.Kinds {
+		q.Add("kind", string(kind))
+	}
+	u.RawQuery = q.Encode()
+
+	hreq, err := http.NewRequest(http.MethodPost, u.String(), r)
+	if err != nil {
+		return nil, err
+	}
+	SetToken(s.Token, hreq)
+	hreq = hreq.WithContext(ctx)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	hc.Timeout = httpClientTimeout
+	resp, err := hc.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var report influxdb.RestoreResourcesReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
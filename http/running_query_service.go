@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// RunningQueryBackend is all services and associated parameters required to construct the RunningQueryHandler.
+type RunningQueryBackend struct {
+	Logger *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	RunningQueryService influxdb.RunningQueryService
+}
+
+// NewRunningQueryBackend returns a new instance of RunningQueryBackend.
+func NewRunningQueryBackend(b *APIBackend) *RunningQueryBackend {
+	return &RunningQueryBackend{
+		Logger: b.Logger.With(zap.String("handler", "running_query")),
+
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		RunningQueryService: b.RunningQueryService,
+	}
+}
+
+// RunningQueryHandler is http handler for running query service.
+type RunningQueryHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	RunningQueryService influxdb.RunningQueryService
+}
+
+const (
+	prefixRunningQueries = "/api/v2/queries"
+	runningQueriesIDPath = prefixRunningQueries + "/:id"
+)
+
+// NewRunningQueryHandler creates a new handler at /api/v2/queries to list and kill running queries.
+func NewRunningQueryHandler(b *RunningQueryBackend) *RunningQueryHandler {
+	h := &RunningQueryHandler{
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		Router:              NewRouter(b.HTTPErrorHandler),
+		Logger:              b.Logger,
+		RunningQueryService: b.RunningQueryService,
+	}
+
+	h.HandlerFunc(http.MethodGet, prefixRunningQueries, h.handleGetRunningQueries)
+	h.HandlerFunc(http.MethodDelete, runningQueriesIDPath, h.handleKillQuery)
+
+	return h
+}
+
+type runningQueriesResponse struct {
+	Queries []*influxdb.RunningQuery `json:"queries"`
+}
+
+func (h *RunningQueryHandler) handleGetRunningQueries(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "RunningQueryHandler.handleGetRunningQueries")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var filter influxdb.RunningQueryFilter
+	if id := r.URL.Query().Get("orgID"); id != "" {
+		orgID, err := influxdb.IDFromString(id)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid org id",
+				Err:  err,
+			}, w)
+			return
+		}
+		filter.OrgID = orgID
+	}
+
+	qs, err := h.RunningQueryService.FindRunningQueries(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if qs == nil {
+		qs = []*influxdb.RunningQuery{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, runningQueriesResponse{Queries: qs}); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *RunningQueryHandler) handleKillQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "RunningQueryHandler.handleKillQuery")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	params := httprouter.ParamsFromContext(ctx)
+	id, err := strconv.ParseUint(params.ByName("id"), 10, 64)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid query id",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.RunningQueryService.KillQuery(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunningQueryService connects to Influx via HTTP using tokens to list and kill running queries.
+type RunningQueryService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.RunningQueryService = (*RunningQueryService)(nil)
+
+// FindRunningQueries returns the running queries matching filter.
+func (s *RunningQueryService) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+
+	var resp runningQueriesResponse
+	err := s.Client.
+		Get(prefixRunningQueries).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.ID == nil {
+		return resp.Queries, nil
+	}
+	for _, q := range resp.Queries {
+		if q.ID == *filter.ID {
+			return []*influxdb.RunningQuery{q}, nil
+		}
+	}
+	return nil, nil
+}
+
+// KillQuery interrupts the execution of a running query.
+func (s *RunningQueryService) KillQuery(ctx context.Context, id uint64) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.Client.
+		Delete(path.Join(prefixRunningQueries, strconv.FormatUint(id, 10))).
+		Do(ctx)
+}
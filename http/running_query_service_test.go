@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// NewMockRunningQueryBackend returns a RunningQueryBackend with mock services.
+func NewMockRunningQueryBackend(t *testing.T) *RunningQueryBackend {
+	return &RunningQueryBackend{
+		Logger:              zaptest.NewLogger(t),
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		RunningQueryService: mock.NewRunningQueryService(),
+	}
+}
+
+func TestRunningQueryHandler_handleGetRunningQueries(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		url        string
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "all queries",
+			url:        "http://localhost:8086/api/v2/queries",
+			statusCode: http.StatusOK,
+			body: `{"queries":[{"id":1,"orgID":"000000000000000a","userID":"000000000000000b","language":"influxql","query":"SELECT * FROM cpu","database":"db0","startTime":"2020-01-01T00:00:00Z","duration":1000000000,"state":"running"}]}
+`,
+		},
+		{
+			name:       "queries of an org",
+			url:        "http://localhost:8086/api/v2/queries?orgID=000000000000000c",
+			statusCode: http.StatusOK,
+			body: `{"queries":[]}
+`,
+		},
+		{
+			name:       "invalid org id",
+			url:        "http://localhost:8086/api/v2/queries?orgID=x",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewMockRunningQueryBackend(t)
+			b.RunningQueryService = &mock.RunningQueryService{
+				FindRunningQueriesFn: func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+					if filter.OrgID != nil && *filter.OrgID != 10 {
+						return nil, nil
+					}
+					return []*influxdb.RunningQuery{{
+						ID:        1,
+						OrgID:     10,
+						UserID:    11,
+						Language:  influxdb.RunningQueryLanguageInfluxQL,
+						Query:     "SELECT * FROM cpu",
+						Database:  "db0",
+						StartTime: start,
+						Duration:  time.Second,
+						State:     "running",
+					}}, nil
+				},
+			}
+			h := NewRunningQueryHandler(b)
+
+			r := httptest.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Fatalf("unexpected status code: got %d, want %d: %s", res.StatusCode, tt.statusCode, body)
+			}
+			if tt.body != "" && string(body) != tt.body {
+				t.Errorf("unexpected body:\ngot  %s\nwant %s", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestRunningQueryHandler_handleKillQuery(t *testing.T) {
+	var killed []uint64
+	b := NewMockRunningQueryBackend(t)
+	b.RunningQueryService = &mock.RunningQueryService{
+		KillQueryFn: func(ctx context.Context, id uint64) error {
+			if id != 2 {
+				return influxdb.ErrRunningQueryNotFound
+			}
+			killed = append(killed, id)
+			return nil
+		},
+	}
+	h := NewRunningQueryHandler(b)
+
+	for _, tt := range []struct {
+		url        string
+		statusCode int
+	}{
+		{url: "http://localhost:8086/api/v2/queries/2", statusCode: http.StatusNoContent},
+		{url: "http://localhost:8086/api/v2/queries/3", statusCode: http.StatusNotFound},
+		{url: "http://localhost:8086/api/v2/queries/x", statusCode: http.StatusBadRequest},
+	} {
+		r := httptest.NewRequest("DELETE", tt.url, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if res := w.Result(); res.StatusCode != tt.statusCode {
+			t.Fatalf("%s: unexpected status code: got %d, want %d", tt.url, res.StatusCode, tt.statusCode)
+		}
+	}
+	if len(killed) != 1 || killed[0] != 2 {
+		t.Fatalf("unexpected killed queries: %v", killed)
+	}
+}
@@ -0,0 +1,216 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// ShardBackend is all services and associated parameters required to construct the ShardHandler.
+type ShardBackend struct {
+	Logger *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	ShardService influxdb.ShardService
+}
+
+// NewShardBackend returns a new instance of ShardBackend.
+func NewShardBackend(b *APIBackend) *ShardBackend {
+	return &ShardBackend{
+		Logger: b.Logger.With(zap.String("handler", "shard")),
+
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		ShardService:     b.ShardService,
+	}
+}
+
+// ShardHandler is http handler for shard service.
+type ShardHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	ShardService influxdb.ShardService
+}
+
+const (
+	prefixShards           = "/api/v2/shards"
+	shardsIDPath           = prefixShards + "/:shardID"
+	shardsRebuildIndexPath = shardsIDPath + "/rebuild-index"
+)
+
+// NewShardHandler creates a new handler at /api/v2/shards to list and delete
+// shards, and rebuild their index.
+func NewShardHandler(b *ShardBackend) *ShardHandler {
+	h := &ShardHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		Logger:           b.Logger,
+		ShardService:     b.ShardService,
+	}
+
+	h.HandlerFunc(http.MethodGet, prefixShards, h.handleGetShardGroups)
+	h.HandlerFunc(http.MethodDelete, shardsIDPath, h.handleDeleteShard)
+	h.HandlerFunc(http.MethodPost, shardsRebuildIndexPath, h.handleRebuildShardIndex)
+
+	return h
+}
+
+type shardGroupsResponse struct {
+	ShardGroups []*influxdb.ShardGroup `json:"shardGroups"`
+}
+
+func (h *ShardHandler) handleGetShardGroups(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "ShardHandler.handleGetShardGroups")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var filter influxdb.ShardGroupFilter
+	if id := r.URL.Query().Get("bucketID"); id != "" {
+		bucketID, err := influxdb.IDFromString(id)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid bucket id",
+				Err:  err,
+			}, w)
+			return
+		}
+		filter.BucketID = bucketID
+	}
+
+	sgs, err := h.ShardService.FindShardGroups(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if sgs == nil {
+		sgs = []*influxdb.ShardGroup{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, shardGroupsResponse{ShardGroups: sgs}); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *ShardHandler) handleDeleteShard(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "ShardHandler.handleDeleteShard")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	shardID, err := decodeShardID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.ShardService.DeleteShard(ctx, shardID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ShardHandler) handleRebuildShardIndex(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "ShardHandler.handleRebuildShardIndex")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	shardID, err := decodeShardID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.ShardService.RebuildShardIndex(ctx, shardID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeShardID(ctx context.Context) (uint64, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	shardID, err := strconv.ParseUint(params.ByName("shardID"), 10, 64)
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid shard id",
+			Err:  err,
+		}
+	}
+	return shardID, nil
+}
+
+// ShardService connects to Influx via HTTP using tokens to manage shards.
+type ShardService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.ShardService = (*ShardService)(nil)
+
+// FindShardGroups returns the shard groups matching filter.
+func (s *ShardService) FindShardGroups(ctx context.Context, filter influxdb.ShardGroupFilter) ([]*influxdb.ShardGroup, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var params [][2]string
+	if filter.BucketID != nil {
+		params = append(params, [2]string{"bucketID", filter.BucketID.String()})
+	}
+
+	var resp shardGroupsResponse
+	err := s.Client.
+		Get(prefixShards).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.ShardID == nil {
+		return resp.ShardGroups, nil
+	}
+	for _, sg := range resp.ShardGroups {
+		for _, sh := range sg.Shards {
+			if sh.ID == *filter.ShardID {
+				return []*influxdb.ShardGroup{sg}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// DeleteShard removes a single shard and its data.
+func (s *ShardService) DeleteShard(ctx context.Context, shardID uint64) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.Client.
+		Delete(path.Join(prefixShards, strconv.FormatUint(shardID, 10))).
+		Do(ctx)
+}
+
+// RebuildShardIndex rebuilds the index of a single shard.
+func (s *ShardService) RebuildShardIndex(ctx context.Context, shardID uint64) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.Client.
+		Post(httpc.BodyEmpty, prefixShards, strconv.FormatUint(shardID, 10), "rebuild-index").
+		Do(ctx)
+}
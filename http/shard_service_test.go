@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// NewMockShardBackend returns a ShardBackend with mock services.
+func NewMockShardBackend(t *testing.T) *ShardBackend {
+	return &ShardBackend{
+		Logger:           zaptest.NewLogger(t),
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
+		ShardService:     mock.NewShardService(),
+	}
+}
+
+func TestShardHandler_handleGetShardGroups(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		url        string
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "all shard groups",
+			url:        "http://localhost:8086/api/v2/shards",
+			statusCode: http.StatusOK,
+			body: `{"shardGroups":[{"id":1,"bucketID":"000000000000000a","startTime":"2020-01-01T00:00:00Z","endTime":"2020-01-02T00:00:00Z","expiryTime":"2020-01-03T00:00:00Z","shards":[{"id":2,"owners":[0],"size":1024}]}]}
+`,
+		},
+		{
+			name:       "shard groups of a bucket",
+			url:        "http://localhost:8086/api/v2/shards?bucketID=000000000000000b",
+			statusCode: http.StatusOK,
+			body: `{"shardGroups":[]}
+`,
+		},
+		{
+			name:       "invalid bucket id",
+			url:        "http://localhost:8086/api/v2/shards?bucketID=x",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewMockShardBackend(t)
+			b.ShardService = &mock.ShardService{
+				FindShardGroupsFn: func(ctx context.Context, filter influxdb.ShardGroupFilter) ([]*influxdb.ShardGroup, error) {
+					if filter.BucketID != nil && *filter.BucketID != 10 {
+						return nil, nil
+					}
+					return []*influxdb.ShardGroup{{
+						ID:         1,
+						BucketID:   10,
+						StartTime:  start,
+						EndTime:    start.Add(24 * time.Hour),
+						ExpiryTime: start.Add(48 * time.Hour),
+						Shards:     []influxdb.Shard{{ID: 2, Owners: []uint64{0}, Size: 1024}},
+					}}, nil
+				},
+			}
+			h := NewShardHandler(b)
+
+			r := httptest.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Fatalf("unexpected status code: got %d, want %d: %s", res.StatusCode, tt.statusCode, body)
+			}
+			if tt.body != "" && string(body) != tt.body {
+				t.Errorf("unexpected body:\ngot  %s\nwant %s", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestShardHandler_handleDeleteShard(t *testing.T) {
+	var deleted []uint64
+	b := NewMockShardBackend(t)
+	b.ShardService = &mock.ShardService{
+		DeleteShardFn: func(ctx context.Context, shardID uint64) error {
+			deleted = append(deleted, shardID)
+			return nil
+		},
+	}
+	h := NewShardHandler(b)
+
+	r := httptest.NewRequest("DELETE", "http://localhost:8086/api/v2/shards/2", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if res := w.Result(); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status code: got %d, want %d", res.StatusCode, http.StatusNoContent)
+	}
+	if len(deleted) != 1 || deleted[0] != 2 {
+		t.Fatalf("unexpected deleted shards: %v", deleted)
+	}
+
+	r = httptest.NewRequest("DELETE", "http://localhost:8086/api/v2/shards/x", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if res := w.Result(); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected status code: got %d, want %d", res.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestShardHandler_handleRebuildShardIndex(t *testing.T) {
+	var rebuilt []uint64
+	b := NewMockShardBackend(t)
+	b.ShardService = &mock.ShardService{
+		RebuildShardIndexFn: func(ctx context.Context, shardID uint64) error {
+			rebuilt = append(rebuilt, shardID)
+			return nil
+		},
+	}
+	h := NewShardHandler(b)
+
+	r := httptest.NewRequest("POST", "http://localhost:8086/api/v2/shards/2/rebuild-index", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if res := w.Result(); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status code: got %d, want %d", res.StatusCode, http.StatusNoContent)
+	}
+	if len(rebuilt) != 1 || rebuilt[0] != 2 {
+		t.Fatalf("unexpected rebuilt shards: %v", rebuilt)
+	}
+}
@@ -0,0 +1,179 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"go.uber.org/zap"
+)
+
+// StorageVerifyBackend is all services and associated parameters required to construct the StorageVerifyHandler.
+type StorageVerifyBackend struct {
+	Logger *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	StorageVerifyService influxdb.StorageVerifyService
+}
+
+// NewStorageVerifyBackend returns a new instance of StorageVerifyBackend.
+func NewStorageVerifyBackend(b *APIBackend) *StorageVerifyBackend {
+	return &StorageVerifyBackend{
+		Logger: b.Logger.With(zap.String("handler", "storage_verify")),
+
+		HTTPErrorHandler:     b.HTTPErrorHandler,
+		StorageVerifyService: b.StorageVerifyService,
+	}
+}
+
+// StorageVerifyHandler is http handler for storage verify service.
+type StorageVerifyHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	StorageVerifyService influxdb.StorageVerifyService
+}
+
+const (
+	prefixStorageVerify = "/api/v2/storage/verify"
+	storageVerifyIDPath = prefixStorageVerify + "/:id"
+)
+
+// NewStorageVerifyHandler creates a new handler at /api/v2/storage/verify to
+// start, follow and cancel storage verifications.
+func NewStorageVerifyHandler(b *StorageVerifyBackend) *StorageVerifyHandler {
+	h := &StorageVerifyHandler{
+		HTTPErrorHandler:     b.HTTPErrorHandler,
+		Router:               NewRouter(b.HTTPErrorHandler),
+		Logger:               b.Logger,
+		StorageVerifyService: b.StorageVerifyService,
+	}
+
+	h.HandlerFunc(http.MethodPost, prefixStorageVerify, h.handlePostVerification)
+	h.HandlerFunc(http.MethodGet, prefixStorageVerify, h.handleGetVerifications)
+	h.HandlerFunc(http.MethodGet, storageVerifyIDPath, h.handleGetVerification)
+	h.HandlerFunc(http.MethodDelete, storageVerifyIDPath, h.handleCancelVerification)
+
+	return h
+}
+
+type storageVerificationsResponse struct {
+	Verifications []*influxdb.StorageVerification `json:"verifications"`
+}
+
+func (h *StorageVerifyHandler) handlePostVerification(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "StorageVerifyHandler.handlePostVerification")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var req influxdb.StorageVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		}, w)
+		return
+	}
+	if !req.BucketID.Valid() {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bucketID is required",
+		}, w)
+		return
+	}
+
+	v, err := h.StorageVerifyService.VerifyStorage(ctx, req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, v); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *StorageVerifyHandler) handleGetVerifications(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "StorageVerifyHandler.handleGetVerifications")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var filter influxdb.StorageVerificationFilter
+	if id := r.URL.Query().Get("bucketID"); id != "" {
+		bucketID, err := influxdb.IDFromString(id)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid bucket id",
+				Err:  err,
+			}, w)
+			return
+		}
+		filter.BucketID = bucketID
+	}
+
+	vs, err := h.StorageVerifyService.FindStorageVerifications(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if vs == nil {
+		vs = []*influxdb.StorageVerification{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, storageVerificationsResponse{Verifications: vs}); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *StorageVerifyHandler) handleGetVerification(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "StorageVerifyHandler.handleGetVerification")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	v, err := h.StorageVerifyService.FindStorageVerificationByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, v); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *StorageVerifyHandler) handleCancelVerification(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "StorageVerifyHandler.handleCancelVerification")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.StorageVerifyService.CancelStorageVerification(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
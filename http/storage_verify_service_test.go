@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// NewMockStorageVerifyBackend returns a StorageVerifyBackend with mock services.
+func NewMockStorageVerifyBackend(t *testing.T) *StorageVerifyBackend {
+	return &StorageVerifyBackend{
+		Logger:               zaptest.NewLogger(t),
+		HTTPErrorHandler:     kithttp.ErrorHandler(0),
+		StorageVerifyService: mock.NewStorageVerifyService(),
+	}
+}
+
+func TestStorageVerifyHandler_handlePostVerification(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		response   string
+	}{
+		{
+			name:       "verify a shard",
+			body:       `{"bucketID":"000000000000000a","shardID":2,"bytesPerSecond":1024}`,
+			statusCode: http.StatusAccepted,
+			response: `{"id":"0000000000000001","bucketID":"000000000000000a","shardID":2,"status":"running","startedAt":"2020-01-01T00:00:00Z","progress":{"shardsTotal":1,"shardsVerified":0,"filesVerified":0,"blocksVerified":0,"bytesVerified":0},"issues":[]}
+`,
+		},
+		{
+			name:       "missing bucket id",
+			body:       `{"shardID":2}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid json",
+			body:       `{`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewMockStorageVerifyBackend(t)
+			b.StorageVerifyService = &mock.StorageVerifyService{
+				VerifyStorageFn: func(ctx context.Context, req influxdb.StorageVerificationRequest) (*influxdb.StorageVerification, error) {
+					if req.BytesPerSecond != 1024 {
+						t.Errorf("unexpected bytes per second: %d", req.BytesPerSecond)
+					}
+					return &influxdb.StorageVerification{
+						ID:        1,
+						BucketID:  req.BucketID,
+						ShardID:   req.ShardID,
+						Status:    influxdb.StorageVerificationRunning,
+						StartedAt: start,
+						Progress:  influxdb.StorageVerificationProgress{ShardsTotal: 1},
+						Issues:    []influxdb.StorageVerificationIssue{},
+					}, nil
+				},
+			}
+			h := NewStorageVerifyHandler(b)
+
+			r := httptest.NewRequest("POST", "http://localhost:8086/api/v2/storage/verify", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Fatalf("unexpected status code: got %d, want %d: %s", res.StatusCode, tt.statusCode, body)
+			}
+			if tt.response != "" && string(body) != tt.response {
+				t.Errorf("unexpected body:\ngot  %s\nwant %s", body, tt.response)
+			}
+		})
+	}
+}
+
+func TestStorageVerifyHandler_handleGetVerifications(t *testing.T) {
+	b := NewMockStorageVerifyBackend(t)
+	b.StorageVerifyService = &mock.StorageVerifyService{
+		FindStorageVerificationsFn: func(ctx context.Context, filter influxdb.StorageVerificationFilter) ([]*influxdb.StorageVerification, error) {
+			if filter.BucketID == nil || *filter.BucketID != 10 {
+				t.Errorf("unexpected filter: %v", filter.BucketID)
+			}
+			return nil, nil
+		},
+	}
+	h := NewStorageVerifyHandler(b)
+
+	r := httptest.NewRequest("GET", "http://localhost:8086/api/v2/storage/verify?bucketID=000000000000000a", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: got %d, want %d: %s", res.StatusCode, http.StatusOK, body)
+	}
+	if exp := "{\"verifications\":[]}\n"; string(body) != exp {
+		t.Errorf("unexpected body:\ngot  %s\nwant %s", body, exp)
+	}
+}
+
+func TestStorageVerifyHandler_handleCancelVerification(t *testing.T) {
+	var canceled []influxdb.ID
+	b := NewMockStorageVerifyBackend(t)
+	b.StorageVerifyService = &mock.StorageVerifyService{
+		CancelStorageVerificationFn: func(ctx context.Context, id influxdb.ID) error {
+			canceled = append(canceled, id)
+			return nil
+		},
+	}
+	h := NewStorageVerifyHandler(b)
+
+	r := httptest.NewRequest("DELETE", "http://localhost:8086/api/v2/storage/verify/0000000000000001", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if res := w.Result(); res.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status code: got %d, want %d", res.StatusCode, http.StatusNoContent)
+	}
+	if len(canceled) != 1 || canceled[0] != 1 {
+		t.Fatalf("unexpected canceled verifications: %v", canceled)
+	}
+}
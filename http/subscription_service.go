@@ -0,0 +1,289 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// SubscriptionBackend is all services and associated parameters required to construct the SubscriptionHandler.
+type SubscriptionBackend struct {
+	Logger *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	SubscriptionService influxdb.SubscriptionService
+}
+
+// NewSubscriptionBackend returns a new instance of SubscriptionBackend.
+func NewSubscriptionBackend(b *APIBackend) *SubscriptionBackend {
+	return &SubscriptionBackend{
+		Logger: b.Logger.With(zap.String("handler", "subscription")),
+
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		SubscriptionService: b.SubscriptionService,
+	}
+}
+
+// SubscriptionHandler is http handler for subscription service.
+type SubscriptionHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	SubscriptionService influxdb.SubscriptionService
+}
+
+const (
+	prefixSubscriptions = "/api/v2/subscriptions"
+	subscriptionsIDPath = prefixSubscriptions + "/:id"
+)
+
+// NewSubscriptionHandler creates a new handler at /api/v2/subscriptions to manage the subscriptions of buckets.
+func NewSubscriptionHandler(b *SubscriptionBackend) *SubscriptionHandler {
+	h := &SubscriptionHandler{
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		Router:              NewRouter(b.HTTPErrorHandler),
+		Logger:              b.Logger,
+		SubscriptionService: b.SubscriptionService,
+	}
+
+	h.HandlerFunc(http.MethodGet, prefixSubscriptions, h.handleGetSubscriptions)
+	h.HandlerFunc(http.MethodPost, prefixSubscriptions, h.handlePostSubscription)
+	h.HandlerFunc(http.MethodGet, subscriptionsIDPath, h.handleGetSubscription)
+	h.HandlerFunc(http.MethodDelete, subscriptionsIDPath, h.handleDeleteSubscription)
+
+	return h
+}
+
+type subscriptionsResponse struct {
+	Subscriptions []*influxdb.Subscription `json:"subscriptions"`
+}
+
+func (h *SubscriptionHandler) handleGetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SubscriptionHandler.handleGetSubscriptions")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter, err := decodeSubscriptionFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	subs, _, err := h.SubscriptionService.FindSubscriptions(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if subs == nil {
+		subs = []*influxdb.Subscription{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, subscriptionsResponse{Subscriptions: subs}); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeSubscriptionFilter(r *http.Request) (influxdb.SubscriptionFilter, error) {
+	var filter influxdb.SubscriptionFilter
+	q := r.URL.Query()
+	if id := q.Get("orgID"); id != "" {
+		orgID, err := influxdb.IDFromString(id)
+		if err != nil {
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid org id",
+				Err:  err,
+			}
+		}
+		filter.OrgID = orgID
+	}
+	if id := q.Get("bucketID"); id != "" {
+		bucketID, err := influxdb.IDFromString(id)
+		if err != nil {
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid bucket id",
+				Err:  err,
+			}
+		}
+		filter.BucketID = bucketID
+	}
+	if name := q.Get("name"); name != "" {
+		filter.Name = &name
+	}
+	return filter, nil
+}
+
+func (h *SubscriptionHandler) handlePostSubscription(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SubscriptionHandler.handlePostSubscription")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var sub influxdb.Subscription
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid subscription",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.SubscriptionService.CreateSubscription(ctx, &sub); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, &sub); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *SubscriptionHandler) handleGetSubscription(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SubscriptionHandler.handleGetSubscription")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	id, err := decodeSubscriptionID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	sub, err := h.SubscriptionService.FindSubscriptionByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, sub); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *SubscriptionHandler) handleDeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SubscriptionHandler.handleDeleteSubscription")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	id, err := decodeSubscriptionID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.SubscriptionService.DeleteSubscription(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeSubscriptionID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id, err := influxdb.IDFromString(params.ByName("id"))
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid subscription id",
+			Err:  err,
+		}
+	}
+	return *id, nil
+}
+
+// SubscriptionService connects to Influx via HTTP using tokens to manage subscriptions.
+type SubscriptionService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.SubscriptionService = (*SubscriptionService)(nil)
+
+// FindSubscriptionByID returns a single subscription by ID.
+func (s *SubscriptionService) FindSubscriptionByID(ctx context.Context, id influxdb.ID) (*influxdb.Subscription, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var sub influxdb.Subscription
+	err := s.Client.
+		Get(path.Join(prefixSubscriptions, id.String())).
+		DecodeJSON(&sub).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// FindSubscriptions returns the subscriptions matching filter.
+func (s *SubscriptionService) FindSubscriptions(ctx context.Context, filter influxdb.SubscriptionFilter) ([]*influxdb.Subscription, int, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if filter.ID != nil {
+		sub, err := s.FindSubscriptionByID(ctx, *filter.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []*influxdb.Subscription{sub}, 1, nil
+	}
+
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.BucketID != nil {
+		params = append(params, [2]string{"bucketID", filter.BucketID.String()})
+	}
+	if filter.Name != nil {
+		params = append(params, [2]string{"name", *filter.Name})
+	}
+
+	var resp subscriptionsResponse
+	err := s.Client.
+		Get(prefixSubscriptions).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Subscriptions, len(resp.Subscriptions), nil
+}
+
+// CreateSubscription creates a new subscription and sets sub.ID with the new identifier.
+func (s *SubscriptionService) CreateSubscription(ctx context.Context, sub *influxdb.Subscription) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.Client.
+		PostJSON(sub, prefixSubscriptions).
+		DecodeJSON(sub).
+		Do(ctx)
+}
+
+// DeleteSubscription removes a subscription by ID.
+func (s *SubscriptionService) DeleteSubscription(ctx context.Context, id influxdb.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.Client.
+		Delete(path.Join(prefixSubscriptions, id.String())).
+		Do(ctx)
+}
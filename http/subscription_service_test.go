@@ -0,0 +1,248 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// NewMockSubscriptionBackend returns a SubscriptionBackend with mock services.
+func NewMockSubscriptionBackend(t *testing.T) *SubscriptionBackend {
+	return &SubscriptionBackend{
+		Logger:              zaptest.NewLogger(t),
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		SubscriptionService: mock.NewSubscriptionService(),
+	}
+}
+
+// newInmemSubscriptionService returns a mock subscription service holding
+// the subscriptions in memory.
+func newInmemSubscriptionService(subs ...*influxdb.Subscription) *mock.SubscriptionService {
+	s := mock.NewSubscriptionService()
+	s.FindSubscriptionByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Subscription, error) {
+		for _, sub := range subs {
+			if sub.ID == id {
+				return sub, nil
+			}
+		}
+		return nil, influxdb.ErrSubscriptionNotFound
+	}
+	s.FindSubscriptionsFn = func(ctx context.Context, filter influxdb.SubscriptionFilter) ([]*influxdb.Subscription, int, error) {
+		var found []*influxdb.Subscription
+		for _, sub := range subs {
+			if filter.OrgID != nil && sub.OrgID != *filter.OrgID {
+				continue
+			}
+			if filter.BucketID != nil && sub.BucketID != *filter.BucketID {
+				continue
+			}
+			if filter.Name != nil && sub.Name != *filter.Name {
+				continue
+			}
+			found = append(found, sub)
+		}
+		return found, len(found), nil
+	}
+	s.CreateSubscriptionFn = func(ctx context.Context, sub *influxdb.Subscription) error {
+		if err := sub.Valid(); err != nil {
+			return err
+		}
+		sub.ID = influxdb.ID(len(subs) + 1)
+		subs = append(subs, sub)
+		return nil
+	}
+	s.DeleteSubscriptionFn = func(ctx context.Context, id influxdb.ID) error {
+		for i, sub := range subs {
+			if sub.ID == id {
+				subs = append(subs[:i], subs[i+1:]...)
+				return nil
+			}
+		}
+		return influxdb.ErrSubscriptionNotFound
+	}
+	return s
+}
+
+func TestSubscriptionHandler_handleGetSubscriptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "all subscriptions",
+			url:        "http://localhost:8086/api/v2/subscriptions",
+			statusCode: http.StatusOK,
+			body: `{"subscriptions":[{"id":"0000000000000001","orgID":"000000000000000a","bucketID":"0000000000000064","name":"s0","mode":"ALL","destinations":["udp://h1:9093"],"createdAt":"0001-01-01T00:00:00Z","updatedAt":"0001-01-01T00:00:00Z"},{"id":"0000000000000002","orgID":"000000000000000a","bucketID":"0000000000000065","name":"s1","mode":"ANY","destinations":["http://h1:9092","http://h2:9092"],"database":"db0","retentionPolicy":"rp0","createdAt":"0001-01-01T00:00:00Z","updatedAt":"0001-01-01T00:00:00Z"}]}
+`,
+		},
+		{
+			name:       "subscriptions of a bucket",
+			url:        "http://localhost:8086/api/v2/subscriptions?bucketID=0000000000000065",
+			statusCode: http.StatusOK,
+			body: `{"subscriptions":[{"id":"0000000000000002","orgID":"000000000000000a","bucketID":"0000000000000065","name":"s1","mode":"ANY","destinations":["http://h1:9092","http://h2:9092"],"database":"db0","retentionPolicy":"rp0","createdAt":"0001-01-01T00:00:00Z","updatedAt":"0001-01-01T00:00:00Z"}]}
+`,
+		},
+		{
+			name:       "subscriptions of another org",
+			url:        "http://localhost:8086/api/v2/subscriptions?orgID=000000000000000b",
+			statusCode: http.StatusOK,
+			body: `{"subscriptions":[]}
+`,
+		},
+		{
+			name:       "invalid bucket id",
+			url:        "http://localhost:8086/api/v2/subscriptions?bucketID=x",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewMockSubscriptionBackend(t)
+			b.SubscriptionService = newInmemSubscriptionService(
+				&influxdb.Subscription{ID: 1, OrgID: 10, BucketID: 100, Name: "s0", Mode: "ALL", Destinations: []string{"udp://h1:9093"}},
+				&influxdb.Subscription{ID: 2, OrgID: 10, BucketID: 101, Name: "s1", Mode: "ANY", Destinations: []string{"http://h1:9092", "http://h2:9092"}, Database: "db0", RetentionPolicy: "rp0"},
+			)
+			h := NewSubscriptionHandler(b)
+
+			r := httptest.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Fatalf("unexpected status code: got %d, want %d: %s", res.StatusCode, tt.statusCode, body)
+			}
+			if tt.body != "" && string(body) != tt.body {
+				t.Errorf("unexpected body:\ngot  %s\nwant %s", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestSubscriptionHandler_handlePostSubscription(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "valid subscription",
+			body:       `{"orgID":"000000000000000a","bucketID":"0000000000000064","name":"s0","mode":"ALL","destinations":["udp://h1:9093"]}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "invalid mode",
+			body:       `{"orgID":"000000000000000a","bucketID":"0000000000000064","name":"s0","mode":"SOME","destinations":["udp://h1:9093"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid json",
+			body:       `{`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewMockSubscriptionBackend(t)
+			b.SubscriptionService = newInmemSubscriptionService()
+			h := NewSubscriptionHandler(b)
+
+			r := httptest.NewRequest("POST", "http://localhost:8086/api/v2/subscriptions", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Fatalf("unexpected status code: got %d, want %d: %s", res.StatusCode, tt.statusCode, body)
+			}
+		})
+	}
+}
+
+func TestSubscriptionHandler_handleDeleteSubscription(t *testing.T) {
+	b := NewMockSubscriptionBackend(t)
+	b.SubscriptionService = newInmemSubscriptionService(
+		&influxdb.Subscription{ID: 1, OrgID: 10, BucketID: 100, Name: "s0", Mode: "ALL", Destinations: []string{"udp://h1:9093"}},
+	)
+	h := NewSubscriptionHandler(b)
+
+	for _, tt := range []struct {
+		url        string
+		statusCode int
+	}{
+		{url: "http://localhost:8086/api/v2/subscriptions/0000000000000001", statusCode: http.StatusNoContent},
+		{url: "http://localhost:8086/api/v2/subscriptions/0000000000000001", statusCode: http.StatusNotFound},
+		{url: "http://localhost:8086/api/v2/subscriptions/x", statusCode: http.StatusBadRequest},
+	} {
+		r := httptest.NewRequest("DELETE", tt.url, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if res := w.Result(); res.StatusCode != tt.statusCode {
+			t.Fatalf("%s: unexpected status code: got %d, want %d", tt.url, res.StatusCode, tt.statusCode)
+		}
+	}
+}
+
+func TestSubscriptionService_Client(t *testing.T) {
+	ctx := context.Background()
+
+	b := NewMockSubscriptionBackend(t)
+	b.SubscriptionService = newInmemSubscriptionService()
+	server := httptest.NewServer(NewSubscriptionHandler(b))
+	defer server.Close()
+
+	s := &SubscriptionService{Client: mustNewHTTPClient(t, server.URL, "")}
+
+	sub := &influxdb.Subscription{
+		OrgID:        10,
+		BucketID:     100,
+		Name:         "s0",
+		Mode:         influxdb.SubscriptionModeAny,
+		Destinations: []string{"http://h1:9092", "http://h2:9092"},
+	}
+	if err := s.CreateSubscription(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	if sub.ID != 1 {
+		t.Fatalf("unexpected subscription id: %v", sub.ID)
+	}
+
+	got, err := s.FindSubscriptionByID(ctx, sub.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, sub); diff != "" {
+		t.Fatalf("unexpected subscription -got/+want\ndiff %s", diff)
+	}
+
+	bucketID := influxdb.ID(100)
+	subs, n, err := s.FindSubscriptions(ctx, influxdb.SubscriptionFilter{BucketID: &bucketID})
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 || len(subs) != 1 || subs[0].Name != "s0" {
+		t.Fatalf("unexpected subscriptions: %v", subs)
+	}
+
+	if err := s.DeleteSubscription(ctx, sub.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindSubscriptionByID(ctx, sub.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
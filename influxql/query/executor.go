@@ -10,10 +10,12 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	iql "github.com/influxdata/influxdb/v2/influxql"
 	"github.com/influxdata/influxdb/v2/influxql/control"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/query/registry"
 	"github.com/influxdata/influxql"
 	"github.com/opentracing/opentracing-go/log"
 	"go.uber.org/zap"
@@ -172,6 +174,9 @@ type Executor struct {
 
 	Metrics *control.ControllerMetrics
 
+	// Registry tracks the running queries so they can be listed and killed.
+	Registry *registry.Registry
+
 	log *zap.Logger
 }
 
@@ -206,6 +211,12 @@ func (e *Executor) executeQuery(ctx context.Context, query *influxql.Query, opt
 
 	defer e.recover(query, results)
 
+	if e.Registry != nil {
+		var done func()
+		ctx, done = e.register(ctx, query, opt, results)
+		defer done()
+	}
+
 	gatherer := new(iql.StatisticsGatherer)
 
 	statusLabel := control.LabelSuccess
@@ -329,6 +340,42 @@ LOOP:
 	}
 }
 
+// register adds the query to the registry and returns a context that is
+// canceled when the query is killed. The returned function removes the query
+// from the registry and reports the interruption of killed queries.
+func (e *Executor) register(ctx context.Context, query *influxql.Query, opt ExecutionOptions, results chan *Result) (context.Context, func()) {
+	q := influxdb.RunningQuery{
+		OrgID:    opt.OrgID,
+		Language: influxdb.RunningQueryLanguageInfluxQL,
+		Query:    query.String(),
+		Database: opt.Database,
+	}
+	if a, err := icontext.GetAuthorizer(ctx); err == nil {
+		q.UserID = a.GetUserID()
+		q.AuthorizationID = a.Identifier()
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	_, unregister := e.Registry.Register(q, func() string {
+		if ctx.Err() != nil {
+			return "killed"
+		}
+		return "running"
+	}, cancel)
+
+	return ctx, func() {
+		unregister()
+		if ctx.Err() != nil && parent.Err() == nil {
+			select {
+			case results <- &Result{Err: ErrQueryInterrupted}:
+			case <-parent.Done():
+			}
+		}
+		cancel()
+	}
+}
+
 // Determines if the Executor will recover any panics or let them crash
 // the server.
 var willCrash bool
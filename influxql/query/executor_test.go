@@ -7,10 +7,12 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/influxdata/influxdb/v2"
 	iql "github.com/influxdata/influxdb/v2/influxql"
 	"github.com/influxdata/influxdb/v2/influxql/control"
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/influxql/query/mocks"
+	"github.com/influxdata/influxdb/v2/query/registry"
 	"github.com/influxdata/influxql"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zaptest"
@@ -59,6 +61,68 @@ func TestQueryExecutor_Interrupt(t *testing.T) {
 	}
 }
 
+func TestQueryExecutor_Kill(t *testing.T) {
+	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	e := NewQueryExecutor(t)
+	e.Registry = registry.New()
+	e.StatementExecutor = &StatementExecutor{
+		ExecuteStatementFn: func(ctx context.Context, stmt influxql.Statement, ectx *query.ExecutionContext) error {
+			close(started)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(100 * time.Millisecond):
+				t.Error("killing the query did not close the channel after 100 milliseconds")
+				return errUnexpected
+			}
+		},
+	}
+
+	orgID := influxdb.ID(1)
+	results, _ := e.ExecuteQuery(context.Background(), q, query.ExecutionOptions{OrgID: orgID, Database: "db0"})
+	<-started
+
+	queries, err := e.Registry.FindRunningQueries(context.Background(), influxdb.RunningQueryFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 {
+		t.Fatalf("unexpected number of running queries: %d", len(queries))
+	}
+	if got, exp := queries[0].Query, q.String(); got != exp {
+		t.Errorf("unexpected query: got %q, exp %q", got, exp)
+	}
+	if got, exp := queries[0].Database, "db0"; got != exp {
+		t.Errorf("unexpected database: got %q, exp %q", got, exp)
+	}
+	if got, exp := queries[0].Language, influxdb.RunningQueryLanguageInfluxQL; got != exp {
+		t.Errorf("unexpected language: got %q, exp %q", got, exp)
+	}
+
+	if err := e.Registry.KillQuery(context.Background(), queries[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	result := <-results
+	if result == nil || result.Err != query.ErrQueryInterrupted {
+		t.Errorf("unexpected result: %v", result)
+	}
+	discardOutput(results)
+
+	queries, err = e.Registry.FindRunningQueries(context.Background(), influxdb.RunningQueryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 0 {
+		t.Fatalf("unexpected number of running queries: %d", len(queries))
+	}
+}
+
 func TestQueryExecutor_Abort(t *testing.T) {
 	q, err := influxql.ParseQuery(`SELECT count(value) FROM cpu`)
 	if err != nil {
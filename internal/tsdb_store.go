@@ -6,6 +6,7 @@ import (
 
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
@@ -30,11 +31,13 @@ type TSDBStoreMock struct {
 	ImportShardFn             func(id uint64, r io.Reader) error
 	MeasurementSeriesCountsFn func(database string) (measurements int, series int)
 	MeasurementsCardinalityFn func(database string) (int64, error)
+	MeasurementsSketchesFn    func(database string) (estimator.Sketch, estimator.Sketch, error)
 	MeasurementNamesFn        func(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error)
 	OpenFn                    func() error
 	PathFn                    func() string
 	RestoreShardFn            func(id uint64, r io.Reader) error
 	SeriesCardinalityFn       func(database string) (int64, error)
+	SeriesSketchesFn          func(database string) (estimator.Sketch, estimator.Sketch, error)
 	SetShardEnabledFn         func(shardID uint64, enabled bool) error
 	ShardFn                   func(id uint64) *tsdb.Shard
 	ShardGroupFn              func(ids []uint64) tsdb.ShardGroup
@@ -101,6 +104,9 @@ func (s *TSDBStoreMock) MeasurementSeriesCounts(database string) (measurements i
 func (s *TSDBStoreMock) MeasurementsCardinality(database string) (int64, error) {
 	return s.MeasurementsCardinalityFn(database)
 }
+func (s *TSDBStoreMock) MeasurementsSketches(database string) (estimator.Sketch, estimator.Sketch, error) {
+	return s.MeasurementsSketchesFn(database)
+}
 func (s *TSDBStoreMock) Open() error {
 	return s.OpenFn()
 }
@@ -113,6 +119,9 @@ func (s *TSDBStoreMock) RestoreShard(id uint64, r io.Reader) error {
 func (s *TSDBStoreMock) SeriesCardinality(database string) (int64, error) {
 	return s.SeriesCardinalityFn(database)
 }
+func (s *TSDBStoreMock) SeriesSketches(database string) (estimator.Sketch, estimator.Sketch, error) {
+	return s.SeriesSketchesFn(database)
+}
 func (s *TSDBStoreMock) SetShardEnabled(shardID uint64, enabled bool) error {
 	return s.SetShardEnabledFn(shardID, enabled)
 }
@@ -0,0 +1,9 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+// Migration0015_AddSubscriptionBuckets creates the bucket necessary for the subscription service to operate.
+var Migration0015_AddSubscriptionBuckets = migration.CreateBuckets(
+	"create subscription buckets",
+	[]byte("subscriptionsv1"),
+)
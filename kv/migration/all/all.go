@@ -35,5 +35,7 @@ var Migrations = [...]migration.Spec{
 	Migration0013_RepairDBRPOwnerAndBucketIDs,
 	// reindex DBRPs
 	Migration0014_ReindexDBRPs,
+	// add subscription buckets
+	Migration0015_AddSubscriptionBuckets,
 	// {{ do_not_edit . }}
 }
@@ -0,0 +1,50 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.DeleteJobService = &DeleteJobService{}
+
+// DeleteJobService is a mock implementation of influxdb.DeleteJobService.
+type DeleteJobService struct {
+	StartDeleteJobFn    func(ctx context.Context, req influxdb.DeleteJobRequest) (*influxdb.DeleteJob, error)
+	FindDeleteJobsFn    func(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error)
+	FindDeleteJobByIDFn func(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error)
+	CancelDeleteJobFn   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewDeleteJobService returns a mock DeleteJobService where its methods will
+// return zero values.
+func NewDeleteJobService() *DeleteJobService {
+	return &DeleteJobService{
+		StartDeleteJobFn: func(context.Context, influxdb.DeleteJobRequest) (*influxdb.DeleteJob, error) { return nil, nil },
+		FindDeleteJobsFn: func(context.Context, influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+			return nil, nil
+		},
+		FindDeleteJobByIDFn: func(context.Context, influxdb.ID) (*influxdb.DeleteJob, error) { return nil, nil },
+		CancelDeleteJobFn:   func(context.Context, influxdb.ID) error { return nil },
+	}
+}
+
+// StartDeleteJob calls StartDeleteJobFn.
+func (s *DeleteJobService) StartDeleteJob(ctx context.Context, req influxdb.DeleteJobRequest) (*influxdb.DeleteJob, error) {
+	return s.StartDeleteJobFn(ctx, req)
+}
+
+// FindDeleteJobs calls FindDeleteJobsFn.
+func (s *DeleteJobService) FindDeleteJobs(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+	return s.FindDeleteJobsFn(ctx, filter)
+}
+
+// FindDeleteJobByID calls FindDeleteJobByIDFn.
+func (s *DeleteJobService) FindDeleteJobByID(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+	return s.FindDeleteJobByIDFn(ctx, id)
+}
+
+// CancelDeleteJob calls CancelDeleteJobFn.
+func (s *DeleteJobService) CancelDeleteJob(ctx context.Context, id influxdb.ID) error {
+	return s.CancelDeleteJobFn(ctx, id)
+}
@@ -13,7 +13,14 @@ type StorageReader struct {
 	ReadTagKeysFn         func(ctx context.Context, spec query.ReadTagKeysSpec, alloc *memory.Allocator) (query.TableIterator, error)
 	ReadTagValuesFn       func(ctx context.Context, spec query.ReadTagValuesSpec, alloc *memory.Allocator) (query.TableIterator, error)
 	ReadWindowAggregateFn func(ctx context.Context, spec query.ReadWindowAggregateSpec, alloc *memory.Allocator) (query.TableIterator, error)
-	CloseFn               func()
+
+	ReadMeasurementNamesFn     func(ctx context.Context, spec query.ReadMeasurementNamesSpec, alloc *memory.Allocator) (query.TableIterator, error)
+	ReadMeasurementTagKeysFn   func(ctx context.Context, spec query.ReadMeasurementTagKeysSpec, alloc *memory.Allocator) (query.TableIterator, error)
+	ReadMeasurementTagValuesFn func(ctx context.Context, spec query.ReadMeasurementTagValuesSpec, alloc *memory.Allocator) (query.TableIterator, error)
+	ReadMeasurementFieldsFn    func(ctx context.Context, spec query.ReadMeasurementFieldsSpec, alloc *memory.Allocator) (query.TableIterator, error)
+	ReadSeriesCardinalityFn    func(ctx context.Context, spec query.ReadSeriesCardinalitySpec, alloc *memory.Allocator) (query.TableIterator, error)
+
+	CloseFn func()
 }
 
 func (s *StorageReader) ReadFilter(ctx context.Context, spec query.ReadFilterSpec, alloc *memory.Allocator) (query.TableIterator, error) {
@@ -44,3 +51,23 @@ func (s *StorageReader) Close() {
 func (s *StorageReader) ReadWindowAggregate(ctx context.Context, spec query.ReadWindowAggregateSpec, alloc *memory.Allocator) (query.TableIterator, error) {
 	return s.ReadWindowAggregateFn(ctx, spec, alloc)
 }
+
+func (s *StorageReader) ReadMeasurementNames(ctx context.Context, spec query.ReadMeasurementNamesSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return s.ReadMeasurementNamesFn(ctx, spec, alloc)
+}
+
+func (s *StorageReader) ReadMeasurementTagKeys(ctx context.Context, spec query.ReadMeasurementTagKeysSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return s.ReadMeasurementTagKeysFn(ctx, spec, alloc)
+}
+
+func (s *StorageReader) ReadMeasurementTagValues(ctx context.Context, spec query.ReadMeasurementTagValuesSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return s.ReadMeasurementTagValuesFn(ctx, spec, alloc)
+}
+
+func (s *StorageReader) ReadMeasurementFields(ctx context.Context, spec query.ReadMeasurementFieldsSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return s.ReadMeasurementFieldsFn(ctx, spec, alloc)
+}
+
+func (s *StorageReader) ReadSeriesCardinality(ctx context.Context, spec query.ReadSeriesCardinalitySpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return s.ReadSeriesCardinalityFn(ctx, spec, alloc)
+}
@@ -0,0 +1,34 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.RunningQueryService = &RunningQueryService{}
+
+// RunningQueryService is a mock implementation of influxdb.RunningQueryService.
+type RunningQueryService struct {
+	FindRunningQueriesFn func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error)
+	KillQueryFn          func(ctx context.Context, id uint64) error
+}
+
+// NewRunningQueryService returns a mock RunningQueryService where its methods will return
+// zero values.
+func NewRunningQueryService() *RunningQueryService {
+	return &RunningQueryService{
+		FindRunningQueriesFn: func(context.Context, influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) { return nil, nil },
+		KillQueryFn:          func(context.Context, uint64) error { return nil },
+	}
+}
+
+// FindRunningQueries calls FindRunningQueriesFn.
+func (s *RunningQueryService) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	return s.FindRunningQueriesFn(ctx, filter)
+}
+
+// KillQuery calls KillQueryFn.
+func (s *RunningQueryService) KillQuery(ctx context.Context, id uint64) error {
+	return s.KillQueryFn(ctx, id)
+}
@@ -0,0 +1,41 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.ShardService = &ShardService{}
+
+// ShardService is a mock implementation of influxdb.ShardService.
+type ShardService struct {
+	FindShardGroupsFn   func(ctx context.Context, filter influxdb.ShardGroupFilter) ([]*influxdb.ShardGroup, error)
+	DeleteShardFn       func(ctx context.Context, shardID uint64) error
+	RebuildShardIndexFn func(ctx context.Context, shardID uint64) error
+}
+
+// NewShardService returns a mock ShardService where its methods will return
+// zero values.
+func NewShardService() *ShardService {
+	return &ShardService{
+		FindShardGroupsFn:   func(context.Context, influxdb.ShardGroupFilter) ([]*influxdb.ShardGroup, error) { return nil, nil },
+		DeleteShardFn:       func(context.Context, uint64) error { return nil },
+		RebuildShardIndexFn: func(context.Context, uint64) error { return nil },
+	}
+}
+
+// FindShardGroups calls FindShardGroupsFn.
+func (s *ShardService) FindShardGroups(ctx context.Context, filter influxdb.ShardGroupFilter) ([]*influxdb.ShardGroup, error) {
+	return s.FindShardGroupsFn(ctx, filter)
+}
+
+// DeleteShard calls DeleteShardFn.
+func (s *ShardService) DeleteShard(ctx context.Context, shardID uint64) error {
+	return s.DeleteShardFn(ctx, shardID)
+}
+
+// RebuildShardIndex calls RebuildShardIndexFn.
+func (s *ShardService) RebuildShardIndex(ctx context.Context, shardID uint64) error {
+	return s.RebuildShardIndexFn(ctx, shardID)
+}
@@ -0,0 +1,52 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.StorageVerifyService = &StorageVerifyService{}
+
+// StorageVerifyService is a mock implementation of influxdb.StorageVerifyService.
+type StorageVerifyService struct {
+	VerifyStorageFn               func(ctx context.Context, req influxdb.StorageVerificationRequest) (*influxdb.StorageVerification, error)
+	FindStorageVerificationsFn    func(ctx context.Context, filter influxdb.StorageVerificationFilter) ([]*influxdb.StorageVerification, error)
+	FindStorageVerificationByIDFn func(ctx context.Context, id influxdb.ID) (*influxdb.StorageVerification, error)
+	CancelStorageVerificationFn   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewStorageVerifyService returns a mock StorageVerifyService where its methods
+// will return zero values.
+func NewStorageVerifyService() *StorageVerifyService {
+	return &StorageVerifyService{
+		VerifyStorageFn: func(context.Context, influxdb.StorageVerificationRequest) (*influxdb.StorageVerification, error) {
+			return nil, nil
+		},
+		FindStorageVerificationsFn: func(context.Context, influxdb.StorageVerificationFilter) ([]*influxdb.StorageVerification, error) {
+			return nil, nil
+		},
+		FindStorageVerificationByIDFn: func(context.Context, influxdb.ID) (*influxdb.StorageVerification, error) { return nil, nil },
+		CancelStorageVerificationFn:   func(context.Context, influxdb.ID) error { return nil },
+	}
+}
+
+// VerifyStorage calls VerifyStorageFn.
+func (s *StorageVerifyService) VerifyStorage(ctx context.Context, req influxdb.StorageVerificationRequest) (*influxdb.StorageVerification, error) {
+	return s.VerifyStorageFn(ctx, req)
+}
+
+// FindStorageVerifications calls FindStorageVerificationsFn.
+func (s *StorageVerifyService) FindStorageVerifications(ctx context.Context, filter influxdb.StorageVerificationFilter) ([]*influxdb.StorageVerification, error) {
+	return s.FindStorageVerificationsFn(ctx, filter)
+}
+
+// FindStorageVerificationByID calls FindStorageVerificationByIDFn.
+func (s *StorageVerifyService) FindStorageVerificationByID(ctx context.Context, id influxdb.ID) (*influxdb.StorageVerification, error) {
+	return s.FindStorageVerificationByIDFn(ctx, id)
+}
+
+// CancelStorageVerification calls CancelStorageVerificationFn.
+func (s *StorageVerifyService) CancelStorageVerification(ctx context.Context, id influxdb.ID) error {
+	return s.CancelStorageVerificationFn(ctx, id)
+}
@@ -0,0 +1,50 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.SubscriptionService = &SubscriptionService{}
+
+// SubscriptionService is a mock implementation of influxdb.SubscriptionService.
+type SubscriptionService struct {
+	FindSubscriptionByIDFn func(ctx context.Context, id influxdb.ID) (*influxdb.Subscription, error)
+	FindSubscriptionsFn    func(ctx context.Context, filter influxdb.SubscriptionFilter) ([]*influxdb.Subscription, int, error)
+	CreateSubscriptionFn   func(ctx context.Context, s *influxdb.Subscription) error
+	DeleteSubscriptionFn   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewSubscriptionService returns a mock SubscriptionService where its methods will return
+// zero values.
+func NewSubscriptionService() *SubscriptionService {
+	return &SubscriptionService{
+		FindSubscriptionByIDFn: func(context.Context, influxdb.ID) (*influxdb.Subscription, error) { return nil, nil },
+		FindSubscriptionsFn: func(context.Context, influxdb.SubscriptionFilter) ([]*influxdb.Subscription, int, error) {
+			return nil, 0, nil
+		},
+		CreateSubscriptionFn: func(context.Context, *influxdb.Subscription) error { return nil },
+		DeleteSubscriptionFn: func(context.Context, influxdb.ID) error { return nil },
+	}
+}
+
+// FindSubscriptionByID calls FindSubscriptionByIDFn.
+func (s *SubscriptionService) FindSubscriptionByID(ctx context.Context, id influxdb.ID) (*influxdb.Subscription, error) {
+	return s.FindSubscriptionByIDFn(ctx, id)
+}
+
+// FindSubscriptions calls FindSubscriptionsFn.
+func (s *SubscriptionService) FindSubscriptions(ctx context.Context, filter influxdb.SubscriptionFilter) ([]*influxdb.Subscription, int, error) {
+	return s.FindSubscriptionsFn(ctx, filter)
+}
+
+// CreateSubscription calls CreateSubscriptionFn.
+func (s *SubscriptionService) CreateSubscription(ctx context.Context, sub *influxdb.Subscription) error {
+	return s.CreateSubscriptionFn(ctx, sub)
+}
+
+// DeleteSubscription calls DeleteSubscriptionFn.
+func (s *SubscriptionService) DeleteSubscription(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteSubscriptionFn(ctx, id)
+}
@@ -0,0 +1,250 @@
+// Package encrypt provides symmetric encryption of streams with AES-256-GCM.
+//
+// Streams are encrypted in segments so that large files can be encrypted and
+// decrypted without being buffered in memory. A stream starts with a header
+// holding a random nonce prefix, followed by the sealed segments. Every
+// segment but the last holds SegmentSize bytes of plaintext. The last segment
+// is marked as such in its additional data, so that a truncated stream fails
+// to decrypt.
+package encrypt
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KeySize is the size of the keys, in bytes.
+	KeySize = 32
+
+	// SaltSize is the size of the salts of keys, in bytes.
+	SaltSize = 16
+
+	// SegmentSize is the size of the plaintext of the segments of a stream, in bytes.
+	SegmentSize = 64 * 1024
+
+	noncePrefixSize = 8
+	tagSize         = 16
+)
+
+var magic = [4]byte{'I', 'X', 'E', '1'}
+
+var (
+	// ErrInvalidHeader is returned when a stream does not start with the
+	// header of an encrypted stream.
+	ErrInvalidHeader = errors.New("encrypt: not an encrypted stream")
+
+	// ErrAuthentication is returned when a stream fails to decrypt, because
+	// of a wrong key or of corrupted or truncated data.
+	ErrAuthentication = errors.New("encrypt: message authentication failed; wrong key or corrupted data")
+
+	errTooLarge = errors.New("encrypt: stream too large")
+)
+
+// NewSalt returns a random salt to derive a key with.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// DeriveKey derives a key from a secret, such as a passphrase or the content
+// of a key file, and a salt with scrypt.
+func DeriveKey(secret, salt []byte) ([]byte, error) {
+	return scrypt.Key(secret, salt, 1<<15, 8, 1, KeySize)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// segment seals and opens the segments of a stream.
+type segment struct {
+	aead    cipher.AEAD
+	nonce   [12]byte
+	counter uint32
+	done    bool
+}
+
+// next sets the nonce of the next segment and returns its additional data.
+func (s *segment) next(last bool) ([]byte, error) {
+	if s.done {
+		return nil, errTooLarge
+	}
+	binary.BigEndian.PutUint32(s.nonce[noncePrefixSize:], s.counter)
+	s.counter++
+	s.done = s.counter == 0
+	if last {
+		return []byte{1}, nil
+	}
+	return []byte{0}, nil
+}
+
+// Writer encrypts the data written to it.
+type Writer struct {
+	w   io.Writer
+	seg segment
+	buf []byte
+	out []byte
+	err error
+}
+
+// NewWriter returns a writer encrypting to w with key. The header of the
+// stream is written to w right away. Close must be called to write the last
+// segment of the stream.
+func NewWriter(w io.Writer, key []byte) (*Writer, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ew := &Writer{
+		w:   w,
+		seg: segment{aead: aead},
+		buf: make([]byte, 0, SegmentSize),
+	}
+	if _, err := rand.Read(ew.seg.nonce[:noncePrefixSize]); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(magic[:]); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(ew.seg.nonce[:noncePrefixSize]); err != nil {
+		return nil, err
+	}
+	return ew, nil
+}
+
+// Write encrypts p to the underlying writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	var n int
+	for len(p) > 0 {
+		// A full segment is only written once more data follows it, since
+		// the last segment of the stream may be a full one.
+		if len(w.buf) == SegmentSize {
+			if w.err = w.flush(false); w.err != nil {
+				return n, w.err
+			}
+		}
+		m := copy(w.buf[len(w.buf):SegmentSize], p)
+		w.buf = w.buf[:len(w.buf)+m]
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+func (w *Writer) flush(last bool) error {
+	ad, err := w.seg.next(last)
+	if err != nil {
+		return err
+	}
+	w.out = w.seg.aead.Seal(w.out[:0], w.seg.nonce[:], w.buf, ad)
+	w.buf = w.buf[:0]
+	_, err = w.w.Write(w.out)
+	return err
+}
+
+// Close writes the last segment of the stream. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.flush(true)
+	if w.err != nil {
+		return w.err
+	}
+	w.err = errors.New("encrypt: write to closed writer")
+	return nil
+}
+
+// Reader decrypts the data read from an encrypted stream.
+type Reader struct {
+	r   *bufio.Reader
+	seg segment
+	in  []byte
+	buf []byte
+	eof bool
+}
+
+// NewReader returns a reader decrypting the stream read from r with key.
+// It reads the header of the stream right away.
+func NewReader(r io.Reader, key []byte) (*Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var header [len(magic) + noncePrefixSize]byte
+	if _, err := io.ReadFull(r, header[:]); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrInvalidHeader
+	} else if err != nil {
+		return nil, err
+	} else if string(header[:len(magic)]) != string(magic[:]) {
+		return nil, ErrInvalidHeader
+	}
+
+	er := &Reader{
+		r:   bufio.NewReaderSize(r, SegmentSize+tagSize),
+		seg: segment{aead: aead},
+		in:  make([]byte, SegmentSize+tagSize),
+	}
+	copy(er.seg.nonce[:noncePrefixSize], header[len(magic):])
+	return er, nil
+}
+
+// Read reads decrypted data into p.
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// next reads and decrypts the next segment of the stream.
+func (r *Reader) next() error {
+	n, err := io.ReadFull(r.r, r.in)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		r.eof = true
+	} else if err != nil {
+		return err
+	} else if _, err := r.r.Peek(1); err == io.EOF {
+		r.eof = true
+	} else if err != nil {
+		return err
+	}
+
+	ad, err := r.seg.next(r.eof)
+	if err != nil {
+		return err
+	}
+	if r.buf, err = r.seg.aead.Open(r.in[:0], r.seg.nonce[:], r.in[:n], ad); err != nil {
+		return ErrAuthentication
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package encrypt_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/pkg/encrypt"
+)
+
+func mustKey(t *testing.T, secret string) []byte {
+	t.Helper()
+	salt, err := encrypt.NewSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := encrypt.DeriveKey([]byte(secret), salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func encryptBytes(t *testing.T, key, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := encrypt.NewWriter(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Write in uneven chunks to cross segment boundaries.
+	for len(data) > 0 {
+		n := 1000
+		if n > len(data) {
+			n = len(data)
+		}
+		if _, err := w.Write(data[:n]); err != nil {
+			t.Fatal(err)
+		}
+		data = data[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decryptBytes(key, data []byte) ([]byte, error) {
+	r, err := encrypt.NewReader(bytes.NewReader(data), key)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestReadWrite(t *testing.T) {
+	key := mustKey(t, "passphrase")
+	for _, size := range []int{0, 1, encrypt.SegmentSize - 1, encrypt.SegmentSize, encrypt.SegmentSize + 1, 3*encrypt.SegmentSize + 17} {
+		data := make([]byte, size)
+		rand.Read(data)
+
+		enc := encryptBytes(t, key, data)
+		// Short plaintexts may appear in the ciphertext by chance.
+		if size >= 16 && bytes.Contains(enc, data) {
+			t.Fatalf("size %d: encrypted stream contains plaintext", size)
+		}
+
+		got, err := decryptBytes(key, enc)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		} else if !bytes.Equal(got, data) {
+			t.Fatalf("size %d: decrypted data mismatch", size)
+		}
+	}
+}
+
+func TestReader_WrongKey(t *testing.T) {
+	enc := encryptBytes(t, mustKey(t, "passphrase"), []byte("hello"))
+	if _, err := decryptBytes(mustKey(t, "other"), enc); err != encrypt.ErrAuthentication {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReader_Tampered(t *testing.T) {
+	key := mustKey(t, "passphrase")
+	data := make([]byte, 2*encrypt.SegmentSize+10)
+	enc := encryptBytes(t, key, data)
+
+	// Flipped bit.
+	flipped := append([]byte(nil), enc...)
+	flipped[len(flipped)/2] ^= 1
+	if _, err := decryptBytes(key, flipped); err != encrypt.ErrAuthentication {
+		t.Fatalf("flipped: unexpected error: %v", err)
+	}
+
+	// Truncated at a segment boundary: the previous segment is not the last one.
+	truncated := enc[:len(enc)-(10+16)]
+	if _, err := decryptBytes(key, truncated); err != encrypt.ErrAuthentication {
+		t.Fatalf("truncated: unexpected error: %v", err)
+	}
+}
+
+func TestNewReader_InvalidHeader(t *testing.T) {
+	key := mustKey(t, "passphrase")
+	for _, data := range [][]byte{nil, []byte("IX"), []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff")} {
+		if _, err := decryptBytes(key, data); err != encrypt.ErrInvalidHeader {
+			t.Fatalf("%q: unexpected error: %v", data, err)
+		}
+	}
+}
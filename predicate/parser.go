@@ -2,6 +2,7 @@ package predicate
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/influxdata/influxdb/v2"
@@ -20,12 +21,20 @@ type buffer [3]struct {
 // to the predicate node
 type parser struct {
 	sc        *influxql.Scanner
+	stmt      string
 	i         int // buffer index
 	n         int // buffer size
 	openParen int
 	buf       buffer
 }
 
+func newParser(stmt string) *parser {
+	return &parser{
+		sc:   influxql.NewScanner(strings.NewReader(stmt)),
+		stmt: stmt,
+	}
+}
+
 // scan returns the next token from the underlying scanner.
 // If a token has been unscanned then read that instead.
 func (p *parser) scan() (tok influxql.Token, pos influxql.Pos, lit string) {
@@ -63,89 +72,52 @@ func (p *parser) scanIgnoreWhitespace() (tok influxql.Token, pos influxql.Pos, l
 }
 
 // Parse the predicate statement.
+// AND has precedence over OR, and both are left associative.
 func Parse(sts string) (n Node, err error) {
 	if sts == "" {
 		return nil, nil
 	}
-	p := new(parser)
-	p.sc = influxql.NewScanner(strings.NewReader(sts))
-	return p.parseLogicalNode()
+	return newParser(sts).parseLogicalNode()
 }
 
+// parseLogicalNode parses the logical expression up to the end of the
+// statement, or up to the closing parenthesis of a parenthesized expression.
 func (p *parser) parseLogicalNode() (Node, error) {
-	n := new(LogicalNode)
+	n, err := p.parseAndNode()
+	if err != nil {
+		return nil, err
+	}
 	for {
 		tok, pos, _ := p.scanIgnoreWhitespace()
 		switch tok {
-		case influxql.NUMBER, influxql.INTEGER, influxql.NAME, influxql.IDENT:
-			p.unscan()
-			tr, err := p.parseTagRuleNode()
-			if err != nil {
-				return *n, err
-			}
-			if n.Children[0] == nil {
-				n.Children[0] = tr
-			} else {
-				n.Children[1] = tr
-			}
-		case influxql.AND:
-			n.Operator = LogicalAnd
-			if n.Children[1] == nil {
-				continue
-			}
-			var n1 Node
-			var err error
-			if tokNext := p.peekTok(); tokNext == influxql.LPAREN {
-				n1, err = p.parseLogicalNode()
-			} else {
-				n1, err = p.parseTagRuleNode()
-			}
-			if err != nil {
-				return *n, err
-			}
-			n = &LogicalNode{
-				Children: [2]Node{*n, n1},
-				Operator: LogicalAnd,
-			}
 		case influxql.OR:
-			return *n, &influxdb.Error{
-				Code: influxdb.EInvalid,
-				Msg:  fmt.Sprintf("the logical operator OR is not supported yet at position %d", pos.Char),
-			}
-		case influxql.LPAREN:
-			p.openParen++
-			currParen := p.openParen
-			n1, err := p.parseLogicalNode()
+			n1, err := p.parseAndNode()
 			if err != nil {
-				return *n, err
+				return nil, err
 			}
-			if p.openParen != currParen-1 {
-				return *n, &influxdb.Error{
-					Code: influxdb.EInvalid,
-					Msg:  "extra ( seen",
-				}
-			}
-			if n.Children[0] == nil {
-				n.Children[0] = n1
-			} else {
-				n.Children[1] = n1
+			n = LogicalNode{
+				Children: [2]Node{n, n1},
+				Operator: LogicalOr,
 			}
 		case influxql.RPAREN:
 			p.openParen--
-			fallthrough
-		case influxql.EOF:
 			if p.openParen < 0 {
-				return *n, &influxdb.Error{
+				return nil, &influxdb.Error{
 					Code: influxdb.EInvalid,
 					Msg:  "extra ) seen",
 				}
 			}
-			if n.Children[1] == nil {
-				return n.Children[0], nil
+			return n, nil
+		case influxql.EOF:
+			if p.openParen > 0 {
+				return nil, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "extra ( seen",
+				}
 			}
-			return *n, nil
+			return n, nil
 		default:
-			return *n, &influxdb.Error{
+			return nil, &influxdb.Error{
 				Code: influxdb.EInvalid,
 				Msg:  fmt.Sprintf("bad logical expression, at position %d", pos.Char),
 			}
@@ -153,6 +125,52 @@ func (p *parser) parseLogicalNode() (Node, error) {
 	}
 }
 
+// parseAndNode parses the operands joined by AND.
+func (p *parser) parseAndNode() (Node, error) {
+	n, err := p.parseOperandNode()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekTok() == influxql.AND {
+		p.scanIgnoreWhitespace()
+		n1, err := p.parseOperandNode()
+		if err != nil {
+			return nil, err
+		}
+		n = LogicalNode{
+			Children: [2]Node{n, n1},
+			Operator: LogicalAnd,
+		}
+	}
+	return n, nil
+}
+
+// parseOperandNode parses a tag rule or a parenthesized logical expression.
+func (p *parser) parseOperandNode() (Node, error) {
+	tok, pos, _ := p.scanIgnoreWhitespace()
+	switch tok {
+	case influxql.NUMBER, influxql.INTEGER, influxql.NAME, influxql.IDENT:
+		p.unscan()
+		return p.parseTagRuleNode()
+	case influxql.LPAREN:
+		p.openParen++
+		return p.parseLogicalNode()
+	case influxql.EOF:
+		if p.openParen > 0 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "extra ( seen",
+			}
+		}
+		fallthrough
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("bad logical expression, at position %d", pos.Char),
+		}
+	}
+}
+
 func (p *parser) parseTagRuleNode() (TagRuleNode, error) {
 	n := new(TagRuleNode)
 	// scan the key
@@ -178,12 +196,11 @@ func (p *parser) parseTagRuleNode() (TagRuleNode, error) {
 		n.Operator = influxdb.NotEqual
 		goto scanRegularTagValue
 	case influxql.EQREGEX:
-		fallthrough
+		n.Operator = influxdb.RegexEqual
+		goto scanRegexTagValue
 	case influxql.NEQREGEX:
-		return *n, &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Msg:  fmt.Sprintf("operator: %q at position: %d is not supported yet", tok.String(), pos.Char),
-		}
+		n.Operator = influxdb.NotRegexEqual
+		goto scanRegexTagValue
 	default:
 		return *n, &influxdb.Error{
 			Code: influxdb.EInvalid,
@@ -218,8 +235,56 @@ scanRegularTagValue:
 			Msg:  fmt.Sprintf("bad tag value: %q, at position %d", lit, pos.Char),
 		}
 	}
+
+	// scan the regex value
+scanRegexTagValue:
+	tok, pos, lit = p.scanRegex(pos)
+	// pos is the position of the rune preceding the regex
+	pos.Char++
+	if tok != influxql.REGEX {
+		return *n, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("bad regex tag value, at position %d", pos.Char),
+		}
+	}
+	if _, err := regexp.Compile(lit); err != nil {
+		return *n, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("bad regex tag value: %q, at position %d", lit, pos.Char),
+			Err:  err,
+		}
+	}
+	n.Value = lit
+	return *n, nil
 }
 
+// scanRegex scans the regex following the regex operator at pos.
+// The scanner only scans a regex starting at its current position,
+// so the whitespace following the operator is skipped first.
+func (p *parser) scanRegex(pos influxql.Pos) (influxql.Token, influxql.Pos, string) {
+	if isWhitespace(p.runeAt(pos.Line, pos.Char+2)) {
+		p.sc.Scan()
+	}
+	return p.sc.ScanRegex()
+}
+
+// runeAt returns the rune of the statement at the given line and char,
+// or 0 past the end of the statement.
+func (p *parser) runeAt(line, char int) rune {
+	lines := strings.Split(p.stmt, "\n")
+	if line >= len(lines) {
+		return 0
+	}
+	if runes := []rune(lines[line]); char < len(runes) {
+		return runes[char]
+	} else if line < len(lines)-1 {
+		return '\n'
+	}
+	return 0
+}
+
+func isWhitespace(ch rune) bool { return ch == ' ' || ch == '\t' || ch == '\n' }
+
 // peekRune returns the next rune that would be read by the scanner.
 func (p *parser) peekTok() influxql.Token {
 	tok, _, _ := p.scanIgnoreWhitespace()
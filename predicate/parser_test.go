@@ -1,13 +1,11 @@
 package predicate
 
 import (
-	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb/v2"
 	influxtesting "github.com/influxdata/influxdb/v2/testing"
-	"github.com/influxdata/influxql"
 )
 
 func TestParseNode(t *testing.T) {
@@ -39,9 +37,52 @@ func TestParseNode(t *testing.T) {
 		},
 		{
 			str: ` abc="opq" Or gender="male" OR temp=1123`,
+			node: LogicalNode{Operator: LogicalOr, Children: [2]Node{
+				LogicalNode{Operator: LogicalOr, Children: [2]Node{
+					TagRuleNode{Tag: influxdb.Tag{Key: "abc", Value: "opq"}},
+					TagRuleNode{Tag: influxdb.Tag{Key: "gender", Value: "male"}},
+				}},
+				TagRuleNode{Tag: influxdb.Tag{Key: "temp", Value: "1123"}},
+			}},
+		},
+		{
+			str: `a=1 or b=2 and c=3 or d=4`,
+			node: LogicalNode{Operator: LogicalOr, Children: [2]Node{
+				LogicalNode{Operator: LogicalOr, Children: [2]Node{
+					TagRuleNode{Tag: influxdb.Tag{Key: "a", Value: "1"}},
+					LogicalNode{Operator: LogicalAnd, Children: [2]Node{
+						TagRuleNode{Tag: influxdb.Tag{Key: "b", Value: "2"}},
+						TagRuleNode{Tag: influxdb.Tag{Key: "c", Value: "3"}},
+					}},
+				}},
+				TagRuleNode{Tag: influxdb.Tag{Key: "d", Value: "4"}},
+			}},
+		},
+		{
+			str: `_measurement="cpu" and (host != "a" or host =~ /^web/) and _field="usage"`,
+			node: LogicalNode{Operator: LogicalAnd, Children: [2]Node{
+				LogicalNode{Operator: LogicalAnd, Children: [2]Node{
+					TagRuleNode{Tag: influxdb.Tag{Key: "_measurement", Value: "cpu"}},
+					LogicalNode{Operator: LogicalOr, Children: [2]Node{
+						TagRuleNode{Tag: influxdb.Tag{Key: "host", Value: "a"}, Operator: influxdb.NotEqual},
+						TagRuleNode{Tag: influxdb.Tag{Key: "host", Value: "^web"}, Operator: influxdb.RegexEqual},
+					}},
+				}},
+				TagRuleNode{Tag: influxdb.Tag{Key: "_field", Value: "usage"}},
+			}},
+		},
+		{
+			str: `a=1 or`,
 			err: &influxdb.Error{
 				Code: influxdb.EInvalid,
-				Msg:  "the logical operator OR is not supported yet at position 11",
+				Msg:  "bad logical expression, at position 7",
+			},
+		},
+		{
+			str: `a=1 b=2`,
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "bad logical expression, at position 4",
 			},
 		},
 		{
@@ -132,17 +173,29 @@ func TestParseTagRule(t *testing.T) {
 			node: TagRuleNode{Tag: influxdb.Tag{Key: "abc", Value: "false"}, Operator: influxdb.Equal},
 		},
 		{
-			str: `abc!~/^payments\./`,
+			str:  `abc!~/^payments\./`,
+			node: TagRuleNode{Tag: influxdb.Tag{Key: "abc", Value: `^payments\.`}, Operator: influxdb.NotRegexEqual},
+		},
+		{
+			str:  `abc=~/^payments\./`,
+			node: TagRuleNode{Tag: influxdb.Tag{Key: "abc", Value: `^payments\.`}, Operator: influxdb.RegexEqual},
+		},
+		{
+			str:  `abc =~   /a\/b/`,
+			node: TagRuleNode{Tag: influxdb.Tag{Key: "abc", Value: `a/b`}, Operator: influxdb.RegexEqual},
+		},
+		{
+			str: `abc=~"opq"`,
 			err: &influxdb.Error{
 				Code: influxdb.EInvalid,
-				Msg:  `operator: "!~" at position: 3 is not supported yet`,
+				Msg:  `bad regex tag value, at position 5`,
 			},
 		},
 		{
-			str: `abc=~/^payments\./`,
+			str: `abc=~/(/`,
 			err: &influxdb.Error{
 				Code: influxdb.EInvalid,
-				Msg:  `operator: "=~" at position: 3 is not supported yet`,
+				Msg:  `bad regex tag value: "(", at position 5`,
 			},
 		},
 		{
@@ -154,9 +207,7 @@ func TestParseTagRule(t *testing.T) {
 		},
 	}
 	for _, c := range cases {
-		p := new(parser)
-		p.sc = influxql.NewScanner(strings.NewReader(c.str))
-		tr, err := p.parseTagRuleNode()
+		tr, err := newParser(c.str).parseTagRuleNode()
 		influxtesting.ErrorsEqual(t, err, c.err)
 		if c.err == nil {
 			if diff := cmp.Diff(tr, c.node); diff != "" {
@@ -46,12 +46,9 @@ func NodeComparison(op influxdb.Operator) (datatypes.Node_Comparison, error) {
 	case influxdb.NotEqual:
 		return datatypes.ComparisonNotEqual, nil
 	case influxdb.RegexEqual:
-		fallthrough
+		return datatypes.ComparisonRegex, nil
 	case influxdb.NotRegexEqual:
-		return 0, &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Msg:  fmt.Sprintf("Operator %s is not supported for delete predicate yet", op),
-		}
+		return datatypes.ComparisonNotRegex, nil
 	default:
 		return 0, &influxdb.Error{
 			Code: influxdb.EInvalid,
@@ -37,6 +37,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	influxlogger "github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/registry"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -66,6 +67,7 @@ type Controller struct {
 	log *zap.Logger
 
 	dependencies []flux.Dependency
+	registry     *registry.Registry
 }
 
 type Config struct {
@@ -115,6 +117,10 @@ type Config struct {
 	MetricLabelKeys []string
 
 	ExecutorDependencies []flux.Dependency
+
+	// Registry tracks the running queries so they can be listed and killed.
+	// It is optional.
+	Registry *registry.Registry
 }
 
 // complete will fill in the defaults, validate the configuration, and
@@ -199,6 +205,7 @@ func New(config Config) (*Controller, error) {
 		metrics:      newControllerMetrics(c.MetricLabelKeys),
 		labelKeys:    c.MetricLabelKeys,
 		dependencies: c.ExecutorDependencies,
+		registry:     c.Registry,
 	}
 	quota := int(c.ConcurrencyQuota)
 	ctrl.wg.Add(quota)
@@ -243,6 +250,7 @@ func (c *Controller) query(ctx context.Context, compiler flux.Compiler) (flux.Qu
 	if err != nil {
 		return nil, handleFluxError(err)
 	}
+	c.registerQuery(ctx, q, compiler)
 
 	if err := c.compileQuery(q, compiler); err != nil {
 		q.setErr(err)
@@ -322,6 +330,36 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 	return q, nil
 }
 
+// registerQuery adds the query to the registry of running queries, if any.
+// The query is removed from the registry when it finishes.
+func (c *Controller) registerQuery(ctx context.Context, q *Query, compiler flux.Compiler) {
+	if c.registry == nil {
+		return
+	}
+
+	rq := influxdb.RunningQuery{
+		Language: influxdb.RunningQueryLanguageFlux,
+		Query:    string(compiler.CompilerType()),
+	}
+	switch compiler := compiler.(type) {
+	case lang.FluxCompiler:
+		rq.Query = compiler.Query
+	case *lang.FluxCompiler:
+		rq.Query = compiler.Query
+	}
+	if req := query.RequestFromContext(ctx); req != nil {
+		rq.OrgID = req.OrganizationID
+		if req.Authorization != nil {
+			rq.UserID = req.Authorization.GetUserID()
+			rq.AuthorizationID = req.Authorization.ID
+		}
+	}
+
+	_, q.unregister = c.registry.Register(rq, func() string {
+		return q.State().String()
+	}, q.Cancel)
+}
+
 func (c *Controller) nextID() QueryID {
 	nextID := atomic.AddUint64(&c.lastID, 1)
 	return QueryID(nextID)
@@ -462,6 +500,10 @@ func (c *Controller) waitForQuery(q *Query) {
 }
 
 func (c *Controller) finish(q *Query) {
+	if q.unregister != nil {
+		q.unregister()
+	}
+
 	c.queriesMu.Lock()
 	delete(c.queries, q.id)
 	if len(c.queries) == 0 && c.shutdown {
@@ -567,6 +609,9 @@ type Query struct {
 
 	memoryManager *queryMemoryManager
 	alloc         *memory.Allocator
+
+	// unregister removes the query from the registry of running queries.
+	unregister func()
 }
 
 func (q *Query) ProfilerResults() (flux.ResultIterator, error) {
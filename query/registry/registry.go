@@ -0,0 +1,107 @@
+// Package registry keeps track of the Flux and InfluxQL queries running on
+// the server so they can be listed and killed through a single ID space.
+package registry
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.RunningQueryService = (*Registry)(nil)
+
+// Registry is a registry of running queries.
+type Registry struct {
+	mu      sync.RWMutex
+	lastID  uint64
+	queries map[uint64]*entry
+
+	now func() time.Time
+}
+
+type entry struct {
+	query  influxdb.RunningQuery
+	state  func() string
+	cancel func()
+}
+
+// New returns a new, empty Registry.
+func New() *Registry {
+	return &Registry{
+		queries: make(map[uint64]*entry),
+		now:     time.Now,
+	}
+}
+
+// Register adds a query to the registry and returns the ID assigned to it.
+// The state function reports the current state of the query and cancel
+// interrupts its execution. The returned function must be called once the
+// query has finished to remove it from the registry.
+func (r *Registry) Register(q influxdb.RunningQuery, state func() string, cancel func()) (uint64, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	q.ID = r.lastID
+	if q.StartTime.IsZero() {
+		q.StartTime = r.now()
+	}
+	r.queries[q.ID] = &entry{query: q, state: state, cancel: cancel}
+
+	var once sync.Once
+	return q.ID, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.queries, q.ID)
+			r.mu.Unlock()
+		})
+	}
+}
+
+// FindRunningQueries returns the registered queries matching filter, ordered by ID.
+func (r *Registry) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	r.mu.RLock()
+	entries := make([]*entry, 0, len(r.queries))
+	for _, e := range r.queries {
+		if filter.ID != nil && e.query.ID != *filter.ID {
+			continue
+		}
+		if filter.OrgID != nil && e.query.OrgID != *filter.OrgID {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	r.mu.RUnlock()
+
+	// The state of a query is read outside of the registry lock since
+	// reporting it may need to acquire locks held by the query itself.
+	now := r.now()
+	queries := make([]*influxdb.RunningQuery, 0, len(entries))
+	for _, e := range entries {
+		q := e.query
+		q.Duration = now.Sub(q.StartTime)
+		if e.state != nil {
+			q.State = e.state()
+		}
+		queries = append(queries, &q)
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].ID < queries[j].ID
+	})
+	return queries, nil
+}
+
+// KillQuery cancels the query with the given ID.
+func (r *Registry) KillQuery(ctx context.Context, id uint64) error {
+	r.mu.RLock()
+	e, ok := r.queries[id]
+	r.mu.RUnlock()
+	if !ok {
+		return influxdb.ErrRunningQueryNotFound
+	}
+	e.cancel()
+	return nil
+}
@@ -0,0 +1,77 @@
+package registry_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query/registry"
+)
+
+func TestRegistry(t *testing.T) {
+	r := registry.New()
+
+	var canceled []uint64
+	register := func(orgID influxdb.ID, language string) (uint64, func()) {
+		var id uint64
+		id, done := r.Register(influxdb.RunningQuery{
+			OrgID:    orgID,
+			Language: language,
+			Query:    language + " query",
+		}, func() string {
+			return "running"
+		}, func() {
+			canceled = append(canceled, id)
+		})
+		return id, done
+	}
+
+	id1, done1 := register(1, influxdb.RunningQueryLanguageInfluxQL)
+	id2, _ := register(1, influxdb.RunningQueryLanguageFlux)
+	id3, _ := register(2, influxdb.RunningQueryLanguageFlux)
+
+	find := func(filter influxdb.RunningQueryFilter) []*influxdb.RunningQuery {
+		t.Helper()
+		qs, err := r.FindRunningQueries(context.Background(), filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return qs
+	}
+	opts := cmpopts.IgnoreFields(influxdb.RunningQuery{}, "StartTime", "Duration")
+
+	orgID := influxdb.ID(1)
+	want := []*influxdb.RunningQuery{
+		{ID: id1, OrgID: 1, Language: "influxql", Query: "influxql query", State: "running"},
+		{ID: id2, OrgID: 1, Language: "flux", Query: "flux query", State: "running"},
+	}
+	if diff := cmp.Diff(want, find(influxdb.RunningQueryFilter{OrgID: &orgID}), opts); diff != "" {
+		t.Fatalf("unexpected queries -want/+got\n%s", diff)
+	}
+
+	want = []*influxdb.RunningQuery{
+		{ID: id3, OrgID: 2, Language: "flux", Query: "flux query", State: "running"},
+	}
+	if diff := cmp.Diff(want, find(influxdb.RunningQueryFilter{ID: &id3}), opts); diff != "" {
+		t.Fatalf("unexpected queries -want/+got\n%s", diff)
+	}
+
+	if err := r.KillQuery(context.Background(), id2); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]uint64{id2}, canceled); diff != "" {
+		t.Fatalf("unexpected canceled queries -want/+got\n%s", diff)
+	}
+
+	// Finished queries are removed from the registry.
+	done1()
+	done1()
+	if got := find(influxdb.RunningQueryFilter{}); len(got) != 2 {
+		t.Fatalf("unexpected number of queries: %d", len(got))
+	}
+	if err := r.KillQuery(context.Background(), id1); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
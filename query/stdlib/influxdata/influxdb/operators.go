@@ -7,6 +7,7 @@ import (
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/stdlib/universe"
 	"github.com/influxdata/flux/values"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
@@ -18,6 +19,12 @@ const (
 	ReadWindowAggregatePhysKind = "ReadWindowAggregatePhysKind"
 	ReadTagKeysPhysKind         = "ReadTagKeysPhysKind"
 	ReadTagValuesPhysKind       = "ReadTagValuesPhysKind"
+
+	ReadMeasurementNamesPhysKind     = "ReadMeasurementNamesPhysKind"
+	ReadMeasurementTagKeysPhysKind   = "ReadMeasurementTagKeysPhysKind"
+	ReadMeasurementTagValuesPhysKind = "ReadMeasurementTagValuesPhysKind"
+	ReadMeasurementFieldsPhysKind    = "ReadMeasurementFieldsPhysKind"
+	ReadSeriesCardinalityPhysKind    = "ReadSeriesCardinalityPhysKind"
 )
 
 type ReadGroupPhysSpec struct {
@@ -118,10 +125,18 @@ type ReadWindowAggregatePhysSpec struct {
 	Aggregates  []plan.ProcedureKind
 	CreateEmpty bool
 	TimeColumn  string
+
+	// Quantile and Compression are the arguments of the quantile aggregate.
+	Quantile    float64
+	Compression float64
 }
 
 func (s *ReadWindowAggregatePhysSpec) PlanDetails() string {
-	return fmt.Sprintf("every = %v, aggregates = %v, createEmpty = %v, timeColumn = \"%s\"", s.WindowEvery, s.Aggregates, s.CreateEmpty, s.TimeColumn)
+	details := fmt.Sprintf("every = %v, aggregates = %v, createEmpty = %v, timeColumn = \"%s\"", s.WindowEvery, s.Aggregates, s.CreateEmpty, s.TimeColumn)
+	if len(s.Aggregates) > 0 && s.Aggregates[0] == universe.QuantileKind {
+		details += fmt.Sprintf(", quantile = %v, compression = %v", s.Quantile, s.Compression)
+	}
+	return details
 }
 
 func (s *ReadWindowAggregatePhysSpec) Kind() plan.ProcedureKind {
@@ -137,6 +152,8 @@ func (s *ReadWindowAggregatePhysSpec) Copy() plan.ProcedureSpec {
 	ns.Aggregates = s.Aggregates
 	ns.CreateEmpty = s.CreateEmpty
 	ns.TimeColumn = s.TimeColumn
+	ns.Quantile = s.Quantile
+	ns.Compression = s.Compression
 
 	return ns
 }
@@ -170,3 +187,91 @@ func (s *ReadTagValuesPhysSpec) Copy() plan.ProcedureSpec {
 	ns.TagKey = s.TagKey
 	return ns
 }
+
+// ReadMeasurementNamesPhysSpec reads the names of the measurements from the
+// index of the storage engine.
+type ReadMeasurementNamesPhysSpec struct {
+	ReadRangePhysSpec
+}
+
+func (s *ReadMeasurementNamesPhysSpec) Kind() plan.ProcedureKind {
+	return ReadMeasurementNamesPhysKind
+}
+
+func (s *ReadMeasurementNamesPhysSpec) Copy() plan.ProcedureSpec {
+	ns := new(ReadMeasurementNamesPhysSpec)
+	ns.ReadRangePhysSpec = *s.ReadRangePhysSpec.Copy().(*ReadRangePhysSpec)
+	return ns
+}
+
+// ReadMeasurementTagKeysPhysSpec reads the tag keys of a measurement from the
+// index of the storage engine.
+type ReadMeasurementTagKeysPhysSpec struct {
+	ReadRangePhysSpec
+	Measurement string
+}
+
+func (s *ReadMeasurementTagKeysPhysSpec) Kind() plan.ProcedureKind {
+	return ReadMeasurementTagKeysPhysKind
+}
+
+func (s *ReadMeasurementTagKeysPhysSpec) Copy() plan.ProcedureSpec {
+	ns := new(ReadMeasurementTagKeysPhysSpec)
+	ns.ReadRangePhysSpec = *s.ReadRangePhysSpec.Copy().(*ReadRangePhysSpec)
+	ns.Measurement = s.Measurement
+	return ns
+}
+
+// ReadMeasurementTagValuesPhysSpec reads the values of a tag key of a
+// measurement from the index of the storage engine.
+type ReadMeasurementTagValuesPhysSpec struct {
+	ReadRangePhysSpec
+	Measurement string
+	TagKey      string
+}
+
+func (s *ReadMeasurementTagValuesPhysSpec) Kind() plan.ProcedureKind {
+	return ReadMeasurementTagValuesPhysKind
+}
+
+func (s *ReadMeasurementTagValuesPhysSpec) Copy() plan.ProcedureSpec {
+	ns := new(ReadMeasurementTagValuesPhysSpec)
+	ns.ReadRangePhysSpec = *s.ReadRangePhysSpec.Copy().(*ReadRangePhysSpec)
+	ns.Measurement = s.Measurement
+	ns.TagKey = s.TagKey
+	return ns
+}
+
+// ReadMeasurementFieldsPhysSpec reads the field keys of a measurement, or of
+// all measurements if Measurement is empty.
+type ReadMeasurementFieldsPhysSpec struct {
+	ReadRangePhysSpec
+	Measurement string
+}
+
+func (s *ReadMeasurementFieldsPhysSpec) Kind() plan.ProcedureKind {
+	return ReadMeasurementFieldsPhysKind
+}
+
+func (s *ReadMeasurementFieldsPhysSpec) Copy() plan.ProcedureSpec {
+	ns := new(ReadMeasurementFieldsPhysSpec)
+	ns.ReadRangePhysSpec = *s.ReadRangePhysSpec.Copy().(*ReadRangePhysSpec)
+	ns.Measurement = s.Measurement
+	return ns
+}
+
+// ReadSeriesCardinalityPhysSpec reads the number of series with data in the
+// range, as a single table with an integer _value column.
+type ReadSeriesCardinalityPhysSpec struct {
+	ReadRangePhysSpec
+}
+
+func (s *ReadSeriesCardinalityPhysSpec) Kind() plan.ProcedureKind {
+	return ReadSeriesCardinalityPhysKind
+}
+
+func (s *ReadSeriesCardinalityPhysSpec) Copy() plan.ProcedureSpec {
+	ns := new(ReadSeriesCardinalityPhysSpec)
+	ns.ReadRangePhysSpec = *s.ReadRangePhysSpec.Copy().(*ReadRangePhysSpec)
+	return ns
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/influxdata/flux/stdlib/universe"
 	"github.com/influxdata/flux/values"
 	"github.com/influxdata/influxdb/v2/kit/feature"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
 )
 
 func init() {
@@ -25,10 +27,15 @@ func init() {
 		PushDownGroupRule{},
 		PushDownReadTagKeysRule{},
 		PushDownReadTagValuesRule{},
+		PushDownReadMeasurementNamesRule{},
+		PushDownReadMeasurementFieldsRule{},
+		PushDownReadMeasurementTagValuesRule{},
+		PushDownReadMeasurementTagKeysRule{},
 		SortedPivotRule{},
 		PushDownWindowAggregateRule{},
 		PushDownWindowAggregateByTimeRule{},
 		PushDownBareAggregateRule{},
+		PushDownReadSeriesCardinalityRule{},
 		GroupWindowAggregateTransposeRule{},
 		// PushDownGroupAggregateRule{},
 		SwitchFillImplRule{},
@@ -374,6 +381,174 @@ func isValidTagKeyForTagValues(key string) bool {
 	return true
 }
 
+// PushDownReadMeasurementNamesRule rewrites 'ReadTagValues' of the
+// _measurement column, as done by schema.measurements(), into
+// 'ReadMeasurementNames' so the names are read from the index.
+type PushDownReadMeasurementNamesRule struct{}
+
+func (rule PushDownReadMeasurementNamesRule) Name() string {
+	return "PushDownReadMeasurementNamesRule"
+}
+
+func (rule PushDownReadMeasurementNamesRule) Pattern() plan.Pattern {
+	return plan.Pat(ReadTagValuesPhysKind)
+}
+
+func (rule PushDownReadMeasurementNamesRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bool, error) {
+	tagValuesSpec := pn.ProcedureSpec().(*ReadTagValuesPhysSpec)
+	if tagValuesSpec.TagKey != datatypes.MeasurementKey {
+		return pn, false, nil
+	}
+
+	return plan.CreateUniquePhysicalNode(ctx, "ReadMeasurementNames", &ReadMeasurementNamesPhysSpec{
+		ReadRangePhysSpec: *tagValuesSpec.ReadRangePhysSpec.Copy().(*ReadRangePhysSpec),
+	}), true, nil
+}
+
+// PushDownReadMeasurementFieldsRule rewrites 'ReadTagValues' of the _field
+// column, as done by schema.fieldKeys() and schema.measurementFieldKeys(),
+// into 'ReadMeasurementFields' so the field keys are read from the index.
+// If the filter selects a single measurement, only the fields of
+// that measurement are read.
+type PushDownReadMeasurementFieldsRule struct{}
+
+func (rule PushDownReadMeasurementFieldsRule) Name() string {
+	return "PushDownReadMeasurementFieldsRule"
+}
+
+func (rule PushDownReadMeasurementFieldsRule) Pattern() plan.Pattern {
+	return plan.Pat(ReadTagValuesPhysKind)
+}
+
+func (rule PushDownReadMeasurementFieldsRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bool, error) {
+	tagValuesSpec := pn.ProcedureSpec().(*ReadTagValuesPhysSpec)
+	if tagValuesSpec.TagKey != datatypes.FieldKey {
+		return pn, false, nil
+	}
+
+	spec := &ReadMeasurementFieldsPhysSpec{
+		ReadRangePhysSpec: *tagValuesSpec.ReadRangePhysSpec.Copy().(*ReadRangePhysSpec),
+	}
+	spec.Measurement, spec.Filter = splitMeasurementPredicate(spec.Filter)
+	return plan.CreateUniquePhysicalNode(ctx, "ReadMeasurementFields", spec), true, nil
+}
+
+// PushDownReadMeasurementTagValuesRule rewrites 'ReadTagValues' whose filter
+// selects a single measurement, as done by schema.measurementTagValues(),
+// into 'ReadMeasurementTagValues'.
+type PushDownReadMeasurementTagValuesRule struct{}
+
+func (rule PushDownReadMeasurementTagValuesRule) Name() string {
+	return "PushDownReadMeasurementTagValuesRule"
+}
+
+func (rule PushDownReadMeasurementTagValuesRule) Pattern() plan.Pattern {
+	return plan.Pat(ReadTagValuesPhysKind)
+}
+
+func (rule PushDownReadMeasurementTagValuesRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bool, error) {
+	tagValuesSpec := pn.ProcedureSpec().(*ReadTagValuesPhysSpec)
+	if tagValuesSpec.TagKey == datatypes.MeasurementKey || tagValuesSpec.TagKey == datatypes.FieldKey {
+		// These are rewritten by the measurement names and fields rules.
+		return pn, false, nil
+	}
+
+	measurement, filter := splitMeasurementPredicate(tagValuesSpec.Filter)
+	if measurement == "" {
+		return pn, false, nil
+	}
+
+	spec := &ReadMeasurementTagValuesPhysSpec{
+		ReadRangePhysSpec: *tagValuesSpec.ReadRangePhysSpec.Copy().(*ReadRangePhysSpec),
+		Measurement:       measurement,
+		TagKey:            tagValuesSpec.TagKey,
+	}
+	spec.Filter = filter
+	return plan.CreateUniquePhysicalNode(ctx, "ReadMeasurementTagValues", spec), true, nil
+}
+
+// PushDownReadMeasurementTagKeysRule rewrites 'ReadTagKeys' whose filter
+// selects a single measurement, as done by schema.measurementTagKeys(),
+// into 'ReadMeasurementTagKeys'.
+type PushDownReadMeasurementTagKeysRule struct{}
+
+func (rule PushDownReadMeasurementTagKeysRule) Name() string {
+	return "PushDownReadMeasurementTagKeysRule"
+}
+
+func (rule PushDownReadMeasurementTagKeysRule) Pattern() plan.Pattern {
+	return plan.Pat(ReadTagKeysPhysKind)
+}
+
+func (rule PushDownReadMeasurementTagKeysRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bool, error) {
+	tagKeysSpec := pn.ProcedureSpec().(*ReadTagKeysPhysSpec)
+	measurement, filter := splitMeasurementPredicate(tagKeysSpec.Filter)
+	if measurement == "" {
+		return pn, false, nil
+	}
+
+	spec := &ReadMeasurementTagKeysPhysSpec{
+		ReadRangePhysSpec: *tagKeysSpec.ReadRangePhysSpec.Copy().(*ReadRangePhysSpec),
+		Measurement:       measurement,
+	}
+	spec.Filter = filter
+	return plan.CreateUniquePhysicalNode(ctx, "ReadMeasurementTagKeys", spec), true, nil
+}
+
+// splitMeasurementPredicate looks for a '_measurement == "name"' comparison
+// that the predicate requires, by being the predicate or a term of its top
+// level conjunction. It returns the name of the measurement and the predicate
+// without the comparison, which is nil if nothing remains. If there is no such
+// comparison, the name is empty and the predicate is returned unchanged.
+func splitMeasurementPredicate(pred *datatypes.Predicate) (string, *datatypes.Predicate) {
+	if pred == nil || pred.Root == nil {
+		return "", pred
+	}
+
+	var measurement string
+	var split func(n *datatypes.Node) *datatypes.Node
+	split = func(n *datatypes.Node) *datatypes.Node {
+		if n.NodeType == datatypes.NodeTypeLogicalExpression && n.GetLogical() == datatypes.LogicalAnd {
+			var children []*datatypes.Node
+			for _, c := range n.Children {
+				if c = split(c); c != nil {
+					children = append(children, c)
+				}
+			}
+			switch len(children) {
+			case 0:
+				return nil
+			case 1:
+				return children[0]
+			}
+			return &datatypes.Node{
+				NodeType: n.NodeType,
+				Value:    n.Value,
+				Children: children,
+			}
+		}
+
+		if measurement == "" && n.NodeType == datatypes.NodeTypeComparisonExpression &&
+			n.GetComparison() == datatypes.ComparisonEqual && len(n.Children) == 2 &&
+			n.Children[0].GetTagRefValue() == models.MeasurementTagKey &&
+			n.Children[1].NodeType == datatypes.NodeTypeLiteral {
+			if v, ok := n.Children[1].Value.(*datatypes.Node_StringValue); ok {
+				measurement = v.StringValue
+				return nil
+			}
+		}
+		return n
+	}
+
+	root := split(pred.Root)
+	if measurement == "" {
+		return "", pred
+	} else if root == nil {
+		return measurement, nil
+	}
+	return measurement, &datatypes.Predicate{Root: root}
+}
+
 // isPushableExpr determines if a predicate expression can be pushed down into the storage layer.
 func isPushableExpr(paramName string, expr semantic.Expression) (bool, error) {
 	switch e := expr.(type) {
@@ -655,7 +830,7 @@ func (SortedPivotRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bo
 
 //
 // Push Down of window aggregates.
-// ReadRangePhys |> window |> { min, max, mean, count, sum }
+// ReadRangePhys |> window |> { min, max, mean, count, sum, stddev, spread, quantile }
 //
 type PushDownWindowAggregateRule struct{}
 
@@ -671,6 +846,9 @@ var windowPushableAggs = []plan.ProcedureKind{
 	universe.MeanKind,
 	universe.FirstKind,
 	universe.LastKind,
+	universe.StddevKind,
+	universe.SpreadKind,
+	universe.QuantileKind,
 }
 
 func (rule PushDownWindowAggregateRule) Pattern() plan.Pattern {
@@ -706,10 +884,36 @@ func canPushWindowedAggregate(ctx context.Context, fnNode plan.Node) bool {
 	case universe.LastKind:
 		lastSpec := fnNode.ProcedureSpec().(*universe.LastProcedureSpec)
 		return lastSpec.Column == execute.DefaultValueColLabel
+	case universe.StddevKind:
+		// The storage engine only computes the sample standard deviation.
+		stddevSpec := fnNode.ProcedureSpec().(*universe.StddevProcedureSpec)
+		return stddevSpec.Mode == "sample" &&
+			len(stddevSpec.Columns) == 1 &&
+			stddevSpec.Columns[0] == execute.DefaultValueColLabel
+	case universe.SpreadKind:
+		spreadSpec := fnNode.ProcedureSpec().(*universe.SpreadProcedureSpec)
+		return len(spreadSpec.Columns) == 1 &&
+			spreadSpec.Columns[0] == execute.DefaultValueColLabel
+	case universe.QuantileKind:
+		// Only the estimate_tdigest method can be pushed down; the exact
+		// methods need every value of the window.
+		quantileSpec, ok := fnNode.ProcedureSpec().(*universe.TDigestQuantileProcedureSpec)
+		return ok &&
+			len(quantileSpec.Columns) == 1 &&
+			quantileSpec.Columns[0] == execute.DefaultValueColLabel
 	}
 	return true
 }
 
+// quantileArgs returns the quantile and compression of a quantile
+// aggregate, and zeroes for the other aggregates.
+func quantileArgs(spec plan.ProcedureSpec) (quantile, compression float64) {
+	if s, ok := spec.(*universe.TDigestQuantileProcedureSpec); ok {
+		return s.Quantile, s.Compression
+	}
+	return 0, 0
+}
+
 func isPushableWindow(windowSpec *universe.WindowProcedureSpec) bool {
 	// every and period must be equal
 	// every.isNegative must be false
@@ -743,12 +947,15 @@ func (PushDownWindowAggregateRule) Rewrite(ctx context.Context, pn plan.Node) (p
 	}
 
 	// Rule passes.
+	quantile, compression := quantileArgs(fnNode.ProcedureSpec())
 	return plan.CreateUniquePhysicalNode(ctx, "ReadWindowAggregate", &ReadWindowAggregatePhysSpec{
 		ReadRangePhysSpec: *fromSpec.Copy().(*ReadRangePhysSpec),
 		Aggregates:        []plan.ProcedureKind{fnNode.Kind()},
 		WindowEvery:       windowSpec.Window.Every,
 		Offset:            windowSpec.Window.Offset,
 		CreateEmpty:       windowSpec.CreateEmpty,
+		Quantile:          quantile,
+		Compression:       compression,
 	}), true, nil
 }
 
@@ -838,13 +1045,89 @@ func (p PushDownBareAggregateRule) Rewrite(ctx context.Context, pn plan.Node) (p
 	fromNode := fnNode.Predecessors()[0]
 	fromSpec := fromNode.ProcedureSpec().(*ReadRangePhysSpec)
 
+	quantile, compression := quantileArgs(fnNode.ProcedureSpec())
 	return plan.CreateUniquePhysicalNode(ctx, "ReadWindowAggregate", &ReadWindowAggregatePhysSpec{
 		ReadRangePhysSpec: *fromSpec.Copy().(*ReadRangePhysSpec),
 		Aggregates:        []plan.ProcedureKind{fnNode.Kind()},
 		WindowEvery:       flux.ConvertDuration(math.MaxInt64 * time.Nanosecond),
+		Quantile:          quantile,
+		Compression:       compression,
+	}), true, nil
+}
+
+// PushDownReadSeriesCardinalityRule matches
+// 'ReadWindowAggregate(first or last) |> group() |> count()', which counts
+// the series with data in the range, and rewrites it into
+// 'ReadSeriesCardinality' so the series are counted by the storage engine
+// rather than read. Filters on _value are not supported by the storage
+// engine, so such queries are not rewritten.
+type PushDownReadSeriesCardinalityRule struct{}
+
+func (PushDownReadSeriesCardinalityRule) Name() string {
+	return "PushDownReadSeriesCardinalityRule"
+}
+
+func (PushDownReadSeriesCardinalityRule) Pattern() plan.Pattern {
+	return plan.Pat(universe.CountKind,
+		plan.Pat(universe.GroupKind,
+			plan.Pat(ReadWindowAggregatePhysKind)))
+}
+
+func (PushDownReadSeriesCardinalityRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bool, error) {
+	countSpec := pn.ProcedureSpec().(*universe.CountProcedureSpec)
+	groupNode := pn.Predecessors()[0]
+	groupSpec := groupNode.ProcedureSpec().(*universe.GroupProcedureSpec)
+	windowAggregateNode := groupNode.Predecessors()[0]
+	windowAggregateSpec := windowAggregateNode.ProcedureSpec().(*ReadWindowAggregatePhysSpec)
+
+	if len(countSpec.Columns) != 1 || countSpec.Columns[0] != execute.DefaultValueColLabel {
+		return pn, false, nil
+	}
+
+	// All of the series need to be grouped into the same table.
+	if groupSpec.GroupMode != flux.GroupModeBy || len(groupSpec.GroupKeys) > 0 {
+		return pn, false, nil
+	}
+
+	// The window aggregate must produce a single row for each series,
+	// as pushing down a bare first() or last() does.
+	if len(windowAggregateSpec.Aggregates) != 1 {
+		return pn, false, nil
+	} else if kind := windowAggregateSpec.Aggregates[0]; kind != universe.FirstKind && kind != universe.LastKind {
+		return pn, false, nil
+	}
+	if windowAggregateSpec.WindowEvery != flux.ConvertDuration(math.MaxInt64*time.Nanosecond) ||
+		!windowAggregateSpec.Offset.IsZero() ||
+		windowAggregateSpec.CreateEmpty ||
+		windowAggregateSpec.TimeColumn != "" {
+		return pn, false, nil
+	}
+
+	if hasFieldRef(windowAggregateSpec.Filter.GetRoot()) {
+		return pn, false, nil
+	}
+
+	return plan.CreateUniquePhysicalNode(ctx, "ReadSeriesCardinality", &ReadSeriesCardinalityPhysSpec{
+		ReadRangePhysSpec: *windowAggregateSpec.ReadRangePhysSpec.Copy().(*ReadRangePhysSpec),
 	}), true, nil
 }
 
+// hasFieldRef returns true if the storage predicate node n refers to
+// the field value.
+func hasFieldRef(n *datatypes.Node) bool {
+	if n == nil {
+		return false
+	} else if n.NodeType == datatypes.NodeTypeFieldRef {
+		return true
+	}
+	for _, c := range n.Children {
+		if hasFieldRef(c) {
+			return true
+		}
+	}
+	return false
+}
+
 // GroupWindowAggregateTransposeRule will match the given pattern.
 // ReadGroupPhys |> window |> { min, max, count, sum }
 //
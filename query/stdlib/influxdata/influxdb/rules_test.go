@@ -19,6 +19,7 @@ import (
 	"github.com/influxdata/flux/values"
 	"github.com/influxdata/influxdb/v2/kit/feature"
 	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
 )
@@ -1120,6 +1121,326 @@ func TestReadTagValuesRule(t *testing.T) {
 	}
 }
 
+func TestReadMeasurementSchemaRules(t *testing.T) {
+	tagEqual := func(key, value string) *datatypes.Node {
+		return &datatypes.Node{
+			NodeType: datatypes.NodeTypeComparisonExpression,
+			Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+			Children: []*datatypes.Node{
+				{NodeType: datatypes.NodeTypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: key}},
+				{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_StringValue{StringValue: value}},
+			},
+		}
+	}
+	and := func(left, right *datatypes.Node) *datatypes.Node {
+		return &datatypes.Node{
+			NodeType: datatypes.NodeTypeLogicalExpression,
+			Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalAnd},
+			Children: []*datatypes.Node{left, right},
+		}
+	}
+	readRange := func(root *datatypes.Node) influxdb.ReadRangePhysSpec {
+		s := influxdb.ReadRangePhysSpec{
+			Bucket: "my-bucket",
+			Bounds: flux.Bounds{
+				Start: fluxTime(5),
+				Stop:  fluxTime(10),
+			},
+		}
+		if root != nil {
+			s.Filter = &datatypes.Predicate{Root: root}
+		}
+		return s
+	}
+	readTagValues := func(tagKey string, root *datatypes.Node) *influxdb.ReadTagValuesPhysSpec {
+		return &influxdb.ReadTagValuesPhysSpec{
+			ReadRangePhysSpec: readRange(root),
+			TagKey:            tagKey,
+		}
+	}
+	rules := []plan.Rule{
+		influxdb.PushDownReadMeasurementNamesRule{},
+		influxdb.PushDownReadMeasurementFieldsRule{},
+		influxdb.PushDownReadMeasurementTagValuesRule{},
+		influxdb.PushDownReadMeasurementTagKeysRule{},
+	}
+
+	measurementCPU := tagEqual(models.MeasurementTagKey, "cpu")
+	hostA := tagEqual("host", "a")
+
+	tests := []plantest.RuleTestCase{
+		{
+			Name:  "measurement names",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadTagValues", readTagValues("_measurement", hostA)),
+				},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadMeasurementNames", &influxdb.ReadMeasurementNamesPhysSpec{
+						ReadRangePhysSpec: readRange(hostA),
+					}),
+				},
+			},
+		},
+		{
+			Name:  "field keys",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadTagValues", readTagValues("_field", nil)),
+				},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadMeasurementFields", &influxdb.ReadMeasurementFieldsPhysSpec{
+						ReadRangePhysSpec: readRange(nil),
+					}),
+				},
+			},
+		},
+		{
+			Name:  "measurement field keys",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadTagValues", readTagValues("_field", measurementCPU)),
+				},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadMeasurementFields", &influxdb.ReadMeasurementFieldsPhysSpec{
+						ReadRangePhysSpec: readRange(nil),
+						Measurement:       "cpu",
+					}),
+				},
+			},
+		},
+		{
+			Name:  "measurement tag values",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadTagValues", readTagValues("host", and(hostA, measurementCPU))),
+				},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadMeasurementTagValues", &influxdb.ReadMeasurementTagValuesPhysSpec{
+						ReadRangePhysSpec: readRange(hostA),
+						Measurement:       "cpu",
+						TagKey:            "host",
+					}),
+				},
+			},
+		},
+		{
+			Name:  "tag values without measurement",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadTagValues", readTagValues("host", hostA)),
+				},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "measurement tag keys",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadTagKeys", &influxdb.ReadTagKeysPhysSpec{
+						ReadRangePhysSpec: readRange(and(measurementCPU, hostA)),
+					}),
+				},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadMeasurementTagKeys", &influxdb.ReadMeasurementTagKeysPhysSpec{
+						ReadRangePhysSpec: readRange(hostA),
+						Measurement:       "cpu",
+					}),
+				},
+			},
+		},
+		{
+			Name:  "tag keys with measurement disjunction",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadTagKeys", &influxdb.ReadTagKeysPhysSpec{
+						ReadRangePhysSpec: readRange(&datatypes.Node{
+							NodeType: datatypes.NodeTypeLogicalExpression,
+							Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalOr},
+							Children: []*datatypes.Node{measurementCPU, hostA},
+						}),
+					}),
+				},
+			},
+			NoChange: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc)
+		})
+	}
+}
+
+func TestReadSeriesCardinalityRule(t *testing.T) {
+	readRange := influxdb.ReadRangePhysSpec{
+		Bucket: "my-bucket",
+		Bounds: flux.Bounds{
+			Start: fluxTime(5),
+			Stop:  fluxTime(10),
+		},
+	}
+	readWindowAggregate := func(proc plan.ProcedureKind) *influxdb.ReadWindowAggregatePhysSpec {
+		return &influxdb.ReadWindowAggregatePhysSpec{
+			ReadRangePhysSpec: *(readRange.Copy().(*influxdb.ReadRangePhysSpec)),
+			WindowEvery:       flux.ConvertDuration(math.MaxInt64 * time.Nanosecond),
+			Aggregates:        []plan.ProcedureKind{proc},
+		}
+	}
+	groupSpec := func(keys ...string) *universe.GroupProcedureSpec {
+		return &universe.GroupProcedureSpec{
+			GroupMode: flux.GroupModeBy,
+			GroupKeys: keys,
+		}
+	}
+	rules := []plan.Rule{
+		influxdb.PushDownBareAggregateRule{},
+		influxdb.PushDownReadSeriesCardinalityRule{},
+	}
+
+	tests := []plantest.RuleTestCase{
+		{
+			// ReadRange -> first -> group -> count => ReadSeriesCardinality
+			Name:  "first",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("first", firstProcedureSpec()),
+					plan.CreatePhysicalNode("group", groupSpec()),
+					plan.CreatePhysicalNode("count", countProcedureSpec()),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+					{2, 3},
+				},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadSeriesCardinality", &influxdb.ReadSeriesCardinalityPhysSpec{
+						ReadRangePhysSpec: readRange,
+					}),
+				},
+			},
+		},
+		{
+			// ReadWindowAggregate(last) -> group -> count => ReadSeriesCardinality
+			Name:  "last",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadWindowAggregate", readWindowAggregate(universe.LastKind)),
+					plan.CreatePhysicalNode("group", groupSpec()),
+					plan.CreatePhysicalNode("count", countProcedureSpec()),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+				},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadSeriesCardinality", &influxdb.ReadSeriesCardinalityPhysSpec{
+						ReadRangePhysSpec: readRange,
+					}),
+				},
+			},
+		},
+		{
+			// ReadWindowAggregate(count) -> group -> count counts the
+			// points, not the series.
+			Name:  "count",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadWindowAggregate", readWindowAggregate(universe.CountKind)),
+					plan.CreatePhysicalNode("group", groupSpec()),
+					plan.CreatePhysicalNode("count", countProcedureSpec()),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+				},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "group by",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadWindowAggregate", readWindowAggregate(universe.FirstKind)),
+					plan.CreatePhysicalNode("group", groupSpec("_measurement")),
+					plan.CreatePhysicalNode("count", countProcedureSpec()),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+				},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "field value filter",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadWindowAggregate", func() *influxdb.ReadWindowAggregatePhysSpec {
+						s := readWindowAggregate(universe.FirstKind)
+						s.Filter = &datatypes.Predicate{
+							Root: &datatypes.Node{
+								NodeType: datatypes.NodeTypeComparisonExpression,
+								Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonGreater},
+								Children: []*datatypes.Node{
+									{NodeType: datatypes.NodeTypeFieldRef, Value: &datatypes.Node_FieldRefValue{FieldRefValue: "_value"}},
+									{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_FloatValue{FloatValue: 0}},
+								},
+							},
+						}
+						return s
+					}()),
+					plan.CreatePhysicalNode("group", groupSpec()),
+					plan.CreatePhysicalNode("count", countProcedureSpec()),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+				},
+			},
+			NoChange: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc)
+		})
+	}
+}
+
 func minProcedureSpec() *universe.MinProcedureSpec {
 	return &universe.MinProcedureSpec{
 		SelectorConfig: execute.SelectorConfig{Column: execute.DefaultValueColLabel},
@@ -1150,6 +1471,24 @@ func lastProcedureSpec() *universe.LastProcedureSpec {
 		SelectorConfig: execute.SelectorConfig{Column: execute.DefaultValueColLabel},
 	}
 }
+func stddevProcedureSpec() *universe.StddevProcedureSpec {
+	return &universe.StddevProcedureSpec{
+		Mode:            "sample",
+		AggregateConfig: execute.AggregateConfig{Columns: []string{execute.DefaultValueColLabel}},
+	}
+}
+func spreadProcedureSpec() *universe.SpreadProcedureSpec {
+	return &universe.SpreadProcedureSpec{
+		AggregateConfig: execute.AggregateConfig{Columns: []string{execute.DefaultValueColLabel}},
+	}
+}
+func quantileProcedureSpec(q float64) *universe.TDigestQuantileProcedureSpec {
+	return &universe.TDigestQuantileProcedureSpec{
+		Quantile:        q,
+		Compression:     1000,
+		AggregateConfig: execute.AggregateConfig{Columns: []string{execute.DefaultValueColLabel}},
+	}
+}
 func meanProcedureSpec() *universe.MeanProcedureSpec {
 	return &universe.MeanProcedureSpec{
 		AggregateConfig: execute.AggregateConfig{Columns: []string{execute.DefaultValueColLabel}},
@@ -1299,6 +1638,43 @@ func TestPushDownWindowAggregateRule(t *testing.T) {
 		After:   simpleResult(universe.LastKind, false),
 	})
 
+	// ReadRange -> window -> stddev => ReadWindowAggregate
+	tests = append(tests, plantest.RuleTestCase{
+		Context: context.Background(),
+		Name:    "SimplePassStddev",
+		Rules:   []plan.Rule{influxdb.PushDownWindowAggregateRule{}},
+		Before:  simplePlanWithWindowAgg(window1m, universe.StddevKind, stddevProcedureSpec()),
+		After:   simpleResult(universe.StddevKind, false),
+	})
+
+	// ReadRange -> window -> spread => ReadWindowAggregate
+	tests = append(tests, plantest.RuleTestCase{
+		Context: context.Background(),
+		Name:    "SimplePassSpread",
+		Rules:   []plan.Rule{influxdb.PushDownWindowAggregateRule{}},
+		Before:  simplePlanWithWindowAgg(window1m, universe.SpreadKind, spreadProcedureSpec()),
+		After:   simpleResult(universe.SpreadKind, false),
+	})
+
+	// ReadRange -> window -> quantile => ReadWindowAggregate
+	tests = append(tests, plantest.RuleTestCase{
+		Context: context.Background(),
+		Name:    "SimplePassQuantile",
+		Rules:   []plan.Rule{influxdb.PushDownWindowAggregateRule{}},
+		Before:  simplePlanWithWindowAgg(window1m, universe.QuantileKind, quantileProcedureSpec(0.99)),
+		After: &plantest.PlanSpec{
+			Nodes: []plan.Node{
+				plan.CreatePhysicalNode("ReadWindowAggregate", &influxdb.ReadWindowAggregatePhysSpec{
+					ReadRangePhysSpec: readRange,
+					Aggregates:        []plan.ProcedureKind{universe.QuantileKind},
+					WindowEvery:       flux.ConvertDuration(60000000000 * time.Nanosecond),
+					Quantile:          0.99,
+					Compression:       1000,
+				}),
+			},
+		},
+	})
+
 	// Rewrite with successors
 	// ReadRange -> window -> min -> count {2} => ReadWindowAggregate -> count {2}
 	tests = append(tests, plantest.RuleTestCase{
@@ -1540,6 +1916,44 @@ func TestPushDownWindowAggregateRule(t *testing.T) {
 		NoChange: true,
 	})
 
+	// Population standard deviation
+	// ReadRange -> window -> stddev => NO-CHANGE
+	tests = append(tests, plantest.RuleTestCase{
+		Name:    "BadStddevMode",
+		Context: context.Background(),
+		Rules:   []plan.Rule{influxdb.PushDownWindowAggregateRule{}},
+		Before: simplePlanWithWindowAgg(window1m, "stddev", &universe.StddevProcedureSpec{
+			Mode:            "population",
+			AggregateConfig: execute.AggregateConfig{Columns: []string{execute.DefaultValueColLabel}},
+		}),
+		NoChange: true,
+	})
+
+	// Bad spread column
+	// ReadRange -> window -> spread => NO-CHANGE
+	tests = append(tests, plantest.RuleTestCase{
+		Name:    "BadSpreadCol",
+		Context: context.Background(),
+		Rules:   []plan.Rule{influxdb.PushDownWindowAggregateRule{}},
+		Before: simplePlanWithWindowAgg(window1m, "spread", &universe.SpreadProcedureSpec{
+			AggregateConfig: execute.AggregateConfig{Columns: []string{"_valmoo"}},
+		}),
+		NoChange: true,
+	})
+
+	// Exact quantile
+	// ReadRange -> window -> quantile => NO-CHANGE
+	tests = append(tests, plantest.RuleTestCase{
+		Name:    "BadQuantileMethod",
+		Context: context.Background(),
+		Rules:   []plan.Rule{influxdb.PushDownWindowAggregateRule{}},
+		Before: simplePlanWithWindowAgg(window1m, "quantile", &universe.ExactQuantileAggProcedureSpec{
+			Quantile:        0.99,
+			AggregateConfig: execute.AggregateConfig{Columns: []string{execute.DefaultValueColLabel}},
+		}),
+		NoChange: true,
+	})
+
 	// No match due to a collapsed node having a successor
 	// ReadRange -> window -> min
 	//                    \-> min
@@ -2574,6 +2988,31 @@ func TestPushDownBareAggregateRule(t *testing.T) {
 				},
 			},
 		},
+		{
+			// ReadRange -> quantile => ReadWindowAggregate
+			Context: context.Background(),
+			Name:    "push down median",
+			Rules:   []plan.Rule{influxdb.PushDownBareAggregateRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", readRange),
+					plan.CreatePhysicalNode("quantile", quantileProcedureSpec(0.5)),
+				},
+				Edges: [][2]int{
+					{0, 1},
+				},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadWindowAggregate", func() *influxdb.ReadWindowAggregatePhysSpec {
+						spec := readWindowAggregate(universe.QuantileKind)
+						spec.Quantile = 0.5
+						spec.Compression = 1000
+						return spec
+					}()),
+				},
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -23,6 +23,11 @@ func init() {
 	execute.RegisterSource(ReadWindowAggregatePhysKind, createReadWindowAggregateSource)
 	execute.RegisterSource(ReadTagKeysPhysKind, createReadTagKeysSource)
 	execute.RegisterSource(ReadTagValuesPhysKind, createReadTagValuesSource)
+	execute.RegisterSource(ReadMeasurementNamesPhysKind, createReadMeasurementNamesSource)
+	execute.RegisterSource(ReadMeasurementTagKeysPhysKind, createReadMeasurementTagKeysSource)
+	execute.RegisterSource(ReadMeasurementTagValuesPhysKind, createReadMeasurementTagValuesSource)
+	execute.RegisterSource(ReadMeasurementFieldsPhysKind, createReadMeasurementFieldsSource)
+	execute.RegisterSource(ReadSeriesCardinalityPhysKind, createReadSeriesCardinalitySource)
 }
 
 type runner interface {
@@ -357,6 +362,8 @@ func createReadWindowAggregateSource(s plan.ProcedureSpec, id execute.DatasetID,
 			Aggregates:  spec.Aggregates,
 			CreateEmpty: spec.CreateEmpty,
 			TimeColumn:  spec.TimeColumn,
+			Quantile:    spec.Quantile,
+			Compression: spec.Compression,
 		},
 		a,
 	), nil
@@ -490,3 +497,327 @@ func (s *readTagValuesSource) run(ctx context.Context) error {
 	}
 	return s.processTables(ctx, ti, execute.Now())
 }
+
+func createReadMeasurementNamesSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	span, ctx := tracing.StartSpanFromContext(a.Context())
+	defer span.Finish()
+
+	spec := prSpec.(*ReadMeasurementNamesPhysSpec)
+	deps := GetStorageDependencies(a.Context()).FromDeps
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, errors.New("missing request on context")
+	}
+	orgID := req.OrganizationID
+
+	bucketID, err := spec.LookupBucketID(ctx, orgID, deps.BucketLookup)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := a.StreamContext().Bounds()
+	return ReadMeasurementNamesSource(
+		dsid,
+		deps.Reader,
+		query.ReadMeasurementNamesSpec{
+			ReadFilterSpec: query.ReadFilterSpec{
+				OrganizationID: orgID,
+				BucketID:       bucketID,
+				Bounds:         *bounds,
+				Predicate:      spec.Filter,
+			},
+		},
+		a,
+	), nil
+}
+
+type readMeasurementNamesSource struct {
+	Source
+
+	reader   query.StorageReader
+	readSpec query.ReadMeasurementNamesSpec
+}
+
+func ReadMeasurementNamesSource(id execute.DatasetID, r query.StorageReader, readSpec query.ReadMeasurementNamesSpec, a execute.Administration) execute.Source {
+	src := &readMeasurementNamesSource{
+		reader:   r,
+		readSpec: readSpec,
+	}
+	src.id = id
+	src.alloc = a.Allocator()
+
+	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
+	src.orgID = readSpec.OrganizationID
+	src.op = "readMeasurementNames"
+
+	src.runner = src
+	return src
+}
+
+func (s *readMeasurementNamesSource) run(ctx context.Context) error {
+	ti, err := s.reader.ReadMeasurementNames(ctx, s.readSpec, s.alloc)
+	if err != nil {
+		return err
+	}
+	return s.processTables(ctx, ti, execute.Now())
+}
+
+func createReadMeasurementTagKeysSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	span, ctx := tracing.StartSpanFromContext(a.Context())
+	defer span.Finish()
+
+	spec := prSpec.(*ReadMeasurementTagKeysPhysSpec)
+	deps := GetStorageDependencies(a.Context()).FromDeps
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, errors.New("missing request on context")
+	}
+	orgID := req.OrganizationID
+
+	bucketID, err := spec.LookupBucketID(ctx, orgID, deps.BucketLookup)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := a.StreamContext().Bounds()
+	return ReadMeasurementTagKeysSource(
+		dsid,
+		deps.Reader,
+		query.ReadMeasurementTagKeysSpec{
+			ReadFilterSpec: query.ReadFilterSpec{
+				OrganizationID: orgID,
+				BucketID:       bucketID,
+				Bounds:         *bounds,
+				Predicate:      spec.Filter,
+			},
+			Measurement: spec.Measurement,
+		},
+		a,
+	), nil
+}
+
+type readMeasurementTagKeysSource struct {
+	Source
+
+	reader   query.StorageReader
+	readSpec query.ReadMeasurementTagKeysSpec
+}
+
+func ReadMeasurementTagKeysSource(id execute.DatasetID, r query.StorageReader, readSpec query.ReadMeasurementTagKeysSpec, a execute.Administration) execute.Source {
+	src := &readMeasurementTagKeysSource{
+		reader:   r,
+		readSpec: readSpec,
+	}
+	src.id = id
+	src.alloc = a.Allocator()
+
+	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
+	src.orgID = readSpec.OrganizationID
+	src.op = "readMeasurementTagKeys"
+
+	src.runner = src
+	return src
+}
+
+func (s *readMeasurementTagKeysSource) run(ctx context.Context) error {
+	ti, err := s.reader.ReadMeasurementTagKeys(ctx, s.readSpec, s.alloc)
+	if err != nil {
+		return err
+	}
+	return s.processTables(ctx, ti, execute.Now())
+}
+
+func createReadMeasurementTagValuesSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	span, ctx := tracing.StartSpanFromContext(a.Context())
+	defer span.Finish()
+
+	spec := prSpec.(*ReadMeasurementTagValuesPhysSpec)
+	deps := GetStorageDependencies(a.Context()).FromDeps
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, errors.New("missing request on context")
+	}
+	orgID := req.OrganizationID
+
+	bucketID, err := spec.LookupBucketID(ctx, orgID, deps.BucketLookup)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := a.StreamContext().Bounds()
+	return ReadMeasurementTagValuesSource(
+		dsid,
+		deps.Reader,
+		query.ReadMeasurementTagValuesSpec{
+			ReadFilterSpec: query.ReadFilterSpec{
+				OrganizationID: orgID,
+				BucketID:       bucketID,
+				Bounds:         *bounds,
+				Predicate:      spec.Filter,
+			},
+			Measurement: spec.Measurement,
+			TagKey:      spec.TagKey,
+		},
+		a,
+	), nil
+}
+
+type readMeasurementTagValuesSource struct {
+	Source
+
+	reader   query.StorageReader
+	readSpec query.ReadMeasurementTagValuesSpec
+}
+
+func ReadMeasurementTagValuesSource(id execute.DatasetID, r query.StorageReader, readSpec query.ReadMeasurementTagValuesSpec, a execute.Administration) execute.Source {
+	src := &readMeasurementTagValuesSource{
+		reader:   r,
+		readSpec: readSpec,
+	}
+	src.id = id
+	src.alloc = a.Allocator()
+
+	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
+	src.orgID = readSpec.OrganizationID
+	src.op = "readMeasurementTagValues"
+
+	src.runner = src
+	return src
+}
+
+func (s *readMeasurementTagValuesSource) run(ctx context.Context) error {
+	ti, err := s.reader.ReadMeasurementTagValues(ctx, s.readSpec, s.alloc)
+	if err != nil {
+		return err
+	}
+	return s.processTables(ctx, ti, execute.Now())
+}
+
+func createReadMeasurementFieldsSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	span, ctx := tracing.StartSpanFromContext(a.Context())
+	defer span.Finish()
+
+	spec := prSpec.(*ReadMeasurementFieldsPhysSpec)
+	deps := GetStorageDependencies(a.Context()).FromDeps
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, errors.New("missing request on context")
+	}
+	orgID := req.OrganizationID
+
+	bucketID, err := spec.LookupBucketID(ctx, orgID, deps.BucketLookup)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := a.StreamContext().Bounds()
+	return ReadMeasurementFieldsSource(
+		dsid,
+		deps.Reader,
+		query.ReadMeasurementFieldsSpec{
+			ReadFilterSpec: query.ReadFilterSpec{
+				OrganizationID: orgID,
+				BucketID:       bucketID,
+				Bounds:         *bounds,
+				Predicate:      spec.Filter,
+			},
+			Measurement: spec.Measurement,
+		},
+		a,
+	), nil
+}
+
+type readMeasurementFieldsSource struct {
+	Source
+
+	reader   query.StorageReader
+	readSpec query.ReadMeasurementFieldsSpec
+}
+
+func ReadMeasurementFieldsSource(id execute.DatasetID, r query.StorageReader, readSpec query.ReadMeasurementFieldsSpec, a execute.Administration) execute.Source {
+	src := &readMeasurementFieldsSource{
+		reader:   r,
+		readSpec: readSpec,
+	}
+	src.id = id
+	src.alloc = a.Allocator()
+
+	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
+	src.orgID = readSpec.OrganizationID
+	src.op = "readMeasurementFields"
+
+	src.runner = src
+	return src
+}
+
+func (s *readMeasurementFieldsSource) run(ctx context.Context) error {
+	ti, err := s.reader.ReadMeasurementFields(ctx, s.readSpec, s.alloc)
+	if err != nil {
+		return err
+	}
+	return s.processTables(ctx, ti, execute.Now())
+}
+
+func createReadSeriesCardinalitySource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	span, ctx := tracing.StartSpanFromContext(a.Context())
+	defer span.Finish()
+
+	spec := prSpec.(*ReadSeriesCardinalityPhysSpec)
+	deps := GetStorageDependencies(a.Context()).FromDeps
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, errors.New("missing request on context")
+	}
+	orgID := req.OrganizationID
+
+	bucketID, err := spec.LookupBucketID(ctx, orgID, deps.BucketLookup)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := a.StreamContext().Bounds()
+	return ReadSeriesCardinalitySource(
+		dsid,
+		deps.Reader,
+		query.ReadSeriesCardinalitySpec{
+			ReadFilterSpec: query.ReadFilterSpec{
+				OrganizationID: orgID,
+				BucketID:       bucketID,
+				Bounds:         *bounds,
+				Predicate:      spec.Filter,
+			},
+		},
+		a,
+	), nil
+}
+
+type readSeriesCardinalitySource struct {
+	Source
+
+	reader   query.StorageReader
+	readSpec query.ReadSeriesCardinalitySpec
+}
+
+func ReadSeriesCardinalitySource(id execute.DatasetID, r query.StorageReader, readSpec query.ReadSeriesCardinalitySpec, a execute.Administration) execute.Source {
+	src := &readSeriesCardinalitySource{
+		reader:   r,
+		readSpec: readSpec,
+	}
+	src.id = id
+	src.alloc = a.Allocator()
+
+	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
+	src.orgID = readSpec.OrganizationID
+	src.op = "readSeriesCardinality"
+
+	src.runner = src
+	return src
+}
+
+func (s *readSeriesCardinalitySource) run(ctx context.Context) error {
+	ti, err := s.reader.ReadSeriesCardinality(ctx, s.readSpec, s.alloc)
+	if err != nil {
+		return err
+	}
+	return s.processTables(ctx, ti, execute.Now())
+}
@@ -57,6 +57,26 @@ func (mockReader) ReadWindowAggregate(ctx context.Context, spec query.ReadWindow
 	return &mockTableIterator{}, nil
 }
 
+func (mockReader) ReadMeasurementNames(ctx context.Context, spec query.ReadMeasurementNamesSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return &mockTableIterator{}, nil
+}
+
+func (mockReader) ReadMeasurementTagKeys(ctx context.Context, spec query.ReadMeasurementTagKeysSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return &mockTableIterator{}, nil
+}
+
+func (mockReader) ReadMeasurementTagValues(ctx context.Context, spec query.ReadMeasurementTagValuesSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return &mockTableIterator{}, nil
+}
+
+func (mockReader) ReadMeasurementFields(ctx context.Context, spec query.ReadMeasurementFieldsSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return &mockTableIterator{}, nil
+}
+
+func (mockReader) ReadSeriesCardinality(ctx context.Context, spec query.ReadSeriesCardinalitySpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return &mockTableIterator{}, nil
+}
+
 func (mockReader) Close() {
 }
 
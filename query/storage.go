@@ -22,6 +22,12 @@ type StorageReader interface {
 	ReadTagKeys(ctx context.Context, spec ReadTagKeysSpec, alloc *memory.Allocator) (TableIterator, error)
 	ReadTagValues(ctx context.Context, spec ReadTagValuesSpec, alloc *memory.Allocator) (TableIterator, error)
 
+	ReadMeasurementNames(ctx context.Context, spec ReadMeasurementNamesSpec, alloc *memory.Allocator) (TableIterator, error)
+	ReadMeasurementTagKeys(ctx context.Context, spec ReadMeasurementTagKeysSpec, alloc *memory.Allocator) (TableIterator, error)
+	ReadMeasurementTagValues(ctx context.Context, spec ReadMeasurementTagValuesSpec, alloc *memory.Allocator) (TableIterator, error)
+	ReadMeasurementFields(ctx context.Context, spec ReadMeasurementFieldsSpec, alloc *memory.Allocator) (TableIterator, error)
+	ReadSeriesCardinality(ctx context.Context, spec ReadSeriesCardinalitySpec, alloc *memory.Allocator) (TableIterator, error)
+
 	Close()
 }
 
@@ -55,6 +61,32 @@ type ReadTagValuesSpec struct {
 	TagKey string
 }
 
+type ReadMeasurementNamesSpec struct {
+	ReadFilterSpec
+}
+
+type ReadMeasurementTagKeysSpec struct {
+	ReadFilterSpec
+	Measurement string
+}
+
+type ReadMeasurementTagValuesSpec struct {
+	ReadFilterSpec
+	Measurement string
+	TagKey      string
+}
+
+// ReadMeasurementFieldsSpec reads the field keys of Measurement, or of all
+// measurements if it is empty.
+type ReadMeasurementFieldsSpec struct {
+	ReadFilterSpec
+	Measurement string
+}
+
+type ReadSeriesCardinalitySpec struct {
+	ReadFilterSpec
+}
+
 // Window and the WindowEvery/Offset should be mutually exclusive. If you set either the WindowEvery or Offset with
 // nanosecond values, then the Window will be ignored
 type ReadWindowAggregateSpec struct {
@@ -65,6 +97,10 @@ type ReadWindowAggregateSpec struct {
 	CreateEmpty bool
 	TimeColumn  string
 	Window      execute.Window
+
+	// Quantile and Compression are the arguments of the quantile aggregate.
+	Quantile    float64
+	Compression float64
 }
 
 func (spec *ReadWindowAggregateSpec) Name() string {
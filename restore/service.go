@@ -0,0 +1,555 @@
+// Package restore restores resources of an organization from a backup of the
+// metadata database into the live one.
+package restore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	"go.uber.org/zap"
+)
+
+// Services are the services of the resources restored from, or restored to.
+type Services struct {
+	Organizations         influxdb.OrganizationService
+	Dashboards            influxdb.DashboardService
+	Tasks                 influxdb.TaskService
+	Checks                influxdb.CheckService
+	NotificationRules     influxdb.NotificationRuleStore
+	NotificationEndpoints influxdb.NotificationEndpointService
+	Variables             influxdb.VariableService
+	Telegrafs             influxdb.TelegrafConfigStore
+}
+
+// SourceFunc returns the services reading the resources of a backed-up metadata store.
+type SourceFunc func(store kv.Store) (Services, error)
+
+var _ influxdb.ResourceRestoreService = (*Service)(nil)
+
+// Service restores resources from backups of the metadata database.
+type Service struct {
+	log    *zap.Logger
+	to     Services
+	source SourceFunc
+}
+
+// NewService returns a service restoring the resources of backups to the services to.
+// The resources of a backup are read with the services returned by source.
+func NewService(log *zap.Logger, to Services, source SourceFunc) *Service {
+	return &Service{
+		log:    log,
+		to:     to,
+		source: source,
+	}
+}
+
+// RestoreResources reads a backed-up metadata database from r and restores the
+// requested resources of one of its organizations.
+func (s *Service) RestoreResources(ctx context.Context, userID influxdb.ID, r io.Reader, req influxdb.RestoreResourcesRequest) (*influxdb.RestoreResourcesReport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	dir, err := ioutil.TempDir("", "influxdb-restore")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "influxd.bolt")
+	if err := copyFile(path, r); err != nil {
+		return nil, err
+	}
+
+	store := bolt.NewKVStore(s.log, path, bolt.WithNoSync)
+	if err := store.Open(ctx); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to open backup of metadata database",
+			Err:  err,
+		}
+	}
+	defer store.Close()
+
+	// Bring backups of older versions up to date.
+	migrator, err := migration.NewMigrator(s.log, store, all.Migrations[:]...)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrator.Up(ctx); err != nil {
+		return nil, err
+	}
+
+	from, err := s.source(store)
+	if err != nil {
+		return nil, err
+	}
+	return Restore(ctx, from, s.to, userID, req)
+}
+
+func copyFile(path string, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Restore restores the requested resources read from the services from to the
+// services to. Restored resources get new IDs. A resource is not restored if a
+// resource of the same kind and name exists in the destination organization, or
+// if it refers to a resource missing from it; it is reported as a conflict.
+func Restore(ctx context.Context, from, to Services, userID influxdb.ID, req influxdb.RestoreResourcesRequest) (*influxdb.RestoreResourcesReport, error) {
+	kinds, err := restoredKinds(req.Kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &restorer{
+		from:   from,
+		to:     to,
+		userID: userID,
+		report: &influxdb.RestoreResourcesReport{
+			Restored:  []influxdb.RestoredResource{},
+			Conflicts: []influxdb.RestoreConflict{},
+		},
+	}
+	if err := r.findOrgs(ctx, req); err != nil {
+		return nil, err
+	}
+
+	restoreFns := map[influxdb.ResourceType]func(context.Context) error{
+		influxdb.VariablesResourceType:        r.restoreVariables,
+		influxdb.DashboardsResourceType:       r.restoreDashboards,
+		influxdb.TelegrafsResourceType:        r.restoreTelegrafs,
+		influxdb.TasksResourceType:            r.restoreTasks,
+		influxdb.ChecksResourceType:           r.restoreChecks,
+		influxdb.NotificationRuleResourceType: r.restoreNotificationRules,
+	}
+	for _, kind := range influxdb.RestorableResourceTypes {
+		if !kinds[kind] {
+			continue
+		}
+		if err := restoreFns[kind](ctx); err != nil {
+			return nil, &influxdb.Error{
+				Msg: fmt.Sprintf("unable to restore %s", kind),
+				Err: err,
+			}
+		}
+	}
+	return r.report, nil
+}
+
+// restoredKinds returns the set of kinds to restore, all restorable kinds if
+// kinds is empty.
+func restoredKinds(kinds []influxdb.ResourceType) (map[influxdb.ResourceType]bool, error) {
+	if len(kinds) == 0 {
+		kinds = influxdb.RestorableResourceTypes
+	}
+
+	set := make(map[influxdb.ResourceType]bool, len(kinds))
+	for _, kind := range kinds {
+		restorable := false
+		for _, k := range influxdb.RestorableResourceTypes {
+			restorable = restorable || k == kind
+		}
+		if !restorable {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("resources of kind %q cannot be restored", kind),
+			}
+		}
+		set[kind] = true
+	}
+	return set, nil
+}
+
+type restorer struct {
+	from, to Services
+	userID   influxdb.ID
+
+	// orgID and newOrgID are the IDs of the organization restored from and to.
+	orgID, newOrgID influxdb.ID
+
+	report *influxdb.RestoreResourcesReport
+}
+
+func (r *restorer) findOrgs(ctx context.Context, req influxdb.RestoreResourcesRequest) error {
+	var filter influxdb.OrganizationFilter
+	if req.OrgID.Valid() {
+		filter.ID = &req.OrgID
+	} else if req.Org != "" {
+		filter.Name = &req.Org
+	} else {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "organization id or name to restore is required",
+		}
+	}
+	org, err := r.from.Organizations.FindOrganization(ctx, filter)
+	if err != nil {
+		return &influxdb.Error{
+			Msg: "unable to find organization in backup",
+			Err: err,
+		}
+	}
+	r.orgID = org.ID
+
+	newFilter := influxdb.OrganizationFilter{Name: &org.Name}
+	if req.NewOrgID.Valid() {
+		newFilter = influxdb.OrganizationFilter{ID: &req.NewOrgID}
+	} else if req.NewOrg != "" {
+		newFilter = influxdb.OrganizationFilter{Name: &req.NewOrg}
+	}
+	newOrg, err := r.to.Organizations.FindOrganization(ctx, newFilter)
+	if err != nil {
+		return &influxdb.Error{
+			Msg: "unable to find organization to restore to",
+			Err: err,
+		}
+	}
+	r.newOrgID = newOrg.ID
+	return nil
+}
+
+func (r *restorer) restored(kind influxdb.ResourceType, name string, oldID, newID influxdb.ID) {
+	r.report.Restored = append(r.report.Restored, influxdb.RestoredResource{
+		Kind:  kind,
+		Name:  name,
+		OldID: oldID,
+		NewID: newID,
+	})
+}
+
+func (r *restorer) conflict(kind influxdb.ResourceType, name string, id influxdb.ID, reason string) {
+	r.report.Conflicts = append(r.report.Conflicts, influxdb.RestoreConflict{
+		Kind:   kind,
+		Name:   name,
+		ID:     id,
+		Reason: reason,
+	})
+}
+
+// exists reports a conflict if name is one of the names of existing resources.
+func (r *restorer) exists(names map[string]bool, kind influxdb.ResourceType, name string, id influxdb.ID) bool {
+	if !names[name] {
+		return false
+	}
+	r.conflict(kind, name, id, fmt.Sprintf("%s %q already exists", kind, name))
+	return true
+}
+
+func (r *restorer) restoreVariables(ctx context.Context) error {
+	existing, err := r.to.Variables.FindVariables(ctx, influxdb.VariableFilter{OrganizationID: &r.newOrgID})
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(existing))
+	for _, v := range existing {
+		names[v.Name] = true
+	}
+
+	vars, err := r.from.Variables.FindVariables(ctx, influxdb.VariableFilter{OrganizationID: &r.orgID})
+	if err != nil {
+		return err
+	}
+	for _, v := range vars {
+		if r.exists(names, influxdb.VariablesResourceType, v.Name, v.ID) {
+			continue
+		}
+
+		newVar := &influxdb.Variable{
+			OrganizationID: r.newOrgID,
+			Name:           v.Name,
+			Description:    v.Description,
+			Selected:       v.Selected,
+			Arguments:      v.Arguments,
+		}
+		if err := r.to.Variables.CreateVariable(ctx, newVar); err != nil {
+			return err
+		}
+		r.restored(influxdb.VariablesResourceType, v.Name, v.ID, newVar.ID)
+	}
+	return nil
+}
+
+func (r *restorer) restoreDashboards(ctx context.Context) error {
+	existing, _, err := r.to.Dashboards.FindDashboards(ctx, influxdb.DashboardFilter{OrganizationID: &r.newOrgID}, influxdb.FindOptions{})
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(existing))
+	for _, d := range existing {
+		names[d.Name] = true
+	}
+
+	dashboards, _, err := r.from.Dashboards.FindDashboards(ctx, influxdb.DashboardFilter{OrganizationID: &r.orgID}, influxdb.FindOptions{})
+	if err != nil {
+		return err
+	}
+	for _, d := range dashboards {
+		if r.exists(names, influxdb.DashboardsResourceType, d.Name, d.ID) {
+			continue
+		}
+
+		newDash := &influxdb.Dashboard{
+			OrganizationID: r.newOrgID,
+			Name:           d.Name,
+			Description:    d.Description,
+		}
+		for _, c := range d.Cells {
+			view, err := r.from.Dashboards.GetDashboardCellView(ctx, d.ID, c.ID)
+			if err != nil {
+				return err
+			}
+			newDash.Cells = append(newDash.Cells, &influxdb.Cell{
+				CellProperty: c.CellProperty,
+				View: &influxdb.View{
+					ViewContents: influxdb.ViewContents{Name: view.Name},
+					Properties:   view.Properties,
+				},
+			})
+		}
+		if err := r.to.Dashboards.CreateDashboard(ctx, newDash); err != nil {
+			return err
+		}
+		r.restored(influxdb.DashboardsResourceType, d.Name, d.ID, newDash.ID)
+	}
+	return nil
+}
+
+func (r *restorer) restoreTelegrafs(ctx context.Context) error {
+	existing, _, err := r.to.Telegrafs.FindTelegrafConfigs(ctx, influxdb.TelegrafConfigFilter{OrgID: &r.newOrgID})
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(existing))
+	for _, t := range existing {
+		names[t.Name] = true
+	}
+
+	configs, _, err := r.from.Telegrafs.FindTelegrafConfigs(ctx, influxdb.TelegrafConfigFilter{OrgID: &r.orgID})
+	if err != nil {
+		return err
+	}
+	for _, t := range configs {
+		if r.exists(names, influxdb.TelegrafsResourceType, t.Name, t.ID) {
+			continue
+		}
+
+		newConfig := &influxdb.TelegrafConfig{
+			OrgID:       r.newOrgID,
+			Name:        t.Name,
+			Description: t.Description,
+			Config:      t.Config,
+			Metadata:    t.Metadata,
+		}
+		if err := r.to.Telegrafs.CreateTelegrafConfig(ctx, newConfig, r.userID); err != nil {
+			return err
+		}
+		r.restored(influxdb.TelegrafsResourceType, t.Name, t.ID, newConfig.ID)
+	}
+	return nil
+}
+
+// findTasks returns all tasks of the organization orgID.
+func findTasks(ctx context.Context, s influxdb.TaskService, orgID influxdb.ID) ([]*influxdb.Task, error) {
+	var all []*influxdb.Task
+	filter := influxdb.TaskFilter{
+		OrganizationID: &orgID,
+		Limit:          influxdb.TaskMaxPageSize,
+	}
+	for {
+		tasks, _, err := s.FindTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, tasks...)
+		if len(tasks) < filter.Limit {
+			return all, nil
+		}
+		filter.After = &tasks[len(tasks)-1].ID
+	}
+}
+
+// restoreTasks restores the tasks created by users. The tasks of checks and
+// notification rules are restored along with them.
+func (r *restorer) restoreTasks(ctx context.Context) error {
+	existing, err := findTasks(ctx, r.to.Tasks, r.newOrgID)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(existing))
+	for _, t := range existing {
+		if t.Type == influxdb.TaskSystemType {
+			names[t.Name] = true
+		}
+	}
+
+	tasks, err := findTasks(ctx, r.from.Tasks, r.orgID)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		if t.Type != influxdb.TaskSystemType {
+			continue
+		}
+		if r.exists(names, influxdb.TasksResourceType, t.Name, t.ID) {
+			continue
+		}
+
+		newTask, err := r.to.Tasks.CreateTask(ctx, influxdb.TaskCreate{
+			Type:           influxdb.TaskSystemType,
+			Flux:           t.Flux,
+			Description:    t.Description,
+			Status:         t.Status,
+			OrganizationID: r.newOrgID,
+			OwnerID:        r.userID,
+		})
+		if err != nil {
+			return err
+		}
+		r.restored(influxdb.TasksResourceType, t.Name, t.ID, newTask.ID)
+	}
+	return nil
+}
+
+// taskStatus returns the status of the backed-up task of a check or notification rule.
+func (r *restorer) taskStatus(ctx context.Context, taskID influxdb.ID) (influxdb.Status, error) {
+	t, err := r.from.Tasks.FindTaskByID(ctx, taskID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return influxdb.Active, nil
+	} else if err != nil {
+		return "", err
+	}
+	return influxdb.Status(t.Status), nil
+}
+
+func (r *restorer) restoreChecks(ctx context.Context) error {
+	existing, _, err := r.to.Checks.FindChecks(ctx, influxdb.CheckFilter{OrgID: &r.newOrgID})
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(existing))
+	for _, c := range existing {
+		names[c.GetName()] = true
+	}
+
+	checks, _, err := r.from.Checks.FindChecks(ctx, influxdb.CheckFilter{OrgID: &r.orgID})
+	if err != nil {
+		return err
+	}
+	for _, c := range checks {
+		oldID := c.GetID()
+		if r.exists(names, influxdb.ChecksResourceType, c.GetName(), oldID) {
+			continue
+		}
+
+		status, err := r.taskStatus(ctx, c.GetTaskID())
+		if err != nil {
+			return err
+		}
+
+		c.SetID(0)
+		c.SetOrgID(r.newOrgID)
+		c.ClearPrivateData()
+		if err := r.to.Checks.CreateCheck(ctx, influxdb.CheckCreate{Check: c, Status: status}, r.userID); err != nil {
+			return err
+		}
+		r.restored(influxdb.ChecksResourceType, c.GetName(), oldID, c.GetID())
+	}
+	return nil
+}
+
+// restoreNotificationRules restores notification rules, sending to the notification
+// endpoints of the same name in the destination organization. Endpoints are not
+// restored since their secrets are not part of the backup.
+func (r *restorer) restoreNotificationRules(ctx context.Context) error {
+	existing, _, err := r.to.NotificationRules.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{OrgID: &r.newOrgID})
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(existing))
+	for _, nr := range existing {
+		names[nr.GetName()] = true
+	}
+
+	endpoints, _, err := r.to.NotificationEndpoints.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{OrgID: &r.newOrgID})
+	if err != nil {
+		return err
+	}
+	endpointIDs := make(map[string]influxdb.ID, len(endpoints))
+	for _, e := range endpoints {
+		endpointIDs[e.GetName()] = e.GetID()
+	}
+
+	rules, _, err := r.from.NotificationRules.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{OrgID: &r.orgID})
+	if err != nil {
+		return err
+	}
+	for _, nr := range rules {
+		oldID := nr.GetID()
+		if r.exists(names, influxdb.NotificationRuleResourceType, nr.GetName(), oldID) {
+			continue
+		}
+
+		endpoint, err := r.from.NotificationEndpoints.FindNotificationEndpointByID(ctx, nr.GetEndpointID())
+		if err != nil {
+			return err
+		}
+		endpointID, ok := endpointIDs[endpoint.GetName()]
+		if !ok {
+			r.conflict(influxdb.NotificationRuleResourceType, nr.GetName(), oldID,
+				fmt.Sprintf("notification endpoint %q not found", endpoint.GetName()))
+			continue
+		}
+
+		status, err := r.taskStatus(ctx, nr.GetTaskID())
+		if err != nil {
+			return err
+		}
+
+		if err := setEndpointID(nr, endpointID); err != nil {
+			return err
+		}
+		nr.SetID(0)
+		nr.SetOrgID(r.newOrgID)
+		nr.ClearPrivateData()
+		create := influxdb.NotificationRuleCreate{NotificationRule: nr, Status: status}
+		if err := r.to.NotificationRules.CreateNotificationRule(ctx, create, r.userID); err != nil {
+			return err
+		}
+		r.restored(influxdb.NotificationRuleResourceType, nr.GetName(), oldID, nr.GetID())
+	}
+	return nil
+}
+
+func setEndpointID(nr influxdb.NotificationRule, id influxdb.ID) error {
+	switch nr := nr.(type) {
+	case *rule.HTTP:
+		nr.EndpointID = id
+	case *rule.PagerDuty:
+		nr.EndpointID = id
+	case *rule.Slack:
+		nr.EndpointID = id
+	case *rule.Telegram:
+		nr.EndpointID = id
+	default:
+		return fmt.Errorf("unsupported notification rule type %s", nr.Type())
+	}
+	return nil
+}
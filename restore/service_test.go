@@ -0,0 +1,377 @@
+package restore_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/dashboards"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	endpointservice "github.com/influxdata/influxdb/v2/notification/endpoint/service"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	ruleservice "github.com/influxdata/influxdb/v2/notification/rule/service"
+	"github.com/influxdata/influxdb/v2/restore"
+	telegrafservice "github.com/influxdata/influxdb/v2/telegraf/service"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const userID = influxdb.ID(0x1000)
+
+// newTaskService returns a mock task service holding the tasks in memory.
+// The kv task service is not used as it requires the flux parser.
+func newTaskService() *mock.TaskService {
+	var tasks []*influxdb.Task
+	s := mock.NewTaskService()
+	s.FindTaskByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Task, error) {
+		for _, t := range tasks {
+			if t.ID == id {
+				return t, nil
+			}
+		}
+		return nil, influxdb.ErrTaskNotFound
+	}
+	s.FindTasksFn = func(_ context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+		var found []*influxdb.Task
+		for _, t := range tasks {
+			if filter.OrganizationID != nil && t.OrganizationID != *filter.OrganizationID {
+				continue
+			}
+			if filter.After != nil && t.ID <= *filter.After {
+				continue
+			}
+			found = append(found, t)
+		}
+		return found, len(found), nil
+	}
+	s.CreateTaskFn = func(_ context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+		t := &influxdb.Task{
+			ID:             influxdb.ID(len(tasks) + 1),
+			Type:           tc.Type,
+			OrganizationID: tc.OrganizationID,
+			OwnerID:        tc.OwnerID,
+			Name:           tc.Type + " task",
+			Description:    tc.Description,
+			Status:         tc.Status,
+			Flux:           tc.Flux,
+		}
+		tasks = append(tasks, t)
+		return t, nil
+	}
+	s.UpdateTaskFn = func(_ context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+		for _, t := range tasks {
+			if t.ID == id {
+				if upd.Status != nil {
+					t.Status = *upd.Status
+				}
+				return t, nil
+			}
+		}
+		return nil, influxdb.ErrTaskNotFound
+	}
+	s.DeleteTaskFn = func(context.Context, influxdb.ID) error { return nil }
+	return s
+}
+
+// newCheckService returns a mock check service holding the checks in memory.
+// The kv check service is not used as it requires the flux parser.
+func newCheckService(tasks influxdb.TaskService) *mock.CheckService {
+	var checks []influxdb.Check
+	s := mock.NewCheckService()
+	s.FindChecksFn = func(_ context.Context, filter influxdb.CheckFilter, _ ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+		var found []influxdb.Check
+		for _, c := range checks {
+			if filter.OrgID != nil && c.GetOrgID() != *filter.OrgID {
+				continue
+			}
+			found = append(found, c)
+		}
+		return found, len(found), nil
+	}
+	s.CreateCheckFn = func(ctx context.Context, c influxdb.CheckCreate, userID influxdb.ID) error {
+		t, err := tasks.CreateTask(ctx, influxdb.TaskCreate{
+			Type:           c.Type(),
+			OrganizationID: c.GetOrgID(),
+			OwnerID:        userID,
+			Status:         string(c.Status),
+		})
+		if err != nil {
+			return err
+		}
+		c.SetID(influxdb.ID(len(checks) + 1))
+		c.SetOwnerID(userID)
+		c.SetTaskID(t.ID)
+		checks = append(checks, c.Check)
+		return nil
+	}
+	return s
+}
+
+// newServices returns the services of the resources stored in store.
+func newServices(t *testing.T, store kv.Store) restore.Services {
+	t.Helper()
+	log := zaptest.NewLogger(t)
+
+	tenantSvc := tenant.NewService(tenant.NewStore(store))
+	kvSvc := kv.NewService(log, store, tenantSvc)
+	tasks := newTaskService()
+	endpoints := endpointservice.NewStore(store)
+	rules, err := ruleservice.New(log, store, tasks, tenantSvc, endpoints)
+	require.NoError(t, err)
+
+	return restore.Services{
+		Organizations:         tenantSvc,
+		Dashboards:            dashboards.NewService(store, kvSvc),
+		Tasks:                 tasks,
+		Checks:                newCheckService(tasks),
+		NotificationRules:     rules,
+		NotificationEndpoints: endpoints,
+		Variables:             kvSvc,
+		Telegrafs:             telegrafservice.New(store),
+	}
+}
+
+func newStore(t *testing.T) kv.Store {
+	t.Helper()
+	store := inmem.NewKVStore()
+	require.NoError(t, all.Up(context.Background(), zaptest.NewLogger(t), store))
+	return store
+}
+
+func createOrg(t *testing.T, s restore.Services, name string) influxdb.ID {
+	t.Helper()
+	org := &influxdb.Organization{Name: name}
+	require.NoError(t, s.Organizations.CreateOrganization(context.Background(), org))
+	return org.ID
+}
+
+func createEndpoint(t *testing.T, s restore.Services, orgID influxdb.ID, name string) influxdb.ID {
+	t.Helper()
+	e := &endpoint.HTTP{
+		Base:       endpoint.Base{Name: name, OrgID: &orgID, Status: influxdb.Active},
+		URL:        "http://localhost:7777",
+		Method:     "POST",
+		AuthMethod: "none",
+	}
+	require.NoError(t, s.NotificationEndpoints.CreateNotificationEndpoint(context.Background(), e, userID))
+	return e.GetID()
+}
+
+func createRule(t *testing.T, s restore.Services, orgID, endpointID influxdb.ID, name string) influxdb.ID {
+	t.Helper()
+	every, err := notification.FromTimeDuration(time.Hour)
+	require.NoError(t, err)
+
+	r := &rule.HTTP{Base: rule.Base{
+		Name:        name,
+		OrgID:       orgID,
+		EndpointID:  endpointID,
+		Every:       &every,
+		StatusRules: []notification.StatusRule{{CurrentLevel: notification.Critical}},
+	}}
+	require.NoError(t, s.NotificationRules.CreateNotificationRule(context.Background(), influxdb.NotificationRuleCreate{
+		NotificationRule: r,
+		Status:           influxdb.Inactive,
+	}, userID))
+	return r.GetID()
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	from, to := newServices(t, newStore(t)), newServices(t, newStore(t))
+
+	orgID := createOrg(t, from, "org")
+	newOrgID := createOrg(t, to, "new-org")
+
+	// Backed-up resources.
+	require.NoError(t, from.Variables.CreateVariable(ctx, &influxdb.Variable{
+		OrganizationID: orgID,
+		Name:           "v0",
+		Arguments:      &influxdb.VariableArguments{Type: "constant", Values: influxdb.VariableConstantValues{"a"}},
+	}))
+	require.NoError(t, from.Variables.CreateVariable(ctx, &influxdb.Variable{
+		OrganizationID: orgID,
+		Name:           "v1",
+		Arguments:      &influxdb.VariableArguments{Type: "constant", Values: influxdb.VariableConstantValues{"b"}},
+	}))
+	dash := &influxdb.Dashboard{
+		OrganizationID: orgID,
+		Name:           "d0",
+		Cells: []*influxdb.Cell{{
+			CellProperty: influxdb.CellProperty{X: 1, Y: 2, W: 3, H: 4},
+			View: &influxdb.View{
+				ViewContents: influxdb.ViewContents{Name: "notes"},
+				Properties:   influxdb.MarkdownViewProperties{Type: "markdown", Note: "hello"},
+			},
+		}},
+	}
+	require.NoError(t, from.Dashboards.CreateDashboard(ctx, dash))
+	require.NoError(t, from.Telegrafs.CreateTelegrafConfig(ctx, &influxdb.TelegrafConfig{
+		OrgID:  orgID,
+		Name:   "t0",
+		Config: "[agent]",
+	}, userID))
+	_, err := from.Tasks.CreateTask(ctx, influxdb.TaskCreate{
+		Type:           influxdb.TaskSystemType,
+		OrganizationID: orgID,
+		Status:         string(influxdb.Inactive),
+	})
+	require.NoError(t, err)
+	require.NoError(t, from.Checks.CreateCheck(ctx, influxdb.CheckCreate{
+		Check:  &check.Deadman{Base: check.Base{Name: "c0", OrgID: orgID}},
+		Status: influxdb.Inactive,
+	}, userID))
+	ruleID := createRule(t, from, orgID, createEndpoint(t, from, orgID, "e0"), "r0")
+	missingRuleID := createRule(t, from, orgID, createEndpoint(t, from, orgID, "e1"), "r1")
+
+	// Resources of the organization restored to.
+	require.NoError(t, to.Variables.CreateVariable(ctx, &influxdb.Variable{
+		OrganizationID: newOrgID,
+		Name:           "v1",
+		Arguments:      &influxdb.VariableArguments{Type: "constant", Values: influxdb.VariableConstantValues{"c"}},
+	}))
+	newEndpointID := createEndpoint(t, to, newOrgID, "e0")
+
+	report, err := restore.Restore(ctx, from, to, userID, influxdb.RestoreResourcesRequest{
+		Org:    "org",
+		NewOrg: "new-org",
+	})
+	require.NoError(t, err)
+
+	restored := make(map[influxdb.ResourceType][]string)
+	for _, r := range report.Restored {
+		require.True(t, r.NewID.Valid())
+		restored[r.Kind] = append(restored[r.Kind], r.Name)
+	}
+	require.Equal(t, map[influxdb.ResourceType][]string{
+		influxdb.VariablesResourceType:        {"v0"},
+		influxdb.DashboardsResourceType:       {"d0"},
+		influxdb.TelegrafsResourceType:        {"t0"},
+		influxdb.TasksResourceType:            {"system task"},
+		influxdb.ChecksResourceType:           {"c0"},
+		influxdb.NotificationRuleResourceType: {"r0"},
+	}, restored)
+
+	require.Len(t, report.Conflicts, 2)
+	require.Equal(t, influxdb.VariablesResourceType, report.Conflicts[0].Kind)
+	require.Equal(t, "v1", report.Conflicts[0].Name)
+	require.Equal(t, influxdb.RestoreConflict{
+		Kind:   influxdb.NotificationRuleResourceType,
+		Name:   "r1",
+		ID:     missingRuleID,
+		Reason: `notification endpoint "e1" not found`,
+	}, report.Conflicts[1])
+
+	// The views of the cells of dashboards are restored.
+	dashboards, _, err := to.Dashboards.FindDashboards(ctx, influxdb.DashboardFilter{OrganizationID: &newOrgID}, influxdb.FindOptions{})
+	require.NoError(t, err)
+	require.Len(t, dashboards, 1)
+	require.Len(t, dashboards[0].Cells, 1)
+	require.Equal(t, influxdb.CellProperty{X: 1, Y: 2, W: 3, H: 4}, dashboards[0].Cells[0].CellProperty)
+	view, err := to.Dashboards.GetDashboardCellView(ctx, dashboards[0].ID, dashboards[0].Cells[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, "notes", view.Name)
+	require.Equal(t, influxdb.MarkdownViewProperties{Type: "markdown", Note: "hello"}, view.Properties)
+
+	// Tasks keep their status, and the ones of checks and rules are not
+	// restored as tasks on their own.
+	tasks, _, err := to.Tasks.FindTasks(ctx, influxdb.TaskFilter{OrganizationID: &newOrgID})
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+	for _, tk := range tasks {
+		require.Equal(t, string(influxdb.Inactive), tk.Status, tk.Type)
+		require.Equal(t, userID, tk.OwnerID)
+	}
+
+	// Notification rules send to the endpoint of the same name.
+	rules, _, err := to.NotificationRules.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{OrgID: &newOrgID})
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, newEndpointID, rules[0].GetEndpointID())
+	require.NotEqual(t, ruleID, rules[0].GetID())
+}
+
+func TestRestore_Kinds(t *testing.T) {
+	ctx := context.Background()
+	from, to := newServices(t, newStore(t)), newServices(t, newStore(t))
+
+	orgID := createOrg(t, from, "org")
+	createOrg(t, to, "org")
+	require.NoError(t, from.Variables.CreateVariable(ctx, &influxdb.Variable{
+		OrganizationID: orgID,
+		Name:           "v0",
+		Arguments:      &influxdb.VariableArguments{Type: "constant", Values: influxdb.VariableConstantValues{"a"}},
+	}))
+	require.NoError(t, from.Dashboards.CreateDashboard(ctx, &influxdb.Dashboard{OrganizationID: orgID, Name: "d0"}))
+
+	report, err := restore.Restore(ctx, from, to, userID, influxdb.RestoreResourcesRequest{
+		OrgID: orgID,
+		Kinds: []influxdb.ResourceType{influxdb.DashboardsResourceType},
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Restored, 1)
+	require.Equal(t, influxdb.DashboardsResourceType, report.Restored[0].Kind)
+	require.Empty(t, report.Conflicts)
+
+	_, err = restore.Restore(ctx, from, to, userID, influxdb.RestoreResourcesRequest{
+		OrgID: orgID,
+		Kinds: []influxdb.ResourceType{influxdb.BucketsResourceType},
+	})
+	require.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+
+	_, err = restore.Restore(ctx, from, to, userID, influxdb.RestoreResourcesRequest{Org: "missing"})
+	require.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+
+	_, err = restore.Restore(ctx, from, to, userID, influxdb.RestoreResourcesRequest{OrgID: orgID, NewOrg: "missing"})
+	require.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+}
+
+func TestService_RestoreResources(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "restore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Back up a metadata database.
+	path := filepath.Join(dir, "influxd.bolt")
+	store := bolt.NewKVStore(zaptest.NewLogger(t), path)
+	require.NoError(t, store.Open(ctx))
+	require.NoError(t, all.Up(ctx, zaptest.NewLogger(t), store))
+	backup := newServices(t, store)
+	orgID := createOrg(t, backup, "org")
+	require.NoError(t, backup.Dashboards.CreateDashboard(ctx, &influxdb.Dashboard{OrganizationID: orgID, Name: "d0"}))
+	require.NoError(t, store.Close())
+
+	to := newServices(t, newStore(t))
+	newOrgID := createOrg(t, to, "org")
+
+	s := restore.NewService(zaptest.NewLogger(t), to, func(store kv.Store) (restore.Services, error) {
+		return newServices(t, store), nil
+	})
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	report, err := s.RestoreResources(ctx, userID, f, influxdb.RestoreResourcesRequest{Org: "org"})
+	require.NoError(t, err)
+	require.Len(t, report.Restored, 1)
+
+	dashboards, _, err := to.Dashboards.FindDashboards(ctx, influxdb.DashboardFilter{OrganizationID: &newOrgID}, influxdb.FindOptions{})
+	require.NoError(t, err)
+	require.Len(t, dashboards, 1)
+	require.Equal(t, "d0", dashboards[0].Name)
+}
@@ -0,0 +1,48 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// Query languages reported by a RunningQuery.
+const (
+	RunningQueryLanguageFlux     = "flux"
+	RunningQueryLanguageInfluxQL = "influxql"
+)
+
+// ErrRunningQueryNotFound is returned when a running query cannot be found.
+var ErrRunningQueryNotFound = &Error{
+	Code: ENotFound,
+	Msg:  "query not found",
+}
+
+// RunningQuery is a Flux or InfluxQL query that is currently being executed.
+type RunningQuery struct {
+	ID              uint64        `json:"id"`
+	OrgID           ID            `json:"orgID"`
+	UserID          ID            `json:"userID,omitempty"`
+	AuthorizationID ID            `json:"authorizationID,omitempty"`
+	Language        string        `json:"language"`
+	Query           string        `json:"query"`
+	Database        string        `json:"database,omitempty"`
+	StartTime       time.Time     `json:"startTime"`
+	Duration        time.Duration `json:"duration"`
+	State           string        `json:"state"`
+}
+
+// RunningQueryFilter represents a set of filters that restrict the returned running queries.
+type RunningQueryFilter struct {
+	ID    *uint64
+	OrgID *ID
+}
+
+// RunningQueryService lists and kills the queries running on the server.
+type RunningQueryService interface {
+	// FindRunningQueries returns the running queries matching filter,
+	// ordered by ID.
+	FindRunningQueries(ctx context.Context, filter RunningQueryFilter) ([]*RunningQuery, error)
+
+	// KillQuery interrupts the execution of a running query.
+	KillQuery(ctx context.Context, id uint64) error
+}
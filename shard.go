@@ -0,0 +1,45 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ShardGroup is a group of shards holding the data of a bucket for a range of time.
+type ShardGroup struct {
+	ID         uint64    `json:"id"`
+	BucketID   ID        `json:"bucketID"`
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+	ExpiryTime time.Time `json:"expiryTime"`
+	Shards     []Shard   `json:"shards"`
+}
+
+// Shard is a single shard of a shard group.
+type Shard struct {
+	ID     uint64   `json:"id"`
+	Owners []uint64 `json:"owners"`
+
+	// Size is the size of the shard on disk, in bytes.
+	Size int64 `json:"size"`
+}
+
+// ShardGroupFilter represents a set of filters that restrict the returned shard groups.
+type ShardGroupFilter struct {
+	BucketID *ID
+	ShardID  *uint64
+}
+
+// ShardService represents the shard management functions of the storage engine.
+type ShardService interface {
+	// FindShardGroups returns the shard groups matching filter. The shard
+	// groups of a bucket are ordered by start time.
+	FindShardGroups(ctx context.Context, filter ShardGroupFilter) ([]*ShardGroup, error)
+
+	// DeleteShard removes a single shard and its data.
+	DeleteShard(ctx context.Context, shardID uint64) error
+
+	// RebuildShardIndex takes a single shard offline, rebuilds its index from
+	// its TSM and WAL files, and brings it back online.
+	RebuildShardIndex(ctx context.Context, shardID uint64) error
+}
@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultDeleteSeriesPerSecond is the default rate at which a delete job
+	// deletes series.
+	DefaultDeleteSeriesPerSecond = 10000
+
+	// maxFinishedDeleteJobs is the number of finished delete jobs kept.
+	maxFinishedDeleteJobs = 100
+)
+
+// deleter runs and keeps track of the delete jobs of the engine.
+type deleter struct {
+	*jobRegistry
+	metrics *deleteMetrics
+}
+
+type deleteJob struct {
+	j      influxdb.DeleteJob
+	shards []uint64
+}
+
+func newDeleter() *deleter {
+	metrics := newDeleteMetrics()
+	return &deleter{
+		jobRegistry: newJobRegistry(maxFinishedDeleteJobs, metrics.active),
+		metrics:     metrics,
+	}
+}
+
+func (job *deleteJob) jobID() influxdb.ID { return job.j.ID }
+func (job *deleteJob) finished() bool     { return job.j.Status != influxdb.DeleteJobRunning }
+
+// StartDeleteJob starts deleting the points of a bucket in the background, one
+// shard at a time.
+func (e *Engine) StartDeleteJob(ctx context.Context, req influxdb.DeleteJobRequest) (*influxdb.DeleteJob, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	if req.SeriesPerSecond < 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "series per second must not be negative",
+		}
+	} else if req.SeriesPerSecond == 0 {
+		req.SeriesPerSecond = DefaultDeleteSeriesPerSecond
+	}
+
+	shards, err := e.bucketShardIDs(req.BucketID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &deleteJob{
+		j: influxdb.DeleteJob{
+			ID:        e.deleter.nextID(),
+			OrgID:     req.OrgID,
+			BucketID:  req.BucketID,
+			Start:     time.Unix(0, req.Start).UTC(),
+			Stop:      time.Unix(0, req.Stop).UTC(),
+			Predicate: req.Statement,
+			Status:    influxdb.DeleteJobRunning,
+			StartedAt: time.Now().UTC(),
+			Progress:  influxdb.DeleteJobProgress{ShardsTotal: len(shards)},
+			Errors:    []influxdb.DeleteJobError{},
+		},
+		shards: shards,
+	}
+
+	// The job is copied before it starts, while nothing else refers to it.
+	j := job.copyLocked()
+	if err := e.deleter.start(job, func(ctx context.Context) {
+		e.runDeleteJob(ctx, job, req)
+	}); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// runDeleteJob deletes the data of the shards of job, deleting at most
+// req.SeriesPerSecond series per second. The shards which fail are reported
+// in the errors of the job, and do not stop the job.
+func (e *Engine) runDeleteJob(ctx context.Context, job *deleteJob, req influxdb.DeleteJobRequest) {
+	log := e.logger.With(zap.String("delete_job_id", job.j.ID.String()), zap.String("bucket_id", job.j.BucketID.String()))
+	log.Info("Delete job started", zap.Int("shards", len(job.shards)))
+
+	limiter := rate.NewLimiter(rate.Limit(req.SeriesPerSecond), req.SeriesPerSecond)
+	wait := func() error {
+		return limiter.Wait(ctx)
+	}
+
+	for _, id := range job.shards {
+		if ctx.Err() != nil {
+			break
+		}
+
+		n, err := e.tsdbStore.DeleteShardSeriesWithPredicate(id, req.Start, req.Stop, req.Predicate, wait)
+		e.deleter.metrics.series.Add(float64(n))
+		if errors.Is(err, tsdb.ErrShardNotFound) {
+			// The shard has been deleted since the job started.
+			err = nil
+		} else if err != nil && ctx.Err() == nil {
+			log.Info("Failed to delete shard data", zap.Uint64("shard_id", id), zap.Error(err))
+		}
+
+		e.deleter.updateJob(job, func(j *influxdb.DeleteJob) {
+			j.Progress.SeriesDeleted += n
+			if err == nil {
+				j.Progress.ShardsDeleted++
+			} else if ctx.Err() == nil {
+				j.Errors = append(j.Errors, influxdb.DeleteJobError{ShardID: id, Message: err.Error()})
+			}
+		})
+	}
+
+	e.deleter.updateJob(job, func(j *influxdb.DeleteJob) {
+		now := time.Now().UTC()
+		j.FinishedAt = &now
+		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			j.Status = influxdb.DeleteJobCanceled
+		case len(j.Errors) > 0:
+			j.Status = influxdb.DeleteJobFailed
+		default:
+			j.Status = influxdb.DeleteJobSuccess
+		}
+		e.deleter.metrics.jobs.WithLabelValues(j.Status).Inc()
+		log.Info("Delete job finished", zap.String("status", j.Status), zap.Int64("series", j.Progress.SeriesDeleted), zap.Int("errors", len(j.Errors)))
+	})
+}
+
+// updateJob applies fn to the delete job.
+func (d *deleter) updateJob(job *deleteJob, fn func(j *influxdb.DeleteJob)) {
+	d.update(func() {
+		fn(&job.j)
+	})
+}
+
+// copyLocked returns a copy of the delete job.
+func (job *deleteJob) copyLocked() *influxdb.DeleteJob {
+	j := job.j
+	if j.FinishedAt != nil {
+		t := *j.FinishedAt
+		j.FinishedAt = &t
+	}
+	j.Errors = append([]influxdb.DeleteJobError{}, j.Errors...)
+	return &j
+}
+
+// FindDeleteJobs returns the recent delete jobs matching filter, in the order
+// they were started.
+func (e *Engine) FindDeleteJobs(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var jobs []*influxdb.DeleteJob
+	e.deleter.forEach(func(j job) {
+		job := j.(*deleteJob)
+		if filter.OrgID != nil && *filter.OrgID != job.j.OrgID {
+			return
+		}
+		if filter.BucketID != nil && *filter.BucketID != job.j.BucketID {
+			return
+		}
+		jobs = append(jobs, job.copyLocked())
+	})
+	return jobs, nil
+}
+
+// FindDeleteJobByID returns a single delete job by ID.
+func (e *Engine) FindDeleteJobByID(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var dj *influxdb.DeleteJob
+	if !e.deleter.find(id, func(j job) {
+		dj = j.(*deleteJob).copyLocked()
+	}) {
+		return nil, errDeleteJobNotFound
+	}
+	return dj, nil
+}
+
+// CancelDeleteJob cancels a running delete job. The data deleted before the
+// job is canceled stays deleted. Canceling a finished job has no effect.
+func (e *Engine) CancelDeleteJob(ctx context.Context, id influxdb.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if !e.deleter.cancel(id) {
+		return errDeleteJobNotFound
+	}
+	return nil
+}
+
+var errDeleteJobNotFound = &influxdb.Error{
+	Code: influxdb.ENotFound,
+	Msg:  "delete job not found",
+}
+
+// deleteMetrics holds metrics related to delete jobs.
+type deleteMetrics struct {
+	active prometheus.Gauge
+	jobs   *prometheus.CounterVec
+	series prometheus.Counter
+}
+
+func newDeleteMetrics() *deleteMetrics {
+	const (
+		namespace = "storage"
+		subsystem = "delete"
+	)
+
+	return &deleteMetrics{
+		active: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "active_jobs",
+			Help:      "Number of running delete jobs",
+		}),
+
+		jobs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "jobs_total",
+			Help:      "Number of finished delete jobs, by status",
+		}, []string{"status"}),
+
+		series: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "series_total",
+			Help:      "Number of series deleted by delete jobs",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *deleteMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.active,
+		m.jobs,
+		m.series,
+	}
+}
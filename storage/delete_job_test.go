@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_StartDeleteJob(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+	e.writeTestPoints(t, bucketID)
+
+	j, err := e.StartDeleteJob(context.Background(), influxdb.DeleteJobRequest{
+		OrgID:    10,
+		BucketID: bucketID,
+		Start:    math.MinInt64,
+		Stop:     math.MaxInt64,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, influxdb.DeleteJobRunning, j.Status)
+	assert.Nil(t, j.FinishedAt)
+	assert.Equal(t, 1, j.Progress.ShardsTotal)
+
+	j = e.waitDeleteJob(t, j.ID)
+	assert.Equal(t, influxdb.DeleteJobSuccess, j.Status)
+	assert.NotNil(t, j.FinishedAt)
+	assert.Empty(t, j.Errors)
+	assert.Equal(t, 1, j.Progress.ShardsDeleted)
+	assert.Equal(t, int64(2), j.Progress.SeriesDeleted)
+
+	names, err := e.tsdbStore.MeasurementNames(nil, bucketID.String(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	orgID, otherID := influxdb.ID(10), influxdb.ID(2)
+	for _, tt := range []struct {
+		filter influxdb.DeleteJobFilter
+		n      int
+	}{
+		{filter: influxdb.DeleteJobFilter{}, n: 1},
+		{filter: influxdb.DeleteJobFilter{OrgID: &orgID}, n: 1},
+		{filter: influxdb.DeleteJobFilter{BucketID: &bucketID}, n: 1},
+		{filter: influxdb.DeleteJobFilter{OrgID: &otherID}, n: 0},
+		{filter: influxdb.DeleteJobFilter{BucketID: &otherID}, n: 0},
+	} {
+		jobs, err := e.FindDeleteJobs(context.Background(), tt.filter)
+		require.NoError(t, err)
+		require.Len(t, jobs, tt.n)
+		if tt.n > 0 {
+			assert.Equal(t, j, jobs[0])
+		}
+	}
+}
+
+func TestEngine_StartDeleteJob_Failed(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+	e.writeTestPoints(t, bucketID)
+
+	// The data of a closed shard cannot be deleted.
+	ids, err := e.bucketShardIDs(bucketID, nil)
+	require.NoError(t, err)
+	require.NoError(t, e.tsdbStore.Shard(ids[0]).Close())
+
+	j, err := e.StartDeleteJob(context.Background(), influxdb.DeleteJobRequest{BucketID: bucketID, Stop: math.MaxInt64})
+	require.NoError(t, err)
+
+	j = e.waitDeleteJob(t, j.ID)
+	assert.Equal(t, influxdb.DeleteJobFailed, j.Status)
+	assert.Zero(t, j.Progress.ShardsDeleted)
+	require.Len(t, j.Errors, 1)
+	assert.Equal(t, ids[0], j.Errors[0].ShardID)
+}
+
+func TestEngine_StartDeleteJob_NotFound(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+
+	_, err := e.StartDeleteJob(context.Background(), influxdb.DeleteJobRequest{BucketID: 2})
+	assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+
+	_, err = e.StartDeleteJob(context.Background(), influxdb.DeleteJobRequest{BucketID: bucketID, SeriesPerSecond: -1})
+	assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+
+	_, err = e.FindDeleteJobByID(context.Background(), 1)
+	assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+	assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(e.CancelDeleteJob(context.Background(), 1)))
+}
+
+func TestEngine_StartDeleteJob_Cancel(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+	e.writeTestPoints(t, bucketID)
+
+	// Deleting the second series waits a second at one series per second.
+	j, err := e.StartDeleteJob(context.Background(), influxdb.DeleteJobRequest{BucketID: bucketID, Stop: math.MaxInt64, SeriesPerSecond: 1})
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	j, err = e.FindDeleteJobByID(context.Background(), j.ID)
+	require.NoError(t, err)
+	assert.Equal(t, influxdb.DeleteJobRunning, j.Status)
+	assert.Zero(t, j.Progress.ShardsDeleted)
+	assert.Equal(t, float64(1), testutil.ToFloat64(e.deleter.metrics.active))
+
+	require.NoError(t, e.CancelDeleteJob(context.Background(), j.ID))
+	j = e.waitDeleteJob(t, j.ID)
+	assert.Equal(t, influxdb.DeleteJobCanceled, j.Status)
+	assert.NotNil(t, j.FinishedAt)
+	assert.Empty(t, j.Errors)
+	assert.Zero(t, j.Progress.ShardsDeleted)
+
+	// Canceling a finished job has no effect.
+	require.NoError(t, e.CancelDeleteJob(context.Background(), j.ID))
+	j2, err := e.FindDeleteJobByID(context.Background(), j.ID)
+	require.NoError(t, err)
+	assert.Equal(t, j, j2)
+}
+
+func TestEngine_StartDeleteJob_Close(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+	e.writeTestPoints(t, bucketID)
+
+	j, err := e.StartDeleteJob(context.Background(), influxdb.DeleteJobRequest{BucketID: bucketID, Stop: math.MaxInt64, SeriesPerSecond: 1})
+	require.NoError(t, err)
+
+	// Closing the engine cancels the running delete jobs.
+	require.NoError(t, e.Close())
+	j, err = e.FindDeleteJobByID(context.Background(), j.ID)
+	require.NoError(t, err)
+	assert.Equal(t, influxdb.DeleteJobCanceled, j.Status)
+
+	_, err = e.StartDeleteJob(context.Background(), influxdb.DeleteJobRequest{BucketID: bucketID})
+	assert.Equal(t, ErrEngineClosed, err)
+}
+
+func TestEngine_StartDeleteJob_Prune(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+
+	// The delete jobs of a bucket without shards finish immediately.
+	var ids []influxdb.ID
+	for i := 0; i < maxFinishedDeleteJobs+2; i++ {
+		j, err := e.StartDeleteJob(context.Background(), influxdb.DeleteJobRequest{BucketID: bucketID})
+		require.NoError(t, err)
+		e.waitDeleteJob(t, j.ID)
+		ids = append(ids, j.ID)
+	}
+
+	// The oldest job is pruned when the last one starts, which keeps the last
+	// one and the finished jobs before it.
+	jobs, err := e.FindDeleteJobs(context.Background(), influxdb.DeleteJobFilter{})
+	require.NoError(t, err)
+	require.Len(t, jobs, maxFinishedDeleteJobs+1)
+	for i, j := range jobs {
+		assert.Equal(t, ids[i+1], j.ID)
+	}
+
+	_, err = e.FindDeleteJobByID(context.Background(), ids[0])
+	assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+}
+
+// waitDeleteJob waits for a delete job to finish, and returns it.
+func (e *testEngine) waitDeleteJob(t *testing.T, id influxdb.ID) *influxdb.DeleteJob {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		j, err := e.FindDeleteJobByID(context.Background(), id)
+		require.NoError(t, err)
+		if j.Status != influxdb.DeleteJobRunning {
+			return j
+		} else if time.Now().After(deadline) {
+			t.Fatalf("delete job %s still running", id)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
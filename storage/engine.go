@@ -12,8 +12,10 @@ import (
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	_ "github.com/influxdata/influxdb/v2/tsdb/engine"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
 	_ "github.com/influxdata/influxdb/v2/tsdb/index/inmem"
 	_ "github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
 	"github.com/influxdata/influxdb/v2/v1/coordinator"
@@ -46,11 +48,15 @@ type Engine struct {
 	metaClient   MetaClient
 	pointsWriter interface {
 		WritePoints(database, retentionPolicy string, consistencyLevel models.ConsistencyLevel, user meta.User, points []models.Point) error
+		WritePointsInto(*coordinator.IntoWriteRequest) error
 		Close() error
 	}
+	writeSubscribers []chan<- *coordinator.WritePointsRequest
 
 	retentionService  *retention.Service
 	precreatorService *precreator.Service
+	verifier          *verifier
+	deleter           *deleter
 
 	defaultMetricLabels prometheus.Labels
 
@@ -68,12 +74,21 @@ func WithMetaClient(c MetaClient) Option {
 	}
 }
 
+// WithWriteSubscriber sends every write to the engine to c, as long as c is
+// ready to receive it. Writes which would block are dropped.
+func WithWriteSubscriber(c chan<- *coordinator.WritePointsRequest) Option {
+	return func(e *Engine) {
+		e.writeSubscribers = append(e.writeSubscribers, c)
+	}
+}
+
 type MetaClient interface {
 	CreateDatabaseWithRetentionPolicy(name string, spec *meta.RetentionPolicySpec) (*meta.DatabaseInfo, error)
 	CreateShardGroup(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error)
 	Database(name string) (di *meta.DatabaseInfo)
 	Databases() []meta.DatabaseInfo
 	DeleteShardGroup(database, policy string, id uint64) error
+	DropShard(id uint64) error
 	PrecreateShardGroups(now, cutoff time.Time) error
 	PruneShardGroups() error
 	RetentionPolicy(database, policy string) (*meta.RetentionPolicyInfo, error)
@@ -89,6 +104,8 @@ type TSDBStore interface {
 	DeleteMeasurement(database, name string) error
 	DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error
 	MeasurementNames(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error)
+	MeasurementsSketches(database string) (estimator.Sketch, estimator.Sketch, error)
+	SeriesSketches(database string) (estimator.Sketch, estimator.Sketch, error)
 	ShardGroup(ids []uint64) tsdb.ShardGroup
 	Shards(ids []uint64) []*tsdb.Shard
 	TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
@@ -106,6 +123,8 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 		path:                path,
 		defaultMetricLabels: prometheus.Labels{},
 		tsdbStore:           tsdb.NewStore(c.Data.Dir),
+		verifier:            newVerifier(),
+		deleter:             newDeleter(),
 		logger:              zap.NewNop(),
 
 		writePointsValidationEnabled: true,
@@ -124,6 +143,9 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 	pw := coordinator.NewPointsWriter()
 	pw.TSDBStore = e.tsdbStore
 	pw.MetaClient = e.metaClient
+	for _, c := range e.writeSubscribers {
+		pw.AddWriteSubscriber(c)
+	}
 	e.pointsWriter = pw
 
 	e.retentionService = retention.NewService(c.RetentionService)
@@ -159,7 +181,29 @@ func (e *Engine) WithLogger(log *zap.Logger) {
 // PrometheusCollectors returns all the prometheus collectors associated with
 // the engine and its components.
 func (e *Engine) PrometheusCollectors() []prometheus.Collector {
-	return nil
+	var metrics []prometheus.Collector
+	metrics = append(metrics, e.verifier.metrics.PrometheusCollectors()...)
+	metrics = append(metrics, e.deleter.metrics.PrometheusCollectors()...)
+	return metrics
+}
+
+// Statistics returns the statistics of the storage engine and of the points
+// writer for periodic monitoring. The given tags are added to each statistic.
+func (e *Engine) Statistics(tags map[string]string) []models.Statistic {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return nil
+	}
+
+	statistics := e.tsdbStore.Statistics(tags)
+	if r, ok := e.pointsWriter.(interface {
+		Statistics(tags map[string]string) []models.Statistic
+	}); ok {
+		statistics = append(statistics, r.Statistics(tags)...)
+	}
+	return statistics
 }
 
 // Open opens the store and all underlying resources. It returns an error if
@@ -187,6 +231,8 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 		return err
 	}
 
+	e.verifier.open()
+	e.deleter.open()
 	e.closing = make(chan struct{})
 
 	return nil
@@ -214,6 +260,11 @@ func (e *Engine) Close() error {
 	close(e.closing)
 	e.mu.RUnlock()
 
+	// Stop the storage verifications and delete jobs before the shards they
+	// work on are closed.
+	e.verifier.close()
+	e.deleter.close()
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.closing = nil
@@ -261,6 +312,19 @@ func (e *Engine) WritePoints(ctx context.Context, orgID influxdb.ID, bucketID in
 	return e.pointsWriter.WritePoints(bucketID.String(), meta.DefaultRetentionPolicyName, models.ConsistencyLevelAll, &meta.UserInfo{}, points)
 }
 
+// WritePointsInto writes the points produced by an InfluxQL SELECT ... INTO
+// statement to the engine.
+func (e *Engine) WritePointsInto(req *coordinator.IntoWriteRequest) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	return e.pointsWriter.WritePointsInto(req)
+}
+
 func (e *Engine) CreateBucket(ctx context.Context, b *influxdb.Bucket) (err error) {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
@@ -313,6 +377,125 @@ func (e *Engine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID
 	return e.tsdbStore.DeleteSeriesWithPredicate(bucketID.String(), min, max, pred)
 }
 
+// FindShardGroups returns the shard groups of all buckets matching filter.
+// Shard groups which have been deleted are not returned.
+func (e *Engine) FindShardGroups(ctx context.Context, filter influxdb.ShardGroupFilter) ([]*influxdb.ShardGroup, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	var groups []*influxdb.ShardGroup
+	for _, di := range e.metaClient.Databases() {
+		bucketID, err := influxdb.IDFromString(di.Name)
+		if err != nil {
+			// Only databases named after a bucket hold bucket data.
+			continue
+		} else if filter.BucketID != nil && *filter.BucketID != *bucketID {
+			continue
+		}
+
+		for _, rpi := range di.RetentionPolicies {
+			for _, sgi := range rpi.ShardGroups {
+				// Shards associated with deleted shard groups are effectively deleted.
+				if sgi.Deleted() {
+					continue
+				}
+
+				group := &influxdb.ShardGroup{
+					ID:         sgi.ID,
+					BucketID:   *bucketID,
+					StartTime:  sgi.StartTime.UTC(),
+					EndTime:    sgi.EndTime.UTC(),
+					ExpiryTime: sgi.EndTime.Add(rpi.Duration).UTC(),
+				}
+				for _, si := range sgi.Shards {
+					if filter.ShardID != nil && *filter.ShardID != si.ID {
+						continue
+					}
+
+					shard := influxdb.Shard{ID: si.ID, Owners: make([]uint64, len(si.Owners))}
+					for i, owner := range si.Owners {
+						shard.Owners[i] = owner.NodeID
+					}
+					if sh := e.tsdbStore.Shard(si.ID); sh != nil {
+						if shard.Size, err = sh.DiskSize(); err != nil {
+							return nil, err
+						}
+					}
+					group.Shards = append(group.Shards, shard)
+				}
+
+				if filter.ShardID != nil && len(group.Shards) == 0 {
+					continue
+				}
+				groups = append(groups, group)
+			}
+		}
+	}
+	return groups, nil
+}
+
+// DeleteShard removes a shard from the meta data and deletes its data.
+func (e *Engine) DeleteShard(ctx context.Context, shardID uint64) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	// Remove the shard reference from the meta data.
+	if err := e.metaClient.DropShard(shardID); err != nil {
+		return err
+	}
+	// Locally delete the shard.
+	return e.tsdbStore.DeleteShard(shardID)
+}
+
+// RebuildShardIndex takes a single shard offline, rebuilds its tsi1 index from
+// its TSM and WAL files, and brings it back online. Series missing from the
+// series file of the bucket are added back to it.
+func (e *Engine) RebuildShardIndex(ctx context.Context, shardID uint64) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	log := e.logger.With(zap.Uint64("shard_id", shardID))
+	err := e.tsdbStore.RebuildShardIndex(shardID, func(sfile *tsdb.SeriesFile, path, walPath string) error {
+		return tsm1.IndexShard(sfile, path, walPath,
+			int64(e.config.Data.MaxIndexLogFileSize), uint64(e.config.Data.CacheMaxMemorySize),
+			tsm1.DefaultIndexBatchSize, log, false)
+	})
+	switch err {
+	case tsdb.ErrShardNotFound:
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "shard not found",
+		}
+	case tsdb.ErrShardIndexRebuild:
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  err.Error(),
+		}
+	}
+	return err
+}
+
 func (e *Engine) BackupKVStore(ctx context.Context, w io.Writer) error {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
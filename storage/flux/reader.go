@@ -7,6 +7,7 @@ import (
 
 	"github.com/gogo/protobuf/types"
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/plan"
@@ -113,6 +114,51 @@ func (r *storeReader) ReadTagValues(ctx context.Context, spec query.ReadTagValue
 	}, nil
 }
 
+func (r *storeReader) ReadMeasurementNames(ctx context.Context, spec query.ReadMeasurementNamesSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return &measurementNamesIterator{
+		ctx:      ctx,
+		s:        r.s,
+		readSpec: spec,
+		alloc:    alloc,
+	}, nil
+}
+
+func (r *storeReader) ReadMeasurementTagKeys(ctx context.Context, spec query.ReadMeasurementTagKeysSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return &measurementTagKeysIterator{
+		ctx:      ctx,
+		s:        r.s,
+		readSpec: spec,
+		alloc:    alloc,
+	}, nil
+}
+
+func (r *storeReader) ReadMeasurementTagValues(ctx context.Context, spec query.ReadMeasurementTagValuesSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return &measurementTagValuesIterator{
+		ctx:      ctx,
+		s:        r.s,
+		readSpec: spec,
+		alloc:    alloc,
+	}, nil
+}
+
+func (r *storeReader) ReadMeasurementFields(ctx context.Context, spec query.ReadMeasurementFieldsSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return &measurementFieldsIterator{
+		ctx:      ctx,
+		s:        r.s,
+		readSpec: spec,
+		alloc:    alloc,
+	}, nil
+}
+
+func (r *storeReader) ReadSeriesCardinality(ctx context.Context, spec query.ReadSeriesCardinalitySpec, alloc *memory.Allocator) (query.TableIterator, error) {
+	return &seriesCardinalityIterator{
+		ctx:      ctx,
+		s:        r.s,
+		readSpec: spec,
+		alloc:    alloc,
+	}, nil
+}
+
 func (r *storeReader) Close() {}
 
 type filterIterator struct {
@@ -649,7 +695,11 @@ func (wai *windowAggregateIterator) Do(f func(flux.Table) error) error {
 		if agg, err := determineAggregateMethod(string(aggKind)); err != nil {
 			return err
 		} else if agg != datatypes.AggregateTypeNone {
-			req.Aggregate[i] = &datatypes.Aggregate{Type: agg}
+			req.Aggregate[i] = &datatypes.Aggregate{
+				Type:        agg,
+				Quantile:    wai.spec.Quantile,
+				Compression: wai.spec.Compression,
+			}
 		}
 	}
 
@@ -863,8 +913,14 @@ func (ti *tagKeysIterator) Do(f func(flux.Table) error) error {
 }
 
 func (ti *tagKeysIterator) handleRead(f func(flux.Table) error, rs cursors.StringIterator) error {
+	return handleReadTagKeys(f, rs, ti.alloc)
+}
+
+// handleReadTagKeys produces a single table with the tag keys of rs, and the
+// _start and _stop columns, in the _value column.
+func handleReadTagKeys(f func(flux.Table) error, rs cursors.StringIterator, alloc *memory.Allocator) error {
 	key := execute.NewGroupKey(nil, nil)
-	builder := execute.NewColListTableBuilder(key, ti.alloc)
+	builder := execute.NewColListTableBuilder(key, alloc)
 	valueIdx, err := builder.AddCol(flux.ColMeta{
 		Label: execute.DefaultValueColLabel,
 		Type:  flux.TString,
@@ -954,8 +1010,14 @@ func (ti *tagValuesIterator) Do(f func(flux.Table) error) error {
 }
 
 func (ti *tagValuesIterator) handleRead(f func(flux.Table) error, rs cursors.StringIterator) error {
+	return handleReadStringValues(f, rs, ti.alloc)
+}
+
+// handleReadStringValues produces a single table with the values of rs in
+// the _value column.
+func handleReadStringValues(f func(flux.Table) error, rs cursors.StringIterator, alloc *memory.Allocator) error {
 	key := execute.NewGroupKey(nil, nil)
-	builder := execute.NewColListTableBuilder(key, ti.alloc)
+	builder := execute.NewColListTableBuilder(key, alloc)
 	valueIdx, err := builder.AddCol(flux.ColMeta{
 		Label: execute.DefaultValueColLabel,
 		Type:  flux.TString,
@@ -986,3 +1048,235 @@ func (ti *tagValuesIterator) handleRead(f func(flux.Table) error, rs cursors.Str
 func (ti *tagValuesIterator) Statistics() cursors.CursorStats {
 	return cursors.CursorStats{}
 }
+
+// newReadSource returns the source of the bucket of spec for a storage request.
+func newReadSource(s storage.Store, spec query.ReadFilterSpec) (*types.Any, error) {
+	src := s.GetSource(
+		uint64(spec.OrganizationID),
+		uint64(spec.BucketID),
+	)
+	return types.MarshalAny(src)
+}
+
+type measurementNamesIterator struct {
+	ctx      context.Context
+	s        storage.Store
+	readSpec query.ReadMeasurementNamesSpec
+	alloc    *memory.Allocator
+}
+
+func (mi *measurementNamesIterator) Do(f func(flux.Table) error) error {
+	any, err := newReadSource(mi.s, mi.readSpec.ReadFilterSpec)
+	if err != nil {
+		return err
+	}
+
+	var req datatypes.MeasurementNamesRequest
+	req.Source = any
+	req.Predicate = mi.readSpec.Predicate
+	req.Range.Start = int64(mi.readSpec.Bounds.Start)
+	req.Range.End = int64(mi.readSpec.Bounds.Stop)
+
+	rs, err := mi.s.MeasurementNames(mi.ctx, &req)
+	if err != nil {
+		return err
+	}
+	return handleReadStringValues(f, rs, mi.alloc)
+}
+
+func (mi *measurementNamesIterator) Statistics() cursors.CursorStats {
+	return cursors.CursorStats{}
+}
+
+type measurementTagKeysIterator struct {
+	ctx      context.Context
+	s        storage.Store
+	readSpec query.ReadMeasurementTagKeysSpec
+	alloc    *memory.Allocator
+}
+
+func (mi *measurementTagKeysIterator) Do(f func(flux.Table) error) error {
+	any, err := newReadSource(mi.s, mi.readSpec.ReadFilterSpec)
+	if err != nil {
+		return err
+	}
+
+	var req datatypes.MeasurementTagKeysRequest
+	req.Source = any
+	req.Measurement = mi.readSpec.Measurement
+	req.Predicate = mi.readSpec.Predicate
+	req.Range.Start = int64(mi.readSpec.Bounds.Start)
+	req.Range.End = int64(mi.readSpec.Bounds.Stop)
+
+	rs, err := mi.s.MeasurementTagKeys(mi.ctx, &req)
+	if err != nil {
+		return err
+	}
+	return handleReadTagKeys(f, rs, mi.alloc)
+}
+
+func (mi *measurementTagKeysIterator) Statistics() cursors.CursorStats {
+	return cursors.CursorStats{}
+}
+
+type measurementTagValuesIterator struct {
+	ctx      context.Context
+	s        storage.Store
+	readSpec query.ReadMeasurementTagValuesSpec
+	alloc    *memory.Allocator
+}
+
+func (mi *measurementTagValuesIterator) Do(f func(flux.Table) error) error {
+	any, err := newReadSource(mi.s, mi.readSpec.ReadFilterSpec)
+	if err != nil {
+		return err
+	}
+
+	var req datatypes.MeasurementTagValuesRequest
+	req.Source = any
+	req.Measurement = mi.readSpec.Measurement
+	req.TagKey = mi.readSpec.TagKey
+	req.Predicate = mi.readSpec.Predicate
+	req.Range.Start = int64(mi.readSpec.Bounds.Start)
+	req.Range.End = int64(mi.readSpec.Bounds.Stop)
+
+	rs, err := mi.s.MeasurementTagValues(mi.ctx, &req)
+	if err != nil {
+		return err
+	}
+	return handleReadStringValues(f, rs, mi.alloc)
+}
+
+func (mi *measurementTagValuesIterator) Statistics() cursors.CursorStats {
+	return cursors.CursorStats{}
+}
+
+type measurementFieldsIterator struct {
+	ctx      context.Context
+	s        storage.Store
+	readSpec query.ReadMeasurementFieldsSpec
+	alloc    *memory.Allocator
+}
+
+func (mi *measurementFieldsIterator) Do(f func(flux.Table) error) error {
+	any, err := newReadSource(mi.s, mi.readSpec.ReadFilterSpec)
+	if err != nil {
+		return err
+	}
+
+	var req datatypes.MeasurementFieldsRequest
+	req.Source = any
+	req.Measurement = mi.readSpec.Measurement
+	req.Predicate = mi.readSpec.Predicate
+	req.Range.Start = int64(mi.readSpec.Bounds.Start)
+	req.Range.End = int64(mi.readSpec.Bounds.Stop)
+
+	rs, err := mi.s.MeasurementFields(mi.ctx, &req)
+	if err != nil {
+		return err
+	}
+	return handleReadStringValues(f, rs, mi.alloc)
+}
+
+func (mi *measurementFieldsIterator) Statistics() cursors.CursorStats {
+	return cursors.CursorStats{}
+}
+
+type seriesCardinalityIterator struct {
+	ctx      context.Context
+	s        storage.Store
+	readSpec query.ReadSeriesCardinalitySpec
+	alloc    *memory.Allocator
+}
+
+// Do produces a single table with the number of series in the _value column,
+// or no table if there are no series, like counting the rows of the series
+// after grouping them together. As grouping them does, it fails if the values
+// of the series have different types.
+func (si *seriesCardinalityIterator) Do(f func(flux.Table) error) error {
+	any, err := newReadSource(si.s, si.readSpec.ReadFilterSpec)
+	if err != nil {
+		return err
+	}
+
+	var req datatypes.ReadFilterRequest
+	req.ReadSource = any
+	req.Predicate = si.readSpec.Predicate
+	req.Range.Start = int64(si.readSpec.Bounds.Start)
+	req.Range.End = int64(si.readSpec.Bounds.Stop)
+
+	counts, err := si.s.SeriesCardinality(si.ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	var (
+		n   int64
+		typ flux.ColType
+	)
+	for _, dt := range []datatypes.ReadResponse_DataType{
+		datatypes.DataTypeFloat,
+		datatypes.DataTypeInteger,
+		datatypes.DataTypeUnsigned,
+		datatypes.DataTypeBoolean,
+		datatypes.DataTypeString,
+	} {
+		if counts[dt] == 0 {
+			continue
+		}
+		if n > 0 {
+			return &flux.Error{
+				Code: codes.FailedPrecondition,
+				Msg:  fmt.Sprintf("schema collision detected: column \"%s\" is both of type %s and %s", execute.DefaultValueColLabel, dataTypeColType(dt), typ),
+			}
+		}
+		n, typ = counts[dt], dataTypeColType(dt)
+	}
+	if n == 0 {
+		return nil
+	}
+
+	key := execute.NewGroupKey(nil, nil)
+	builder := execute.NewColListTableBuilder(key, si.alloc)
+	valueIdx, err := builder.AddCol(flux.ColMeta{
+		Label: execute.DefaultValueColLabel,
+		Type:  flux.TInt,
+	})
+	if err != nil {
+		return err
+	}
+	defer builder.ClearData()
+
+	if err := builder.AppendInt(valueIdx, n); err != nil {
+		return err
+	}
+
+	tbl, err := builder.Table()
+	if err != nil {
+		return err
+	}
+	builder.ClearData()
+	return f(tbl)
+}
+
+func (si *seriesCardinalityIterator) Statistics() cursors.CursorStats {
+	return cursors.CursorStats{}
+}
+
+// dataTypeColType returns the column type of the values of a data type.
+func dataTypeColType(dt datatypes.ReadResponse_DataType) flux.ColType {
+	switch dt {
+	case datatypes.DataTypeFloat:
+		return flux.TFloat
+	case datatypes.DataTypeInteger:
+		return flux.TInt
+	case datatypes.DataTypeUnsigned:
+		return flux.TUInt
+	case datatypes.DataTypeBoolean:
+		return flux.TBool
+	case datatypes.DataTypeString:
+		return flux.TString
+	default:
+		return flux.TInvalid
+	}
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/snowflake"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// job is a background job of the engine, such as a storage verification or a
+// delete job, kept by a jobRegistry.
+type job interface {
+	// jobID returns the ID of the job.
+	jobID() influxdb.ID
+
+	// finished reports whether the job has finished. It is called with the
+	// lock of the registry held.
+	finished() bool
+}
+
+// jobRegistry runs and keeps track of the background jobs of a kind. It keeps
+// the running jobs, and the most recent finished jobs up to maxFinished.
+type jobRegistry struct {
+	mu      sync.Mutex
+	entries []*jobEntry
+	closed  bool
+	wg      sync.WaitGroup
+
+	maxFinished int
+	idGen       influxdb.IDGenerator
+	active      prometheus.Gauge
+}
+
+type jobEntry struct {
+	job    job
+	cancel context.CancelFunc
+}
+
+// newJobRegistry returns a registry keeping maxFinished finished jobs, and
+// counting its running jobs with active.
+func newJobRegistry(maxFinished int, active prometheus.Gauge) *jobRegistry {
+	return &jobRegistry{
+		maxFinished: maxFinished,
+		idGen:       snowflake.NewDefaultIDGenerator(),
+		active:      active,
+	}
+}
+
+// open allows jobs to run.
+func (r *jobRegistry) open() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = false
+}
+
+// close cancels the running jobs and waits for them to stop.
+func (r *jobRegistry) close() {
+	r.mu.Lock()
+	r.closed = true
+	for _, entry := range r.entries {
+		entry.cancel()
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+}
+
+// nextID returns the ID of a new job.
+func (r *jobRegistry) nextID() influxdb.ID {
+	return r.idGen.ID()
+}
+
+// start adds j to the jobs and calls run in the background, with a context
+// canceled when the job is canceled or the registry is closed. It returns
+// ErrEngineClosed if the registry is closed.
+func (r *jobRegistry) start(j job, run func(ctx context.Context)) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		cancel()
+		return ErrEngineClosed
+	}
+	r.entries = append(r.entries, &jobEntry{job: j, cancel: cancel})
+	r.pruneLocked()
+	r.wg.Add(1)
+	r.mu.Unlock()
+
+	r.active.Inc()
+	go func() {
+		defer r.wg.Done()
+		defer r.active.Dec()
+		defer cancel()
+		run(ctx)
+	}()
+	return nil
+}
+
+// update calls fn with the lock held, to update the state of a job.
+func (r *jobRegistry) update(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn()
+}
+
+// forEach calls fn with each job, in the order they were started, with the
+// lock held.
+func (r *jobRegistry) forEach(fn func(j job)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range r.entries {
+		fn(entry.job)
+	}
+}
+
+// find calls fn with the job with the given ID, with the lock held. It
+// reports whether the job was found.
+func (r *jobRegistry) find(id influxdb.ID, fn func(j job)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := r.findLocked(id)
+	if entry == nil {
+		return false
+	}
+	fn(entry.job)
+	return true
+}
+
+// cancel cancels the job with the given ID. Canceling a finished job has no
+// effect. It reports whether the job was found.
+func (r *jobRegistry) cancel(id influxdb.ID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := r.findLocked(id)
+	if entry == nil {
+		return false
+	}
+	entry.cancel()
+	return true
+}
+
+func (r *jobRegistry) findLocked(id influxdb.ID) *jobEntry {
+	for _, entry := range r.entries {
+		if entry.job.jobID() == id {
+			return entry
+		}
+	}
+	return nil
+}
+
+// pruneLocked removes the oldest finished jobs beyond the number kept.
+func (r *jobRegistry) pruneLocked() {
+	var finished int
+	for _, entry := range r.entries {
+		if entry.job.finished() {
+			finished++
+		}
+	}
+
+	entries := r.entries[:0]
+	for _, entry := range r.entries {
+		if finished > r.maxFinished && entry.job.finished() {
+			finished--
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	r.entries = entries
+}
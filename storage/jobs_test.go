@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/influxdata/influxdb/v2/v1/services/meta"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type testJob struct {
+	id   influxdb.ID
+	done bool
+}
+
+func (j *testJob) jobID() influxdb.ID { return j.id }
+func (j *testJob) finished() bool     { return j.done }
+
+func TestJobRegistry(t *testing.T) {
+	active := prometheus.NewGauge(prometheus.GaugeOpts{Name: "active"})
+	r := newJobRegistry(2, active)
+	r.open()
+
+	// A job runs until it is canceled.
+	j := &testJob{id: r.nextID()}
+	started, stopped := make(chan struct{}), make(chan struct{})
+	require.NoError(t, r.start(j, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		r.update(func() { j.done = true })
+		close(stopped)
+	}))
+	<-started
+	assert.Equal(t, float64(1), testutil.ToFloat64(active))
+
+	var found job
+	assert.True(t, r.find(j.id, func(j job) { found = j }))
+	assert.Equal(t, j, found)
+	assert.False(t, r.find(j.id+1, func(job) { t.Fatal("unexpected job") }))
+	assert.False(t, r.cancel(j.id+1))
+
+	assert.True(t, r.cancel(j.id))
+	<-stopped
+
+	// Closing the registry waits for the jobs, and prevents new ones.
+	r.close()
+	assert.Equal(t, float64(0), testutil.ToFloat64(active))
+	assert.Equal(t, ErrEngineClosed, r.start(&testJob{id: r.nextID()}, func(context.Context) {
+		t.Fatal("unexpected run")
+	}))
+
+	// Opening it again allows new jobs.
+	r.open()
+	defer r.close()
+	require.NoError(t, r.start(&testJob{id: r.nextID(), done: true}, func(context.Context) {}))
+}
+
+func TestJobRegistry_Prune(t *testing.T) {
+	r := newJobRegistry(2, prometheus.NewGauge(prometheus.GaugeOpts{Name: "active"}))
+	for i, done := range []bool{true, false, true, true, false, true} {
+		r.entries = append(r.entries, &jobEntry{job: &testJob{id: influxdb.ID(i + 1), done: done}})
+	}
+
+	// Running jobs are kept however old they are, and only the most recent
+	// finished jobs are.
+	r.pruneLocked()
+	var ids []influxdb.ID
+	r.forEach(func(j job) { ids = append(ids, j.jobID()) })
+	assert.Equal(t, []influxdb.ID{2, 4, 5, 6}, ids)
+}
+
+// testEngine is an open Engine writing to a temporary directory, with a meta
+// client backed by an in-memory store.
+type testEngine struct {
+	*Engine
+}
+
+func newTestEngine(t *testing.T) *testEngine {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "storage-engine-")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	logger := zaptest.NewLogger(t)
+	store := inmem.NewKVStore()
+	require.NoError(t, all.Up(context.Background(), logger, store))
+	metaClient := meta.NewClient(meta.NewConfig(), store)
+	require.NoError(t, metaClient.Open())
+
+	e := NewEngine(dir, NewConfig(), WithMetaClient(metaClient))
+	e.WithLogger(logger)
+	require.NoError(t, e.Open(context.Background()))
+	t.Cleanup(func() { e.Close() })
+
+	return &testEngine{Engine: e}
+}
+
+// createTestBucket creates the database of a bucket.
+func (e *testEngine) createTestBucket(t *testing.T, id influxdb.ID) influxdb.ID {
+	t.Helper()
+	require.NoError(t, e.CreateBucket(context.Background(), &influxdb.Bucket{ID: id}))
+	return id
+}
+
+// writeTestPoints writes points of two series to a single shard of a bucket,
+// and snapshots them to a TSM file.
+func (e *testEngine) writeTestPoints(t *testing.T, bucketID influxdb.ID) {
+	t.Helper()
+
+	points, err := models.ParsePointsString("cpu,host=a value=1 1000000000\ncpu,host=b value=2 2000000000\n")
+	require.NoError(t, err)
+	require.NoError(t, e.WritePoints(context.Background(), 1, bucketID, points))
+
+	ids, err := e.bucketShardIDs(bucketID, nil)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	engine, err := e.tsdbStore.Shard(ids[0]).Engine()
+	require.NoError(t, err)
+	require.NoError(t, engine.(*tsm1.Engine).WriteSnapshot())
+}
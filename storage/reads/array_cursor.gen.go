@@ -15,6 +15,7 @@ import (
 	"github.com/influxdata/flux/values"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/influxdata/tdigest"
 )
 
 const (
@@ -196,6 +197,60 @@ func newWindowMeanArrayCursor(cur cursors.Cursor, window execute.Window) (cursor
 	}
 }
 
+func newWindowStddevArrayCursor(cur cursors.Cursor, window execute.Window) (cursors.Cursor, error) {
+	switch cur := cur.(type) {
+
+	case cursors.FloatArrayCursor:
+		return newFloatWindowStddevArrayCursor(cur, window), nil
+
+	case cursors.IntegerArrayCursor:
+		return newIntegerWindowStddevArrayCursor(cur, window), nil
+
+	case cursors.UnsignedArrayCursor:
+		return newUnsignedWindowStddevArrayCursor(cur, window), nil
+
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unsupported input type for stddev aggregate: %s", arrayCursorType(cur)),
+		}
+	}
+}
+
+func newWindowSpreadArrayCursor(cur cursors.Cursor, window execute.Window) (cursors.Cursor, error) {
+	switch cur := cur.(type) {
+
+	case cursors.FloatArrayCursor:
+		return newFloatWindowSpreadArrayCursor(cur, window), nil
+
+	case cursors.IntegerArrayCursor:
+		return newIntegerWindowSpreadArrayCursor(cur, window), nil
+
+	case cursors.UnsignedArrayCursor:
+		return newUnsignedWindowSpreadArrayCursor(cur, window), nil
+
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unsupported input type for spread aggregate: %s", arrayCursorType(cur)),
+		}
+	}
+}
+
+func newWindowQuantileArrayCursor(cur cursors.Cursor, window execute.Window, quantile, compression float64) (cursors.Cursor, error) {
+	switch cur := cur.(type) {
+
+	case cursors.FloatArrayCursor:
+		return newFloatWindowQuantileArrayCursor(cur, window, quantile, compression), nil
+
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unsupported input type for quantile aggregate: %s", arrayCursorType(cur)),
+		}
+	}
+}
+
 // ********************
 // Float Array Cursor
 
@@ -269,23 +324,32 @@ LOOP:
 type floatMultiShardArrayCursor struct {
 	cursors.FloatArrayCursor
 	cursorContext
+	cond   expression
 	filter *floatArrayFilterCursor
 }
 
 func (c *floatMultiShardArrayCursor) reset(cur cursors.FloatArrayCursor, itrs cursors.CursorIterators, cond expression) {
-	if cond != nil {
-		if c.filter == nil {
-			c.filter = newFloatFilterArrayCursor(cond)
-		}
-		c.filter.reset(cur)
-		cur = c.filter
-	}
-
-	c.FloatArrayCursor = cur
+	c.cond = cond
+	c.FloatArrayCursor = c.filterCursor(cur)
 	c.itrs = itrs
 	c.err = nil
 }
 
+// filterCursor wraps cur in a filter evaluating the condition on the values,
+// unless cur already evaluates it.
+func (c *floatMultiShardArrayCursor) filterCursor(cur cursors.FloatArrayCursor) cursors.FloatArrayCursor {
+	if c.cond == nil || isFiltered(cur) {
+		return cur
+	}
+
+	if c.filter == nil {
+		c.filter = newFloatFilterArrayCursor(c.cond)
+	}
+	c.filter.cond = c.cond
+	c.filter.reset(cur)
+	return c.filter
+}
+
 func (c *floatMultiShardArrayCursor) Err() error { return c.err }
 
 func (c *floatMultiShardArrayCursor) Stats() cursors.CursorStats {
@@ -327,10 +391,7 @@ func (c *floatMultiShardArrayCursor) nextArrayCursor() bool {
 			next = FloatEmptyArrayCursor
 			c.err = errors.New("expected float cursor")
 		} else {
-			if c.filter != nil {
-				c.filter.reset(next)
-				next = c.filter
-			}
+			next = c.filterCursor(next)
 		}
 		c.FloatArrayCursor = next
 	} else {
@@ -896,8 +957,361 @@ WINDOWS:
 				// new window detected, close the current window
 				// do not generate a point for empty windows
 				if windowHasPoints {
-					c.res.Timestamps[pos] = tsAcc
-					c.res.Values[pos] = acc
+					c.res.Timestamps[pos] = tsAcc
+					c.res.Values[pos] = acc
+					pos++
+					if pos >= MaxPointsPerBlock {
+						// the output array is full,
+						// save the remaining points in the input array in tmp.
+						// they will be processed in the next call to Next()
+						c.tmp.Timestamps = a.Timestamps[rowIdx:]
+						c.tmp.Values = a.Values[rowIdx:]
+						break WINDOWS
+					}
+				}
+
+				// start the new window
+				acc = -math.MaxFloat64
+				windowEnd = int64(c.window.GetEarliestBounds(values.Time(ts)).Stop)
+				windowHasPoints = false
+
+				continue WINDOWS
+			} else {
+				if !windowHasPoints || a.Values[rowIdx] > acc {
+					acc = a.Values[rowIdx]
+					tsAcc = a.Timestamps[rowIdx]
+				}
+				windowHasPoints = true
+			}
+		}
+
+		// Clear buffered timestamps & values if we make it through a cursor.
+		// The break above will skip this if a cursor is partially read.
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		// get the next chunk
+		a = c.FloatArrayCursor.Next()
+		if a.Len() == 0 {
+			// write the final point
+			// do not generate a point for empty windows
+			if windowHasPoints {
+				c.res.Timestamps[pos] = tsAcc
+				c.res.Values[pos] = acc
+				pos++
+			}
+			break WINDOWS
+		}
+		rowIdx = 0
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+
+	return c.res
+}
+
+type floatWindowMeanArrayCursor struct {
+	cursors.FloatArrayCursor
+	res    *cursors.FloatArray
+	tmp    *cursors.FloatArray
+	window execute.Window
+}
+
+func newFloatWindowMeanArrayCursor(cur cursors.FloatArrayCursor, window execute.Window) *floatWindowMeanArrayCursor {
+	resLen := MaxPointsPerBlock
+	if window.Every.IsZero() {
+		resLen = 1
+	}
+	return &floatWindowMeanArrayCursor{
+		FloatArrayCursor: cur,
+		res:              cursors.NewFloatArrayLen(resLen),
+		tmp:              &cursors.FloatArray{},
+		window:           window,
+	}
+}
+
+func (c *floatWindowMeanArrayCursor) Stats() cursors.CursorStats {
+	return c.FloatArrayCursor.Stats()
+}
+
+func (c *floatWindowMeanArrayCursor) Next() *cursors.FloatArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.FloatArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.FloatArrayCursor.Next()
+	}
+
+	if a.Len() == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	rowIdx := 0
+	var sum float64
+	var count int64
+
+	var windowEnd int64
+	if !c.window.Every.IsZero() {
+		windowEnd = int64(c.window.GetEarliestBounds(values.Time(a.Timestamps[rowIdx])).Stop)
+	} else {
+		windowEnd = math.MaxInt64
+	}
+	windowHasPoints := false
+
+	// enumerate windows
+WINDOWS:
+	for {
+		for ; rowIdx < a.Len(); rowIdx++ {
+			ts := a.Timestamps[rowIdx]
+			if !c.window.Every.IsZero() && ts >= windowEnd {
+				// new window detected, close the current window
+				// do not generate a point for empty windows
+				if windowHasPoints {
+					c.res.Timestamps[pos] = windowEnd
+					c.res.Values[pos] = sum / float64(count)
+					pos++
+					if pos >= MaxPointsPerBlock {
+						// the output array is full,
+						// save the remaining points in the input array in tmp.
+						// they will be processed in the next call to Next()
+						c.tmp.Timestamps = a.Timestamps[rowIdx:]
+						c.tmp.Values = a.Values[rowIdx:]
+						break WINDOWS
+					}
+				}
+
+				// start the new window
+				sum = 0
+				count = 0
+				windowEnd = int64(c.window.GetEarliestBounds(values.Time(ts)).Stop)
+				windowHasPoints = false
+
+				continue WINDOWS
+			} else {
+				sum += a.Values[rowIdx]
+				count++
+				windowHasPoints = true
+			}
+		}
+
+		// Clear buffered timestamps & values if we make it through a cursor.
+		// The break above will skip this if a cursor is partially read.
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		// get the next chunk
+		a = c.FloatArrayCursor.Next()
+		if a.Len() == 0 {
+			// write the final point
+			// do not generate a point for empty windows
+			if windowHasPoints {
+				c.res.Timestamps[pos] = windowEnd
+				c.res.Values[pos] = sum / float64(count)
+				pos++
+			}
+			break WINDOWS
+		}
+		rowIdx = 0
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+
+	return c.res
+}
+
+type floatWindowStddevArrayCursor struct {
+	cursors.FloatArrayCursor
+	res    *cursors.FloatArray
+	tmp    *cursors.FloatArray
+	window execute.Window
+}
+
+func newFloatWindowStddevArrayCursor(cur cursors.FloatArrayCursor, window execute.Window) *floatWindowStddevArrayCursor {
+	resLen := MaxPointsPerBlock
+	if window.Every.IsZero() {
+		resLen = 1
+	}
+	return &floatWindowStddevArrayCursor{
+		FloatArrayCursor: cur,
+		res:              cursors.NewFloatArrayLen(resLen),
+		tmp:              &cursors.FloatArray{},
+		window:           window,
+	}
+}
+
+func (c *floatWindowStddevArrayCursor) Stats() cursors.CursorStats {
+	return c.FloatArrayCursor.Stats()
+}
+
+func (c *floatWindowStddevArrayCursor) Next() *cursors.FloatArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.FloatArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.FloatArrayCursor.Next()
+	}
+
+	if a.Len() == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	rowIdx := 0
+	var count, mean, m2 float64
+
+	var windowEnd int64
+	if !c.window.Every.IsZero() {
+		windowEnd = int64(c.window.GetEarliestBounds(values.Time(a.Timestamps[rowIdx])).Stop)
+	} else {
+		windowEnd = math.MaxInt64
+	}
+	windowHasPoints := false
+
+	// enumerate windows
+WINDOWS:
+	for {
+		for ; rowIdx < a.Len(); rowIdx++ {
+			ts := a.Timestamps[rowIdx]
+			if !c.window.Every.IsZero() && ts >= windowEnd {
+				// new window detected, close the current window
+				// do not generate a point for empty windows
+				if windowHasPoints {
+					c.res.Timestamps[pos] = windowEnd
+					if count > 1 {
+						c.res.Values[pos] = math.Sqrt(m2 / (count - 1))
+					} else {
+						c.res.Values[pos] = math.NaN()
+					}
+					pos++
+					if pos >= MaxPointsPerBlock {
+						// the output array is full,
+						// save the remaining points in the input array in tmp.
+						// they will be processed in the next call to Next()
+						c.tmp.Timestamps = a.Timestamps[rowIdx:]
+						c.tmp.Values = a.Values[rowIdx:]
+						break WINDOWS
+					}
+				}
+
+				// start the new window
+				count = 0
+				mean = 0
+				m2 = 0
+				windowEnd = int64(c.window.GetEarliestBounds(values.Time(ts)).Stop)
+				windowHasPoints = false
+
+				continue WINDOWS
+			} else {
+				v := a.Values[rowIdx]
+				count++
+				delta := v - mean
+				mean += delta / count
+				m2 += delta * (v - mean)
+				windowHasPoints = true
+			}
+		}
+
+		// Clear buffered timestamps & values if we make it through a cursor.
+		// The break above will skip this if a cursor is partially read.
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		// get the next chunk
+		a = c.FloatArrayCursor.Next()
+		if a.Len() == 0 {
+			// write the final point
+			// do not generate a point for empty windows
+			if windowHasPoints {
+				c.res.Timestamps[pos] = windowEnd
+				if count > 1 {
+					c.res.Values[pos] = math.Sqrt(m2 / (count - 1))
+				} else {
+					c.res.Values[pos] = math.NaN()
+				}
+				pos++
+			}
+			break WINDOWS
+		}
+		rowIdx = 0
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+
+	return c.res
+}
+
+type floatWindowSpreadArrayCursor struct {
+	cursors.FloatArrayCursor
+	res    *cursors.FloatArray
+	tmp    *cursors.FloatArray
+	window execute.Window
+}
+
+func newFloatWindowSpreadArrayCursor(cur cursors.FloatArrayCursor, window execute.Window) *floatWindowSpreadArrayCursor {
+	resLen := MaxPointsPerBlock
+	if window.Every.IsZero() {
+		resLen = 1
+	}
+	return &floatWindowSpreadArrayCursor{
+		FloatArrayCursor: cur,
+		res:              cursors.NewFloatArrayLen(resLen),
+		tmp:              &cursors.FloatArray{},
+		window:           window,
+	}
+}
+
+func (c *floatWindowSpreadArrayCursor) Stats() cursors.CursorStats {
+	return c.FloatArrayCursor.Stats()
+}
+
+func (c *floatWindowSpreadArrayCursor) Next() *cursors.FloatArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.FloatArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.FloatArrayCursor.Next()
+	}
+
+	if a.Len() == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	rowIdx := 0
+	var min, max float64
+
+	var windowEnd int64
+	if !c.window.Every.IsZero() {
+		windowEnd = int64(c.window.GetEarliestBounds(values.Time(a.Timestamps[rowIdx])).Stop)
+	} else {
+		windowEnd = math.MaxInt64
+	}
+	windowHasPoints := false
+
+	// enumerate windows
+WINDOWS:
+	for {
+		for ; rowIdx < a.Len(); rowIdx++ {
+			ts := a.Timestamps[rowIdx]
+			if !c.window.Every.IsZero() && ts >= windowEnd {
+				// new window detected, close the current window
+				// do not generate a point for empty windows
+				if windowHasPoints {
+					c.res.Timestamps[pos] = windowEnd
+					c.res.Values[pos] = max - min
 					pos++
 					if pos >= MaxPointsPerBlock {
 						// the output array is full,
@@ -910,15 +1324,18 @@ WINDOWS:
 				}
 
 				// start the new window
-				acc = -math.MaxFloat64
+				min = 0
+				max = 0
 				windowEnd = int64(c.window.GetEarliestBounds(values.Time(ts)).Stop)
 				windowHasPoints = false
 
 				continue WINDOWS
 			} else {
-				if !windowHasPoints || a.Values[rowIdx] > acc {
-					acc = a.Values[rowIdx]
-					tsAcc = a.Timestamps[rowIdx]
+				if !windowHasPoints || a.Values[rowIdx] < min {
+					min = a.Values[rowIdx]
+				}
+				if !windowHasPoints || a.Values[rowIdx] > max {
+					max = a.Values[rowIdx]
 				}
 				windowHasPoints = true
 			}
@@ -935,8 +1352,8 @@ WINDOWS:
 			// write the final point
 			// do not generate a point for empty windows
 			if windowHasPoints {
-				c.res.Timestamps[pos] = tsAcc
-				c.res.Values[pos] = acc
+				c.res.Timestamps[pos] = windowEnd
+				c.res.Values[pos] = max - min
 				pos++
 			}
 			break WINDOWS
@@ -950,31 +1367,35 @@ WINDOWS:
 	return c.res
 }
 
-type floatWindowMeanArrayCursor struct {
+type floatWindowQuantileArrayCursor struct {
 	cursors.FloatArrayCursor
-	res    *cursors.FloatArray
-	tmp    *cursors.FloatArray
-	window execute.Window
+	res         *cursors.FloatArray
+	tmp         *cursors.FloatArray
+	window      execute.Window
+	quantile    float64
+	compression float64
 }
 
-func newFloatWindowMeanArrayCursor(cur cursors.FloatArrayCursor, window execute.Window) *floatWindowMeanArrayCursor {
+func newFloatWindowQuantileArrayCursor(cur cursors.FloatArrayCursor, window execute.Window, quantile float64, compression float64) *floatWindowQuantileArrayCursor {
 	resLen := MaxPointsPerBlock
 	if window.Every.IsZero() {
 		resLen = 1
 	}
-	return &floatWindowMeanArrayCursor{
+	return &floatWindowQuantileArrayCursor{
 		FloatArrayCursor: cur,
 		res:              cursors.NewFloatArrayLen(resLen),
 		tmp:              &cursors.FloatArray{},
 		window:           window,
+		quantile:         quantile,
+		compression:      compression,
 	}
 }
 
-func (c *floatWindowMeanArrayCursor) Stats() cursors.CursorStats {
+func (c *floatWindowQuantileArrayCursor) Stats() cursors.CursorStats {
 	return c.FloatArrayCursor.Stats()
 }
 
-func (c *floatWindowMeanArrayCursor) Next() *cursors.FloatArray {
+func (c *floatWindowQuantileArrayCursor) Next() *cursors.FloatArray {
 	pos := 0
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
@@ -991,8 +1412,7 @@ func (c *floatWindowMeanArrayCursor) Next() *cursors.FloatArray {
 	}
 
 	rowIdx := 0
-	var sum float64
-	var count int64
+	td := tdigest.NewWithCompression(c.compression)
 
 	var windowEnd int64
 	if !c.window.Every.IsZero() {
@@ -1012,7 +1432,7 @@ WINDOWS:
 				// do not generate a point for empty windows
 				if windowHasPoints {
 					c.res.Timestamps[pos] = windowEnd
-					c.res.Values[pos] = sum / float64(count)
+					c.res.Values[pos] = td.Quantile(c.quantile)
 					pos++
 					if pos >= MaxPointsPerBlock {
 						// the output array is full,
@@ -1025,15 +1445,13 @@ WINDOWS:
 				}
 
 				// start the new window
-				sum = 0
-				count = 0
+				td = tdigest.NewWithCompression(c.compression)
 				windowEnd = int64(c.window.GetEarliestBounds(values.Time(ts)).Stop)
 				windowHasPoints = false
 
 				continue WINDOWS
 			} else {
-				sum += a.Values[rowIdx]
-				count++
+				td.Add(a.Values[rowIdx], 1)
 				windowHasPoints = true
 			}
 		}
@@ -1050,7 +1468,7 @@ WINDOWS:
 			// do not generate a point for empty windows
 			if windowHasPoints {
 				c.res.Timestamps[pos] = windowEnd
-				c.res.Values[pos] = sum / float64(count)
+				c.res.Values[pos] = td.Quantile(c.quantile)
 				pos++
 			}
 			break WINDOWS
@@ -1148,23 +1566,32 @@ LOOP:
 type integerMultiShardArrayCursor struct {
 	cursors.IntegerArrayCursor
 	cursorContext
+	cond   expression
 	filter *integerArrayFilterCursor
 }
 
 func (c *integerMultiShardArrayCursor) reset(cur cursors.IntegerArrayCursor, itrs cursors.CursorIterators, cond expression) {
-	if cond != nil {
-		if c.filter == nil {
-			c.filter = newIntegerFilterArrayCursor(cond)
-		}
-		c.filter.reset(cur)
-		cur = c.filter
-	}
-
-	c.IntegerArrayCursor = cur
+	c.cond = cond
+	c.IntegerArrayCursor = c.filterCursor(cur)
 	c.itrs = itrs
 	c.err = nil
 }
 
+// filterCursor wraps cur in a filter evaluating the condition on the values,
+// unless cur already evaluates it.
+func (c *integerMultiShardArrayCursor) filterCursor(cur cursors.IntegerArrayCursor) cursors.IntegerArrayCursor {
+	if c.cond == nil || isFiltered(cur) {
+		return cur
+	}
+
+	if c.filter == nil {
+		c.filter = newIntegerFilterArrayCursor(c.cond)
+	}
+	c.filter.cond = c.cond
+	c.filter.reset(cur)
+	return c.filter
+}
+
 func (c *integerMultiShardArrayCursor) Err() error { return c.err }
 
 func (c *integerMultiShardArrayCursor) Stats() cursors.CursorStats {
@@ -1206,10 +1633,7 @@ func (c *integerMultiShardArrayCursor) nextArrayCursor() bool {
 			next = IntegerEmptyArrayCursor
 			c.err = errors.New("expected integer cursor")
 		} else {
-			if c.filter != nil {
-				c.filter.reset(next)
-				next = c.filter
-			}
+			next = c.filterCursor(next)
 		}
 		c.IntegerArrayCursor = next
 	} else {
@@ -1836,24 +2260,263 @@ type integerWindowMeanArrayCursor struct {
 	window execute.Window
 }
 
-func newIntegerWindowMeanArrayCursor(cur cursors.IntegerArrayCursor, window execute.Window) *integerWindowMeanArrayCursor {
+func newIntegerWindowMeanArrayCursor(cur cursors.IntegerArrayCursor, window execute.Window) *integerWindowMeanArrayCursor {
+	resLen := MaxPointsPerBlock
+	if window.Every.IsZero() {
+		resLen = 1
+	}
+	return &integerWindowMeanArrayCursor{
+		IntegerArrayCursor: cur,
+		res:                cursors.NewFloatArrayLen(resLen),
+		tmp:                &cursors.IntegerArray{},
+		window:             window,
+	}
+}
+
+func (c *integerWindowMeanArrayCursor) Stats() cursors.CursorStats {
+	return c.IntegerArrayCursor.Stats()
+}
+
+func (c *integerWindowMeanArrayCursor) Next() *cursors.FloatArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.IntegerArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.IntegerArrayCursor.Next()
+	}
+
+	if a.Len() == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	rowIdx := 0
+	var sum int64
+	var count int64
+
+	var windowEnd int64
+	if !c.window.Every.IsZero() {
+		windowEnd = int64(c.window.GetEarliestBounds(values.Time(a.Timestamps[rowIdx])).Stop)
+	} else {
+		windowEnd = math.MaxInt64
+	}
+	windowHasPoints := false
+
+	// enumerate windows
+WINDOWS:
+	for {
+		for ; rowIdx < a.Len(); rowIdx++ {
+			ts := a.Timestamps[rowIdx]
+			if !c.window.Every.IsZero() && ts >= windowEnd {
+				// new window detected, close the current window
+				// do not generate a point for empty windows
+				if windowHasPoints {
+					c.res.Timestamps[pos] = windowEnd
+					c.res.Values[pos] = float64(sum) / float64(count)
+					pos++
+					if pos >= MaxPointsPerBlock {
+						// the output array is full,
+						// save the remaining points in the input array in tmp.
+						// they will be processed in the next call to Next()
+						c.tmp.Timestamps = a.Timestamps[rowIdx:]
+						c.tmp.Values = a.Values[rowIdx:]
+						break WINDOWS
+					}
+				}
+
+				// start the new window
+				sum = 0
+				count = 0
+				windowEnd = int64(c.window.GetEarliestBounds(values.Time(ts)).Stop)
+				windowHasPoints = false
+
+				continue WINDOWS
+			} else {
+				sum += a.Values[rowIdx]
+				count++
+				windowHasPoints = true
+			}
+		}
+
+		// Clear buffered timestamps & values if we make it through a cursor.
+		// The break above will skip this if a cursor is partially read.
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		// get the next chunk
+		a = c.IntegerArrayCursor.Next()
+		if a.Len() == 0 {
+			// write the final point
+			// do not generate a point for empty windows
+			if windowHasPoints {
+				c.res.Timestamps[pos] = windowEnd
+				c.res.Values[pos] = float64(sum) / float64(count)
+				pos++
+			}
+			break WINDOWS
+		}
+		rowIdx = 0
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+
+	return c.res
+}
+
+type integerWindowStddevArrayCursor struct {
+	cursors.IntegerArrayCursor
+	res    *cursors.FloatArray
+	tmp    *cursors.IntegerArray
+	window execute.Window
+}
+
+func newIntegerWindowStddevArrayCursor(cur cursors.IntegerArrayCursor, window execute.Window) *integerWindowStddevArrayCursor {
+	resLen := MaxPointsPerBlock
+	if window.Every.IsZero() {
+		resLen = 1
+	}
+	return &integerWindowStddevArrayCursor{
+		IntegerArrayCursor: cur,
+		res:                cursors.NewFloatArrayLen(resLen),
+		tmp:                &cursors.IntegerArray{},
+		window:             window,
+	}
+}
+
+func (c *integerWindowStddevArrayCursor) Stats() cursors.CursorStats {
+	return c.IntegerArrayCursor.Stats()
+}
+
+func (c *integerWindowStddevArrayCursor) Next() *cursors.FloatArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.IntegerArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.IntegerArrayCursor.Next()
+	}
+
+	if a.Len() == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	rowIdx := 0
+	var count, mean, m2 float64
+
+	var windowEnd int64
+	if !c.window.Every.IsZero() {
+		windowEnd = int64(c.window.GetEarliestBounds(values.Time(a.Timestamps[rowIdx])).Stop)
+	} else {
+		windowEnd = math.MaxInt64
+	}
+	windowHasPoints := false
+
+	// enumerate windows
+WINDOWS:
+	for {
+		for ; rowIdx < a.Len(); rowIdx++ {
+			ts := a.Timestamps[rowIdx]
+			if !c.window.Every.IsZero() && ts >= windowEnd {
+				// new window detected, close the current window
+				// do not generate a point for empty windows
+				if windowHasPoints {
+					c.res.Timestamps[pos] = windowEnd
+					if count > 1 {
+						c.res.Values[pos] = math.Sqrt(m2 / (count - 1))
+					} else {
+						c.res.Values[pos] = math.NaN()
+					}
+					pos++
+					if pos >= MaxPointsPerBlock {
+						// the output array is full,
+						// save the remaining points in the input array in tmp.
+						// they will be processed in the next call to Next()
+						c.tmp.Timestamps = a.Timestamps[rowIdx:]
+						c.tmp.Values = a.Values[rowIdx:]
+						break WINDOWS
+					}
+				}
+
+				// start the new window
+				count = 0
+				mean = 0
+				m2 = 0
+				windowEnd = int64(c.window.GetEarliestBounds(values.Time(ts)).Stop)
+				windowHasPoints = false
+
+				continue WINDOWS
+			} else {
+				v := float64(a.Values[rowIdx])
+				count++
+				delta := v - mean
+				mean += delta / count
+				m2 += delta * (v - mean)
+				windowHasPoints = true
+			}
+		}
+
+		// Clear buffered timestamps & values if we make it through a cursor.
+		// The break above will skip this if a cursor is partially read.
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		// get the next chunk
+		a = c.IntegerArrayCursor.Next()
+		if a.Len() == 0 {
+			// write the final point
+			// do not generate a point for empty windows
+			if windowHasPoints {
+				c.res.Timestamps[pos] = windowEnd
+				if count > 1 {
+					c.res.Values[pos] = math.Sqrt(m2 / (count - 1))
+				} else {
+					c.res.Values[pos] = math.NaN()
+				}
+				pos++
+			}
+			break WINDOWS
+		}
+		rowIdx = 0
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+
+	return c.res
+}
+
+type integerWindowSpreadArrayCursor struct {
+	cursors.IntegerArrayCursor
+	res    *cursors.IntegerArray
+	tmp    *cursors.IntegerArray
+	window execute.Window
+}
+
+func newIntegerWindowSpreadArrayCursor(cur cursors.IntegerArrayCursor, window execute.Window) *integerWindowSpreadArrayCursor {
 	resLen := MaxPointsPerBlock
 	if window.Every.IsZero() {
 		resLen = 1
 	}
-	return &integerWindowMeanArrayCursor{
+	return &integerWindowSpreadArrayCursor{
 		IntegerArrayCursor: cur,
-		res:                cursors.NewFloatArrayLen(resLen),
+		res:                cursors.NewIntegerArrayLen(resLen),
 		tmp:                &cursors.IntegerArray{},
 		window:             window,
 	}
 }
 
-func (c *integerWindowMeanArrayCursor) Stats() cursors.CursorStats {
+func (c *integerWindowSpreadArrayCursor) Stats() cursors.CursorStats {
 	return c.IntegerArrayCursor.Stats()
 }
 
-func (c *integerWindowMeanArrayCursor) Next() *cursors.FloatArray {
+func (c *integerWindowSpreadArrayCursor) Next() *cursors.IntegerArray {
 	pos := 0
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
@@ -1866,12 +2529,11 @@ func (c *integerWindowMeanArrayCursor) Next() *cursors.FloatArray {
 	}
 
 	if a.Len() == 0 {
-		return &cursors.FloatArray{}
+		return &cursors.IntegerArray{}
 	}
 
 	rowIdx := 0
-	var sum int64
-	var count int64
+	var min, max int64
 
 	var windowEnd int64
 	if !c.window.Every.IsZero() {
@@ -1891,7 +2553,7 @@ WINDOWS:
 				// do not generate a point for empty windows
 				if windowHasPoints {
 					c.res.Timestamps[pos] = windowEnd
-					c.res.Values[pos] = float64(sum) / float64(count)
+					c.res.Values[pos] = max - min
 					pos++
 					if pos >= MaxPointsPerBlock {
 						// the output array is full,
@@ -1904,15 +2566,19 @@ WINDOWS:
 				}
 
 				// start the new window
-				sum = 0
-				count = 0
+				min = 0
+				max = 0
 				windowEnd = int64(c.window.GetEarliestBounds(values.Time(ts)).Stop)
 				windowHasPoints = false
 
 				continue WINDOWS
 			} else {
-				sum += a.Values[rowIdx]
-				count++
+				if !windowHasPoints || a.Values[rowIdx] < min {
+					min = a.Values[rowIdx]
+				}
+				if !windowHasPoints || a.Values[rowIdx] > max {
+					max = a.Values[rowIdx]
+				}
 				windowHasPoints = true
 			}
 		}
@@ -1929,7 +2595,7 @@ WINDOWS:
 			// do not generate a point for empty windows
 			if windowHasPoints {
 				c.res.Timestamps[pos] = windowEnd
-				c.res.Values[pos] = float64(sum) / float64(count)
+				c.res.Values[pos] = max - min
 				pos++
 			}
 			break WINDOWS
@@ -2027,23 +2693,32 @@ LOOP:
 type unsignedMultiShardArrayCursor struct {
 	cursors.UnsignedArrayCursor
 	cursorContext
+	cond   expression
 	filter *unsignedArrayFilterCursor
 }
 
 func (c *unsignedMultiShardArrayCursor) reset(cur cursors.UnsignedArrayCursor, itrs cursors.CursorIterators, cond expression) {
-	if cond != nil {
-		if c.filter == nil {
-			c.filter = newUnsignedFilterArrayCursor(cond)
-		}
-		c.filter.reset(cur)
-		cur = c.filter
-	}
-
-	c.UnsignedArrayCursor = cur
+	c.cond = cond
+	c.UnsignedArrayCursor = c.filterCursor(cur)
 	c.itrs = itrs
 	c.err = nil
 }
 
+// filterCursor wraps cur in a filter evaluating the condition on the values,
+// unless cur already evaluates it.
+func (c *unsignedMultiShardArrayCursor) filterCursor(cur cursors.UnsignedArrayCursor) cursors.UnsignedArrayCursor {
+	if c.cond == nil || isFiltered(cur) {
+		return cur
+	}
+
+	if c.filter == nil {
+		c.filter = newUnsignedFilterArrayCursor(c.cond)
+	}
+	c.filter.cond = c.cond
+	c.filter.reset(cur)
+	return c.filter
+}
+
 func (c *unsignedMultiShardArrayCursor) Err() error { return c.err }
 
 func (c *unsignedMultiShardArrayCursor) Stats() cursors.CursorStats {
@@ -2085,10 +2760,7 @@ func (c *unsignedMultiShardArrayCursor) nextArrayCursor() bool {
 			next = UnsignedEmptyArrayCursor
 			c.err = errors.New("expected unsigned cursor")
 		} else {
-			if c.filter != nil {
-				c.filter.reset(next)
-				next = c.filter
-			}
+			next = c.filterCursor(next)
 		}
 		c.UnsignedArrayCursor = next
 	} else {
@@ -2822,6 +3494,248 @@ WINDOWS:
 	return c.res
 }
 
+type unsignedWindowStddevArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	res    *cursors.FloatArray
+	tmp    *cursors.UnsignedArray
+	window execute.Window
+}
+
+func newUnsignedWindowStddevArrayCursor(cur cursors.UnsignedArrayCursor, window execute.Window) *unsignedWindowStddevArrayCursor {
+	resLen := MaxPointsPerBlock
+	if window.Every.IsZero() {
+		resLen = 1
+	}
+	return &unsignedWindowStddevArrayCursor{
+		UnsignedArrayCursor: cur,
+		res:                 cursors.NewFloatArrayLen(resLen),
+		tmp:                 &cursors.UnsignedArray{},
+		window:              window,
+	}
+}
+
+func (c *unsignedWindowStddevArrayCursor) Stats() cursors.CursorStats {
+	return c.UnsignedArrayCursor.Stats()
+}
+
+func (c *unsignedWindowStddevArrayCursor) Next() *cursors.FloatArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.UnsignedArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.UnsignedArrayCursor.Next()
+	}
+
+	if a.Len() == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	rowIdx := 0
+	var count, mean, m2 float64
+
+	var windowEnd int64
+	if !c.window.Every.IsZero() {
+		windowEnd = int64(c.window.GetEarliestBounds(values.Time(a.Timestamps[rowIdx])).Stop)
+	} else {
+		windowEnd = math.MaxInt64
+	}
+	windowHasPoints := false
+
+	// enumerate windows
+WINDOWS:
+	for {
+		for ; rowIdx < a.Len(); rowIdx++ {
+			ts := a.Timestamps[rowIdx]
+			if !c.window.Every.IsZero() && ts >= windowEnd {
+				// new window detected, close the current window
+				// do not generate a point for empty windows
+				if windowHasPoints {
+					c.res.Timestamps[pos] = windowEnd
+					if count > 1 {
+						c.res.Values[pos] = math.Sqrt(m2 / (count - 1))
+					} else {
+						c.res.Values[pos] = math.NaN()
+					}
+					pos++
+					if pos >= MaxPointsPerBlock {
+						// the output array is full,
+						// save the remaining points in the input array in tmp.
+						// they will be processed in the next call to Next()
+						c.tmp.Timestamps = a.Timestamps[rowIdx:]
+						c.tmp.Values = a.Values[rowIdx:]
+						break WINDOWS
+					}
+				}
+
+				// start the new window
+				count = 0
+				mean = 0
+				m2 = 0
+				windowEnd = int64(c.window.GetEarliestBounds(values.Time(ts)).Stop)
+				windowHasPoints = false
+
+				continue WINDOWS
+			} else {
+				v := float64(a.Values[rowIdx])
+				count++
+				delta := v - mean
+				mean += delta / count
+				m2 += delta * (v - mean)
+				windowHasPoints = true
+			}
+		}
+
+		// Clear buffered timestamps & values if we make it through a cursor.
+		// The break above will skip this if a cursor is partially read.
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		// get the next chunk
+		a = c.UnsignedArrayCursor.Next()
+		if a.Len() == 0 {
+			// write the final point
+			// do not generate a point for empty windows
+			if windowHasPoints {
+				c.res.Timestamps[pos] = windowEnd
+				if count > 1 {
+					c.res.Values[pos] = math.Sqrt(m2 / (count - 1))
+				} else {
+					c.res.Values[pos] = math.NaN()
+				}
+				pos++
+			}
+			break WINDOWS
+		}
+		rowIdx = 0
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+
+	return c.res
+}
+
+type unsignedWindowSpreadArrayCursor struct {
+	cursors.UnsignedArrayCursor
+	res    *cursors.UnsignedArray
+	tmp    *cursors.UnsignedArray
+	window execute.Window
+}
+
+func newUnsignedWindowSpreadArrayCursor(cur cursors.UnsignedArrayCursor, window execute.Window) *unsignedWindowSpreadArrayCursor {
+	resLen := MaxPointsPerBlock
+	if window.Every.IsZero() {
+		resLen = 1
+	}
+	return &unsignedWindowSpreadArrayCursor{
+		UnsignedArrayCursor: cur,
+		res:                 cursors.NewUnsignedArrayLen(resLen),
+		tmp:                 &cursors.UnsignedArray{},
+		window:              window,
+	}
+}
+
+func (c *unsignedWindowSpreadArrayCursor) Stats() cursors.CursorStats {
+	return c.UnsignedArrayCursor.Stats()
+}
+
+func (c *unsignedWindowSpreadArrayCursor) Next() *cursors.UnsignedArray {
+	pos := 0
+	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
+	c.res.Values = c.res.Values[:cap(c.res.Values)]
+
+	var a *cursors.UnsignedArray
+	if c.tmp.Len() > 0 {
+		a = c.tmp
+	} else {
+		a = c.UnsignedArrayCursor.Next()
+	}
+
+	if a.Len() == 0 {
+		return &cursors.UnsignedArray{}
+	}
+
+	rowIdx := 0
+	var min, max uint64
+
+	var windowEnd int64
+	if !c.window.Every.IsZero() {
+		windowEnd = int64(c.window.GetEarliestBounds(values.Time(a.Timestamps[rowIdx])).Stop)
+	} else {
+		windowEnd = math.MaxInt64
+	}
+	windowHasPoints := false
+
+	// enumerate windows
+WINDOWS:
+	for {
+		for ; rowIdx < a.Len(); rowIdx++ {
+			ts := a.Timestamps[rowIdx]
+			if !c.window.Every.IsZero() && ts >= windowEnd {
+				// new window detected, close the current window
+				// do not generate a point for empty windows
+				if windowHasPoints {
+					c.res.Timestamps[pos] = windowEnd
+					c.res.Values[pos] = max - min
+					pos++
+					if pos >= MaxPointsPerBlock {
+						// the output array is full,
+						// save the remaining points in the input array in tmp.
+						// they will be processed in the next call to Next()
+						c.tmp.Timestamps = a.Timestamps[rowIdx:]
+						c.tmp.Values = a.Values[rowIdx:]
+						break WINDOWS
+					}
+				}
+
+				// start the new window
+				min = 0
+				max = 0
+				windowEnd = int64(c.window.GetEarliestBounds(values.Time(ts)).Stop)
+				windowHasPoints = false
+
+				continue WINDOWS
+			} else {
+				if !windowHasPoints || a.Values[rowIdx] < min {
+					min = a.Values[rowIdx]
+				}
+				if !windowHasPoints || a.Values[rowIdx] > max {
+					max = a.Values[rowIdx]
+				}
+				windowHasPoints = true
+			}
+		}
+
+		// Clear buffered timestamps & values if we make it through a cursor.
+		// The break above will skip this if a cursor is partially read.
+		c.tmp.Timestamps = nil
+		c.tmp.Values = nil
+
+		// get the next chunk
+		a = c.UnsignedArrayCursor.Next()
+		if a.Len() == 0 {
+			// write the final point
+			// do not generate a point for empty windows
+			if windowHasPoints {
+				c.res.Timestamps[pos] = windowEnd
+				c.res.Values[pos] = max - min
+				pos++
+			}
+			break WINDOWS
+		}
+		rowIdx = 0
+	}
+
+	c.res.Timestamps = c.res.Timestamps[:pos]
+	c.res.Values = c.res.Values[:pos]
+
+	return c.res
+}
+
 type unsignedEmptyArrayCursor struct {
 	res cursors.UnsignedArray
 }
@@ -2906,23 +3820,32 @@ LOOP:
 type stringMultiShardArrayCursor struct {
 	cursors.StringArrayCursor
 	cursorContext
+	cond   expression
 	filter *stringArrayFilterCursor
 }
 
 func (c *stringMultiShardArrayCursor) reset(cur cursors.StringArrayCursor, itrs cursors.CursorIterators, cond expression) {
-	if cond != nil {
-		if c.filter == nil {
-			c.filter = newStringFilterArrayCursor(cond)
-		}
-		c.filter.reset(cur)
-		cur = c.filter
-	}
-
-	c.StringArrayCursor = cur
+	c.cond = cond
+	c.StringArrayCursor = c.filterCursor(cur)
 	c.itrs = itrs
 	c.err = nil
 }
 
+// filterCursor wraps cur in a filter evaluating the condition on the values,
+// unless cur already evaluates it.
+func (c *stringMultiShardArrayCursor) filterCursor(cur cursors.StringArrayCursor) cursors.StringArrayCursor {
+	if c.cond == nil || isFiltered(cur) {
+		return cur
+	}
+
+	if c.filter == nil {
+		c.filter = newStringFilterArrayCursor(c.cond)
+	}
+	c.filter.cond = c.cond
+	c.filter.reset(cur)
+	return c.filter
+}
+
 func (c *stringMultiShardArrayCursor) Err() error { return c.err }
 
 func (c *stringMultiShardArrayCursor) Stats() cursors.CursorStats {
@@ -2964,10 +3887,7 @@ func (c *stringMultiShardArrayCursor) nextArrayCursor() bool {
 			next = StringEmptyArrayCursor
 			c.err = errors.New("expected string cursor")
 		} else {
-			if c.filter != nil {
-				c.filter.reset(next)
-				next = c.filter
-			}
+			next = c.filterCursor(next)
 		}
 		c.StringArrayCursor = next
 	} else {
@@ -3330,23 +4250,32 @@ LOOP:
 type booleanMultiShardArrayCursor struct {
 	cursors.BooleanArrayCursor
 	cursorContext
+	cond   expression
 	filter *booleanArrayFilterCursor
 }
 
 func (c *booleanMultiShardArrayCursor) reset(cur cursors.BooleanArrayCursor, itrs cursors.CursorIterators, cond expression) {
-	if cond != nil {
-		if c.filter == nil {
-			c.filter = newBooleanFilterArrayCursor(cond)
-		}
-		c.filter.reset(cur)
-		cur = c.filter
-	}
-
-	c.BooleanArrayCursor = cur
+	c.cond = cond
+	c.BooleanArrayCursor = c.filterCursor(cur)
 	c.itrs = itrs
 	c.err = nil
 }
 
+// filterCursor wraps cur in a filter evaluating the condition on the values,
+// unless cur already evaluates it.
+func (c *booleanMultiShardArrayCursor) filterCursor(cur cursors.BooleanArrayCursor) cursors.BooleanArrayCursor {
+	if c.cond == nil || isFiltered(cur) {
+		return cur
+	}
+
+	if c.filter == nil {
+		c.filter = newBooleanFilterArrayCursor(c.cond)
+	}
+	c.filter.cond = c.cond
+	c.filter.reset(cur)
+	return c.filter
+}
+
 func (c *booleanMultiShardArrayCursor) Err() error { return c.err }
 
 func (c *booleanMultiShardArrayCursor) Stats() cursors.CursorStats {
@@ -3388,10 +4317,7 @@ func (c *booleanMultiShardArrayCursor) nextArrayCursor() bool {
 			next = BooleanEmptyArrayCursor
 			c.err = errors.New("expected boolean cursor")
 		} else {
-			if c.filter != nil {
-				c.filter.reset(next)
-				next = c.filter
-			}
+			next = c.filterCursor(next)
 		}
 		c.BooleanArrayCursor = next
 	} else {
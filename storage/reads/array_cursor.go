@@ -5,10 +5,15 @@ import (
 	"fmt"
 
 	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
 )
 
+// DefaultQuantileCompression is the compression of the t-digest used to
+// estimate the quantile aggregate when the request does not specify one.
+const DefaultQuantileCompression = 1000
+
 type singleValue struct {
 	v interface{}
 }
@@ -45,6 +50,22 @@ func newWindowAggregateArrayCursor(ctx context.Context, agg *datatypes.Aggregate
 		return newWindowMaxArrayCursor(cursor, window), nil
 	case datatypes.AggregateTypeMean:
 		return newWindowMeanArrayCursor(cursor, window)
+	case datatypes.AggregateTypeStddev:
+		return newWindowStddevArrayCursor(cursor, window)
+	case datatypes.AggregateTypeSpread:
+		return newWindowSpreadArrayCursor(cursor, window)
+	case datatypes.AggregateTypeQuantile:
+		if agg.Quantile < 0 || agg.Quantile > 1 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("quantile must be between 0 and 1: %v", agg.Quantile),
+			}
+		}
+		compression := agg.Compression
+		if compression == 0 {
+			compression = DefaultQuantileCompression
+		}
+		return newWindowQuantileArrayCursor(cursor, window, agg.Quantile, compression)
 	default:
 		// TODO(sgc): should be validated higher up
 		panic("invalid aggregate")
@@ -58,6 +79,13 @@ type cursorContext struct {
 	err  error
 }
 
+// isFiltered returns true if cur only returns the values matching the
+// ValueCond of its request.
+func isFiltered(cur cursors.Cursor) bool {
+	fc, ok := cur.(cursors.FilteredCursor)
+	return ok && fc.Filtered()
+}
+
 type multiShardArrayCursors struct {
 	ctx context.Context
 	req cursors.CursorRequest
@@ -99,6 +127,7 @@ func (m *multiShardArrayCursors) createCursor(row SeriesRow) cursors.Cursor {
 	m.req.Name = row.Name
 	m.req.Tags = row.SeriesTags
 	m.req.Field = row.Field
+	m.req.ValueCond = row.ValueCond
 
 	var cond expression
 	if row.ValueCond != nil {
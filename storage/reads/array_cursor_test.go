@@ -2,7 +2,9 @@ package reads
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors/mock"
+	"github.com/influxdata/influxql"
 	"github.com/stretchr/testify/require"
 )
 
@@ -49,6 +52,88 @@ func TestIntegerFilterArrayCursor(t *testing.T) {
 	}
 }
 
+// valueCondCursorIterator returns integer cursors over values, which evaluate
+// the value condition of the request themselves if filter is true.
+type valueCondCursorIterator struct {
+	values []int64
+	filter bool
+	conds  []influxql.Expr
+}
+
+func (i *valueCondCursorIterator) Next(ctx context.Context, req *cursors.CursorRequest) (cursors.Cursor, error) {
+	i.conds = append(i.conds, req.ValueCond)
+
+	filtered := i.filter && req.ValueCond != nil
+	a := &cursors.IntegerArray{}
+	for j, v := range i.values {
+		if filtered && !EvalExprBool(req.ValueCond, &singleValue{v: v}) {
+			continue
+		}
+		a.Timestamps = append(a.Timestamps, int64(j))
+		a.Values = append(a.Values, v)
+	}
+	return &valueCondCursor{a: a, filtered: filtered}, nil
+}
+
+func (i *valueCondCursorIterator) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+type valueCondCursor struct {
+	a        *cursors.IntegerArray
+	filtered bool
+}
+
+func (c *valueCondCursor) Close()                     {}
+func (c *valueCondCursor) Err() error                 { return nil }
+func (c *valueCondCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+func (c *valueCondCursor) Filtered() bool             { return c.filtered }
+
+func (c *valueCondCursor) Next() *cursors.IntegerArray {
+	a := c.a
+	c.a = &cursors.IntegerArray{}
+	return a
+}
+
+func TestMultiShardArrayCursors_ValueCond(t *testing.T) {
+	values := []int64{1, 8, 2, 7, 3, 6}
+	filtered := &valueCondCursorIterator{values: values, filter: true}
+	unfiltered := &valueCondCursorIterator{values: values}
+
+	m := newMultiShardArrayCursors(context.Background(), 0, math.MaxInt64, true)
+	for _, tt := range []struct {
+		cond influxql.Expr
+		want []int64
+	}{
+		{cond: influxql.MustParseExpr(`"$" > 5`), want: []int64{8, 7, 6, 8, 7, 6, 8, 7, 6}},
+		{cond: influxql.MustParseExpr(`"$" < 3`), want: []int64{1, 2, 1, 2, 1, 2}},
+		{cond: nil, want: append(append(values, values...), values...)},
+	} {
+		row := SeriesRow{
+			Field:     "f",
+			Query:     cursors.CursorIterators{filtered, unfiltered, filtered},
+			ValueCond: tt.cond,
+		}
+		cur := m.createCursor(row).(cursors.IntegerArrayCursor)
+
+		var got []int64
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			got = append(got, a.Values...)
+		}
+		if !cmp.Equal(got, tt.want) {
+			t.Errorf("unexpected values for %v; -got/+want\n%s", tt.cond, cmp.Diff(got, tt.want))
+		}
+	}
+
+	// The value condition is passed to the cursor iterators.
+	want := []influxql.Expr{
+		influxql.MustParseExpr(`"$" > 5`), influxql.MustParseExpr(`"$" > 5`),
+		influxql.MustParseExpr(`"$" < 3`), influxql.MustParseExpr(`"$" < 3`),
+		nil, nil,
+	}
+	if got := filtered.conds; !cmp.Equal(got, want) {
+		t.Errorf("unexpected value conditions; -got/+want\n%s", cmp.Diff(got, want))
+	}
+}
+
 func makeIntegerArray(n int, tsStart time.Time, tsStep time.Duration, valueFn func(i int64) int64) *cursors.IntegerArray {
 	ia := &cursors.IntegerArray{
 		Timestamps: make([]int64, n),
@@ -2157,6 +2242,241 @@ func TestWindowMeanArrayCursor(t *testing.T) {
 	}
 }
 
+func TestWindowStddevArrayCursor(t *testing.T) {
+	maxTimestamp := time.Unix(0, math.MaxInt64)
+
+	testcases := []aggArrayCursorTest{
+		{
+			name:  "no window",
+			every: 0,
+			inputArrays: []*cursors.IntegerArray{
+				makeIntegerArray(
+					5,
+					mustParseTime("2010-01-01T00:00:00Z"), time.Minute,
+					func(i int64) int64 { return i + 1 },
+				),
+			},
+			wantFloats: []*cursors.FloatArray{
+				makeFloatArray(1, maxTimestamp, 0, func(int64) float64 { return math.Sqrt(2.5) }),
+			},
+		},
+		{
+			name:        "no window empty",
+			every:       0,
+			inputArrays: []*cursors.IntegerArray{},
+			wantFloats:  []*cursors.FloatArray{},
+		},
+		{
+			name:  "window",
+			every: 30 * time.Minute,
+			inputArrays: []*cursors.IntegerArray{
+				makeIntegerArray(
+					8,
+					mustParseTime("2010-01-01T00:00:00Z"), 15*time.Minute,
+					func(i int64) int64 {
+						return i
+					},
+				),
+			},
+			wantFloats: []*cursors.FloatArray{
+				makeFloatArray(4, mustParseTime("2010-01-01T00:30:00Z"), 30*time.Minute,
+					func(i int64) float64 { return math.Sqrt(0.5) }),
+			},
+		},
+		{
+			name:  "window two input arrays",
+			every: 30 * time.Minute,
+			inputArrays: []*cursors.IntegerArray{
+				makeIntegerArray(
+					3,
+					mustParseTime("2010-01-01T00:00:00Z"), 15*time.Minute,
+					func(i int64) int64 {
+						return i
+					},
+				),
+				makeIntegerArray(
+					3,
+					mustParseTime("2010-01-01T00:45:00Z"), 15*time.Minute,
+					func(i int64) int64 {
+						return 3 + i
+					},
+				),
+			},
+			wantFloats: []*cursors.FloatArray{
+				makeFloatArray(3, mustParseTime("2010-01-01T00:30:00Z"), 30*time.Minute,
+					func(i int64) float64 { return math.Sqrt(0.5) }),
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc.createCursorFn = func(cur cursors.IntegerArrayCursor, every, offset int64, window execute.Window) cursors.Cursor {
+			if every != 0 || offset != 0 {
+				everyDur := values.MakeDuration(every, 0, false)
+				offsetDur := values.MakeDuration(offset, 0, false)
+				window = execute.Window{
+					Every:  everyDur,
+					Offset: offsetDur,
+				}
+			}
+			return newIntegerWindowStddevArrayCursor(cur, window)
+		}
+		tc.run(t)
+	}
+
+	t.Run("single point window", func(t *testing.T) {
+		// The sample standard deviation of a single point is undefined.
+		a := makeIntegerArray(1, mustParseTime("2010-01-01T00:00:00Z"), 0, func(int64) int64 { return 10 })
+		cur := newIntegerWindowStddevArrayCursor(&MockIntegerArrayCursor{
+			NextFunc: func() *cursors.IntegerArray {
+				defer func() { a = &cursors.IntegerArray{} }()
+				return a
+			},
+		}, execute.Window{})
+		got := cur.Next()
+		if got.Len() != 1 || !math.IsNaN(got.Values[0]) {
+			t.Fatalf("expected a single NaN value, got %v", got.Values)
+		}
+	})
+}
+
+func TestWindowSpreadArrayCursor(t *testing.T) {
+	maxTimestamp := time.Unix(0, math.MaxInt64)
+
+	testcases := []aggArrayCursorTest{
+		{
+			name:  "no window",
+			every: 0,
+			inputArrays: []*cursors.IntegerArray{
+				makeIntegerArray(
+					5,
+					mustParseTime("2010-01-01T00:00:00Z"), time.Minute,
+					func(i int64) int64 { return []int64{3, 1, 5, -2, 4}[i] },
+				),
+			},
+			wantIntegers: []*cursors.IntegerArray{
+				makeIntegerArray(1, maxTimestamp, 0, func(int64) int64 { return 7 }),
+			},
+		},
+		{
+			name:         "no window empty",
+			every:        0,
+			inputArrays:  []*cursors.IntegerArray{},
+			wantIntegers: []*cursors.IntegerArray{},
+		},
+		{
+			name:  "window",
+			every: 30 * time.Minute,
+			inputArrays: []*cursors.IntegerArray{
+				makeIntegerArray(
+					8,
+					mustParseTime("2010-01-01T00:00:00Z"), 15*time.Minute,
+					func(i int64) int64 {
+						return i * i
+					},
+				),
+			},
+			wantIntegers: []*cursors.IntegerArray{
+				makeIntegerArray(4, mustParseTime("2010-01-01T00:30:00Z"), 30*time.Minute,
+					func(i int64) int64 { return 1 + 4*i }),
+			},
+		},
+		{
+			name:  "empty window",
+			every: 15 * time.Minute,
+			inputArrays: []*cursors.IntegerArray{
+				makeIntegerArray(
+					2,
+					mustParseTime("2010-01-01T00:05:00Z"), 30*time.Minute,
+					func(i int64) int64 {
+						return 100 + i
+					},
+				),
+			},
+			wantIntegers: []*cursors.IntegerArray{
+				makeIntegerArray(2, mustParseTime("2010-01-01T00:15:00Z"), 30*time.Minute,
+					func(int64) int64 { return 0 }),
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc.createCursorFn = func(cur cursors.IntegerArrayCursor, every, offset int64, window execute.Window) cursors.Cursor {
+			if every != 0 || offset != 0 {
+				everyDur := values.MakeDuration(every, 0, false)
+				offsetDur := values.MakeDuration(offset, 0, false)
+				window = execute.Window{
+					Every:  everyDur,
+					Offset: offsetDur,
+				}
+			}
+			return newIntegerWindowSpreadArrayCursor(cur, window)
+		}
+		tc.run(t)
+	}
+}
+
+func TestWindowQuantileArrayCursor(t *testing.T) {
+	// Two windows of 100 minutes, each holding the values 0 to 99 in a
+	// random order and split over two input arrays.
+	rnd := rand.New(rand.NewSource(1))
+	var arrays []*cursors.FloatArray
+	for w := 0; w < 2; w++ {
+		perm := rnd.Perm(100)
+		start := mustParseTime("2010-01-01T00:00:00Z").Add(time.Duration(w) * 100 * time.Minute)
+		arrays = append(arrays,
+			makeFloatArray(50, start, time.Minute, func(i int64) float64 { return float64(perm[i]) }),
+			makeFloatArray(50, start.Add(50*time.Minute), time.Minute, func(i int64) float64 { return float64(perm[50+i]) }),
+		)
+	}
+
+	for _, tt := range []struct {
+		quantile float64
+		want     float64
+	}{
+		{quantile: 0, want: 0},
+		{quantile: 0.5, want: 49.5},
+		{quantile: 0.99, want: 98.5},
+		{quantile: 1, want: 99},
+	} {
+		t.Run(fmt.Sprintf("%v", tt.quantile), func(t *testing.T) {
+			i := 0
+			cur := &MockFloatArrayCursor{
+				NextFunc: func() *cursors.FloatArray {
+					if i < len(arrays) {
+						i++
+						return arrays[i-1]
+					}
+					return &cursors.FloatArray{}
+				},
+			}
+			window := execute.Window{
+				Every:  values.MakeDuration(int64(100*time.Minute), 0, false),
+				Period: values.MakeDuration(int64(100*time.Minute), 0, false),
+			}
+			c := newFloatWindowQuantileArrayCursor(cur, window, tt.quantile, DefaultQuantileCompression)
+
+			got := c.Next()
+			if got.Len() != 2 {
+				t.Fatalf("unexpected number of windows: got %d, want 2", got.Len())
+			}
+			for j, v := range got.Values {
+				if math.Abs(v-tt.want) > 1 {
+					t.Errorf("unexpected quantile in window %d: got %v, want %v", j, v, tt.want)
+				}
+			}
+			wantTimestamps := []int64{
+				mustParseTime("2010-01-01T01:40:00Z").UnixNano(),
+				mustParseTime("2010-01-01T03:20:00Z").UnixNano(),
+			}
+			if diff := cmp.Diff(got.Timestamps, wantTimestamps); diff != "" {
+				t.Errorf("unexpected timestamps; -got/+want:\n%v", diff)
+			}
+			if got := c.Next(); got.Len() != 0 {
+				t.Errorf("expected no more windows, got %d", got.Len())
+			}
+		})
+	}
+}
+
 // This test replicates GitHub issue
 // https://github.com/influxdata/influxdb/issues/20035
 func TestMultiShardArrayCursor(t *testing.T) {
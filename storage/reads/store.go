@@ -84,5 +84,21 @@ type Store interface {
 	TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error)
 	TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error)
 
+	// MeasurementNames returns the names of the measurements of the series
+	// matching the request with data in the range. Like the other schema
+	// methods, it uses the index of the shards the range covers, and only
+	// reads the data of the shards it partially covers.
+	MeasurementNames(ctx context.Context, req *datatypes.MeasurementNamesRequest) (cursors.StringIterator, error)
+	// MeasurementTagKeys returns the tag keys of the series of a measurement.
+	MeasurementTagKeys(ctx context.Context, req *datatypes.MeasurementTagKeysRequest) (cursors.StringIterator, error)
+	// MeasurementTagValues returns the values of a tag key of the series of
+	// a measurement.
+	MeasurementTagValues(ctx context.Context, req *datatypes.MeasurementTagValuesRequest) (cursors.StringIterator, error)
+	// MeasurementFields returns the field keys of a measurement.
+	MeasurementFields(ctx context.Context, req *datatypes.MeasurementFieldsRequest) (cursors.StringIterator, error)
+	// SeriesCardinality returns the number of series ReadFilter would return
+	// for the request, by the data type of their values.
+	SeriesCardinality(ctx context.Context, req *datatypes.ReadFilterRequest) (map[datatypes.ReadResponse_DataType]int64, error)
+
 	GetSource(orgID, bucketID uint64) proto.Message
 }
@@ -0,0 +1,373 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultVerifyBytesPerSecond is the default rate at which a storage
+	// verification reads data.
+	DefaultVerifyBytesPerSecond = 16 * 1024 * 1024
+
+	// maxFinishedVerifications is the number of finished verifications kept.
+	maxFinishedVerifications = 100
+)
+
+// verifier runs and keeps track of the storage verifications of the engine.
+type verifier struct {
+	*jobRegistry
+	metrics *verifyMetrics
+}
+
+type verifyJob struct {
+	v      influxdb.StorageVerification
+	shards []uint64
+}
+
+func newVerifier() *verifier {
+	metrics := newVerifyMetrics()
+	return &verifier{
+		jobRegistry: newJobRegistry(maxFinishedVerifications, metrics.active),
+		metrics:     metrics,
+	}
+}
+
+func (job *verifyJob) jobID() influxdb.ID { return job.v.ID }
+func (job *verifyJob) finished() bool     { return job.v.Status != influxdb.StorageVerificationRunning }
+
+// VerifyStorage starts the verification of the TSM files, tombstones and tsi1
+// index of the shards of a bucket, or of one of its shards, in the background.
+func (e *Engine) VerifyStorage(ctx context.Context, req influxdb.StorageVerificationRequest) (*influxdb.StorageVerification, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	if req.BytesPerSecond < 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bytes per second must not be negative",
+		}
+	} else if req.BytesPerSecond == 0 {
+		req.BytesPerSecond = DefaultVerifyBytesPerSecond
+	}
+
+	shards, err := e.bucketShardIDs(req.BucketID, req.ShardID)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &verifyJob{
+		v: influxdb.StorageVerification{
+			ID:        e.verifier.nextID(),
+			BucketID:  req.BucketID,
+			ShardID:   req.ShardID,
+			Status:    influxdb.StorageVerificationRunning,
+			StartedAt: time.Now().UTC(),
+			Progress:  influxdb.StorageVerificationProgress{ShardsTotal: len(shards)},
+			Issues:    []influxdb.StorageVerificationIssue{},
+		},
+		shards: shards,
+	}
+
+	// The job is copied before it starts, while nothing else refers to it.
+	verification := job.copyLocked()
+	if err := e.verifier.start(job, func(ctx context.Context) {
+		e.runVerification(ctx, job, req.BytesPerSecond)
+	}); err != nil {
+		return nil, err
+	}
+	return verification, nil
+}
+
+// bucketShardIDs returns the IDs of the shards of a bucket, or the given shard
+// ID if it belongs to the bucket.
+func (e *Engine) bucketShardIDs(bucketID influxdb.ID, shardID *uint64) ([]uint64, error) {
+	di := e.metaClient.Database(bucketID.String())
+	if di == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "bucket not found",
+		}
+	}
+
+	var ids []uint64
+	for _, rpi := range di.RetentionPolicies {
+		for _, sgi := range rpi.ShardGroups {
+			if sgi.Deleted() {
+				continue
+			}
+			for _, si := range sgi.Shards {
+				if shardID == nil || *shardID == si.ID {
+					ids = append(ids, si.ID)
+				}
+			}
+		}
+	}
+
+	if shardID != nil && len(ids) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "shard not found",
+		}
+	}
+	return ids, nil
+}
+
+// runVerification verifies the shards of job, reading at most bytesPerSecond.
+func (e *Engine) runVerification(ctx context.Context, job *verifyJob, bytesPerSecond int64) {
+	log := e.logger.With(zap.String("verification_id", job.v.ID.String()), zap.String("bucket_id", job.v.BucketID.String()))
+	log.Info("Storage verification started", zap.Int("shards", len(job.shards)))
+
+	burst := int(bytesPerSecond)
+	limiter := rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+	wait := func(n int) error {
+		for n > 0 {
+			m := n
+			if m > burst {
+				m = burst
+			}
+			if err := limiter.WaitN(ctx, m); err != nil {
+				return err
+			}
+			n -= m
+		}
+		return nil
+	}
+
+	var err error
+	for _, id := range job.shards {
+		if err = e.verifyShard(ctx, job, id, wait); err != nil {
+			break
+		}
+		e.verifier.updateVerification(job, func(v *influxdb.StorageVerification) {
+			v.Progress.ShardsVerified++
+		})
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+
+	e.verifier.updateVerification(job, func(v *influxdb.StorageVerification) {
+		now := time.Now().UTC()
+		v.FinishedAt = &now
+		switch {
+		case errors.Is(err, context.Canceled):
+			v.Status = influxdb.StorageVerificationCanceled
+		case err != nil:
+			v.Status, v.Error = influxdb.StorageVerificationFailed, err.Error()
+		case len(v.Issues) > 0:
+			v.Status = influxdb.StorageVerificationCorrupt
+		default:
+			v.Status = influxdb.StorageVerificationHealthy
+		}
+		e.verifier.metrics.verifications.WithLabelValues(v.Status).Inc()
+		log.Info("Storage verification finished", zap.String("status", v.Status), zap.Int("issues", len(v.Issues)), zap.Error(err))
+	})
+}
+
+// verifyShard verifies the TSM files, tombstones and tsi1 index of a shard.
+// Shards which have been deleted or are not open are skipped.
+func (e *Engine) verifyShard(ctx context.Context, job *verifyJob, id uint64, wait func(n int) error) error {
+	sh := e.tsdbStore.Shard(id)
+	if sh == nil {
+		return nil
+	}
+	engine, err := sh.Engine()
+	if err != nil {
+		e.logger.Info("Skipping verification of closed shard", zap.Uint64("shard_id", id), zap.Error(err))
+		return nil
+	}
+
+	if tsmEngine, ok := engine.(*tsm1.Engine); ok {
+		if err := tsmEngine.Verify(ctx, wait, func(fv tsm1.FileVerification) error {
+			var issues []influxdb.StorageVerificationIssue
+			if fv.BlockErr != nil {
+				issues = append(issues, influxdb.StorageVerificationIssue{ShardID: id, Kind: influxdb.StorageVerificationIssueTSM, Path: fv.Path, Message: fv.BlockErr.Error()})
+			}
+			if fv.TombstoneErr != nil {
+				issues = append(issues, influxdb.StorageVerificationIssue{ShardID: id, Kind: influxdb.StorageVerificationIssueTombstone, Path: fv.Path, Message: fv.TombstoneErr.Error()})
+			}
+			e.verifier.metrics.blocks.Add(float64(fv.Blocks))
+			e.verifier.metrics.bytes.Add(float64(fv.Bytes))
+			e.verifier.updateVerification(job, func(v *influxdb.StorageVerification) {
+				v.Progress.FilesVerified++
+				v.Progress.BlocksVerified += int64(fv.Blocks)
+				v.Progress.BytesVerified += fv.Bytes
+				v.Issues = append(v.Issues, issues...)
+			})
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	idx, err := sh.Index()
+	if err != nil {
+		return nil
+	}
+	if tsiIndex, ok := idx.(*tsi1.Index); ok {
+		if err := tsiIndex.Verify(ctx, wait); ctx.Err() != nil {
+			return ctx.Err()
+		} else if err != nil {
+			e.verifier.updateVerification(job, func(v *influxdb.StorageVerification) {
+				v.Issues = append(v.Issues, influxdb.StorageVerificationIssue{ShardID: id, Kind: influxdb.StorageVerificationIssueIndex, Path: tsiIndex.Path(), Message: err.Error()})
+			})
+		}
+	}
+	return nil
+}
+
+// updateVerification applies fn to the verification of job, and counts its
+// new issues.
+func (v *verifier) updateVerification(job *verifyJob, fn func(v *influxdb.StorageVerification)) {
+	v.update(func() {
+		n := len(job.v.Issues)
+		fn(&job.v)
+		for _, issue := range job.v.Issues[n:] {
+			v.metrics.issues.WithLabelValues(issue.Kind).Inc()
+		}
+	})
+}
+
+// copyLocked returns a copy of the verification of job.
+func (job *verifyJob) copyLocked() *influxdb.StorageVerification {
+	v := job.v
+	if v.ShardID != nil {
+		id := *v.ShardID
+		v.ShardID = &id
+	}
+	if v.FinishedAt != nil {
+		t := *v.FinishedAt
+		v.FinishedAt = &t
+	}
+	v.Issues = append([]influxdb.StorageVerificationIssue{}, v.Issues...)
+	return &v
+}
+
+// FindStorageVerifications returns the recent storage verifications matching
+// filter, in the order they were started.
+func (e *Engine) FindStorageVerifications(ctx context.Context, filter influxdb.StorageVerificationFilter) ([]*influxdb.StorageVerification, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var verifications []*influxdb.StorageVerification
+	e.verifier.forEach(func(j job) {
+		job := j.(*verifyJob)
+		if filter.BucketID != nil && *filter.BucketID != job.v.BucketID {
+			return
+		}
+		verifications = append(verifications, job.copyLocked())
+	})
+	return verifications, nil
+}
+
+// FindStorageVerificationByID returns a single storage verification by ID.
+func (e *Engine) FindStorageVerificationByID(ctx context.Context, id influxdb.ID) (*influxdb.StorageVerification, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var verification *influxdb.StorageVerification
+	if !e.verifier.find(id, func(j job) {
+		verification = j.(*verifyJob).copyLocked()
+	}) {
+		return nil, errVerificationNotFound
+	}
+	return verification, nil
+}
+
+// CancelStorageVerification cancels a running storage verification. Canceling
+// a finished verification has no effect.
+func (e *Engine) CancelStorageVerification(ctx context.Context, id influxdb.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if !e.verifier.cancel(id) {
+		return errVerificationNotFound
+	}
+	return nil
+}
+
+var errVerificationNotFound = &influxdb.Error{
+	Code: influxdb.ENotFound,
+	Msg:  "storage verification not found",
+}
+
+// verifyMetrics holds metrics related to storage verifications.
+type verifyMetrics struct {
+	active        prometheus.Gauge
+	verifications *prometheus.CounterVec
+	blocks        prometheus.Counter
+	bytes         prometheus.Counter
+	issues        *prometheus.CounterVec
+}
+
+func newVerifyMetrics() *verifyMetrics {
+	const (
+		namespace = "storage"
+		subsystem = "verify"
+	)
+
+	return &verifyMetrics{
+		active: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "active",
+			Help:      "Number of running storage verifications",
+		}),
+
+		verifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "verifications_total",
+			Help:      "Number of finished storage verifications, by status",
+		}, []string{"status"}),
+
+		blocks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "blocks_total",
+			Help:      "Number of TSM blocks verified",
+		}),
+
+		bytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "bytes_total",
+			Help:      "Number of bytes of TSM blocks verified",
+		}),
+
+		issues: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "issues_total",
+			Help:      "Number of corruptions found by storage verifications, by kind",
+		}, []string{"kind"}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *verifyMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.active,
+		m.verifications,
+		m.blocks,
+		m.bytes,
+		m.issues,
+	}
+}
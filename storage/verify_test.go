@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_VerifyStorage(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+	e.writeTestPoints(t, bucketID)
+
+	v, err := e.VerifyStorage(context.Background(), influxdb.StorageVerificationRequest{BucketID: bucketID})
+	require.NoError(t, err)
+	assert.Equal(t, influxdb.StorageVerificationRunning, v.Status)
+	assert.Nil(t, v.FinishedAt)
+	assert.Equal(t, 1, v.Progress.ShardsTotal)
+
+	v = e.waitVerification(t, v.ID)
+	assert.Equal(t, influxdb.StorageVerificationHealthy, v.Status)
+	assert.NotNil(t, v.FinishedAt)
+	assert.Empty(t, v.Issues)
+	assert.Equal(t, 1, v.Progress.ShardsVerified)
+	assert.Equal(t, 1, v.Progress.FilesVerified)
+	assert.NotZero(t, v.Progress.BlocksVerified)
+	assert.NotZero(t, v.Progress.BytesVerified)
+
+	verifications, err := e.FindStorageVerifications(context.Background(), influxdb.StorageVerificationFilter{BucketID: &bucketID})
+	require.NoError(t, err)
+	require.Len(t, verifications, 1)
+	assert.Equal(t, v, verifications[0])
+
+	otherID := influxdb.ID(2)
+	verifications, err = e.FindStorageVerifications(context.Background(), influxdb.StorageVerificationFilter{BucketID: &otherID})
+	require.NoError(t, err)
+	assert.Empty(t, verifications)
+}
+
+func TestEngine_VerifyStorage_Corrupt(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+	e.writeTestPoints(t, bucketID)
+
+	// Flip a byte of the first block, after the header of the file and the
+	// checksum of the block.
+	paths, err := filepath.Glob(filepath.Join(e.path, "data", "*", "*", "*", "*."+tsm1.TSMFileExtension))
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	f, err := os.OpenFile(paths[0], os.O_RDWR, 0)
+	require.NoError(t, err)
+	b := make([]byte, 1)
+	_, err = f.ReadAt(b, 10)
+	require.NoError(t, err)
+	b[0] ^= 0xff
+	_, err = f.WriteAt(b, 10)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	v, err := e.VerifyStorage(context.Background(), influxdb.StorageVerificationRequest{BucketID: bucketID})
+	require.NoError(t, err)
+
+	v = e.waitVerification(t, v.ID)
+	assert.Equal(t, influxdb.StorageVerificationCorrupt, v.Status)
+	require.Len(t, v.Issues, 1)
+	assert.Equal(t, influxdb.StorageVerificationIssueTSM, v.Issues[0].Kind)
+	assert.Equal(t, paths[0], v.Issues[0].Path)
+}
+
+func TestEngine_VerifyStorage_NotFound(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+
+	_, err := e.VerifyStorage(context.Background(), influxdb.StorageVerificationRequest{BucketID: 2})
+	assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+
+	shardID := uint64(100)
+	_, err = e.VerifyStorage(context.Background(), influxdb.StorageVerificationRequest{BucketID: bucketID, ShardID: &shardID})
+	assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+
+	_, err = e.VerifyStorage(context.Background(), influxdb.StorageVerificationRequest{BucketID: bucketID, BytesPerSecond: -1})
+	assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+
+	_, err = e.FindStorageVerificationByID(context.Background(), 1)
+	assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+	assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(e.CancelStorageVerification(context.Background(), 1)))
+}
+
+func TestEngine_VerifyStorage_Throttle(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+	e.writeTestPoints(t, bucketID)
+
+	// Verifying a single series key takes several seconds at one byte per
+	// second.
+	v, err := e.VerifyStorage(context.Background(), influxdb.StorageVerificationRequest{BucketID: bucketID, BytesPerSecond: 1})
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	v, err = e.FindStorageVerificationByID(context.Background(), v.ID)
+	require.NoError(t, err)
+	assert.Equal(t, influxdb.StorageVerificationRunning, v.Status)
+	assert.Zero(t, v.Progress.ShardsVerified)
+	assert.Equal(t, float64(1), testutil.ToFloat64(e.verifier.metrics.active))
+
+	require.NoError(t, e.CancelStorageVerification(context.Background(), v.ID))
+	v = e.waitVerification(t, v.ID)
+	assert.Equal(t, influxdb.StorageVerificationCanceled, v.Status)
+	assert.NotNil(t, v.FinishedAt)
+	assert.Empty(t, v.Error)
+
+	// Canceling a finished verification has no effect.
+	require.NoError(t, e.CancelStorageVerification(context.Background(), v.ID))
+	v2, err := e.FindStorageVerificationByID(context.Background(), v.ID)
+	require.NoError(t, err)
+	assert.Equal(t, v, v2)
+}
+
+func TestEngine_VerifyStorage_Close(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+	e.writeTestPoints(t, bucketID)
+
+	v, err := e.VerifyStorage(context.Background(), influxdb.StorageVerificationRequest{BucketID: bucketID, BytesPerSecond: 1})
+	require.NoError(t, err)
+
+	// Closing the engine cancels the running verifications.
+	require.NoError(t, e.Close())
+	v, err = e.FindStorageVerificationByID(context.Background(), v.ID)
+	require.NoError(t, err)
+	assert.Equal(t, influxdb.StorageVerificationCanceled, v.Status)
+
+	_, err = e.VerifyStorage(context.Background(), influxdb.StorageVerificationRequest{BucketID: bucketID})
+	assert.Equal(t, ErrEngineClosed, err)
+}
+
+func TestEngine_VerifyStorage_Prune(t *testing.T) {
+	e := newTestEngine(t)
+	bucketID := e.createTestBucket(t, 1)
+
+	// The verifications of a bucket without shards finish immediately.
+	var ids []influxdb.ID
+	for i := 0; i < maxFinishedVerifications+2; i++ {
+		v, err := e.VerifyStorage(context.Background(), influxdb.StorageVerificationRequest{BucketID: bucketID})
+		require.NoError(t, err)
+		e.waitVerification(t, v.ID)
+		ids = append(ids, v.ID)
+	}
+
+	// The oldest verification is pruned when the last one starts, which keeps
+	// the last one and the finished verifications before it.
+	verifications, err := e.FindStorageVerifications(context.Background(), influxdb.StorageVerificationFilter{})
+	require.NoError(t, err)
+	require.Len(t, verifications, maxFinishedVerifications+1)
+	for i, v := range verifications {
+		assert.Equal(t, ids[i+1], v.ID)
+	}
+
+	_, err = e.FindStorageVerificationByID(context.Background(), ids[0])
+	assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+}
+
+// waitVerification waits for a verification to finish, and returns it.
+func (e *testEngine) waitVerification(t *testing.T, id influxdb.ID) *influxdb.StorageVerification {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		v, err := e.FindStorageVerificationByID(context.Background(), id)
+		require.NoError(t, err)
+		if v.Status != influxdb.StorageVerificationRunning {
+			return v
+		} else if time.Now().After(deadline) {
+			t.Fatalf("verification %s still running", id)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,98 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// Statuses of a storage verification.
+const (
+	// StorageVerificationRunning is the status of a verification in progress.
+	StorageVerificationRunning = "running"
+	// StorageVerificationHealthy is the status of a verification which found
+	// no corruption.
+	StorageVerificationHealthy = "healthy"
+	// StorageVerificationCorrupt is the status of a verification which found
+	// corruption, reported in its issues.
+	StorageVerificationCorrupt = "corrupt"
+	// StorageVerificationFailed is the status of a verification which could
+	// not complete.
+	StorageVerificationFailed = "failed"
+	// StorageVerificationCanceled is the status of a canceled verification.
+	StorageVerificationCanceled = "canceled"
+)
+
+// Kinds of storage verification issues.
+const (
+	StorageVerificationIssueTSM       = "tsm"
+	StorageVerificationIssueTombstone = "tombstone"
+	StorageVerificationIssueIndex     = "index"
+)
+
+// StorageVerification is a job verifying the integrity of the stored data of a
+// bucket, or of one of its shards, while the server runs.
+type StorageVerification struct {
+	ID         ID                          `json:"id"`
+	BucketID   ID                          `json:"bucketID"`
+	ShardID    *uint64                     `json:"shardID,omitempty"`
+	Status     string                      `json:"status"`
+	StartedAt  time.Time                   `json:"startedAt"`
+	FinishedAt *time.Time                  `json:"finishedAt,omitempty"`
+	Progress   StorageVerificationProgress `json:"progress"`
+	Issues     []StorageVerificationIssue  `json:"issues"`
+
+	// Error is the reason a failed verification could not complete.
+	Error string `json:"error,omitempty"`
+}
+
+// StorageVerificationProgress is the progress of a storage verification.
+type StorageVerificationProgress struct {
+	ShardsTotal    int   `json:"shardsTotal"`
+	ShardsVerified int   `json:"shardsVerified"`
+	FilesVerified  int   `json:"filesVerified"`
+	BlocksVerified int64 `json:"blocksVerified"`
+	BytesVerified  int64 `json:"bytesVerified"`
+}
+
+// StorageVerificationIssue is a corruption found by a storage verification.
+type StorageVerificationIssue struct {
+	ShardID uint64 `json:"shardID"`
+	Kind    string `json:"kind"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// StorageVerificationRequest is a request to verify the stored data of a
+// bucket, or of one of its shards.
+type StorageVerificationRequest struct {
+	BucketID ID      `json:"bucketID"`
+	ShardID  *uint64 `json:"shardID,omitempty"`
+
+	// BytesPerSecond limits the rate at which the data is read, to limit the
+	// impact of the verification on the server. The default rate is used if 0.
+	BytesPerSecond int64 `json:"bytesPerSecond,omitempty"`
+}
+
+// StorageVerificationFilter represents a set of filters that restrict the
+// returned storage verifications.
+type StorageVerificationFilter struct {
+	BucketID *ID
+}
+
+// StorageVerifyService verifies the integrity of the stored data while the
+// server runs.
+type StorageVerifyService interface {
+	// VerifyStorage starts the verification of the stored data of a bucket,
+	// or of one of its shards, in the background.
+	VerifyStorage(ctx context.Context, req StorageVerificationRequest) (*StorageVerification, error)
+
+	// FindStorageVerifications returns the recent verifications matching
+	// filter, in the order they were started.
+	FindStorageVerifications(ctx context.Context, filter StorageVerificationFilter) ([]*StorageVerification, error)
+
+	// FindStorageVerificationByID returns a single verification by ID.
+	FindStorageVerificationByID(ctx context.Context, id ID) (*StorageVerification, error)
+
+	// CancelStorageVerification cancels a running verification.
+	CancelStorageVerification(ctx context.Context, id ID) error
+}
@@ -0,0 +1,120 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Subscription modes.
+const (
+	// SubscriptionModeAll sends every write to all destinations.
+	SubscriptionModeAll = "ALL"
+	// SubscriptionModeAny sends every write to one of the destinations, in turn.
+	SubscriptionModeAny = "ANY"
+)
+
+// ErrSubscriptionNotFound is returned when a subscription cannot be found.
+var ErrSubscriptionNotFound = &Error{
+	Code: ENotFound,
+	Msg:  "subscription not found",
+}
+
+// Subscription forwards the points written to a bucket to external HTTP or
+// UDP destinations, such as Kapacitor.
+type Subscription struct {
+	ID       ID     `json:"id,omitempty"`
+	OrgID    ID     `json:"orgID"`
+	BucketID ID     `json:"bucketID"`
+	Name     string `json:"name"`
+	Mode     string `json:"mode"`
+
+	// Destinations are the http, https or udp URLs the points are sent to.
+	Destinations []string `json:"destinations"`
+
+	// Database and RetentionPolicy are sent along with the points to HTTP
+	// destinations, which commonly expect the 1.x write API. They default to
+	// the names the storage engine uses for the bucket.
+	Database        string `json:"database,omitempty"`
+	RetentionPolicy string `json:"retentionPolicy,omitempty"`
+
+	CRUDLog
+}
+
+// Valid returns an error if the subscription is invalid.
+func (s *Subscription) Valid() error {
+	if s.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "subscription name is required",
+		}
+	}
+	if !s.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "subscription organization ID is invalid",
+		}
+	}
+	if !s.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "subscription bucket ID is invalid",
+		}
+	}
+	if s.Mode != SubscriptionModeAll && s.Mode != SubscriptionModeAny {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("invalid subscription mode %q, must be %s or %s", s.Mode, SubscriptionModeAll, SubscriptionModeAny),
+		}
+	}
+	if len(s.Destinations) == 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "subscription requires at least one destination",
+		}
+	}
+	for _, dest := range s.Destinations {
+		u, err := url.Parse(dest)
+		if err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid subscription destination %q", dest),
+				Err:  err,
+			}
+		}
+		switch u.Scheme {
+		case "http", "https", "udp":
+		default:
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid subscription destination %q, scheme must be http, https or udp", dest),
+			}
+		}
+	}
+	return nil
+}
+
+// SubscriptionFilter represents a set of filters that restrict the returned subscriptions.
+type SubscriptionFilter struct {
+	ID       *ID
+	OrgID    *ID
+	BucketID *ID
+	Name     *string
+}
+
+// SubscriptionService represents a service for managing subscriptions.
+type SubscriptionService interface {
+	// FindSubscriptionByID returns a single subscription by ID.
+	FindSubscriptionByID(ctx context.Context, id ID) (*Subscription, error)
+
+	// FindSubscriptions returns the subscriptions matching filter and the
+	// total count of matching subscriptions.
+	FindSubscriptions(ctx context.Context, filter SubscriptionFilter) ([]*Subscription, int, error)
+
+	// CreateSubscription creates a new subscription and sets s.ID with the new identifier.
+	// The name of a subscription is unique within its bucket.
+	CreateSubscription(ctx context.Context, s *Subscription) error
+
+	// DeleteSubscription removes a subscription by ID.
+	DeleteSubscription(ctx context.Context, id ID) error
+}
@@ -0,0 +1,48 @@
+package subscription
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// BucketService wraps an influxdb.BucketService so that the subscriptions of
+// a bucket are deleted along with it.
+type BucketService struct {
+	influxdb.BucketService
+	Logger              *zap.Logger
+	SubscriptionService influxdb.SubscriptionService
+}
+
+// NewBucketService returns a BucketService deleting the subscriptions of the
+// buckets deleted from bucketService with subscriptionService.
+func NewBucketService(logger *zap.Logger, bucketService influxdb.BucketService, subscriptionService influxdb.SubscriptionService) *BucketService {
+	return &BucketService{
+		Logger:              logger,
+		BucketService:       bucketService,
+		SubscriptionService: subscriptionService,
+	}
+}
+
+// DeleteBucket removes a bucket by ID, and then its subscriptions.
+func (s *BucketService) DeleteBucket(ctx context.Context, id influxdb.ID) error {
+	if err := s.BucketService.DeleteBucket(ctx, id); err != nil {
+		return err
+	}
+
+	logger := s.Logger.With(zap.String("bucket_id", id.String()))
+	subs, _, err := s.SubscriptionService.FindSubscriptions(ctx, influxdb.SubscriptionFilter{
+		BucketID: &id,
+	})
+	if err != nil {
+		logger.Error("Failed to lookup subscriptions for Bucket.", zap.Error(err))
+		return nil
+	}
+	for _, sub := range subs {
+		if err := s.SubscriptionService.DeleteSubscription(ctx, sub.ID); err != nil {
+			logger.Error("Failed to delete subscription for Bucket.", zap.Error(err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// Package subscription stores the subscriptions which forward the points
+// written to a bucket to external destinations.
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+var bucket = []byte("subscriptionsv1")
+
+var _ influxdb.SubscriptionService = (*Service)(nil)
+
+// ErrSubscriptionExists is returned when creating a subscription with the
+// name of another subscription of the bucket.
+var ErrSubscriptionExists = &influxdb.Error{
+	Code: influxdb.EConflict,
+	Msg:  "subscription already exists",
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
+
+// Service is a kv backed influxdb.SubscriptionService. Subscriptions are few,
+// so they are stored without indexes and filtered by scanning.
+type Service struct {
+	store kv.Store
+	IDGen influxdb.IDGenerator
+	now   func() time.Time
+}
+
+// NewService returns a subscription service backed by st.
+func NewService(st kv.Store) *Service {
+	return &Service{
+		store: st,
+		IDGen: snowflake.NewDefaultIDGenerator(),
+		now:   time.Now,
+	}
+}
+
+// FindSubscriptionByID returns a single subscription by ID.
+func (s *Service) FindSubscriptionByID(ctx context.Context, id influxdb.ID) (*influxdb.Subscription, error) {
+	var sub *influxdb.Subscription
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		sub, err = s.findSubscriptionByID(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *Service) findSubscriptionByID(tx kv.Tx, id influxdb.ID) (*influxdb.Subscription, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, influxdb.ErrSubscriptionNotFound
+	} else if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	var sub influxdb.Subscription
+	if err := json.Unmarshal(v, &sub); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return &sub, nil
+}
+
+// FindSubscriptions returns the subscriptions matching filter and the total
+// count of matching subscriptions.
+func (s *Service) FindSubscriptions(ctx context.Context, filter influxdb.SubscriptionFilter) ([]*influxdb.Subscription, int, error) {
+	if filter.ID != nil {
+		sub, err := s.FindSubscriptionByID(ctx, *filter.ID)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				return []*influxdb.Subscription{}, 0, nil
+			}
+			return nil, 0, err
+		}
+		if !filterFunc(sub, filter) {
+			return []*influxdb.Subscription{}, 0, nil
+		}
+		return []*influxdb.Subscription{sub}, 1, nil
+	}
+
+	subs := []*influxdb.Subscription{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		subs, err = s.findSubscriptions(tx, filter)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return subs, len(subs), nil
+}
+
+func (s *Service) findSubscriptions(tx kv.Tx, filter influxdb.SubscriptionFilter) ([]*influxdb.Subscription, error) {
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	subs := []*influxdb.Subscription{}
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		var sub influxdb.Subscription
+		if err := json.Unmarshal(v, &sub); err != nil {
+			return nil, ErrInternalService(err)
+		}
+		if filterFunc(&sub, filter) {
+			subs = append(subs, &sub)
+		}
+	}
+	return subs, nil
+}
+
+func filterFunc(sub *influxdb.Subscription, filter influxdb.SubscriptionFilter) bool {
+	return (filter.ID == nil || *filter.ID == sub.ID) &&
+		(filter.OrgID == nil || *filter.OrgID == sub.OrgID) &&
+		(filter.BucketID == nil || *filter.BucketID == sub.BucketID) &&
+		(filter.Name == nil || *filter.Name == sub.Name)
+}
+
+// CreateSubscription creates a new subscription and sets sub.ID with the new
+// identifier. The name of a subscription is unique within its bucket.
+func (s *Service) CreateSubscription(ctx context.Context, sub *influxdb.Subscription) error {
+	if err := sub.Valid(); err != nil {
+		return err
+	}
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		subs, err := s.findSubscriptions(tx, influxdb.SubscriptionFilter{
+			BucketID: &sub.BucketID,
+			Name:     &sub.Name,
+		})
+		if err != nil {
+			return err
+		} else if len(subs) > 0 {
+			return ErrSubscriptionExists
+		}
+
+		sub.ID = s.IDGen.ID()
+		now := s.now()
+		sub.SetCreatedAt(now)
+		sub.SetUpdatedAt(now)
+		return s.putSubscription(tx, sub)
+	})
+}
+
+func (s *Service) putSubscription(tx kv.Tx, sub *influxdb.Subscription) error {
+	encodedID, err := sub.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	v, err := json.Marshal(sub)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+// DeleteSubscription removes a subscription by ID.
+func (s *Service) DeleteSubscription(ctx context.Context, id influxdb.ID) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		if _, err := s.findSubscriptionByID(tx, id); err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+		b, err := tx.Bucket(bucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := b.Delete(encodedID); err != nil {
+			return ErrInternalService(err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,171 @@
+package subscription_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/subscription"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestBoltStore(t *testing.T) (kv.Store, func()) {
+	t.Helper()
+
+	f, err := ioutil.TempFile("", "influxdata-bolt-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	path := f.Name()
+	s := bolt.NewKVStore(logger, path, bolt.WithNoSync)
+	if err := s.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := all.Up(ctx, logger, s); err != nil {
+		t.Fatal(err)
+	}
+
+	return s, func() {
+		s.Close()
+		os.Remove(path)
+	}
+}
+
+func TestService(t *testing.T) {
+	st, closeStore := newTestBoltStore(t)
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := subscription.NewService(st)
+	svc.IDGen = mock.NewMockIDGenerator()
+
+	sub := &influxdb.Subscription{
+		OrgID:        1,
+		BucketID:     2,
+		Name:         "kapacitor",
+		Mode:         influxdb.SubscriptionModeAll,
+		Destinations: []string{"http://localhost:9092"},
+	}
+	if err := svc.CreateSubscription(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	if !sub.ID.Valid() {
+		t.Fatal("expected subscription ID to be set")
+	}
+
+	// Names are unique within a bucket.
+	dup := &influxdb.Subscription{
+		OrgID:        1,
+		BucketID:     2,
+		Name:         "kapacitor",
+		Mode:         influxdb.SubscriptionModeAny,
+		Destinations: []string{"udp://localhost:9100"},
+	}
+	if err := svc.CreateSubscription(ctx, dup); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Invalid subscriptions are rejected.
+	invalid := &influxdb.Subscription{
+		OrgID:        1,
+		BucketID:     2,
+		Name:         "invalid",
+		Mode:         influxdb.SubscriptionModeAll,
+		Destinations: []string{"tcp://localhost:9100"},
+	}
+	if err := svc.CreateSubscription(ctx, invalid); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := svc.FindSubscriptionByID(ctx, sub.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(sub, got, cmpopts.EquateApproxTime(0)); diff != "" {
+		t.Fatalf("unexpected subscription -want/+got\n%s", diff)
+	}
+
+	bucketID, otherBucketID := influxdb.ID(2), influxdb.ID(3)
+	for _, tt := range []struct {
+		filter influxdb.SubscriptionFilter
+		n      int
+	}{
+		{filter: influxdb.SubscriptionFilter{}, n: 1},
+		{filter: influxdb.SubscriptionFilter{ID: &sub.ID}, n: 1},
+		{filter: influxdb.SubscriptionFilter{BucketID: &bucketID}, n: 1},
+		{filter: influxdb.SubscriptionFilter{BucketID: &otherBucketID}, n: 0},
+		{filter: influxdb.SubscriptionFilter{ID: &sub.ID, BucketID: &otherBucketID}, n: 0},
+	} {
+		subs, n, err := svc.FindSubscriptions(ctx, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != tt.n || len(subs) != tt.n {
+			t.Errorf("unexpected number of subscriptions for %+v: %d", tt.filter, n)
+		}
+	}
+
+	if err := svc.DeleteSubscription(ctx, sub.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindSubscriptionByID(ctx, sub.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.DeleteSubscription(ctx, sub.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBucketService_DeleteBucket(t *testing.T) {
+	st, closeStore := newTestBoltStore(t)
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := subscription.NewService(st)
+
+	for _, sub := range []*influxdb.Subscription{
+		{OrgID: 1, BucketID: 2, Name: "s0", Mode: influxdb.SubscriptionModeAll, Destinations: []string{"http://localhost:9092"}},
+		{OrgID: 1, BucketID: 2, Name: "s1", Mode: influxdb.SubscriptionModeAll, Destinations: []string{"http://localhost:9092"}},
+		{OrgID: 1, BucketID: 3, Name: "s0", Mode: influxdb.SubscriptionModeAll, Destinations: []string{"http://localhost:9092"}},
+	} {
+		if err := svc.CreateSubscription(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var deleted influxdb.ID
+	buckets := mock.NewBucketService()
+	buckets.DeleteBucketFn = func(_ context.Context, id influxdb.ID) error {
+		deleted = id
+		return nil
+	}
+
+	bs := subscription.NewBucketService(zaptest.NewLogger(t), buckets, svc)
+	if err := bs.DeleteBucket(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Fatalf("unexpected deleted bucket: %v", deleted)
+	}
+
+	subs, _, err := svc.FindSubscriptions(ctx, influxdb.SubscriptionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 1 || subs[0].BucketID != 3 {
+		t.Fatalf("unexpected subscriptions after deleting bucket: %+v", subs)
+	}
+}
@@ -61,6 +61,7 @@ type executorConfig struct {
 	systemBuildCompiler    CompilerBuilderFunc
 	nonSystemBuildCompiler CompilerBuilderFunc
 	flagger                feature.Flagger
+	taskRunners            map[string]TaskRunner
 }
 
 type executorOption func(*executorConfig)
@@ -124,6 +125,24 @@ func WithFlagger(flagger feature.Flagger) executorOption {
 	}
 }
 
+// TaskRunner runs tasks which are not executed as a Flux query, such as
+// InfluxQL continuous queries. The context.Context provided can be assumed
+// to be an authorized context.
+type TaskRunner interface {
+	RunTask(ctx context.Context, task *influxdb.Task, scheduledFor time.Time) error
+}
+
+// WithTaskRunner is an Executor option that runs tasks of the given type
+// with r, rather than compiling and querying their Flux script.
+func WithTaskRunner(taskType string, r TaskRunner) executorOption {
+	return func(o *executorConfig) {
+		if o.taskRunners == nil {
+			o.taskRunners = make(map[string]TaskRunner)
+		}
+		o.taskRunners[taskType] = r
+	}
+}
+
 // NewExecutor creates a new task executor
 func NewExecutor(log *zap.Logger, qs query.QueryService, us PermissionService, ts influxdb.TaskService, tcs backend.TaskControlService, opts ...executorOption) (*Executor, *ExecutorMetrics) {
 	cfg := &executorConfig{
@@ -149,6 +168,7 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, us PermissionService, t
 		systemBuildCompiler:    cfg.systemBuildCompiler,
 		nonSystemBuildCompiler: cfg.nonSystemBuildCompiler,
 		flagger:                cfg.flagger,
+		taskRunners:            cfg.taskRunners,
 	}
 
 	e.metrics = NewExecutorMetrics(e)
@@ -187,6 +207,9 @@ type Executor struct {
 	nonSystemBuildCompiler CompilerBuilderFunc
 	systemBuildCompiler    CompilerBuilderFunc
 	flagger                feature.Flagger
+
+	// taskRunners run tasks of a given type instead of their Flux script.
+	taskRunners map[string]TaskRunner
 }
 
 // SetLimitFunc sets the limit func for this task executor
@@ -493,6 +516,11 @@ func (w *worker) executeQuery(p *promise) {
 
 	ctx = icontext.SetAuthorizer(ctx, p.auth)
 
+	if r, ok := w.e.taskRunners[p.task.Type]; ok {
+		w.runTask(ctx, p, r)
+		return
+	}
+
 	buildCompiler := w.systemBuildCompiler
 	if p.task.Type != influxdb.TaskSystemType {
 		buildCompiler = w.nonSystemBuildCompiler
@@ -550,6 +578,16 @@ func (w *worker) executeQuery(p *promise) {
 	w.finish(p, influxdb.RunSuccess, nil)
 }
 
+// runTask runs the task of p with r rather than as a Flux query.
+func (w *worker) runTask(ctx context.Context, p *promise, r TaskRunner) {
+	if err := r.RunTask(ctx, p.task, p.run.ScheduledFor); err != nil {
+		w.finish(p, influxdb.RunFail, influxdb.ErrRunExecutionError(err))
+		return
+	}
+
+	w.finish(p, influxdb.RunSuccess, nil)
+}
+
 // RunsActive returns the current number of workers, which is equivalent to
 // the number of runs actively running
 func (e *Executor) RunsActive() int {
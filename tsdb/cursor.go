@@ -21,6 +21,7 @@ type (
 	BooleanArrayCursor  = cursors.BooleanArrayCursor
 
 	Cursor          = cursors.Cursor
+	FilteredCursor  = cursors.FilteredCursor
 	CursorStats     = cursors.CursorStats
 	CursorRequest   = cursors.CursorRequest
 	CursorIterator  = cursors.CursorIterator
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxql"
 )
 
 const DefaultMaxPointsPerBlock = 1000
@@ -46,6 +47,22 @@ type CursorRequest struct {
 	Ascending bool
 	StartTime int64
 	EndTime   int64
+
+	// ValueCond is an optional condition on the values of the field, where
+	// the values are referenced as "$". Cursor iterators may evaluate it
+	// while reading the values, in which case the returned cursor implements
+	// FilteredCursor.
+	ValueCond influxql.Expr
+}
+
+// FilteredCursor is implemented by the cursors which may only return the
+// values matching the ValueCond of their request.
+type FilteredCursor interface {
+	Cursor
+
+	// Filtered returns true if the cursor only returns the values matching
+	// the ValueCond of its request.
+	Filtered() bool
 }
 
 type CursorIterator interface {
@@ -57,6 +57,7 @@ type Engine interface {
 	CreateSeriesListIfNotExists(keys, names [][]byte, tags []models.Tags) error
 	DeleteSeriesRange(itr SeriesIterator, min, max int64) error
 	DeleteSeriesRangeWithPredicate(itr SeriesIterator, predicate func(name []byte, tags models.Tags) (int64, int64, bool)) error
+	DeleteSeriesFieldsRange(itr SeriesIterator, min, max int64, predicate func(seriesKey, field []byte) bool) error
 
 	MeasurementsSketches() (estimator.Sketch, estimator.Sketch, error)
 	SeriesSketches() (estimator.Sketch, estimator.Sketch, error)
@@ -27,8 +27,9 @@ type floatArrayAscendingCursor struct {
 		keyCursor *KeyCursor
 	}
 
-	end int64
-	res *tsdb.FloatArray
+	end  int64
+	pred func(v float64) bool
+	res  *tsdb.FloatArray
 }
 
 func newFloatArrayAscendingCursor() *floatArrayAscendingCursor {
@@ -39,8 +40,11 @@ func newFloatArrayAscendingCursor() *floatArrayAscendingCursor {
 	return c
 }
 
-func (c *floatArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+// reset resets the cursor to read the values from seek to end. If pred is
+// not nil, only the values matching pred are returned.
+func (c *floatArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor, pred func(v float64) bool) {
 	c.end = end
+	c.pred = pred
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
 		return c.cache.values[i].UnixNano() >= seek
@@ -51,10 +55,17 @@ func (c *floatArrayAscendingCursor) reset(seek, end int64, cacheValues Values, t
 	c.tsm.pos = sort.Search(c.tsm.values.Len(), func(i int) bool {
 		return c.tsm.values.Timestamps[i] >= seek
 	})
+	if c.pred != nil {
+		c.filterTSM()
+	}
 }
 
 func (c *floatArrayAscendingCursor) Err() error { return nil }
 
+// Filtered returns true if the cursor only returns the values matching its
+// predicate.
+func (c *floatArrayAscendingCursor) Filtered() bool { return c.pred != nil }
+
 func (c *floatArrayAscendingCursor) Stats() tsdb.CursorStats {
 	return tsdb.CursorStats{}
 }
@@ -96,7 +107,10 @@ func (c *floatArrayAscendingCursor) Next() *tsdb.FloatArray {
 			c.tsm.pos++
 		}
 
-		pos++
+		// The values of the TSM blocks have already been filtered.
+		if ckey > tkey || c.pred == nil || c.pred(c.res.Values[pos]) {
+			pos++
+		}
 
 		if c.tsm.pos >= len(tvals.Timestamps) {
 			tvals = c.nextTSM()
@@ -129,8 +143,10 @@ func (c *floatArrayAscendingCursor) Next() *tsdb.FloatArray {
 			for pos < len(c.res.Timestamps) && c.cache.pos < len(cvals) {
 				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
 				c.res.Values[pos] = cvals[c.cache.pos].(FloatValue).value
-				pos++
 				c.cache.pos++
+				if c.pred == nil || c.pred(c.res.Values[pos]) {
+					pos++
+				}
 			}
 		}
 	}
@@ -153,9 +169,40 @@ func (c *floatArrayAscendingCursor) nextTSM() *tsdb.FloatArray {
 	c.tsm.keyCursor.Next()
 	c.tsm.values, _ = c.tsm.keyCursor.ReadFloatArrayBlock(c.tsm.buf)
 	c.tsm.pos = 0
+	if c.pred != nil {
+		c.filterTSM()
+	}
 	return c.tsm.values
 }
 
+// filterTSM keeps the values of the current TSM block from c.tsm.pos on
+// which match the predicate. If no value matches, it reads the next blocks
+// until one has matching values, or a block ends after c.end.
+func (c *floatArrayAscendingCursor) filterTSM() {
+	for {
+		a := c.tsm.values
+		n := 0
+		for i := c.tsm.pos; i < len(a.Timestamps); i++ {
+			if c.pred(a.Values[i]) {
+				a.Timestamps[n] = a.Timestamps[i]
+				a.Values[n] = a.Values[i]
+				n++
+			}
+		}
+
+		if n > 0 || len(a.Timestamps) == 0 || a.Timestamps[len(a.Timestamps)-1] >= c.end {
+			a.Timestamps = a.Timestamps[:n]
+			a.Values = a.Values[:n]
+			c.tsm.pos = 0
+			return
+		}
+
+		c.tsm.keyCursor.Next()
+		c.tsm.values, _ = c.tsm.keyCursor.ReadFloatArrayBlock(c.tsm.buf)
+		c.tsm.pos = 0
+	}
+}
+
 type floatArrayDescendingCursor struct {
 	cache struct {
 		values Values
@@ -169,8 +216,9 @@ type floatArrayDescendingCursor struct {
 		keyCursor *KeyCursor
 	}
 
-	end int64
-	res *tsdb.FloatArray
+	end  int64
+	pred func(v float64) bool
+	res  *tsdb.FloatArray
 }
 
 func newFloatArrayDescendingCursor() *floatArrayDescendingCursor {
@@ -181,8 +229,11 @@ func newFloatArrayDescendingCursor() *floatArrayDescendingCursor {
 	return c
 }
 
-func (c *floatArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+// reset resets the cursor to read the values from seek down to end. If pred
+// is not nil, only the values matching pred are returned.
+func (c *floatArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor, pred func(v float64) bool) {
 	c.end = end
+	c.pred = pred
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
 		return c.cache.values[i].UnixNano() >= seek
@@ -195,10 +246,17 @@ func (c *floatArrayDescendingCursor) reset(seek, end int64, cacheValues Values,
 		return c.tsm.values.Timestamps[i] >= seek
 	})
 	c.tsm.pos--
+	if c.pred != nil {
+		c.filterTSM()
+	}
 }
 
 func (c *floatArrayDescendingCursor) Err() error { return nil }
 
+// Filtered returns true if the cursor only returns the values matching its
+// predicate.
+func (c *floatArrayDescendingCursor) Filtered() bool { return c.pred != nil }
+
 func (c *floatArrayDescendingCursor) Stats() tsdb.CursorStats {
 	return tsdb.CursorStats{}
 }
@@ -238,7 +296,10 @@ func (c *floatArrayDescendingCursor) Next() *tsdb.FloatArray {
 			c.tsm.pos--
 		}
 
-		pos++
+		// The values of the TSM blocks have already been filtered.
+		if ckey < tkey || c.pred == nil || c.pred(c.res.Values[pos]) {
+			pos++
+		}
 
 		if c.tsm.pos < 0 {
 			tvals = c.nextTSM()
@@ -264,8 +325,10 @@ func (c *floatArrayDescendingCursor) Next() *tsdb.FloatArray {
 			for pos < len(c.res.Timestamps) && c.cache.pos >= 0 {
 				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
 				c.res.Values[pos] = cvals[c.cache.pos].(FloatValue).value
-				pos++
 				c.cache.pos--
+				if c.pred == nil || c.pred(c.res.Values[pos]) {
+					pos++
+				}
 			}
 		}
 	}
@@ -288,9 +351,40 @@ func (c *floatArrayDescendingCursor) nextTSM() *tsdb.FloatArray {
 	c.tsm.keyCursor.Next()
 	c.tsm.values, _ = c.tsm.keyCursor.ReadFloatArrayBlock(c.tsm.buf)
 	c.tsm.pos = len(c.tsm.values.Timestamps) - 1
+	if c.pred != nil {
+		c.filterTSM()
+	}
 	return c.tsm.values
 }
 
+// filterTSM keeps the values of the current TSM block up to c.tsm.pos which
+// match the predicate. If no value matches, it reads the next blocks
+// until one has matching values, or a block starts before c.end.
+func (c *floatArrayDescendingCursor) filterTSM() {
+	for {
+		a := c.tsm.values
+		n := 0
+		for i := 0; i <= c.tsm.pos; i++ {
+			if c.pred(a.Values[i]) {
+				a.Timestamps[n] = a.Timestamps[i]
+				a.Values[n] = a.Values[i]
+				n++
+			}
+		}
+
+		if n > 0 || len(a.Timestamps) == 0 || a.Timestamps[0] < c.end {
+			a.Timestamps = a.Timestamps[:n]
+			a.Values = a.Values[:n]
+			c.tsm.pos = n - 1
+			return
+		}
+
+		c.tsm.keyCursor.Next()
+		c.tsm.values, _ = c.tsm.keyCursor.ReadFloatArrayBlock(c.tsm.buf)
+		c.tsm.pos = len(c.tsm.values.Timestamps) - 1
+	}
+}
+
 type integerArrayAscendingCursor struct {
 	cache struct {
 		values Values
@@ -304,8 +398,9 @@ type integerArrayAscendingCursor struct {
 		keyCursor *KeyCursor
 	}
 
-	end int64
-	res *tsdb.IntegerArray
+	end  int64
+	pred func(v int64) bool
+	res  *tsdb.IntegerArray
 }
 
 func newIntegerArrayAscendingCursor() *integerArrayAscendingCursor {
@@ -316,8 +411,11 @@ func newIntegerArrayAscendingCursor() *integerArrayAscendingCursor {
 	return c
 }
 
-func (c *integerArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+// reset resets the cursor to read the values from seek to end. If pred is
+// not nil, only the values matching pred are returned.
+func (c *integerArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor, pred func(v int64) bool) {
 	c.end = end
+	c.pred = pred
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
 		return c.cache.values[i].UnixNano() >= seek
@@ -328,10 +426,17 @@ func (c *integerArrayAscendingCursor) reset(seek, end int64, cacheValues Values,
 	c.tsm.pos = sort.Search(c.tsm.values.Len(), func(i int) bool {
 		return c.tsm.values.Timestamps[i] >= seek
 	})
+	if c.pred != nil {
+		c.filterTSM()
+	}
 }
 
 func (c *integerArrayAscendingCursor) Err() error { return nil }
 
+// Filtered returns true if the cursor only returns the values matching its
+// predicate.
+func (c *integerArrayAscendingCursor) Filtered() bool { return c.pred != nil }
+
 func (c *integerArrayAscendingCursor) Stats() tsdb.CursorStats {
 	return tsdb.CursorStats{}
 }
@@ -373,7 +478,10 @@ func (c *integerArrayAscendingCursor) Next() *tsdb.IntegerArray {
 			c.tsm.pos++
 		}
 
-		pos++
+		// The values of the TSM blocks have already been filtered.
+		if ckey > tkey || c.pred == nil || c.pred(c.res.Values[pos]) {
+			pos++
+		}
 
 		if c.tsm.pos >= len(tvals.Timestamps) {
 			tvals = c.nextTSM()
@@ -406,8 +514,10 @@ func (c *integerArrayAscendingCursor) Next() *tsdb.IntegerArray {
 			for pos < len(c.res.Timestamps) && c.cache.pos < len(cvals) {
 				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
 				c.res.Values[pos] = cvals[c.cache.pos].(IntegerValue).value
-				pos++
 				c.cache.pos++
+				if c.pred == nil || c.pred(c.res.Values[pos]) {
+					pos++
+				}
 			}
 		}
 	}
@@ -430,9 +540,40 @@ func (c *integerArrayAscendingCursor) nextTSM() *tsdb.IntegerArray {
 	c.tsm.keyCursor.Next()
 	c.tsm.values, _ = c.tsm.keyCursor.ReadIntegerArrayBlock(c.tsm.buf)
 	c.tsm.pos = 0
+	if c.pred != nil {
+		c.filterTSM()
+	}
 	return c.tsm.values
 }
 
+// filterTSM keeps the values of the current TSM block from c.tsm.pos on
+// which match the predicate. If no value matches, it reads the next blocks
+// until one has matching values, or a block ends after c.end.
+func (c *integerArrayAscendingCursor) filterTSM() {
+	for {
+		a := c.tsm.values
+		n := 0
+		for i := c.tsm.pos; i < len(a.Timestamps); i++ {
+			if c.pred(a.Values[i]) {
+				a.Timestamps[n] = a.Timestamps[i]
+				a.Values[n] = a.Values[i]
+				n++
+			}
+		}
+
+		if n > 0 || len(a.Timestamps) == 0 || a.Timestamps[len(a.Timestamps)-1] >= c.end {
+			a.Timestamps = a.Timestamps[:n]
+			a.Values = a.Values[:n]
+			c.tsm.pos = 0
+			return
+		}
+
+		c.tsm.keyCursor.Next()
+		c.tsm.values, _ = c.tsm.keyCursor.ReadIntegerArrayBlock(c.tsm.buf)
+		c.tsm.pos = 0
+	}
+}
+
 type integerArrayDescendingCursor struct {
 	cache struct {
 		values Values
@@ -446,8 +587,9 @@ type integerArrayDescendingCursor struct {
 		keyCursor *KeyCursor
 	}
 
-	end int64
-	res *tsdb.IntegerArray
+	end  int64
+	pred func(v int64) bool
+	res  *tsdb.IntegerArray
 }
 
 func newIntegerArrayDescendingCursor() *integerArrayDescendingCursor {
@@ -458,8 +600,11 @@ func newIntegerArrayDescendingCursor() *integerArrayDescendingCursor {
 	return c
 }
 
-func (c *integerArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+// reset resets the cursor to read the values from seek down to end. If pred
+// is not nil, only the values matching pred are returned.
+func (c *integerArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor, pred func(v int64) bool) {
 	c.end = end
+	c.pred = pred
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
 		return c.cache.values[i].UnixNano() >= seek
@@ -472,10 +617,17 @@ func (c *integerArrayDescendingCursor) reset(seek, end int64, cacheValues Values
 		return c.tsm.values.Timestamps[i] >= seek
 	})
 	c.tsm.pos--
+	if c.pred != nil {
+		c.filterTSM()
+	}
 }
 
 func (c *integerArrayDescendingCursor) Err() error { return nil }
 
+// Filtered returns true if the cursor only returns the values matching its
+// predicate.
+func (c *integerArrayDescendingCursor) Filtered() bool { return c.pred != nil }
+
 func (c *integerArrayDescendingCursor) Stats() tsdb.CursorStats {
 	return tsdb.CursorStats{}
 }
@@ -515,7 +667,10 @@ func (c *integerArrayDescendingCursor) Next() *tsdb.IntegerArray {
 			c.tsm.pos--
 		}
 
-		pos++
+		// The values of the TSM blocks have already been filtered.
+		if ckey < tkey || c.pred == nil || c.pred(c.res.Values[pos]) {
+			pos++
+		}
 
 		if c.tsm.pos < 0 {
 			tvals = c.nextTSM()
@@ -541,8 +696,10 @@ func (c *integerArrayDescendingCursor) Next() *tsdb.IntegerArray {
 			for pos < len(c.res.Timestamps) && c.cache.pos >= 0 {
 				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
 				c.res.Values[pos] = cvals[c.cache.pos].(IntegerValue).value
-				pos++
 				c.cache.pos--
+				if c.pred == nil || c.pred(c.res.Values[pos]) {
+					pos++
+				}
 			}
 		}
 	}
@@ -565,9 +722,40 @@ func (c *integerArrayDescendingCursor) nextTSM() *tsdb.IntegerArray {
 	c.tsm.keyCursor.Next()
 	c.tsm.values, _ = c.tsm.keyCursor.ReadIntegerArrayBlock(c.tsm.buf)
 	c.tsm.pos = len(c.tsm.values.Timestamps) - 1
+	if c.pred != nil {
+		c.filterTSM()
+	}
 	return c.tsm.values
 }
 
+// filterTSM keeps the values of the current TSM block up to c.tsm.pos which
+// match the predicate. If no value matches, it reads the next blocks
+// until one has matching values, or a block starts before c.end.
+func (c *integerArrayDescendingCursor) filterTSM() {
+	for {
+		a := c.tsm.values
+		n := 0
+		for i := 0; i <= c.tsm.pos; i++ {
+			if c.pred(a.Values[i]) {
+				a.Timestamps[n] = a.Timestamps[i]
+				a.Values[n] = a.Values[i]
+				n++
+			}
+		}
+
+		if n > 0 || len(a.Timestamps) == 0 || a.Timestamps[0] < c.end {
+			a.Timestamps = a.Timestamps[:n]
+			a.Values = a.Values[:n]
+			c.tsm.pos = n - 1
+			return
+		}
+
+		c.tsm.keyCursor.Next()
+		c.tsm.values, _ = c.tsm.keyCursor.ReadIntegerArrayBlock(c.tsm.buf)
+		c.tsm.pos = len(c.tsm.values.Timestamps) - 1
+	}
+}
+
 type unsignedArrayAscendingCursor struct {
 	cache struct {
 		values Values
@@ -581,8 +769,9 @@ type unsignedArrayAscendingCursor struct {
 		keyCursor *KeyCursor
 	}
 
-	end int64
-	res *tsdb.UnsignedArray
+	end  int64
+	pred func(v uint64) bool
+	res  *tsdb.UnsignedArray
 }
 
 func newUnsignedArrayAscendingCursor() *unsignedArrayAscendingCursor {
@@ -593,8 +782,11 @@ func newUnsignedArrayAscendingCursor() *unsignedArrayAscendingCursor {
 	return c
 }
 
-func (c *unsignedArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+// reset resets the cursor to read the values from seek to end. If pred is
+// not nil, only the values matching pred are returned.
+func (c *unsignedArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor, pred func(v uint64) bool) {
 	c.end = end
+	c.pred = pred
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
 		return c.cache.values[i].UnixNano() >= seek
@@ -605,10 +797,17 @@ func (c *unsignedArrayAscendingCursor) reset(seek, end int64, cacheValues Values
 	c.tsm.pos = sort.Search(c.tsm.values.Len(), func(i int) bool {
 		return c.tsm.values.Timestamps[i] >= seek
 	})
+	if c.pred != nil {
+		c.filterTSM()
+	}
 }
 
 func (c *unsignedArrayAscendingCursor) Err() error { return nil }
 
+// Filtered returns true if the cursor only returns the values matching its
+// predicate.
+func (c *unsignedArrayAscendingCursor) Filtered() bool { return c.pred != nil }
+
 func (c *unsignedArrayAscendingCursor) Stats() tsdb.CursorStats {
 	return tsdb.CursorStats{}
 }
@@ -650,7 +849,10 @@ func (c *unsignedArrayAscendingCursor) Next() *tsdb.UnsignedArray {
 			c.tsm.pos++
 		}
 
-		pos++
+		// The values of the TSM blocks have already been filtered.
+		if ckey > tkey || c.pred == nil || c.pred(c.res.Values[pos]) {
+			pos++
+		}
 
 		if c.tsm.pos >= len(tvals.Timestamps) {
 			tvals = c.nextTSM()
@@ -683,8 +885,10 @@ func (c *unsignedArrayAscendingCursor) Next() *tsdb.UnsignedArray {
 			for pos < len(c.res.Timestamps) && c.cache.pos < len(cvals) {
 				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
 				c.res.Values[pos] = cvals[c.cache.pos].(UnsignedValue).value
-				pos++
 				c.cache.pos++
+				if c.pred == nil || c.pred(c.res.Values[pos]) {
+					pos++
+				}
 			}
 		}
 	}
@@ -707,9 +911,40 @@ func (c *unsignedArrayAscendingCursor) nextTSM() *tsdb.UnsignedArray {
 	c.tsm.keyCursor.Next()
 	c.tsm.values, _ = c.tsm.keyCursor.ReadUnsignedArrayBlock(c.tsm.buf)
 	c.tsm.pos = 0
+	if c.pred != nil {
+		c.filterTSM()
+	}
 	return c.tsm.values
 }
 
+// filterTSM keeps the values of the current TSM block from c.tsm.pos on
+// which match the predicate. If no value matches, it reads the next blocks
+// until one has matching values, or a block ends after c.end.
+func (c *unsignedArrayAscendingCursor) filterTSM() {
+	for {
+		a := c.tsm.values
+		n := 0
+		for i := c.tsm.pos; i < len(a.Timestamps); i++ {
+			if c.pred(a.Values[i]) {
+				a.Timestamps[n] = a.Timestamps[i]
+				a.Values[n] = a.Values[i]
+				n++
+			}
+		}
+
+		if n > 0 || len(a.Timestamps) == 0 || a.Timestamps[len(a.Timestamps)-1] >= c.end {
+			a.Timestamps = a.Timestamps[:n]
+			a.Values = a.Values[:n]
+			c.tsm.pos = 0
+			return
+		}
+
+		c.tsm.keyCursor.Next()
+		c.tsm.values, _ = c.tsm.keyCursor.ReadUnsignedArrayBlock(c.tsm.buf)
+		c.tsm.pos = 0
+	}
+}
+
 type unsignedArrayDescendingCursor struct {
 	cache struct {
 		values Values
@@ -723,8 +958,9 @@ type unsignedArrayDescendingCursor struct {
 		keyCursor *KeyCursor
 	}
 
-	end int64
-	res *tsdb.UnsignedArray
+	end  int64
+	pred func(v uint64) bool
+	res  *tsdb.UnsignedArray
 }
 
 func newUnsignedArrayDescendingCursor() *unsignedArrayDescendingCursor {
@@ -735,8 +971,11 @@ func newUnsignedArrayDescendingCursor() *unsignedArrayDescendingCursor {
 	return c
 }
 
-func (c *unsignedArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+// reset resets the cursor to read the values from seek down to end. If pred
+// is not nil, only the values matching pred are returned.
+func (c *unsignedArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor, pred func(v uint64) bool) {
 	c.end = end
+	c.pred = pred
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
 		return c.cache.values[i].UnixNano() >= seek
@@ -749,10 +988,17 @@ func (c *unsignedArrayDescendingCursor) reset(seek, end int64, cacheValues Value
 		return c.tsm.values.Timestamps[i] >= seek
 	})
 	c.tsm.pos--
+	if c.pred != nil {
+		c.filterTSM()
+	}
 }
 
 func (c *unsignedArrayDescendingCursor) Err() error { return nil }
 
+// Filtered returns true if the cursor only returns the values matching its
+// predicate.
+func (c *unsignedArrayDescendingCursor) Filtered() bool { return c.pred != nil }
+
 func (c *unsignedArrayDescendingCursor) Stats() tsdb.CursorStats {
 	return tsdb.CursorStats{}
 }
@@ -792,7 +1038,10 @@ func (c *unsignedArrayDescendingCursor) Next() *tsdb.UnsignedArray {
 			c.tsm.pos--
 		}
 
-		pos++
+		// The values of the TSM blocks have already been filtered.
+		if ckey < tkey || c.pred == nil || c.pred(c.res.Values[pos]) {
+			pos++
+		}
 
 		if c.tsm.pos < 0 {
 			tvals = c.nextTSM()
@@ -818,8 +1067,10 @@ func (c *unsignedArrayDescendingCursor) Next() *tsdb.UnsignedArray {
 			for pos < len(c.res.Timestamps) && c.cache.pos >= 0 {
 				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
 				c.res.Values[pos] = cvals[c.cache.pos].(UnsignedValue).value
-				pos++
 				c.cache.pos--
+				if c.pred == nil || c.pred(c.res.Values[pos]) {
+					pos++
+				}
 			}
 		}
 	}
@@ -842,9 +1093,40 @@ func (c *unsignedArrayDescendingCursor) nextTSM() *tsdb.UnsignedArray {
 	c.tsm.keyCursor.Next()
 	c.tsm.values, _ = c.tsm.keyCursor.ReadUnsignedArrayBlock(c.tsm.buf)
 	c.tsm.pos = len(c.tsm.values.Timestamps) - 1
+	if c.pred != nil {
+		c.filterTSM()
+	}
 	return c.tsm.values
 }
 
+// filterTSM keeps the values of the current TSM block up to c.tsm.pos which
+// match the predicate. If no value matches, it reads the next blocks
+// until one has matching values, or a block starts before c.end.
+func (c *unsignedArrayDescendingCursor) filterTSM() {
+	for {
+		a := c.tsm.values
+		n := 0
+		for i := 0; i <= c.tsm.pos; i++ {
+			if c.pred(a.Values[i]) {
+				a.Timestamps[n] = a.Timestamps[i]
+				a.Values[n] = a.Values[i]
+				n++
+			}
+		}
+
+		if n > 0 || len(a.Timestamps) == 0 || a.Timestamps[0] < c.end {
+			a.Timestamps = a.Timestamps[:n]
+			a.Values = a.Values[:n]
+			c.tsm.pos = n - 1
+			return
+		}
+
+		c.tsm.keyCursor.Next()
+		c.tsm.values, _ = c.tsm.keyCursor.ReadUnsignedArrayBlock(c.tsm.buf)
+		c.tsm.pos = len(c.tsm.values.Timestamps) - 1
+	}
+}
+
 type stringArrayAscendingCursor struct {
 	cache struct {
 		values Values
@@ -858,8 +1140,9 @@ type stringArrayAscendingCursor struct {
 		keyCursor *KeyCursor
 	}
 
-	end int64
-	res *tsdb.StringArray
+	end  int64
+	pred func(v string) bool
+	res  *tsdb.StringArray
 }
 
 func newStringArrayAscendingCursor() *stringArrayAscendingCursor {
@@ -870,8 +1153,11 @@ func newStringArrayAscendingCursor() *stringArrayAscendingCursor {
 	return c
 }
 
-func (c *stringArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+// reset resets the cursor to read the values from seek to end. If pred is
+// not nil, only the values matching pred are returned.
+func (c *stringArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor, pred func(v string) bool) {
 	c.end = end
+	c.pred = pred
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
 		return c.cache.values[i].UnixNano() >= seek
@@ -882,10 +1168,17 @@ func (c *stringArrayAscendingCursor) reset(seek, end int64, cacheValues Values,
 	c.tsm.pos = sort.Search(c.tsm.values.Len(), func(i int) bool {
 		return c.tsm.values.Timestamps[i] >= seek
 	})
+	if c.pred != nil {
+		c.filterTSM()
+	}
 }
 
 func (c *stringArrayAscendingCursor) Err() error { return nil }
 
+// Filtered returns true if the cursor only returns the values matching its
+// predicate.
+func (c *stringArrayAscendingCursor) Filtered() bool { return c.pred != nil }
+
 func (c *stringArrayAscendingCursor) Stats() tsdb.CursorStats {
 	return tsdb.CursorStats{}
 }
@@ -927,7 +1220,10 @@ func (c *stringArrayAscendingCursor) Next() *tsdb.StringArray {
 			c.tsm.pos++
 		}
 
-		pos++
+		// The values of the TSM blocks have already been filtered.
+		if ckey > tkey || c.pred == nil || c.pred(c.res.Values[pos]) {
+			pos++
+		}
 
 		if c.tsm.pos >= len(tvals.Timestamps) {
 			tvals = c.nextTSM()
@@ -960,8 +1256,10 @@ func (c *stringArrayAscendingCursor) Next() *tsdb.StringArray {
 			for pos < len(c.res.Timestamps) && c.cache.pos < len(cvals) {
 				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
 				c.res.Values[pos] = cvals[c.cache.pos].(StringValue).value
-				pos++
 				c.cache.pos++
+				if c.pred == nil || c.pred(c.res.Values[pos]) {
+					pos++
+				}
 			}
 		}
 	}
@@ -984,9 +1282,40 @@ func (c *stringArrayAscendingCursor) nextTSM() *tsdb.StringArray {
 	c.tsm.keyCursor.Next()
 	c.tsm.values, _ = c.tsm.keyCursor.ReadStringArrayBlock(c.tsm.buf)
 	c.tsm.pos = 0
+	if c.pred != nil {
+		c.filterTSM()
+	}
 	return c.tsm.values
 }
 
+// filterTSM keeps the values of the current TSM block from c.tsm.pos on
+// which match the predicate. If no value matches, it reads the next blocks
+// until one has matching values, or a block ends after c.end.
+func (c *stringArrayAscendingCursor) filterTSM() {
+	for {
+		a := c.tsm.values
+		n := 0
+		for i := c.tsm.pos; i < len(a.Timestamps); i++ {
+			if c.pred(a.Values[i]) {
+				a.Timestamps[n] = a.Timestamps[i]
+				a.Values[n] = a.Values[i]
+				n++
+			}
+		}
+
+		if n > 0 || len(a.Timestamps) == 0 || a.Timestamps[len(a.Timestamps)-1] >= c.end {
+			a.Timestamps = a.Timestamps[:n]
+			a.Values = a.Values[:n]
+			c.tsm.pos = 0
+			return
+		}
+
+		c.tsm.keyCursor.Next()
+		c.tsm.values, _ = c.tsm.keyCursor.ReadStringArrayBlock(c.tsm.buf)
+		c.tsm.pos = 0
+	}
+}
+
 type stringArrayDescendingCursor struct {
 	cache struct {
 		values Values
@@ -1000,8 +1329,9 @@ type stringArrayDescendingCursor struct {
 		keyCursor *KeyCursor
 	}
 
-	end int64
-	res *tsdb.StringArray
+	end  int64
+	pred func(v string) bool
+	res  *tsdb.StringArray
 }
 
 func newStringArrayDescendingCursor() *stringArrayDescendingCursor {
@@ -1012,8 +1342,11 @@ func newStringArrayDescendingCursor() *stringArrayDescendingCursor {
 	return c
 }
 
-func (c *stringArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+// reset resets the cursor to read the values from seek down to end. If pred
+// is not nil, only the values matching pred are returned.
+func (c *stringArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor, pred func(v string) bool) {
 	c.end = end
+	c.pred = pred
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
 		return c.cache.values[i].UnixNano() >= seek
@@ -1026,10 +1359,17 @@ func (c *stringArrayDescendingCursor) reset(seek, end int64, cacheValues Values,
 		return c.tsm.values.Timestamps[i] >= seek
 	})
 	c.tsm.pos--
+	if c.pred != nil {
+		c.filterTSM()
+	}
 }
 
 func (c *stringArrayDescendingCursor) Err() error { return nil }
 
+// Filtered returns true if the cursor only returns the values matching its
+// predicate.
+func (c *stringArrayDescendingCursor) Filtered() bool { return c.pred != nil }
+
 func (c *stringArrayDescendingCursor) Stats() tsdb.CursorStats {
 	return tsdb.CursorStats{}
 }
@@ -1069,7 +1409,10 @@ func (c *stringArrayDescendingCursor) Next() *tsdb.StringArray {
 			c.tsm.pos--
 		}
 
-		pos++
+		// The values of the TSM blocks have already been filtered.
+		if ckey < tkey || c.pred == nil || c.pred(c.res.Values[pos]) {
+			pos++
+		}
 
 		if c.tsm.pos < 0 {
 			tvals = c.nextTSM()
@@ -1095,8 +1438,10 @@ func (c *stringArrayDescendingCursor) Next() *tsdb.StringArray {
 			for pos < len(c.res.Timestamps) && c.cache.pos >= 0 {
 				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
 				c.res.Values[pos] = cvals[c.cache.pos].(StringValue).value
-				pos++
 				c.cache.pos--
+				if c.pred == nil || c.pred(c.res.Values[pos]) {
+					pos++
+				}
 			}
 		}
 	}
@@ -1119,9 +1464,40 @@ func (c *stringArrayDescendingCursor) nextTSM() *tsdb.StringArray {
 	c.tsm.keyCursor.Next()
 	c.tsm.values, _ = c.tsm.keyCursor.ReadStringArrayBlock(c.tsm.buf)
 	c.tsm.pos = len(c.tsm.values.Timestamps) - 1
+	if c.pred != nil {
+		c.filterTSM()
+	}
 	return c.tsm.values
 }
 
+// filterTSM keeps the values of the current TSM block up to c.tsm.pos which
+// match the predicate. If no value matches, it reads the next blocks
+// until one has matching values, or a block starts before c.end.
+func (c *stringArrayDescendingCursor) filterTSM() {
+	for {
+		a := c.tsm.values
+		n := 0
+		for i := 0; i <= c.tsm.pos; i++ {
+			if c.pred(a.Values[i]) {
+				a.Timestamps[n] = a.Timestamps[i]
+				a.Values[n] = a.Values[i]
+				n++
+			}
+		}
+
+		if n > 0 || len(a.Timestamps) == 0 || a.Timestamps[0] < c.end {
+			a.Timestamps = a.Timestamps[:n]
+			a.Values = a.Values[:n]
+			c.tsm.pos = n - 1
+			return
+		}
+
+		c.tsm.keyCursor.Next()
+		c.tsm.values, _ = c.tsm.keyCursor.ReadStringArrayBlock(c.tsm.buf)
+		c.tsm.pos = len(c.tsm.values.Timestamps) - 1
+	}
+}
+
 type booleanArrayAscendingCursor struct {
 	cache struct {
 		values Values
@@ -1135,8 +1511,9 @@ type booleanArrayAscendingCursor struct {
 		keyCursor *KeyCursor
 	}
 
-	end int64
-	res *tsdb.BooleanArray
+	end  int64
+	pred func(v bool) bool
+	res  *tsdb.BooleanArray
 }
 
 func newBooleanArrayAscendingCursor() *booleanArrayAscendingCursor {
@@ -1147,8 +1524,11 @@ func newBooleanArrayAscendingCursor() *booleanArrayAscendingCursor {
 	return c
 }
 
-func (c *booleanArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+// reset resets the cursor to read the values from seek to end. If pred is
+// not nil, only the values matching pred are returned.
+func (c *booleanArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor, pred func(v bool) bool) {
 	c.end = end
+	c.pred = pred
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
 		return c.cache.values[i].UnixNano() >= seek
@@ -1159,10 +1539,17 @@ func (c *booleanArrayAscendingCursor) reset(seek, end int64, cacheValues Values,
 	c.tsm.pos = sort.Search(c.tsm.values.Len(), func(i int) bool {
 		return c.tsm.values.Timestamps[i] >= seek
 	})
+	if c.pred != nil {
+		c.filterTSM()
+	}
 }
 
 func (c *booleanArrayAscendingCursor) Err() error { return nil }
 
+// Filtered returns true if the cursor only returns the values matching its
+// predicate.
+func (c *booleanArrayAscendingCursor) Filtered() bool { return c.pred != nil }
+
 func (c *booleanArrayAscendingCursor) Stats() tsdb.CursorStats {
 	return tsdb.CursorStats{}
 }
@@ -1204,7 +1591,10 @@ func (c *booleanArrayAscendingCursor) Next() *tsdb.BooleanArray {
 			c.tsm.pos++
 		}
 
-		pos++
+		// The values of the TSM blocks have already been filtered.
+		if ckey > tkey || c.pred == nil || c.pred(c.res.Values[pos]) {
+			pos++
+		}
 
 		if c.tsm.pos >= len(tvals.Timestamps) {
 			tvals = c.nextTSM()
@@ -1237,8 +1627,10 @@ func (c *booleanArrayAscendingCursor) Next() *tsdb.BooleanArray {
 			for pos < len(c.res.Timestamps) && c.cache.pos < len(cvals) {
 				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
 				c.res.Values[pos] = cvals[c.cache.pos].(BooleanValue).value
-				pos++
 				c.cache.pos++
+				if c.pred == nil || c.pred(c.res.Values[pos]) {
+					pos++
+				}
 			}
 		}
 	}
@@ -1261,9 +1653,40 @@ func (c *booleanArrayAscendingCursor) nextTSM() *tsdb.BooleanArray {
 	c.tsm.keyCursor.Next()
 	c.tsm.values, _ = c.tsm.keyCursor.ReadBooleanArrayBlock(c.tsm.buf)
 	c.tsm.pos = 0
+	if c.pred != nil {
+		c.filterTSM()
+	}
 	return c.tsm.values
 }
 
+// filterTSM keeps the values of the current TSM block from c.tsm.pos on
+// which match the predicate. If no value matches, it reads the next blocks
+// until one has matching values, or a block ends after c.end.
+func (c *booleanArrayAscendingCursor) filterTSM() {
+	for {
+		a := c.tsm.values
+		n := 0
+		for i := c.tsm.pos; i < len(a.Timestamps); i++ {
+			if c.pred(a.Values[i]) {
+				a.Timestamps[n] = a.Timestamps[i]
+				a.Values[n] = a.Values[i]
+				n++
+			}
+		}
+
+		if n > 0 || len(a.Timestamps) == 0 || a.Timestamps[len(a.Timestamps)-1] >= c.end {
+			a.Timestamps = a.Timestamps[:n]
+			a.Values = a.Values[:n]
+			c.tsm.pos = 0
+			return
+		}
+
+		c.tsm.keyCursor.Next()
+		c.tsm.values, _ = c.tsm.keyCursor.ReadBooleanArrayBlock(c.tsm.buf)
+		c.tsm.pos = 0
+	}
+}
+
 type booleanArrayDescendingCursor struct {
 	cache struct {
 		values Values
@@ -1277,8 +1700,9 @@ type booleanArrayDescendingCursor struct {
 		keyCursor *KeyCursor
 	}
 
-	end int64
-	res *tsdb.BooleanArray
+	end  int64
+	pred func(v bool) bool
+	res  *tsdb.BooleanArray
 }
 
 func newBooleanArrayDescendingCursor() *booleanArrayDescendingCursor {
@@ -1289,8 +1713,11 @@ func newBooleanArrayDescendingCursor() *booleanArrayDescendingCursor {
 	return c
 }
 
-func (c *booleanArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+// reset resets the cursor to read the values from seek down to end. If pred
+// is not nil, only the values matching pred are returned.
+func (c *booleanArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor, pred func(v bool) bool) {
 	c.end = end
+	c.pred = pred
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
 		return c.cache.values[i].UnixNano() >= seek
@@ -1303,10 +1730,17 @@ func (c *booleanArrayDescendingCursor) reset(seek, end int64, cacheValues Values
 		return c.tsm.values.Timestamps[i] >= seek
 	})
 	c.tsm.pos--
+	if c.pred != nil {
+		c.filterTSM()
+	}
 }
 
 func (c *booleanArrayDescendingCursor) Err() error { return nil }
 
+// Filtered returns true if the cursor only returns the values matching its
+// predicate.
+func (c *booleanArrayDescendingCursor) Filtered() bool { return c.pred != nil }
+
 func (c *booleanArrayDescendingCursor) Stats() tsdb.CursorStats {
 	return tsdb.CursorStats{}
 }
@@ -1346,7 +1780,10 @@ func (c *booleanArrayDescendingCursor) Next() *tsdb.BooleanArray {
 			c.tsm.pos--
 		}
 
-		pos++
+		// The values of the TSM blocks have already been filtered.
+		if ckey < tkey || c.pred == nil || c.pred(c.res.Values[pos]) {
+			pos++
+		}
 
 		if c.tsm.pos < 0 {
 			tvals = c.nextTSM()
@@ -1372,8 +1809,10 @@ func (c *booleanArrayDescendingCursor) Next() *tsdb.BooleanArray {
 			for pos < len(c.res.Timestamps) && c.cache.pos >= 0 {
 				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
 				c.res.Values[pos] = cvals[c.cache.pos].(BooleanValue).value
-				pos++
 				c.cache.pos--
+				if c.pred == nil || c.pred(c.res.Values[pos]) {
+					pos++
+				}
 			}
 		}
 	}
@@ -1396,5 +1835,36 @@ func (c *booleanArrayDescendingCursor) nextTSM() *tsdb.BooleanArray {
 	c.tsm.keyCursor.Next()
 	c.tsm.values, _ = c.tsm.keyCursor.ReadBooleanArrayBlock(c.tsm.buf)
 	c.tsm.pos = len(c.tsm.values.Timestamps) - 1
+	if c.pred != nil {
+		c.filterTSM()
+	}
 	return c.tsm.values
 }
+
+// filterTSM keeps the values of the current TSM block up to c.tsm.pos which
+// match the predicate. If no value matches, it reads the next blocks
+// until one has matching values, or a block starts before c.end.
+func (c *booleanArrayDescendingCursor) filterTSM() {
+	for {
+		a := c.tsm.values
+		n := 0
+		for i := 0; i <= c.tsm.pos; i++ {
+			if c.pred(a.Values[i]) {
+				a.Timestamps[n] = a.Timestamps[i]
+				a.Values[n] = a.Values[i]
+				n++
+			}
+		}
+
+		if n > 0 || len(a.Timestamps) == 0 || a.Timestamps[0] < c.end {
+			a.Timestamps = a.Timestamps[:n]
+			a.Values = a.Values[:n]
+			c.tsm.pos = n - 1
+			return
+		}
+
+		c.tsm.keyCursor.Next()
+		c.tsm.values, _ = c.tsm.keyCursor.ReadBooleanArrayBlock(c.tsm.buf)
+		c.tsm.pos = len(c.tsm.values.Timestamps) - 1
+	}
+}
@@ -14,8 +14,14 @@ import (
 	"github.com/influxdata/influxdb/v2/tsdb"
 )
 
-// buildFloatArrayCursor creates an array cursor for a float field.
-func (q *arrayCursorIterator) buildFloatArrayCursor(ctx context.Context, name []byte, tags models.Tags, field string, opt query.IteratorOptions) tsdb.FloatArrayCursor {
+// buildFloatArrayCursor creates an array cursor for a float field. If pred
+// is not nil, the cursor only returns the values matching pred.
+func (q *arrayCursorIterator) buildFloatArrayCursor(ctx context.Context, name []byte, tags models.Tags, field string, opt query.IteratorOptions, pred valuePredicate) tsdb.FloatArrayCursor {
+	var match func(v float64) bool
+	if pred != nil {
+		match = pred.matchFloat
+	}
+
 	key := q.seriesFieldKeyBytes(name, tags, field)
 	cacheValues := q.e.Cache.Values(key)
 	keyCursor := q.e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
@@ -23,19 +29,25 @@ func (q *arrayCursorIterator) buildFloatArrayCursor(ctx context.Context, name []
 		if q.asc.Float == nil {
 			q.asc.Float = newFloatArrayAscendingCursor()
 		}
-		q.asc.Float.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor)
+		q.asc.Float.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor, match)
 		return q.asc.Float
 	} else {
 		if q.desc.Float == nil {
 			q.desc.Float = newFloatArrayDescendingCursor()
 		}
-		q.desc.Float.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor)
+		q.desc.Float.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor, match)
 		return q.desc.Float
 	}
 }
 
-// buildIntegerArrayCursor creates an array cursor for a integer field.
-func (q *arrayCursorIterator) buildIntegerArrayCursor(ctx context.Context, name []byte, tags models.Tags, field string, opt query.IteratorOptions) tsdb.IntegerArrayCursor {
+// buildIntegerArrayCursor creates an array cursor for a integer field. If pred
+// is not nil, the cursor only returns the values matching pred.
+func (q *arrayCursorIterator) buildIntegerArrayCursor(ctx context.Context, name []byte, tags models.Tags, field string, opt query.IteratorOptions, pred valuePredicate) tsdb.IntegerArrayCursor {
+	var match func(v int64) bool
+	if pred != nil {
+		match = pred.matchInteger
+	}
+
 	key := q.seriesFieldKeyBytes(name, tags, field)
 	cacheValues := q.e.Cache.Values(key)
 	keyCursor := q.e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
@@ -43,19 +55,25 @@ func (q *arrayCursorIterator) buildIntegerArrayCursor(ctx context.Context, name
 		if q.asc.Integer == nil {
 			q.asc.Integer = newIntegerArrayAscendingCursor()
 		}
-		q.asc.Integer.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor)
+		q.asc.Integer.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor, match)
 		return q.asc.Integer
 	} else {
 		if q.desc.Integer == nil {
 			q.desc.Integer = newIntegerArrayDescendingCursor()
 		}
-		q.desc.Integer.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor)
+		q.desc.Integer.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor, match)
 		return q.desc.Integer
 	}
 }
 
-// buildUnsignedArrayCursor creates an array cursor for a unsigned field.
-func (q *arrayCursorIterator) buildUnsignedArrayCursor(ctx context.Context, name []byte, tags models.Tags, field string, opt query.IteratorOptions) tsdb.UnsignedArrayCursor {
+// buildUnsignedArrayCursor creates an array cursor for a unsigned field. If pred
+// is not nil, the cursor only returns the values matching pred.
+func (q *arrayCursorIterator) buildUnsignedArrayCursor(ctx context.Context, name []byte, tags models.Tags, field string, opt query.IteratorOptions, pred valuePredicate) tsdb.UnsignedArrayCursor {
+	var match func(v uint64) bool
+	if pred != nil {
+		match = pred.matchUnsigned
+	}
+
 	key := q.seriesFieldKeyBytes(name, tags, field)
 	cacheValues := q.e.Cache.Values(key)
 	keyCursor := q.e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
@@ -63,19 +81,25 @@ func (q *arrayCursorIterator) buildUnsignedArrayCursor(ctx context.Context, name
 		if q.asc.Unsigned == nil {
 			q.asc.Unsigned = newUnsignedArrayAscendingCursor()
 		}
-		q.asc.Unsigned.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor)
+		q.asc.Unsigned.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor, match)
 		return q.asc.Unsigned
 	} else {
 		if q.desc.Unsigned == nil {
 			q.desc.Unsigned = newUnsignedArrayDescendingCursor()
 		}
-		q.desc.Unsigned.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor)
+		q.desc.Unsigned.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor, match)
 		return q.desc.Unsigned
 	}
 }
 
-// buildStringArrayCursor creates an array cursor for a string field.
-func (q *arrayCursorIterator) buildStringArrayCursor(ctx context.Context, name []byte, tags models.Tags, field string, opt query.IteratorOptions) tsdb.StringArrayCursor {
+// buildStringArrayCursor creates an array cursor for a string field. If pred
+// is not nil, the cursor only returns the values matching pred.
+func (q *arrayCursorIterator) buildStringArrayCursor(ctx context.Context, name []byte, tags models.Tags, field string, opt query.IteratorOptions, pred valuePredicate) tsdb.StringArrayCursor {
+	var match func(v string) bool
+	if pred != nil {
+		match = pred.matchString
+	}
+
 	key := q.seriesFieldKeyBytes(name, tags, field)
 	cacheValues := q.e.Cache.Values(key)
 	keyCursor := q.e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
@@ -83,19 +107,25 @@ func (q *arrayCursorIterator) buildStringArrayCursor(ctx context.Context, name [
 		if q.asc.String == nil {
 			q.asc.String = newStringArrayAscendingCursor()
 		}
-		q.asc.String.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor)
+		q.asc.String.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor, match)
 		return q.asc.String
 	} else {
 		if q.desc.String == nil {
 			q.desc.String = newStringArrayDescendingCursor()
 		}
-		q.desc.String.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor)
+		q.desc.String.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor, match)
 		return q.desc.String
 	}
 }
 
-// buildBooleanArrayCursor creates an array cursor for a boolean field.
-func (q *arrayCursorIterator) buildBooleanArrayCursor(ctx context.Context, name []byte, tags models.Tags, field string, opt query.IteratorOptions) tsdb.BooleanArrayCursor {
+// buildBooleanArrayCursor creates an array cursor for a boolean field. If pred
+// is not nil, the cursor only returns the values matching pred.
+func (q *arrayCursorIterator) buildBooleanArrayCursor(ctx context.Context, name []byte, tags models.Tags, field string, opt query.IteratorOptions, pred valuePredicate) tsdb.BooleanArrayCursor {
+	var match func(v bool) bool
+	if pred != nil {
+		match = pred.matchBoolean
+	}
+
 	key := q.seriesFieldKeyBytes(name, tags, field)
 	cacheValues := q.e.Cache.Values(key)
 	keyCursor := q.e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
@@ -103,13 +133,13 @@ func (q *arrayCursorIterator) buildBooleanArrayCursor(ctx context.Context, name
 		if q.asc.Boolean == nil {
 			q.asc.Boolean = newBooleanArrayAscendingCursor()
 		}
-		q.asc.Boolean.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor)
+		q.asc.Boolean.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor, match)
 		return q.asc.Boolean
 	} else {
 		if q.desc.Boolean == nil {
 			q.desc.Boolean = newBooleanArrayDescendingCursor()
 		}
-		q.desc.Boolean.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor)
+		q.desc.Boolean.reset(opt.SeekTime(), opt.StopTime(), cacheValues, keyCursor, match)
 		return q.desc.Boolean
 	}
 }
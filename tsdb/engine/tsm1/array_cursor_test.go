@@ -81,7 +81,7 @@ func TestDescendingCursor_SinglePointStartTime(t *testing.T) {
 		defer kc.Close()
 		cur := newIntegerArrayDescendingCursor()
 		// Include a cached value with timestamp equal to END
-		cur.reset(START, END, Values{NewIntegerValue(1, 1)}, kc)
+		cur.reset(START, END, Values{NewIntegerValue(1, 1)}, kc, nil)
 
 		var got []int64
 		ar := cur.Next()
@@ -116,7 +116,7 @@ func TestDescendingCursor_SinglePointStartTime(t *testing.T) {
 		kc := fs.KeyCursor(context.Background(), []byte("m,_field=v#!~#v"), START, false)
 		defer kc.Close()
 		cur := newIntegerArrayDescendingCursor()
-		cur.reset(START, END, nil, kc)
+		cur.reset(START, END, nil, kc, nil)
 
 		var got []int64
 		ar := cur.Next()
@@ -164,7 +164,7 @@ func TestFileStore_DuplicatePoints(t *testing.T) {
 		kc := fs.KeyCursor(context.Background(), []byte("m,_field=v#!~#v"), START, true)
 		defer kc.Close()
 		cur := newFloatArrayAscendingCursor()
-		cur.reset(START, END, nil, kc)
+		cur.reset(START, END, nil, kc, nil)
 
 		var got []int64
 		ar := cur.Next()
@@ -183,7 +183,7 @@ func TestFileStore_DuplicatePoints(t *testing.T) {
 		kc := fs.KeyCursor(context.Background(), []byte("m,_field=v#!~#v"), START, false)
 		defer kc.Close()
 		cur := newFloatArrayDescendingCursor()
-		cur.reset(START, END, nil, kc)
+		cur.reset(START, END, nil, kc, nil)
 
 		var got []int64
 		ar := cur.Next()
@@ -258,7 +258,7 @@ func TestFileStore_MergeBlocksLargerThat1000_SecondEntirelyContained(t *testing.
 		kc := fs.KeyCursor(context.Background(), []byte("m,_field=v#!~#v"), START, true)
 		defer kc.Close()
 		cur := newFloatArrayAscendingCursor()
-		cur.reset(START, END, nil, kc)
+		cur.reset(START, END, nil, kc, nil)
 
 		exp := makeTs(1000, 800, 10)
 		exp = append(exp, makeTs(1005, 400, 10)...)
@@ -281,7 +281,7 @@ func TestFileStore_MergeBlocksLargerThat1000_SecondEntirelyContained(t *testing.
 		kc := fs.KeyCursor(context.Background(), []byte("m,_field=v#!~#v"), START, false)
 		defer kc.Close()
 		cur := newFloatArrayDescendingCursor()
-		cur.reset(START, END, nil, kc)
+		cur.reset(START, END, nil, kc, nil)
 
 		exp := makeTs(1000, 800, 10)
 		exp = append(exp, makeTs(1005, 400, 10)...)
@@ -361,7 +361,7 @@ func TestFileStore_MergeBlocksLargerThat1000_MultipleBlocksInEachFile(t *testing
 		kc := fs.KeyCursor(context.Background(), []byte("m,_field=v#!~#v"), START, true)
 		defer kc.Close()
 		cur := newFloatArrayAscendingCursor()
-		cur.reset(START, END, nil, kc)
+		cur.reset(START, END, nil, kc, nil)
 
 		exp := makeArray(1000, 3500, 10, 1.01)
 		a2 := makeArray(4005, 3500, 5, 2.01)
@@ -388,7 +388,7 @@ func TestFileStore_MergeBlocksLargerThat1000_MultipleBlocksInEachFile(t *testing
 		kc := fs.KeyCursor(context.Background(), []byte("m,_field=v#!~#v"), START, false)
 		defer kc.Close()
 		cur := newFloatArrayDescendingCursor()
-		cur.reset(START, END, nil, kc)
+		cur.reset(START, END, nil, kc, nil)
 
 		exp := makeArray(1000, 3500, 10, 1.01)
 		a2 := makeArray(4005, 3500, 5, 2.01)
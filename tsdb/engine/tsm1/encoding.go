@@ -246,6 +246,68 @@ func BlockCount(block []byte) (int, error) {
 	return CountTimestamps(tb), nil
 }
 
+// BlockEncodings returns the names of the encodings of the timestamps and of
+// the values encoded in block, such as "simple8b" or "gorilla".
+func BlockEncodings(block []byte) (timestamps, values string, err error) {
+	if len(block) <= encodedBlockHeaderSize {
+		return "", "", fmt.Errorf("encodings of short block: got %v, exp %v", len(block), encodedBlockHeaderSize)
+	}
+
+	blockType, err := BlockType(block)
+	if err != nil {
+		return "", "", err
+	}
+
+	tb, vb, err := unpackBlock(block[1:])
+	if err != nil {
+		return "", "", fmt.Errorf("BlockEncodings: error unpacking block: %v", err)
+	} else if len(tb) == 0 || len(vb) == 0 {
+		return "", "", fmt.Errorf("BlockEncodings: empty block")
+	}
+
+	var valueEncodings map[byte]string
+	switch blockType {
+	case BlockFloat64:
+		valueEncodings = floatEncodingNames
+	case BlockInteger, BlockUnsigned:
+		valueEncodings = intEncodingNames
+	case BlockBoolean:
+		valueEncodings = booleanEncodingNames
+	case BlockString:
+		valueEncodings = stringEncodingNames
+	}
+	return encodingName(timeEncodingNames, tb[0]>>4), encodingName(valueEncodings, vb[0]>>4), nil
+}
+
+var (
+	timeEncodingNames = map[byte]string{
+		timeUncompressed:           "uncompressed",
+		timeCompressedPackedSimple: "simple8b",
+		timeCompressedRLE:          "rle",
+	}
+	floatEncodingNames = map[byte]string{
+		floatCompressedGorilla: "gorilla",
+	}
+	intEncodingNames = map[byte]string{
+		intUncompressed:     "uncompressed",
+		intCompressedSimple: "simple8b",
+		intCompressedRLE:    "rle",
+	}
+	booleanEncodingNames = map[byte]string{
+		booleanCompressedBitPacked: "bitpacked",
+	}
+	stringEncodingNames = map[byte]string{
+		stringCompressedSnappy: "snappy",
+	}
+)
+
+func encodingName(names map[byte]string, encoding byte) string {
+	if name, ok := names[encoding]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", encoding)
+}
+
 // DecodeBlock takes a byte slice and decodes it into values of the appropriate type
 // based on the block.
 func DecodeBlock(block []byte, vals []Value) ([]Value, error) {
@@ -292,6 +292,44 @@ func TestEncoding_BlockType(t *testing.T) {
 	}
 }
 
+func TestEncoding_BlockEncodings(t *testing.T) {
+	tests := []struct {
+		value      interface{}
+		timestamps string
+		values     string
+	}{
+		{value: float64(1.0), timestamps: "rle", values: "gorilla"},
+		{value: int64(1), timestamps: "rle", values: "rle"},
+		{value: uint64(1), timestamps: "rle", values: "rle"},
+		{value: true, timestamps: "rle", values: "bitpacked"},
+		{value: "string", timestamps: "rle", values: "snappy"},
+	}
+
+	for _, test := range tests {
+		var values []tsm1.Value
+		for i := 0; i < 10; i++ {
+			values = append(values, tsm1.NewValue(int64(i)*int64(time.Second), test.value))
+		}
+
+		b, err := tsm1.Values(values).Encode(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ts, vs, err := tsm1.BlockEncodings(b)
+		if err != nil {
+			t.Fatalf("unexpected error decoding block encodings: %v", err)
+		}
+		if ts != test.timestamps || vs != test.values {
+			t.Fatalf("encodings mismatch for %T: got %s/%s, exp %s/%s", test.value, ts, vs, test.timestamps, test.values)
+		}
+	}
+
+	if _, _, err := tsm1.BlockEncodings([]byte{10}); err == nil {
+		t.Fatalf("expected error decoding block encodings, got nil")
+	}
+}
+
 func TestEncoding_Count(t *testing.T) {
 	tests := []struct {
 		value     interface{}
@@ -1384,6 +1384,20 @@ func (e *Engine) DeleteSeriesRange(itr tsdb.SeriesIterator, min, max int64) erro
 // DeleteSeriesRangeWithPredicate removes the values between min and max (inclusive) from all series
 // for which predicate() returns true. If predicate() is nil, then all values in range are removed.
 func (e *Engine) DeleteSeriesRangeWithPredicate(itr tsdb.SeriesIterator, predicate func(name []byte, tags models.Tags) (int64, int64, bool)) error {
+	return e.deleteSeriesRangeWithPredicate(itr, predicate, nil)
+}
+
+// DeleteSeriesFieldsRange removes the values between min and max (inclusive) of the fields
+// of all series for which predicate() returns true. predicate() is called with the series
+// key and the field of each key of the series. If predicate() is nil, then all fields are removed.
+// Series are removed from the index once none of their fields have values left.
+func (e *Engine) DeleteSeriesFieldsRange(itr tsdb.SeriesIterator, min, max int64, predicate func(seriesKey, field []byte) bool) error {
+	return e.deleteSeriesRangeWithPredicate(itr, func(name []byte, tags models.Tags) (int64, int64, bool) {
+		return min, max, true
+	}, predicate)
+}
+
+func (e *Engine) deleteSeriesRangeWithPredicate(itr tsdb.SeriesIterator, predicate func(name []byte, tags models.Tags) (int64, int64, bool), fieldPredicate func(seriesKey, field []byte) bool) error {
 	var disableOnce bool
 
 	// Ensure that the index does not compact away the measurement or series we're
@@ -1465,7 +1479,7 @@ func (e *Engine) DeleteSeriesRangeWithPredicate(itr tsdb.SeriesIterator, predica
 
 		if sz >= deleteFlushThreshold || flushBatch {
 			// Delete all matching batch.
-			if err := e.deleteSeriesRange(batch, min, max); err != nil {
+			if err := e.deleteSeriesRange(batch, min, max, fieldPredicate); err != nil {
 				return err
 			}
 			batch = batch[:0]
@@ -1484,7 +1498,7 @@ func (e *Engine) DeleteSeriesRangeWithPredicate(itr tsdb.SeriesIterator, predica
 
 	if len(batch) > 0 {
 		// Delete all matching batch.
-		if err := e.deleteSeriesRange(batch, min, max); err != nil {
+		if err := e.deleteSeriesRange(batch, min, max, fieldPredicate); err != nil {
 			return err
 		}
 	}
@@ -1495,12 +1509,27 @@ func (e *Engine) DeleteSeriesRangeWithPredicate(itr tsdb.SeriesIterator, predica
 
 // deleteSeriesRange removes the values between min and max (inclusive) from all series.  This
 // does not update the index or disable compactions.  This should mainly be called by DeleteSeriesRange
-// and not directly.
-func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
+// and not directly.  If fieldPredicate is not nil, only the fields of the series for which it returns
+// true are removed.
+func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64, fieldPredicate func(seriesKey, field []byte) bool) error {
 	if len(seriesKeys) == 0 {
 		return nil
 	}
 
+	// matchesField is called concurrently by the deletes of the TSM files, so calls to
+	// fieldPredicate are serialized.
+	var fieldPredicateMu sync.Mutex
+	matchesField := func(key []byte) bool {
+		if fieldPredicate == nil {
+			return true
+		}
+		seriesKey, field := SeriesAndFieldFromCompositeKey(key)
+
+		fieldPredicateMu.Lock()
+		defer fieldPredicateMu.Unlock()
+		return fieldPredicate(seriesKey, field)
+	}
+
 	// Min and max time in the engine are slightly different from the query language values.
 	if min == influxql.MinTime {
 		min = math.MinInt64
@@ -1562,7 +1591,7 @@ func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
 			if j >= len(seriesKeys) {
 				break
 			}
-			if bytes.Equal(seriesKeys[j], seriesKey) {
+			if bytes.Equal(seriesKeys[j], seriesKey) && matchesField(indexKey) {
 				if err := batch.DeleteRange([][]byte{indexKey}, min, max); err != nil {
 					batch.Rollback()
 					return err
@@ -1585,7 +1614,7 @@ func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
 		// Cache does not walk keys in sorted order, so search the sorted
 		// series we need to delete to see if any of the cache keys match.
 		i := bytesutil.SearchBytes(seriesKeys, seriesKey)
-		if i < len(seriesKeys) && bytes.Equal(seriesKey, seriesKeys[i]) {
+		if i < len(seriesKeys) && bytes.Equal(seriesKey, seriesKeys[i]) && matchesField(k) {
 			// k is the measurement + tags + sep + field
 			deleteKeys = append(deleteKeys, k)
 		}
@@ -1479,6 +1479,70 @@ func TestEngine_DeleteSeriesRangeWithPredicate(t *testing.T) {
 }
 
 // Tests that a nil predicate deletes all values returned from the series iterator.
+func TestEngine_DeleteSeriesFieldsRange(t *testing.T) {
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) {
+			p1 := MustParsePointString("cpu,host=A value=1.1,other=1.2 1000000000")
+			p2 := MustParsePointString("cpu,host=B value=1.3 1000000000")
+			p3 := MustParsePointString("cpu,host=A value=1.4,other=1.5 2000000000") // In the cache
+
+			e, err := NewEngine(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// mock the planner so compactions don't run during the test
+			e.CompactionPlan = &mockPlanner{}
+			if err := e.Open(); err != nil {
+				t.Fatal(err)
+			}
+			defer e.Close()
+
+			for _, p := range []models.Point{p1, p2} {
+				if err := e.CreateSeriesIfNotExists(p.Key(), p.Name(), p.Tags()); err != nil {
+					t.Fatalf("create series index error: %v", err)
+				}
+			}
+			if err := e.WritePoints([]models.Point{p1, p2}); err != nil {
+				t.Fatalf("failed to write points: %s", err.Error())
+			}
+			if err := e.WriteSnapshot(); err != nil {
+				t.Fatalf("failed to snapshot: %s", err.Error())
+			}
+			if err := e.WritePoints([]models.Point{p3}); err != nil {
+				t.Fatalf("failed to write points: %s", err.Error())
+			}
+
+			// Delete the value field of every series.
+			itr := &seriesIterator{keys: [][]byte{[]byte("cpu,host=A"), []byte("cpu,host=B")}}
+			predicate := func(seriesKey, field []byte) bool {
+				return string(field) == "value"
+			}
+			if err := e.DeleteSeriesFieldsRange(itr, math.MinInt64, math.MaxInt64, predicate); err != nil {
+				t.Fatalf("failed to delete series fields: %v", err)
+			}
+
+			keys := e.FileStore.Keys()
+			if exp, got := 1, len(keys); exp != got {
+				t.Fatalf("series count mismatch: exp %v, got %v", exp, got)
+			} else if _, ok := keys["cpu,host=A#!~#other"]; !ok {
+				t.Fatalf("wrong series deleted: got %v", keys)
+			}
+			if exp, got := [][]byte{[]byte("cpu,host=A#!~#other")}, e.Cache.Keys(); !reflect.DeepEqual(exp, got) {
+				t.Fatalf("cache keys mismatch: exp %q, got %q", exp, got)
+			}
+
+			// Only the series without fields left is removed from the index.
+			if id := e.sfile.SeriesID([]byte("cpu"), models.NewTags(map[string]string{"host": "A"}), nil); id == 0 {
+				t.Fatal("series cpu,host=A was removed from the series file")
+			}
+			if id := e.sfile.SeriesID([]byte("cpu"), models.NewTags(map[string]string{"host": "B"}), nil); id != 0 {
+				t.Fatal("series cpu,host=B still exists in the series file")
+			}
+		})
+	}
+}
+
 func TestEngine_DeleteSeriesRangeWithPredicate_Nil(t *testing.T) {
 	for _, index := range tsdb.RegisteredIndexes() {
 		t.Run(index, func(t *testing.T) {
@@ -2028,6 +2092,117 @@ func TestEngine_CreateCursor_Descending(t *testing.T) {
 	}
 }
 
+// Ensure engine can create cursors which only return the values matching the
+// value condition of the request, with the values of the cache replacing the
+// ones of the TSM files.
+func TestEngine_CreateCursor_ValueCond(t *testing.T) {
+	t.Parallel()
+
+	cond := influxql.MustParseExpr(`"$" >= 2500 OR "$" < 3`)
+	match := func(v float64) bool { return v >= 2500 || v < 3 }
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) {
+			e := MustOpenEngine(index)
+			defer e.Close()
+
+			e.MeasurementFields([]byte("cpu")).CreateFieldIfNotExists([]byte("value"), influxql.Float)
+			e.CreateSeriesIfNotExists([]byte("cpu,host=A"), []byte("cpu"), models.NewTags(map[string]string{"host": "A"}))
+
+			// Write several blocks of values to the TSM files, then replace some
+			// of them, matching or not, in the cache.
+			points := make([]string, 0, 3000)
+			for i := 1; i <= 3000; i++ {
+				points = append(points, fmt.Sprintf("cpu,host=A value=%d %d", i, i))
+			}
+			if err := e.WritePointsString(points...); err != nil {
+				t.Fatalf("failed to write points: %s", err.Error())
+			}
+			e.MustWriteSnapshot()
+
+			if err := e.WritePointsString(
+				`cpu,host=A value=5000 50`,
+				`cpu,host=A value=1000 2`,
+				`cpu,host=A value=0 2000`,
+				`cpu,host=A value=1000 2600`,
+				`cpu,host=A value=2 3005`,
+				`cpu,host=A value=10 3006`,
+			); err != nil {
+				t.Fatalf("failed to write points: %s", err.Error())
+			}
+
+			q, err := e.CreateCursorIterator(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			read := func(req *tsdb.CursorRequest) ([]int64, []float64, bool) {
+				cur, err := q.Next(context.Background(), req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer cur.Close()
+
+				var ts []int64
+				var vs []float64
+				fcur := cur.(tsdb.FloatArrayCursor)
+				for a := fcur.Next(); a.Len() > 0; a = fcur.Next() {
+					ts = append(ts, a.Timestamps...)
+					vs = append(vs, a.Values...)
+				}
+				return ts, vs, cur.(tsdb.FilteredCursor).Filtered()
+			}
+
+			for _, tt := range []struct {
+				name       string
+				ascending  bool
+				start, end int64
+			}{
+				{name: "ascending", ascending: true, start: 0, end: 4000},
+				{name: "ascending range", ascending: true, start: 2, end: 2700},
+				{name: "descending", ascending: false, start: 0, end: 4000},
+				{name: "descending range", ascending: false, start: 2, end: 2700},
+			} {
+				t.Run(tt.name, func(t *testing.T) {
+					req := &tsdb.CursorRequest{
+						Name:      []byte("cpu"),
+						Tags:      models.ParseTags([]byte("cpu,host=A")),
+						Field:     "value",
+						Ascending: tt.ascending,
+						StartTime: tt.start,
+						EndTime:   tt.end,
+					}
+					allTs, allVs, filtered := read(req)
+					if filtered {
+						t.Fatal("expected the cursor not to be filtered")
+					}
+
+					var expTs []int64
+					var expVs []float64
+					for i, v := range allVs {
+						if match(v) {
+							expTs = append(expTs, allTs[i])
+							expVs = append(expVs, v)
+						}
+					}
+
+					req.ValueCond = cond
+					ts, vs, filtered := read(req)
+					if !filtered {
+						t.Fatal("expected the cursor to be filtered")
+					}
+					if !cmp.Equal(expTs, ts) {
+						t.Errorf("unexpected timestamps; -got/+exp\n%s", cmp.Diff(ts, expTs))
+					}
+					if !cmp.Equal(expVs, vs) {
+						t.Errorf("unexpected values; -got/+exp\n%s", cmp.Diff(vs, expVs))
+					}
+				})
+			}
+		})
+	}
+}
+
 func makeBlockTypeSlice(n int) []byte {
 	r := make([]byte, n)
 	b := tsm1.BlockFloat64
@@ -305,6 +305,21 @@ func (f *FileStore) Files() []TSMFile {
 	return f.files
 }
 
+// refFiles returns the TSM files currently loaded, with a reference held on
+// each so that they stay valid in the presence of compactions. The caller must
+// release each file with Unref once done with it.
+func (f *FileStore) refFiles() []TSMFile {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	files := make([]TSMFile, len(f.files))
+	copy(files, f.files)
+	for _, file := range files {
+		file.Ref()
+	}
+	return files
+}
+
 // Free releases any resources held by the FileStore.  The resources will be re-acquired
 // if necessary if they are needed after freeing them.
 func (f *FileStore) Free() error {
@@ -0,0 +1,142 @@
+package tsm1
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// SQLBlockExporter writes out all blocks of TSM files to a SQL export.
+type SQLBlockExporter struct {
+	w io.Writer
+
+	initialized bool
+
+	// Write schema, if true.
+	ShowSchema bool
+}
+
+// NewSQLBlockExporter returns a new instance of SQLBlockExporter.
+func NewSQLBlockExporter(w io.Writer) *SQLBlockExporter {
+	return &SQLBlockExporter{
+		w: w,
+
+		ShowSchema: true,
+	}
+}
+
+// Close ends the export and writes final output.
+func (e *SQLBlockExporter) Close() error {
+	return nil
+}
+
+// ExportFile writes all blocks of the TSM file.
+func (e *SQLBlockExporter) ExportFile(filename string) error {
+	if err := e.initialize(); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filename, os.O_RDONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := NewTSMReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	fmt.Fprintln(e.w, `BEGIN TRANSACTION;`)
+
+	itr := r.BlockIterator()
+	for itr.Next() {
+		key, minTime, maxTime, typ, checksum, buf, err := itr.Read()
+		if err != nil {
+			return err
+		}
+
+		seriesKey, field := SeriesAndFieldFromCompositeKey(key)
+		name, tags := models.ParseKeyBytes(seriesKey)
+
+		count, err := BlockCount(buf)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(e.w,
+			"INSERT INTO blocks (filename, measurement, tags, field, series_key, min_time, max_time, block_type, size, count, checksum) VALUES (%s, %s, %s, %s, %s, %d, %d, %s, %d, %d, %d);\n",
+			quoteSQL(filepath.Base(filename)),
+			quoteSQL(string(name)),
+			quoteSQL(strings.TrimPrefix(string(tags.HashKey()), ",")),
+			quoteSQL(string(field)),
+			quoteSQL(string(seriesKey)),
+			minTime,
+			maxTime,
+			quoteSQL(BlockTypeToInfluxQLDataType(typ).String()),
+			len(buf),
+			count,
+			checksum,
+		); err != nil {
+			return err
+		}
+	}
+	if err := itr.Err(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(e.w, "COMMIT;")
+	return nil
+}
+
+func (e *SQLBlockExporter) initialize() error {
+	if e.initialized {
+		return nil
+	}
+	e.initialized = true
+
+	if !e.ShowSchema {
+		return nil
+	}
+	fmt.Fprintln(e.w, `
+CREATE TABLE IF NOT EXISTS blocks (
+	filename    TEXT NOT NULL,
+	measurement TEXT NOT NULL,
+	tags        TEXT NOT NULL,
+	field       TEXT NOT NULL,
+	series_key  TEXT NOT NULL,
+	min_time    INTEGER NOT NULL,
+	max_time    INTEGER NOT NULL,
+	block_type  TEXT NOT NULL,
+	size        INTEGER NOT NULL,
+	count       INTEGER NOT NULL,
+	checksum    INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_blocks_filename ON blocks (filename);
+CREATE INDEX IF NOT EXISTS idx_blocks_series_key ON blocks (series_key);
+`[1:])
+
+	return nil
+}
+
+func quoteSQL(s string) string {
+	return `'` + sqlReplacer.Replace(toValidUTF8(s)) + `'`
+}
+
+var sqlReplacer = strings.NewReplacer(`'`, `''`, "\x00", "")
+
+func toValidUTF8(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,45 @@
+package tsm1_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+)
+
+func TestSQLBlockExporter_ExportFile(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	path := MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		"cpu,host=a,region=east#!~#value": {tsm1.NewValue(1, 1.5), tsm1.NewValue(2, 2.5)},
+		"mem,host=b#!~#free":              {tsm1.NewValue(10, int64(3))},
+	})
+
+	var buf bytes.Buffer
+	e := tsm1.NewSQLBlockExporter(&buf)
+	e.ShowSchema = false
+	if err := e.ExportFile(path); err != nil {
+		t.Fatal(err)
+	} else if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	} else if lines[0] != "BEGIN TRANSACTION;" || lines[3] != "COMMIT;" {
+		t.Fatalf("unexpected transaction:\n%s", buf.String())
+	}
+
+	for i, want := range []string{
+		"INSERT INTO blocks (filename, measurement, tags, field, series_key, min_time, max_time, block_type, size, count, checksum) VALUES ('000000001-000000001.tsm', 'cpu', 'host=a,region=east', 'value', 'cpu,host=a,region=east', 1, 2, 'float', ",
+		"INSERT INTO blocks (filename, measurement, tags, field, series_key, min_time, max_time, block_type, size, count, checksum) VALUES ('000000001-000000001.tsm', 'mem', 'host=b', 'free', 'mem,host=b', 10, 10, 'integer', ",
+	} {
+		if got := lines[i+1]; !strings.HasPrefix(got, want) {
+			t.Fatalf("unexpected block %d:\ngot=%s\nwant prefix=%s", i, got, want)
+		}
+	}
+}
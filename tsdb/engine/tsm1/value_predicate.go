@@ -0,0 +1,337 @@
+package tsm1
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/influxdata/influxql"
+)
+
+// valueRef is the reference to the values of the field in the ValueCond of
+// a cursor request.
+const valueRef = "$"
+
+// valuePredicate matches the values of a field against the ValueCond of a
+// cursor request, so that the array cursors only return the matching values.
+//
+// The comparisons follow the semantics of Flux, so that the values a filter
+// pushed down to the array cursors returns are the same as the ones the filter
+// returns in Flux: integers, unsigned integers and floats compare with each
+// other, strings are ordered and match regular expressions, and booleans only
+// compare for equality. Comparisons between other types never match.
+type valuePredicate interface {
+	matchFloat(v float64) bool
+	matchInteger(v int64) bool
+	matchUnsigned(v uint64) bool
+	matchString(v string) bool
+	matchBoolean(v bool) bool
+}
+
+// newValuePredicate returns a valuePredicate evaluating expr, which must only
+// reference the values of the field. It returns an error if expr can not be
+// evaluated on the values alone.
+func newValuePredicate(expr influxql.Expr) (valuePredicate, error) {
+	switch expr := expr.(type) {
+	case *influxql.ParenExpr:
+		return newValuePredicate(expr.Expr)
+
+	case *influxql.BooleanLiteral:
+		return valuePredicateConstant(expr.Val), nil
+
+	case *influxql.BinaryExpr:
+		switch expr.Op {
+		case influxql.AND, influxql.OR:
+			left, err := newValuePredicate(expr.LHS)
+			if err != nil {
+				return nil, err
+			}
+			right, err := newValuePredicate(expr.RHS)
+			if err != nil {
+				return nil, err
+			}
+			if expr.Op == influxql.AND {
+				return &valuePredicateAnd{left: left, right: right}, nil
+			}
+			return &valuePredicateOr{left: left, right: right}, nil
+
+		case influxql.EQ, influxql.NEQ, influxql.LT, influxql.LTE, influxql.GT, influxql.GTE, influxql.EQREGEX, influxql.NEQREGEX:
+			return newValuePredicateComparison(expr)
+		}
+	}
+	return nil, fmt.Errorf("unsupported value condition: %v", expr)
+}
+
+// newValuePredicateComparison returns a valuePredicate comparing the values
+// with a literal. The literal may be on either side of the comparison.
+func newValuePredicateComparison(expr *influxql.BinaryExpr) (valuePredicate, error) {
+	op, ref, lit := expr.Op, expr.LHS, expr.RHS
+	_, isRef := ref.(*influxql.VarRef)
+	if !isRef {
+		// The literal is on the left, so swap the sides of the comparison.
+		ref, lit = lit, ref
+		switch op {
+		case influxql.LT:
+			op = influxql.GT
+		case influxql.LTE:
+			op = influxql.GTE
+		case influxql.GT:
+			op = influxql.LT
+		case influxql.GTE:
+			op = influxql.LTE
+		case influxql.EQREGEX, influxql.NEQREGEX:
+			return nil, fmt.Errorf("unsupported value condition: %v", expr)
+		}
+	}
+	if ref, ok := ref.(*influxql.VarRef); !ok || ref.Val != valueRef {
+		return nil, fmt.Errorf("unsupported value condition: %v", expr)
+	}
+
+	p := &valuePredicateComparison{op: op, literalFirst: !isRef}
+	switch lit := lit.(type) {
+	case *influxql.NumberLiteral:
+		p.typ, p.float = influxql.Float, lit.Val
+	case *influxql.IntegerLiteral:
+		p.typ, p.integer = influxql.Integer, lit.Val
+	case *influxql.UnsignedLiteral:
+		p.typ, p.unsigned = influxql.Unsigned, lit.Val
+	case *influxql.StringLiteral:
+		p.typ, p.string = influxql.String, lit.Val
+	case *influxql.BooleanLiteral:
+		p.typ, p.boolean = influxql.Boolean, lit.Val
+	case *influxql.RegexLiteral:
+		p.regex = lit.Val
+	default:
+		return nil, fmt.Errorf("unsupported value condition: %v", expr)
+	}
+
+	// Ensure that a regex is compared if and only if the comparison is a regex
+	if (p.regex != nil) != (op == influxql.EQREGEX || op == influxql.NEQREGEX) {
+		return nil, fmt.Errorf("invalid comparison involving regex: %v", expr)
+	}
+	return p, nil
+}
+
+// valuePredicateConstant is a condition which is always true or always false.
+type valuePredicateConstant bool
+
+func (p valuePredicateConstant) matchFloat(float64) bool   { return bool(p) }
+func (p valuePredicateConstant) matchInteger(int64) bool   { return bool(p) }
+func (p valuePredicateConstant) matchUnsigned(uint64) bool { return bool(p) }
+func (p valuePredicateConstant) matchString(string) bool   { return bool(p) }
+func (p valuePredicateConstant) matchBoolean(bool) bool    { return bool(p) }
+
+// valuePredicateAnd combines two value predicates with an And.
+type valuePredicateAnd struct {
+	left, right valuePredicate
+}
+
+func (p *valuePredicateAnd) matchFloat(v float64) bool {
+	return p.left.matchFloat(v) && p.right.matchFloat(v)
+}
+
+func (p *valuePredicateAnd) matchInteger(v int64) bool {
+	return p.left.matchInteger(v) && p.right.matchInteger(v)
+}
+
+func (p *valuePredicateAnd) matchUnsigned(v uint64) bool {
+	return p.left.matchUnsigned(v) && p.right.matchUnsigned(v)
+}
+
+func (p *valuePredicateAnd) matchString(v string) bool {
+	return p.left.matchString(v) && p.right.matchString(v)
+}
+
+func (p *valuePredicateAnd) matchBoolean(v bool) bool {
+	return p.left.matchBoolean(v) && p.right.matchBoolean(v)
+}
+
+// valuePredicateOr combines two value predicates with an Or.
+type valuePredicateOr struct {
+	left, right valuePredicate
+}
+
+func (p *valuePredicateOr) matchFloat(v float64) bool {
+	return p.left.matchFloat(v) || p.right.matchFloat(v)
+}
+
+func (p *valuePredicateOr) matchInteger(v int64) bool {
+	return p.left.matchInteger(v) || p.right.matchInteger(v)
+}
+
+func (p *valuePredicateOr) matchUnsigned(v uint64) bool {
+	return p.left.matchUnsigned(v) || p.right.matchUnsigned(v)
+}
+
+func (p *valuePredicateOr) matchString(v string) bool {
+	return p.left.matchString(v) || p.right.matchString(v)
+}
+
+func (p *valuePredicateOr) matchBoolean(v bool) bool {
+	return p.left.matchBoolean(v) || p.right.matchBoolean(v)
+}
+
+// valuePredicateComparison compares the values with a literal. The type of the
+// literal is typ, unless it is a regex. If the literal was on the left of the
+// comparison, op has been swapped to compare the values with the literal.
+type valuePredicateComparison struct {
+	op           influxql.Token
+	typ          influxql.DataType
+	literalFirst bool
+
+	float    float64
+	integer  int64
+	unsigned uint64
+	string   string
+	boolean  bool
+	regex    *regexp.Regexp
+}
+
+func (p *valuePredicateComparison) matchFloat(v float64) bool {
+	switch p.typ {
+	case influxql.Float:
+		return compareFloat(p.op, v, p.float)
+	case influxql.Integer:
+		return compareFloat(p.op, v, float64(p.integer))
+	case influxql.Unsigned:
+		return compareFloat(p.op, v, float64(p.unsigned))
+	}
+	return false
+}
+
+func (p *valuePredicateComparison) matchInteger(v int64) bool {
+	switch p.typ {
+	case influxql.Float:
+		return compareFloat(p.op, float64(v), p.float)
+	case influxql.Integer:
+		return compareInteger(p.op, v, p.integer)
+	case influxql.Unsigned:
+		if v < 0 {
+			return compareNegativeInteger(p.op, !p.literalFirst)
+		}
+		return compareUnsigned(p.op, uint64(v), p.unsigned)
+	}
+	return false
+}
+
+func (p *valuePredicateComparison) matchUnsigned(v uint64) bool {
+	switch p.typ {
+	case influxql.Float:
+		return compareFloat(p.op, float64(v), p.float)
+	case influxql.Integer:
+		if p.integer < 0 {
+			return compareNegativeInteger(p.op, p.literalFirst)
+		}
+		return compareUnsigned(p.op, v, uint64(p.integer))
+	case influxql.Unsigned:
+		return compareUnsigned(p.op, v, p.unsigned)
+	}
+	return false
+}
+
+func (p *valuePredicateComparison) matchString(v string) bool {
+	switch {
+	case p.regex != nil:
+		return p.regex.MatchString(v) == (p.op == influxql.EQREGEX)
+	case p.typ == influxql.String:
+		return compareString(p.op, v, p.string)
+	}
+	return false
+}
+
+func (p *valuePredicateComparison) matchBoolean(v bool) bool {
+	if p.typ != influxql.Boolean {
+		return false
+	}
+	switch p.op {
+	case influxql.EQ:
+		return v == p.boolean
+	case influxql.NEQ:
+		return v != p.boolean
+	}
+	return false
+}
+
+// compareNegativeInteger returns the result of comparing a negative integer
+// with an unsigned integer, as Flux does: a negative integer on the left of an
+// ordering comparison is always true, and on the right always false.
+func compareNegativeInteger(op influxql.Token, left bool) bool {
+	switch op {
+	case influxql.EQ:
+		return false
+	case influxql.NEQ:
+		return true
+	}
+	return left
+}
+
+func compareFloat(op influxql.Token, l, r float64) bool {
+	switch op {
+	case influxql.EQ:
+		return l == r
+	case influxql.NEQ:
+		return l != r
+	case influxql.LT:
+		return l < r
+	case influxql.LTE:
+		return l <= r
+	case influxql.GT:
+		return l > r
+	case influxql.GTE:
+		return l >= r
+	}
+	return false
+}
+
+func compareInteger(op influxql.Token, l, r int64) bool {
+	switch op {
+	case influxql.EQ:
+		return l == r
+	case influxql.NEQ:
+		return l != r
+	case influxql.LT:
+		return l < r
+	case influxql.LTE:
+		return l <= r
+	case influxql.GT:
+		return l > r
+	case influxql.GTE:
+		return l >= r
+	}
+	return false
+}
+
+func compareUnsigned(op influxql.Token, l, r uint64) bool {
+	switch op {
+	case influxql.EQ:
+		return l == r
+	case influxql.NEQ:
+		return l != r
+	case influxql.LT:
+		return l < r
+	case influxql.LTE:
+		return l <= r
+	case influxql.GT:
+		return l > r
+	case influxql.GTE:
+		return l >= r
+	}
+	return false
+}
+
+func compareString(op influxql.Token, l, r string) bool {
+	switch op {
+	case influxql.EQ:
+		return l == r
+	case influxql.NEQ:
+		return l != r
+	case influxql.LT:
+		return l < r
+	case influxql.LTE:
+		return l <= r
+	case influxql.GT:
+		return l > r
+	case influxql.GTE:
+		return l >= r
+	}
+	return false
+}
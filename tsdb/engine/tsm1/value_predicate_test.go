@@ -0,0 +1,239 @@
+package tsm1
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxql"
+)
+
+// TestValuePredicate_Flux ensures that a value predicate matches the same
+// values as the comparisons of Flux, so that pushing a filter on the values
+// down to the array cursors does not change the result of a query.
+func TestValuePredicate_Flux(t *testing.T) {
+	fieldValues := []interface{}{
+		-2.5, 0.0, 1.0, 100.0, 100.5, math.MaxUint64 * 1.5, math.Inf(-1), math.NaN(),
+		int64(math.MinInt64), int64(-1), int64(0), int64(1), int64(100), int64(math.MaxInt64),
+		uint64(0), uint64(1), uint64(100), uint64(math.MaxUint64),
+		"", "a", "abc", "b", "100",
+		true, false,
+	}
+
+	literals := []influxql.Expr{
+		&influxql.NumberLiteral{Val: -1},
+		&influxql.NumberLiteral{Val: 1},
+		&influxql.NumberLiteral{Val: 100.5},
+		&influxql.IntegerLiteral{Val: -1},
+		&influxql.IntegerLiteral{Val: 1},
+		&influxql.IntegerLiteral{Val: 100},
+		&influxql.UnsignedLiteral{Val: 1},
+		&influxql.UnsignedLiteral{Val: math.MaxUint64},
+		&influxql.StringLiteral{Val: "a"},
+		&influxql.StringLiteral{Val: "abc"},
+		&influxql.BooleanLiteral{Val: true},
+		&influxql.BooleanLiteral{Val: false},
+		&influxql.RegexLiteral{Val: regexp.MustCompile(`^a`)},
+	}
+
+	ops := map[influxql.Token]ast.OperatorKind{
+		influxql.EQ:       ast.EqualOperator,
+		influxql.NEQ:      ast.NotEqualOperator,
+		influxql.LT:       ast.LessThanOperator,
+		influxql.LTE:      ast.LessThanEqualOperator,
+		influxql.GT:       ast.GreaterThanOperator,
+		influxql.GTE:      ast.GreaterThanEqualOperator,
+		influxql.EQREGEX:  ast.RegexpMatchOperator,
+		influxql.NEQREGEX: ast.NotRegexpMatchOperator,
+	}
+
+	ref := &influxql.VarRef{Val: valueRef}
+	for _, lit := range literals {
+		_, isRegex := lit.(*influxql.RegexLiteral)
+		for op, fluxOp := range ops {
+			if isRegex != (op == influxql.EQREGEX || op == influxql.NEQREGEX) {
+				continue
+			}
+
+			exprs := []*influxql.BinaryExpr{{LHS: ref, Op: op, RHS: lit}}
+			if !isRegex {
+				exprs = append(exprs, &influxql.BinaryExpr{LHS: lit, Op: op, RHS: ref})
+			}
+
+			for _, expr := range exprs {
+				pred, err := newValuePredicate(expr)
+				if err != nil {
+					t.Fatalf("unexpected error for %v: %v", expr, err)
+				}
+
+				for _, v := range fieldValues {
+					l, r := values.New(v), literalValue(lit)
+					if expr.LHS != ref {
+						l, r = r, l
+					}
+					want := fluxCompare(fluxOp, l, r)
+					if got := matchValue(pred, v); got != want {
+						t.Errorf("%v with $ = %#v: got %v, want %v", expr, v, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+// fluxCompare returns the result of comparing l with r in Flux, or false if
+// Flux does not support the comparison.
+func fluxCompare(op ast.OperatorKind, l, r values.Value) bool {
+	fn, err := values.LookupBinaryFunction(values.BinaryFuncSignature{
+		Operator: op,
+		Left:     l.Type().Nature(),
+		Right:    r.Type().Nature(),
+	})
+	if err != nil {
+		return false
+	}
+	v, err := fn(l, r)
+	if err != nil {
+		panic(err)
+	}
+	return v.Bool()
+}
+
+func literalValue(lit influxql.Expr) values.Value {
+	switch lit := lit.(type) {
+	case *influxql.NumberLiteral:
+		return values.New(lit.Val)
+	case *influxql.IntegerLiteral:
+		return values.New(lit.Val)
+	case *influxql.UnsignedLiteral:
+		return values.New(lit.Val)
+	case *influxql.StringLiteral:
+		return values.New(lit.Val)
+	case *influxql.BooleanLiteral:
+		return values.New(lit.Val)
+	case *influxql.RegexLiteral:
+		return values.New(lit.Val)
+	default:
+		panic(fmt.Sprintf("unexpected literal %T", lit))
+	}
+}
+
+func matchValue(pred valuePredicate, v interface{}) bool {
+	switch v := v.(type) {
+	case float64:
+		return pred.matchFloat(v)
+	case int64:
+		return pred.matchInteger(v)
+	case uint64:
+		return pred.matchUnsigned(v)
+	case string:
+		return pred.matchString(v)
+	case bool:
+		return pred.matchBoolean(v)
+	default:
+		panic(fmt.Sprintf("unexpected value %T", v))
+	}
+}
+
+func TestNewValuePredicate(t *testing.T) {
+	tests := []struct {
+		name  string
+		expr  string
+		match []interface{}
+		skip  []interface{}
+	}{
+		{
+			name:  "and",
+			expr:  `"$" > 1 AND "$" <= 5`,
+			match: []interface{}{2.0, int64(5), uint64(3)},
+			skip:  []interface{}{1.0, int64(6), uint64(0), "3"},
+		},
+		{
+			name:  "or in parens",
+			expr:  `("$" < 0 OR "$" = 10) AND "$" != -5`,
+			match: []interface{}{-1.0, int64(10), uint64(10)},
+			skip:  []interface{}{0.0, int64(-5), uint64(5)},
+		},
+		{
+			name:  "literal on the left",
+			expr:  `10 > "$"`,
+			match: []interface{}{9.5, int64(-10), uint64(0)},
+			skip:  []interface{}{10.0, int64(11), uint64(10)},
+		},
+		{
+			name:  "strings",
+			expr:  `"$" >= 'b' OR "$" =~ /^a.c$/`,
+			match: []interface{}{"b", "c", "abc"},
+			skip:  []interface{}{"a", "ab", "Z", 10.0},
+		},
+		{
+			name:  "booleans",
+			expr:  `"$" = true`,
+			match: []interface{}{true},
+			skip:  []interface{}{false, int64(1), "true"},
+		},
+		{
+			name:  "constant",
+			expr:  `true`,
+			match: []interface{}{false, 0.0, int64(0), uint64(0), ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := influxql.ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pred, err := newValuePredicate(expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, v := range tt.match {
+				if !matchValue(pred, v) {
+					t.Errorf("expected %#v to match", v)
+				}
+			}
+			for _, v := range tt.skip {
+				if matchValue(pred, v) {
+					t.Errorf("expected %#v not to match", v)
+				}
+			}
+		})
+	}
+}
+
+func TestNewValuePredicate_Unsupported(t *testing.T) {
+	for _, s := range []string{
+		`"$" > host`,
+		`host = 'a'`,
+		`"$" = "$"`,
+		`"$" + 1 > 2`,
+		`"$" > now()`,
+		`"$"`,
+	} {
+		expr, err := influxql.ParseExpr(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := newValuePredicate(expr); err == nil {
+			t.Errorf("expected an error for %s", s)
+		}
+	}
+
+	// Misplaced regexes do not parse, so build the expressions.
+	re := &influxql.RegexLiteral{Val: regexp.MustCompile(`a`)}
+	ref := &influxql.VarRef{Val: valueRef}
+	for _, expr := range []influxql.Expr{
+		&influxql.BinaryExpr{LHS: ref, Op: influxql.EQ, RHS: re},
+		&influxql.BinaryExpr{LHS: re, Op: influxql.EQREGEX, RHS: ref},
+	} {
+		if _, err := newValuePredicate(expr); err == nil {
+			t.Errorf("expected an error for %s", expr)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package tsm1
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+)
+
+// FileVerification is the result of the verification of a TSM file.
+type FileVerification struct {
+	Path string
+
+	// Blocks and Bytes are the number of blocks and bytes verified.
+	Blocks int
+	Bytes  int64
+
+	// CorruptBlocks is the number of blocks whose checksum does not match.
+	CorruptBlocks int
+
+	// BlockErr is the first corruption found in the blocks of the file, if any.
+	BlockErr error
+
+	// TombstoneErr is the corruption found in the tombstones of the file, if any.
+	TombstoneErr error
+}
+
+// Verify verifies the checksums of the blocks of the TSM files of the engine,
+// and that their tombstones can be read, while the engine is running. A
+// reference is held on each file while it is verified, so that compactions do
+// not remove it.
+//
+// wait is called with the size of each block read, to throttle the
+// verification, and fn is called with the result of the verification of each
+// file. Verification stops at the first error either of them returns.
+func (e *Engine) Verify(ctx context.Context, wait func(n int) error, fn func(v FileVerification) error) error {
+	files := e.FileStore.refFiles()
+	defer func() {
+		for _, f := range files {
+			f.Unref()
+		}
+	}()
+
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		v, err := verifyFile(f, wait)
+		if err != nil {
+			return err
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyFile verifies the blocks and the tombstones of f. It only returns an
+// error if wait does.
+func verifyFile(f TSMFile, wait func(n int) error) (FileVerification, error) {
+	v := FileVerification{Path: f.Path()}
+
+	itr := f.BlockIterator()
+	for itr.Next() {
+		_, _, _, _, checksum, buf, err := itr.Read()
+		if err != nil {
+			v.BlockErr = fmt.Errorf("read block %d of key %q: %w", v.Blocks, itr.key, err)
+			break
+		}
+		if err := wait(len(buf)); err != nil {
+			return v, err
+		}
+
+		if expected := crc32.ChecksumIEEE(buf); checksum != expected {
+			if v.BlockErr == nil {
+				v.BlockErr = fmt.Errorf("block %d of key %q: got checksum %d, expected %d", v.Blocks, itr.key, checksum, expected)
+			}
+			v.CorruptBlocks++
+		}
+		v.Blocks++
+		v.Bytes += int64(len(buf))
+	}
+
+	if r, ok := f.(*TSMReader); ok && r.tombstoner != nil {
+		if err := r.tombstoner.Walk(func(Tombstone) error { return nil }); err != nil {
+			v.TombstoneErr = fmt.Errorf("read tombstones: %w", err)
+		}
+	}
+	return v, nil
+}
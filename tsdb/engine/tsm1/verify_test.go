@@ -0,0 +1,84 @@
+package tsm1_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+)
+
+func TestEngine_Verify(t *testing.T) {
+	e := MustOpenEngine("tsi1")
+	defer e.Close()
+
+	if err := e.WritePointsString(
+		`cpu,host=A value=1.1 1000000000`,
+		`cpu,host=B value=1.2 2000000000`,
+	); err != nil {
+		t.Fatal(err)
+	}
+	e.MustWriteSnapshot()
+
+	var waited int
+	wait := func(n int) error { waited += n; return nil }
+
+	var results []tsm1.FileVerification
+	verify := func() {
+		results = results[:0]
+		if err := e.Verify(context.Background(), wait, func(v tsm1.FileVerification) error {
+			results = append(results, v)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("got %d verified files, expected 1", len(results))
+		}
+	}
+
+	verify()
+	if v := results[0]; v.BlockErr != nil || v.TombstoneErr != nil {
+		t.Fatalf("unexpected corruption: %v, %v", v.BlockErr, v.TombstoneErr)
+	} else if v.Blocks != 2 {
+		t.Fatalf("got %d verified blocks, expected 2", v.Blocks)
+	} else if int64(waited) != v.Bytes {
+		t.Fatalf("waited for %d bytes, verified %d", waited, v.Bytes)
+	}
+
+	// Corrupt the data of the first block, after its checksum.
+	f, err := os.OpenFile(results[0].Path, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b [1]byte
+	if _, err := f.ReadAt(b[:], 10); err != nil {
+		t.Fatal(err)
+	}
+	b[0] ^= 0xff
+	if _, err := f.WriteAt(b[:], 10); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	verify()
+	if v := results[0]; v.BlockErr == nil || v.CorruptBlocks != 1 {
+		t.Fatalf("expected 1 corrupt block, got %d: %v", v.CorruptBlocks, v.BlockErr)
+	}
+}
+
+func TestEngine_Verify_Canceled(t *testing.T) {
+	e := MustOpenEngine("tsi1")
+	defer e.Close()
+
+	if err := e.WritePointsString(`cpu,host=A value=1.1 1000000000`); err != nil {
+		t.Fatal(err)
+	}
+	e.MustWriteSnapshot()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := e.Verify(ctx, func(int) error { return nil }, func(tsm1.FileVerification) error { return nil }); err != context.Canceled {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
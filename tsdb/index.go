@@ -160,20 +160,25 @@ func (e *seriesElemAdapter) Expr() influxql.Expr { return e.expr }
 
 var _ SeriesIDIterator = (*PredicateSeriesIDIterator)(nil)
 
+// PredicateSeriesIDIterator filters the series of an iterator with a delete predicate.
+// A series matches if the predicate matches the series, or one of the fields it is
+// given.
 type PredicateSeriesIDIterator struct {
-	itr   SeriesIDIterator
-	sfile *SeriesFile
-	pred  influxdb.Predicate
+	itr    SeriesIDIterator
+	sfile  *SeriesFile
+	pred   influxdb.Predicate
+	fields [][]byte
 }
 
-func NewPredicateSeriesIDIterator(itr SeriesIDIterator, sfile *SeriesFile, pred influxdb.Predicate) SeriesIDIterator {
+func NewPredicateSeriesIDIterator(itr SeriesIDIterator, sfile *SeriesFile, pred influxdb.Predicate, fields [][]byte) SeriesIDIterator {
 	if pred == nil {
 		return itr
 	}
 	return &PredicateSeriesIDIterator{
-		itr:   itr,
-		sfile: sfile,
-		pred:  pred,
+		itr:    itr,
+		sfile:  sfile,
+		pred:   pred,
+		fields: fields,
 	}
 }
 
@@ -193,15 +198,38 @@ func (itr *PredicateSeriesIDIterator) Next() (SeriesIDElem, error) {
 		}
 
 		name, tags := ParseSeriesKey(seriesKey)
-		tags = append(models.Tags{{Key: models.MeasurementTagKeyBytes, Value: name}}, tags...)
-		key := models.MakeKey(name, tags)
-		if !itr.pred.Matches(key) {
+		if !itr.matches(name, tags) {
 			continue
 		}
 		return elem, nil
 	}
 }
 
+func (itr *PredicateSeriesIDIterator) matches(name []byte, tags models.Tags) bool {
+	if itr.pred.Matches(PredicateKey(name, tags, nil)) {
+		return true
+	}
+	for _, field := range itr.fields {
+		if itr.pred.Matches(PredicateKey(name, tags, field)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PredicateKey returns the key a delete predicate matches for the field of a series.
+// The measurement and the field are set as the tags with the special keys
+// models.MeasurementTagKey and models.FieldKeyTagKey. The field is omitted if nil.
+func PredicateKey(name []byte, tags models.Tags, field []byte) []byte {
+	t := make(models.Tags, 0, len(tags)+2)
+	t = append(t, models.Tag{Key: models.MeasurementTagKeyBytes, Value: name})
+	t = append(t, tags...)
+	if field != nil {
+		t = append(t, models.Tag{Key: models.FieldKeyTagKeyBytes, Value: field})
+	}
+	return models.MakeKey(name, t)
+}
+
 // SeriesIDElem represents a single series and optional expression.
 type SeriesIDElem struct {
 	SeriesID uint64
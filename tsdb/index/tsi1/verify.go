@@ -0,0 +1,84 @@
+package tsi1
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// Verify checks the consistency of the index with the series file while the
+// index is open: the series of the index, and of each of its measurements,
+// must exist in the series file, and belong to the measurement. Series which
+// are dropped from the index concurrently are skipped.
+//
+// wait is called with the size of each series key read, to throttle the
+// verification. It returns the first inconsistency found, or the first error
+// returned by wait.
+func (i *Index) Verify(ctx context.Context, wait func(n int) error) error {
+	var err error
+	i.SeriesIDSet().ForEachNoLock(func(id uint64) {
+		if err != nil {
+			return
+		} else if err = ctx.Err(); err != nil {
+			return
+		}
+
+		key := i.sfile.SeriesKey(id)
+		if (key == nil || i.sfile.IsDeleted(id)) && i.hasSeriesID(id) {
+			err = fmt.Errorf("series %d of the index is missing from the series file", id)
+			return
+		}
+		err = wait(len(key))
+	})
+	if err != nil {
+		return err
+	}
+
+	return i.ForEachMeasurementName(func(name []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		itr, err := i.MeasurementSeriesIDIterator(name)
+		if err != nil {
+			return fmt.Errorf("measurement %q: %w", name, err)
+		} else if itr == nil {
+			return nil
+		}
+		defer itr.Close()
+
+		for {
+			e, err := itr.Next()
+			if err != nil {
+				return fmt.Errorf("measurement %q: %w", name, err)
+			} else if e.SeriesID == 0 {
+				return nil
+			}
+
+			key := i.sfile.SeriesKey(e.SeriesID)
+			if key == nil || i.sfile.IsDeleted(e.SeriesID) {
+				if !i.hasSeriesID(e.SeriesID) {
+					continue
+				}
+				return fmt.Errorf("series %d of measurement %q is missing from the series file", e.SeriesID, name)
+			} else if keyName, _ := tsdb.ParseSeriesKey(key); !bytes.Equal(keyName, name) {
+				return fmt.Errorf("series %d of measurement %q belongs to measurement %q in the series file", e.SeriesID, name, keyName)
+			}
+			if err := wait(len(key)); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// hasSeriesID returns true if the series with the given id is in the index.
+func (i *Index) hasSeriesID(id uint64) bool {
+	for _, p := range i.partitions {
+		if p.seriesIDSet.Contains(id) {
+			return true
+		}
+	}
+	return false
+}
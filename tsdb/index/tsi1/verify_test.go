@@ -0,0 +1,41 @@
+package tsi1_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+func TestIndex_Verify(t *testing.T) {
+	idx := MustOpenDefaultIndex()
+	defer idx.Close()
+
+	if err := idx.CreateSeriesSliceIfNotExists([]Series{
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "east"})},
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "west"})},
+		{Name: []byte("mem"), Tags: models.NewTags(map[string]string{"region": "east"})},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	idx.Run(t, func(t *testing.T) {
+		var waited int
+		if err := idx.Verify(context.Background(), func(n int) error { waited += n; return nil }); err != nil {
+			t.Fatal(err)
+		} else if waited == 0 {
+			t.Fatal("expected verification to wait for the series keys read")
+		}
+	})
+
+	// Delete a series of the index from the series file.
+	var id uint64
+	idx.SeriesIDSet().ForEach(func(seriesID uint64) { id = seriesID })
+	if err := idx.SeriesFile.DeleteSeriesID(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Verify(context.Background(), func(int) error { return nil }); err == nil || !strings.Contains(err.Error(), "missing from the series file") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
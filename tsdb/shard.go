@@ -734,6 +734,16 @@ func (s *Shard) DeleteSeriesRangeWithPredicate(itr SeriesIterator, predicate fun
 	return engine.DeleteSeriesRangeWithPredicate(itr, predicate)
 }
 
+// DeleteSeriesFieldsRange deletes the values between min and max (inclusive) of the fields of
+// the series for which predicate() returns true. If predicate() is nil, then all fields are deleted.
+func (s *Shard) DeleteSeriesFieldsRange(itr SeriesIterator, min, max int64, predicate func(seriesKey, field []byte) bool) error {
+	engine, err := s.Engine()
+	if err != nil {
+		return err
+	}
+	return engine.DeleteSeriesFieldsRange(itr, min, max, predicate)
+}
+
 // DeleteMeasurement deletes a measurement and all underlying series.
 func (s *Shard) DeleteMeasurement(name []byte) error {
 	engine, err := s.Engine()
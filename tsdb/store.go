@@ -25,6 +25,7 @@ import (
 	"github.com/influxdata/influxdb/v2/pkg/estimator/hll"
 	"github.com/influxdata/influxdb/v2/pkg/limiter"
 	"github.com/influxdata/influxql"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -39,6 +40,9 @@ var (
 	// ErrMultipleIndexTypes is returned when trying to do deletes on a database with
 	// multiple index types.
 	ErrMultipleIndexTypes = errors.New("cannot delete data. DB contains shards using both inmem and tsi1 indexes. Please convert all shards to use the same index type to delete data.")
+	// ErrShardIndexRebuild is returned when trying to rebuild the index of a
+	// shard whose index is already being rebuilt.
+	ErrShardIndexRebuild = errors.New("shard index is being rebuilt")
 )
 
 // Statistics gathered by the store.
@@ -91,6 +95,9 @@ type Store struct {
 	// This prevents new shards from being created while old ones are being deleted.
 	pendingShardDeletes map[uint64]struct{}
 
+	// Maintains a set of shards whose index is being rebuilt.
+	pendingIndexRebuilds map[uint64]struct{}
+
 	// Epoch tracker helps serialize writes and deletes that may conflict. It
 	// is stored by shard.
 	epochs map[uint64]*epochTracker
@@ -110,15 +117,16 @@ type Store struct {
 func NewStore(path string) *Store {
 	logger := zap.NewNop()
 	return &Store{
-		databases:           make(map[string]*databaseState),
-		path:                path,
-		sfiles:              make(map[string]*SeriesFile),
-		indexes:             make(map[string]interface{}),
-		pendingShardDeletes: make(map[uint64]struct{}),
-		epochs:              make(map[uint64]*epochTracker),
-		EngineOptions:       NewEngineOptions(),
-		Logger:              logger,
-		baseLogger:          logger,
+		databases:            make(map[string]*databaseState),
+		path:                 path,
+		sfiles:               make(map[string]*SeriesFile),
+		indexes:              make(map[string]interface{}),
+		pendingShardDeletes:  make(map[uint64]struct{}),
+		pendingIndexRebuilds: make(map[uint64]struct{}),
+		epochs:               make(map[uint64]*epochTracker),
+		EngineOptions:        NewEngineOptions(),
+		Logger:               logger,
+		baseLogger:           logger,
 	}
 }
 
@@ -490,6 +498,7 @@ func (s *Store) Close() error {
 	s.sfiles = map[string]*SeriesFile{}
 	s.indexes = make(map[string]interface{})
 	s.pendingShardDeletes = make(map[uint64]struct{})
+	s.pendingIndexRebuilds = make(map[uint64]struct{})
 	s.shards = nil
 	s.opened = false // Store may now be opened again.
 	s.mu.Unlock()
@@ -696,6 +705,93 @@ func (s *Store) SetShardEnabled(shardID uint64, enabled bool) error {
 	return nil
 }
 
+// RebuildShardIndex takes the shard with the specified ID offline, rebuilds
+// its tsi1 index with rebuild and brings the shard back online. rebuild is
+// called with the database series file and the data and WAL directories of
+// the closed shard, and must create the index in its "index" directory.
+//
+// Writes and queries to the shard fail while its index is rebuilt. If rebuild
+// fails, the previous index is restored.
+func (s *Store) RebuildShardIndex(shardID uint64, rebuild func(sfile *SeriesFile, path, walPath string) error) (err error) {
+	s.mu.Lock()
+	select {
+	case <-s.closing:
+		s.mu.Unlock()
+		return ErrStoreClosed
+	default:
+	}
+	sh := s.shards[shardID]
+	if sh == nil {
+		s.mu.Unlock()
+		return ErrShardNotFound
+	} else if _, ok := s.pendingIndexRebuilds[shardID]; ok {
+		s.mu.Unlock()
+		return ErrShardIndexRebuild
+	}
+	s.pendingIndexRebuilds[shardID] = struct{}{}
+	sfile := s.sfiles[sh.Database()]
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pendingIndexRebuilds, shardID)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	if typ := sh.IndexType(); typ != TSI1IndexName {
+		return fmt.Errorf("cannot rebuild %q index of shard %d, only %q indexes can be rebuilt", typ, shardID, TSI1IndexName)
+	}
+
+	log := s.Logger.With(logger.Shard(shardID))
+	log.Info("Rebuilding shard index")
+
+	sh.mu.RLock()
+	enabled := sh.enabled
+	sh.mu.RUnlock()
+
+	sh.SetEnabled(false)
+	if err := sh.Close(); err != nil {
+		sh.SetEnabled(enabled)
+		return err
+	}
+
+	// Bring the shard back online however the rebuild ends.
+	defer func() {
+		if e := sh.Open(); e != nil {
+			err = multierr.Append(err, fmt.Errorf("error reopening shard %d: %w", shardID, e))
+		}
+		sh.SetEnabled(enabled)
+
+		if err == nil {
+			log.Info("Rebuilt shard index")
+		}
+	}()
+
+	// Keep the previous index until the new one is built.
+	indexPath := filepath.Join(sh.path, "index")
+	oldPath := filepath.Join(sh.path, ".index.old")
+	if err := os.RemoveAll(oldPath); err != nil {
+		return err
+	}
+	if err := os.Rename(indexPath, oldPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := rebuild(sfile, sh.path, sh.walPath); err != nil {
+		log.Error("Failed to rebuild shard index, restoring previous index", zap.Error(err))
+		if e := os.RemoveAll(indexPath); e != nil {
+			return multierr.Append(err, e)
+		}
+		if e := os.Rename(oldPath, indexPath); e != nil && !os.IsNotExist(e) {
+			return multierr.Append(err, e)
+		}
+		return err
+	}
+	return os.RemoveAll(oldPath)
+}
+
 // DeleteShards removes all shards from disk.
 func (s *Store) DeleteShards() error {
 	for _, id := range s.ShardIDs() {
@@ -1304,50 +1400,132 @@ func (s *Store) DeleteSeriesWithPredicate(database string, min, max int64, pred
 		limit.Take()
 		defer limit.Release()
 
-		// install our guard and wait for any prior deletes to finish. the
-		// guard ensures future deletes that could conflict wait for us.
-		waiter := epochs[sh.id].WaitDelete(newGuard(min, max, nil, nil))
-		waiter.Wait()
-		defer waiter.Done()
+		_, err := s.deleteShardSeriesWithPredicate(sh, sfile, epochs[sh.id], min, max, pred, nil)
+		return err
+	})
+}
 
-		index, err := sh.Index()
-		if err != nil {
-			return err
-		}
+// DeleteShardSeriesWithPredicate deletes the series data of a single shard
+// between min and max matching pred, like DeleteSeriesWithPredicate. If wait is
+// not nil, it is called before each matching series is deleted, and the delete
+// stops with the error it returns. It returns the number of series deleted.
+func (s *Store) DeleteShardSeriesWithPredicate(shardID uint64, min, max int64, pred influxdb.Predicate, wait func() error) (int64, error) {
+	s.mu.RLock()
+	sh := s.shards[shardID]
+	if sh == nil {
+		s.mu.RUnlock()
+		return 0, ErrShardNotFound
+	}
+	if s.databases[sh.database].hasMultipleIndexTypes() {
+		s.mu.RUnlock()
+		return 0, ErrMultipleIndexTypes
+	}
+	sfile := s.sfiles[sh.database]
+	epoch := s.epochs[shardID]
+	s.mu.RUnlock()
 
-		// Find matching series keys for each measurement.
-		mitr, err := index.MeasurementIterator()
+	if sfile == nil {
+		return 0, nil
+	}
+	return s.deleteShardSeriesWithPredicate(sh, sfile, epoch, min, max, pred, wait)
+}
+
+func (s *Store) deleteShardSeriesWithPredicate(sh *Shard, sfile *SeriesFile, epoch *epochTracker, min, max int64, pred influxdb.Predicate, wait func() error) (int64, error) {
+	// install our guard and wait for any prior deletes to finish. the
+	// guard ensures future deletes that could conflict wait for us.
+	waiter := epoch.WaitDelete(newGuard(min, max, nil, nil))
+	waiter.Wait()
+	defer waiter.Done()
+
+	index, err := sh.Index()
+	if err != nil {
+		return 0, err
+	}
+
+	// Find matching series keys for each measurement.
+	mitr, err := index.MeasurementIterator()
+	if err != nil {
+		return 0, err
+	}
+	defer mitr.Close()
+
+	var n int64
+	for {
+		mm, err := mitr.Next()
 		if err != nil {
-			return err
+			return n, err
+		} else if mm == nil {
+			break
 		}
-		defer mitr.Close()
 
-		for {
-			mm, err := mitr.Next()
+		if err := func() error {
+			sitr, err := index.MeasurementSeriesIDIterator(mm)
 			if err != nil {
 				return err
-			} else if mm == nil {
-				break
+			} else if sitr == nil {
+				return nil
 			}
-
-			if err := func() error {
-				sitr, err := index.MeasurementSeriesIDIterator(mm)
-				if err != nil {
+			defer sitr.Close()
+
+			// The series are only counted once they are deleted, which
+			// happens once the iterator is exhausted.
+			var deleted int64
+			if pred == nil {
+				itr := &waitSeriesIterator{itr: NewSeriesIteratorAdapter(sfile, sitr), wait: wait, n: &deleted}
+				if err := sh.DeleteSeriesRange(itr, min, max); err != nil {
 					return err
-				} else if sitr == nil {
-					return nil
 				}
-				defer sitr.Close()
+				n += deleted
+				return nil
+			}
 
-				itr := NewSeriesIteratorAdapter(sfile, NewPredicateSeriesIDIterator(sitr, sfile, pred))
-				return sh.DeleteSeriesRange(itr, min, max)
-			}(); err != nil {
+			// The predicate may select fields, so a series matches if any of
+			// its fields does, and only the matching fields are deleted.
+			var fields [][]byte
+			if mf := sh.MeasurementFields(mm); mf != nil {
+				for _, field := range mf.FieldKeys() {
+					fields = append(fields, []byte(field))
+				}
+			}
+			itr := &waitSeriesIterator{itr: NewSeriesIteratorAdapter(sfile, NewPredicateSeriesIDIterator(sitr, sfile, pred, fields)), wait: wait, n: &deleted}
+			if err := sh.DeleteSeriesFieldsRange(itr, min, max, func(seriesKey, field []byte) bool {
+				name, tags := models.ParseKeyBytes(seriesKey)
+				return pred.Matches(PredicateKey(name, tags, field))
+			}); err != nil {
 				return err
 			}
+			n += deleted
+			return nil
+		}(); err != nil {
+			return n, err
 		}
+	}
 
-		return nil
-	})
+	return n, nil
+}
+
+// waitSeriesIterator counts the series of an iterator, calling wait before
+// returning each of them.
+type waitSeriesIterator struct {
+	itr  SeriesIterator
+	wait func() error
+	n    *int64
+}
+
+func (itr *waitSeriesIterator) Close() error { return itr.itr.Close() }
+
+func (itr *waitSeriesIterator) Next() (SeriesElem, error) {
+	elem, err := itr.itr.Next()
+	if elem == nil || err != nil {
+		return elem, err
+	}
+	if itr.wait != nil {
+		if err := itr.wait(); err != nil {
+			return nil, err
+		}
+	}
+	*itr.n++
+	return elem, nil
 }
 
 // DeleteSeries loops through the local shards and deletes the series data for
@@ -26,7 +26,9 @@ import (
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/pkg/deep"
 	"github.com/influxdata/influxdb/v2/pkg/slices"
+	"github.com/influxdata/influxdb/v2/predicate"
 	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
 	"github.com/influxdata/influxdb/v2/tsdb/index/inmem"
 	"github.com/influxdata/influxql"
 )
@@ -830,6 +832,165 @@ func TestStore_Shard_SeriesN(t *testing.T) {
 	}
 }
 
+func TestStore_RebuildShardIndex(t *testing.T) {
+	s := MustOpenStore(tsdb.TSI1IndexName)
+	defer s.Close()
+
+	s.MustCreateShardWithData("db0", "rp0", 1,
+		`cpu value=1 0`,
+		`cpu,host=serverA value=2 10`,
+	)
+
+	rebuild := func(sfile *tsdb.SeriesFile, path, walPath string) error {
+		if _, err := os.Stat(filepath.Join(path, "index")); !os.IsNotExist(err) {
+			t.Fatalf("expected the previous index to be moved away: %v", err)
+		}
+		return tsm1.IndexShard(sfile, path, walPath, tsdb.DefaultMaxIndexLogFileSize, tsdb.DefaultCacheMaxMemorySize, tsm1.DefaultIndexBatchSize, s.Logger, false)
+	}
+	if err := s.RebuildShardIndex(1, rebuild); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := s.Shard(1).SeriesN(), int64(2); got != exp {
+		t.Fatalf("got series count of %d, but expected %d", got, exp)
+	}
+
+	// The shard is back online.
+	s.MustWriteToShardString(1, `cpu,host=serverB value=3 20`)
+	if got, exp := s.Shard(1).SeriesN(), int64(3); got != exp {
+		t.Fatalf("got series count of %d, but expected %d", got, exp)
+	}
+
+	// The previous index is restored if the rebuild fails.
+	errRebuild := errors.New("rebuild failed")
+	if err := s.RebuildShardIndex(1, func(sfile *tsdb.SeriesFile, path, walPath string) error {
+		return errRebuild
+	}); err != errRebuild {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, exp := s.Shard(1).SeriesN(), int64(3); got != exp {
+		t.Fatalf("got series count of %d, but expected %d", got, exp)
+	}
+
+	// The shard is back online after a failed rebuild.
+	s.MustWriteToShardString(1, `cpu,host=serverC value=4 30`)
+	if got, exp := s.Shard(1).SeriesN(), int64(4); got != exp {
+		t.Fatalf("got series count of %d, but expected %d", got, exp)
+	}
+
+	if err := s.RebuildShardIndex(2, rebuild); err != tsdb.ErrShardNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestStore_DeleteSeriesWithPredicate(t *testing.T) {
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1,
+			`cpu,host=serverA value=1,other=2 0`,
+			`cpu,host=serverB value=3 0`,
+			`cpu,host=serverC value=4 0`,
+			`mem,host=serverA value=5 0`,
+		)
+
+		n, err := predicate.Parse(`_measurement="cpu" and (host="serverA" or host=~/B$/) and _field="value"`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pred, err := predicate.New(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.DeleteSeriesWithPredicate("db0", math.MinInt64, math.MaxInt64, pred); err != nil {
+			t.Fatal(err)
+		}
+
+		engine, err := s.Shard(1).Engine()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, k := range engine.(*tsm1.Engine).Cache.Keys() {
+			keys = append(keys, string(k))
+		}
+		if exp := []string{"cpu,host=serverA#!~#other", "cpu,host=serverC#!~#value", "mem,host=serverA#!~#value"}; !reflect.DeepEqual(keys, exp) {
+			t.Fatalf("got keys %q, expected %q", keys, exp)
+		}
+
+		// Only cpu,host=serverB has no fields left.
+		if got, exp := s.Shard(1).SeriesN(), int64(3); got != exp {
+			t.Fatalf("got series count of %d, but expected %d", got, exp)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
+func TestStore_DeleteShardSeriesWithPredicate(t *testing.T) {
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1,
+			`cpu,host=serverA value=1 0`,
+			`cpu,host=serverB value=2 0`,
+			`mem,host=serverA value=3 0`,
+		)
+		s.MustCreateShardWithData("db0", "rp0", 2,
+			`cpu,host=serverA value=1 10`,
+		)
+
+		n, err := predicate.Parse(`_measurement="cpu"`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pred, err := predicate.New(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var waits int
+		deleted, err := s.DeleteShardSeriesWithPredicate(1, math.MinInt64, math.MaxInt64, pred, func() error {
+			waits++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted != 2 || waits != 2 {
+			t.Fatalf("got %d series deleted after %d waits, expected 2", deleted, waits)
+		}
+		if got, exp := s.Shard(1).SeriesN(), int64(1); got != exp {
+			t.Fatalf("got series count of %d, but expected %d", got, exp)
+		}
+		if got, exp := s.Shard(2).SeriesN(), int64(1); got != exp {
+			t.Fatalf("got series count of %d for the other shard, but expected %d", got, exp)
+		}
+
+		// An error of wait stops the delete.
+		errStop := errors.New("stop")
+		if _, err := s.DeleteShardSeriesWithPredicate(2, math.MinInt64, math.MaxInt64, nil, func() error {
+			return errStop
+		}); !errors.Is(err, errStop) {
+			t.Fatalf("got error %v, expected %v", err, errStop)
+		}
+		if got, exp := s.Shard(2).SeriesN(), int64(1); got != exp {
+			t.Fatalf("got series count of %d, but expected %d", got, exp)
+		}
+
+		if _, err := s.DeleteShardSeriesWithPredicate(3, math.MinInt64, math.MaxInt64, nil, nil); err != tsdb.ErrShardNotFound {
+			t.Fatalf("got error %v, expected %v", err, tsdb.ErrShardNotFound)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
 func TestStore_MeasurementNames_Deduplicate(t *testing.T) {
 
 	test := func(index string) {
@@ -0,0 +1,45 @@
+package authorization
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorization"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+type PermissionService interface {
+	UpdatePermissions(ctx context.Context, id influxdb.ID, permissions []influxdb.Permission) error
+}
+
+// AuthedPermissionService is middleware for authorizing requests to the inner PermissionService.
+type AuthedPermissionService struct {
+	auth  AuthFinder
+	inner PermissionService
+}
+
+// NewAuthedPermissionService wraps an existing PermissionService with authorization middleware.
+func NewAuthedPermissionService(auth AuthFinder, inner PermissionService) *AuthedPermissionService {
+	return &AuthedPermissionService{auth: auth, inner: inner}
+}
+
+// UpdatePermissions replaces the permissions of a known authorization. The
+// caller must be allowed all of the new permissions.
+func (s *AuthedPermissionService) UpdatePermissions(ctx context.Context, authID influxdb.ID, permissions []influxdb.Permission) error {
+	auth, err := s.auth.FindAuthorizationByID(ctx, authID)
+	if err != nil {
+		return ErrAuthNotFound
+	}
+
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.AuthorizationsResourceType, auth.ID, auth.OrgID); err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWriteResource(ctx, influxdb.UsersResourceType, auth.UserID); err != nil {
+		return err
+	}
+	if err := authorization.VerifyPermissions(ctx, permissions); err != nil {
+		return err
+	}
+
+	return s.inner.UpdatePermissions(ctx, authID, permissions)
+}
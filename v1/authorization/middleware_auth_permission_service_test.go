@@ -0,0 +1,98 @@
+package authorization_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	itest "github.com/influxdata/influxdb/v2/testing"
+	"github.com/influxdata/influxdb/v2/v1/authorization"
+	"github.com/influxdata/influxdb/v2/v1/authorization/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthedPermissionService_UpdatePermissions(t *testing.T) {
+	var (
+		authID   = itest.MustIDBase16("0000000000001000")
+		userID   = itest.MustIDBase16("0000000000002000")
+		orgID    = itest.MustIDBase16("0000000000003000")
+		bucketID = itest.MustIDBase16("0000000000004000")
+	)
+	permissions := []influxdb.Permission{
+		*itest.MustNewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+	}
+
+	t.Run("error when auth not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		af := mocks.NewMockAuthFinder(ctrl)
+		af.EXPECT().
+			FindAuthorizationByID(ctx, authID).
+			Return(nil, &influxdb.Error{})
+
+		ps := authorization.NewAuthedPermissionService(af, nil)
+		err := ps.UpdatePermissions(ctx, authID, permissions)
+		assert.EqualError(t, err, authorization.ErrAuthNotFound.Error())
+	})
+
+	t.Run("error when permissions are not allowed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		auth := influxdb.Authorization{
+			ID:     authID,
+			OrgID:  orgID,
+			UserID: userID,
+			Status: influxdb.Active,
+			Permissions: append(influxdb.MePermissions(userID), influxdb.Permission{
+				Action:   influxdb.WriteAction,
+				Resource: influxdb.Resource{Type: influxdb.AuthorizationsResourceType, OrgID: &orgID},
+			}),
+		}
+		ctx := context.Background()
+		ctx = icontext.SetAuthorizer(ctx, &auth)
+
+		af := mocks.NewMockAuthFinder(ctrl)
+		af.EXPECT().
+			FindAuthorizationByID(ctx, authID).
+			Return(&auth, nil)
+
+		ps := authorization.NewAuthedPermissionService(af, nil)
+		err := ps.UpdatePermissions(ctx, authID, permissions)
+		assert.Equal(t, influxdb.EForbidden, influxdb.ErrorCode(err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		auth := influxdb.Authorization{
+			ID:          authID,
+			OrgID:       orgID,
+			UserID:      userID,
+			Status:      influxdb.Active,
+			Permissions: append(influxdb.OwnerPermissions(orgID), influxdb.MePermissions(userID)...),
+		}
+		ctx := context.Background()
+		ctx = icontext.SetAuthorizer(ctx, &auth)
+
+		af := mocks.NewMockAuthFinder(ctrl)
+		af.EXPECT().
+			FindAuthorizationByID(ctx, authID).
+			Return(&auth, nil)
+
+		inner := mocks.NewMockPermissionService(ctrl)
+		inner.EXPECT().
+			UpdatePermissions(ctx, authID, permissions).
+			Return(nil)
+
+		ps := authorization.NewAuthedPermissionService(af, inner)
+		err := ps.UpdatePermissions(ctx, authID, permissions)
+		assert.NoError(t, err)
+	})
+}
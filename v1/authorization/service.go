@@ -203,6 +203,29 @@ func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *
 	return auth, err
 }
 
+// UpdatePermissions replaces the permissions of the authorization.
+//
+// This API is intended for the InfluxQL GRANT and REVOKE statements.
+func (s *Service) UpdatePermissions(ctx context.Context, id influxdb.ID, permissions []influxdb.Permission) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		a, err := s.store.GetAuthorizationByID(ctx, tx, id)
+		if err != nil {
+			return ErrAuthNotFound
+		}
+
+		a.Permissions = permissions
+		if err := a.Valid(); err != nil {
+			return &influxdb.Error{
+				Err: err,
+			}
+		}
+		a.SetUpdatedAt(time.Now())
+
+		_, err = s.store.UpdateAuthorization(ctx, tx, id, a)
+		return err
+	})
+}
+
 func (s *Service) DeleteAuthorization(ctx context.Context, id influxdb.ID) error {
 	return s.store.Update(ctx, func(tx kv.Tx) (err error) {
 		return s.store.DeleteAuthorization(ctx, tx, id)
@@ -4,17 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/authorizer"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	iql "github.com/influxdata/influxdb/v2/influxql"
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
 	"github.com/influxdata/influxdb/v2/pkg/tracing"
 	"github.com/influxdata/influxdb/v2/pkg/tracing/fields"
 	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/v1/monitor"
+	"github.com/influxdata/influxdb/v2/v1/services/continuous_querier"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	"github.com/influxdata/influxql"
 )
@@ -35,6 +41,44 @@ type StatementExecutor struct {
 
 	DBRP influxdb.DBRPMappingServiceV2
 
+	// BucketService for creating, updating and deleting the buckets
+	// which back databases and retention policies.
+	BucketService influxdb.BucketService
+
+	// TaskService for managing the tasks which run continuous queries.
+	TaskService influxdb.TaskService
+
+	// ShardService for listing and dropping the shards of buckets.
+	ShardService influxdb.ShardService
+
+	// RunningQueryService for listing and killing running queries.
+	RunningQueryService influxdb.RunningQueryService
+
+	// Monitor for the statistics and diagnostics of the server.
+	Monitor *monitor.Monitor
+
+	// SubscriptionService for managing the subscriptions of buckets.
+	SubscriptionService influxdb.SubscriptionService
+
+	// AuthorizationService for the v1 authorizations which back users. The
+	// name of a user is the token of its authorization.
+	AuthorizationService influxdb.AuthorizationService
+
+	// Used for setting the passwords of users.
+	PasswordService interface {
+		SetPassword(ctx context.Context, id influxdb.ID, password string) error
+	}
+
+	// Used for granting and revoking the privileges of users.
+	PermissionService interface {
+		UpdatePermissions(ctx context.Context, id influxdb.ID, permissions []influxdb.Permission) error
+	}
+
+	// Used for rewriting points back into system for SELECT INTO statements.
+	PointsWriter interface {
+		WritePointsInto(*IntoWriteRequest) error
+	}
+
 	// Select statement limits
 	MaxSelectPointN   int
 	MaxSelectSeriesN  int
@@ -53,35 +97,35 @@ func (e *StatementExecutor) ExecuteStatement(ctx context.Context, stmt influxql.
 	var err error
 	switch stmt := stmt.(type) {
 	case *influxql.AlterRetentionPolicyStatement:
-		err = iql.ErrNotImplemented("ALTER RETENTION POLICY")
+		err = e.executeAlterRetentionPolicyStatement(ctx, stmt, ectx)
 	case *influxql.CreateContinuousQueryStatement:
-		err = iql.ErrNotImplemented("CREATE CONTINUOUS QUERY")
+		err = e.executeCreateContinuousQueryStatement(ctx, stmt, ectx)
 	case *influxql.CreateDatabaseStatement:
-		err = iql.ErrNotImplemented("CREATE DATABASE")
+		err = e.executeCreateDatabaseStatement(ctx, stmt, ectx)
 	case *influxql.CreateRetentionPolicyStatement:
-		err = iql.ErrNotImplemented("CREATE RETENTION POLICY")
+		err = e.executeCreateRetentionPolicyStatement(ctx, stmt, ectx)
 	case *influxql.CreateSubscriptionStatement:
-		err = iql.ErrNotImplemented("CREATE SUBSCRIPTION")
+		err = e.executeCreateSubscriptionStatement(ctx, stmt, ectx)
 	case *influxql.CreateUserStatement:
-		err = iql.ErrNotImplemented("CREATE USER")
+		err = e.executeCreateUserStatement(ctx, stmt, ectx)
 	case *influxql.DeleteSeriesStatement:
 		return e.executeDeleteSeriesStatement(ctx, stmt, ectx.Database, ectx)
 	case *influxql.DropContinuousQueryStatement:
-		err = iql.ErrNotImplemented("DROP CONTINUOUS QUERY")
+		err = e.executeDropContinuousQueryStatement(ctx, stmt, ectx)
 	case *influxql.DropDatabaseStatement:
-		err = iql.ErrNotImplemented("DROP DATABASE")
+		err = e.executeDropDatabaseStatement(ctx, stmt, ectx)
 	case *influxql.DropMeasurementStatement:
 		return e.executeDropMeasurementStatement(ctx, stmt, ectx.Database, ectx)
 	case *influxql.DropSeriesStatement:
-		err = iql.ErrNotImplemented("DROP SERIES")
+		err = e.executeDropSeriesStatement(ctx, stmt, ectx.Database, ectx)
 	case *influxql.DropRetentionPolicyStatement:
-		err = iql.ErrNotImplemented("DROP RETENTION POLICY")
+		err = e.executeDropRetentionPolicyStatement(ctx, stmt, ectx)
 	case *influxql.DropShardStatement:
-		err = iql.ErrNotImplemented("DROP SHARD")
+		err = e.executeDropShardStatement(ctx, stmt, ectx)
 	case *influxql.DropSubscriptionStatement:
-		err = iql.ErrNotImplemented("DROP SUBSCRIPTION")
+		err = e.executeDropSubscriptionStatement(ctx, stmt, ectx)
 	case *influxql.DropUserStatement:
-		err = iql.ErrNotImplemented("DROP USER")
+		err = e.executeDropUserStatement(ctx, stmt, ectx)
 	case *influxql.ExplainStatement:
 		if stmt.Analyze {
 			rows, err = e.executeExplainAnalyzeStatement(ctx, stmt, ectx)
@@ -89,47 +133,49 @@ func (e *StatementExecutor) ExecuteStatement(ctx context.Context, stmt influxql.
 			rows, err = e.executeExplainStatement(ctx, stmt, ectx)
 		}
 	case *influxql.GrantStatement:
-		err = iql.ErrNotImplemented("GRANT")
+		err = e.executeGrantStatement(ctx, stmt, ectx)
 	case *influxql.GrantAdminStatement:
-		err = iql.ErrNotImplemented("GRANT ALL")
+		err = e.executeGrantAdminStatement(ctx, stmt, ectx)
 	case *influxql.RevokeStatement:
-		err = iql.ErrNotImplemented("REVOKE")
+		err = e.executeRevokeStatement(ctx, stmt, ectx)
 	case *influxql.RevokeAdminStatement:
-		err = iql.ErrNotImplemented("REVOKE ALL")
+		err = e.executeRevokeAdminStatement(ctx, stmt, ectx)
 	case *influxql.ShowContinuousQueriesStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW CONTINUOUS QUERIES")
+		rows, err = e.executeShowContinuousQueriesStatement(ctx, stmt, ectx)
 	case *influxql.ShowDatabasesStatement:
 		rows, err = e.executeShowDatabasesStatement(ctx, stmt, ectx)
 	case *influxql.ShowDiagnosticsStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW DIAGNOSTICS")
+		rows, err = e.executeShowDiagnosticsStatement(ctx, stmt)
 	case *influxql.ShowGrantsForUserStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW GRANTS")
+		rows, err = e.executeShowGrantsForUserStatement(ctx, stmt, ectx)
 	case *influxql.ShowMeasurementsStatement:
 		return e.executeShowMeasurementsStatement(ctx, stmt, ectx)
 	case *influxql.ShowMeasurementCardinalityStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW MEASUREMENT CARDINALITY")
+		rows, err = e.executeShowMeasurementCardinalityStatement(ctx, stmt, ectx)
 	case *influxql.ShowRetentionPoliciesStatement:
 		rows, err = e.executeShowRetentionPoliciesStatement(ctx, stmt, ectx)
 	case *influxql.ShowSeriesCardinalityStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW SERIES CARDINALITY")
+		rows, err = e.executeShowSeriesCardinalityStatement(ctx, stmt, ectx)
 	case *influxql.ShowShardsStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW SHARDS")
+		rows, err = e.executeShowShardsStatement(ctx, stmt, ectx)
 	case *influxql.ShowShardGroupsStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW SHARD GROUPS")
+		rows, err = e.executeShowShardGroupsStatement(ctx, stmt, ectx)
 	case *influxql.ShowStatsStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW STATS")
+		rows, err = e.executeShowStatsStatement(ctx, stmt)
 	case *influxql.ShowSubscriptionsStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW SUBSCRIPTIONS")
+		rows, err = e.executeShowSubscriptionsStatement(ctx, stmt, ectx)
 	case *influxql.ShowTagKeysStatement:
 		return e.executeShowTagKeys(ctx, stmt, ectx)
 	case *influxql.ShowTagValuesStatement:
 		return e.executeShowTagValues(ctx, stmt, ectx)
 	case *influxql.ShowUsersStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW USERS")
+		rows, err = e.executeShowUsersStatement(ctx, stmt, ectx)
 	case *influxql.SetPasswordUserStatement:
-		err = iql.ErrNotImplemented("SET PASSWORD")
-	case *influxql.ShowQueriesStatement, *influxql.KillQueryStatement:
-		err = iql.ErrNotImplemented("SHOW QUERIES")
+		err = e.executeSetPasswordUserStatement(ctx, stmt, ectx)
+	case *influxql.ShowQueriesStatement:
+		rows, err = e.executeShowQueriesStatement(ctx, stmt, ectx)
+	case *influxql.KillQueryStatement:
+		err = e.executeKillQueryStatement(ctx, stmt, ectx)
 	default:
 		return query.ErrInvalidQuery
 	}
@@ -239,231 +285,1467 @@ CLEANUP:
 	return models.Rows{row}, nil
 }
 
-func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *influxql.SelectStatement, ectx *query.ExecutionContext) error {
-	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions, ectx.StatisticsGatherer)
+func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *influxql.SelectStatement, ectx *query.ExecutionContext) error {
+	// Resolve the bucket written by an INTO statement before reading any data.
+	var pointsWriter *BufferedPointsWriter
+	if stmt.Target != nil {
+		if e.PointsWriter == nil {
+			return iql.ErrNotImplemented("SELECT INTO")
+		}
+
+		mapping, err := e.findTargetMapping(ctx, stmt.Target, ectx)
+		if err != nil {
+			return err
+		}
+		pointsWriter = NewBufferedPointsWriter(e.PointsWriter, mapping.BucketID.String(), meta.DefaultRetentionPolicyName, 10000)
+	}
+
+	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions, ectx.StatisticsGatherer)
+	if err != nil {
+		return err
+	}
+
+	// Generate a row emitter from the iterator set.
+	em := query.NewEmitter(cur, ectx.ChunkSize)
+	defer em.Close()
+
+	// Emit rows to the results channel.
+	var writeN int64
+	var emitted bool
+
+	for {
+		row, partial, err := em.Emit()
+		if err != nil {
+			return err
+		} else if row == nil {
+			// Check if the query was interrupted while emitting.
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			break
+		}
+
+		// Write points back into system for INTO statements.
+		if stmt.Target != nil {
+			n, err := e.writeInto(pointsWriter, stmt, row)
+			if err != nil {
+				return err
+			}
+			writeN += n
+			continue
+		}
+
+		result := &query.Result{
+			Series:  []*models.Row{row},
+			Partial: partial,
+		}
+
+		// Send results or exit if closing.
+		if err := ectx.Send(ctx, result); err != nil {
+			return err
+		}
+
+		emitted = true
+	}
+
+	// Flush remaining points and emit write count if an INTO statement.
+	if stmt.Target != nil {
+		if err := pointsWriter.Flush(); err != nil {
+			return err
+		}
+
+		return ectx.Send(ctx, &query.Result{
+			Series: []*models.Row{{
+				Name:    "result",
+				Columns: []string{"time", "written"},
+				Values:  [][]interface{}{{time.Unix(0, 0).UTC(), writeN}},
+			}},
+		})
+	}
+
+	// Always emit at least one result.
+	if !emitted {
+		return ectx.Send(ctx, &query.Result{
+			Series: make([]*models.Row, 0),
+		})
+	}
+
+	return nil
+}
+
+// findTargetMapping returns the DBRP mapping of the bucket written by the
+// INTO clause of a SELECT statement, verifying it may be written to.
+func (e *StatementExecutor) findTargetMapping(ctx context.Context, target *influxql.Target, ectx *query.ExecutionContext) (*influxdb.DBRPMappingV2, error) {
+	if target.Measurement.Database == "" {
+		return nil, errNoDatabaseInTarget
+	}
+
+	mapping, err := e.findMapping(ctx, ectx.OrgID, target.Measurement.Database, target.Measurement.RetentionPolicy)
+	if err != nil {
+		return nil, err
+	} else if mapping == nil {
+		return nil, meta.ErrRetentionPolicyNotFound
+	}
+
+	perm, err := influxdb.NewPermissionAtID(mapping.BucketID, influxdb.WriteAction, influxdb.BucketsResourceType, mapping.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+func (e *StatementExecutor) writeInto(w *BufferedPointsWriter, stmt *influxql.SelectStatement, row *models.Row) (n int64, err error) {
+	// It might seem a bit weird that this is where we do this, since we will have to
+	// convert rows back to points. The Executors (both aggregate and raw) are complex
+	// enough that changing them to write back to the DB is going to be clumsy
+	//
+	// it might seem weird to have the write be in the Executor, but the interweaving of
+	// limitedRowWriter and ExecuteAggregate/Raw makes it ridiculously hard to make sure that the
+	// results will be the same as when queried normally.
+	name := stmt.Target.Measurement.Name
+	if name == "" {
+		name = row.Name
+	}
+
+	points, err := convertRowToPoints(name, row)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.WritePointsInto(&IntoWriteRequest{
+		Database:        w.database,
+		RetentionPolicy: w.retentionPolicy,
+		Points:          points,
+	}); err != nil {
+		return 0, err
+	}
+
+	return int64(len(points)), nil
+}
+
+var errNoDatabaseInTarget = errors.New("no database in target")
+
+// convertRowToPoints will convert a query result Row into Points that can be written back in.
+func convertRowToPoints(measurementName string, row *models.Row) ([]models.Point, error) {
+	// figure out which parts of the result are the time and which are the fields
+	timeIndex := -1
+	fieldIndexes := make(map[string]int)
+	for i, c := range row.Columns {
+		if c == "time" {
+			timeIndex = i
+		} else {
+			fieldIndexes[c] = i
+		}
+	}
+
+	if timeIndex == -1 {
+		return nil, errors.New("error finding time index in result")
+	}
+
+	points := make([]models.Point, 0, len(row.Values))
+	for _, v := range row.Values {
+		vals := make(map[string]interface{})
+		for fieldName, fieldIndex := range fieldIndexes {
+			val := v[fieldIndex]
+			// Check specifically for nil or a NullFloat. This is because
+			// the NullFloat represents float numbers that don't have an internal representation
+			// (like NaN) that cannot be written back, but will not equal nil so there will be
+			// an attempt to write them if we do not check for it.
+			if val != nil && val != query.NullFloat {
+				vals[fieldName] = v[fieldIndex]
+			}
+		}
+
+		p, err := models.NewPoint(measurementName, models.NewTags(row.Tags), vals, v[timeIndex].(time.Time))
+		if err != nil {
+			// Drop points that can't be stored
+			continue
+		}
+
+		points = append(points, p)
+	}
+
+	return points, nil
+}
+
+func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.SelectStatement, opt query.ExecutionOptions, gatherer *iql.StatisticsGatherer) (query.Cursor, error) {
+	defer func(start time.Time) {
+		dur := time.Since(start)
+		gatherer.Append(iql.NewImmutableCollector(iql.Statistics{PlanDuration: dur}))
+	}(time.Now())
+
+	sopt := query.SelectOptions{
+		OrgID:              opt.OrgID,
+		NodeID:             opt.NodeID,
+		MaxSeriesN:         e.MaxSelectSeriesN,
+		MaxPointN:          e.MaxSelectPointN,
+		MaxBucketsN:        e.MaxSelectBucketsN,
+		StatisticsGatherer: gatherer,
+	}
+
+	// Create a set of iterators from a selection.
+	cur, err := query.Select(ctx, stmt, e.ShardMapper, sopt)
+	if err != nil {
+		return nil, err
+	}
+	return cur, nil
+}
+
+func (e *StatementExecutor) executeShowDatabasesStatement(ctx context.Context, q *influxql.ShowDatabasesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	row := &models.Row{Name: "databases", Columns: []string{"name"}}
+	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seenDbs := make(map[string]struct{}, len(dbrps))
+	for _, dbrp := range dbrps {
+		if _, ok := seenDbs[dbrp.Database]; ok {
+			continue
+		}
+
+		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, dbrp.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		err = authorizer.IsAllowed(ctx, *perm)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+				continue
+			}
+			return nil, err
+		}
+		seenDbs[dbrp.Database] = struct{}{}
+		row.Values = append(row.Values, []interface{}{dbrp.Database})
+	}
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeCreateDatabaseStatement(ctx context.Context, stmt *influxql.CreateDatabaseStatement, ectx *query.ExecutionContext) error {
+	if stmt.Name == "" {
+		return ErrDatabaseNameRequired
+	}
+
+	// Without a retention policy clause, creating a database that already
+	// exists is a no-op, whichever retention policies it has.
+	if !stmt.RetentionPolicyCreate {
+		_, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+			OrgID:    &ectx.OrgID,
+			Database: &stmt.Name,
+		})
+		if err != nil {
+			return err
+		} else if n > 0 {
+			return nil
+		}
+	}
+
+	rpName := stmt.RetentionPolicyName
+	if rpName == "" {
+		rpName = meta.DefaultRetentionPolicyName
+	}
+
+	var duration time.Duration
+	if stmt.RetentionPolicyDuration != nil {
+		duration = *stmt.RetentionPolicyDuration
+	}
+
+	mapping, err := e.findMapping(ctx, ectx.OrgID, stmt.Name, rpName)
+	if err != nil {
+		return err
+	} else if mapping != nil {
+		// CREATE DATABASE is idempotent, as long as the requested retention
+		// policy matches the existing one.
+		b, err := e.BucketService.FindBucketByID(ctx, mapping.BucketID)
+		if err != nil {
+			return err
+		}
+		if b.RetentionPeriod != duration {
+			return meta.ErrRetentionPolicyConflict
+		}
+		return nil
+	}
+
+	return e.createRetentionPolicy(ctx, ectx.OrgID, stmt.Name, rpName, duration, true)
+}
+
+func (e *StatementExecutor) executeDropDatabaseStatement(ctx context.Context, stmt *influxql.DropDatabaseStatement, ectx *query.ExecutionContext) error {
+	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &stmt.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Dropping a database that does not exist is not an error.
+	for _, dbrp := range dbrps {
+		if err := e.dropRetentionPolicy(ctx, dbrp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeCreateRetentionPolicyStatement(ctx context.Context, stmt *influxql.CreateRetentionPolicyStatement, ectx *query.ExecutionContext) error {
+	mapping, err := e.findMapping(ctx, ectx.OrgID, stmt.Database, stmt.Name)
+	if err != nil {
+		return err
+	} else if mapping != nil {
+		b, err := e.BucketService.FindBucketByID(ctx, mapping.BucketID)
+		if err != nil {
+			return err
+		}
+		if b.RetentionPeriod != stmt.Duration || (stmt.Default && !mapping.Default) {
+			return meta.ErrRetentionPolicyConflict
+		}
+		return nil
+	}
+
+	return e.createRetentionPolicy(ctx, ectx.OrgID, stmt.Database, stmt.Name, stmt.Duration, stmt.Default)
+}
+
+func (e *StatementExecutor) executeAlterRetentionPolicyStatement(ctx context.Context, stmt *influxql.AlterRetentionPolicyStatement, ectx *query.ExecutionContext) error {
+	mapping, err := e.findMapping(ctx, ectx.OrgID, stmt.Database, stmt.Name)
+	if err != nil {
+		return err
+	} else if mapping == nil {
+		return meta.ErrRetentionPolicyNotFound
+	}
+
+	// Buckets have no shard group duration or replication factor to update.
+	if stmt.ShardGroupDuration != nil {
+		return errors.New("changing the shard duration of a retention policy is not supported")
+	} else if stmt.Replication != nil {
+		return errors.New("changing the replication factor of a retention policy is not supported")
+	}
+
+	if stmt.Duration != nil {
+		if err := validateRetentionPolicyDuration(*stmt.Duration); err != nil {
+			return err
+		}
+		if _, err := e.BucketService.UpdateBucket(ctx, mapping.BucketID, influxdb.BucketUpdate{
+			RetentionPeriod: stmt.Duration,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if stmt.Default && !mapping.Default {
+		mapping.Default = true
+		if err := e.DBRP.Update(ctx, mapping); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeDropRetentionPolicyStatement(ctx context.Context, stmt *influxql.DropRetentionPolicyStatement, ectx *query.ExecutionContext) error {
+	mapping, err := e.findMapping(ctx, ectx.OrgID, stmt.Database, stmt.Name)
+	if err != nil {
+		return err
+	} else if mapping == nil {
+		// Dropping a retention policy that does not exist is not an error.
+		return nil
+	}
+	return e.dropRetentionPolicy(ctx, mapping)
+}
+
+// findMapping returns the DBRP mapping for the database and retention policy,
+// or nil if no such mapping exists.
+func (e *StatementExecutor) findMapping(ctx context.Context, orgID influxdb.ID, database, rp string) (*influxdb.DBRPMappingV2, error) {
+	if database == "" {
+		return nil, ErrDatabaseNameRequired
+	} else if rp == "" {
+		return nil, meta.ErrRetentionPolicyNameRequired
+	}
+
+	dbrps, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:           &orgID,
+		Database:        &database,
+		RetentionPolicy: &rp,
+	})
+	if err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, nil
+	}
+	return dbrps[0], nil
+}
+
+// createRetentionPolicy creates a bucket named "database/rp" within the
+// organization and maps the database and retention policy to it.
+func (e *StatementExecutor) createRetentionPolicy(ctx context.Context, orgID influxdb.ID, database, rp string, duration time.Duration, isDefault bool) error {
+	if err := validateRetentionPolicyDuration(duration); err != nil {
+		return err
+	}
+
+	b := &influxdb.Bucket{
+		OrgID:               orgID,
+		Type:                influxdb.BucketTypeUser,
+		Name:                database + "/" + rp,
+		Description:         fmt.Sprintf("Created by InfluxQL for database %s with retention policy %s", database, rp),
+		RetentionPolicyName: rp,
+		RetentionPeriod:     duration,
+	}
+	if err := e.BucketService.CreateBucket(ctx, b); err != nil {
+		return err
+	}
+
+	mapping := &influxdb.DBRPMappingV2{
+		Database:        database,
+		RetentionPolicy: rp,
+		Default:         isDefault,
+		OrganizationID:  orgID,
+		BucketID:        b.ID,
+	}
+	if err := e.DBRP.Create(ctx, mapping); err != nil {
+		// Do not leave a bucket behind that cannot be reached via InfluxQL.
+		if derr := e.BucketService.DeleteBucket(ctx, b.ID); derr != nil {
+			return fmt.Errorf("creating DBRP mapping: %v; removing bucket %s: %v", err, b.ID, derr)
+		}
+		return err
+	}
+	return nil
+}
+
+// dropRetentionPolicy removes the DBRP mapping and the bucket it refers to.
+func (e *StatementExecutor) dropRetentionPolicy(ctx context.Context, mapping *influxdb.DBRPMappingV2) error {
+	if err := e.DBRP.Delete(ctx, mapping.OrganizationID, mapping.ID); err != nil {
+		return err
+	}
+	if err := e.BucketService.DeleteBucket(ctx, mapping.BucketID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	return nil
+}
+
+// validateRetentionPolicyDuration ensures d is either infinite or at least
+// the minimum retention policy duration.
+func validateRetentionPolicyDuration(d time.Duration) error {
+	if d != 0 && d < meta.MinRetentionPolicyDuration {
+		return meta.ErrRetentionPolicyDurationTooLow
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeCreateContinuousQueryStatement(ctx context.Context, stmt *influxql.CreateContinuousQueryStatement, ectx *query.ExecutionContext) error {
+	if _, err := e.getDefaultRP(ctx, stmt.Database, ectx); err != nil {
+		return err
+	}
+
+	// Verify the source and target can be resolved, as the task will not
+	// be able to report such errors until it first runs.
+	if err := e.validateContinuousQueryTarget(ctx, stmt, ectx); err != nil {
+		return err
+	}
+
+	cqs, err := e.findContinuousQueries(ctx, ectx.OrgID)
+	if err != nil {
+		return err
+	}
+	for _, t := range cqs {
+		if continuous_querier.Database(t) != stmt.Database || t.Name != stmt.Name {
+			continue
+		}
+		// Creating an identical continuous query is not an error.
+		if cq, err := continuous_querier.Statement(t); err == nil && cq.String() == stmt.String() {
+			return nil
+		}
+		return meta.ErrContinuousQueryExists
+	}
+
+	ownerID, err := icontext.GetUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	tc, err := continuous_querier.NewTaskCreate(ectx.OrgID, ownerID, stmt)
+	if err != nil {
+		return err
+	}
+	_, err = e.TaskService.CreateTask(ctx, tc)
+	return err
+}
+
+// validateContinuousQueryTarget ensures the databases read and written by
+// the continuous query are mapped to buckets.
+func (e *StatementExecutor) validateContinuousQueryTarget(ctx context.Context, stmt *influxql.CreateContinuousQueryStatement, ectx *query.ExecutionContext) error {
+	if stmt.Source.Target == nil || stmt.Source.Target.Measurement == nil {
+		return errors.New("continuous query must contain an INTO clause")
+	}
+
+	target := stmt.Source.Target.Measurement
+	if target.Database == "" {
+		return nil
+	}
+	if target.RetentionPolicy == "" {
+		_, err := e.getDefaultRP(ctx, target.Database, ectx)
+		return err
+	}
+
+	mapping, err := e.findMapping(ctx, ectx.OrgID, target.Database, target.RetentionPolicy)
+	if err != nil {
+		return err
+	} else if mapping == nil {
+		return meta.ErrRetentionPolicyNotFound
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeDropContinuousQueryStatement(ctx context.Context, stmt *influxql.DropContinuousQueryStatement, ectx *query.ExecutionContext) error {
+	cqs, err := e.findContinuousQueries(ctx, ectx.OrgID)
+	if err != nil {
+		return err
+	}
+	for _, t := range cqs {
+		if continuous_querier.Database(t) == stmt.Database && t.Name == stmt.Name {
+			return e.TaskService.DeleteTask(ctx, t.ID)
+		}
+	}
+	return meta.ErrContinuousQueryNotFound
+}
+
+func (e *StatementExecutor) executeShowContinuousQueriesStatement(ctx context.Context, stmt *influxql.ShowContinuousQueriesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	cqs, err := e.findContinuousQueries(ctx, ectx.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group continuous queries by database, in the order databases are listed.
+	var rows []*models.Row
+	byDatabase := make(map[string]*models.Row)
+	for _, t := range cqs {
+		db := continuous_querier.Database(t)
+		row, ok := byDatabase[db]
+		if !ok {
+			row = &models.Row{Name: db, Columns: []string{"name", "query"}}
+			byDatabase[db] = row
+			rows = append(rows, row)
+		}
+
+		q, _ := t.Metadata[continuous_querier.MetadataQuery].(string)
+		row.Values = append(row.Values, []interface{}{t.Name, q})
+	}
+	return rows, nil
+}
+
+// findContinuousQueries returns all tasks running continuous queries within
+// the organization.
+func (e *StatementExecutor) findContinuousQueries(ctx context.Context, orgID influxdb.ID) ([]*influxdb.Task, error) {
+	taskType := continuous_querier.TaskType
+	filter := influxdb.TaskFilter{
+		Type:           &taskType,
+		OrganizationID: &orgID,
+		Limit:          influxdb.TaskMaxPageSize,
+	}
+
+	var cqs []*influxdb.Task
+	for {
+		tasks, _, err := e.TaskService.FindTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tasks {
+			if continuous_querier.IsContinuousQuery(t) {
+				cqs = append(cqs, t)
+			}
+		}
+		if len(tasks) < filter.Limit {
+			return cqs, nil
+		}
+		filter.After = &tasks[len(tasks)-1].ID
+	}
+}
+
+func (e *StatementExecutor) getDefaultRP(ctx context.Context, database string, ectx *query.ExecutionContext) (*influxdb.DBRPMappingV2, error) {
+	defaultRP := true
+	mappings, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &database,
+		Default:  &defaultRP,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("finding DBRP mappings: %v", err)
+	} else if n == 0 {
+		return nil, fmt.Errorf("default retention policy not set for: %s", database)
+	} else if n != 1 {
+		return nil, fmt.Errorf("finding DBRP mappings: expected 1, found %d", n)
+	}
+	return mappings[0], nil
+}
+
+func (e *StatementExecutor) executeDeleteSeriesStatement(ctx context.Context, q *influxql.DeleteSeriesStatement, database string, ectx *query.ExecutionContext) error {
+	mapping, err := e.getDefaultRP(ctx, database, ectx)
+	if err != nil {
+		return err
+	}
+
+	// Convert "now()" to current time.
+	q.Condition = influxql.Reduce(q.Condition, &influxql.NowValuer{Now: time.Now().UTC()})
+
+	return e.TSDBStore.DeleteSeries(mapping.BucketID.String(), q.Sources, q.Condition)
+}
+
+func (e *StatementExecutor) executeDropSeriesStatement(ctx context.Context, q *influxql.DropSeriesStatement, database string, ectx *query.ExecutionContext) error {
+	if database == "" {
+		return ErrDatabaseNameRequired
+	}
+
+	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &database,
+	})
+	if err != nil {
+		return err
+	} else if len(dbrps) == 0 {
+		return query.ErrDatabaseNotFound(database)
+	}
+
+	// Series are dropped from every retention policy of the database, so write
+	// access to all of their buckets is required before anything is deleted.
+	for _, dbrp := range dbrps {
+		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.WriteAction, influxdb.BucketsResourceType, ectx.OrgID)
+		if err != nil {
+			return err
+		}
+		if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+			return err
+		}
+	}
+
+	// Convert "now()" to current time.
+	q.Condition = influxql.Reduce(q.Condition, &influxql.NowValuer{Now: time.Now().UTC()})
+
+	for _, dbrp := range dbrps {
+		if err := e.TSDBStore.DeleteSeries(dbrp.BucketID.String(), q.Sources, q.Condition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeDropMeasurementStatement(ctx context.Context, q *influxql.DropMeasurementStatement, database string, ectx *query.ExecutionContext) error {
+	mapping, err := e.getDefaultRP(ctx, database, ectx)
+	if err != nil {
+		return err
+	}
+	return e.TSDBStore.DeleteMeasurement(mapping.BucketID.String(), q.Name)
+}
+
+func (e *StatementExecutor) executeShowMeasurementsStatement(ctx context.Context, q *influxql.ShowMeasurementsStatement, ectx *query.ExecutionContext) error {
+	if q.Database == "" {
+		return ErrDatabaseNameRequired
+	}
+
+	mapping, err := e.getDefaultRP(ctx, q.Database, ectx)
+	if err != nil {
+		return err
+	}
+
+	names, err := e.TSDBStore.MeasurementNames(ectx.Authorizer, mapping.BucketID.String(), q.Condition)
+	if err != nil || len(names) == 0 {
+		return ectx.Send(ctx, &query.Result{
+			Err: err,
+		})
+	}
+
+	if q.Offset > 0 {
+		if q.Offset >= len(names) {
+			names = nil
+		} else {
+			names = names[q.Offset:]
+		}
+	}
+
+	if q.Limit > 0 {
+		if q.Limit < len(names) {
+			names = names[:q.Limit]
+		}
+	}
+
+	values := make([][]interface{}, len(names))
+	for i, name := range names {
+		values[i] = []interface{}{string(name)}
+	}
+
+	if len(values) == 0 {
+		return ectx.Send(ctx, &query.Result{})
+	}
+
+	return ectx.Send(ctx, &query.Result{
+		Series: []*models.Row{{
+			Name:    "measurements",
+			Columns: []string{"name"},
+			Values:  values,
+		}},
+	})
+}
+
+func (e *StatementExecutor) executeShowRetentionPoliciesStatement(ctx context.Context, q *influxql.ShowRetentionPoliciesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if q.Database == "" {
+		return nil, ErrDatabaseNameRequired
+	}
+
+	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &q.Database,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	row := &models.Row{Columns: []string{"name", "duration", "shardGroupDuration", "replicaN", "default"}}
+	for _, dbrp := range dbrps {
+		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, dbrp.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		err = authorizer.IsAllowed(ctx, *perm)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+				continue
+			}
+			return nil, err
+		}
+		var retention time.Duration
+		if e.BucketService != nil {
+			b, err := e.BucketService.FindBucketByID(ctx, dbrp.BucketID)
+			if err != nil {
+				return nil, err
+			}
+			retention = b.RetentionPeriod
+		}
+		// The storage engine derives the shard group duration from the
+		// retention period of the bucket.
+		row.Values = append(row.Values, []interface{}{dbrp.RetentionPolicy, retention.String(), meta.ShardGroupDuration(retention).String(), 1, dbrp.Default})
+	}
+
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeShowShardsStatement(ctx context.Context, stmt *influxql.ShowShardsStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	dbrps, err := e.findReadableMappings(ctx, influxdb.DBRPMappingFilterV2{OrgID: &ectx.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []*models.Row{}
+	byDatabase := make(map[string]*models.Row)
+	for _, dbrp := range dbrps {
+		row, ok := byDatabase[dbrp.Database]
+		if !ok {
+			row = &models.Row{Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "owners", "size"}, Name: dbrp.Database}
+			byDatabase[dbrp.Database] = row
+			rows = append(rows, row)
+		}
+
+		sgs, err := e.ShardService.FindShardGroups(ctx, influxdb.ShardGroupFilter{BucketID: &dbrp.BucketID})
+		if err != nil {
+			return nil, err
+		}
+		for _, sg := range sgs {
+			for _, sh := range sg.Shards {
+				row.Values = append(row.Values, []interface{}{
+					sh.ID,
+					dbrp.Database,
+					dbrp.RetentionPolicy,
+					sg.ID,
+					sg.StartTime.UTC().Format(time.RFC3339),
+					sg.EndTime.UTC().Format(time.RFC3339),
+					sg.ExpiryTime.UTC().Format(time.RFC3339),
+					joinUint64(sh.Owners),
+					sh.Size,
+				})
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (e *StatementExecutor) executeShowShardGroupsStatement(ctx context.Context, stmt *influxql.ShowShardGroupsStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	dbrps, err := e.findReadableMappings(ctx, influxdb.DBRPMappingFilterV2{OrgID: &ectx.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	row := &models.Row{Columns: []string{"id", "database", "retention_policy", "start_time", "end_time", "expiry_time"}, Name: "shard groups"}
+	for _, dbrp := range dbrps {
+		sgs, err := e.ShardService.FindShardGroups(ctx, influxdb.ShardGroupFilter{BucketID: &dbrp.BucketID})
+		if err != nil {
+			return nil, err
+		}
+		for _, sg := range sgs {
+			row.Values = append(row.Values, []interface{}{
+				sg.ID,
+				dbrp.Database,
+				dbrp.RetentionPolicy,
+				sg.StartTime.UTC().Format(time.RFC3339),
+				sg.EndTime.UTC().Format(time.RFC3339),
+				sg.ExpiryTime.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeDropShardStatement(ctx context.Context, stmt *influxql.DropShardStatement, ectx *query.ExecutionContext) error {
+	sgs, err := e.ShardService.FindShardGroups(ctx, influxdb.ShardGroupFilter{ShardID: &stmt.ID})
+	if err != nil {
+		return err
+	} else if len(sgs) == 0 {
+		// Dropping a shard that does not exist is not an error.
+		return nil
+	}
+
+	// The shard may only be dropped if its bucket belongs to the organization.
+	bucketID := sgs[0].BucketID
+	_, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		BucketID: &bucketID,
+	})
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return nil
+	}
+
+	perm, err := influxdb.NewPermissionAtID(bucketID, influxdb.WriteAction, influxdb.BucketsResourceType, ectx.OrgID)
+	if err != nil {
+		return err
+	}
+	if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+		return err
+	}
+	return e.ShardService.DeleteShard(ctx, stmt.ID)
+}
+
+func (e *StatementExecutor) executeShowDiagnosticsStatement(ctx context.Context, stmt *influxql.ShowDiagnosticsStatement) (models.Rows, error) {
+	if e.Monitor == nil {
+		return nil, iql.ErrNotImplemented("SHOW DIAGNOSTICS")
+	}
+	// The diagnostics describe the whole server rather than an organization.
+	if err := authorizer.IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+
+	diags, err := e.Monitor.Diagnostics()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get a sorted list of diagnostics keys.
+	sortedKeys := make([]string, 0, len(diags))
+	for k := range diags {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+
+	rows := make([]*models.Row, 0, len(diags))
+	for _, k := range sortedKeys {
+		if stmt.Module != "" && k != stmt.Module {
+			continue
+		}
+
+		row := &models.Row{Name: k}
+
+		row.Columns = diags[k].Columns
+		row.Values = diags[k].Rows
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (e *StatementExecutor) executeShowStatsStatement(ctx context.Context, stmt *influxql.ShowStatsStatement) (models.Rows, error) {
+	if e.Monitor == nil {
+		return nil, iql.ErrNotImplemented("SHOW STATS")
+	}
+	// The statistics describe the whole server rather than an organization.
+	if err := authorizer.IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+
+	stats, err := e.Monitor.Statistics(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*models.Row
+	for _, stat := range stats {
+		if stmt.Module != "" && stat.Name != stmt.Module {
+			continue
+		}
+		row := &models.Row{Name: stat.Name, Tags: stat.Tags}
+
+		values := make([]interface{}, 0, len(stat.Values))
+		for _, k := range stat.ValueNames() {
+			row.Columns = append(row.Columns, k)
+			values = append(values, stat.Values[k])
+		}
+		row.Values = [][]interface{}{values}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (e *StatementExecutor) executeShowQueriesStatement(ctx context.Context, stmt *influxql.ShowQueriesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.RunningQueryService == nil {
+		return nil, iql.ErrNotImplemented("SHOW QUERIES")
+	}
+
+	qs, err := e.RunningQueryService.FindRunningQueries(ctx, influxdb.RunningQueryFilter{OrgID: &ectx.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	row := &models.Row{Columns: []string{"qid", "query", "database", "duration", "status", "language", "user_id", "token_id"}}
+	for _, q := range qs {
+		row.Values = append(row.Values, []interface{}{
+			q.ID,
+			q.Query,
+			q.Database,
+			formatQueryDuration(q.Duration),
+			q.State,
+			q.Language,
+			formatOptionalID(q.UserID),
+			formatOptionalID(q.AuthorizationID),
+		})
+	}
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeKillQueryStatement(ctx context.Context, stmt *influxql.KillQueryStatement, ectx *query.ExecutionContext) error {
+	if e.RunningQueryService == nil {
+		return iql.ErrNotImplemented("KILL QUERY")
+	}
+	if stmt.Host != "" {
+		return iql.ErrNotImplemented("KILL QUERY ON")
+	}
+
+	// Queries of other organizations are reported as missing.
+	qs, err := e.RunningQueryService.FindRunningQueries(ctx, influxdb.RunningQueryFilter{
+		ID:    &stmt.QueryID,
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return err
+	} else if len(qs) == 0 {
+		return fmt.Errorf("no such query id: %d", stmt.QueryID)
+	}
+	return e.RunningQueryService.KillQuery(ctx, stmt.QueryID)
+}
+
+// formatQueryDuration truncates d to the precision of its largest unit.
+func formatQueryDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		d = d - (d % time.Second)
+	case d >= time.Millisecond:
+		d = d - (d % time.Millisecond)
+	case d >= time.Microsecond:
+		d = d - (d % time.Microsecond)
+	}
+	return d.String()
+}
+
+// formatOptionalID returns the string form of id, or an empty string if id is not set.
+func formatOptionalID(id influxdb.ID) string {
+	if !id.Valid() {
+		return ""
+	}
+	return id.String()
+}
+
+func (e *StatementExecutor) executeCreateSubscriptionStatement(ctx context.Context, stmt *influxql.CreateSubscriptionStatement, ectx *query.ExecutionContext) error {
+	if e.SubscriptionService == nil {
+		return iql.ErrNotImplemented("CREATE SUBSCRIPTION")
+	}
+
+	mapping, err := e.findMapping(ctx, ectx.OrgID, stmt.Database, stmt.RetentionPolicy)
+	if err != nil {
+		return err
+	} else if mapping == nil {
+		return meta.ErrRetentionPolicyNotFound
+	}
+
+	// Destinations written to over HTTP expect the names of the 1.x database
+	// and retention policy rather than the bucket ID.
+	return e.SubscriptionService.CreateSubscription(ctx, &influxdb.Subscription{
+		OrgID:           mapping.OrganizationID,
+		BucketID:        mapping.BucketID,
+		Name:            stmt.Name,
+		Mode:            stmt.Mode,
+		Destinations:    stmt.Destinations,
+		Database:        stmt.Database,
+		RetentionPolicy: stmt.RetentionPolicy,
+	})
+}
+
+func (e *StatementExecutor) executeDropSubscriptionStatement(ctx context.Context, stmt *influxql.DropSubscriptionStatement, ectx *query.ExecutionContext) error {
+	if e.SubscriptionService == nil {
+		return iql.ErrNotImplemented("DROP SUBSCRIPTION")
+	}
+
+	mapping, err := e.findMapping(ctx, ectx.OrgID, stmt.Database, stmt.RetentionPolicy)
+	if err != nil {
+		return err
+	} else if mapping == nil {
+		return meta.ErrSubscriptionNotFound
+	}
+
+	subs, _, err := e.SubscriptionService.FindSubscriptions(ctx, influxdb.SubscriptionFilter{
+		BucketID: &mapping.BucketID,
+		Name:     &stmt.Name,
+	})
+	if err != nil {
+		return err
+	} else if len(subs) == 0 {
+		return meta.ErrSubscriptionNotFound
+	}
+	return e.SubscriptionService.DeleteSubscription(ctx, subs[0].ID)
+}
+
+// executeShowSubscriptionsStatement lists the subscriptions of the readable
+// buckets of the organization with one row per database.
+func (e *StatementExecutor) executeShowSubscriptionsStatement(ctx context.Context, stmt *influxql.ShowSubscriptionsStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.SubscriptionService == nil {
+		return nil, iql.ErrNotImplemented("SHOW SUBSCRIPTIONS")
+	}
+
+	dbrps, err := e.findReadableMappings(ctx, influxdb.DBRPMappingFilterV2{OrgID: &ectx.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []*models.Row{}
+	byDatabase := make(map[string]*models.Row)
+	for _, dbrp := range dbrps {
+		subs, _, err := e.SubscriptionService.FindSubscriptions(ctx, influxdb.SubscriptionFilter{BucketID: &dbrp.BucketID})
+		if err != nil {
+			return nil, err
+		} else if len(subs) == 0 {
+			continue
+		}
+
+		row, ok := byDatabase[dbrp.Database]
+		if !ok {
+			row = &models.Row{Columns: []string{"retention_policy", "name", "mode", "destinations"}, Name: dbrp.Database}
+			byDatabase[dbrp.Database] = row
+			rows = append(rows, row)
+		}
+		for _, sub := range subs {
+			row.Values = append(row.Values, []interface{}{dbrp.RetentionPolicy, sub.Name, sub.Mode, sub.Destinations})
+		}
+	}
+	return rows, nil
+}
+
+func (e *StatementExecutor) executeCreateUserStatement(ctx context.Context, stmt *influxql.CreateUserStatement, ectx *query.ExecutionContext) error {
+	if e.AuthorizationService == nil {
+		return iql.ErrNotImplemented("CREATE USER")
+	}
+
+	if _, err := e.findUser(ctx, ectx.OrgID, stmt.Name); err == nil {
+		return meta.ErrUserExists
+	} else if err != meta.ErrUserNotFound {
+		return err
+	}
+
+	// The new user is owned by the user creating it.
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+
+	auth := &influxdb.Authorization{
+		OrgID:  ectx.OrgID,
+		UserID: a.GetUserID(),
+		Token:  stmt.Name,
+		Status: influxdb.Active,
+	}
+	if stmt.Admin {
+		auth.Permissions = adminPermissions(ectx.OrgID)
+	}
+	if err := e.AuthorizationService.CreateAuthorization(ctx, auth); err != nil {
+		if influxdb.ErrorCode(err) == influxdb.EConflict {
+			return meta.ErrUserExists
+		}
+		return err
+	}
+
+	// A user without a valid password cannot authenticate, so it is removed again.
+	if err := e.PasswordService.SetPassword(ctx, auth.ID, stmt.Password); err != nil {
+		if derr := e.AuthorizationService.DeleteAuthorization(ctx, auth.ID); derr != nil {
+			return derr
+		}
+		return err
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeDropUserStatement(ctx context.Context, stmt *influxql.DropUserStatement, ectx *query.ExecutionContext) error {
+	if e.AuthorizationService == nil {
+		return iql.ErrNotImplemented("DROP USER")
+	}
+
+	auth, err := e.findUser(ctx, ectx.OrgID, stmt.Name)
+	if err != nil {
+		return err
+	}
+	return e.AuthorizationService.DeleteAuthorization(ctx, auth.ID)
+}
+
+func (e *StatementExecutor) executeSetPasswordUserStatement(ctx context.Context, stmt *influxql.SetPasswordUserStatement, ectx *query.ExecutionContext) error {
+	if e.AuthorizationService == nil {
+		return iql.ErrNotImplemented("SET PASSWORD")
+	}
+
+	auth, err := e.findUser(ctx, ectx.OrgID, stmt.Name)
 	if err != nil {
 		return err
 	}
+	return e.PasswordService.SetPassword(ctx, auth.ID, stmt.Password)
+}
 
-	// Generate a row emitter from the iterator set.
-	em := query.NewEmitter(cur, ectx.ChunkSize)
-	defer em.Close()
-
-	// Emit rows to the results channel.
-	var emitted bool
+func (e *StatementExecutor) executeGrantStatement(ctx context.Context, stmt *influxql.GrantStatement, ectx *query.ExecutionContext) error {
+	if e.AuthorizationService == nil {
+		return iql.ErrNotImplemented("GRANT")
+	}
 
-	if stmt.Target != nil {
-		// SELECT INTO is unsupported
-		return iql.ErrNotImplemented("SELECT INTO")
+	auth, err := e.findUser(ctx, ectx.OrgID, stmt.User)
+	if err != nil {
+		return err
+	}
+	ps, err := e.databasePermissions(ctx, ectx.OrgID, stmt.On, stmt.Privilege)
+	if err != nil {
+		return err
 	}
+	return e.PermissionService.UpdatePermissions(ctx, auth.ID, addPermissions(auth.Permissions, ps))
+}
 
-	for {
-		row, partial, err := em.Emit()
-		if err != nil {
-			return err
-		} else if row == nil {
-			// Check if the query was interrupted while emitting.
-			if err := ctx.Err(); err != nil {
-				return err
-			}
-			break
-		}
+func (e *StatementExecutor) executeGrantAdminStatement(ctx context.Context, stmt *influxql.GrantAdminStatement, ectx *query.ExecutionContext) error {
+	if e.AuthorizationService == nil {
+		return iql.ErrNotImplemented("GRANT ALL")
+	}
 
-		result := &query.Result{
-			Series:  []*models.Row{row},
-			Partial: partial,
-		}
+	auth, err := e.findUser(ctx, ectx.OrgID, stmt.User)
+	if err != nil {
+		return err
+	}
+	return e.PermissionService.UpdatePermissions(ctx, auth.ID, addPermissions(auth.Permissions, adminPermissions(ectx.OrgID)))
+}
 
-		// Send results or exit if closing.
-		if err := ectx.Send(ctx, result); err != nil {
-			return err
-		}
+func (e *StatementExecutor) executeRevokeStatement(ctx context.Context, stmt *influxql.RevokeStatement, ectx *query.ExecutionContext) error {
+	if e.AuthorizationService == nil {
+		return iql.ErrNotImplemented("REVOKE")
+	}
 
-		emitted = true
+	auth, err := e.findUser(ctx, ectx.OrgID, stmt.User)
+	if err != nil {
+		return err
+	}
+	ps, err := e.databasePermissions(ctx, ectx.OrgID, stmt.On, stmt.Privilege)
+	if err != nil {
+		return err
 	}
+	return e.PermissionService.UpdatePermissions(ctx, auth.ID, removePermissions(auth.Permissions, ps))
+}
 
-	// Always emit at least one result.
-	if !emitted {
-		return ectx.Send(ctx, &query.Result{
-			Series: make([]*models.Row, 0),
-		})
+func (e *StatementExecutor) executeRevokeAdminStatement(ctx context.Context, stmt *influxql.RevokeAdminStatement, ectx *query.ExecutionContext) error {
+	if e.AuthorizationService == nil {
+		return iql.ErrNotImplemented("REVOKE ALL")
 	}
 
-	return nil
+	auth, err := e.findUser(ctx, ectx.OrgID, stmt.User)
+	if err != nil {
+		return err
+	}
+	return e.PermissionService.UpdatePermissions(ctx, auth.ID, removePermissions(auth.Permissions, adminPermissions(ectx.OrgID)))
 }
 
-func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.SelectStatement, opt query.ExecutionOptions, gatherer *iql.StatisticsGatherer) (query.Cursor, error) {
-	defer func(start time.Time) {
-		dur := time.Since(start)
-		gatherer.Append(iql.NewImmutableCollector(iql.Statistics{PlanDuration: dur}))
-	}(time.Now())
-
-	sopt := query.SelectOptions{
-		OrgID:              opt.OrgID,
-		NodeID:             opt.NodeID,
-		MaxSeriesN:         e.MaxSelectSeriesN,
-		MaxPointN:          e.MaxSelectPointN,
-		MaxBucketsN:        e.MaxSelectBucketsN,
-		StatisticsGatherer: gatherer,
+func (e *StatementExecutor) executeShowUsersStatement(ctx context.Context, stmt *influxql.ShowUsersStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.AuthorizationService == nil {
+		return nil, iql.ErrNotImplemented("SHOW USERS")
 	}
 
-	// Create a set of iterators from a selection.
-	cur, err := query.Select(ctx, stmt, e.ShardMapper, sopt)
+	auths, _, err := e.AuthorizationService.FindAuthorizations(ctx, influxdb.AuthorizationFilter{OrgID: &ectx.OrgID})
 	if err != nil {
 		return nil, err
 	}
-	return cur, nil
+	sort.Slice(auths, func(i, j int) bool {
+		return auths[i].Token < auths[j].Token
+	})
+
+	row := &models.Row{Columns: []string{"user", "admin"}}
+	for _, a := range auths {
+		row.Values = append(row.Values, []interface{}{a.Token, isAdmin(a)})
+	}
+	return []*models.Row{row}, nil
 }
 
-func (e *StatementExecutor) executeShowDatabasesStatement(ctx context.Context, q *influxql.ShowDatabasesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
-	row := &models.Row{Name: "databases", Columns: []string{"name"}}
-	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
-		OrgID: &ectx.OrgID,
-	})
+func (e *StatementExecutor) executeShowGrantsForUserStatement(ctx context.Context, stmt *influxql.ShowGrantsForUserStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.AuthorizationService == nil {
+		return nil, iql.ErrNotImplemented("SHOW GRANTS")
+	}
+
+	auth, err := e.findUser(ctx, ectx.OrgID, stmt.Name)
 	if err != nil {
 		return nil, err
 	}
 
-	seenDbs := make(map[string]struct{}, len(dbrps))
-	for _, dbrp := range dbrps {
-		if _, ok := seenDbs[dbrp.Database]; ok {
+	// Combine the privileges on all of the buckets a database is mapped to.
+	privileges := make(map[string]influxql.Privilege)
+	for _, p := range auth.Permissions {
+		if p.Resource.Type != influxdb.BucketsResourceType || p.Resource.ID == nil {
 			continue
 		}
 
-		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, dbrp.OrganizationID)
+		dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+			OrgID:    &ectx.OrgID,
+			BucketID: p.Resource.ID,
+		})
 		if err != nil {
 			return nil, err
 		}
-		err = authorizer.IsAllowed(ctx, *perm)
-		if err != nil {
-			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
-				continue
+		for _, dbrp := range dbrps {
+			if p.Action == influxdb.ReadAction {
+				privileges[dbrp.Database] |= influxql.ReadPrivilege
+			} else {
+				privileges[dbrp.Database] |= influxql.WritePrivilege
 			}
-			return nil, err
 		}
-		seenDbs[dbrp.Database] = struct{}{}
-		row.Values = append(row.Values, []interface{}{dbrp.Database})
+	}
+
+	databases := make([]string, 0, len(privileges))
+	for db := range privileges {
+		databases = append(databases, db)
+	}
+	sort.Strings(databases)
+
+	row := &models.Row{Columns: []string{"database", "privilege"}}
+	for _, db := range databases {
+		row.Values = append(row.Values, []interface{}{db, privileges[db].String()})
 	}
 	return []*models.Row{row}, nil
 }
 
-func (e *StatementExecutor) getDefaultRP(ctx context.Context, database string, ectx *query.ExecutionContext) (*influxdb.DBRPMappingV2, error) {
-	defaultRP := true
-	mappings, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
-		OrgID:    &ectx.OrgID,
-		Database: &database,
-		Default:  &defaultRP,
-	})
+// findUser returns the v1 authorization of the user with the given name in
+// the organization.
+func (e *StatementExecutor) findUser(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Authorization, error) {
+	auths, _, err := e.AuthorizationService.FindAuthorizations(ctx, influxdb.AuthorizationFilter{Token: &name})
 	if err != nil {
-		return nil, fmt.Errorf("finding DBRP mappings: %v", err)
-	} else if n == 0 {
-		return nil, fmt.Errorf("default retention policy not set for: %s", database)
-	} else if n != 1 {
-		return nil, fmt.Errorf("finding DBRP mappings: expected 1, found %d", n)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, meta.ErrUserNotFound
+		}
+		return nil, err
 	}
-	return mappings[0], nil
+
+	for _, a := range auths {
+		if a.OrgID == orgID {
+			return a, nil
+		}
+	}
+	return nil, meta.ErrUserNotFound
 }
 
-func (e *StatementExecutor) executeDeleteSeriesStatement(ctx context.Context, q *influxql.DeleteSeriesStatement, database string, ectx *query.ExecutionContext) error {
-	mapping, err := e.getDefaultRP(ctx, database, ectx)
+// databasePermissions returns the bucket permissions which make up privilege
+// on the database.
+func (e *StatementExecutor) databasePermissions(ctx context.Context, orgID influxdb.ID, database string, privilege influxql.Privilege) ([]influxdb.Permission, error) {
+	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &orgID,
+		Database: &database,
+	})
 	if err != nil {
-		return err
+		return nil, err
+	} else if len(dbrps) == 0 {
+		return nil, query.ErrDatabaseNotFound(database)
 	}
 
-	// Convert "now()" to current time.
-	q.Condition = influxql.Reduce(q.Condition, &influxql.NowValuer{Now: time.Now().UTC()})
+	var actions []influxdb.Action
+	if privilege&influxql.ReadPrivilege != 0 {
+		actions = append(actions, influxdb.ReadAction)
+	}
+	if privilege&influxql.WritePrivilege != 0 {
+		actions = append(actions, influxdb.WriteAction)
+	}
 
-	return e.TSDBStore.DeleteSeries(mapping.BucketID.String(), q.Sources, q.Condition)
+	var ps []influxdb.Permission
+	for _, dbrp := range dbrps {
+		for _, action := range actions {
+			p, err := influxdb.NewPermissionAtID(dbrp.BucketID, action, influxdb.BucketsResourceType, orgID)
+			if err != nil {
+				return nil, err
+			}
+			ps = append(ps, *p)
+		}
+	}
+	return ps, nil
 }
 
-func (e *StatementExecutor) executeDropMeasurementStatement(ctx context.Context, q *influxql.DropMeasurementStatement, database string, ectx *query.ExecutionContext) error {
-	mapping, err := e.getDefaultRP(ctx, database, ectx)
-	if err != nil {
-		return err
+// adminPermissions returns the permissions of an admin user, which may read
+// and write all buckets of the organization.
+func adminPermissions(orgID influxdb.ID) []influxdb.Permission {
+	ps := make([]influxdb.Permission, 0, 2)
+	for _, action := range []influxdb.Action{influxdb.ReadAction, influxdb.WriteAction} {
+		ps = append(ps, influxdb.Permission{
+			Action: action,
+			Resource: influxdb.Resource{
+				Type:  influxdb.BucketsResourceType,
+				OrgID: &orgID,
+			},
+		})
 	}
-	return e.TSDBStore.DeleteMeasurement(mapping.BucketID.String(), q.Name)
+	return ps
 }
 
-func (e *StatementExecutor) executeShowMeasurementsStatement(ctx context.Context, q *influxql.ShowMeasurementsStatement, ectx *query.ExecutionContext) error {
-	if q.Database == "" {
-		return ErrDatabaseNameRequired
+func isAdmin(a *influxdb.Authorization) bool {
+	for _, p := range adminPermissions(a.OrgID) {
+		if !hasPermission(a.Permissions, p) {
+			return false
+		}
 	}
+	return true
+}
 
-	mapping, err := e.getDefaultRP(ctx, q.Database, ectx)
-	if err != nil {
-		return err
+func hasPermission(ps []influxdb.Permission, p influxdb.Permission) bool {
+	for _, q := range ps {
+		if q.Action == p.Action && q.Resource.Type == p.Resource.Type &&
+			equalIDs(q.Resource.ID, p.Resource.ID) && equalIDs(q.Resource.OrgID, p.Resource.OrgID) {
+			return true
+		}
 	}
+	return false
+}
 
-	names, err := e.TSDBStore.MeasurementNames(ectx.Authorizer, mapping.BucketID.String(), q.Condition)
-	if err != nil || len(names) == 0 {
-		return ectx.Send(ctx, &query.Result{
-			Err: err,
-		})
+func equalIDs(a, b *influxdb.ID) bool {
+	if a == nil || b == nil {
+		return a == b
 	}
+	return *a == *b
+}
 
-	if q.Offset > 0 {
-		if q.Offset >= len(names) {
-			names = nil
-		} else {
-			names = names[q.Offset:]
+// addPermissions returns ps with the permissions of add it does not have yet.
+func addPermissions(ps, add []influxdb.Permission) []influxdb.Permission {
+	result := append([]influxdb.Permission(nil), ps...)
+	for _, p := range add {
+		if !hasPermission(result, p) {
+			result = append(result, p)
 		}
 	}
+	return result
+}
 
-	if q.Limit > 0 {
-		if q.Limit < len(names) {
-			names = names[:q.Limit]
+// removePermissions returns ps without the permissions of remove.
+func removePermissions(ps, remove []influxdb.Permission) []influxdb.Permission {
+	result := make([]influxdb.Permission, 0, len(ps))
+	for _, p := range ps {
+		if !hasPermission(remove, p) {
+			result = append(result, p)
 		}
 	}
+	return result
+}
 
-	values := make([][]interface{}, len(names))
-	for i, name := range names {
-		values[i] = []interface{}{string(name)}
+// findReadableMappings returns the DBRP mappings matching filter whose buckets
+// can be read by the authorizer in ctx.
+func (e *StatementExecutor) findReadableMappings(ctx context.Context, filter influxdb.DBRPMappingFilterV2) ([]*influxdb.DBRPMappingV2, error) {
+	dbrps, _, err := e.DBRP.FindMany(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(values) == 0 {
-		return ectx.Send(ctx, &query.Result{})
+	readable := dbrps[:0]
+	for _, dbrp := range dbrps {
+		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, dbrp.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+				continue
+			}
+			return nil, err
+		}
+		readable = append(readable, dbrp)
 	}
+	return readable, nil
+}
 
-	return ectx.Send(ctx, &query.Result{
-		Series: []*models.Row{{
-			Name:    "measurements",
-			Columns: []string{"name"},
-			Values:  values,
-		}},
-	})
+// executeShowSeriesCardinalityStatement returns an estimate of the number of
+// series in every readable bucket mapped to the database. Exact and filtered
+// forms of the statement are rewritten into SELECT statements by the query
+// rewriter and never reach this method.
+func (e *StatementExecutor) executeShowSeriesCardinalityStatement(ctx context.Context, stmt *influxql.ShowSeriesCardinalityStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if stmt.Database == "" {
+		return nil, ErrDatabaseNameRequired
+	}
+	return e.estimateCardinality(ctx, stmt.Database, ectx, e.TSDBStore.SeriesSketches)
 }
 
-func (e *StatementExecutor) executeShowRetentionPoliciesStatement(ctx context.Context, q *influxql.ShowRetentionPoliciesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
-	if q.Database == "" {
+// executeShowMeasurementCardinalityStatement returns an estimate of the number
+// of measurements in every readable bucket mapped to the database.
+func (e *StatementExecutor) executeShowMeasurementCardinalityStatement(ctx context.Context, stmt *influxql.ShowMeasurementCardinalityStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if stmt.Database == "" {
 		return nil, ErrDatabaseNameRequired
 	}
+	return e.estimateCardinality(ctx, stmt.Database, ectx, e.TSDBStore.MeasurementsSketches)
+}
 
-	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+// estimateCardinality merges the sketches returned by fn for every readable
+// bucket mapped to database and returns the estimated cardinality as a row.
+func (e *StatementExecutor) estimateCardinality(ctx context.Context, database string, ectx *query.ExecutionContext, fn func(database string) (estimator.Sketch, estimator.Sketch, error)) (models.Rows, error) {
+	dbrps, err := e.findReadableMappings(ctx, influxdb.DBRPMappingFilterV2{
 		OrgID:    &ectx.OrgID,
-		Database: &q.Database,
+		Database: &database,
 	})
-
 	if err != nil {
 		return nil, err
 	}
 
-	row := &models.Row{Columns: []string{"name", "duration", "shardGroupDuration", "replicaN", "default"}}
+	var ss, ts estimator.Sketch
 	for _, dbrp := range dbrps {
-		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, dbrp.OrganizationID)
+		s, t, err := fn(dbrp.BucketID.String())
 		if err != nil {
 			return nil, err
 		}
-		err = authorizer.IsAllowed(ctx, *perm)
-		if err != nil {
-			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
-				continue
-			}
+		if ss == nil {
+			ss, ts = s, t
+			continue
+		}
+		if err := ss.Merge(s); err != nil {
+			return nil, err
+		}
+		if err := ts.Merge(t); err != nil {
 			return nil, err
 		}
-		row.Values = append(row.Values, []interface{}{dbrp.RetentionPolicy, "0s", "168h0m0s", 1, dbrp.Default})
 	}
 
-	return []*models.Row{row}, nil
+	var n int64
+	if ss != nil {
+		n = int64(ss.Count() - ts.Count())
+	}
+	return []*models.Row{{
+		Columns: []string{"cardinality estimation"},
+		Values:  [][]interface{}{{n}},
+	}}, nil
+}
+
+// joinUint64 returns a comma-delimited string of uint64 numbers.
+func joinUint64(a []uint64) string {
+	var buf strings.Builder
+	for i, x := range a {
+		buf.WriteString(strconv.FormatUint(x, 10))
+		if i < len(a)-1 {
+			buf.WriteRune(',')
+		}
+	}
+	return buf.String()
 }
 
 func (e *StatementExecutor) executeShowTagKeys(ctx context.Context, q *influxql.ShowTagKeysStatement, ectx *query.ExecutionContext) error {
@@ -757,7 +2039,9 @@ func (m mappings) DefaultRetentionPolicy(db string) string {
 type TSDBStore interface {
 	DeleteMeasurement(database, name string) error
 	DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error
+	MeasurementsSketches(database string) (estimator.Sketch, estimator.Sketch, error)
 	MeasurementNames(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error)
+	SeriesSketches(database string) (estimator.Sketch, estimator.Sketch, error)
 	TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
 	TagValues(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error)
 }
@@ -769,3 +2053,94 @@ var _ TSDBStore = LocalTSDBStore{}
 type LocalTSDBStore struct {
 	*tsdb.Store
 }
+
+// IntoWriteRequest is a partial copy of cluster.WriteRequest
+type IntoWriteRequest struct {
+	Database        string
+	RetentionPolicy string
+	Points          []models.Point
+}
+
+// pointsWriter is a subset of the PointsWriter used by SELECT INTO statements.
+type pointsWriter interface {
+	WritePointsInto(*IntoWriteRequest) error
+}
+
+// BufferedPointsWriter adds buffering to a pointsWriter so that SELECT INTO queries
+// write their points to the destination in batches.
+type BufferedPointsWriter struct {
+	w               pointsWriter
+	buf             []models.Point
+	database        string
+	retentionPolicy string
+}
+
+// NewBufferedPointsWriter returns a new BufferedPointsWriter.
+func NewBufferedPointsWriter(w pointsWriter, database, retentionPolicy string, capacity int) *BufferedPointsWriter {
+	return &BufferedPointsWriter{
+		w:               w,
+		buf:             make([]models.Point, 0, capacity),
+		database:        database,
+		retentionPolicy: retentionPolicy,
+	}
+}
+
+// WritePointsInto implements pointsWriter for BufferedPointsWriter.
+func (w *BufferedPointsWriter) WritePointsInto(req *IntoWriteRequest) error {
+	// Make sure we're buffering points only for the expected destination.
+	if req.Database != w.database || req.RetentionPolicy != w.retentionPolicy {
+		return fmt.Errorf("writer for %s.%s can't write into %s.%s", w.database, w.retentionPolicy, req.Database, req.RetentionPolicy)
+	}
+
+	for i := 0; i < len(req.Points); {
+		// Get the available space in the buffer.
+		avail := cap(w.buf) - len(w.buf)
+
+		// Calculate number of points to copy into the buffer.
+		n := len(req.Points[i:])
+		if n > avail {
+			n = avail
+		}
+
+		// Copy points into buffer.
+		w.buf = append(w.buf, req.Points[i:n+i]...)
+
+		// Advance the index by number of points copied.
+		i += n
+
+		// If buffer is full, flush points to underlying writer.
+		if len(w.buf) == cap(w.buf) {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Flush writes all buffered points to the underlying writer.
+func (w *BufferedPointsWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	if err := w.w.WritePointsInto(&IntoWriteRequest{
+		Database:        w.database,
+		RetentionPolicy: w.retentionPolicy,
+		Points:          w.buf,
+	}); err != nil {
+		return err
+	}
+
+	// Clear the buffer.
+	w.buf = w.buf[:0]
+
+	return nil
+}
+
+// Len returns the number of points buffered.
+func (w *BufferedPointsWriter) Len() int { return len(w.buf) }
+
+// Cap returns the capacity (in points) of the buffer.
+func (w *BufferedPointsWriter) Cap() int { return cap(w.buf) }
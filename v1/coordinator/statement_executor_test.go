@@ -19,10 +19,14 @@ import (
 	"github.com/influxdata/influxdb/v2/influxql/control"
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/internal"
+	"github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
+	"github.com/influxdata/influxdb/v2/pkg/estimator/hll"
 	itesting "github.com/influxdata/influxdb/v2/testing"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/influxdata/influxdb/v2/v1/coordinator"
+	"github.com/influxdata/influxdb/v2/v1/monitor"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	"github.com/influxdata/influxql"
 	"go.uber.org/zap/zaptest"
@@ -102,6 +106,108 @@ func TestQueryExecutor_ExecuteQuery_SelectStatement(t *testing.T) {
 	}
 }
 
+// Ensure query executor can write the results of a SELECT INTO statement.
+func TestQueryExecutor_ExecuteQuery_SelectIntoStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	empty := ""
+	filt := influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &empty, RetentionPolicy: &empty}
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), filt).
+		Return([]*influxdb.DBRPMappingV2{{}}, 1, nil)
+	db, rp := "db1", "rp1"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return([]*influxdb.DBRPMappingV2{{ID: 1, Database: db, RetentionPolicy: rp, OrganizationID: orgID, BucketID: 0xffe1}}, 1, nil)
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+
+	var written []*coordinator.IntoWriteRequest
+	e.StatementExecutor.PointsWriter = PointsWriterFn(func(req *coordinator.IntoWriteRequest) error {
+		written = append(written, req)
+		return nil
+	})
+
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(_ context.Context, _ *influxql.Measurement, _ query.IteratorOptions) (query.Iterator, error) {
+			return &FloatIterator{Points: []query.FloatPoint{
+				{Name: "cpu", Time: int64(0 * time.Second), Aux: []interface{}{float64(100)}},
+				{Name: "cpu", Time: int64(1 * time.Second), Aux: []interface{}{float64(200)}},
+			}}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(0xffe1, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	// Verify the write count is returned.
+	if a := ReadAllResults(e.ExecuteQuery(ctx, `SELECT * INTO db1.rp1.cpu_copy FROM cpu`, "db0", 0, orgID)); !reflect.DeepEqual(a, []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "result",
+				Columns: []string{"time", "written"},
+				Values:  [][]interface{}{{time.Unix(0, 0).UTC(), int64(2)}},
+			}},
+		},
+	}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	// Verify the points were written to the bucket of the target.
+	if len(written) != 1 {
+		t.Fatalf("unexpected number of writes: %d", len(written))
+	}
+	if got, exp := written[0].Database, influxdb.ID(0xffe1).String(); got != exp {
+		t.Fatalf("unexpected database: got %s, exp %s", got, exp)
+	}
+	var lines []string
+	for _, p := range written[0].Points {
+		lines = append(lines, p.String())
+	}
+	if exp := []string{"cpu_copy value=100 0", "cpu_copy value=200 1000000000"}; !reflect.DeepEqual(lines, exp) {
+		t.Fatalf("unexpected points: got %v, exp %v", lines, exp)
+	}
+
+	// Verify writing is forbidden without permission on the target bucket.
+	ctx = icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{ID: orgID, OrgID: orgID, Status: influxdb.Active})
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return([]*influxdb.DBRPMappingV2{{ID: 1, Database: db, RetentionPolicy: rp, OrganizationID: orgID, BucketID: 0xffe1}}, 1, nil)
+	if a := ReadAllResults(e.ExecuteQuery(ctx, `SELECT * INTO db1.rp1.cpu_copy FROM cpu`, "db0", 0, orgID)); len(a) != 1 || influxdb.ErrorCode(a[0].Err) != influxdb.EUnauthorized {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+}
+
+// PointsWriterFn adapts a function to the PointsWriter of a StatementExecutor.
+type PointsWriterFn func(req *coordinator.IntoWriteRequest) error
+
+func (fn PointsWriterFn) WritePointsInto(req *coordinator.IntoWriteRequest) error {
+	return fn(req)
+}
+
 // Ensure query executor can enforce a maximum bucket selection count.
 func TestQueryExecutor_ExecuteQuery_MaxSelectBucketsN(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -385,161 +491,1538 @@ func TestQueryExecutor_ExecuteQuery_ShowDatabases(t *testing.T) {
 	}
 }
 
-// QueryExecutor is a test wrapper for coordinator.QueryExecutor.
-type QueryExecutor struct {
-	*query.Executor
-
-	MetaClient        MetaClient
-	TSDBStore         *internal.TSDBStoreMock
-	DBRP              *mocks.MockDBRPMappingServiceV2
-	StatementExecutor *coordinator.StatementExecutor
-	LogOutput         bytes.Buffer
-}
+func TestQueryExecutor_ExecuteQuery_CreateDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-// NewQueryExecutor returns a new instance of Executor.
-// This query executor always has a node id of 0.
-func NewQueryExecutor(t *testing.T, opts ...optFn) *QueryExecutor {
-	e := &QueryExecutor{
-		Executor:  query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{})),
-		TSDBStore: &internal.TSDBStoreMock{},
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	bucketID := influxdb.ID(0xffe0)
+	db, rp := "db0", "rp0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return(nil, 0, nil)
+	dbrp.EXPECT().
+		Create(gomock.Any(), &influxdb.DBRPMappingV2{
+			Database:        db,
+			RetentionPolicy: rp,
+			Default:         true,
+			OrganizationID:  orgID,
+			BucketID:        bucketID,
+		}).
+		Return(nil)
+
+	var created *influxdb.Bucket
+	buckets := mock.NewBucketService()
+	buckets.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+		b.ID = bucketID
+		created = b
+		return nil
 	}
 
-	for _, opt := range opts {
-		opt(e)
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:          dbrp,
+		BucketService: buckets,
 	}
 
-	e.TSDBStore.CreateShardFn = func(database, policy string, shardID uint64, enabled bool) error {
-		return nil
+	q := MustParseQuery("CREATE DATABASE db0 WITH DURATION 2h NAME rp0")
+	results := ReadAllResults(qe.ExecuteQuery(context.Background(), q, query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{{StatementID: 0}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
 	}
 
-	e.TSDBStore.MeasurementNamesFn = func(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error) {
-		return nil, nil
+	if created == nil {
+		t.Fatal("expected bucket to be created")
 	}
+	if exp, got := "db0/rp0", created.Name; exp != got {
+		t.Errorf("unexpected bucket name: exp %q, got %q", exp, got)
+	}
+	if exp, got := orgID, created.OrgID; exp != got {
+		t.Errorf("unexpected bucket org: exp %v, got %v", exp, got)
+	}
+	if exp, got := 2*time.Hour, created.RetentionPeriod; exp != got {
+		t.Errorf("unexpected retention period: exp %v, got %v", exp, got)
+	}
+}
 
-	e.TSDBStore.TagValuesFn = func(_ query.Authorizer, _ []uint64, _ influxql.Expr) ([]tsdb.TagValues, error) {
-		return nil, nil
+func TestQueryExecutor_ExecuteQuery_CreateDatabase_Exists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	db := "db0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 1, Database: db, RetentionPolicy: "rp1", Default: true, OrganizationID: orgID, BucketID: 0xffe0},
+		}, 1, nil)
+
+	buckets := mock.NewBucketService()
+	buckets.CreateBucketFn = func(context.Context, *influxdb.Bucket) error {
+		t.Fatal("bucket should not be created")
+		return nil
 	}
 
-	e.StatementExecutor = &coordinator.StatementExecutor{
-		MetaClient: &e.MetaClient,
-		TSDBStore:  e.TSDBStore,
-		DBRP:       e.DBRP,
-		ShardMapper: &coordinator.LocalShardMapper{
-			MetaClient: &e.MetaClient,
-			TSDBStore:  e.TSDBStore,
-			DBRP:       e.DBRP,
-		},
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:          dbrp,
+		BucketService: buckets,
 	}
-	e.Executor.StatementExecutor = e.StatementExecutor
 
-	return e
+	q := MustParseQuery("CREATE DATABASE db0")
+	results := ReadAllResults(qe.ExecuteQuery(context.Background(), q, query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{{StatementID: 0}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
 }
 
-type optFn func(qe *QueryExecutor)
+func TestQueryExecutor_ExecuteQuery_CreateDatabase_Autogen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-func WithDBRP(dbrp *mocks.MockDBRPMappingServiceV2) optFn {
-	return func(qe *QueryExecutor) {
-		qe.DBRP = dbrp
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	bucketID := influxdb.ID(0xffe0)
+	db, rp := "db0", "autogen"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return(nil, 0, nil)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return(nil, 0, nil)
+	dbrp.EXPECT().
+		Create(gomock.Any(), &influxdb.DBRPMappingV2{
+			Database:        db,
+			RetentionPolicy: rp,
+			Default:         true,
+			OrganizationID:  orgID,
+			BucketID:        bucketID,
+		}).
+		Return(nil)
+
+	var created *influxdb.Bucket
+	buckets := mock.NewBucketService()
+	buckets.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+		b.ID = bucketID
+		created = b
+		return nil
 	}
-}
-
-// DefaultQueryExecutor returns a Executor with a database (db0) and retention policy (rp0).
-func DefaultQueryExecutor(t *testing.T, opts ...optFn) *QueryExecutor {
-	e := NewQueryExecutor(t, opts...)
-	e.MetaClient.DatabaseFn = DefaultMetaClientDatabaseFn
-	return e
-}
 
-// ExecuteQuery parses query and executes against the database.
-func (e *QueryExecutor) ExecuteQuery(ctx context.Context, q, database string, chunkSize int, orgID influxdb.ID) (<-chan *query.Result, *influxql2.Statistics) {
-	return e.Executor.ExecuteQuery(ctx, MustParseQuery(q), query.ExecutionOptions{
-		OrgID:     orgID,
-		Database:  database,
-		ChunkSize: chunkSize,
-	})
-}
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:          dbrp,
+		BucketService: buckets,
+	}
 
-type MockShard struct {
-	Measurements             []string
-	FieldDimensionsFn        func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error)
-	FieldKeysByMeasurementFn func(name []byte) []string
-	CreateIteratorFn         func(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error)
-	IteratorCostFn           func(m string, opt query.IteratorOptions) (query.IteratorCost, error)
-	ExpandSourcesFn          func(sources influxql.Sources) (influxql.Sources, error)
-}
+	q := MustParseQuery("CREATE DATABASE db0")
+	results := ReadAllResults(qe.ExecuteQuery(context.Background(), q, query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{{StatementID: 0}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
 
-func (sh *MockShard) MeasurementsByRegex(re *regexp.Regexp) []string {
-	names := make([]string, 0, len(sh.Measurements))
-	for _, name := range sh.Measurements {
-		if re.MatchString(name) {
-			names = append(names, name)
-		}
+	if created == nil {
+		t.Fatal("expected bucket to be created")
+	}
+	if exp, got := "db0/autogen", created.Name; exp != got {
+		t.Errorf("unexpected bucket name: exp %q, got %q", exp, got)
 	}
-	return names
 }
 
-func (sh *MockShard) FieldKeysByMeasurement(name []byte) []string {
-	return sh.FieldKeysByMeasurementFn(name)
-}
+func TestQueryExecutor_ExecuteQuery_CreateRetentionPolicy_DurationTooLow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-func (sh *MockShard) FieldDimensions(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
-	return sh.FieldDimensionsFn(measurements)
-}
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	db, rp := "db0", "rp0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return(nil, 0, nil)
 
-func (sh *MockShard) MapType(measurement, field string) influxql.DataType {
-	f, d, err := sh.FieldDimensions([]string{measurement})
-	if err != nil {
-		return influxql.Unknown
+	buckets := mock.NewBucketService()
+	buckets.CreateBucketFn = func(context.Context, *influxdb.Bucket) error {
+		t.Fatal("bucket should not be created")
+		return nil
 	}
 
-	if typ, ok := f[field]; ok {
-		return typ
-	} else if _, ok := d[field]; ok {
-		return influxql.Tag
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:          dbrp,
+		BucketService: buckets,
 	}
-	return influxql.Unknown
-}
-
-func (sh *MockShard) CreateIterator(ctx context.Context, measurement *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
-	return sh.CreateIteratorFn(ctx, measurement, opt)
-}
 
-func (sh *MockShard) IteratorCost(measurement string, opt query.IteratorOptions) (query.IteratorCost, error) {
-	return sh.IteratorCostFn(measurement, opt)
+	q := MustParseQuery("CREATE RETENTION POLICY rp0 ON db0 DURATION 30m REPLICATION 1")
+	results := ReadAllResults(qe.ExecuteQuery(context.Background(), q, query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{{StatementID: 0, Err: meta.ErrRetentionPolicyDurationTooLow}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
 }
 
-func (sh *MockShard) ExpandSources(sources influxql.Sources) (influxql.Sources, error) {
-	return sh.ExpandSourcesFn(sources)
-}
+func TestQueryExecutor_ExecuteQuery_AlterRetentionPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-// MustParseQuery parses s into a query. Panic on error.
-func MustParseQuery(s string) *influxql.Query {
-	q, err := influxql.ParseQuery(s)
-	if err != nil {
-		panic(err)
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	bucketID := influxdb.ID(0xffe0)
+	db, rp := "db0", "rp1"
+	mapping := &influxdb.DBRPMappingV2{ID: 1, Database: db, RetentionPolicy: rp, OrganizationID: orgID, BucketID: bucketID}
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return([]*influxdb.DBRPMappingV2{mapping}, 1, nil)
+	dbrp.EXPECT().
+		Update(gomock.Any(), &influxdb.DBRPMappingV2{ID: 1, Database: db, RetentionPolicy: rp, Default: true, OrganizationID: orgID, BucketID: bucketID}).
+		Return(nil)
+
+	var upd influxdb.BucketUpdate
+	buckets := mock.NewBucketService()
+	buckets.UpdateBucketFn = func(_ context.Context, id influxdb.ID, u influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+		if id != bucketID {
+			t.Fatalf("unexpected bucket ID: %v", id)
+		}
+		upd = u
+		return &influxdb.Bucket{ID: id}, nil
 	}
-	return q
-}
 
-// ReadAllResults reads all results from c and returns as a slice.
-func ReadAllResults(c <-chan *query.Result, _ *influxql2.Statistics) []*query.Result {
-	var a []*query.Result
-	for result := range c {
-		a = append(a, result)
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:          dbrp,
+		BucketService: buckets,
 	}
-	return a
-}
 
-// FloatIterator is a represents an iterator that reads from a slice.
-type FloatIterator struct {
-	Points []query.FloatPoint
-	stats  query.IteratorStats
+	q := MustParseQuery("ALTER RETENTION POLICY rp1 ON db0 DURATION 4h DEFAULT")
+	results := ReadAllResults(qe.ExecuteQuery(context.Background(), q, query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{{StatementID: 0}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+	if upd.RetentionPeriod == nil || *upd.RetentionPeriod != 4*time.Hour {
+		t.Fatalf("unexpected bucket update: %s", spew.Sdump(upd))
+	}
 }
 
-func (itr *FloatIterator) Stats() query.IteratorStats { return itr.stats }
-func (itr *FloatIterator) Close() error               { return nil }
+func TestQueryExecutor_ExecuteQuery_AlterRetentionPolicy_Unsupported(t *testing.T) {
+	for _, tt := range []struct {
+		stmt string
+		err  string
+	}{
+		{
+			stmt: "ALTER RETENTION POLICY rp1 ON db0 DURATION 4h SHARD DURATION 2h",
+			err:  "changing the shard duration of a retention policy is not supported",
+		},
+		{
+			stmt: "ALTER RETENTION POLICY rp1 ON db0 REPLICATION 2",
+			err:  "changing the replication factor of a retention policy is not supported",
+		},
+	} {
+		t.Run(tt.stmt, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
 
-// Next returns the next value and shifts it off the beginning of the points slice.
+			dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+			orgID := influxdb.ID(0xff00)
+			db, rp := "db0", "rp1"
+			dbrp.EXPECT().
+				FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+				Return([]*influxdb.DBRPMappingV2{{ID: 1, Database: db, RetentionPolicy: rp, OrganizationID: orgID, BucketID: 0xffe0}}, 1, nil)
+
+			buckets := mock.NewBucketService()
+			buckets.UpdateBucketFn = func(context.Context, influxdb.ID, influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+				t.Fatal("bucket should not be updated")
+				return nil, nil
+			}
+
+			qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+			qe.StatementExecutor = &coordinator.StatementExecutor{
+				DBRP:          dbrp,
+				BucketService: buckets,
+			}
+
+			results := ReadAllResults(qe.ExecuteQuery(context.Background(), MustParseQuery(tt.stmt), query.ExecutionOptions{OrgID: orgID}))
+			if len(results) != 1 || results[0].Err == nil || results[0].Err.Error() != tt.err {
+				t.Fatalf("unexpected results: %s", spew.Sdump(results))
+			}
+		})
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowRetentionPolicies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	db := "db0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 1, Database: db, RetentionPolicy: "autogen", Default: true, OrganizationID: orgID, BucketID: 0xffe0},
+			{ID: 2, Database: db, RetentionPolicy: "rp1", OrganizationID: orgID, BucketID: 0xffe1},
+			{ID: 3, Database: db, RetentionPolicy: "rp2", OrganizationID: orgID, BucketID: 0xffe2},
+		}, 3, nil)
+
+	retention := map[influxdb.ID]time.Duration{
+		0xffe0: 0,
+		0xffe1: 72 * time.Hour,
+		0xffe2: 6 * time.Hour,
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, RetentionPeriod: retention[id]}, nil
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:          dbrp,
+		BucketService: buckets,
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(0xffe0, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(0xffe1, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(0xffe2, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+	results := ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery("SHOW RETENTION POLICIES ON db0"), query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Columns: []string{"name", "duration", "shardGroupDuration", "replicaN", "default"},
+			Values: [][]interface{}{
+				{"autogen", "0s", "168h0m0s", 1, true},
+				{"rp1", "72h0m0s", "24h0m0s", 1, false},
+				{"rp2", "6h0m0s", "1h0m0s", 1, false},
+			},
+		}},
+	}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_DropDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	db := "db0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 1, Database: db, RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: 0xffe0},
+			{ID: 2, Database: db, RetentionPolicy: "rp1", OrganizationID: orgID, BucketID: 0xffe1},
+		}, 2, nil)
+	dbrp.EXPECT().Delete(gomock.Any(), orgID, influxdb.ID(1)).Return(nil)
+	dbrp.EXPECT().Delete(gomock.Any(), orgID, influxdb.ID(2)).Return(nil)
+
+	var deleted []influxdb.ID
+	buckets := mock.NewBucketService()
+	buckets.DeleteBucketFn = func(_ context.Context, id influxdb.ID) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:          dbrp,
+		BucketService: buckets,
+	}
+
+	q := MustParseQuery("DROP DATABASE db0")
+	results := ReadAllResults(qe.ExecuteQuery(context.Background(), q, query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{{StatementID: 0}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+	if exp := []influxdb.ID{0xffe0, 0xffe1}; !reflect.DeepEqual(deleted, exp) {
+		t.Fatalf("unexpected deleted buckets: exp %v, got %v", exp, deleted)
+	}
+}
+
+// QueryExecutor is a test wrapper for coordinator.QueryExecutor.
+type QueryExecutor struct {
+	*query.Executor
+
+	MetaClient        MetaClient
+	TSDBStore         *internal.TSDBStoreMock
+	DBRP              *mocks.MockDBRPMappingServiceV2
+	StatementExecutor *coordinator.StatementExecutor
+	LogOutput         bytes.Buffer
+}
+
+func TestQueryExecutor_ExecuteQuery_CreateContinuousQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	mapping := &influxdb.DBRPMappingV2{ID: 1, Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: 0xffe0, Default: true}
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), gomock.Any()).
+		Return([]*influxdb.DBRPMappingV2{mapping}, 1, nil).
+		Times(2)
+
+	var created influxdb.TaskCreate
+	tasks := mock.NewTaskService()
+	tasks.FindTasksFn = func(_ context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+		return nil, 0, nil
+	}
+	tasks.CreateTaskFn = func(_ context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+		created = tc
+		return &influxdb.Task{ID: 1, Type: tc.Type, OrganizationID: tc.OrganizationID}, nil
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:        dbrp,
+		TaskService: tasks,
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{UserID: 0xf0})
+	q := MustParseQuery("CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM cpu GROUP BY time(1h) END")
+	results := ReadAllResults(qe.ExecuteQuery(ctx, q, query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{{StatementID: 0}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+	if created.OrganizationID != orgID || created.OwnerID != 0xf0 {
+		t.Fatalf("unexpected task: %s", spew.Sdump(created))
+	}
+	if got, exp := created.Metadata["database"], "db0"; got != exp {
+		t.Fatalf("unexpected database: exp %v, got %v", exp, got)
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowContinuousQueries(t *testing.T) {
+	orgID := influxdb.ID(0xff00)
+	tasks := mock.NewTaskService()
+	tasks.FindTasksFn = func(_ context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+		if filter.OrganizationID == nil || *filter.OrganizationID != orgID {
+			t.Fatalf("unexpected filter: %v", filter)
+		}
+		return []*influxdb.Task{
+			{ID: 1, Name: "cq0", Type: "continuous_query", Metadata: map[string]interface{}{"database": "db0", "query": "CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h) END"}},
+			{ID: 2, Name: "cq1", Type: "continuous_query", Metadata: map[string]interface{}{"database": "db1", "query": "CREATE CONTINUOUS QUERY cq1 ON db1 BEGIN SELECT mean(value) INTO mem_1h FROM mem GROUP BY time(1h) END"}},
+		}, 2, nil
+	}
+	var deleted influxdb.ID
+	tasks.DeleteTaskFn = func(_ context.Context, id influxdb.ID) error {
+		deleted = id
+		return nil
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		TaskService: tasks,
+	}
+
+	q := MustParseQuery("SHOW CONTINUOUS QUERIES")
+	results := ReadAllResults(qe.ExecuteQuery(context.Background(), q, query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{
+				{
+					Name:    "db0",
+					Columns: []string{"name", "query"},
+					Values:  [][]interface{}{{"cq0", "CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h) END"}},
+				},
+				{
+					Name:    "db1",
+					Columns: []string{"name", "query"},
+					Values:  [][]interface{}{{"cq1", "CREATE CONTINUOUS QUERY cq1 ON db1 BEGIN SELECT mean(value) INTO mem_1h FROM mem GROUP BY time(1h) END"}},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+
+	q = MustParseQuery("DROP CONTINUOUS QUERY cq1 ON db1")
+	results = ReadAllResults(qe.ExecuteQuery(context.Background(), q, query.ExecutionOptions{OrgID: orgID}))
+	if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+	if deleted != 2 {
+		t.Fatalf("unexpected deleted task: %v", deleted)
+	}
+
+	q = MustParseQuery("DROP CONTINUOUS QUERY cq1 ON db0")
+	results = ReadAllResults(qe.ExecuteQuery(context.Background(), q, query.ExecutionOptions{OrgID: orgID}))
+	if len(results) != 1 || results[0].Err != meta.ErrContinuousQueryNotFound {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 1, Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: 0xffe0},
+			{ID: 2, Database: "db1", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: 0xffe1},
+		}, 2, nil)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	shards := mock.NewShardService()
+	shards.FindShardGroupsFn = func(_ context.Context, filter influxdb.ShardGroupFilter) ([]*influxdb.ShardGroup, error) {
+		if filter.BucketID == nil || *filter.BucketID != 0xffe0 {
+			t.Fatalf("unexpected filter: %+v", filter)
+		}
+		return []*influxdb.ShardGroup{{
+			ID:         1,
+			BucketID:   0xffe0,
+			StartTime:  start,
+			EndTime:    start.Add(24 * time.Hour),
+			ExpiryTime: start.Add(48 * time.Hour),
+			Shards:     []influxdb.Shard{{ID: 10, Owners: []uint64{0}, Size: 1024}},
+		}}, nil
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:         dbrp,
+		ShardService: shards,
+	}
+
+	// The authorizer may only read the bucket of db0.
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(0xffe0, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	results := ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery("SHOW SHARDS"), query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "db0",
+				Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "owners", "size"},
+				Values: [][]interface{}{
+					{uint64(10), "db0", "rp0", uint64(1), "2020-01-01T00:00:00Z", "2020-01-02T00:00:00Z", "2020-01-03T00:00:00Z", "0", int64(1024)},
+				},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_DropShard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID, bucketID := influxdb.ID(0xff00), influxdb.ID(0xffe0)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, BucketID: &bucketID}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 1, Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: bucketID},
+		}, 1, nil).
+		Times(2)
+
+	var deleted []uint64
+	shards := mock.NewShardService()
+	shards.FindShardGroupsFn = func(_ context.Context, filter influxdb.ShardGroupFilter) ([]*influxdb.ShardGroup, error) {
+		if filter.ShardID == nil || *filter.ShardID != 10 {
+			return nil, nil
+		}
+		return []*influxdb.ShardGroup{{ID: 1, BucketID: bucketID, Shards: []influxdb.Shard{{ID: 10}}}}, nil
+	}
+	shards.DeleteShardFn = func(_ context.Context, id uint64) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:         dbrp,
+		ShardService: shards,
+	}
+
+	// Dropping a shard requires write access to its bucket.
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+	results := ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery("DROP SHARD 10"), query.ExecutionOptions{OrgID: orgID}))
+	if len(results) != 1 || influxdb.ErrorCode(results[0].Err) != influxdb.EUnauthorized {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+
+	ctx = icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(bucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+	results = ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery("DROP SHARD 10; DROP SHARD 11"), query.ExecutionOptions{OrgID: orgID}))
+	if exp := []*query.Result{{StatementID: 0}, {StatementID: 1}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+	if exp := []uint64{10}; !reflect.DeepEqual(deleted, exp) {
+		t.Fatalf("unexpected deleted shards: exp %v, got %v", exp, deleted)
+	}
+}
+
+type ReporterFunc func(tags map[string]string) []models.Statistic
+
+func (f ReporterFunc) Statistics(tags map[string]string) []models.Statistic {
+	return f(tags)
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowStats(t *testing.T) {
+	m := monitor.New(ReporterFunc(func(tags map[string]string) []models.Statistic {
+		return []models.Statistic{
+			{Name: "shard", Tags: map[string]string{"id": "1"}, Values: map[string]interface{}{"writePointsOk": int64(10), "diskBytes": int64(512)}},
+			{Name: "tsm1_wal", Tags: map[string]string{"id": "1"}, Values: map[string]interface{}{"currentSegmentDiskBytes": int64(64)}},
+		}
+	}), monitor.Config{})
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		Monitor: m,
+	}
+
+	// Statistics are only available to operators.
+	orgID := influxdb.ID(0xff00)
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:          orgID,
+		OrgID:       orgID,
+		Status:      influxdb.Active,
+		Permissions: influxdb.OwnerPermissions(orgID),
+	})
+	results := ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery("SHOW STATS"), query.ExecutionOptions{OrgID: orgID}))
+	if len(results) != 1 || influxdb.ErrorCode(results[0].Err) != influxdb.EUnauthorized {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+
+	ctx = icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:          orgID,
+		OrgID:       orgID,
+		Status:      influxdb.Active,
+		Permissions: influxdb.OperPermissions(),
+	})
+	results = ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery("SHOW STATS FOR 'shard'"), query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "shard",
+				Tags:    map[string]string{"id": "1"},
+				Columns: []string{"diskBytes", "writePointsOk"},
+				Values:  [][]interface{}{{int64(512), int64(10)}},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowDiagnostics(t *testing.T) {
+	m := monitor.New(nil, monitor.Config{})
+	m.Version = "v2.0.0"
+	m.Commit = "abc123"
+	m.BuildTime = "2020-01-01T00:00:00Z"
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		Monitor: m,
+	}
+
+	orgID := influxdb.ID(0xff00)
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:          orgID,
+		OrgID:       orgID,
+		Status:      influxdb.Active,
+		Permissions: influxdb.OperPermissions(),
+	})
+	results := ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery("SHOW DIAGNOSTICS FOR 'build'"), query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "build",
+				Columns: []string{"Branch", "Build Time", "Commit", "Version"},
+				Values:  [][]interface{}{{"", "2020-01-01T00:00:00Z", "abc123", "v2.0.0"}},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+
+	// Without a module, every registered diagnostic is returned.
+	results = ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery("SHOW DIAGNOSTICS"), query.ExecutionOptions{OrgID: orgID}))
+	var names []string
+	for _, row := range results[0].Series {
+		names = append(names, row.Name)
+	}
+	if exp := []string{"build", "network", "runtime", "system"}; !reflect.DeepEqual(names, exp) {
+		t.Fatalf("unexpected diagnostics: exp %v, got %v", exp, names)
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_DropSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgID := influxdb.ID(0xff00)
+	bucketID0, bucketID1 := influxdb.ID(0xffe0), influxdb.ID(0xffe1)
+	db0 := "db0"
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db0}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 1, Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: bucketID0},
+			{ID: 2, Database: "db0", RetentionPolicy: "rp1", OrganizationID: orgID, BucketID: bucketID1},
+		}, 2, nil).
+		Times(2)
+
+	type deleteSeries struct {
+		database  string
+		sources   string
+		condition string
+	}
+	var deleted []deleteSeries
+	tsdbStore := &internal.TSDBStoreMock{
+		DeleteSeriesFn: func(database string, sources []influxql.Source, condition influxql.Expr) error {
+			deleted = append(deleted, deleteSeries{
+				database:  database,
+				sources:   influxql.Sources(sources).String(),
+				condition: condition.String(),
+			})
+			return nil
+		},
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:      dbrp,
+		TSDBStore: tsdbStore,
+	}
+
+	// Dropping series requires write access to every bucket of the database.
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(bucketID0, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+	q := `DROP SERIES FROM cpu WHERE host = 'serverA' AND time < '2020-01-01T00:00:00Z'`
+	results := ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery(q), query.ExecutionOptions{OrgID: orgID, Database: "db0"}))
+	if len(results) != 1 || influxdb.ErrorCode(results[0].Err) != influxdb.EUnauthorized {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("unexpected deleted series: %v", deleted)
+	}
+
+	ctx = icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(bucketID0, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(bucketID1, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+	results = ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery(q), query.ExecutionOptions{OrgID: orgID, Database: "db0"}))
+	if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+
+	cond := `host = 'serverA' AND time < '2020-01-01T00:00:00Z'`
+	exp := []deleteSeries{
+		{database: bucketID0.String(), sources: "cpu", condition: cond},
+		{database: bucketID1.String(), sources: "cpu", condition: cond},
+	}
+	if !reflect.DeepEqual(deleted, exp) {
+		t.Fatalf("unexpected deleted series: exp %v, got %v", exp, deleted)
+	}
+
+	// A database is required.
+	results = ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery("DROP SERIES FROM cpu"), query.ExecutionOptions{OrgID: orgID}))
+	if len(results) != 1 || results[0].Err != coordinator.ErrDatabaseNameRequired {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+}
+
+type SetPasswordFn func(ctx context.Context, id influxdb.ID, password string) error
+
+func (fn SetPasswordFn) SetPassword(ctx context.Context, id influxdb.ID, password string) error {
+	return fn(ctx, id, password)
+}
+
+type UpdatePermissionsFn func(ctx context.Context, id influxdb.ID, permissions []influxdb.Permission) error
+
+func (fn UpdatePermissionsFn) UpdatePermissions(ctx context.Context, id influxdb.ID, permissions []influxdb.Permission) error {
+	return fn(ctx, id, permissions)
+}
+
+func TestQueryExecutor_ExecuteQuery_UserManagement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgID, userID, bucketID := influxdb.ID(0xff00), influxdb.ID(0xfe00), influxdb.ID(0xffe0)
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, filter influxdb.DBRPMappingFilterV2) ([]*influxdb.DBRPMappingV2, int, error) {
+			if (filter.Database != nil && *filter.Database != "db0") || (filter.BucketID != nil && *filter.BucketID != bucketID) {
+				return nil, 0, nil
+			}
+			return []*influxdb.DBRPMappingV2{
+				{ID: 1, Database: "db0", RetentionPolicy: "autogen", OrganizationID: orgID, BucketID: bucketID},
+			}, 1, nil
+		}).
+		AnyTimes()
+
+	// The v1 authorizations of the users, by token.
+	auths := make(map[string]*influxdb.Authorization)
+	passwords := make(map[influxdb.ID]string)
+	authSvc := mock.NewAuthorizationService()
+	authSvc.FindAuthorizationsFn = func(_ context.Context, filter influxdb.AuthorizationFilter, _ ...influxdb.FindOptions) ([]*influxdb.Authorization, int, error) {
+		if filter.Token != nil {
+			a, ok := auths[*filter.Token]
+			if !ok {
+				return nil, 0, &influxdb.Error{Code: influxdb.ENotFound, Msg: "authorization not found"}
+			}
+			return []*influxdb.Authorization{a}, 1, nil
+		}
+		var as []*influxdb.Authorization
+		for _, a := range auths {
+			as = append(as, a)
+		}
+		return as, len(as), nil
+	}
+	authSvc.CreateAuthorizationFn = func(_ context.Context, a *influxdb.Authorization) error {
+		a.ID = influxdb.ID(len(auths) + 1)
+		auths[a.Token] = a
+		return nil
+	}
+	authSvc.DeleteAuthorizationFn = func(_ context.Context, id influxdb.ID) error {
+		for token, a := range auths {
+			if a.ID == id {
+				delete(auths, token)
+			}
+		}
+		return nil
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:                 dbrp,
+		AuthorizationService: authSvc,
+		PasswordService: SetPasswordFn(func(_ context.Context, id influxdb.ID, password string) error {
+			if len(password) < 8 {
+				return errors.New("password is too short")
+			}
+			passwords[id] = password
+			return nil
+		}),
+		PermissionService: UpdatePermissionsFn(func(_ context.Context, id influxdb.ID, permissions []influxdb.Permission) error {
+			for _, a := range auths {
+				if a.ID == id {
+					a.Permissions = permissions
+				}
+			}
+			return nil
+		}),
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:          orgID,
+		OrgID:       orgID,
+		UserID:      userID,
+		Status:      influxdb.Active,
+		Permissions: influxdb.OwnerPermissions(orgID),
+	})
+	results := ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery(`
+		CREATE USER bob WITH PASSWORD 'password1';
+		CREATE USER admin WITH PASSWORD 'password2' WITH ALL PRIVILEGES;
+		GRANT READ ON db0 TO bob;
+		GRANT WRITE ON db0 TO bob;
+		SHOW GRANTS FOR bob;
+		REVOKE READ ON db0 FROM bob;
+		REVOKE ALL PRIVILEGES FROM admin;
+		SHOW GRANTS FOR bob;
+		SHOW USERS;
+		SET PASSWORD FOR bob = 'password3';
+		DROP USER admin;
+		SHOW USERS`), query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{
+		{StatementID: 0},
+		{StatementID: 1},
+		{StatementID: 2},
+		{StatementID: 3},
+		{
+			StatementID: 4,
+			Series: []*models.Row{{
+				Columns: []string{"database", "privilege"},
+				Values:  [][]interface{}{{"db0", "ALL PRIVILEGES"}},
+			}},
+		},
+		{StatementID: 5},
+		{StatementID: 6},
+		{
+			StatementID: 7,
+			Series: []*models.Row{{
+				Columns: []string{"database", "privilege"},
+				Values:  [][]interface{}{{"db0", "WRITE"}},
+			}},
+		},
+		{
+			StatementID: 8,
+			Series: []*models.Row{{
+				Columns: []string{"user", "admin"},
+				Values:  [][]interface{}{{"admin", false}, {"bob", false}},
+			}},
+		},
+		{StatementID: 9},
+		{StatementID: 10},
+		{
+			StatementID: 11,
+			Series: []*models.Row{{
+				Columns: []string{"user", "admin"},
+				Values:  [][]interface{}{{"bob", false}},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+
+	bob := auths["bob"]
+	if bob.UserID != userID || bob.OrgID != orgID {
+		t.Fatalf("unexpected owner of user: %s", spew.Sdump(bob))
+	}
+	if got := passwords[bob.ID]; got != "password3" {
+		t.Fatalf("unexpected password: %q", got)
+	}
+
+	for _, tt := range []struct {
+		q   string
+		err string
+	}{
+		{q: "CREATE USER bob WITH PASSWORD 'password1'", err: "user already exists"},
+		{q: "CREATE USER carol WITH PASSWORD 'short'", err: "password is too short"},
+		{q: "DROP USER carol", err: "user not found"},
+		{q: "GRANT READ ON db1 TO bob", err: "database not found: db1"},
+	} {
+		results := ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery(tt.q), query.ExecutionOptions{OrgID: orgID}))
+		if len(results) != 1 || results[0].Err == nil || results[0].Err.Error() != tt.err {
+			t.Errorf("%s: unexpected results: %s", tt.q, spew.Sdump(results))
+		}
+	}
+	if _, ok := auths["carol"]; ok {
+		t.Fatal("expected user without a valid password to be removed")
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowCardinalityEstimation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID, database := influxdb.ID(0xff00), "db0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &database}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 1, Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: 0xffe0},
+			{ID: 2, Database: "db0", RetentionPolicy: "rp1", OrganizationID: orgID, BucketID: 0xffe1},
+			{ID: 3, Database: "db0", RetentionPolicy: "rp2", OrganizationID: orgID, BucketID: 0xffe2},
+		}, 3, nil).
+		Times(2)
+
+	// Each bucket contains keys that overlap with the other buckets, and the
+	// second bucket has a tombstone for one of its keys.
+	sketches := func(name string) func(database string) (estimator.Sketch, estimator.Sketch, error) {
+		return func(database string) (estimator.Sketch, estimator.Sketch, error) {
+			ss, ts := hll.NewDefaultPlus(), hll.NewDefaultPlus()
+			switch database {
+			case influxdb.ID(0xffe0).String():
+				ss.Add([]byte(name + "a"))
+				ss.Add([]byte(name + "b"))
+			case influxdb.ID(0xffe1).String():
+				ss.Add([]byte(name + "b"))
+				ss.Add([]byte(name + "c"))
+				ts.Add([]byte(name + "c"))
+			default:
+				t.Fatalf("unexpected database: %s", database)
+			}
+			return ss, ts, nil
+		}
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP: dbrp,
+		TSDBStore: &internal.TSDBStoreMock{
+			SeriesSketchesFn:       sketches("cpu,host="),
+			MeasurementsSketchesFn: sketches("m"),
+		},
+	}
+
+	// The authorizer may not read the bucket mapped to rp2.
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(0xffe0, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(0xffe1, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	for _, q := range []string{"SHOW SERIES CARDINALITY ON db0", "SHOW MEASUREMENT CARDINALITY ON db0"} {
+		results := ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery(q), query.ExecutionOptions{OrgID: orgID}))
+		exp := []*query.Result{
+			{
+				StatementID: 0,
+				Series: []*models.Row{{
+					Columns: []string{"cardinality estimation"},
+					Values:  [][]interface{}{{int64(2)}},
+				}},
+			},
+		}
+		if !reflect.DeepEqual(results, exp) {
+			t.Fatalf("%s: unexpected results: exp %s, got %s", q, spew.Sdump(exp), spew.Sdump(results))
+		}
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowCardinalityExact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), gomock.Any()).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 1, Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe0},
+		}, 1, nil).
+		AnyTimes()
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	// The shard holds three series of two measurements and, like the engine,
+	// evaluates the condition, dimensions and name stripping of the iterator
+	// options against the series.
+	series := []struct {
+		name string
+		tags map[string]string
+	}{
+		{name: "cpu", tags: map[string]string{"host": "a"}},
+		{name: "cpu", tags: map[string]string{"host": "b"}},
+		{name: "mem", tags: map[string]string{"host": "a"}},
+	}
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		sh := &MockShard{Measurements: []string{"cpu", "mem"}}
+		sh.CreateIteratorFn = func(_ context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+			var itr StringIterator
+			for _, s := range series {
+				if s.name != m.Name {
+					continue
+				} else if opt.Condition != nil {
+					valuer := make(map[string]interface{})
+					for k, v := range s.tags {
+						valuer[k] = v
+					}
+					if !influxql.EvalBool(opt.Condition, valuer) {
+						continue
+					}
+				}
+
+				tags := make(map[string]string)
+				for _, d := range opt.Dimensions {
+					tags[d] = s.tags[d]
+				}
+
+				var value string
+				switch ref := opt.Expr.(*influxql.VarRef); ref.Val {
+				case "_name":
+					value = s.name
+				case "_seriesKey":
+					value = string(models.MakeKey([]byte(s.name), models.NewTags(s.tags)))
+				default:
+					t.Fatalf("unexpected expression: %s", ref)
+				}
+				name := s.name
+				if opt.StripName {
+					name = ""
+				}
+				itr.Points = append(itr.Points, query.StringPoint{Name: name, Tags: query.NewTags(tags), Value: value})
+			}
+			return &itr, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, map[string]struct{}{"host": {}}, nil
+		}
+		return sh
+	}
+
+	row := func(name string, tags map[string]string, n int64) *models.Row {
+		return &models.Row{Name: name, Tags: tags, Columns: []string{"count"}, Values: [][]interface{}{{n}}}
+	}
+
+	tests := []struct {
+		q   string
+		exp models.Rows
+	}{
+		{
+			q:   `SHOW SERIES EXACT CARDINALITY`,
+			exp: models.Rows{row("cpu", nil, 2), row("mem", nil, 1)},
+		},
+		{
+			q:   `SHOW SERIES CARDINALITY FROM cpu`,
+			exp: models.Rows{row("cpu", nil, 2)},
+		},
+		{
+			q:   `SHOW SERIES EXACT CARDINALITY WHERE host = 'a'`,
+			exp: models.Rows{row("cpu", nil, 1), row("mem", nil, 1)},
+		},
+		{
+			q: `SHOW SERIES EXACT CARDINALITY FROM cpu GROUP BY host`,
+			exp: models.Rows{
+				row("cpu", map[string]string{"host": "a"}, 1),
+				row("cpu", map[string]string{"host": "b"}, 1),
+			},
+		},
+		{
+			q:   `SHOW MEASUREMENT EXACT CARDINALITY`,
+			exp: models.Rows{row("", nil, 2)},
+		},
+		{
+			q:   `SHOW MEASUREMENT CARDINALITY FROM cpu`,
+			exp: models.Rows{row("", nil, 1)},
+		},
+		{
+			q:   `SHOW MEASUREMENT EXACT CARDINALITY WHERE host = 'b'`,
+			exp: models.Rows{row("", nil, 1)},
+		},
+		{
+			q: `SHOW MEASUREMENT EXACT CARDINALITY GROUP BY host`,
+			exp: models.Rows{
+				row("", map[string]string{"host": "a"}, 2),
+				row("", map[string]string{"host": "b"}, 1),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.q, func(t *testing.T) {
+			// Each series of the result may be sent separately.
+			var rows models.Rows
+			for _, result := range ReadAllResults(e.ExecuteQuery(context.Background(), tt.q, "db0", 0, orgID)) {
+				if result.Err != nil {
+					t.Fatal(result.Err)
+				}
+				rows = append(rows, result.Series...)
+			}
+			if !reflect.DeepEqual(rows, tt.exp) {
+				t.Fatalf("unexpected rows: exp %s, got %s", spew.Sdump(tt.exp), spew.Sdump(rows))
+			}
+		})
+	}
+
+	results := ReadAllResults(e.ExecuteQuery(context.Background(), `SHOW SERIES EXACT CARDINALITY WHERE time > now() - 1h`, "db0", 0, orgID))
+	if len(results) != 1 || results[0].Err == nil || results[0].Err.Error() != "SHOW SERIES EXACT CARDINALITY doesn't support time in WHERE clause" {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowQueries(t *testing.T) {
+	orgID := influxdb.ID(0xff00)
+	queries := mock.NewRunningQueryService()
+	queries.FindRunningQueriesFn = func(_ context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+		if filter.OrgID == nil || *filter.OrgID != orgID {
+			t.Fatalf("unexpected filter: %+v", filter)
+		}
+		return []*influxdb.RunningQuery{
+			{ID: 1, OrgID: orgID, UserID: 0xfe00, AuthorizationID: 0xfd00, Language: "influxql", Query: "SELECT * FROM cpu", Database: "db0", Duration: 1500 * time.Millisecond, State: "running"},
+			{ID: 2, OrgID: orgID, Language: "flux", Query: `from(bucket: "b")`, Duration: 1500 * time.Microsecond, State: "executing"},
+		}, nil
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		RunningQueryService: queries,
+	}
+
+	results := ReadAllResults(qe.ExecuteQuery(context.Background(), MustParseQuery("SHOW QUERIES"), query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Columns: []string{"qid", "query", "database", "duration", "status", "language", "user_id", "token_id"},
+				Values: [][]interface{}{
+					{uint64(1), "SELECT * FROM cpu", "db0", "1s", "running", "influxql", "000000000000fe00", "000000000000fd00"},
+					{uint64(2), `from(bucket: "b")`, "", "1ms", "executing", "flux", "", ""},
+				},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_KillQuery(t *testing.T) {
+	orgID := influxdb.ID(0xff00)
+	var killed []uint64
+	queries := mock.NewRunningQueryService()
+	queries.FindRunningQueriesFn = func(_ context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+		if filter.OrgID == nil || *filter.OrgID != orgID || filter.ID == nil {
+			t.Fatalf("unexpected filter: %+v", filter)
+		}
+		if *filter.ID != 1 {
+			return nil, nil
+		}
+		return []*influxdb.RunningQuery{{ID: 1, OrgID: orgID}}, nil
+	}
+	queries.KillQueryFn = func(_ context.Context, id uint64) error {
+		killed = append(killed, id)
+		return nil
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		RunningQueryService: queries,
+	}
+
+	results := ReadAllResults(qe.ExecuteQuery(context.Background(), MustParseQuery("KILL QUERY 1"), query.ExecutionOptions{OrgID: orgID}))
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+	if !reflect.DeepEqual(killed, []uint64{1}) {
+		t.Fatalf("unexpected killed queries: %v", killed)
+	}
+
+	results = ReadAllResults(qe.ExecuteQuery(context.Background(), MustParseQuery("KILL QUERY 2"), query.ExecutionOptions{OrgID: orgID}))
+	if len(results) != 1 || results[0].Err == nil || results[0].Err.Error() != "no such query id: 2" {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_Subscriptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgID := influxdb.ID(0xff00)
+	mappings := []*influxdb.DBRPMappingV2{
+		{ID: 1, Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: 0xffe0},
+		{ID: 2, Database: "db1", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: 0xffe1},
+	}
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, filter influxdb.DBRPMappingFilterV2) ([]*influxdb.DBRPMappingV2, int, error) {
+			var found []*influxdb.DBRPMappingV2
+			for _, m := range mappings {
+				if filter.Database != nil && *filter.Database != m.Database {
+					continue
+				}
+				if filter.RetentionPolicy != nil && *filter.RetentionPolicy != m.RetentionPolicy {
+					continue
+				}
+				found = append(found, m)
+			}
+			return found, len(found), nil
+		}).
+		AnyTimes()
+
+	var subs []*influxdb.Subscription
+	subscriptions := mock.NewSubscriptionService()
+	subscriptions.FindSubscriptionsFn = func(_ context.Context, filter influxdb.SubscriptionFilter) ([]*influxdb.Subscription, int, error) {
+		var found []*influxdb.Subscription
+		for _, sub := range subs {
+			if filter.BucketID != nil && *filter.BucketID != sub.BucketID {
+				continue
+			}
+			if filter.Name != nil && *filter.Name != sub.Name {
+				continue
+			}
+			found = append(found, sub)
+		}
+		return found, len(found), nil
+	}
+	subscriptions.CreateSubscriptionFn = func(_ context.Context, sub *influxdb.Subscription) error {
+		sub.ID = influxdb.ID(len(subs) + 1)
+		subs = append(subs, sub)
+		return nil
+	}
+	subscriptions.DeleteSubscriptionFn = func(_ context.Context, id influxdb.ID) error {
+		for i, sub := range subs {
+			if sub.ID == id {
+				subs = append(subs[:i], subs[i+1:]...)
+				return nil
+			}
+		}
+		return influxdb.ErrSubscriptionNotFound
+	}
+
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP:                dbrp,
+		SubscriptionService: subscriptions,
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID}},
+		},
+	})
+	opts := query.ExecutionOptions{OrgID: orgID}
+
+	results := ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery(`CREATE SUBSCRIPTION "s0" ON "db0"."rp0" DESTINATIONS ANY 'http://h1:9092', 'http://h2:9092'`), opts))
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+	exp := &influxdb.Subscription{
+		ID:              1,
+		OrgID:           orgID,
+		BucketID:        0xffe0,
+		Name:            "s0",
+		Mode:            influxdb.SubscriptionModeAny,
+		Destinations:    []string{"http://h1:9092", "http://h2:9092"},
+		Database:        "db0",
+		RetentionPolicy: "rp0",
+	}
+	if len(subs) != 1 || !reflect.DeepEqual(subs[0], exp) {
+		t.Fatalf("unexpected subscriptions: exp %s, got %s", spew.Sdump(exp), spew.Sdump(subs))
+	}
+
+	results = ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery(`CREATE SUBSCRIPTION "s1" ON "db2"."rp0" DESTINATIONS ALL 'udp://h1:9093'`), opts))
+	if len(results) != 1 || results[0].Err == nil || results[0].Err.Error() != meta.ErrRetentionPolicyNotFound.Error() {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+
+	results = ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery("SHOW SUBSCRIPTIONS"), opts))
+	expResults := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "db0",
+				Columns: []string{"retention_policy", "name", "mode", "destinations"},
+				Values: [][]interface{}{
+					{"rp0", "s0", "ANY", []string{"http://h1:9092", "http://h2:9092"}},
+				},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(results, expResults) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(expResults), spew.Sdump(results))
+	}
+
+	results = ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery(`DROP SUBSCRIPTION "s0" ON "db1"."rp0"`), opts))
+	if len(results) != 1 || results[0].Err == nil || results[0].Err.Error() != meta.ErrSubscriptionNotFound.Error() {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+
+	results = ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery(`DROP SUBSCRIPTION "s0" ON "db0"."rp0"`), opts))
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+	if len(subs) != 0 {
+		t.Fatalf("unexpected subscriptions: %s", spew.Sdump(subs))
+	}
+}
+
+// NewQueryExecutor returns a new instance of Executor.
+// This query executor always has a node id of 0.
+func NewQueryExecutor(t *testing.T, opts ...optFn) *QueryExecutor {
+	e := &QueryExecutor{
+		Executor:  query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{})),
+		TSDBStore: &internal.TSDBStoreMock{},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	e.TSDBStore.CreateShardFn = func(database, policy string, shardID uint64, enabled bool) error {
+		return nil
+	}
+
+	e.TSDBStore.MeasurementNamesFn = func(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error) {
+		return nil, nil
+	}
+
+	e.TSDBStore.TagValuesFn = func(_ query.Authorizer, _ []uint64, _ influxql.Expr) ([]tsdb.TagValues, error) {
+		return nil, nil
+	}
+
+	e.StatementExecutor = &coordinator.StatementExecutor{
+		MetaClient: &e.MetaClient,
+		TSDBStore:  e.TSDBStore,
+		DBRP:       e.DBRP,
+		ShardMapper: &coordinator.LocalShardMapper{
+			MetaClient: &e.MetaClient,
+			TSDBStore:  e.TSDBStore,
+			DBRP:       e.DBRP,
+		},
+	}
+	e.Executor.StatementExecutor = e.StatementExecutor
+
+	return e
+}
+
+type optFn func(qe *QueryExecutor)
+
+func WithDBRP(dbrp *mocks.MockDBRPMappingServiceV2) optFn {
+	return func(qe *QueryExecutor) {
+		qe.DBRP = dbrp
+	}
+}
+
+// DefaultQueryExecutor returns a Executor with a database (db0) and retention policy (rp0).
+func DefaultQueryExecutor(t *testing.T, opts ...optFn) *QueryExecutor {
+	e := NewQueryExecutor(t, opts...)
+	e.MetaClient.DatabaseFn = DefaultMetaClientDatabaseFn
+	return e
+}
+
+// ExecuteQuery parses query and executes against the database.
+func (e *QueryExecutor) ExecuteQuery(ctx context.Context, q, database string, chunkSize int, orgID influxdb.ID) (<-chan *query.Result, *influxql2.Statistics) {
+	return e.Executor.ExecuteQuery(ctx, MustParseQuery(q), query.ExecutionOptions{
+		OrgID:     orgID,
+		Database:  database,
+		ChunkSize: chunkSize,
+	})
+}
+
+type MockShard struct {
+	Measurements             []string
+	FieldDimensionsFn        func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error)
+	FieldKeysByMeasurementFn func(name []byte) []string
+	CreateIteratorFn         func(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error)
+	IteratorCostFn           func(m string, opt query.IteratorOptions) (query.IteratorCost, error)
+	ExpandSourcesFn          func(sources influxql.Sources) (influxql.Sources, error)
+}
+
+func (sh *MockShard) MeasurementsByRegex(re *regexp.Regexp) []string {
+	names := make([]string, 0, len(sh.Measurements))
+	for _, name := range sh.Measurements {
+		if re.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (sh *MockShard) FieldKeysByMeasurement(name []byte) []string {
+	return sh.FieldKeysByMeasurementFn(name)
+}
+
+func (sh *MockShard) FieldDimensions(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+	return sh.FieldDimensionsFn(measurements)
+}
+
+func (sh *MockShard) MapType(measurement, field string) influxql.DataType {
+	switch field {
+	case "_name", "_tagKey", "_tagValue", "_seriesKey":
+		return influxql.String
+	}
+
+	f, d, err := sh.FieldDimensions([]string{measurement})
+	if err != nil {
+		return influxql.Unknown
+	}
+
+	if typ, ok := f[field]; ok {
+		return typ
+	} else if _, ok := d[field]; ok {
+		return influxql.Tag
+	}
+	return influxql.Unknown
+}
+
+func (sh *MockShard) CreateIterator(ctx context.Context, measurement *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+	return sh.CreateIteratorFn(ctx, measurement, opt)
+}
+
+func (sh *MockShard) IteratorCost(measurement string, opt query.IteratorOptions) (query.IteratorCost, error) {
+	return sh.IteratorCostFn(measurement, opt)
+}
+
+func (sh *MockShard) ExpandSources(sources influxql.Sources) (influxql.Sources, error) {
+	return sh.ExpandSourcesFn(sources)
+}
+
+// MustParseQuery parses s into a query. Panic on error.
+func MustParseQuery(s string) *influxql.Query {
+	q, err := influxql.ParseQuery(s)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// ReadAllResults reads all results from c and returns as a slice.
+func ReadAllResults(c <-chan *query.Result, _ *influxql2.Statistics) []*query.Result {
+	var a []*query.Result
+	for result := range c {
+		a = append(a, result)
+	}
+	return a
+}
+
+// FloatIterator is a represents an iterator that reads from a slice.
+type FloatIterator struct {
+	Points []query.FloatPoint
+	stats  query.IteratorStats
+}
+
+func (itr *FloatIterator) Stats() query.IteratorStats { return itr.stats }
+func (itr *FloatIterator) Close() error               { return nil }
+
+// Next returns the next value and shifts it off the beginning of the points slice.
 func (itr *FloatIterator) Next() (*query.FloatPoint, error) {
 	if len(itr.Points) == 0 {
 		return nil, nil
@@ -549,3 +2032,23 @@ func (itr *FloatIterator) Next() (*query.FloatPoint, error) {
 	itr.Points = itr.Points[1:]
 	return v, nil
 }
+
+// StringIterator is a represents an iterator that reads from a slice.
+type StringIterator struct {
+	Points []query.StringPoint
+	stats  query.IteratorStats
+}
+
+func (itr *StringIterator) Stats() query.IteratorStats { return itr.stats }
+func (itr *StringIterator) Close() error               { return nil }
+
+// Next returns the next value and shifts it off the beginning of the points slice.
+func (itr *StringIterator) Next() (*query.StringPoint, error) {
+	if len(itr.Points) == 0 {
+		return nil, nil
+	}
+
+	v := &itr.Points[0]
+	itr.Points = itr.Points[1:]
+	return v, nil
+}
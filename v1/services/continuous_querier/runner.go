@@ -0,0 +1,58 @@
+package continuous_querier
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	iql "github.com/influxdata/influxdb/v2/influxql"
+	"github.com/influxdata/influxdb/v2/influxql/query"
+	"github.com/influxdata/influxql"
+)
+
+// QueryExecutor executes InfluxQL queries.
+type QueryExecutor interface {
+	ExecuteQuery(ctx context.Context, q *influxql.Query, opt query.ExecutionOptions) (<-chan *query.Result, *iql.Statistics)
+}
+
+// Runner runs the continuous query of a task.
+type Runner struct {
+	QueryExecutor QueryExecutor
+}
+
+// NewRunner returns a Runner which executes continuous queries with qe.
+func NewRunner(qe QueryExecutor) *Runner {
+	return &Runner{QueryExecutor: qe}
+}
+
+// RunTask executes the continuous query of t for the run scheduled at
+// scheduledFor, which runs after the offset of the task. The authorizer of ctx is used to access the source and
+// target databases.
+func (r *Runner) RunTask(ctx context.Context, t *influxdb.Task, scheduledFor time.Time) error {
+	cq, err := Statement(t)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := Query(cq, scheduledFor.Add(t.Offset))
+	if err != nil {
+		return err
+	} else if stmt == nil {
+		return nil
+	}
+
+	results, _ := r.QueryExecutor.ExecuteQuery(ctx, &influxql.Query{Statements: influxql.Statements{stmt}}, query.ExecutionOptions{
+		OrgID:      t.OrganizationID,
+		Database:   cq.Database,
+		Authorizer: query.OpenAuthorizer,
+		Quiet:      true,
+	})
+
+	// Drain all results so that the executor can finish.
+	for res := range results {
+		if res.Err != nil && err == nil {
+			err = res.Err
+		}
+	}
+	return err
+}
@@ -0,0 +1,109 @@
+package continuous_querier_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	iql "github.com/influxdata/influxdb/v2/influxql"
+	"github.com/influxdata/influxdb/v2/influxql/query"
+	"github.com/influxdata/influxdb/v2/v1/services/continuous_querier"
+	"github.com/influxdata/influxql"
+)
+
+func TestRunner_RunTask(t *testing.T) {
+	stmt := MustParseContinuousQuery(`CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h) END`)
+	tc, err := continuous_querier.NewTaskCreate(1, 2, stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := &influxdb.Task{ID: 3, Type: tc.Type, OrganizationID: 1, Metadata: tc.Metadata}
+
+	var executed *influxql.Query
+	var opts query.ExecutionOptions
+	qe := QueryExecutorFn(func(ctx context.Context, q *influxql.Query, opt query.ExecutionOptions) (<-chan *query.Result, *iql.Statistics) {
+		executed, opts = q, opt
+		results := make(chan *query.Result, 1)
+		results <- &query.Result{}
+		close(results)
+		return results, &iql.Statistics{}
+	})
+
+	r := continuous_querier.NewRunner(qe)
+	if err := r.RunTask(context.Background(), task, mustParseTime("2000-01-01T10:00:00Z")); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE time >= '2000-01-01T09:00:00Z' AND time < '2000-01-01T10:00:00Z' GROUP BY time(1h)`
+	if executed == nil {
+		t.Fatal("expected query to be executed")
+	} else if got := executed.String(); got != exp {
+		t.Errorf("unexpected query:\ngot %s\nexp %s", got, exp)
+	}
+	if opts.OrgID != 1 || opts.Database != "db0" {
+		t.Errorf("unexpected execution options: %+v", opts)
+	}
+}
+
+func TestRunner_RunTask_Offset(t *testing.T) {
+	stmt := MustParseContinuousQuery(`CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h, 15m) END`)
+	tc, err := continuous_querier.NewTaskCreate(1, 2, stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := &influxdb.Task{ID: 3, Type: tc.Type, OrganizationID: 1, Offset: 15 * time.Minute, Metadata: tc.Metadata}
+
+	var executed *influxql.Query
+	qe := QueryExecutorFn(func(ctx context.Context, q *influxql.Query, opt query.ExecutionOptions) (<-chan *query.Result, *iql.Statistics) {
+		executed = q
+		results := make(chan *query.Result)
+		close(results)
+		return results, &iql.Statistics{}
+	})
+
+	// The run scheduled at 10:00 runs at 10:15 and covers the interval
+	// which just ended.
+	r := continuous_querier.NewRunner(qe)
+	if err := r.RunTask(context.Background(), task, mustParseTime("2000-01-01T10:00:00Z")); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE time >= '2000-01-01T09:15:00Z' AND time < '2000-01-01T10:15:00Z' GROUP BY time(1h, 15m)`
+	if executed == nil {
+		t.Fatal("expected query to be executed")
+	} else if got := executed.String(); got != exp {
+		t.Errorf("unexpected query:\ngot %s\nexp %s", got, exp)
+	}
+}
+
+func TestRunner_RunTask_Error(t *testing.T) {
+	stmt := MustParseContinuousQuery(`CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h) END`)
+	tc, err := continuous_querier.NewTaskCreate(1, 2, stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := &influxdb.Task{ID: 3, Type: tc.Type, OrganizationID: 1, Metadata: tc.Metadata}
+
+	expErr := errors.New("marker")
+	qe := QueryExecutorFn(func(ctx context.Context, q *influxql.Query, opt query.ExecutionOptions) (<-chan *query.Result, *iql.Statistics) {
+		results := make(chan *query.Result, 2)
+		results <- &query.Result{Err: expErr}
+		results <- &query.Result{Err: query.ErrNotExecuted}
+		close(results)
+		return results, &iql.Statistics{}
+	})
+
+	r := continuous_querier.NewRunner(qe)
+	if err := r.RunTask(context.Background(), task, mustParseTime("2000-01-01T10:00:00Z")); err != expErr {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// QueryExecutorFn adapts a function to a continuous_querier.QueryExecutor.
+type QueryExecutorFn func(ctx context.Context, q *influxql.Query, opt query.ExecutionOptions) (<-chan *query.Result, *iql.Statistics)
+
+func (fn QueryExecutorFn) ExecuteQuery(ctx context.Context, q *influxql.Query, opt query.ExecutionOptions) (<-chan *query.Result, *iql.Statistics) {
+	return fn(ctx, q, opt)
+}
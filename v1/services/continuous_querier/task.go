@@ -0,0 +1,157 @@
+// Package continuous_querier maps InfluxQL continuous queries onto tasks.
+package continuous_querier // import "github.com/influxdata/influxdb/v2/v1/services/continuous_querier"
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxql"
+)
+
+// TaskType is the type of the tasks which run continuous queries.
+const TaskType = "continuous_query"
+
+// Metadata keys of a continuous query task.
+const (
+	// MetadataDatabase is the database the continuous query was created on.
+	MetadataDatabase = "database"
+
+	// MetadataQuery is the CREATE CONTINUOUS QUERY statement of the task.
+	MetadataQuery = "query"
+)
+
+// ErrInvalidTask is returned when a task does not hold a continuous query.
+var ErrInvalidTask = errors.New("task is not a continuous query")
+
+// NewTaskCreate returns the TaskCreate for the continuous query, to be owned
+// by ownerID within the organization orgID.
+//
+// The continuous query is stored in the task metadata, while the Flux script
+// of the task only carries the schedule. The task runs every RESAMPLE EVERY
+// interval or, if unset, every GROUP BY time interval, delayed by the GROUP BY
+// time offset.
+func NewTaskCreate(orgID, ownerID influxdb.ID, stmt *influxql.CreateContinuousQueryStatement) (influxdb.TaskCreate, error) {
+	interval, err := stmt.Source.GroupByInterval()
+	if err != nil {
+		return influxdb.TaskCreate{}, err
+	} else if interval <= 0 {
+		return influxdb.TaskCreate{}, errors.New("continuous query requires a GROUP BY time interval")
+	}
+
+	offset, err := stmt.Source.GroupByOffset()
+	if err != nil {
+		return influxdb.TaskCreate{}, err
+	}
+
+	every := interval
+	if stmt.ResampleEvery > 0 {
+		every = stmt.ResampleEvery
+	}
+
+	opts := fmt.Sprintf("name: %s, every: %s", strconv.Quote(stmt.Name), influxql.FormatDuration(every))
+	if offset > 0 {
+		opts += fmt.Sprintf(", offset: %s", influxql.FormatDuration(offset))
+	}
+
+	return influxdb.TaskCreate{
+		Type:           TaskType,
+		Flux:           fmt.Sprintf("option task = {%s}\n", opts),
+		Description:    fmt.Sprintf("Continuous query %s on database %s", stmt.Name, stmt.Database),
+		OrganizationID: orgID,
+		OwnerID:        ownerID,
+		Metadata: map[string]interface{}{
+			MetadataDatabase: stmt.Database,
+			MetadataQuery:    stmt.String(),
+		},
+	}, nil
+}
+
+// IsContinuousQuery reports whether t runs a continuous query.
+func IsContinuousQuery(t *influxdb.Task) bool {
+	return t.Type == TaskType
+}
+
+// Database returns the database the continuous query of t was created on.
+func Database(t *influxdb.Task) string {
+	db, _ := t.Metadata[MetadataDatabase].(string)
+	return db
+}
+
+// Statement returns the continuous query stored in t.
+func Statement(t *influxdb.Task) (*influxql.CreateContinuousQueryStatement, error) {
+	if !IsContinuousQuery(t) {
+		return nil, ErrInvalidTask
+	}
+
+	q, ok := t.Metadata[MetadataQuery].(string)
+	if !ok {
+		return nil, ErrInvalidTask
+	}
+
+	stmt, err := influxql.ParseStatement(q)
+	if err != nil {
+		return nil, err
+	}
+
+	cq, ok := stmt.(*influxql.CreateContinuousQueryStatement)
+	if !ok {
+		return nil, ErrInvalidTask
+	}
+	return cq, nil
+}
+
+// Query returns the SELECT statement of the continuous query, restricted to
+// the time range covered by a run at now. A nil statement is returned if the
+// run covers no complete interval.
+func Query(cq *influxql.CreateContinuousQueryStatement, now time.Time) (*influxql.SelectStatement, error) {
+	interval, err := cq.Source.GroupByInterval()
+	if err != nil {
+		return nil, err
+	} else if interval <= 0 {
+		return nil, errors.New("continuous query requires a GROUP BY time interval")
+	}
+
+	offset, err := cq.Source.GroupByOffset()
+	if err != nil {
+		return nil, err
+	}
+
+	resampleEvery := interval
+	if cq.ResampleEvery > 0 {
+		resampleEvery = cq.ResampleEvery
+	}
+
+	// The query covers the last interval unless told otherwise. If the query
+	// runs less often than the interval, it must cover the time since the
+	// previous run.
+	resampleFor := interval
+	if cq.ResampleFor > 0 {
+		resampleFor = cq.ResampleFor
+	} else if interval < resampleEvery {
+		resampleFor = resampleEvery
+	}
+
+	// A query running more often than the interval also covers the interval
+	// in progress, while any other query ends at the current interval.
+	lag := resampleEvery
+	if lag > interval {
+		lag = interval
+	}
+
+	// The query ends at the last interval boundary, which is shifted by the
+	// offset, and starts at the boundary that leaves room for resampleFor.
+	end := now.Add(interval - lag - offset).Truncate(interval).Add(offset)
+	start := end.Add(-resampleFor - offset).Truncate(interval).Add(offset)
+	if !end.After(start) {
+		return nil, nil
+	}
+
+	q := cq.Source.Clone()
+	if err := q.SetTimeRange(start, end); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
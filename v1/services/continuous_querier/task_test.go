@@ -0,0 +1,162 @@
+package continuous_querier_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/v1/services/continuous_querier"
+	"github.com/influxdata/influxql"
+)
+
+func TestNewTaskCreate(t *testing.T) {
+	tests := []struct {
+		name string
+		q    string
+		flux string
+	}{
+		{
+			name: "group by interval",
+			q:    `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h) END`,
+			flux: "option task = {name: \"cq0\", every: 1h}\n",
+		},
+		{
+			name: "resample every",
+			q:    `CREATE CONTINUOUS QUERY cq0 ON db0 RESAMPLE EVERY 10m FOR 2h BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h) END`,
+			flux: "option task = {name: \"cq0\", every: 10m}\n",
+		},
+		{
+			name: "group by offset",
+			q:    `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h, 15m) END`,
+			flux: "option task = {name: \"cq0\", every: 1h, offset: 15m}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := MustParseContinuousQuery(tt.q)
+
+			tc, err := continuous_querier.NewTaskCreate(1, 2, stmt)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, exp := tc.Flux, tt.flux; got != exp {
+				t.Errorf("unexpected flux: got %q, exp %q", got, exp)
+			}
+			if got, exp := tc.Type, continuous_querier.TaskType; got != exp {
+				t.Errorf("unexpected type: got %q, exp %q", got, exp)
+			}
+			if tc.OrganizationID != 1 || tc.OwnerID != 2 {
+				t.Errorf("unexpected org or owner: %v, %v", tc.OrganizationID, tc.OwnerID)
+			}
+
+			task := &influxdb.Task{Type: tc.Type, Metadata: tc.Metadata}
+			if got, exp := continuous_querier.Database(task), "db0"; got != exp {
+				t.Errorf("unexpected database: got %q, exp %q", got, exp)
+			}
+			cq, err := continuous_querier.Statement(task)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, exp := cq.String(), stmt.String(); got != exp {
+				t.Errorf("unexpected statement: got %q, exp %q", got, exp)
+			}
+		})
+	}
+}
+
+func TestStatement_InvalidTask(t *testing.T) {
+	if _, err := continuous_querier.Statement(&influxdb.Task{Type: "system"}); err != continuous_querier.ErrInvalidTask {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := continuous_querier.Statement(&influxdb.Task{Type: continuous_querier.TaskType}); err != continuous_querier.ErrInvalidTask {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	now := mustParseTime("2000-01-01T10:00:00Z")
+
+	tests := []struct {
+		name string
+		q    string
+		now  time.Time
+		exp  string
+	}{
+		{
+			name: "previous interval",
+			q:    `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h) END`,
+			now:  now,
+			exp:  `SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE time >= '2000-01-01T09:00:00Z' AND time < '2000-01-01T10:00:00Z' GROUP BY time(1h)`,
+		},
+		{
+			name: "resample for",
+			q:    `CREATE CONTINUOUS QUERY cq0 ON db0 RESAMPLE FOR 3h BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h) END`,
+			now:  now,
+			exp:  `SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE time >= '2000-01-01T07:00:00Z' AND time < '2000-01-01T10:00:00Z' GROUP BY time(1h)`,
+		},
+		{
+			name: "resample every longer than interval",
+			q:    `CREATE CONTINUOUS QUERY cq0 ON db0 RESAMPLE EVERY 2h BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h) END`,
+			now:  now,
+			exp:  `SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE time >= '2000-01-01T08:00:00Z' AND time < '2000-01-01T10:00:00Z' GROUP BY time(1h)`,
+		},
+		{
+			name: "existing condition",
+			q:    `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE host = 'a' GROUP BY time(1h) END`,
+			now:  now,
+			exp:  `SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE host = 'a' AND time >= '2000-01-01T09:00:00Z' AND time < '2000-01-01T10:00:00Z' GROUP BY time(1h)`,
+		},
+		{
+			name: "offset",
+			q:    `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h, 15m) END`,
+			now:  now.Add(15 * time.Minute),
+			exp:  `SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE time >= '2000-01-01T09:15:00Z' AND time < '2000-01-01T10:15:00Z' GROUP BY time(1h, 15m)`,
+		},
+		{
+			name: "offset between boundaries",
+			q:    `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h, 15m) END`,
+			now:  now,
+			exp:  `SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE time >= '2000-01-01T08:15:00Z' AND time < '2000-01-01T09:15:00Z' GROUP BY time(1h, 15m)`,
+		},
+		{
+			name: "offset resample every",
+			q:    `CREATE CONTINUOUS QUERY cq0 ON db0 RESAMPLE EVERY 10m BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h, 15m) END`,
+			now:  now.Add(25 * time.Minute),
+			exp:  `SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE time >= '2000-01-01T10:15:00Z' AND time < '2000-01-01T11:15:00Z' GROUP BY time(1h, 15m)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := continuous_querier.Query(MustParseContinuousQuery(tt.q), tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q == nil {
+				t.Fatal("expected a query")
+			}
+			if got := q.String(); got != tt.exp {
+				t.Errorf("unexpected query:\ngot %s\nexp %s", got, tt.exp)
+			}
+		})
+	}
+}
+
+// MustParseContinuousQuery parses s into a continuous query. Panic on error.
+func MustParseContinuousQuery(s string) *influxql.CreateContinuousQueryStatement {
+	stmt, err := influxql.ParseStatement(s)
+	if err != nil {
+		panic(err)
+	}
+	return stmt.(*influxql.CreateContinuousQueryStatement)
+}
+
+func mustParseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -1298,8 +1298,8 @@ func (rpi *RetentionPolicyInfo) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
-// shardGroupDuration returns the default duration for a shard group based on a policy duration.
-func shardGroupDuration(d time.Duration) time.Duration {
+// ShardGroupDuration returns the default duration for a shard group based on a policy duration.
+func ShardGroupDuration(d time.Duration) time.Duration {
 	if d >= 180*24*time.Hour || d == 0 { // 6 months or 0
 		return 7 * 24 * time.Hour
 	} else if d >= 2*24*time.Hour { // 2 days
@@ -1312,12 +1312,12 @@ func shardGroupDuration(d time.Duration) time.Duration {
 func normalisedShardDuration(sgd, d time.Duration) time.Duration {
 	// If it is zero, it likely wasn't specified, so we default to the shard group duration
 	if sgd == 0 {
-		return shardGroupDuration(d)
+		return ShardGroupDuration(d)
 	}
 	// If it was specified, but it's less than the MinRetentionPolicyDuration, then normalize
 	// to the MinRetentionPolicyDuration
 	if sgd < MinRetentionPolicyDuration {
-		return shardGroupDuration(MinRetentionPolicyDuration)
+		return ShardGroupDuration(MinRetentionPolicyDuration)
 	}
 	return sgd
 }
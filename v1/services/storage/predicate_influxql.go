@@ -123,7 +123,7 @@ func (v *hasAnyTagKeys) Visit(node influxql.Node) influxql.Visitor {
 	}
 
 	if n, ok := node.(*influxql.VarRef); ok {
-		if n.Val != fieldKey && n.Val != measurementKey && n.Val != "$" {
+		if n.Val != fieldKey && n.Val != measurementKey && n.Val != measurementRemap[measurementKey] && n.Val != "$" {
 			v.found = true
 			return nil
 		}
@@ -238,14 +238,63 @@ type metaqueryAttributes struct {
 	db, rp     string
 	start, end int64
 	pred       influxql.Expr
+
+	// coveredShardIDs are the shards of the shard groups within the range,
+	// and partialShardIDs those of the shard groups partially within it. The
+	// index of a shard holds the series with data anywhere in the shard, so
+	// the data of the partially covered shards must be read to find the
+	// series with data in the range.
+	coveredShardIDs, partialShardIDs []uint64
+}
+
+// shardIDs returns the IDs of all the shards of the range.
+func (mqAttrs *metaqueryAttributes) shardIDs() []uint64 {
+	shardIDs := make([]uint64, 0, len(mqAttrs.coveredShardIDs)+len(mqAttrs.partialShardIDs))
+	shardIDs = append(shardIDs, mqAttrs.coveredShardIDs...)
+	return append(shardIDs, mqAttrs.partialShardIDs...)
+}
+
+// metaqueryValues returns the sorted union of the values found by indexed with
+// the index of the shards covered by the range, and of those found by slow
+// reading the data of the shards partially covered. If the index cannot be
+// used, slow reads the data of all the shards.
+func metaqueryValues(mqAttrs *metaqueryAttributes, useIndex bool, indexed, slow func(shardIDs []uint64) ([]string, error)) (cursors.StringIterator, error) {
+	coveredShardIDs, partialShardIDs := mqAttrs.coveredShardIDs, mqAttrs.partialShardIDs
+	if !useIndex {
+		coveredShardIDs, partialShardIDs = nil, mqAttrs.shardIDs()
+	}
+
+	var indexedValues, slowValues []string
+	if len(coveredShardIDs) > 0 {
+		values, err := indexed(coveredShardIDs)
+		if err != nil {
+			return nil, err
+		}
+		indexedValues = values
+	}
+	if len(partialShardIDs) > 0 {
+		values, err := slow(partialShardIDs)
+		if err != nil {
+			return nil, err
+		}
+		slowValues = values
+	}
+
+	values := slices.MergeSortedStrings(indexedValues, slowValues)
+	if len(values) == 0 {
+		return cursors.EmptyStringIterator, nil
+	}
+	return cursors.NewStringSliceIterator(values), nil
 }
 
-func (s *Store) tagKeysWithFieldPredicate(ctx context.Context, mqAttrs *metaqueryAttributes, shardIDs []uint64) (cursors.StringIterator, error) {
+// tagKeysSlow returns the tag keys of the series of the shards with data in
+// the range, which are read rather than found with the index.
+func (s *Store) tagKeysSlow(ctx context.Context, mqAttrs *metaqueryAttributes, shardIDs []uint64) ([]string, error) {
 	var cur reads.SeriesCursor
 	if ic, err := newIndexSeriesCursorInfluxQLPred(ctx, mqAttrs.pred, s.TSDBStore.Shards(shardIDs)); err != nil {
 		return nil, err
 	} else if ic == nil {
-		return cursors.EmptyStringIterator, nil
+		return nil, nil
 	} else {
 		cur = ic
 	}
@@ -273,33 +322,25 @@ func (s *Store) tagKeysWithFieldPredicate(ctx context.Context, mqAttrs *metaquer
 		arr = append(arr, tag)
 	}
 	sort.Strings(arr)
-	return cursors.NewStringSliceIterator(arr), nil
+	return arr, nil
 }
 
-func (s *Store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error) {
-	if req.TagsSource == nil {
-		return nil, errors.New("missing read source")
-	}
-	source, err := getReadSource(*req.TagsSource)
-	if err != nil {
-		return nil, err
-	}
-	db, rp, start, end, err := s.validateArgs(source.OrganizationID, source.BucketID, req.Range.Start, req.Range.End)
+// newMetaqueryAttributes validates the source, range and predicate of a schema
+// request. If measurement is not empty, the predicate is restricted to the
+// series of the measurement.
+func (s *Store) newMetaqueryAttributes(src types.Any, rng datatypes.TimestampRange, predicate *datatypes.Predicate, measurement string) (*metaqueryAttributes, error) {
+	source, err := getReadSource(src)
 	if err != nil {
 		return nil, err
 	}
 
-	shardIDs, err := s.findShardIDs(db, rp, false, start, end)
+	db, rp, start, end, err := s.validateArgs(source.OrganizationID, source.BucketID, rng.Start, rng.End)
 	if err != nil {
 		return nil, err
 	}
-	if len(shardIDs) == 0 {
-		return cursors.EmptyStringIterator, nil
-	}
 
 	var expr influxql.Expr
-	if root := req.Predicate.GetRoot(); root != nil {
-		var err error
+	if root := predicate.GetRoot(); root != nil {
 		expr, err = reads.NodeToExpr(root, measurementRemap)
 		if err != nil {
 			return nil, err
@@ -308,28 +349,112 @@ func (s *Store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cur
 		if found := reads.HasFieldValueKey(expr); found {
 			return nil, errors.New("field values unsupported")
 		}
-		if found := reads.ExprHasKey(expr, fieldKey); found {
-			mqAttrs := &metaqueryAttributes{
-				orgID: source.GetOrgID(),
-				db:    db,
-				rp:    rp,
-				start: start,
-				end:   end,
-				pred:  expr,
-			}
-			return s.tagKeysWithFieldPredicate(ctx, mqAttrs, shardIDs)
-		}
+
 		expr = influxql.Reduce(influxql.CloneExpr(expr), nil)
 		if reads.IsTrueBooleanLiteral(expr) {
 			expr = nil
 		}
 	}
 
+	if measurement != "" {
+		measurementExpr := &influxql.BinaryExpr{
+			Op:  influxql.EQ,
+			LHS: &influxql.VarRef{Val: measurementRemap[measurementKey]},
+			RHS: &influxql.StringLiteral{Val: measurement},
+		}
+		if expr != nil {
+			expr = &influxql.BinaryExpr{
+				Op:  influxql.AND,
+				LHS: measurementExpr,
+				RHS: &influxql.ParenExpr{Expr: expr},
+			}
+		} else {
+			expr = measurementExpr
+		}
+	}
+
+	coveredShardIDs, partialShardIDs, err := s.findShardIDsByCoverage(db, rp, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metaqueryAttributes{
+		orgID:           source.GetOrgID(),
+		db:              db,
+		rp:              rp,
+		start:           start,
+		end:             end,
+		pred:            expr,
+		coveredShardIDs: coveredShardIDs,
+		partialShardIDs: partialShardIDs,
+	}, nil
+}
+
+// findShardIDsByCoverage returns the IDs of the shards of the shard groups
+// overlapping the range from start to end, inclusive, split between those of
+// the shard groups within the range and those of the shard groups partially
+// within it.
+func (s *Store) findShardIDsByCoverage(database, rp string, start, end int64) (covered, partial []uint64, err error) {
+	groups, err := s.MetaClient.ShardGroupsByTimeRange(database, rp, time.Unix(0, start), time.Unix(0, end))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Sort(meta.ShardGroupInfos(groups))
+
+	for _, g := range groups {
+		for _, si := range g.Shards {
+			if g.StartTime.UnixNano() < start || g.EndTime.UnixNano()-1 > end {
+				partial = append(partial, si.ID)
+			} else {
+				covered = append(covered, si.ID)
+			}
+		}
+	}
+	return covered, partial, nil
+}
+
+func (s *Store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error) {
+	if req.TagsSource == nil {
+		return nil, errors.New("missing read source")
+	}
+	mqAttrs, err := s.newMetaqueryAttributes(*req.TagsSource, req.Range, req.Predicate, "")
+	if err != nil {
+		return nil, err
+	}
+	return s.tagKeys(ctx, mqAttrs)
+}
+
+// MeasurementTagKeys returns the tag keys of the series of a measurement.
+func (s *Store) MeasurementTagKeys(ctx context.Context, req *datatypes.MeasurementTagKeysRequest) (cursors.StringIterator, error) {
+	if req.Source == nil {
+		return nil, errors.New("missing read source")
+	}
+	mqAttrs, err := s.newMetaqueryAttributes(*req.Source, req.Range, req.Predicate, req.Measurement)
+	if err != nil {
+		return nil, err
+	}
+	return s.tagKeys(ctx, mqAttrs)
+}
+
+func (s *Store) tagKeys(ctx context.Context, mqAttrs *metaqueryAttributes) (cursors.StringIterator, error) {
+	// If there are any references to _field, we need to use the slow path
+	// since we cannot rely on the index alone.
+	useIndex := mqAttrs.pred == nil || !reads.ExprHasKey(mqAttrs.pred, fieldKey)
+	return metaqueryValues(mqAttrs, useIndex, func(shardIDs []uint64) ([]string, error) {
+		return s.tagKeysIndexed(mqAttrs, shardIDs)
+	}, func(shardIDs []uint64) ([]string, error) {
+		return s.tagKeysSlow(ctx, mqAttrs, shardIDs)
+	})
+}
+
+// tagKeysIndexed returns the tag keys of the series of the shards, found with
+// their index.
+func (s *Store) tagKeysIndexed(mqAttrs *metaqueryAttributes, shardIDs []uint64) ([]string, error) {
 	// TODO(jsternberg): Use a real authorizer.
 	auth := query.OpenAuthorizer
-	keys, err := s.TSDBStore.TagKeys(auth, shardIDs, expr)
+	keys, err := s.TSDBStore.TagKeys(auth, shardIDs, mqAttrs.pred)
 	if err != nil {
-		return cursors.EmptyStringIterator, err
+		return nil, err
 	}
 
 	m := map[string]bool{
@@ -347,60 +472,44 @@ func (s *Store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cur
 		names = append(names, name)
 	}
 	sort.Strings(names)
-	return cursors.NewStringSliceIterator(names), nil
+	return names, nil
 }
 
 func (s *Store) TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error) {
 	if req.TagsSource == nil {
 		return nil, errors.New("missing read source")
 	}
-
-	source, err := getReadSource(*req.TagsSource)
+	mqAttrs, err := s.newMetaqueryAttributes(*req.TagsSource, req.Range, req.Predicate, "")
 	if err != nil {
 		return nil, err
 	}
+	return s.tagKeyValues(ctx, mqAttrs, req.TagKey)
+}
 
-	db, rp, start, end, err := s.validateArgs(source.OrganizationID, source.BucketID, req.Range.Start, req.Range.End)
+// MeasurementTagValues returns the values of a tag key of the series of a
+// measurement.
+func (s *Store) MeasurementTagValues(ctx context.Context, req *datatypes.MeasurementTagValuesRequest) (cursors.StringIterator, error) {
+	if req.Source == nil {
+		return nil, errors.New("missing read source")
+	}
+	mqAttrs, err := s.newMetaqueryAttributes(*req.Source, req.Range, req.Predicate, req.Measurement)
 	if err != nil {
 		return nil, err
 	}
+	return s.tagKeyValues(ctx, mqAttrs, req.TagKey)
+}
 
-	var influxqlPred influxql.Expr
-	if root := req.Predicate.GetRoot(); root != nil {
-		var err error
-		influxqlPred, err = reads.NodeToExpr(root, measurementRemap)
-		if err != nil {
-			return nil, err
-		}
-
-		if found := reads.HasFieldValueKey(influxqlPred); found {
-			return nil, errors.New("field values unsupported")
-		}
-
-		influxqlPred = influxql.Reduce(influxql.CloneExpr(influxqlPred), nil)
-		if reads.IsTrueBooleanLiteral(influxqlPred) {
-			influxqlPred = nil
-		}
-	}
-
-	mqAttrs := &metaqueryAttributes{
-		orgID: source.GetOrgID(),
-		db:    db,
-		rp:    rp,
-		start: start,
-		end:   end,
-		pred:  influxqlPred,
-	}
-
-	tagKey, ok := measurementRemap[req.TagKey]
-	if !ok {
-		tagKey = req.TagKey
+// tagKeyValues returns the values of tagKey, which may be the measurement or
+// field key.
+func (s *Store) tagKeyValues(ctx context.Context, mqAttrs *metaqueryAttributes, tagKey string) (cursors.StringIterator, error) {
+	if k, ok := measurementRemap[tagKey]; ok {
+		tagKey = k
 	}
 
 	// Getting values of _measurement or _field are handled specially
 	switch tagKey {
 	case "_name":
-		return s.MeasurementNames(ctx, mqAttrs)
+		return s.measurementNames(ctx, mqAttrs)
 
 	case "_field":
 		return s.measurementFields(ctx, mqAttrs)
@@ -412,20 +521,17 @@ func (s *Store) TagValues(ctx context.Context, req *datatypes.TagValuesRequest)
 func (s *Store) tagValues(ctx context.Context, mqAttrs *metaqueryAttributes, tagKey string) (cursors.StringIterator, error) {
 	// If there are any references to _field, we need to use the slow path
 	// since we cannot rely on the index alone.
-	if mqAttrs.pred != nil {
-		if hasFieldKey := reads.ExprHasKey(mqAttrs.pred, fieldKey); hasFieldKey {
-			return s.tagValuesSlow(ctx, mqAttrs, tagKey)
-		}
-	}
-
-	shardIDs, err := s.findShardIDs(mqAttrs.db, mqAttrs.rp, false, mqAttrs.start, mqAttrs.end)
-	if err != nil {
-		return nil, err
-	}
-	if len(shardIDs) == 0 {
-		return cursors.EmptyStringIterator, nil
-	}
+	useIndex := mqAttrs.pred == nil || !reads.ExprHasKey(mqAttrs.pred, fieldKey)
+	return metaqueryValues(mqAttrs, useIndex, func(shardIDs []uint64) ([]string, error) {
+		return s.tagValuesIndexed(mqAttrs, tagKey, shardIDs)
+	}, func(shardIDs []uint64) ([]string, error) {
+		return s.tagValuesSlow(ctx, mqAttrs, tagKey, shardIDs)
+	})
+}
 
+// tagValuesIndexed returns the values of tagKey of the series of the shards,
+// found with their index.
+func (s *Store) tagValuesIndexed(mqAttrs *metaqueryAttributes, tagKey string, shardIDs []uint64) ([]string, error) {
 	tagKeyExpr := &influxql.BinaryExpr{
 		Op: influxql.EQ,
 		LHS: &influxql.VarRef{
@@ -436,21 +542,21 @@ func (s *Store) tagValues(ctx context.Context, mqAttrs *metaqueryAttributes, tag
 		},
 	}
 
+	// The predicate of the slow path is left as is.
+	pred := influxql.Expr(tagKeyExpr)
 	if mqAttrs.pred != nil {
-		mqAttrs.pred = &influxql.BinaryExpr{
+		pred = &influxql.BinaryExpr{
 			Op:  influxql.AND,
 			LHS: tagKeyExpr,
 			RHS: &influxql.ParenExpr{
 				Expr: mqAttrs.pred,
 			},
 		}
-	} else {
-		mqAttrs.pred = tagKeyExpr
 	}
 
 	// TODO(jsternberg): Use a real authorizer.
 	auth := query.OpenAuthorizer
-	values, err := s.TSDBStore.TagValues(auth, shardIDs, mqAttrs.pred)
+	values, err := s.TSDBStore.TagValues(auth, shardIDs, pred)
 	if err != nil {
 		return nil, err
 	}
@@ -467,22 +573,59 @@ func (s *Store) tagValues(ctx context.Context, mqAttrs *metaqueryAttributes, tag
 		names = append(names, name)
 	}
 	sort.Strings(names)
-	return cursors.NewStringSliceIterator(names), nil
+	return names, nil
 }
 
-func (s *Store) MeasurementNames(ctx context.Context, mqAttrs *metaqueryAttributes) (cursors.StringIterator, error) {
-	if mqAttrs.pred != nil {
-		if hasFieldKey := reads.ExprHasKey(mqAttrs.pred, fieldKey); hasFieldKey {
-			// If there is a predicate on _field, we cannot use the index
-			// to filter out unwanted measurement names. Use a slower
-			// block scan instead.
-			return s.tagValuesSlow(ctx, mqAttrs, measurementKey)
+// MeasurementNames returns the names of the measurements of the series
+// matching the request.
+func (s *Store) MeasurementNames(ctx context.Context, req *datatypes.MeasurementNamesRequest) (cursors.StringIterator, error) {
+	if req.Source == nil {
+		return nil, errors.New("missing read source")
+	}
+	mqAttrs, err := s.newMetaqueryAttributes(*req.Source, req.Range, req.Predicate, "")
+	if err != nil {
+		return nil, err
+	}
+	return s.measurementNames(ctx, mqAttrs)
+}
+
+func (s *Store) measurementNames(ctx context.Context, mqAttrs *metaqueryAttributes) (cursors.StringIterator, error) {
+	// If there is a predicate on _field, we cannot use the index
+	// to filter out unwanted measurement names. Use a slower
+	// block scan instead.
+	useIndex := mqAttrs.pred == nil || !reads.ExprHasKey(mqAttrs.pred, fieldKey)
+	return metaqueryValues(mqAttrs, useIndex, func(shardIDs []uint64) ([]string, error) {
+		return s.measurementNamesIndexed(mqAttrs, shardIDs)
+	}, func(shardIDs []uint64) ([]string, error) {
+		return s.tagValuesSlow(ctx, mqAttrs, measurementKey, shardIDs)
+	})
+}
+
+// measurementNamesIndexed returns the names of the measurements of the series
+// of the shards, found with their index.
+func (s *Store) measurementNamesIndexed(mqAttrs *metaqueryAttributes, shardIDs []uint64) ([]string, error) {
+	// Only the index of the shards in the range is used, rather than the
+	// index of all the shards of the database.
+	shards := s.TSDBStore.Shards(shardIDs)
+	if len(shards) == 0 {
+		return nil, nil
+	}
+	sfile, err := shards[0].SeriesFile()
+	if err != nil {
+		return nil, err
+	}
+	is := tsdb.IndexSet{Indexes: make([]tsdb.Index, 0, len(shards)), SeriesFile: sfile}
+	for _, sh := range shards {
+		index, err := sh.Index()
+		if err != nil {
+			return nil, err
 		}
+		is.Indexes = append(is.Indexes, index)
 	}
 
 	// TODO(jsternberg): Use a real authorizer.
 	auth := query.OpenAuthorizer
-	values, err := s.TSDBStore.MeasurementNames(auth, mqAttrs.db, mqAttrs.pred)
+	values, err := is.DedupeInmemIndexes().MeasurementNamesByExpr(auth, mqAttrs.pred)
 	if err != nil {
 		return nil, err
 	}
@@ -497,7 +640,7 @@ func (s *Store) MeasurementNames(ctx context.Context, mqAttrs *metaqueryAttribut
 		names = append(names, name)
 	}
 	sort.Strings(names)
-	return cursors.NewStringSliceIterator(names), nil
+	return names, nil
 }
 
 func (s *Store) GetSource(orgID, bucketID uint64) proto.Message {
@@ -507,27 +650,33 @@ func (s *Store) GetSource(orgID, bucketID uint64) proto.Message {
 	}
 }
 
-func (s *Store) measurementFields(ctx context.Context, mqAttrs *metaqueryAttributes) (cursors.StringIterator, error) {
-	if mqAttrs.pred != nil {
-		if hasFieldKey := reads.ExprHasKey(mqAttrs.pred, fieldKey); hasFieldKey {
-			return s.tagValuesSlow(ctx, mqAttrs, fieldKey)
-		}
-
-		// If there predicates on anything besides _measurement, we can't
-		// use the index and need to use the slow path.
-		if hasTagKey(mqAttrs.pred) {
-			return s.tagValuesSlow(ctx, mqAttrs, fieldKey)
-		}
+// MeasurementFields returns the field keys of a measurement, or of all
+// measurements if none is given.
+func (s *Store) MeasurementFields(ctx context.Context, req *datatypes.MeasurementFieldsRequest) (cursors.StringIterator, error) {
+	if req.Source == nil {
+		return nil, errors.New("missing read source")
 	}
-
-	shardIDs, err := s.findShardIDs(mqAttrs.db, mqAttrs.rp, false, mqAttrs.start, mqAttrs.end)
+	mqAttrs, err := s.newMetaqueryAttributes(*req.Source, req.Range, req.Predicate, req.Measurement)
 	if err != nil {
 		return nil, err
 	}
-	if len(shardIDs) == 0 {
-		return cursors.EmptyStringIterator, nil
-	}
+	return s.measurementFields(ctx, mqAttrs)
+}
+
+func (s *Store) measurementFields(ctx context.Context, mqAttrs *metaqueryAttributes) (cursors.StringIterator, error) {
+	// If there are predicates on _field, or on anything besides
+	// _measurement, we can't use the index and need to use the slow path.
+	useIndex := mqAttrs.pred == nil || (!reads.ExprHasKey(mqAttrs.pred, fieldKey) && !hasTagKey(mqAttrs.pred))
+	return metaqueryValues(mqAttrs, useIndex, func(shardIDs []uint64) ([]string, error) {
+		return s.measurementFieldsIndexed(ctx, mqAttrs, shardIDs)
+	}, func(shardIDs []uint64) ([]string, error) {
+		return s.tagValuesSlow(ctx, mqAttrs, fieldKey, shardIDs)
+	})
+}
 
+// measurementFieldsIndexed returns the field keys of the measurements of the
+// shards, found with their metadata.
+func (s *Store) measurementFieldsIndexed(ctx context.Context, mqAttrs *metaqueryAttributes, shardIDs []uint64) ([]string, error) {
 	sg := s.TSDBStore.ShardGroup(shardIDs)
 	ms := &influxql.Measurement{
 		Database:        mqAttrs.db,
@@ -554,9 +703,71 @@ func (s *Store) measurementFields(ctx context.Context, mqAttrs *metaqueryAttribu
 	}
 
 	sort.Strings(fieldNames)
-	fieldNames = slices.MergeSortedStrings(fieldNames)
+	return slices.MergeSortedStrings(fieldNames), nil
+}
 
-	return cursors.NewStringSliceIterator(fieldNames), nil
+// SeriesCardinality returns the number of series ReadFilter would return for
+// the request, by the data type of their values, each field of a series key
+// counting as one series. The series are found with the index, and only the
+// first block of each is read to check it has data in the range. Predicates on
+// field values are not supported.
+func (s *Store) SeriesCardinality(ctx context.Context, req *datatypes.ReadFilterRequest) (map[datatypes.ReadResponse_DataType]int64, error) {
+	if req.ReadSource == nil {
+		return nil, errors.New("missing read source")
+	}
+	mqAttrs, err := s.newMetaqueryAttributes(*req.ReadSource, req.Range, req.Predicate, "")
+	if err != nil {
+		return nil, err
+	}
+
+	shardIDs := mqAttrs.shardIDs()
+	if len(shardIDs) == 0 {
+		return nil, nil
+	}
+
+	var cur reads.SeriesCursor
+	if ic, err := newIndexSeriesCursorInfluxQLPred(ctx, mqAttrs.pred, s.TSDBStore.Shards(shardIDs)); err != nil {
+		return nil, err
+	} else if ic == nil {
+		return nil, nil
+	} else {
+		cur = ic
+	}
+
+	counts := make(map[datatypes.ReadResponse_DataType]int64)
+	rs := reads.NewFilteredResultSet(ctx, mqAttrs.start, mqAttrs.end, cur)
+	defer rs.Close()
+	for rs.Next() {
+		func() {
+			c := rs.Cursor()
+			if c == nil {
+				return
+			}
+			defer c.Close()
+			if cursorHasData(c) {
+				counts[cursorDataType(c)]++
+			}
+		}()
+	}
+	return counts, rs.Err()
+}
+
+// cursorDataType returns the data type of the values of c.
+func cursorDataType(c cursors.Cursor) datatypes.ReadResponse_DataType {
+	switch typedCur := c.(type) {
+	case cursors.IntegerArrayCursor:
+		return datatypes.DataTypeInteger
+	case cursors.FloatArrayCursor:
+		return datatypes.DataTypeFloat
+	case cursors.UnsignedArrayCursor:
+		return datatypes.DataTypeUnsigned
+	case cursors.BooleanArrayCursor:
+		return datatypes.DataTypeBoolean
+	case cursors.StringArrayCursor:
+		return datatypes.DataTypeString
+	default:
+		panic(fmt.Sprintf("unreachable: %T", typedCur))
+	}
 }
 
 func cursorHasData(c cursors.Cursor) bool {
@@ -583,26 +794,19 @@ func cursorHasData(c cursors.Cursor) bool {
 	return l != 0
 }
 
-// tagValuesSlow will determine the tag values for the given tagKey.
+// tagValuesSlow will determine the tag values for the given tagKey of the
+// series of the shards with data in the range.
 // It's generally faster to use tagValues, measurementFields or
 // MeasurementNames, but those methods will only use the index and metadata
 // stored in the shard. Because fields are not themselves indexed, we have no way
 // of correlating fields to tag values, so we sometimes need to consult tsm to
 // provide an accurate answer.
-func (s *Store) tagValuesSlow(ctx context.Context, mqAttrs *metaqueryAttributes, tagKey string) (cursors.StringIterator, error) {
-	shardIDs, err := s.findShardIDs(mqAttrs.db, mqAttrs.rp, false, mqAttrs.start, mqAttrs.end)
-	if err != nil {
-		return nil, err
-	}
-	if len(shardIDs) == 0 {
-		return cursors.EmptyStringIterator, nil
-	}
-
+func (s *Store) tagValuesSlow(ctx context.Context, mqAttrs *metaqueryAttributes, tagKey string, shardIDs []uint64) ([]string, error) {
 	var cur reads.SeriesCursor
 	if ic, err := newIndexSeriesCursorInfluxQLPred(ctx, mqAttrs.pred, s.TSDBStore.Shards(shardIDs)); err != nil {
 		return nil, err
 	} else if ic == nil {
-		return cursors.EmptyStringIterator, nil
+		return nil, nil
 	} else {
 		cur = ic
 	}
@@ -622,8 +826,8 @@ func (s *Store) tagValuesSlow(ctx context.Context, mqAttrs *metaqueryAttributes,
 			}
 			defer c.Close()
 
-			if cursorHasData(c) {
-				f := rs.Tags().Get([]byte(tagKey))
+			// Series without the tag have no value for it.
+			if f := rs.Tags().Get([]byte(tagKey)); f != nil && cursorHasData(c) {
 				m[string(f)] = struct{}{}
 			}
 		}()
@@ -634,5 +838,5 @@ func (s *Store) tagValuesSlow(ctx context.Context, mqAttrs *metaqueryAttributes,
 		names = append(names, name)
 	}
 	sort.Strings(names)
-	return cursors.NewStringSliceIterator(names), nil
+	return names, nil
 }
@@ -0,0 +1,464 @@
+package storage_test
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/influxql/query"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	_ "github.com/influxdata/influxdb/v2/tsdb/engine"
+	"github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
+	"github.com/influxdata/influxdb/v2/v1/services/meta"
+	"github.com/influxdata/influxdb/v2/v1/services/storage"
+	"github.com/influxdata/influxql"
+)
+
+const (
+	testOrgID    = influxdb.ID(0x0a)
+	testBucketID = influxdb.ID(0x0b)
+)
+
+// testTime is the start of the first shard group of the test store.
+var testTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// metaClient returns the shard groups of a single retention policy of the
+// test bucket.
+type metaClient struct {
+	groups []meta.ShardGroupInfo
+}
+
+func (c *metaClient) Database(name string) *meta.DatabaseInfo {
+	if name != testBucketID.String() {
+		return nil
+	}
+	return &meta.DatabaseInfo{
+		Name:                   name,
+		DefaultRetentionPolicy: meta.DefaultRetentionPolicyName,
+		RetentionPolicies: []meta.RetentionPolicyInfo{{
+			Name:               meta.DefaultRetentionPolicyName,
+			ShardGroupDuration: time.Hour,
+			ShardGroups:        c.groups,
+		}},
+	}
+}
+
+func (c *metaClient) ShardGroupsByTimeRange(database, policy string, min, max time.Time) ([]meta.ShardGroupInfo, error) {
+	var groups []meta.ShardGroupInfo
+	for _, g := range c.groups {
+		if g.Overlaps(min, max) {
+			groups = append(groups, g)
+		}
+	}
+	return groups, nil
+}
+
+// newTestStore returns a store reading the points of lines, which are written
+// to a shard of one hour per shard group, starting at testTime.
+func newTestStore(t *testing.T, lines ...string) *storage.Store {
+	t.Helper()
+
+	s := tsdb.NewStore(t.TempDir())
+	s.EngineOptions.IndexVersion = tsi1.IndexName
+	s.EngineOptions.Config.WALDir = t.TempDir()
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	points, err := models.ParsePointsString(strings.Join(lines, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mc := &metaClient{}
+	shards := map[uint64][]models.Point{}
+	for _, p := range points {
+		id := uint64(p.Time().Sub(testTime)/time.Hour) + 1
+		if _, ok := shards[id]; !ok {
+			start := testTime.Add(time.Duration(id-1) * time.Hour)
+			mc.groups = append(mc.groups, meta.ShardGroupInfo{
+				ID:        id,
+				StartTime: start,
+				EndTime:   start.Add(time.Hour),
+				Shards:    []meta.ShardInfo{{ID: id}},
+			})
+			if err := s.CreateShard(testBucketID.String(), meta.DefaultRetentionPolicyName, id, true); err != nil {
+				t.Fatal(err)
+			}
+		}
+		shards[id] = append(shards[id], p)
+	}
+	for id, points := range shards {
+		if err := s.WriteToShard(id, points); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return storage.NewStore(s, mc)
+}
+
+func tagRef(key string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeTagRef,
+		Value:    &datatypes.Node_TagRefValue{TagRefValue: key},
+	}
+}
+
+func stringLiteral(v string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLiteral,
+		Value:    &datatypes.Node_StringValue{StringValue: v},
+	}
+}
+
+// tagEqual returns a node comparing the tag key to a value, as Flux
+// translates 'r.key == "value"'.
+func tagEqual(key, value string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+		Children: []*datatypes.Node{tagRef(key), stringLiteral(value)},
+	}
+}
+
+func and(children ...*datatypes.Node) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLogicalExpression,
+		Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalAnd},
+		Children: children,
+	}
+}
+
+// cursorLen returns the number of values of the cursor.
+func cursorLen(t *testing.T, c cursors.Cursor) int {
+	t.Helper()
+	var n int
+	for {
+		var l int
+		switch c := c.(type) {
+		case cursors.FloatArrayCursor:
+			l = c.Next().Len()
+		case cursors.IntegerArrayCursor:
+			l = c.Next().Len()
+		case cursors.UnsignedArrayCursor:
+			l = c.Next().Len()
+		case cursors.StringArrayCursor:
+			l = c.Next().Len()
+		case cursors.BooleanArrayCursor:
+			l = c.Next().Len()
+		default:
+			t.Fatalf("unexpected cursor %T", c)
+		}
+		if l == 0 {
+			return n
+		}
+		n += l
+	}
+}
+
+// readSeries reads the series of the request with ReadFilter, as the
+// unoptimized Flux query reads them, and returns the tags of those with
+// data in the range, and their number by data type.
+func readSeries(t *testing.T, s *storage.Store, req *datatypes.ReadFilterRequest) ([]models.Tags, map[datatypes.ReadResponse_DataType]int64) {
+	t.Helper()
+	rs, err := s.ReadFilter(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	} else if rs == nil {
+		return nil, nil
+	}
+	defer rs.Close()
+
+	var series []models.Tags
+	counts := map[datatypes.ReadResponse_DataType]int64{}
+	for rs.Next() {
+		c := rs.Cursor()
+		if c == nil {
+			continue
+		}
+		if cursorLen(t, c) > 0 {
+			series = append(series, rs.Tags().Clone())
+			counts[dataType(t, c)]++
+		}
+		c.Close()
+	}
+	if err := rs.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return series, counts
+}
+
+// dataType returns the data type of the values of the cursor.
+func dataType(t *testing.T, c cursors.Cursor) datatypes.ReadResponse_DataType {
+	t.Helper()
+	switch c.(type) {
+	case cursors.FloatArrayCursor:
+		return datatypes.DataTypeFloat
+	case cursors.IntegerArrayCursor:
+		return datatypes.DataTypeInteger
+	case cursors.UnsignedArrayCursor:
+		return datatypes.DataTypeUnsigned
+	case cursors.StringArrayCursor:
+		return datatypes.DataTypeString
+	case cursors.BooleanArrayCursor:
+		return datatypes.DataTypeBoolean
+	default:
+		t.Fatalf("unexpected cursor %T", c)
+		return 0
+	}
+}
+
+// distinct returns the sorted distinct values of the tag key of the series,
+// ignoring the series without the tag.
+func distinct(series []models.Tags, key string) []string {
+	m := map[string]bool{}
+	for _, tags := range series {
+		if v := tags.Get([]byte(key)); v != nil {
+			m[string(v)] = true
+		}
+	}
+	return sortedKeys(m)
+}
+
+// tagKeys returns the sorted distinct tag keys of the series.
+func tagKeys(series []models.Tags) []string {
+	m := map[string]bool{}
+	for _, tags := range series {
+		for _, tag := range tags {
+			m[string(tag.Key)] = true
+		}
+	}
+	return sortedKeys(m)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// stringsOf returns the values of iter, failing t if err is not nil.
+func stringsOf(t *testing.T) func(iter cursors.StringIterator, err error) []string {
+	return func(iter cursors.StringIterator, err error) []string {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := []string{}
+		for iter.Next() {
+			values = append(values, iter.Value())
+		}
+		return values
+	}
+}
+
+// TestStore_SchemaQueries checks that each schema method of the store returns
+// what the query it replaces would compute from the series read by
+// ReadFilter.
+func TestStore_SchemaQueries(t *testing.T) {
+	ts := func(d time.Duration) string {
+		return " " + strconv.FormatInt(testTime.Add(d).UnixNano(), 10)
+	}
+	s := newTestStore(t,
+		// The first shard group.
+		"cpu,host=a,region=east usage=1,idle=2"+ts(10*time.Minute),
+		"cpu,host=b usage=3"+ts(50*time.Minute),
+		"mem,host=a free=4i"+ts(20*time.Minute),
+		"mem,host=b,rack=1 used=5i"+ts(40*time.Minute),
+		// The second shard group.
+		"cpu,host=a,region=east usage=6"+ts(90*time.Minute),
+		"cpu,host=c,dc=x usage=7"+ts(80*time.Minute),
+		"disk,host=a,path=/ used=8i"+ts(100*time.Minute),
+		// The third shard group.
+		"cpu,host=d usage=9"+ts(130*time.Minute),
+		"disk,host=b,path=/home used=10i"+ts(170*time.Minute),
+	)
+	source, err := types.MarshalAny(s.GetSource(uint64(testOrgID), uint64(testBucketID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := datatypes.TimestampRange{}
+	rng := func(start, end time.Duration) datatypes.TimestampRange {
+		return datatypes.TimestampRange{
+			Start: testTime.Add(start).UnixNano(),
+			End:   testTime.Add(end).UnixNano(),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		rng         datatypes.TimestampRange
+		measurement string
+		pred        *datatypes.Node
+	}{
+		{name: "all", rng: all},
+		{name: "measurement", rng: all, measurement: "cpu"},
+		{name: "tag", rng: all, measurement: "cpu", pred: tagEqual("host", "a")},
+		{name: "field", rng: all, pred: tagEqual(models.FieldKeyTagKey, "usage")},
+		{name: "measurement and field", rng: all, measurement: "mem", pred: tagEqual(models.FieldKeyTagKey, "used")},
+		// The range covers a shard group and excludes the other.
+		{name: "shard group", rng: rng(0, time.Hour-1)},
+		{name: "shard group measurement", rng: rng(time.Hour, 2*time.Hour-1), measurement: "cpu"},
+		{name: "shard group field", rng: rng(0, time.Hour-1), pred: tagEqual(models.FieldKeyTagKey, "usage")},
+		// The range excludes some series of each shard group.
+		{name: "range", rng: rng(15*time.Minute, 85*time.Minute)},
+		{name: "range measurement", rng: rng(15*time.Minute, 85*time.Minute), measurement: "cpu"},
+		{name: "range field", rng: rng(30*time.Minute, 95*time.Minute), pred: tagEqual(models.FieldKeyTagKey, "usage")},
+		{name: "range tag", rng: rng(0, 30*time.Minute), measurement: "mem", pred: tagEqual("host", "a")},
+		// The range covers the second shard group, and excludes some series
+		// of the first and third.
+		{name: "covered shard group", rng: rng(30*time.Minute, 150*time.Minute)},
+		{name: "covered shard group measurement", rng: rng(30*time.Minute, 150*time.Minute), measurement: "cpu"},
+		{name: "covered shard group tag", rng: rng(30*time.Minute, 150*time.Minute), measurement: "disk", pred: tagEqual("host", "a")},
+		{name: "covered shard group field", rng: rng(30*time.Minute, 150*time.Minute), pred: tagEqual(models.FieldKeyTagKey, "used")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			readStrings := stringsOf(t)
+
+			// The predicate of the unoptimized query, which filters the
+			// measurement along with the other columns.
+			root := tt.pred
+			if tt.measurement != "" {
+				root = tagEqual(models.MeasurementTagKey, tt.measurement)
+				if tt.pred != nil {
+					root = and(root, tt.pred)
+				}
+			}
+			var filterPred, pred *datatypes.Predicate
+			if root != nil {
+				filterPred = &datatypes.Predicate{Root: root}
+			}
+			if tt.pred != nil {
+				pred = &datatypes.Predicate{Root: tt.pred}
+			}
+
+			series, counts := readSeries(t, s, &datatypes.ReadFilterRequest{ReadSource: source, Range: tt.rng, Predicate: filterPred})
+			if len(series) == 0 {
+				t.Fatal("expected series to be read")
+			}
+
+			n, err := s.SeriesCardinality(ctx, &datatypes.ReadFilterRequest{ReadSource: source, Range: tt.rng, Predicate: filterPred})
+			if err != nil {
+				t.Fatal(err)
+			} else if diff := cmp.Diff(counts, n); diff != "" {
+				t.Errorf("SeriesCardinality: -exp/+got\n%s", diff)
+			}
+
+			names := readStrings(s.MeasurementNames(ctx, &datatypes.MeasurementNamesRequest{Source: source, Range: tt.rng, Predicate: filterPred}))
+			if diff := cmp.Diff(distinct(series, "_measurement"), names); diff != "" {
+				t.Errorf("MeasurementNames: -exp/+got\n%s", diff)
+			}
+
+			fields := readStrings(s.MeasurementFields(ctx, &datatypes.MeasurementFieldsRequest{Source: source, Range: tt.rng, Measurement: tt.measurement, Predicate: pred}))
+			if diff := cmp.Diff(distinct(series, "_field"), fields); diff != "" {
+				t.Errorf("MeasurementFields: -exp/+got\n%s", diff)
+			}
+
+			if tt.measurement == "" {
+				return
+			}
+
+			keys := readStrings(s.MeasurementTagKeys(ctx, &datatypes.MeasurementTagKeysRequest{Source: source, Range: tt.rng, Measurement: tt.measurement, Predicate: pred}))
+			if diff := cmp.Diff(tagKeys(series), keys); diff != "" {
+				t.Errorf("MeasurementTagKeys: -exp/+got\n%s", diff)
+			}
+
+			for _, key := range tagKeys(series) {
+				if key == "_measurement" || key == "_field" {
+					continue
+				}
+				values := readStrings(s.MeasurementTagValues(ctx, &datatypes.MeasurementTagValuesRequest{Source: source, Range: tt.rng, Measurement: tt.measurement, TagKey: key, Predicate: pred}))
+				if diff := cmp.Diff(distinct(series, key), values); diff != "" {
+					t.Errorf("MeasurementTagValues(%s): -exp/+got\n%s", key, diff)
+				}
+			}
+		})
+	}
+}
+
+// recordingTSDBStore records the shards whose index is used to find tag keys
+// and values.
+type recordingTSDBStore struct {
+	storage.TSDBStore
+	tagKeys, tagValues []uint64
+}
+
+func (s *recordingTSDBStore) TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error) {
+	s.tagKeys = append(s.tagKeys, shardIDs...)
+	return s.TSDBStore.TagKeys(auth, shardIDs, cond)
+}
+
+func (s *recordingTSDBStore) TagValues(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error) {
+	s.tagValues = append(s.tagValues, shardIDs...)
+	return s.TSDBStore.TagValues(auth, shardIDs, cond)
+}
+
+// TestStore_SchemaQueries_Index checks that the index is only used for the
+// shards the range covers, and not at all with a predicate on fields.
+func TestStore_SchemaQueries_Index(t *testing.T) {
+	ts := func(d time.Duration) string {
+		return " " + strconv.FormatInt(testTime.Add(d).UnixNano(), 10)
+	}
+	s := newTestStore(t,
+		"cpu,host=a usage=1"+ts(10*time.Minute),
+		"cpu,host=b usage=2"+ts(70*time.Minute),
+		"cpu,host=c usage=3"+ts(130*time.Minute),
+		"cpu,host=d usage=4"+ts(170*time.Minute),
+	)
+	tsdbStore := &recordingTSDBStore{TSDBStore: s.TSDBStore}
+	s.TSDBStore = tsdbStore
+	source, err := types.MarshalAny(s.GetSource(uint64(testOrgID), uint64(testBucketID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	readStrings := stringsOf(t)
+	rng := datatypes.TimestampRange{
+		Start: testTime.Add(30 * time.Minute).UnixNano(),
+		End:   testTime.Add(150 * time.Minute).UnixNano(),
+	}
+
+	keys := readStrings(s.MeasurementTagKeys(ctx, &datatypes.MeasurementTagKeysRequest{Source: source, Range: rng, Measurement: "cpu"}))
+	if diff := cmp.Diff([]string{"_field", "_measurement", "host"}, keys); diff != "" {
+		t.Errorf("MeasurementTagKeys: -exp/+got\n%s", diff)
+	}
+	values := readStrings(s.MeasurementTagValues(ctx, &datatypes.MeasurementTagValuesRequest{Source: source, Range: rng, Measurement: "cpu", TagKey: "host"}))
+	if diff := cmp.Diff([]string{"b", "c"}, values); diff != "" {
+		t.Errorf("MeasurementTagValues: -exp/+got\n%s", diff)
+	}
+	if diff := cmp.Diff([]uint64{2}, tsdbStore.tagKeys); diff != "" {
+		t.Errorf("TagKeys shards: -exp/+got\n%s", diff)
+	}
+	if diff := cmp.Diff([]uint64{2}, tsdbStore.tagValues); diff != "" {
+		t.Errorf("TagValues shards: -exp/+got\n%s", diff)
+	}
+
+	tsdbStore.tagKeys, tsdbStore.tagValues = nil, nil
+	pred := &datatypes.Predicate{Root: tagEqual(models.FieldKeyTagKey, "usage")}
+	readStrings(s.MeasurementTagKeys(ctx, &datatypes.MeasurementTagKeysRequest{Source: source, Range: rng, Measurement: "cpu", Predicate: pred}))
+	values = readStrings(s.MeasurementTagValues(ctx, &datatypes.MeasurementTagValuesRequest{Source: source, Range: rng, Measurement: "cpu", TagKey: "host", Predicate: pred}))
+	if diff := cmp.Diff([]string{"b", "c"}, values); diff != "" {
+		t.Errorf("MeasurementTagValues with field predicate: -exp/+got\n%s", diff)
+	}
+	if len(tsdbStore.tagKeys) > 0 || len(tsdbStore.tagValues) > 0 {
+		t.Errorf("expected the index not to be used with a field predicate, got TagKeys shards %v, TagValues shards %v", tsdbStore.tagKeys, tsdbStore.tagValues)
+	}
+}
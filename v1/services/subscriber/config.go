@@ -0,0 +1,63 @@
+package subscriber
+
+import (
+	"errors"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/toml"
+)
+
+const (
+	// DefaultHTTPTimeout is the default HTTP timeout for a Config.
+	DefaultHTTPTimeout = 30 * time.Second
+
+	// DefaultWriteConcurrency is the default write concurrency of each subscription.
+	DefaultWriteConcurrency = 40
+
+	// DefaultWriteBufferSize is the default number of pending writes
+	// buffered for each subscription.
+	DefaultWriteBufferSize = 1000
+)
+
+// Config represents the configuration of the subscriber service.
+type Config struct {
+	// Whether to enable the subscriber service.
+	Enabled bool `toml:"enabled"`
+
+	// The timeout of writes to HTTP destinations.
+	HTTPTimeout toml.Duration `toml:"http-timeout"`
+
+	// Allow insecure HTTPS connections to subscriptions.
+	InsecureSkipVerify bool `toml:"insecure-skip-verify"`
+
+	// The number of writer goroutines processing the write channel of each subscription.
+	WriteConcurrency int `toml:"write-concurrency"`
+
+	// The number of in-flight writes buffered in the write channel of each
+	// subscription. Writes to a full channel are dropped.
+	WriteBufferSize int `toml:"write-buffer-size"`
+}
+
+// NewConfig returns a new instance of a subscriber config.
+func NewConfig() Config {
+	return Config{
+		Enabled:          true,
+		HTTPTimeout:      toml.Duration(DefaultHTTPTimeout),
+		WriteConcurrency: DefaultWriteConcurrency,
+		WriteBufferSize:  DefaultWriteBufferSize,
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if c.HTTPTimeout <= 0 {
+		return errors.New("http-timeout must be greater than 0")
+	}
+	if c.WriteConcurrency <= 0 {
+		return errors.New("write-concurrency must be greater than 0")
+	}
+	if c.WriteBufferSize <= 0 {
+		return errors.New("write-buffer-size must be greater than 0")
+	}
+	return nil
+}
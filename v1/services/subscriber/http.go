@@ -0,0 +1,86 @@
+package subscriber
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/v1/coordinator"
+)
+
+// HTTP supports writing points over HTTP using the line protocol of the 1.x
+// write API. The user info of the destination URL, if any, is sent as basic
+// authentication.
+type HTTP struct {
+	client *http.Client
+	url    url.URL
+	user   *url.Userinfo
+}
+
+// NewHTTP returns a new HTTP points writer with default options.
+func NewHTTP(u url.URL, timeout time.Duration) *HTTP {
+	return NewHTTPS(u, timeout, false)
+}
+
+// NewHTTPS returns a new HTTPS points writer. Certificates of the destination
+// are not verified if insecure is set.
+func NewHTTPS(u url.URL, timeout time.Duration, insecure bool) *HTTP {
+	user := u.User
+	u.User = nil
+	return &HTTP{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
+			},
+		},
+		url:  u,
+		user: user,
+	}
+}
+
+// WritePoints writes the points to the HTTP destination.
+func (h *HTTP) WritePoints(p *coordinator.WritePointsRequest) error {
+	var buf bytes.Buffer
+	for _, pt := range p.Points {
+		buf.WriteString(pt.String())
+		buf.WriteByte('\n')
+	}
+
+	u := h.url
+	u.Path = u.Path + "/write"
+	params := url.Values{}
+	params.Set("db", p.Database)
+	if p.RetentionPolicy != "" {
+		params.Set("rp", p.RetentionPolicy)
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if h.user != nil {
+		password, _ := h.user.Password()
+		req.SetBasicAuth(h.user.Username(), password)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status %s writing to %s: %s", resp.Status, h.url.Host, bytes.TrimSpace(body))
+	}
+	return nil
+}
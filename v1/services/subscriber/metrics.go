@@ -0,0 +1,50 @@
+package subscriber
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// subscriberMetrics holds metrics related to the subscriber service.
+type subscriberMetrics struct {
+	pointsWritten *prometheus.CounterVec
+	pointsDropped *prometheus.CounterVec
+	writeFailures *prometheus.CounterVec
+}
+
+func newSubscriberMetrics() *subscriberMetrics {
+	const (
+		namespace = "storage"
+		subsystem = "subscriber"
+	)
+	labels := []string{"bucket", "subscription"}
+
+	return &subscriberMetrics{
+		pointsWritten: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "points_written_total",
+			Help:      "Number of points written to subscription destinations",
+		}, labels),
+
+		pointsDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "points_dropped_total",
+			Help:      "Number of points dropped because the write buffer of a subscription was full",
+		}, labels),
+
+		writeFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "write_failures_total",
+			Help:      "Number of failed writes to subscription destinations",
+		}, labels),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *subscriberMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.pointsWritten,
+		m.pointsDropped,
+		m.writeFailures,
+	}
+}
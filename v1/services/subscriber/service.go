@@ -0,0 +1,370 @@
+// Package subscriber implements the subscriber service to forward incoming
+// data to remote services.
+package subscriber
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/v1/coordinator"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// PointsWriter is an interface for writing points to a subscription destination.
+// Only WritePoints() needs to be satisfied.
+type PointsWriter interface {
+	WritePoints(p *coordinator.WritePointsRequest) error
+}
+
+// Service manages forking the incoming data from the storage engine to the
+// destinations of the subscriptions of its bucket.
+//
+// Service is also an influxdb.SubscriptionService, wrapping the service which
+// stores the subscriptions, so that the running subscriptions are updated as
+// soon as subscriptions are created or deleted.
+type Service struct {
+	SubscriptionService influxdb.SubscriptionService
+
+	// NewPointsWriter creates the writer of a single destination.
+	NewPointsWriter func(u url.URL) (PointsWriter, error)
+
+	logger  *zap.Logger
+	conf    Config
+	metrics *subscriberMetrics
+
+	points  chan *coordinator.WritePointsRequest
+	closing chan struct{}
+	wg      sync.WaitGroup
+
+	mu   sync.RWMutex
+	subs map[influxdb.ID]*chanWriter
+}
+
+var _ influxdb.SubscriptionService = (*Service)(nil)
+
+// NewService returns a subscriber service with the given settings.
+func NewService(s influxdb.SubscriptionService, c Config) *Service {
+	svc := &Service{
+		SubscriptionService: s,
+		logger:              zap.NewNop(),
+		conf:                c,
+		metrics:             newSubscriberMetrics(),
+		points:              make(chan *coordinator.WritePointsRequest, 100),
+	}
+	svc.NewPointsWriter = svc.newPointsWriter
+	return svc
+}
+
+// WithLogger sets the logger on the service.
+func (s *Service) WithLogger(log *zap.Logger) {
+	s.logger = log.With(zap.String("service", "subscriber"))
+}
+
+// PrometheusCollectors returns the metrics of the service.
+func (s *Service) PrometheusCollectors() []prometheus.Collector {
+	return s.metrics.PrometheusCollectors()
+}
+
+// Points returns a channel into which write point requests can be sent. Writes
+// are only forwarded while the service is open.
+func (s *Service) Points() chan<- *coordinator.WritePointsRequest {
+	return s.points
+}
+
+// Open starts the subscription service.
+func (s *Service) Open(ctx context.Context) error {
+	if !s.conf.Enabled {
+		return nil // Service disabled.
+	}
+
+	s.mu.Lock()
+	if s.closing != nil {
+		s.mu.Unlock()
+		return nil // Already open.
+	}
+	s.closing = make(chan struct{})
+	s.subs = make(map[influxdb.ID]*chanWriter)
+	s.mu.Unlock()
+
+	if err := s.update(ctx); err != nil {
+		return err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run()
+	}()
+
+	s.logger.Info("Opened service")
+	return nil
+}
+
+// Close terminates the subscription service, after the queued writes have
+// been sent.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	if s.closing == nil {
+		s.mu.Unlock()
+		return nil // Already closed.
+	}
+	close(s.closing)
+	s.mu.Unlock()
+
+	// Wait for the dispatching of writes to stop before closing the writers.
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, cw := range s.subs {
+		cw.Close()
+		delete(s.subs, id)
+	}
+	s.closing = nil
+
+	s.logger.Info("Closed service")
+	return nil
+}
+
+// run dispatches the incoming writes until the service is closed. The points
+// channel is left open, as the storage engine may still send to it; its
+// buffer fills up and further writes are dropped by the sender.
+func (s *Service) run() {
+	for {
+		select {
+		case <-s.closing:
+			return
+		case p := <-s.points:
+			s.write(p)
+		}
+	}
+}
+
+func (s *Service) write(p *coordinator.WritePointsRequest) {
+	// The storage engine names the database of a bucket after its ID.
+	bucketID, err := influxdb.IDFromString(p.Database)
+	if err != nil {
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, cw := range s.subs {
+		if cw.sub.BucketID == *bucketID {
+			cw.Write(p)
+		}
+	}
+}
+
+// update synchronizes the running subscriptions with the stored ones.
+// Subscriptions are never modified, so they are compared by ID.
+func (s *Service) update(ctx context.Context) error {
+	subs, _, err := s.SubscriptionService.FindSubscriptions(ctx, influxdb.SubscriptionFilter{})
+	if err != nil {
+		return err
+	}
+
+	// The writers of the deleted subscriptions are closed after releasing the
+	// lock, as closing waits for their queued writes to be sent.
+	var closed []*chanWriter
+	defer func() {
+		for _, cw := range closed {
+			cw.Close()
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing == nil {
+		return nil // Not open.
+	}
+
+	current := make(map[influxdb.ID]bool, len(subs))
+	for _, sub := range subs {
+		current[sub.ID] = true
+		if _, ok := s.subs[sub.ID]; ok {
+			continue
+		}
+
+		cw, err := s.newChanWriter(sub)
+		if err != nil {
+			// Skip the subscription, so that it does not block the others.
+			s.logger.Info("Subscription creation failed", zap.String("name", sub.Name), zap.Error(err))
+			continue
+		}
+		s.subs[sub.ID] = cw
+		s.logger.Info("Added new subscription",
+			zap.String("bucket_id", sub.BucketID.String()),
+			zap.String("name", sub.Name))
+	}
+
+	for id, cw := range s.subs {
+		if current[id] {
+			continue
+		}
+		closed = append(closed, cw)
+		delete(s.subs, id)
+		s.logger.Info("Deleted old subscription",
+			zap.String("bucket_id", cw.sub.BucketID.String()),
+			zap.String("name", cw.sub.Name))
+	}
+	return nil
+}
+
+// newChanWriter creates and starts the writer of a subscription.
+func (s *Service) newChanWriter(sub *influxdb.Subscription) (*chanWriter, error) {
+	writers := make([]PointsWriter, 0, len(sub.Destinations))
+	for _, dest := range sub.Destinations {
+		u, err := url.Parse(dest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse destination %q: %v", dest, err)
+		}
+		w, err := s.NewPointsWriter(*u)
+		if err != nil {
+			return nil, err
+		}
+		writers = append(writers, w)
+	}
+
+	labels := prometheus.Labels{"bucket": sub.BucketID.String(), "subscription": sub.Name}
+	cw := &chanWriter{
+		sub:           sub,
+		writeRequests: make(chan *coordinator.WritePointsRequest, s.conf.WriteBufferSize),
+		pw: &balanceWriter{
+			any:     sub.Mode == influxdb.SubscriptionModeAny,
+			writers: writers,
+		},
+		logger:        s.logger.With(zap.String("bucket_id", sub.BucketID.String()), zap.String("subscription", sub.Name)),
+		pointsWritten: s.metrics.pointsWritten.With(labels),
+		pointsDropped: s.metrics.pointsDropped.With(labels),
+		writeFailures: s.metrics.writeFailures.With(labels),
+	}
+	cw.Run(s.conf.WriteConcurrency)
+	return cw, nil
+}
+
+func (s *Service) newPointsWriter(u url.URL) (PointsWriter, error) {
+	switch u.Scheme {
+	case "udp":
+		return NewUDP(u.Host), nil
+	case "http":
+		return NewHTTP(u, time.Duration(s.conf.HTTPTimeout)), nil
+	case "https":
+		return NewHTTPS(u, time.Duration(s.conf.HTTPTimeout), s.conf.InsecureSkipVerify), nil
+	default:
+		return nil, fmt.Errorf("unknown destination scheme %s", u.Scheme)
+	}
+}
+
+// FindSubscriptionByID returns a single subscription by ID.
+func (s *Service) FindSubscriptionByID(ctx context.Context, id influxdb.ID) (*influxdb.Subscription, error) {
+	return s.SubscriptionService.FindSubscriptionByID(ctx, id)
+}
+
+// FindSubscriptions returns the subscriptions matching filter.
+func (s *Service) FindSubscriptions(ctx context.Context, filter influxdb.SubscriptionFilter) ([]*influxdb.Subscription, int, error) {
+	return s.SubscriptionService.FindSubscriptions(ctx, filter)
+}
+
+// CreateSubscription creates a subscription and starts forwarding writes to it.
+func (s *Service) CreateSubscription(ctx context.Context, sub *influxdb.Subscription) error {
+	if err := s.SubscriptionService.CreateSubscription(ctx, sub); err != nil {
+		return err
+	}
+	return s.update(ctx)
+}
+
+// DeleteSubscription stops forwarding writes to a subscription and deletes it.
+func (s *Service) DeleteSubscription(ctx context.Context, id influxdb.ID) error {
+	if err := s.SubscriptionService.DeleteSubscription(ctx, id); err != nil {
+		return err
+	}
+	return s.update(ctx)
+}
+
+// chanWriter sends the writes of a subscription to its destinations from a
+// bounded buffer. Writes to a full buffer are dropped.
+type chanWriter struct {
+	sub           *influxdb.Subscription
+	writeRequests chan *coordinator.WritePointsRequest
+	pw            PointsWriter
+	logger        *zap.Logger
+	wg            sync.WaitGroup
+
+	pointsWritten prometheus.Counter
+	pointsDropped prometheus.Counter
+	writeFailures prometheus.Counter
+}
+
+// Write queues the points for writing, or drops them if the buffer is full.
+func (c *chanWriter) Write(p *coordinator.WritePointsRequest) {
+	if c.sub.Database != "" {
+		p = &coordinator.WritePointsRequest{Database: c.sub.Database, RetentionPolicy: c.sub.RetentionPolicy, Points: p.Points}
+	}
+
+	select {
+	case c.writeRequests <- p:
+	default:
+		c.pointsDropped.Add(float64(len(p.Points)))
+	}
+}
+
+// Run starts n goroutines writing the queued points.
+func (c *chanWriter) Run(n int) {
+	for i := 0; i < n; i++ {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			for wr := range c.writeRequests {
+				if err := c.pw.WritePoints(wr); err != nil {
+					c.logger.Info("Failed to write points to subscription", zap.Error(err))
+					c.writeFailures.Inc()
+				} else {
+					c.pointsWritten.Add(float64(len(wr.Points)))
+				}
+			}
+		}()
+	}
+}
+
+// Close closes the chanWriter and waits for the queued points to be written.
+func (c *chanWriter) Close() {
+	close(c.writeRequests)
+	c.wg.Wait()
+}
+
+// balanceWriter writes to its destinations, either to all of them or to any
+// one of them in turn.
+type balanceWriter struct {
+	any     bool
+	writers []PointsWriter
+
+	mu sync.Mutex
+	i  int
+}
+
+// WritePoints writes the points to the destinations. In ANY mode, the next
+// destination is tried when a write fails.
+func (b *balanceWriter) WritePoints(p *coordinator.WritePointsRequest) error {
+	var lastErr error
+	for range b.writers {
+		// round robin through destinations.
+		b.mu.Lock()
+		w := b.writers[b.i]
+		b.i = (b.i + 1) % len(b.writers)
+		b.mu.Unlock()
+
+		// write points to destination.
+		if err := w.WritePoints(p); err != nil {
+			lastErr = err
+		} else if b.any {
+			return nil
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,477 @@
+package subscriber_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/v1/coordinator"
+	"github.com/influxdata/influxdb/v2/v1/services/subscriber"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const bucketID = influxdb.ID(0x1000)
+
+// newSubscriptionService returns a mock subscription service storing the
+// subscriptions in memory.
+func newSubscriptionService(subs ...*influxdb.Subscription) *mock.SubscriptionService {
+	var mu sync.Mutex
+	s := mock.NewSubscriptionService()
+	s.FindSubscriptionsFn = func(context.Context, influxdb.SubscriptionFilter) ([]*influxdb.Subscription, int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*influxdb.Subscription(nil), subs...), len(subs), nil
+	}
+	s.CreateSubscriptionFn = func(_ context.Context, sub *influxdb.Subscription) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sub.ID = influxdb.ID(len(subs) + 1)
+		subs = append(subs, sub)
+		return nil
+	}
+	s.DeleteSubscriptionFn = func(_ context.Context, id influxdb.ID) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for i, sub := range subs {
+			if sub.ID == id {
+				subs = append(subs[:i], subs[i+1:]...)
+				return nil
+			}
+		}
+		return influxdb.ErrSubscriptionNotFound
+	}
+	return s
+}
+
+func newWritePointsRequest(t *testing.T, bucketID influxdb.ID, lp string) *coordinator.WritePointsRequest {
+	t.Helper()
+	points, err := models.ParsePointsString(lp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &coordinator.WritePointsRequest{Database: bucketID.String(), RetentionPolicy: "autogen", Points: points}
+}
+
+// writeRecorder records the points written to the destinations, by URL.
+type writeRecorder struct {
+	mu      sync.Mutex
+	written map[string][]string
+	done    chan struct{}
+
+	// started receives before a write waits on block, if set.
+	started chan struct{}
+	block   chan struct{}
+}
+
+func newWriteRecorder() *writeRecorder {
+	return &writeRecorder{written: make(map[string][]string), done: make(chan struct{}, 100)}
+}
+
+func (r *writeRecorder) NewPointsWriter(u url.URL) (subscriber.PointsWriter, error) {
+	return writerFunc(func(p *coordinator.WritePointsRequest) error {
+		if r.block != nil {
+			r.started <- struct{}{}
+			<-r.block
+		}
+		r.mu.Lock()
+		for _, pt := range p.Points {
+			r.written[u.String()] = append(r.written[u.String()], pt.String())
+		}
+		r.mu.Unlock()
+		r.done <- struct{}{}
+		return nil
+	}), nil
+}
+
+func (r *writeRecorder) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for write %d of %d", i+1, n)
+		}
+	}
+}
+
+func (r *writeRecorder) count(dest string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.written[dest])
+}
+
+type writerFunc func(p *coordinator.WritePointsRequest) error
+
+func (f writerFunc) WritePoints(p *coordinator.WritePointsRequest) error { return f(p) }
+
+func openService(t *testing.T, c subscriber.Config, w *writeRecorder, subs ...*influxdb.Subscription) *subscriber.Service {
+	t.Helper()
+	s := subscriber.NewService(newSubscriptionService(subs...), c)
+	if w != nil {
+		s.NewPointsWriter = w.NewPointsWriter
+	}
+	if err := s.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestService_ModeAll(t *testing.T) {
+	w := newWriteRecorder()
+	s := openService(t, subscriber.NewConfig(), w, &influxdb.Subscription{
+		ID:           1,
+		BucketID:     bucketID,
+		Name:         "s0",
+		Mode:         influxdb.SubscriptionModeAll,
+		Destinations: []string{"udp://h1:9093", "udp://h2:9093"},
+	})
+
+	s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=1 1")
+	s.Points() <- newWritePointsRequest(t, bucketID+1, "cpu v=2 2")
+	s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=3 3")
+	w.wait(t, 4)
+
+	for _, dest := range []string{"udp://h1:9093", "udp://h2:9093"} {
+		if got := w.count(dest); got != 2 {
+			t.Errorf("%s: unexpected number of points: got %d, want 2", dest, got)
+		}
+	}
+}
+
+func TestService_ModeAny(t *testing.T) {
+	w := newWriteRecorder()
+	s := openService(t, subscriber.NewConfig(), w, &influxdb.Subscription{
+		ID:           1,
+		BucketID:     bucketID,
+		Name:         "s0",
+		Mode:         influxdb.SubscriptionModeAny,
+		Destinations: []string{"udp://h1:9093", "udp://h2:9093"},
+	})
+
+	for i := 0; i < 4; i++ {
+		s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=1 1")
+	}
+	w.wait(t, 4)
+
+	for _, dest := range []string{"udp://h1:9093", "udp://h2:9093"} {
+		if got := w.count(dest); got != 2 {
+			t.Errorf("%s: unexpected number of points: got %d, want 2", dest, got)
+		}
+	}
+}
+
+func TestService_DropsWhenBufferFull(t *testing.T) {
+	w := newWriteRecorder()
+	w.started = make(chan struct{}, 10)
+	w.block = make(chan struct{})
+
+	c := subscriber.NewConfig()
+	c.WriteConcurrency = 1
+	c.WriteBufferSize = 1
+	s := openService(t, c, w, &influxdb.Subscription{
+		ID:           1,
+		BucketID:     bucketID,
+		Name:         "s0",
+		Mode:         influxdb.SubscriptionModeAll,
+		Destinations: []string{"udp://h1:9093"},
+	})
+
+	// The first write blocks the writer, the second one fills the buffer
+	// and the others are dropped.
+	s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=1 1\ncpu v=2 2")
+	select {
+	case <-w.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for write")
+	}
+	for i := 0; i < 4; i++ {
+		s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=1 1\ncpu v=2 2")
+	}
+
+	// Wait for the dispatching of all writes.
+	dropped := func() float64 {
+		return counterValue(t, s.PrometheusCollectors(), "storage_subscriber_points_dropped_total")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for dropped() != 6 {
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected number of dropped points: got %v, want 6", dropped())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(w.block)
+	w.wait(t, 2)
+	if got := w.count("udp://h1:9093"); got != 4 {
+		t.Fatalf("unexpected number of points: got %d, want 4", got)
+	}
+}
+
+func TestService_CreateDeleteSubscription(t *testing.T) {
+	ctx := context.Background()
+	w := newWriteRecorder()
+	s := openService(t, subscriber.NewConfig(), w)
+
+	sub := &influxdb.Subscription{
+		BucketID:     bucketID,
+		Name:         "s0",
+		Mode:         influxdb.SubscriptionModeAll,
+		Destinations: []string{"udp://h1:9093"},
+	}
+	if err := s.CreateSubscription(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=1 1")
+	w.wait(t, 1)
+
+	if err := s.DeleteSubscription(ctx, sub.ID); err != nil {
+		t.Fatal(err)
+	}
+	s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=2 2")
+	select {
+	case <-w.done:
+		t.Fatal("unexpected write to a deleted subscription")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if got := w.count("udp://h1:9093"); got != 1 {
+		t.Fatalf("unexpected number of points: got %d, want 1", got)
+	}
+}
+
+func TestService_DeleteSubscriptionDoesNotBlockWrites(t *testing.T) {
+	ctx := context.Background()
+	started, block := make(chan struct{}, 1), make(chan struct{})
+	w := newWriteRecorder()
+
+	c := subscriber.NewConfig()
+	c.WriteConcurrency = 1
+	subs := newSubscriptionService(
+		&influxdb.Subscription{ID: 1, BucketID: bucketID, Name: "slow", Mode: influxdb.SubscriptionModeAll, Destinations: []string{"udp://slow:9093"}},
+		&influxdb.Subscription{ID: 2, BucketID: bucketID + 1, Name: "s0", Mode: influxdb.SubscriptionModeAll, Destinations: []string{"udp://h1:9093"}},
+	)
+	updating := make(chan struct{})
+	deleteSubscription := subs.DeleteSubscriptionFn
+	subs.DeleteSubscriptionFn = func(ctx context.Context, id influxdb.ID) error {
+		defer close(updating)
+		return deleteSubscription(ctx, id)
+	}
+	s := subscriber.NewService(subs, c)
+	s.NewPointsWriter = func(u url.URL) (subscriber.PointsWriter, error) {
+		if u.Host != "slow:9093" {
+			return w.NewPointsWriter(u)
+		}
+		return writerFunc(func(*coordinator.WritePointsRequest) error {
+			started <- struct{}{}
+			<-block
+			return nil
+		}), nil
+	}
+	if err := s.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var once sync.Once
+	release := func() { once.Do(func() { close(block) }) }
+	defer release()
+
+	// Block the writer of the slow subscription, and delete it while its
+	// queued write is pending.
+	s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=1 1")
+	s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=2 2")
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for write")
+	}
+	deleted := make(chan error, 1)
+	go func() { deleted <- s.DeleteSubscription(ctx, 1) }()
+
+	// Writes to the other subscriptions are still dispatched while the
+	// deleted one drains.
+	<-updating
+	time.Sleep(50 * time.Millisecond)
+	s.Points() <- newWritePointsRequest(t, bucketID+1, "cpu v=3 3")
+	w.wait(t, 1)
+
+	release()
+	select {
+	case err := <-deleted:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the subscription to be deleted")
+	}
+}
+
+func TestService_HTTP(t *testing.T) {
+	type request struct {
+		query url.Values
+		body  string
+	}
+	requests := make(chan request, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.URL.Path != "/write" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requests <- request{query: r.URL.Query(), body: string(body)}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	s := openService(t, subscriber.NewConfig(), nil,
+		&influxdb.Subscription{
+			ID:           1,
+			BucketID:     bucketID,
+			Name:         "s0",
+			Mode:         influxdb.SubscriptionModeAll,
+			Destinations: []string{ts.URL},
+		},
+		&influxdb.Subscription{
+			ID:              2,
+			BucketID:        bucketID + 1,
+			Name:            "s1",
+			Mode:            influxdb.SubscriptionModeAll,
+			Destinations:    []string{ts.URL},
+			Database:        "db0",
+			RetentionPolicy: "rp0",
+		},
+	)
+
+	s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=1 1")
+	s.Points() <- newWritePointsRequest(t, bucketID+1, "mem v=2 2")
+
+	got := make(map[string]string)
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-requests:
+			got[r.query.Get("db")+"/"+r.query.Get("rp")] = strings.TrimSpace(r.body)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for HTTP write")
+		}
+	}
+
+	want := map[string]string{
+		bucketID.String() + "/autogen": "cpu v=1 1",
+		"db0/rp0":                      "mem v=2 2",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("unexpected write for %s: got %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestService_HTTP_BasicAuth(t *testing.T) {
+	type credentials struct {
+		username, password string
+		ok                 bool
+	}
+	requests := make(chan credentials, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		requests <- credentials{username: username, password: password, ok: ok}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.User = url.UserPassword("user", "p@ss")
+
+	s := openService(t, subscriber.NewConfig(), nil, &influxdb.Subscription{
+		ID:           1,
+		BucketID:     bucketID,
+		Name:         "s0",
+		Mode:         influxdb.SubscriptionModeAll,
+		Destinations: []string{u.String()},
+	})
+
+	s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=1 1")
+
+	select {
+	case got := <-requests:
+		if want := (credentials{username: "user", password: "p@ss", ok: true}); got != want {
+			t.Fatalf("unexpected credentials: got %+v, want %+v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for HTTP write")
+	}
+}
+
+func TestService_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	s := openService(t, subscriber.NewConfig(), nil, &influxdb.Subscription{
+		ID:           1,
+		BucketID:     bucketID,
+		Name:         "s0",
+		Mode:         influxdb.SubscriptionModeAll,
+		Destinations: []string{"udp://" + conn.LocalAddr().String()},
+	})
+
+	s.Points() <- newWritePointsRequest(t, bucketID, "cpu v=1 1\ncpu v=2 2")
+
+	var got []string
+	buf := make([]byte, 1024)
+	for i := 0; i < 2; i++ {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, strings.TrimSpace(string(buf[:n])))
+	}
+	if len(got) != 2 || got[0] != "cpu v=1 1" || got[1] != "cpu v=2 2" {
+		t.Fatalf("unexpected packets: %q", got)
+	}
+}
+
+// counterValue returns the sum of the counters named name.
+func counterValue(t *testing.T, collectors []prometheus.Collector, name string) float64 {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors...)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v float64
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.Metric {
+			v += counterOf(m)
+		}
+	}
+	return v
+}
+
+func counterOf(m *dto.Metric) float64 {
+	if m.Counter == nil {
+		return 0
+	}
+	return m.Counter.GetValue()
+}
@@ -0,0 +1,40 @@
+package subscriber
+
+import (
+	"net"
+
+	"github.com/influxdata/influxdb/v2/v1/coordinator"
+)
+
+// UDP supports writing points over UDP using the line protocol.
+type UDP struct {
+	addr string
+}
+
+// NewUDP returns a new UDP points writer.
+func NewUDP(addr string) *UDP {
+	return &UDP{
+		addr: addr,
+	}
+}
+
+// WritePoints writes the points to the UDP destination, one point per packet.
+func (u *UDP) WritePoints(p *coordinator.WritePointsRequest) error {
+	addr, err := net.ResolveUDPAddr("udp", u.addr)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, pt := range p.Points {
+		if _, err := conn.Write([]byte(pt.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}